@@ -13,6 +13,7 @@ import (
 	"github.com/liliang-cn/agent-go/pkg/acpserver"
 	"github.com/liliang-cn/agent-go/pkg/agent"
 	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
 	agentgomcp "github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/spf13/cobra"
 )
@@ -116,6 +117,22 @@ type sessionRuntime struct {
 	cleanup func() error
 }
 
+// RunStream runs the prompt for the local user: ACP clients launch this
+// process over stdio, so they get the system memory principal
+func (s *sessionRuntime) RunStream(ctx context.Context, goal string) (<-chan *agent.Event, error) {
+	return s.SessionRuntime.RunStream(domain.WithSystemMemoryPrincipal(ctx), goal)
+}
+
+// DecideMemorySuggestion decides a suggestion the client reviewed, also
+// for the local user
+func (s *sessionRuntime) DecideMemorySuggestion(ctx context.Context, id string, accept bool, by string) error {
+	decider, ok := s.SessionRuntime.(acpserver.MemorySuggestionRuntime)
+	if !ok {
+		return fmt.Errorf("memory suggestions are not supported")
+	}
+	return decider.DecideMemorySuggestion(domain.WithSystemMemoryPrincipal(ctx), id, accept, by)
+}
+
 func (s *sessionRuntime) Close() error {
 	var firstErr error
 	if err := s.SessionRuntime.Close(); err != nil {
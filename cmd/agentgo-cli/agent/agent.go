@@ -21,6 +21,9 @@ var (
 	Verbose        bool
 	Debug          bool // New debug flag
 	EnablePTC      bool // Enable Programmatic Tool Calling
+	adaptiveExec   bool // Replan remaining steps when a step fails
+	maxReplans     int
+	verifySteps    bool
 	skillsService  *skills.Service
 	skillsInitOnce sync.Once
 	skillsInitErr  error
@@ -48,7 +51,7 @@ var runCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		goal := args[0]
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Use the new Event-Driven Stream Runner
 		return runStream(ctx, goal)
@@ -62,7 +65,7 @@ var executeCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		planID := args[0]
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
@@ -80,8 +83,15 @@ var executeCmd = &cobra.Command{
 		fmt.Printf("📋 Goal: %s\n\n", plan.Goal)
 
 		// Execute the plan
-		result, err := agentService.ExecutePlan(ctx, plan)
-		if err != nil {
+		var result *agent.ExecutionResult
+		if adaptiveExec {
+			result, err = agentService.ExecutePlanAdaptive(ctx, plan,
+				agent.WithMaxReplans(maxReplans),
+				agent.WithStepVerification(verifySteps))
+		} else {
+			result, err = agentService.ExecutePlan(ctx, plan)
+		}
+		if err != nil && result == nil {
 			return fmt.Errorf("execution failed: %w", err)
 		}
 
@@ -106,8 +116,11 @@ var executeCmd = &cobra.Command{
 			fmt.Printf("Duration: %s\n", result.Duration)
 		}
 		fmt.Printf("Steps: %d done, %d failed\n", result.StepsDone, result.StepsFailed)
+		if len(plan.Revisions) > 0 {
+			fmt.Printf("Revisions: %d (see 'agentgo agent plan get %s')\n", len(plan.Revisions), plan.ID)
+		}
 
-		return nil
+		return err
 	},
 }
 
@@ -123,7 +136,7 @@ var planCreateCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		goal := args[0]
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
@@ -154,7 +167,7 @@ var planListCmd = &cobra.Command{
 	Short: "List agent plans",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
@@ -189,7 +202,7 @@ var planGetCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		planID := args[0]
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
@@ -219,6 +232,28 @@ var planGetCmd = &cobra.Command{
 			}
 		}
 
+		if len(plan.Revisions) > 0 {
+			fmt.Printf("\nRevision History:\n")
+			for _, rev := range plan.Revisions {
+				fmt.Printf("  #%d [%s] %s\n", rev.Number, rev.Trigger, rev.CreatedAt.Format("2006-01-02 15:04:05"))
+				if rev.Failure != "" {
+					fmt.Printf("     Failure: %s\n", rev.Failure)
+				}
+				if rev.Instruction != "" {
+					fmt.Printf("     Instruction: %s\n", rev.Instruction)
+				}
+				if rev.Reasoning != "" {
+					fmt.Printf("     Reasoning: %s\n", rev.Reasoning)
+				}
+				for _, step := range rev.ReplacedSteps {
+					fmt.Printf("     - [%s] %s\n", step.Tool, step.Description)
+				}
+				for _, step := range rev.NewSteps {
+					fmt.Printf("     + [%s] %s\n", step.Tool, step.Description)
+				}
+			}
+		}
+
 		return nil
 	},
 }
@@ -234,7 +269,7 @@ var sessionListCmd = &cobra.Command{
 	Short: "List agent sessions",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
@@ -268,7 +303,7 @@ var sessionGetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sessionID := args[0]
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
 			return err
@@ -297,7 +332,7 @@ var reviseCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		planID := args[0]
 		instruction := args[1]
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		_, agentService, err := initAgentServices(ctx)
 		if err != nil {
@@ -347,7 +382,7 @@ Example:
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		message := args[0]
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Initialize agent services
 		_, agentService, err := initAgentServices(ctx)
@@ -406,6 +441,9 @@ func init() {
 	runCmd.Flags().BoolVar(&EnablePTC, "ptc", false, "Enable Programmatic Tool Calling (JS sandbox)")
 	runCmd.Flags().StringVar(&runAgentName, "agent", "", "run a stored agent by name")
 	executeCmd.Flags().BoolVar(&EnablePTC, "ptc", false, "Enable Programmatic Tool Calling (JS sandbox)")
+	executeCmd.Flags().BoolVar(&adaptiveExec, "adaptive", false, "Revise the remaining steps when a step fails")
+	executeCmd.Flags().IntVar(&maxReplans, "max-replans", 3, "Maximum number of automatic plan revisions (with --adaptive)")
+	executeCmd.Flags().BoolVar(&verifySteps, "verify", true, "Verify each step result with the LLM (with --adaptive)")
 	AgentCmd.AddCommand(runCmd)
 	AgentCmd.AddCommand(agentListCmd)
 	AgentCmd.AddCommand(agentShowCmd)
@@ -537,6 +575,8 @@ func renderStreamEvent(w io.Writer, evt *agent.Event, state *streamRenderState)
 		sep := strings.Repeat("─", 60)
 		fmt.Fprintf(w, "\n\033[2m%s\n🐛 DEBUG [Round %d] %s\n%s\n%s\n%s\033[0m\n",
 			sep, evt.Round, label, sep, evt.Content, sep)
+	case agent.EventTypeToolsChanged:
+		fmt.Fprintf(w, "\n🔄 %s\n", evt.Content)
 	case agent.EventTypeError:
 		fmt.Fprintf(w, "\n❌ Error: %s\n", evt.Content)
 	}
@@ -44,7 +44,8 @@ type chatRequest struct {
 	Done  chan struct{}
 }
 
-var agentMentionPattern = regexp.MustCompile(`^@([^\s@]+)$`)
+// agentMentionPattern matches @AgentName; @server:uri is an MCP resource
+var agentMentionPattern = regexp.MustCompile(`^@([^\s@:]+)$`)
 var chatThinkBlockRe = regexp.MustCompile(`(?s)<think>.*?</think>`)
 
 var chatCmd = &cobra.Command{
@@ -78,7 +79,7 @@ func init() {
 }
 
 func runChat(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
 	chatCfg := cfg
 	if chatCfg == nil {
@@ -105,6 +106,7 @@ func runChat(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to build concierge service: %w", err)
 	}
 	defer svc.Close()
+	svc.SetElicitor(newTerminalElicitor())
 
 	// Set session if specified
 	if chatSessionID != "" {
@@ -135,11 +137,11 @@ func runChat(cmd *cobra.Command, args []string) error {
 			return parseErr
 		}
 		if len(tasks) > 0 {
-			return runDelegatedTaskChainAsync(context.Background(), agentManager, svc.CurrentSessionID(), tasks, nil, false)
+			return runDelegatedTaskChainAsync(ctx, agentManager, svc.CurrentSessionID(), tasks, nil, false)
 		}
 	}
 
-	result, err := svc.Chat(ctx, message)
+	result, err := svc.Chat(ctx, attachResources(ctx, svc, message))
 	if err != nil {
 		return fmt.Errorf("chat failed: %w", err)
 	}
@@ -289,6 +291,7 @@ func runInteractiveChat(ctx context.Context, svc *agent.Service, manager *agent.
 	fmt.Printf("%s This chat talks to Concierge, the always-on intake agent for AgentGo.\n", cliui.Tip)
 	fmt.Printf("%s Type 'quit' or 'exit' to end, 'clear' to reset session\n", cliui.Tip)
 	fmt.Printf("%s Tip: Use '@AgentName <instruction>' to run a saved agent in the background\n", cliui.Tip)
+	fmt.Printf("%s Tip: Mention '@server:uri' to attach an MCP resource\n", cliui.Tip)
 	fmt.Println()
 
 	// Setup signal handling for graceful shutdown
@@ -315,7 +318,7 @@ func runInteractiveChat(ctx context.Context, svc *agent.Service, manager *agent.
 					return
 				}
 				fmt.Printf("\n%s\n", cliui.Thinking)
-				result, err := svc.Chat(ctx, req.Input)
+				result, err := svc.Chat(ctx, attachResources(ctx, svc, req.Input))
 				if err != nil {
 					fmt.Printf("%s Error: %v\n\n", cliui.Error, err)
 				} else {
@@ -412,6 +415,16 @@ func runInteractiveChat(ctx context.Context, svc *agent.Service, manager *agent.
 	return nil
 }
 
+// attachResources appends the MCP resources mentioned as @server:uri to the
+// message; unreadable mentions are reported and left as plain text
+func attachResources(ctx context.Context, svc *agent.Service, message string) string {
+	withResources, err := svc.AttachResourceMentions(ctx, message)
+	if err != nil {
+		fmt.Printf("%s Could not attach resource: %v\n", cliui.Error, err)
+	}
+	return withResources
+}
+
 func parseDelegatedTasks(input string, isKnownAgent func(name string) bool) ([]delegatedTask, error) {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/internal/cliui"
+	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/internal/lineinput"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+// terminalElicitor answers MCP servers' requests for user input with a form
+// built from the requested schema. Terminal reads cannot be interrupted, so a
+// timeout is noticed once the answer being typed is submitted.
+type terminalElicitor struct {
+	mu       sync.Mutex // one form at a time
+	readLine func(prompt string) (string, error)
+	out      io.Writer
+}
+
+func newTerminalElicitor() *terminalElicitor {
+	return &terminalElicitor{readLine: lineinput.ReadInteractiveLine, out: os.Stdout}
+}
+
+func (t *terminalElicitor) Elicit(ctx context.Context, req *mcp.ElicitationRequest) (*mcp.ElicitationResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fmt.Fprintf(t.out, "\n%s %s asks: %s\n", cliui.Question, req.Server, req.Message)
+	if req.URL != "" {
+		fmt.Fprintf(t.out, "   Open: %s\n", req.URL)
+	}
+
+	for {
+		choice, err := t.ask(ctx, "   Respond? [y]es / [n]o / [c]ancel: ")
+		if err != nil {
+			return t.interrupted(err)
+		}
+		switch strings.ToLower(strings.TrimSpace(choice)) {
+		case "y", "yes":
+		case "n", "no":
+			return &mcp.ElicitationResponse{Action: mcp.ElicitationDecline}, nil
+		case "c", "cancel":
+			return &mcp.ElicitationResponse{Action: mcp.ElicitationCancel}, nil
+		default:
+			continue
+		}
+		break
+	}
+
+	content := make(map[string]any, len(req.Fields))
+	for _, field := range req.Fields {
+		if field.Description != "" {
+			fmt.Fprintf(t.out, "   %s\n", field.Description)
+		}
+		for {
+			input, err := t.ask(ctx, elicitationFieldPrompt(field))
+			if err != nil {
+				return t.interrupted(err)
+			}
+			value, err := mcp.ParseElicitationValue(field, input)
+			if err != nil {
+				fmt.Fprintf(t.out, "   %s %v\n", cliui.Error, err)
+				continue
+			}
+			if value != nil {
+				content[field.Name] = value
+			}
+			break
+		}
+	}
+	return &mcp.ElicitationResponse{Action: mcp.ElicitationAccept, Content: content}, nil
+}
+
+func (t *terminalElicitor) ask(ctx context.Context, prompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	line, err := t.readLine(prompt)
+	if err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return line, nil
+}
+
+// interrupted turns Ctrl+C or EOF into a cancellation and reports timeouts
+func (t *terminalElicitor) interrupted(err error) (*mcp.ElicitationResponse, error) {
+	switch {
+	case errors.Is(err, lineinput.ErrInputCanceled), errors.Is(err, io.EOF):
+		fmt.Fprintln(t.out, "   Cancelled")
+		return &mcp.ElicitationResponse{Action: mcp.ElicitationCancel}, nil
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		fmt.Fprintln(t.out, "   Request expired, answer discarded")
+	}
+	return nil, err
+}
+
+func elicitationFieldPrompt(field mcp.ElicitationField) string {
+	var b strings.Builder
+	b.WriteString("   ")
+	b.WriteString(field.Label())
+	b.WriteString(" (")
+	b.WriteString(field.Type)
+	if field.Required {
+		b.WriteString(", required")
+	}
+	b.WriteString(")")
+	if len(field.Enum) > 0 {
+		fmt.Fprintf(&b, " {%s}", strings.Join(field.Enum, "|"))
+	}
+	if field.Default != nil {
+		fmt.Fprintf(&b, " [%v]", field.Default)
+	}
+	b.WriteString(": ")
+	return b.String()
+}
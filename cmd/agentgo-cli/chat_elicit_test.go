@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/internal/lineinput"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+func scriptedElicitor(lines ...string) *terminalElicitor {
+	return &terminalElicitor{
+		out: io.Discard,
+		readLine: func(prompt string) (string, error) {
+			if len(lines) == 0 {
+				return "", io.EOF
+			}
+			line := lines[0]
+			lines = lines[1:]
+			return line, nil
+		},
+	}
+}
+
+func TestTerminalElicitorFillsForm(t *testing.T) {
+	req := &mcp.ElicitationRequest{
+		Server:  "github",
+		Message: "Create the issue?",
+		Fields: []mcp.ElicitationField{
+			{Name: "count", Type: "integer", Required: true},
+			{Name: "label", Type: "string", Enum: []string{"bug", "feature"}},
+			{Name: "draft", Type: "boolean", Default: false},
+		},
+	}
+	// "three" and "docs" are rejected and asked again
+	e := scriptedElicitor("y", "three", "3", "docs", "bug", "")
+
+	resp, err := e.Elicit(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Elicit() error = %v", err)
+	}
+	if resp.Action != mcp.ElicitationAccept {
+		t.Fatalf("action = %q", resp.Action)
+	}
+	if resp.Content["count"] != int64(3) || resp.Content["label"] != "bug" || resp.Content["draft"] != false {
+		t.Fatalf("content = %#v", resp.Content)
+	}
+}
+
+func TestTerminalElicitorDeclineAndCancel(t *testing.T) {
+	req := &mcp.ElicitationRequest{Server: "github", Message: "Continue?"}
+
+	resp, err := scriptedElicitor("n").Elicit(context.Background(), req)
+	if err != nil || resp.Action != mcp.ElicitationDecline {
+		t.Fatalf("decline = %+v, %v", resp, err)
+	}
+
+	ctrlC := &terminalElicitor{out: io.Discard, readLine: func(string) (string, error) {
+		return "", lineinput.ErrInputCanceled
+	}}
+	resp, err = ctrlC.Elicit(context.Background(), req)
+	if err != nil || resp.Action != mcp.ElicitationCancel {
+		t.Fatalf("ctrl+c = %+v, %v", resp, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := scriptedElicitor("y").Elicit(ctx, req); err == nil {
+		t.Fatal("expected an error for an expired request")
+	}
+}
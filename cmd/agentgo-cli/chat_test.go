@@ -88,6 +88,16 @@ func TestParseDelegatedTasks(t *testing.T) {
 		}
 	})
 
+	t.Run("resource mention is not a delegation", func(t *testing.T) {
+		tasks, err := parseDelegatedTasks("@files:notes/todo.md 总结一下", isKnown)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(tasks) != 0 {
+			t.Fatalf("expected no tasks, got %d", len(tasks))
+		}
+	})
+
 	t.Run("unknown later mention treated as text", func(t *testing.T) {
 		tasks, err := parseDelegatedTasks("@Assistant 调查 @Unknown 这个名字会不会被保留", isKnown)
 		if err != nil {
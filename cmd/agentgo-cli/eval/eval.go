@@ -0,0 +1,224 @@
+// Package eval provides CLI commands for running evaluation suites
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/eval"
+	"github.com/liliang-cn/agent-go/pkg/rag"
+	"github.com/liliang-cn/agent-go/pkg/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	Cfg     *config.Config
+	Verbose bool
+
+	outputFormat string
+	outputPath   string
+	baselinePath string
+	saveBaseline string
+	targetKind   string
+	noJudge      bool
+)
+
+// SetSharedVariables sets shared variables from root command
+func SetSharedVariables(cfg *config.Config, verbose bool) {
+	Cfg = cfg
+	Verbose = verbose
+}
+
+// Cmd is the eval parent command
+var Cmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Evaluate agents and RAG pipelines against test suites",
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run [suite.yaml]",
+	Short: "Run an evaluation suite",
+	Long: `Run every case in a YAML suite against the agent or RAG pipeline and
+report answer matches, tool-call precision, retrieval recall@k/MRR, judge
+scores, latency and estimated cost.
+
+Examples:
+  agentgo eval run suite.yaml
+  agentgo eval run suite.yaml --format junit --output report.xml
+  agentgo eval run suite.yaml --baseline baseline.json
+  agentgo eval run suite.yaml --save-baseline baseline.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		suite, err := eval.LoadSuite(args[0])
+		if err != nil {
+			return err
+		}
+		if targetKind != "" {
+			suite.Target = targetKind
+			if err := suite.Validate(); err != nil {
+				return err
+			}
+		}
+
+		target, cleanup, err := buildTarget(ctx, suite)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		var opts []eval.RunnerOption
+		if embedder, err := services.GetGlobalEmbeddingService(ctx); err == nil {
+			opts = append(opts, eval.WithEmbedder(embedder))
+		}
+		if !noJudge {
+			if judge, err := services.GetGlobalLLM(); err == nil {
+				opts = append(opts, eval.WithJudge(judge))
+			}
+		}
+		opts = append(opts, eval.WithProgress(func(res eval.CaseResult) {
+			status := "✅"
+			switch {
+			case res.Error != "":
+				status = "💥"
+			case res.Skipped:
+				status = "⏭️"
+			case !res.Passed:
+				status = "❌"
+			}
+			fmt.Fprintf(os.Stderr, "%s %s (%dms)\n", status, res.ID, res.LatencyMs)
+		}))
+
+		report, err := eval.NewRunner(target, opts...).Run(ctx, suite)
+		if err != nil {
+			return fmt.Errorf("evaluation failed: %w", err)
+		}
+
+		if baselinePath != "" {
+			baseline, err := eval.LoadReport(baselinePath)
+			if err != nil {
+				return err
+			}
+			report.Compare(baseline)
+		}
+
+		if saveBaseline != "" {
+			if err := report.SaveReport(saveBaseline); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "💾 Baseline saved to %s\n", saveBaseline)
+		}
+
+		if err := writeReport(report); err != nil {
+			return err
+		}
+		printSummary(os.Stderr, report)
+
+		if report.Comparison.HasRegressions() {
+			return fmt.Errorf("%d case(s) regressed against baseline", len(report.Comparison.Regressions))
+		}
+		return nil
+	},
+}
+
+func init() {
+	runCmd.Flags().StringVar(&outputFormat, "format", "json", "report format: json or junit")
+	runCmd.Flags().StringVarP(&outputPath, "output", "o", "", "write the report to a file instead of stdout")
+	runCmd.Flags().StringVar(&baselinePath, "baseline", "", "compare against a saved JSON baseline report")
+	runCmd.Flags().StringVar(&saveBaseline, "save-baseline", "", "save this run as a JSON baseline report")
+	runCmd.Flags().StringVar(&targetKind, "target", "", "override the suite target: agent or rag")
+	runCmd.Flags().BoolVar(&noJudge, "no-judge", false, "skip LLM-judge rubric grading")
+	Cmd.AddCommand(runCmd)
+}
+
+// buildTarget creates the agent or RAG target for the suite
+func buildTarget(ctx context.Context, suite *eval.Suite) (eval.Target, func(), error) {
+	switch suite.Target {
+	case eval.TargetRAG:
+		if Cfg == nil {
+			return nil, nil, fmt.Errorf("configuration not loaded")
+		}
+		llm, err := services.GetGlobalLLM()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get global LLM service: %w", err)
+		}
+		embedder, err := services.GetGlobalEmbeddingService(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get global embedder service: %w", err)
+		}
+		extractor, _ := llm.(domain.MetadataExtractor)
+		client, err := rag.NewClient(Cfg, embedder, llm, extractor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create RAG client: %w", err)
+		}
+		return eval.NewRAGTarget(client, nil, suite.Model), func() { client.Close() }, nil
+
+	default:
+		svc, err := agent.New("AgentGo Eval").
+			WithRAG().
+			WithMCP().
+			WithSkills().
+			Build()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to init agent: %w", err)
+		}
+		return eval.NewAgentTarget(svc), func() { svc.Close() }, nil
+	}
+}
+
+// writeReport writes the report in the selected format
+func writeReport(report *eval.Report) error {
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch outputFormat {
+	case "json":
+		return report.WriteJSON(w)
+	case "junit":
+		return report.WriteJUnit(w)
+	default:
+		return fmt.Errorf("unsupported format: %s (use json or junit)", outputFormat)
+	}
+}
+
+// printSummary prints a human-readable summary and baseline diff
+func printSummary(w io.Writer, report *eval.Report) {
+	s := report.Summary
+	fmt.Fprintf(w, "\n📊 %s: %d/%d passed (%.0f%%), %d failed, %d errored, %d skipped\n",
+		report.Suite, s.Passed, s.Total-s.Skipped, s.PassRate*100, s.Failed, s.Errored, s.Skipped)
+	fmt.Fprintf(w, "   recall=%.2f mrr=%.2f tool_precision=%.2f judge=%.2f\n",
+		s.MeanRecall, s.MeanMRR, s.MeanToolPrecision, s.MeanJudgeScore)
+	fmt.Fprintf(w, "   latency mean=%.0fms p95=%dms | tokens=%d cost=$%.4f\n",
+		s.MeanLatencyMs, s.P95LatencyMs, s.TotalTokens, s.TotalCost)
+
+	cmp := report.Comparison
+	if cmp == nil {
+		return
+	}
+	fmt.Fprintf(w, "\n🔍 Baseline comparison: %+.0f%% pass rate\n", cmp.MetricDeltas["pass_rate"]*100)
+	for _, d := range cmp.Regressions {
+		fmt.Fprintf(w, "   ❌ %s regressed (%s)\n", d.ID, d.Detail)
+	}
+	for _, d := range cmp.Fixes {
+		fmt.Fprintf(w, "   ✅ %s fixed\n", d.ID)
+	}
+	for _, id := range cmp.Added {
+		fmt.Fprintf(w, "   ➕ %s added\n", id)
+	}
+	for _, id := range cmp.Removed {
+		fmt.Fprintf(w, "   ➖ %s removed\n", id)
+	}
+}
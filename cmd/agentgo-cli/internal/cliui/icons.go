@@ -14,5 +14,6 @@ const (
 	TaskStarted = "▶"
 	Success     = "✅"
 	Error       = "❌"
+	Question    = "❓"
 	Goodbye     = "👋"
 )
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/spf13/cobra"
 )
 
@@ -18,13 +19,18 @@ type serverConfigJSON struct {
 
 // serverConfig represents a single server configuration in JSON
 type serverConfig struct {
-	Type       string            `json:"type,omitempty"`
-	Command    string            `json:"command,omitempty"`
-	Args       []string          `json:"args,omitempty"`
-	URL        string            `json:"url,omitempty"`
-	Headers    map[string]string `json:"headers,omitempty"`
-	WorkingDir string            `json:"working_dir,omitempty"`
-	Env        map[string]string `json:"env,omitempty"`
+	Type       string                    `json:"type,omitempty"`
+	Command    string                    `json:"command,omitempty"`
+	Args       []string                  `json:"args,omitempty"`
+	URL        string                    `json:"url,omitempty"`
+	Headers    map[string]string         `json:"headers,omitempty"`
+	WorkingDir string                    `json:"working_dir,omitempty"`
+	Env        map[string]string         `json:"env,omitempty"`
+	OAuth      *mcp.OAuthConfig          `json:"oauth,omitempty"`
+	OpenAPI    *mcp.OpenAPIConfig        `json:"openapi,omitempty"`
+	Policy     mcp.ToolPolicy            `json:"policy,omitempty"`
+	Tools      map[string]mcp.ToolPolicy `json:"tools,omitempty"`
+	Sandbox    *mcp.SandboxConfig        `json:"sandbox,omitempty"`
 }
 
 // mcpAddCmd adds a new MCP server configuration
@@ -61,6 +67,10 @@ Examples:
   # Add an HTTP server with authentication
   agentgo mcp add-json github '{"type":"http","url":"https://api.github.com/mcp","headers":{"Authorization":"Bearer YOUR_TOKEN"}}'
 
+  # Add an HTTP server that uses OAuth, then authorize it
+  agentgo mcp add-json linear '{"type":"http","url":"https://mcp.linear.app/mcp"}'
+  agentgo mcp login linear
+
   # Add a stdio server with environment variables
   agentgo mcp add-json myserver '{"type":"stdio","command":"node","args":["server.js"],"env":{"DEBUG":"true"}}'
 
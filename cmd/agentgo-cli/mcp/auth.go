@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpLoginCmd = &cobra.Command{
+	Use:   "login <server-name>",
+	Short: "Authorize a remote MCP server with OAuth",
+	Long: `Authorize agentgo to use a remote (HTTP or SSE) MCP server that requires OAuth.
+
+The server's authorization server is discovered automatically, agentgo registers
+itself as a client when needed and opens the authorization page in your browser.
+Tokens are stored in the user config directory and refreshed automatically.
+
+Examples:
+  agentgo mcp login linear
+  agentgo mcp login linear --no-browser`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMCPLogin,
+}
+
+var mcpLogoutCmd = &cobra.Command{
+	Use:   "logout <server-name>",
+	Short: "Remove the stored OAuth tokens of an MCP server",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMCPLogout,
+}
+
+var (
+	loginNoBrowser bool
+	loginTimeout   time.Duration
+)
+
+func init() {
+	MCPCmd.AddCommand(mcpLoginCmd)
+	MCPCmd.AddCommand(mcpLogoutCmd)
+
+	mcpLoginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Print the authorization URL instead of opening a browser")
+	mcpLoginCmd.Flags().DurationVar(&loginTimeout, "timeout", 5*time.Minute, "How long to wait for the authorization to complete")
+}
+
+func runMCPLogin(cmd *cobra.Command, args []string) error {
+	if err := loadCfg(); err != nil {
+		return err
+	}
+
+	serverName := args[0]
+	server, err := findServer(serverName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), loginTimeout)
+	defer cancel()
+
+	store := mcp.NewOAuthStore(Cfg.MCP.OAuthDir)
+	login := &mcp.OAuthLogin{
+		Store: store,
+		OpenURL: func(authURL string) error {
+			fmt.Printf("🔐 Authorize %s in your browser:\n   %s\n\n", serverName, authURL)
+			if !loginNoBrowser {
+				if err := openBrowser(authURL); err != nil {
+					fmt.Printf("⚠️  Could not open a browser (%v); open the URL above manually\n", err)
+				}
+			}
+			fmt.Println("Waiting for authorization...")
+			return nil
+		},
+	}
+
+	creds, err := login.Login(ctx, *server)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("authorization of %s timed out after %s", serverName, loginTimeout)
+		}
+		return fmt.Errorf("login to %s failed: %w", serverName, err)
+	}
+
+	fmt.Printf("✅ Logged in to MCP server: %s\n", serverName)
+	fmt.Printf("   Issuer: %s\n", creds.Issuer)
+	if creds.Token != nil && !creds.Token.Expiry.IsZero() {
+		fmt.Printf("   Token expires: %s\n", creds.Token.Expiry.Local().Format(time.RFC1123))
+	}
+	fmt.Printf("   Credentials: %s\n", store.Path(serverName))
+	return nil
+}
+
+func runMCPLogout(cmd *cobra.Command, args []string) error {
+	if err := loadCfg(); err != nil {
+		return err
+	}
+
+	serverName := args[0]
+	if err := mcp.NewOAuthStore(Cfg.MCP.OAuthDir).Delete(serverName); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("Not logged in to %s\n", serverName)
+			return nil
+		}
+		return fmt.Errorf("failed to remove credentials: %w", err)
+	}
+	fmt.Printf("✅ Logged out of MCP server: %s\n", serverName)
+	return nil
+}
+
+func loadCfg() error {
+	if Cfg != nil {
+		return nil
+	}
+	var err error
+	Cfg, err = config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return nil
+}
+
+// findServer returns the configuration of a remote server by name
+func findServer(name string) (*mcp.ServerConfig, error) {
+	for _, s := range Cfg.MCP.GetLoadedServers() {
+		if s.Name != name {
+			continue
+		}
+		if s.Type != mcp.ServerTypeHTTP && s.Type != mcp.ServerTypeSSE {
+			return nil, fmt.Errorf("server %s is a %s server; only HTTP and SSE servers use OAuth", name, s.Type)
+		}
+		return &s, nil
+	}
+	return nil, fmt.Errorf("server %s not found in configuration", name)
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpGatewayCmd = &cobra.Command{
+	Use:   "gateway",
+	Short: "Run a shared gateway that owns the MCP servers",
+	Long: `Run the configured MCP servers once and share them with every AgentGo process.
+
+The gateway serves the tools, resources and prompts of all servers over
+streamable HTTP. Tools are namespaced per server (mcp_<server>_<tool>), the
+filesystem guard is applied centrally and each client can be limited to some
+servers and tools with [[mcp.gateway.clients]] entries in the config.
+
+Point other processes at it with:
+  [mcp.gateway]
+  url = "http://127.0.0.1:8766/mcp"
+  token = "..."        # when clients are configured
+
+or AgentGo_MCP_GATEWAY_URL=http://127.0.0.1:8766/mcp.
+
+Examples:
+  agentgo mcp gateway
+  agentgo mcp gateway --addr 127.0.0.1:9000`,
+	RunE: runMCPGateway,
+}
+
+func init() {
+	MCPCmd.AddCommand(mcpGatewayCmd)
+
+	mcpGatewayCmd.Flags().String("addr", "", "Listen address (default mcp.gateway.addr, 127.0.0.1:8766)")
+}
+
+func runMCPGateway(cmd *cobra.Command, args []string) error {
+	if err := loadCfg(); err != nil {
+		return err
+	}
+	addr, _ := cmd.Flags().GetString("addr")
+	if addr == "" {
+		addr = Cfg.MCP.Gateway.Addr
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{}))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	gateway, err := mcp.NewGateway(&Cfg.MCP, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create MCP gateway: %w", err)
+	}
+	defer gateway.Close()
+	gateway.Start(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", gateway.Handler())
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Close()
+	}()
+
+	fmt.Printf("🔌 MCP gateway serving %d server(s) on http://%s/mcp\n", gateway.Manager().GetServerCount(), addr)
+	if len(Cfg.MCP.Gateway.Clients) == 0 {
+		fmt.Println("   No clients configured: every local client may use every server")
+	}
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpAddOpenAPICmd = &cobra.Command{
+	Use:   "add-openapi <server-name> <spec>",
+	Short: "Serve the operations of an HTTP API as MCP tools",
+	Long: `Add an HTTP API described by an OpenAPI 3.x or Swagger 2.0 spec as an MCP server.
+
+Every operation becomes a tool whose input schema is built from its parameters
+and request body, with $refs and allOf/oneOf schemas resolved. GET operations
+with a cursor are paged through automatically, up to --max-pages pages.
+
+The spec may be a URL or a file, JSON or YAML. Secrets may reference
+environment variables as ${NAME}; quote them so the shell leaves them alone.
+API key names and OAuth token URLs default to the spec's security schemes.
+Multipart file fields only upload files under an --upload-dir.
+
+Examples:
+  agentgo mcp add-openapi petstore https://petstore3.swagger.io/api/v3/openapi.json
+
+  # Only some operations, with a bearer token
+  agentgo mcp add-openapi github ./github.yaml --tag issues --exclude 'delete*' --auth bearer --token '${GITHUB_TOKEN}'
+
+  # OAuth client credentials
+  agentgo mcp add-openapi billing https://billing.internal/openapi.yaml \
+    --auth oauth2 --client-id agentgo --client-secret '${BILLING_SECRET}' --scope invoices.read`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMCPAddOpenAPI,
+}
+
+var (
+	openAPIBaseURL      string
+	openAPITags         []string
+	openAPIOperations   []string
+	openAPIExclude      []string
+	openAPIHeaders      []string
+	openAPIMaxPages     int
+	openAPIAuthType     string
+	openAPIToken        string
+	openAPIKeyName      string
+	openAPIKeyIn        string
+	openAPIUsername     string
+	openAPIPassword     string
+	openAPIClientID     string
+	openAPIClientSecret string
+	openAPITokenURL     string
+	openAPIScopes       []string
+	openAPIUploadDirs   []string
+)
+
+func init() {
+	MCPCmd.AddCommand(mcpAddOpenAPICmd)
+
+	flags := mcpAddOpenAPICmd.Flags()
+	flags.StringVar(&openAPIBaseURL, "base-url", "", "API base URL (default: the first server of the spec)")
+	flags.StringSliceVar(&openAPITags, "tag", nil, "Only serve operations with this tag (repeatable)")
+	flags.StringSliceVar(&openAPIOperations, "operation", nil, "Only serve this operationId, globs allowed (repeatable)")
+	flags.StringSliceVar(&openAPIExclude, "exclude", nil, "Leave out this operationId, globs allowed (repeatable)")
+	flags.StringArrayVar(&openAPIHeaders, "header", nil, "Header sent with every request, as 'Name: value' (repeatable)")
+	flags.IntVar(&openAPIMaxPages, "max-pages", 0, "Pages fetched per paginated call (default 5, 1 disables paging)")
+	flags.StringVar(&openAPIAuthType, "auth", "", "Auth type: bearer, basic, apikey or oauth2")
+	flags.StringVar(&openAPIToken, "token", "", "Bearer token or API key")
+	flags.StringVar(&openAPIKeyName, "api-key-name", "", "API key parameter name (default from the spec)")
+	flags.StringVar(&openAPIKeyIn, "api-key-in", "", "Where the API key goes: header, query or cookie")
+	flags.StringVar(&openAPIUsername, "username", "", "Username for basic auth")
+	flags.StringVar(&openAPIPassword, "password", "", "Password for basic auth")
+	flags.StringVar(&openAPIClientID, "client-id", "", "OAuth client ID")
+	flags.StringVar(&openAPIClientSecret, "client-secret", "", "OAuth client secret")
+	flags.StringVar(&openAPITokenURL, "token-url", "", "OAuth token URL (default from the spec)")
+	flags.StringSliceVar(&openAPIScopes, "scope", nil, "OAuth scope (repeatable)")
+	flags.StringSliceVar(&openAPIUploadDirs, "upload-dir", nil, "Directory file uploads may be read from (repeatable)")
+}
+
+func runMCPAddOpenAPI(cmd *cobra.Command, args []string) error {
+	if err := loadCfg(); err != nil {
+		return err
+	}
+
+	serverName, spec := args[0], args[1]
+	if !strings.Contains(spec, "://") {
+		// mcpServers.json resolves relative files against its own directory
+		abs, err := filepath.Abs(spec)
+		if err != nil {
+			return err
+		}
+		spec = abs
+	}
+
+	openapi := &mcp.OpenAPIConfig{
+		Spec:       spec,
+		BaseURL:    openAPIBaseURL,
+		Tags:       openAPITags,
+		Operations: openAPIOperations,
+		Exclude:    openAPIExclude,
+	}
+	for _, dir := range openAPIUploadDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		openapi.UploadDirs = append(openapi.UploadDirs, abs)
+	}
+	for _, header := range openAPIHeaders {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, want 'Name: value'", header)
+		}
+		if openapi.Headers == nil {
+			openapi.Headers = map[string]string{}
+		}
+		openapi.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	if openAPIMaxPages > 0 {
+		openapi.Pagination = &mcp.OpenAPIPagination{MaxPages: openAPIMaxPages}
+	}
+	if openAPIAuthType != "" {
+		openapi.Auth = &mcp.OpenAPIAuth{
+			Type:         openAPIAuthType,
+			Token:        openAPIToken,
+			Name:         openAPIKeyName,
+			In:           openAPIKeyIn,
+			Username:     openAPIUsername,
+			Password:     openAPIPassword,
+			ClientID:     openAPIClientID,
+			ClientSecret: openAPIClientSecret,
+			TokenURL:     openAPITokenURL,
+			Scopes:       openAPIScopes,
+		}
+	}
+
+	// Load the spec now, so mistakes show up here and not at the first call
+	bridge, err := mcp.NewOpenAPIBridge(openapi)
+	if err != nil {
+		return err
+	}
+
+	configFile := getConfigFilePath()
+	var jsonConfig serverConfigJSON
+	if data, err := os.ReadFile(configFile); err == nil {
+		if err := json.Unmarshal(data, &jsonConfig); err != nil {
+			return fmt.Errorf("failed to parse existing config: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if _, exists := jsonConfig.MCPServers[serverName]; exists {
+		return fmt.Errorf("server %s already exists. Use 'agentgo mcp remove %s' first", serverName, serverName)
+	}
+	if jsonConfig.MCPServers == nil {
+		jsonConfig.MCPServers = make(map[string]serverConfig)
+	}
+	jsonConfig.MCPServers[serverName] = serverConfig{Type: string(mcp.ServerTypeOpenAPI), OpenAPI: openapi}
+	if err := saveConfigFile(configFile, &jsonConfig); err != nil {
+		return err
+	}
+
+	tools := bridge.Tools()
+	fmt.Printf("✅ Added OpenAPI server: %s\n", serverName)
+	fmt.Printf("   Spec: %s\n", spec)
+	fmt.Printf("   Tools: %d\n", len(tools))
+	for i, tool := range tools {
+		if i == 10 {
+			fmt.Printf("     ... and %d more\n", len(tools)-i)
+			break
+		}
+		fmt.Printf("     mcp_%s_%s\n", serverName, tool.Name)
+	}
+	fmt.Printf("   Config: %s\n\n", configFile)
+	fmt.Printf("💡 Limit calls with a \"policy\" entry, test with: agentgo mcp list\n")
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcpserver"
+	"github.com/liliang-cn/agent-go/pkg/prompt"
+	"github.com/liliang-cn/agent-go/pkg/rag"
+	"github.com/liliang-cn/agent-go/pkg/services"
+	"github.com/liliang-cn/agent-go/pkg/skills"
+	"github.com/spf13/cobra"
+)
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve AgentGo as an MCP server",
+	Long: `Expose AgentGo to other MCP hosts (editors, agent frameworks).
+
+Published capabilities:
+  rag      rag_query, rag_ingest and rag_list tools; documents as resources
+  memory   memory_search and memory_add tools
+  skills   one skill_<id> tool per enabled skill
+  agents   run_agent and squad_go tools
+  prompts  prompt templates as MCP prompts
+
+Examples:
+  # Launched by an MCP host over stdio
+  agentgo mcp serve
+
+  # Streamable HTTP on localhost:8765, knowledge base and memory only
+  agentgo mcp serve --transport http --token "$AGENTGO_MCP_TOKEN" --expose rag,memory
+
+HTTP clients must send "Authorization: Bearer <token>" with the --token
+value or a memory.access user token; the server refuses to start over HTTP
+without one. Memory tools act for the matching memory.access user, and are
+anonymous under --token.
+
+rag_ingest only reads files under --ingest-dir, which defaults to the
+configured mcp.filesystem_dirs.`,
+	RunE: runMCPServe,
+}
+
+var serveCapabilities = []string{"rag", "memory", "skills", "agents", "prompts"}
+
+func init() {
+	MCPCmd.AddCommand(mcpServeCmd)
+
+	mcpServeCmd.Flags().String("transport", "stdio", "Transport: stdio or http")
+	mcpServeCmd.Flags().String("addr", "127.0.0.1:8765", "Listen address for the http transport")
+	mcpServeCmd.Flags().String("name", "agentgo", "Server name reported to clients")
+	mcpServeCmd.Flags().StringSlice("expose", serveCapabilities, "Capabilities to publish: "+strings.Join(serveCapabilities, ", "))
+	mcpServeCmd.Flags().String("prompts-dir", "", "Directory of <key>.md prompt overrides to publish")
+	mcpServeCmd.Flags().StringSlice("ingest-dir", nil, "Directories rag_ingest may read files from (default: mcp.filesystem_dirs)")
+	mcpServeCmd.Flags().String("token", "", "Bearer token HTTP clients must present (memory.access user tokens are accepted too)")
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+	if Cfg == nil {
+		return fmt.Errorf("MCP serve requires loaded configuration")
+	}
+	transport, _ := cmd.Flags().GetString("transport")
+	addr, _ := cmd.Flags().GetString("addr")
+	name, _ := cmd.Flags().GetString("name")
+	expose, _ := cmd.Flags().GetStringSlice("expose")
+	promptsDir, _ := cmd.Flags().GetString("prompts-dir")
+	ingestDirs, _ := cmd.Flags().GetStringSlice("ingest-dir")
+	token, _ := cmd.Flags().GetString("token")
+	if len(ingestDirs) == 0 {
+		ingestDirs = Cfg.MCP.FilesystemDirs
+	}
+
+	if transport != "stdio" && transport != "http" {
+		return fmt.Errorf("unknown transport %q (use stdio or http)", transport)
+	}
+	for _, c := range expose {
+		if !slices.Contains(serveCapabilities, c) {
+			return fmt.Errorf("unknown capability %q (use %s)", c, strings.Join(serveCapabilities, ", "))
+		}
+	}
+	// run_agent, rag_ingest and the skill tools must not be open to anyone
+	// who can reach the port
+	if transport == "http" && token == "" && !slices.ContainsFunc(Cfg.Memory.Access.Users, func(u config.MemoryAccessUser) bool {
+		return u.ID != "" && u.Token != ""
+	}) {
+		return fmt.Errorf("the http transport requires --token or memory.access users with tokens")
+	}
+
+	// stdout carries the protocol on stdio, so everything else goes to stderr
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := mcpserver.Options{
+		Name:       name,
+		Logger:     logger,
+		IngestDirs: ingestDirs,
+		Token:      token,
+		Principal:  domain.SystemMemoryPrincipal(),
+		Authenticate: func(token string) *domain.MemoryPrincipal {
+			user, ok := Cfg.Memory.Access.Authenticate(token)
+			if !ok {
+				return nil
+			}
+			return &domain.MemoryPrincipal{UserID: user.ID, Admin: slices.Contains(Cfg.Memory.Access.Admins, user.ID)}
+		},
+	}
+	var closers []func() error
+	defer func() {
+		for _, c := range closers {
+			_ = c()
+		}
+	}()
+
+	if slices.Contains(expose, "rag") {
+		client, err := newServeRAGClient()
+		if err != nil {
+			logger.Warn("knowledge base not published", "error", err)
+		} else {
+			opts.RAG = client
+			closers = append(closers, client.Close)
+		}
+	}
+	if slices.Contains(expose, "memory") {
+		svc, err := agent.New(name).
+			WithConfig(Cfg).
+			WithDBPath(filepath.Join(Cfg.DataDir(), "agent.db")).
+			WithMemory().
+			Build()
+		if err != nil || svc.MemoryService() == nil {
+			logger.Warn("memory not published", "error", err)
+		} else {
+			opts.Memory = svc.MemoryService()
+			closers = append(closers, svc.Close)
+		}
+	}
+	if slices.Contains(expose, "skills") {
+		svc, err := newServeSkillsService(ctx)
+		if err != nil {
+			logger.Warn("skills not published", "error", err)
+		} else {
+			opts.Skills = svc
+			closers = append(closers, svc.Close)
+		}
+	}
+	if slices.Contains(expose, "agents") {
+		store, err := agent.NewStore(filepath.Join(Cfg.DataDir(), "agent.db"))
+		if err != nil {
+			logger.Warn("agents not published", "error", err)
+		} else {
+			manager := agent.NewSquadManager(store)
+			_ = manager.SeedDefaultMembers()
+			opts.Agents = manager
+		}
+	}
+	if slices.Contains(expose, "prompts") {
+		pm := prompt.NewManager()
+		if promptsDir != "" {
+			if err := pm.LoadFromDir(promptsDir); err != nil {
+				return err
+			}
+		}
+		opts.Prompts = pm
+	}
+
+	server, err := mcpserver.New(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if transport == "stdio" {
+		return server.ServeStdio(ctx)
+	}
+
+	httpServer := &http.Server{Addr: addr, Handler: server.HTTPHandler()}
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Close()
+	}()
+	logger.Info("serving MCP over streamable HTTP", "addr", addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func newServeRAGClient() (*rag.Client, error) {
+	llmService, err := services.GetGlobalLLM()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global LLM service: %w", err)
+	}
+	embedService, err := services.GetGlobalEmbeddingService(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global embedder service: %w", err)
+	}
+	var metadataExtractor domain.MetadataExtractor
+	if extractor, ok := llmService.(domain.MetadataExtractor); ok {
+		metadataExtractor = extractor
+	} else if extractor, ok := embedService.(domain.MetadataExtractor); ok {
+		metadataExtractor = extractor
+	}
+	return rag.NewClient(Cfg, embedService, llmService, metadataExtractor)
+}
+
+func newServeSkillsService(ctx context.Context) (*skills.Service, error) {
+	svc, err := skills.NewService(&skills.Config{
+		Enabled:               Cfg.Skills.Enabled,
+		Paths:                 Cfg.SkillsPaths(),
+		AutoLoad:              Cfg.Skills.AutoLoad,
+		DBPath:                filepath.Join(Cfg.DataDir(), "skills.db"),
+		LogLevel:              "info",
+		AllowCommandInjection: Cfg.Skills.AllowCommandInjection,
+		RequireConfirmation:   Cfg.Skills.RequireConfirmation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	svc.SetStore(skills.NewMemoryStore())
+	if err := svc.LoadAll(ctx); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
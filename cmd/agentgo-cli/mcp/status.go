@@ -4,9 +4,115 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+	"github.com/spf13/cobra"
 )
 
+var mcpStatusCmd = &cobra.Command{
+	Use:   "status [server-name]",
+	Short: "Show configured MCP servers and how they are sandboxed",
+	Long: `Show the configured MCP servers and, for stdio servers, the sandbox they run in.
+
+A stdio server with a "sandbox" entry runs with a scrubbed environment and,
+depending on what this machine provides, under bubblewrap (private filesystem
+and network) or in a private network namespace, with prlimit resource limits.
+The report shows what is actually enforced here. With --verbose it also lists
+the sandbox paths and the runtime environment check.
+
+Examples:
+  agentgo mcp status
+  agentgo mcp status github --verbose`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMCPStatus,
+}
+
+func init() {
+	MCPCmd.AddCommand(mcpStatusCmd)
+}
+
+func runMCPStatus(cmd *cobra.Command, args []string) error {
+	if err := loadCfg(); err != nil {
+		return err
+	}
+
+	servers := Cfg.MCP.GetLoadedServers()
+	if len(args) == 1 {
+		servers = slices.DeleteFunc(servers, func(s mcp.ServerConfig) bool { return s.Name != args[0] })
+		if len(servers) == 0 {
+			return fmt.Errorf("server %s not found in configuration", args[0])
+		}
+	}
+
+	fmt.Printf("📡 MCP Servers (%d)\n", len(servers))
+	for _, server := range servers {
+		printServerStatus(server)
+	}
+
+	if Verbose {
+		PrintMCPEnvironmentStatus(CheckMCPEnvironment())
+	}
+	return nil
+}
+
+func printServerStatus(server mcp.ServerConfig) {
+	switch server.Type {
+	case mcp.ServerTypeStdio:
+		command := strings.Join(append(slices.Clone(server.Command), server.Args...), " ")
+		fmt.Printf("\n   %s (stdio): %s\n", server.Name, command)
+	case mcp.ServerTypeHTTP, mcp.ServerTypeSSE:
+		fmt.Printf("\n   %s (%s): %s\n", server.Name, server.Type, server.URL)
+		return
+	case mcp.ServerTypeOpenAPI:
+		fmt.Printf("\n   %s (openapi): %s\n", server.Name, server.OpenAPI.Spec)
+		return
+	default:
+		fmt.Printf("\n   %s (%s)\n", server.Name, server.Type)
+		return
+	}
+
+	sandbox := server.Sandbox
+	if sandbox == nil {
+		fmt.Println("      🔓 Not sandboxed: runs with your full privileges")
+		return
+	}
+	report := sandbox.Report()
+	fmt.Printf("      🔒 Sandbox: %s\n", report.Backend)
+	if report.Filesystem {
+		fmt.Printf("         Filesystem: system directories, %d read-write and %d read-only paths\n", len(sandbox.Paths), len(sandbox.ReadOnly))
+	} else {
+		fmt.Println("         Filesystem: not restricted")
+	}
+	switch {
+	case sandbox.Network:
+		fmt.Println("         Network: allowed")
+	case report.Network:
+		fmt.Println("         Network: blocked")
+	default:
+		fmt.Println("         Network: not blocked")
+	}
+	if len(report.Limits) > 0 {
+		fmt.Printf("         Limits: %s\n", strings.Join(report.Limits, ", "))
+	}
+	if Verbose {
+		for _, p := range sandbox.Paths {
+			fmt.Printf("         rw %s\n", p)
+		}
+		for _, p := range sandbox.ReadOnly {
+			fmt.Printf("         ro %s\n", p)
+		}
+		fmt.Printf("         Env: %s\n", strings.Join(report.Env, ", "))
+	}
+	for _, problem := range report.Problems {
+		fmt.Printf("         ⚠️  %s\n", problem)
+	}
+	if report.Blocked {
+		fmt.Println("         ❌ The server will not start until these are fixed")
+	}
+}
+
 // MCPEnvironmentStatus represents the status of MCP runtime environments
 type MCPEnvironmentStatus struct {
 	Python struct {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -39,30 +40,96 @@ Memory types:
 	// Add subcommands
 	cmd.AddCommand(newSearchCommand(opts))
 	cmd.AddCommand(newGetCommand(opts))
+	cmd.AddCommand(newHistoryCommand(opts))
+	cmd.AddCommand(newConflictsCommand(opts))
 	cmd.AddCommand(newAddCommand(opts))
 	cmd.AddCommand(newUpdateCommand(opts))
 	cmd.AddCommand(newListCommand(opts))
 	cmd.AddCommand(newDeleteCommand(opts))
 	cmd.AddCommand(newRebuildCommand(opts))
+	cmd.AddCommand(newGCCommand(opts))
+	cmd.AddCommand(newExportCommand(opts))
+	cmd.AddCommand(newImportCommand(opts))
+	cmd.AddCommand(newEpisodesCommand(opts))
+	cmd.AddCommand(newFeedbackCommand(opts))
+	cmd.AddCommand(newGraphCommand(opts))
+	cmd.AddCommand(newSuggestionsCommand(opts))
+	cmd.AddCommand(newLogCommand(opts))
+	cmd.AddCommand(newDiffCommand(opts))
+	cmd.AddCommand(newRevertCommand(opts))
 
 	return cmd
 }
 
 // newSearchCommand creates the search subcommand
 func newSearchCommand(opts *CommandOptions) *cobra.Command {
-	var limit int
+	var (
+		limit      int
+		asOf       string
+		explain    bool
+		session    string
+		scoring    map[string]string
+		jsonOutput bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "search <query>",
 		Short: "Search memories by query",
-		Args:  cobra.ExactArgs(1),
+		Long: `Search memories by query.
+
+With --as-of, only memories the agent believed at that time are returned,
+including ones that have since been superseded.
+
+With --explain, the query runs through the same retrieval pipeline the
+agent uses and every candidate is shown with the sources that found it, its
+vector/BM25/RRF scores, the scorer's factors, its scope weight and why it was
+kept or dropped. --scoring overrides scoring settings for this query only.
+
+Example:
+  agentgo memory search "deploy target" --as-of 2026-03-01
+  agentgo memory search "deploy target" --as-of 2026-03-01T15:04:05Z
+  agentgo memory search "deploy target" --explain --scoring recency_weight=0.6,half_life_days=7`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			svc, err := createMemoryService(opts)
 			if err != nil {
 				return err
 			}
 
-			memories, err := svc.Search(cmd.Context(), args[0], limit)
+			if explain {
+				if asOf != "" {
+					return fmt.Errorf("--explain cannot be combined with --as-of")
+				}
+				retrieveOpts := &memory.RetrieveOptions{MaxMemories: limit}
+				if len(scoring) > 0 {
+					retrieveOpts.Scoring, err = memory.ApplyScoringOverrides(svc.ScoringConfig(), scoring)
+					if err != nil {
+						return err
+					}
+				}
+				_, ex, err := svc.Explain(cmd.Context(), args[0], session, retrieveOpts)
+				if err != nil {
+					return fmt.Errorf("explain failed: %w", err)
+				}
+				if jsonOutput {
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+					return enc.Encode(ex)
+				}
+				printExplanation(ex)
+				return nil
+			}
+
+			var memories []*domain.MemoryWithScore
+			if asOf != "" {
+				at, perr := parseTimeFlag(asOf)
+				if perr != nil {
+					return perr
+				}
+				memories, err = svc.SearchAsOf(cmd.Context(), args[0], at, limit)
+			} else {
+				memories, err = svc.Search(cmd.Context(), args[0], limit)
+			}
 			if err != nil {
 				return fmt.Errorf("search failed: %w", err)
 			}
@@ -78,6 +145,9 @@ func newSearchCommand(opts *CommandOptions) *cobra.Command {
 				fmt.Printf("    ID: %s\n", mem.ID)
 				fmt.Printf("    Content: %s\n", mem.Content)
 				fmt.Printf("    Importance: %.2f | Access Count: %d\n", mem.Importance, mem.AccessCount)
+				if asOf != "" {
+					fmt.Printf("    Valid: %s\n", validityRange(mem.Memory))
+				}
 				fmt.Println()
 			}
 
@@ -86,10 +156,71 @@ func newSearchCommand(opts *CommandOptions) *cobra.Command {
 	}
 
 	cmd.Flags().IntVarP(&limit, "limit", "l", 5, "Maximum number of results")
+	cmd.Flags().StringVar(&asOf, "as-of", "", "Search memories as believed at this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Explain how each candidate memory was retrieved and scored")
+	cmd.Flags().StringVar(&session, "session", "", "Session ID to retrieve for (with --explain)")
+	cmd.Flags().StringToStringVar(&scoring, "scoring", nil, "Scoring overrides for this query, e.g. recency_weight=0.5 (with --explain)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the explanation as JSON (with --explain)")
 
 	return cmd
 }
 
+// printExplanation renders a retrieval explanation for the terminal
+func printExplanation(ex *memory.RetrievalExplanation) {
+	fmt.Printf("Query: %s\n", ex.Query)
+	if ex.QueryType != "" {
+		fmt.Printf("Classifier: %s (needs memory: %v)\n", ex.QueryType, ex.NeedsMemory)
+	}
+	if !ex.NeedsMemory {
+		fmt.Println("Retrieval skipped.")
+		return
+	}
+	if ex.Logic != "" {
+		fmt.Printf("Navigator: %s\n", truncateString(ex.Logic, 300))
+	}
+	if ex.Redacted > 0 {
+		fmt.Printf("Redacted: %d memories hidden by access control\n", ex.Redacted)
+	}
+	if len(ex.Memories) == 0 {
+		fmt.Println("\nNo candidate memories.")
+		return
+	}
+
+	fmt.Println()
+	for _, m := range ex.Memories {
+		if m.Selected {
+			fmt.Printf("#%d ", m.Rank)
+		} else {
+			fmt.Printf("-- dropped (%s) ", m.Excluded)
+		}
+		fmt.Printf("[%s] %s\n", m.Type, m.MemoryID)
+		fmt.Printf("    %s\n", truncateString(m.Content, 120))
+		fmt.Printf("    sources: %s | scope: %s (weight %.2f)\n", strings.Join(m.Sources, ", "), m.Scope, m.ScopeWeight)
+
+		var ranks []string
+		if m.VectorRank > 0 {
+			ranks = append(ranks, fmt.Sprintf("vector %.3f (#%d)", m.VectorScore, m.VectorRank))
+		}
+		if m.TextRank > 0 {
+			ranks = append(ranks, fmt.Sprintf("bm25 %.3f (#%d)", m.TextScore, m.TextRank))
+		}
+		if m.NavigatorRank > 0 {
+			ranks = append(ranks, fmt.Sprintf("navigator #%d", m.NavigatorRank))
+		}
+		if m.RRFRank > 0 {
+			ranks = append(ranks, fmt.Sprintf("rrf %.4f (#%d)", m.RRFScore, m.RRFRank))
+		}
+		if len(ranks) > 0 {
+			fmt.Printf("    retrieval: %s\n", strings.Join(ranks, " | "))
+		}
+		if sc := m.Scoring; sc != nil {
+			fmt.Printf("    score: base %.3f → final %.3f (recency %.2f, importance %.2f, length %.2f, access %.2f, outcome %.2f)\n",
+				sc.Base, sc.Final, sc.Recency, sc.Importance, sc.Length, sc.Access, sc.Outcome)
+		}
+		fmt.Println()
+	}
+}
+
 // newGetCommand creates the get subcommand
 func newGetCommand(opts *CommandOptions) *cobra.Command {
 	cmd := &cobra.Command{
@@ -116,6 +247,146 @@ func newGetCommand(opts *CommandOptions) *cobra.Command {
 	return cmd
 }
 
+// newHistoryCommand creates the history subcommand
+func newHistoryCommand(opts *CommandOptions) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "history <memory-id>",
+		Short: "Show how a memory changed over time",
+		Long: `Show every version of a memory, oldest first, following the chain of
+memories that superseded one another, with each version's validity window
+and revision log.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			versions, err := svc.History(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("history failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(versions)
+			}
+
+			fmt.Printf("History of %s (%d versions):\n\n", args[0], len(versions))
+			for i, mem := range versions {
+				marker := ""
+				if mem.ID == args[0] {
+					marker = " *"
+				}
+				fmt.Printf("[%d] %s%s\n", i+1, mem.ID, marker)
+				fmt.Printf("    Type: %s\n", mem.Type)
+				fmt.Printf("    Valid: %s\n", validityRange(mem))
+				fmt.Printf("    Content: %s\n", truncateString(mem.Content, 200))
+				if mem.SupersededBy != "" {
+					fmt.Printf("    Superseded by: %s\n", mem.SupersededBy)
+				}
+				for _, rev := range mem.RevisionHistory {
+					fmt.Printf("    - %s %s: %s\n", rev.At.Format(time.RFC3339), rev.By, rev.Summary)
+				}
+				fmt.Println()
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// newConflictsCommand creates the conflicts subcommand
+func newConflictsCommand(opts *CommandOptions) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "Review contradicting memories",
+		Long: `List memories flagged as contradicting each other. When a new fact or
+preference contradicts an existing one, both are kept with lowered confidence
+until a human picks the winner with "memory conflicts resolve".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			conflicts, err := svc.Conflicts(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("list conflicts failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(conflicts)
+			}
+
+			if len(conflicts) == 0 {
+				fmt.Println("No open conflicts.")
+				return nil
+			}
+
+			fmt.Printf("Open conflicts (%d):\n\n", len(conflicts))
+			for i, c := range conflicts {
+				fmt.Printf("[%d] detected %s\n", i+1, c.DetectedAt.Format("2006-01-02 15:04"))
+				if c.Reason != "" {
+					fmt.Printf("    Reason: %s\n", c.Reason)
+				}
+				for _, mem := range c.Memories {
+					fmt.Printf("    - %s (%s, confidence %.2f): %s\n", mem.ID, mem.Type, mem.Confidence, truncateString(mem.Content, 100))
+				}
+				fmt.Println()
+			}
+			fmt.Println("Resolve with: agentgo memory conflicts resolve <winning-memory-id>")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	cmd.AddCommand(newResolveConflictCommand(opts))
+
+	return cmd
+}
+
+// newResolveConflictCommand creates the conflicts resolve subcommand
+func newResolveConflictCommand(opts *CommandOptions) *cobra.Command {
+	var by string
+
+	cmd := &cobra.Command{
+		Use:   "resolve <winning-memory-id>",
+		Short: "Keep one side of a conflict and supersede the others",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			if err := svc.ResolveConflict(cmd.Context(), args[0], by); err != nil {
+				return fmt.Errorf("resolve failed: %w", err)
+			}
+
+			fmt.Printf("Conflict resolved in favour of %s.\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "user", "Reviewer name recorded in the revision history")
+
+	return cmd
+}
+
 // newAddCommand creates the add subcommand
 func newAddCommand(opts *CommandOptions) *cobra.Command {
 	var (
@@ -302,10 +573,13 @@ func createMemoryService(opts *CommandOptions) (*memory.Service, error) {
 	switch storeType {
 	case "vector":
 		memStore, err = store.NewMemoryStore(path)
-	case "file", "hybrid":
-		memStore, err = store.NewFileMemoryStore(path)
 	default:
-		memStore, err = store.NewFileMemoryStore(path)
+		var fileStore *store.FileMemoryStore
+		fileStore, err = store.NewFileMemoryStore(path)
+		if err == nil && Cfg != nil && Cfg.Memory.Git.Enabled {
+			err = fileStore.EnableGit()
+		}
+		memStore = fileStore
 	}
 
 	if err != nil {
@@ -342,6 +616,9 @@ func createMemoryService(opts *CommandOptions) (*memory.Service, error) {
 
 	// Create service with LLM/embedder if available
 	memCfg := memory.DefaultConfig()
+	if Cfg != nil && Cfg.Memory.Episodes.MinSimilarity > 0 {
+		memCfg.Episodes.MinSimilarity = Cfg.Memory.Episodes.MinSimilarity
+	}
 	memSvc = memory.NewService(memStore, llm, embedder, memCfg)
 
 	// If vector store and embedder available, set shadow index for hybrid search
@@ -349,6 +626,58 @@ func createMemoryService(opts *CommandOptions) (*memory.Service, error) {
 		// The vector store itself can be used as shadow index
 		memSvc.SetShadowIndex(memStore)
 	}
+	// Hybrid agents keep a vector copy of the file memories next to them
+	if storeType == "hybrid" && embedder != nil && Cfg != nil {
+		if shadow, err := store.NewMemoryStore(filepath.Join(Cfg.DataDir(), "agentgo.db")); err == nil {
+			_ = shadow.InitSchema(context.Background())
+			memSvc.SetShadowIndex(shadow)
+		}
+	}
+
+	// The CLI has no RAG processor, so the entity graph always uses the file backend
+	if Cfg != nil && Cfg.Memory.Graph.Enabled {
+		graphPath := Cfg.Memory.Graph.Path
+		if graphPath == "" {
+			graphPath = filepath.Join(path, "graph.json")
+			if storeType == "vector" {
+				graphPath = filepath.Join(filepath.Dir(path), "memory_graph.json")
+			}
+		}
+		graphStore, err := store.NewFileGraphStore(graphPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open memory graph: %w", err)
+		}
+		if err := memSvc.SetGraphStore(graphStore); err != nil {
+			return nil, err
+		}
+	}
+
+	// Always open the suggestion store so pending suggestions can be reviewed
+	// here whatever mode the agent runs in
+	policy := memory.SuggestionPolicy{}
+	suggestionPath := filepath.Join(path, "suggestions.json")
+	if storeType == "vector" {
+		suggestionPath = filepath.Join(filepath.Dir(path), "memory_suggestions.json")
+	}
+	if Cfg != nil {
+		sc := Cfg.Memory.Suggestions
+		policy = memory.SuggestionPolicy{
+			Mode:                 memory.SuggestionMode(sc.Mode),
+			Timeout:              sc.Timeout,
+			OnTimeout:            sc.OnTimeout,
+			AutoAcceptConfidence: sc.AutoAcceptConfidence,
+		}
+		if sc.Path != "" {
+			suggestionPath = sc.Path
+		}
+	}
+	suggestionStore, err := store.NewFileSuggestionStore(suggestionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory suggestions: %w", err)
+	}
+	if err := memSvc.SetSuggestionPolicy(policy, suggestionStore); err != nil {
+		return nil, err
+	}
 
 	return memSvc, nil
 }
@@ -363,6 +692,27 @@ func isValidMemoryType(t string) bool {
 	}
 }
 
+// parseTimeFlag parses an RFC3339 timestamp or a YYYY-MM-DD date. A bare date
+// means the end of that day in local time.
+func parseTimeFlag(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339 or YYYY-MM-DD)", value)
+}
+
+// validityRange formats a memory's validity window
+func validityRange(mem *domain.Memory) string {
+	to := "now"
+	if mem.ValidTo != nil {
+		to = mem.ValidTo.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s → %s", mem.EffectiveFrom().Format(time.RFC3339), to)
+}
+
 // truncateString truncates a string to a maximum length
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -429,3 +779,760 @@ Example:
 		},
 	}
 }
+
+// newGCCommand creates the "memory gc" subcommand
+func newGCCommand(opts *CommandOptions) *cobra.Command {
+	var (
+		dryRun       bool
+		jsonOutput   bool
+		threshold    float64
+		minAgeDays   int
+		scopeQuota   int
+		purgeAfter   int
+		noReflect    bool
+		noCompaction bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Archive decayed memories, enforce quotas and compact the store",
+		Long: `Run a memory maintenance pass:
+
+  - purge memories that stopped being valid more than --purge-after-days ago
+  - archive memories whose decayed score is below --threshold
+  - archive the lowest scoring memories of scopes over --scope-quota
+  - consolidate facts into observations (file store with an LLM)
+  - compact the file store _index/
+
+Archived memories are closed, not deleted, so "memory history" and
+"memory search --as-of" still find them until they are purged.
+Defaults come from memory.maintenance in the config file.
+
+Example:
+  agentgo memory gc --dry-run
+  agentgo memory gc --scope-quota 500 --purge-after-days 180`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			cfg := memory.DefaultMaintenanceConfig()
+			if Cfg != nil {
+				m := Cfg.Memory.Maintenance
+				cfg.ArchiveThreshold = m.ArchiveThreshold
+				cfg.MinAge = time.Duration(m.MinAgeDays) * 24 * time.Hour
+				cfg.ScopeQuota = m.ScopeQuota
+				cfg.PurgeAfter = time.Duration(m.PurgeAfterDays) * 24 * time.Hour
+				cfg.Consolidate = m.Consolidate
+				cfg.CompactIndex = m.CompactIndex
+			}
+			flags := cmd.Flags()
+			if flags.Changed("threshold") {
+				cfg.ArchiveThreshold = threshold
+			}
+			if flags.Changed("min-age-days") {
+				cfg.MinAge = time.Duration(minAgeDays) * 24 * time.Hour
+			}
+			if flags.Changed("scope-quota") {
+				cfg.ScopeQuota = scopeQuota
+			}
+			if flags.Changed("purge-after-days") {
+				cfg.PurgeAfter = time.Duration(purgeAfter) * 24 * time.Hour
+			}
+			if noReflect {
+				cfg.Consolidate = false
+			}
+			if noCompaction {
+				cfg.CompactIndex = false
+			}
+			cfg.DryRun = dryRun
+
+			report, err := svc.RunMaintenance(cmd.Context(), cfg)
+			if err != nil {
+				return fmt.Errorf("memory gc failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+
+			if report.DryRun {
+				fmt.Printf("Dry run: scanned %d memories, nothing was changed.\n\n", report.Scanned)
+			} else {
+				fmt.Printf("Scanned %d memories.\n\n", report.Scanned)
+			}
+
+			if len(report.Actions) == 0 {
+				fmt.Println("Nothing to do.")
+			}
+			for _, a := range report.Actions {
+				switch {
+				case a.MemoryID != "":
+					fmt.Printf("  %-12s %s (%s, score %.2f): %s\n", a.Kind, a.MemoryID, a.Type, a.Score, truncateString(a.Content, 60))
+					fmt.Printf("  %-12s %s\n", "", a.Reason)
+				default:
+					fmt.Printf("  %-12s %s\n", a.Kind, a.Reason)
+				}
+			}
+
+			fmt.Printf("\nArchived: %d  Over quota: %d  Purged: %d  Merged: %d  Consolidated scopes: %d\n",
+				report.Count(memory.MaintenanceArchive), report.Count(memory.MaintenanceQuota),
+				report.Count(memory.MaintenancePurge), report.Count(memory.MaintenanceMerge),
+				report.Count(memory.MaintenanceConsolidate))
+			for _, e := range report.Errors {
+				fmt.Printf("⚠️  %s\n", e)
+			}
+			if report.DryRun && len(report.Actions) > 0 {
+				fmt.Println("\nRun without --dry-run to apply these changes.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would change without modifying the store")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the report as JSON")
+	cmd.Flags().Float64Var(&threshold, "threshold", 0.35, "Archive memories whose decayed score is below this (0 disables)")
+	cmd.Flags().IntVar(&minAgeDays, "min-age-days", 30, "Never archive memories younger than this")
+	cmd.Flags().IntVar(&scopeQuota, "scope-quota", 0, "Maximum current memories per scope (0 = unlimited)")
+	cmd.Flags().IntVar(&purgeAfter, "purge-after-days", 0, "Delete archived or superseded memories after this many days (0 = keep)")
+	cmd.Flags().BoolVar(&noReflect, "no-consolidate", false, "Skip merging near-duplicates and consolidating facts into observations")
+	cmd.Flags().BoolVar(&noCompaction, "no-compact", false, "Skip compacting the index")
+
+	return cmd
+}
+
+// newExportCommand creates the "memory export" subcommand
+func newExportCommand(opts *CommandOptions) *cobra.Command {
+	var (
+		output      string
+		sessions    []string
+		types       []string
+		currentOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export memories, mental models and bank configs as JSONL",
+		Long: `Write the memory store as versioned JSONL. The export keeps scopes, types,
+evidence links, validity intervals, revision history, mental models and bank
+configs, so it can be imported into a file or SQLite store on any machine.
+Vectors are not exported; use "memory import --reembed" on the target.
+
+Example:
+  agentgo memory export -o memories.jsonl
+  agentgo memory export --session project-x --current-only > project-x.jsonl`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			exportOpts := &memory.ExportOptions{SessionIDs: sessions, CurrentOnly: currentOnly}
+			for _, t := range types {
+				exportOpts.Types = append(exportOpts.Types, domain.MemoryType(t))
+			}
+
+			out := os.Stdout
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			stats, err := svc.Export(cmd.Context(), out, exportOpts)
+			if err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d memories, %d mental models, %d bank configs\n",
+				stats.Memories, stats.MentalModels, stats.Banks)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default stdout)")
+	cmd.Flags().StringSliceVar(&sessions, "session", nil, "Only export these session scopes (\"\" for global)")
+	cmd.Flags().StringSliceVar(&types, "type", nil, "Only export these memory types")
+	cmd.Flags().BoolVar(&currentOnly, "current-only", false, "Skip superseded and archived memories")
+
+	return cmd
+}
+
+// newImportCommand creates the "memory import" subcommand
+func newImportCommand(opts *CommandOptions) *cobra.Command {
+	var (
+		onConflict string
+		remapIDs   bool
+		mapSession []string
+		sessions   []string
+		reembed    bool
+		dryRun     bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Import a JSONL memory export",
+		Long: `Import memories, mental models and bank configs written by "memory export".
+
+Conflict policies for IDs that already exist:
+  skip       keep the existing memory (default)
+  overwrite  replace it with the imported memory
+  merge      keep the newer content and combine evidence, history and counters
+
+Example:
+  agentgo memory import memories.jsonl --db-path ~/.agentgo/data/agentgo.db --reembed
+  agentgo memory import memories.jsonl --remap-ids --map-session project-x=project-y`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := memory.ParseImportConflictPolicy(onConflict)
+			if err != nil {
+				return err
+			}
+
+			sessionMap := make(map[string]string, len(mapSession))
+			for _, pair := range mapSession {
+				from, to, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("invalid --map-session %q (want old=new)", pair)
+				}
+				sessionMap[from] = to
+			}
+
+			in := os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open %s: %w", args[0], err)
+				}
+				defer f.Close()
+				in = f
+			}
+
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			stats, err := svc.Import(cmd.Context(), in, &memory.ImportOptions{
+				OnConflict: policy,
+				RemapIDs:   remapIDs,
+				SessionMap: sessionMap,
+				SessionIDs: sessions,
+				Reembed:    reembed,
+				DryRun:     dryRun,
+			})
+			if err != nil {
+				return fmt.Errorf("import failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			}
+
+			if dryRun {
+				fmt.Println("Dry run: nothing was written.")
+			}
+			fmt.Printf("Memories: %d created, %d overwritten, %d merged, %d skipped\n",
+				stats.Created, stats.Overwritten, stats.Merged, stats.Skipped)
+			fmt.Printf("Mental models: %d  Bank configs: %d\n", stats.MentalModels, stats.Banks)
+			if reembed {
+				fmt.Printf("Re-embedded: %d\n", stats.Reembedded)
+			}
+			if remapIDs {
+				fmt.Printf("Remapped IDs: %d\n", len(stats.IDMap))
+			}
+			for _, e := range stats.Errors {
+				fmt.Printf("⚠️  %s\n", e)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "skip", "What to do with existing IDs: skip, overwrite or merge")
+	cmd.Flags().BoolVar(&remapIDs, "remap-ids", false, "Assign fresh IDs and rewrite links between imported memories")
+	cmd.Flags().StringSliceVar(&mapSession, "map-session", nil, "Move a scope on import (old=new, repeatable)")
+	cmd.Flags().StringSliceVar(&sessions, "session", nil, "Only import these session scopes")
+	cmd.Flags().BoolVar(&reembed, "reembed", false, "Compute fresh vectors with the configured embedder")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported without writing")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the import summary as JSON")
+
+	return cmd
+}
+
+// newEpisodesCommand creates the episodes subcommand
+func newEpisodesCommand(opts *CommandOptions) *cobra.Command {
+	var (
+		limit      int
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "episodes [goal]",
+		Short: "List past agent runs remembered as episodes",
+		Long: `List episodic memories: distilled past agent runs with their approach and
+outcome. With a goal, show the episodes an agent would be reminded of for
+that goal, ranked by similarity and weighted by success.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			var episodes []*domain.MemoryWithScore
+			if len(args) == 1 {
+				episodes, err = svc.RecallEpisodes(cmd.Context(), args[0], limit)
+			} else {
+				var mems []*domain.Memory
+				mems, err = svc.ListEpisodes(cmd.Context(), limit)
+				for _, m := range mems {
+					episodes = append(episodes, &domain.MemoryWithScore{Memory: m})
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("list episodes failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(episodes)
+			}
+
+			if len(episodes) == 0 {
+				fmt.Println("No episodes found.")
+				return nil
+			}
+
+			for i, ep := range episodes {
+				fmt.Printf("[%d] %s  %s", i+1, ep.ID, ep.CreatedAt.Format("2006-01-02 15:04"))
+				if len(args) == 1 {
+					fmt.Printf("  (score %.2f)", ep.Score)
+				}
+				fmt.Println()
+				if session, ok := ep.Metadata[domain.MetaEpisodeSession]; ok && session != "" {
+					fmt.Printf("    Session: %v\n", session)
+				}
+				if feedback, ok := ep.Metadata[domain.MetaEpisodeFeedback]; ok && feedback != "" {
+					fmt.Printf("    Feedback: %v\n", feedback)
+				}
+				for _, line := range strings.Split(ep.Content, "\n") {
+					fmt.Printf("    %s\n", truncateString(line, 200))
+				}
+				fmt.Println()
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 10, "Maximum number of episodes")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// newFeedbackCommand creates the feedback subcommand
+func newFeedbackCommand(opts *CommandOptions) *cobra.Command {
+	var (
+		good    bool
+		bad     bool
+		comment string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "feedback <session-id>",
+		Short: "Rate the agent run recorded under a session",
+		Long: `Attach user feedback to the episode of the most recent run in a session.
+Well-rated runs are recalled ahead of others; badly rated ones are ranked down.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if good == bad {
+				return fmt.Errorf("specify exactly one of --good or --bad")
+			}
+
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			ep, err := svc.AddEpisodeFeedback(cmd.Context(), args[0], good, comment)
+			if err != nil {
+				return fmt.Errorf("feedback failed: %w", err)
+			}
+
+			fmt.Printf("✅ Recorded feedback on episode %s\n", ep.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&good, "good", false, "The run went well")
+	cmd.Flags().BoolVar(&bad, "bad", false, "The run went badly")
+	cmd.Flags().StringVarP(&comment, "comment", "m", "", "Optional comment")
+
+	return cmd
+}
+
+// newGraphCommand creates the graph subcommand
+func newGraphCommand(opts *CommandOptions) *cobra.Command {
+	var (
+		depth      int
+		relations  []string
+		limit      int
+		rebuild    bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "graph [entity]",
+		Short: "Show what memory knows about an entity",
+		Long: `Query the memory entity graph. Entities are resolved by name or alias and the
+graph is walked along typed relations, e.g. "memory graph apollo --relation owns"
+for a project and its owners, together with the stored facts that mention them.
+
+Requires memory.graph.enabled. Use --rebuild to extract entities from all
+existing facts (needs an LLM).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !rebuild && len(args) == 0 {
+				return fmt.Errorf("specify an entity or --rebuild")
+			}
+
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+			if svc.EntityGraph() == nil {
+				return fmt.Errorf("entity graph is not enabled (set memory.graph.enabled: true)")
+			}
+
+			if rebuild {
+				n, err := svc.RebuildEntityGraph(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("graph rebuild failed after %d memories: %w", n, err)
+				}
+				fmt.Printf("✅ Indexed %d memories into the entity graph\n", n)
+				if len(args) == 0 {
+					return nil
+				}
+			}
+
+			res, err := svc.QueryEntity(cmd.Context(), args[0], memory.EntityQuery{
+				Depth:     depth,
+				Relations: relations,
+				MaxFacts:  limit,
+			})
+			if err != nil {
+				return fmt.Errorf("graph query failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(res)
+			}
+
+			fmt.Println(res.Format())
+			if len(res.Entities) > 1 {
+				fmt.Println("\nRelated entities:")
+				for _, e := range res.Entities[1:] {
+					fmt.Printf("  %s", e.Name)
+					if e.Type != "" {
+						fmt.Printf(" (%s)", e.Type)
+					}
+					fmt.Printf("  depth %d\n", e.Depth)
+				}
+			}
+			if len(res.Facts) > 0 {
+				fmt.Println("\nFacts:")
+				for _, m := range res.Facts {
+					fmt.Printf("  [%s] %s\n", m.ID, truncateString(m.Content, 200))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&depth, "depth", "d", 1, "Relation hops to follow")
+	cmd.Flags().StringSliceVarP(&relations, "relation", "r", nil, "Only follow these relation types")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "Maximum number of facts")
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "Extract entities from all existing memories first")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// newSuggestionsCommand creates the suggestions subcommand
+func newSuggestionsCommand(opts *CommandOptions) *cobra.Command {
+	var sessionID string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "suggestions",
+		Short: "Review memories the agent proposed to remember",
+		Long: `List pending memory suggestions. With memory.suggestions.mode set to
+"suggest", memories extracted after a task are queued here instead of being
+stored silently; accept or reject them with "memory suggestions accept|reject".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			suggestions, err := svc.PendingSuggestions(cmd.Context(), sessionID)
+			if err != nil {
+				return fmt.Errorf("list suggestions failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(suggestions)
+			}
+
+			if len(suggestions) == 0 {
+				fmt.Println("No pending suggestions.")
+				return nil
+			}
+
+			fmt.Printf("Pending suggestions (%d):\n\n", len(suggestions))
+			for _, sg := range suggestions {
+				fmt.Printf("%s [%s, %s] confidence %.2f\n", sg.ID, sg.Type, sg.Scope, sg.Confidence)
+				fmt.Printf("    %s\n", truncateString(sg.Content, 200))
+				if sg.ExpiresAt != nil {
+					fmt.Printf("    Expires: %s\n", sg.ExpiresAt.Format("2006-01-02 15:04"))
+				}
+				fmt.Println()
+			}
+			fmt.Println("Decide with: agentgo memory suggestions accept|reject <id>")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionID, "session", "", "Only show suggestions from this session")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	cmd.AddCommand(newDecideSuggestionCommand(opts, true))
+	cmd.AddCommand(newDecideSuggestionCommand(opts, false))
+
+	return cmd
+}
+
+// newDecideSuggestionCommand creates the suggestions accept/reject subcommands
+func newDecideSuggestionCommand(opts *CommandOptions, accept bool) *cobra.Command {
+	var by string
+
+	use, short := "reject <id>...", "Discard suggested memories"
+	if accept {
+		use, short = "accept <id>...", "Store suggested memories"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := createMemoryService(opts)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				if !accept {
+					if err := svc.RejectSuggestion(cmd.Context(), id, by); err != nil {
+						return fmt.Errorf("reject %s failed: %w", id, err)
+					}
+					fmt.Printf("Rejected %s.\n", id)
+					continue
+				}
+				mem, err := svc.AcceptSuggestion(cmd.Context(), id, by)
+				if err != nil {
+					return fmt.Errorf("accept %s failed: %w", id, err)
+				}
+				fmt.Printf("Accepted %s as memory %s.\n", id, mem.ID)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "user", "Reviewer name recorded on the suggestion")
+
+	return cmd
+}
+
+// openVersionedStore opens the file memory store with git versioning on.
+// It works when memory.git.enabled is set or the store already has a repo.
+func openVersionedStore(opts *CommandOptions) (*store.FileMemoryStore, error) {
+	path := opts.DBPath
+	if path == "" && Cfg != nil {
+		path = Cfg.Memory.MemoryPath
+	}
+	if path == "" {
+		path = "./.agentgo/data/memories"
+	}
+	if strings.HasSuffix(path, ".db") || strings.HasSuffix(path, ".sqlite") {
+		return nil, fmt.Errorf("memory history needs the file memory store, not %s", path)
+	}
+
+	enabled := Cfg != nil && Cfg.Memory.Git.Enabled
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		enabled = true
+	}
+	if !enabled {
+		return nil, fmt.Errorf("git versioning is off for %s; set memory.git.enabled: true", path)
+	}
+
+	fileStore, err := store.NewFileMemoryStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store at %q: %w", path, err)
+	}
+	if err := fileStore.EnableGit(); err != nil {
+		return nil, err
+	}
+	return fileStore, nil
+}
+
+// newLogCommand creates the log subcommand
+func newLogCommand(opts *CommandOptions) *cobra.Command {
+	var memoryID string
+	var limit int
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the history of memory changes",
+		Long: `List the commits made to the git-versioned memory store, newest first,
+with who made each change and why. Requires memory.git.enabled.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileStore, err := openVersionedStore(opts)
+			if err != nil {
+				return err
+			}
+
+			commits, err := fileStore.Log(cmd.Context(), memoryID, limit)
+			if err != nil {
+				return fmt.Errorf("log failed: %w", err)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(commits)
+			}
+
+			if len(commits) == 0 {
+				fmt.Println("No memory history yet.")
+				return nil
+			}
+			for _, c := range commits {
+				fmt.Printf("%s %s %-8s %s\n", c.Hash[:8], c.When.Format("2006-01-02 15:04"), c.Actor, c.Subject)
+				if c.Reason != "" {
+					fmt.Printf("         Reason: %s\n", c.Reason)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&memoryID, "memory", "", "Only show changes to this memory ID")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum commits to show (0 = all)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// newDiffCommand creates the diff subcommand
+func newDiffCommand(opts *CommandOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [commit] [commit]",
+		Short: "Show memory changes made by a commit or between two commits",
+		Long: `Print a unified diff of the memory files. With no argument it shows the
+latest change; with one commit, what that commit changed; with two, the
+difference between them.
+
+Example:
+  agentgo memory diff
+  agentgo memory diff 3f2a91c0
+  agentgo memory diff 3f2a91c0 HEAD`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileStore, err := openVersionedStore(opts)
+			if err != nil {
+				return err
+			}
+
+			var from, to string
+			if len(args) > 0 {
+				from = args[0]
+			}
+			if len(args) > 1 {
+				to = args[1]
+			}
+			diff, err := fileStore.Diff(cmd.Context(), from, to)
+			if err != nil {
+				return fmt.Errorf("diff failed: %w", err)
+			}
+			if diff == "" {
+				fmt.Println("No changes.")
+				return nil
+			}
+			fmt.Print(diff)
+			return nil
+		},
+	}
+}
+
+// newRevertCommand creates the revert subcommand
+func newRevertCommand(opts *CommandOptions) *cobra.Command {
+	var by, reason string
+
+	cmd := &cobra.Command{
+		Use:   "revert <commit>",
+		Short: "Undo the memory changes made by a commit",
+		Long: `Restore every memory a commit touched to its state before that commit and
+record the rollback as a new commit. Memories the commit created are removed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileStore, err := openVersionedStore(opts)
+			if err != nil {
+				return err
+			}
+
+			ctx := store.WithMemoryChange(cmd.Context(), by, reason)
+			commit, err := fileStore.Revert(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("revert failed: %w", err)
+			}
+			if err := fileStore.RebuildIndex(cmd.Context()); err != nil {
+				return fmt.Errorf("reverted, but rebuilding the index failed: %w", err)
+			}
+			memSvc, err := createMemoryService(opts)
+			if err != nil {
+				return fmt.Errorf("reverted, but re-indexing the memories failed: %w", err)
+			}
+			if err := memSvc.Reindex(domain.WithSystemMemoryPrincipal(cmd.Context()), commit.MemoryIDs...); err != nil {
+				return fmt.Errorf("reverted, but re-indexing the memories failed: %w", err)
+			}
+
+			fmt.Printf("Reverted %s as %s (%d memories).\n", args[0], commit.Hash[:8], len(commit.MemoryIDs))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "user", "Actor recorded on the revert commit")
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the change is being rolled back")
+
+	return cmd
+}
@@ -7,9 +7,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/liliang-cn/agent-go/pkg/rag/chunker"
 	"github.com/liliang-cn/agent-go/pkg/rag/processor"
 	"github.com/liliang-cn/agent-go/pkg/rag/store"
@@ -26,6 +28,7 @@ var (
 	source          string
 	enhancedExtract bool
 	concurrency     int
+	mcpResource     string
 )
 
 var ingestCmd = &cobra.Command{
@@ -33,11 +36,15 @@ var ingestCmd = &cobra.Command{
 	Short: "Import documents into vector database",
 	Long: `Chunk document content, vectorize and store into local vector database.
 Supports text format files like .txt, .md, .pdf, etc.
-You can also use --text flag to ingest text directly.`,
+You can also use --text flag to ingest text directly, or --mcp to ingest a
+resource of a configured MCP server (e.g. --mcp docs:file:///guide.md).`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if textInput != "" {
+		if textInput != "" && mcpResource != "" {
+			return fmt.Errorf("cannot specify both --text and --mcp")
+		}
+		if textInput != "" || mcpResource != "" {
 			if len(args) > 0 {
-				return fmt.Errorf("cannot specify both file path and --text flag")
+				return fmt.Errorf("cannot specify both file path and --text or --mcp flag")
 			}
 			return nil
 		}
@@ -110,6 +117,9 @@ You can also use --text flag to ingest text directly.`,
 		if textInput != "" {
 			return processText(ctx, processor, textInput)
 		}
+		if mcpResource != "" {
+			return processMCPResource(ctx, processor, mcpResource)
+		}
 
 		// Handle file path
 		if len(args) == 0 {
@@ -251,12 +261,39 @@ func processText(ctx context.Context, p *processor.Service, text string) error {
 	return nil
 }
 
+// processMCPResource starts the MCP server named in ref ("server:uri") and
+// ingests the resource's text
+func processMCPResource(ctx context.Context, p *processor.Service, ref string) error {
+	server, uri, ok := strings.Cut(ref, ":")
+	if !ok || server == "" || uri == "" {
+		return fmt.Errorf("invalid --mcp value %q, expected server:uri", ref)
+	}
+
+	mcpSvc, err := mcp.NewService(&Cfg.MCP, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create MCP service: %w", err)
+	}
+	defer mcpSvc.Close()
+	if err := mcpSvc.StartServers(ctx, []string{server}); err != nil {
+		return err
+	}
+
+	resp, err := mcpSvc.IngestResource(ctx, p, server, uri)
+	if err != nil {
+		return fmt.Errorf("failed to ingest %s: %w", ref, err)
+	}
+
+	fmt.Printf("Successfully ingested %s: %d chunks (ID: %s)\n", ref, resp.ChunkCount, resp.DocumentID)
+	return nil
+}
+
 func init() {
 	ingestCmd.Flags().IntVarP(&chunkSize, "chunk-size", "c", 300, "text chunk size")
 	ingestCmd.Flags().IntVarP(&overlap, "overlap", "o", 50, "chunk overlap size")
 	ingestCmd.Flags().IntVarP(&batchSize, "batch-size", "b", 10, "batch processing size")
 	ingestCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "process directory recursively")
 	ingestCmd.Flags().StringVar(&textInput, "text", "", "ingest text directly instead of from file")
+	ingestCmd.Flags().StringVar(&mcpResource, "mcp", "", "ingest an MCP server resource, as server:uri")
 	ingestCmd.Flags().StringVar(&source, "source", "", "source name for text input (default: text-input)")
 	ingestCmd.Flags().BoolVarP(&enhancedExtract, "enhanced", "e", false, "enable enhanced metadata extraction with temporal refs, entities, and events")
 	ingestCmd.Flags().IntVar(&concurrency, "concurrency", runtime.NumCPU(), "number of concurrent workers for ingestion")
@@ -9,13 +9,16 @@ import (
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/acp"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/agent"
 	cachecmd "github.com/liliang-cn/agent-go/cmd/agentgo-cli/cache"
+	evalcmd "github.com/liliang-cn/agent-go/cmd/agentgo-cli/eval"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/mcp"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/memory"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/ptc"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/rag"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/skills"
 	"github.com/liliang-cn/agent-go/cmd/agentgo-cli/squad"
+	tracecmd "github.com/liliang-cn/agent-go/cmd/agentgo-cli/trace"
 	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
 	agentgolog "github.com/liliang-cn/agent-go/pkg/log"
 	"github.com/liliang-cn/agent-go/pkg/services"
 	"github.com/spf13/cobra"
@@ -79,6 +82,8 @@ var RootCmd = &cobra.Command{
 		ptc.SetSharedVariables(cfg, verbose)
 		acp.SetSharedVariables(cfg, verbose)
 		cachecmd.SetSharedVariables(cfg, verbose)
+		evalcmd.SetSharedVariables(cfg, verbose)
+		tracecmd.SetSharedVariables(cfg, verbose)
 
 		return nil
 	},
@@ -86,11 +91,13 @@ var RootCmd = &cobra.Command{
 
 func commandNeedsGlobalPool(cmd *cobra.Command) bool {
 	path := cmd.CommandPath()
-	return !strings.HasPrefix(path, "agentgo cache")
+	return !strings.HasPrefix(path, "agentgo cache") && !strings.HasPrefix(path, "agentgo trace")
 }
 
+// Execute runs the CLI. Commands act for the local user, so they carry the
+// system memory principal even when memory access control is enabled.
 func Execute() error {
-	return RootCmd.Execute()
+	return RootCmd.ExecuteContext(domain.WithSystemMemoryPrincipal(context.Background()))
 }
 
 // GetRootCmd returns the root cobra command for testing purposes.
@@ -144,6 +151,12 @@ func init() {
 	// Add Cache command
 	RootCmd.AddCommand(cachecmd.Cmd)
 
+	// Add Eval command
+	RootCmd.AddCommand(evalcmd.Cmd)
+
+	// Add Trace command
+	RootCmd.AddCommand(tracecmd.Cmd)
+
 	RootCmd.AddCommand(llmCmd)
 	RootCmd.AddCommand(statusCmd)
 
@@ -94,7 +94,7 @@ var goCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return runSquadMessage(context.Background(), manager, "", strings.Join(args, " "))
+		return runSquadMessage(cmd.Context(), manager, "", strings.Join(args, " "))
 	},
 }
 
@@ -574,7 +574,7 @@ func runInteractiveSquad(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	return runInteractiveSquadChat(context.Background(), manager)
+	return runInteractiveSquadChat(cmd.Context(), manager)
 }
 
 func runInteractiveSquadChat(ctx context.Context, manager *agent.SquadManager) error {
@@ -0,0 +1,332 @@
+// Package trace provides CLI commands for inspecting persisted agent traces
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	Cfg     *config.Config
+	Verbose bool
+
+	listLimit   int
+	listSession string
+	listAgent   string
+	listSince   time.Duration
+	listJSON    bool
+
+	exportFormat string
+	exportOutput string
+)
+
+// SetSharedVariables sets shared variables from root command
+func SetSharedVariables(cfg *config.Config, verbose bool) {
+	Cfg = cfg
+	Verbose = verbose
+}
+
+// Cmd is the trace parent command
+var Cmd = &cobra.Command{
+	Use:   "trace",
+	Short: "Inspect persisted agent traces",
+	Long: `Inspect agent traces persisted to the trace database.
+
+Every agent run, LLM call, tool call, sub-agent and RAG retrieval is stored
+as a span when tracing is enabled (tracing.enabled, on by default). Traces
+older than tracing.retention_days are pruned automatically.`,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent traces",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		filter := agent.TraceFilter{SessionID: listSession, AgentID: listAgent, Limit: listLimit}
+		if listSince > 0 {
+			filter.Since = time.Now().Add(-listSince)
+		}
+		traces, err := store.ListTraces(cmd.Context(), filter)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if listJSON {
+			return writeJSON(out, traces)
+		}
+		if len(traces) == 0 {
+			fmt.Fprintln(out, "No traces found.")
+			return nil
+		}
+		fmt.Fprintf(out, "%-32s  %-19s  %-10s  %5s  %4s  %5s  %8s  %s\n",
+			"TRACE ID", "STARTED", "DURATION", "SPANS", "LLM", "TOOLS", "TOKENS", "NAME")
+		for _, t := range traces {
+			status := ""
+			if t.Status == agent.SpanStatusError {
+				status = " [error]"
+			}
+			fmt.Fprintf(out, "%-32s  %-19s  %-10s  %5d  %4d  %5d  %8d  %s%s\n",
+				t.ID, t.StartTime.Format("2006-01-02 15:04:05"), roundDuration(t.Duration),
+				t.SpanCount, t.LLMCalls, t.ToolCalls, t.InputTokens+t.OutputTokens, t.Name, status)
+		}
+		return nil
+	},
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <trace-id>",
+	Short: "Show a trace as a waterfall with linked history and usage",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		detail, err := loadDetail(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		printWaterfall(cmd.OutOrStdout(), detail)
+		return nil
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <trace-id>",
+	Short: "Export a trace as JSON or text",
+	Long: `Export a trace with its spans, linked history records and usage records.
+
+Formats:
+  json    full trace detail (default)
+  pretty  human-readable span listing`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		detail, err := loadDetail(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if exportOutput != "" {
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		switch agent.ExportFormat(exportFormat) {
+		case agent.ExportFormatJSON:
+			return writeJSON(out, detail)
+		case agent.ExportFormatPretty:
+			text, err := agent.NewTraceExporter(nil).ExportTrace(detail.Trace.ToTrace(detail.Spans), agent.ExportFormatPretty)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(out, text)
+			return err
+		default:
+			return fmt.Errorf("unknown export format %q (use json or pretty)", exportFormat)
+		}
+	},
+}
+
+func init() {
+	listCmd.Flags().IntVarP(&listLimit, "limit", "n", 20, "maximum number of traces to list")
+	listCmd.Flags().StringVar(&listSession, "session", "", "only traces for this session ID")
+	listCmd.Flags().StringVar(&listAgent, "agent", "", "only traces for this agent ID")
+	listCmd.Flags().DurationVar(&listSince, "since", 0, "only traces started within this duration (e.g. 24h)")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "output as JSON")
+
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "export format: json or pretty")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write to a file instead of stdout")
+
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(showCmd)
+	Cmd.AddCommand(exportCmd)
+}
+
+func openStore() (*agent.TraceStore, error) {
+	path := Cfg.TracesPath()
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("no trace database at %s (is tracing.enabled set?)", path)
+	}
+	return agent.NewTraceStore(path, Cfg.TraceRetention())
+}
+
+// loadDetail loads a trace and links the history and usage databases when
+// they exist
+func loadDetail(ctx context.Context, traceID string) (*agent.TraceDetail, error) {
+	store, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	var history *agent.HistoryStore
+	if _, err := os.Stat(Cfg.HistoryPath()); err == nil {
+		if history, err = agent.NewHistoryStore(Cfg.HistoryPath()); err == nil {
+			defer history.Close()
+		} else if Verbose {
+			fmt.Fprintf(os.Stderr, "warning: history not linked: %v\n", err)
+		}
+	}
+
+	var usageSvc *usage.Service
+	if _, err := os.Stat(filepath.Join(Cfg.DataDir(), "usage.db")); err == nil {
+		if usageSvc, err = usage.NewService(Cfg); err == nil {
+			defer usageSvc.Close()
+		} else if Verbose {
+			fmt.Fprintf(os.Stderr, "warning: usage not linked: %v\n", err)
+		}
+	}
+
+	return agent.LoadTraceDetail(ctx, store, traceID, history, usageSvc)
+}
+
+// waterfallWidth is the number of columns used for span timing bars
+const waterfallWidth = 40
+
+func printWaterfall(out io.Writer, detail *agent.TraceDetail) {
+	t := detail.Trace
+	fmt.Fprintf(out, "Trace:    %s\n", t.ID)
+	fmt.Fprintf(out, "Name:     %s\n", t.Name)
+	if t.SessionID != "" {
+		fmt.Fprintf(out, "Session:  %s\n", t.SessionID)
+	}
+	fmt.Fprintf(out, "Started:  %s\n", t.StartTime.Format(time.RFC3339))
+	fmt.Fprintf(out, "Duration: %s\n", roundDuration(t.Duration))
+	fmt.Fprintf(out, "Spans:    %d (llm %d, tools %d, errors %d)\n", t.SpanCount, t.LLMCalls, t.ToolCalls, t.Errors)
+	fmt.Fprintf(out, "Tokens:   %d in / %d out\n\n", t.InputTokens, t.OutputTokens)
+
+	total := t.Duration
+	if total <= 0 {
+		total = time.Millisecond
+	}
+	for _, row := range orderSpans(detail.Spans) {
+		span := row.span
+		offset := int(float64(span.StartTime.Sub(t.StartTime)) / float64(total) * waterfallWidth)
+		width := int(float64(span.Duration) / float64(total) * waterfallWidth)
+		offset = clamp(offset, 0, waterfallWidth-1)
+		width = clamp(width, 1, waterfallWidth-offset)
+		bar := strings.Repeat(" ", offset) + strings.Repeat("█", width) + strings.Repeat(" ", waterfallWidth-offset-width)
+
+		label := strings.Repeat("  ", row.depth) + span.Name
+		if tokens := spanTokens(span); tokens != "" {
+			label += " " + tokens
+		}
+		if span.Status == agent.SpanStatusError {
+			label += " ✗ " + span.Attributes["error"]
+		}
+		fmt.Fprintf(out, "|%s| %9s  %s\n", bar, roundDuration(span.Duration), label)
+	}
+
+	if len(detail.History) > 0 {
+		fmt.Fprintf(out, "\nHistory (%d records):\n", len(detail.History))
+		for _, r := range detail.History {
+			content := r.Content
+			if r.ToolName != "" {
+				content = r.ToolName + " " + content
+			}
+			fmt.Fprintf(out, "  %s  %-11s %s\n", r.CreatedAt.Format("15:04:05.000"), r.Role, truncate(content, 80))
+		}
+	}
+	if len(detail.Usage) > 0 {
+		fmt.Fprintf(out, "\nUsage (%d records):\n", len(detail.Usage))
+		for _, r := range detail.Usage {
+			fmt.Fprintf(out, "  %s  %-4s %-24s %6d in %6d out  $%.4f\n",
+				r.CreatedAt.Format("15:04:05.000"), r.CallType, r.Model, r.InputTokens, r.OutputTokens, r.Cost)
+		}
+	}
+}
+
+type spanRow struct {
+	span  *agent.Span
+	depth int
+}
+
+// orderSpans flattens the span tree depth-first with children by start time
+func orderSpans(spans []*agent.Span) []spanRow {
+	ids := make(map[string]bool, len(spans))
+	children := make(map[string][]*agent.Span)
+	for _, s := range spans {
+		ids[s.ID] = true
+	}
+	var roots []*agent.Span
+	for _, s := range spans {
+		if s.ParentID == "" || !ids[s.ParentID] {
+			roots = append(roots, s)
+			continue
+		}
+		children[s.ParentID] = append(children[s.ParentID], s)
+	}
+
+	var rows []spanRow
+	var walk func(list []*agent.Span, depth int)
+	walk = func(list []*agent.Span, depth int) {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].StartTime.Before(list[j].StartTime) })
+		for _, s := range list {
+			rows = append(rows, spanRow{span: s, depth: depth})
+			walk(children[s.ID], depth+1)
+		}
+	}
+	walk(roots, 0)
+	return rows
+}
+
+func spanTokens(span *agent.Span) string {
+	in := span.Attributes[string(telemetry.AttrGenAIInputTokens)]
+	out := span.Attributes[string(telemetry.AttrGenAIOutputTokens)]
+	if in == "" && out == "" {
+		return ""
+	}
+	return fmt.Sprintf("(%s→%s tok)", in, out)
+}
+
+func roundDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func writeJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
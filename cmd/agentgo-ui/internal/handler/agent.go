@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/agent"
 	agentgolog "github.com/liliang-cn/agent-go/pkg/log"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 )
 
 // Agent handlers
@@ -94,7 +95,15 @@ func (h *Handler) HandleAgentStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	events, err := svc.RunStream(r.Context(), req.Message)
+	// MCP input requests raised by this run are shown on this stream only
+	session := req.SessionID
+	if session == "" {
+		session = uuid.New().String()
+	}
+	prompts, unsubscribe := h.elicitations.subscribe(session)
+	defer unsubscribe()
+
+	events, err := svc.RunStream(mcp.WithElicitationSession(r.Context(), session), req.Message)
 	if err != nil {
 		data, _ := json.Marshal(map[string]string{"type": "error", "content": err.Error()})
 		fmt.Fprintf(w, "data: %s\n\n", data)
@@ -102,11 +111,25 @@ func (h *Handler) HandleAgentStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for evt := range events {
+	for {
+		var evt *agent.Event
 		select {
 		case <-r.Context().Done():
 			return
-		default:
+		case req := <-prompts:
+			data, _ := json.Marshal(elicitationEvent(session, req))
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+			continue
+		case e, ok := <-events:
+			if !ok {
+				fmt.Fprintf(w, "data: [DONE]\n\n")
+				flusher.Flush()
+				return
+			}
+			evt = e
 		}
 
 		payload := map[string]interface{}{
@@ -134,9 +157,6 @@ func (h *Handler) HandleAgentStream(w http.ResponseWriter, r *http.Request) {
 		}
 		flusher.Flush()
 	}
-
-	fmt.Fprintf(w, "data: [DONE]\n\n")
-	flusher.Flush()
 }
 
 func (h *Handler) HandleAgents(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+// elicitationHub forwards MCP servers' requests for user input to the agent
+// stream of the session whose tool call raised them, and waits for the
+// browser to answer through /api/mcp/elicitations/respond.
+type elicitationHub struct {
+	mu         sync.Mutex
+	pending    map[string]*pendingElicitation
+	streams    map[int]*elicitationStream
+	nextStream int
+}
+
+type pendingElicitation struct {
+	req     *mcp.ElicitationRequest
+	session string
+	reply   chan *mcp.ElicitationResponse
+}
+
+type elicitationStream struct {
+	session string
+	ch      chan *mcp.ElicitationRequest
+}
+
+func newElicitationHub() *elicitationHub {
+	return &elicitationHub{
+		pending: make(map[string]*pendingElicitation),
+		streams: make(map[int]*elicitationStream),
+	}
+}
+
+// Elicit implements mcp.Elicitor. Requests are cancelled when they cannot be
+// tied to a session or that session has no stream open to show them.
+func (hub *elicitationHub) Elicit(ctx context.Context, req *mcp.ElicitationRequest) (*mcp.ElicitationResponse, error) {
+	session := mcp.ElicitationSession(ctx)
+	p := &pendingElicitation{req: req, session: session, reply: make(chan *mcp.ElicitationResponse, 1)}
+
+	hub.mu.Lock()
+	var targets []chan *mcp.ElicitationRequest
+	if session != "" {
+		for _, s := range hub.streams {
+			if s.session == session {
+				targets = append(targets, s.ch)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		hub.mu.Unlock()
+		return &mcp.ElicitationResponse{Action: mcp.ElicitationCancel}, nil
+	}
+	hub.pending[req.ID] = p
+	for _, ch := range targets {
+		select {
+		case ch <- req:
+		default:
+		}
+	}
+	hub.mu.Unlock()
+
+	defer func() {
+		hub.mu.Lock()
+		delete(hub.pending, req.ID)
+		hub.mu.Unlock()
+	}()
+
+	select {
+	case resp := <-p.reply:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// subscribe registers an open stream of session for its requests
+func (hub *elicitationHub) subscribe(session string) (<-chan *mcp.ElicitationRequest, func()) {
+	ch := make(chan *mcp.ElicitationRequest, 8)
+
+	hub.mu.Lock()
+	id := hub.nextStream
+	hub.nextStream++
+	hub.streams[id] = &elicitationStream{session: session, ch: ch}
+	hub.mu.Unlock()
+
+	return ch, func() {
+		hub.mu.Lock()
+		delete(hub.streams, id)
+		hub.mu.Unlock()
+	}
+}
+
+// list returns the requests of session still waiting for an answer
+func (hub *elicitationHub) list(session string) []*mcp.ElicitationRequest {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	out := make([]*mcp.ElicitationRequest, 0, len(hub.pending))
+	for _, p := range hub.pending {
+		if p.session == session {
+			out = append(out, p.req)
+		}
+	}
+	return out
+}
+
+// resolve answers a pending request of session, reporting false when it is
+// unknown, belongs to another session or was already answered
+func (hub *elicitationHub) resolve(id, session string, resp *mcp.ElicitationResponse) bool {
+	hub.mu.Lock()
+	p, ok := hub.pending[id]
+	if ok && p.session == session {
+		delete(hub.pending, id)
+	} else {
+		ok = false
+	}
+	hub.mu.Unlock()
+	if !ok {
+		return false
+	}
+	p.reply <- resp
+	return true
+}
+
+func elicitationEvent(session string, req *mcp.ElicitationRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "elicitation",
+		"session_id":  session,
+		"elicitation": req,
+	}
+}
+
+// HandleMCPElicitations lists the MCP input requests of a session waiting for
+// an answer
+func (h *Handler) HandleMCPElicitations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session := r.URL.Query().Get("session_id")
+	if session == "" {
+		JSONError(w, "session_id is required", http.StatusBadRequest)
+		return
+	}
+	JSONResponse(w, h.elicitations.list(session))
+}
+
+// HandleMCPElicitationRespond submits the user's answer to an MCP input request
+func (h *Handler) HandleMCPElicitationRespond(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID        string                 `json:"id"`
+		SessionID string                 `json:"session_id"`
+		Action    string                 `json:"action"`
+		Content   map[string]interface{} `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch req.Action {
+	case mcp.ElicitationAccept, mcp.ElicitationDecline, mcp.ElicitationCancel:
+	default:
+		JSONError(w, "action must be accept, decline or cancel", http.StatusBadRequest)
+		return
+	}
+
+	if !h.elicitations.resolve(req.ID, req.SessionID, &mcp.ElicitationResponse{Action: req.Action, Content: req.Content}) {
+		JSONError(w, "Elicitation not found or expired", http.StatusNotFound)
+		return
+	}
+	JSONResponse(w, map[string]string{"status": "ok"})
+}
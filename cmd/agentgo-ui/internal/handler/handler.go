@@ -34,6 +34,9 @@ type Handler struct {
 	aiChatSessions map[string]string
 	opsLogMu       sync.RWMutex
 	opsLogs        []OpsLogEntry
+	elicitations   *elicitationHub
+	tracesMu       sync.Mutex
+	traces         *agent.TraceStore
 }
 
 // New creates a new handler
@@ -45,6 +48,18 @@ func New(cfg *config.Config, ragClient *rag.Client, skillsService *skills.Servic
 	configHandler := NewConfigHandler(cfg, configPath)
 	setupHandler := NewSetupHandler(cfg, configPath)
 
+	// MCP servers ask for user input through the agent stream
+	elicitations := newElicitationHub()
+	if agentService != nil {
+		agentService.SetElicitor(elicitations)
+	}
+	if mcpService != nil {
+		mcpService.SetElicitor(elicitations)
+	}
+	if squadManager != nil {
+		squadManager.SetElicitor(elicitations)
+	}
+
 	return &Handler{
 		cfg:            cfg,
 		ConfigHandler:  configHandler,
@@ -59,6 +74,7 @@ func New(cfg *config.Config, ragClient *rag.Client, skillsService *skills.Servic
 		embedder:       embedder,
 		aiChatSessions: make(map[string]string),
 		opsLogs:        make([]OpsLogEntry, 0, 64),
+		elicitations:   elicitations,
 	}
 }
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/liliang-cn/agent-go/pkg/agent"
 	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 )
 
 func testConfig(t *testing.T) *config.Config {
@@ -451,3 +452,81 @@ func TestDequeueToolCallID(t *testing.T) {
 		t.Fatalf("expected empty id, got %s", got)
 	}
 }
+
+func TestElicitationRoundTrip(t *testing.T) {
+	h := &Handler{elicitations: newElicitationHub()}
+
+	// Without an open stream nobody can answer
+	resp, err := h.elicitations.Elicit(context.Background(), &mcp.ElicitationRequest{ID: "none"})
+	if err != nil || resp.Action != mcp.ElicitationCancel {
+		t.Fatalf("Elicit() without stream = %+v, %v", resp, err)
+	}
+
+	prompts, unsubscribe := h.elicitations.subscribe("tab-1")
+	defer unsubscribe()
+	others, unsubscribeOthers := h.elicitations.subscribe("tab-2")
+	defer unsubscribeOthers()
+
+	// Requests that cannot be tied to a session are not shown to anyone
+	resp, err = h.elicitations.Elicit(context.Background(), &mcp.ElicitationRequest{ID: "orphan"})
+	if err != nil || resp.Action != mcp.ElicitationCancel {
+		t.Fatalf("Elicit() without session = %+v, %v", resp, err)
+	}
+
+	done := make(chan *mcp.ElicitationResponse, 1)
+	go func() {
+		ctx := mcp.WithElicitationSession(context.Background(), "tab-1")
+		resp, _ := h.elicitations.Elicit(ctx, &mcp.ElicitationRequest{ID: "e1", Server: "github", Message: "Which repo?"})
+		done <- resp
+	}()
+
+	select {
+	case req := <-prompts:
+		if req.ID != "e1" {
+			t.Fatalf("unexpected request: %+v", req)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request not forwarded to stream")
+	}
+	select {
+	case req := <-others:
+		t.Fatalf("request leaked to another session: %+v", req)
+	default:
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleMCPElicitations(rec, httptest.NewRequest(http.MethodGet, "/api/mcp/elicitations?session_id=tab-1", nil))
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`"id":"e1"`)) {
+		t.Fatalf("pending list = %s", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.HandleMCPElicitations(rec, httptest.NewRequest(http.MethodGet, "/api/mcp/elicitations?session_id=tab-2", nil))
+	if bytes.Contains(rec.Body.Bytes(), []byte(`"id":"e1"`)) {
+		t.Fatalf("pending list of another session = %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleMCPElicitationRespond(rec, httptest.NewRequest(http.MethodPost, "/api/mcp/elicitations/respond",
+		bytes.NewReader([]byte(`{"id":"e1","session_id":"tab-2","action":"decline"}`))))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("respond from another session status = %d", rec.Code)
+	}
+
+	body := []byte(`{"id":"e1","session_id":"tab-1","action":"accept","content":{"repo":"agent-go"}}`)
+	rec = httptest.NewRecorder()
+	h.HandleMCPElicitationRespond(rec, httptest.NewRequest(http.MethodPost, "/api/mcp/elicitations/respond", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("respond status = %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	resp = <-done
+	if resp.Action != mcp.ElicitationAccept || resp.Content["repo"] != "agent-go" {
+		t.Fatalf("Elicit() = %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleMCPElicitationRespond(rec, httptest.NewRequest(http.MethodPost, "/api/mcp/elicitations/respond", bytes.NewReader(body)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second respond status = %d", rec.Code)
+	}
+}
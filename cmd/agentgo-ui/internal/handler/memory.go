@@ -2,11 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/memory"
+	"github.com/liliang-cn/agent-go/pkg/store"
 )
 
 // Memory handlers
@@ -48,7 +52,14 @@ func (h *Handler) HandleMemoryAdd(w http.ResponseWriter, r *http.Request) {
 		Importance: req.Importance,
 		CreatedAt:  time.Now(),
 	}
-	h.memoryService.Add(r.Context(), mem)
+	// Memories added by a signed-in user belong to that user's scope
+	if p := domain.MemoryPrincipalFromContext(r.Context()); p != nil && !p.Admin && p.UserID != "" {
+		mem.SessionID = string(domain.MemoryScopeUser) + ":" + p.UserID
+	}
+	if err := h.memoryService.Add(r.Context(), mem); err != nil {
+		JSONError(w, err.Error(), memoryErrorStatus(err))
+		return
+	}
 	JSONResponse(w, map[string]interface{}{"success": true, "id": mem.ID})
 }
 
@@ -85,9 +96,186 @@ func (h *Handler) HandleMemoryOperation(w http.ResponseWriter, r *http.Request)
 		}
 		JSONResponse(w, mem)
 	case http.MethodDelete:
-		h.memoryService.Delete(r.Context(), id)
+		if err := h.memoryService.Delete(r.Context(), id); err != nil {
+			JSONError(w, err.Error(), memoryErrorStatus(err))
+			return
+		}
 		JSONResponse(w, map[string]bool{"success": true})
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
+
+func memoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrMemoryAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, store.ErrMemoryNotFound), errors.Is(err, store.ErrSuggestionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrSuggestionDecided):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HandleMemoryConflicts lists open memory contradictions awaiting review
+func (h *Handler) HandleMemoryConflicts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.memoryService == nil {
+		JSONResponse(w, []interface{}{})
+		return
+	}
+	conflicts, err := h.memoryService.Conflicts(r.Context())
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if conflicts == nil {
+		conflicts = []*memory.MemoryConflict{}
+	}
+	JSONResponse(w, conflicts)
+}
+
+// HandleMemoryConflictResolve keeps the chosen memory and supersedes the
+// memories it contradicted
+func (h *Handler) HandleMemoryConflictResolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.memoryService == nil {
+		JSONError(w, "Memory service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		WinnerID string `json:"winner_id"`
+		By       string `json:"by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.WinnerID == "" {
+		JSONError(w, "winner_id is required", http.StatusBadRequest)
+		return
+	}
+	if err := h.memoryService.ResolveConflict(r.Context(), req.WinnerID, req.By); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	JSONResponse(w, map[string]interface{}{"success": true})
+}
+
+// HandleMemoryExplain runs memory retrieval for a query and explains how each
+// candidate was found, scored and kept or dropped. Scoring overrides in the
+// request apply to this query only, for tuning weights interactively.
+func (h *Handler) HandleMemoryExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.memoryService == nil {
+		JSONError(w, "Memory service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Query     string                 `json:"query"`
+		SessionID string                 `json:"session_id"`
+		Limit     int                    `json:"limit"`
+		Scoring   map[string]interface{} `json:"scoring"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+		JSONError(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	opts := &memory.RetrieveOptions{MaxMemories: req.Limit}
+	if len(req.Scoring) > 0 {
+		overrides := make(map[string]string, len(req.Scoring))
+		for k, v := range req.Scoring {
+			overrides[k] = fmt.Sprint(v)
+		}
+		scoring, err := memory.ApplyScoringOverrides(h.memoryService.ScoringConfig(), overrides)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.Scoring = scoring
+	}
+
+	_, explanation, err := h.memoryService.Explain(r.Context(), req.Query, req.SessionID, opts)
+	if err != nil {
+		JSONError(w, err.Error(), memoryErrorStatus(err))
+		return
+	}
+	JSONResponse(w, explanation)
+}
+
+// HandleMemorySuggestions lists memories proposed by the agent that await
+// review, optionally for one session
+func (h *Handler) HandleMemorySuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.memoryService == nil {
+		JSONResponse(w, []interface{}{})
+		return
+	}
+	suggestions, err := h.memoryService.PendingSuggestions(r.Context(), r.URL.Query().Get("session_id"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if suggestions == nil {
+		suggestions = []*domain.MemorySuggestion{}
+	}
+	JSONResponse(w, suggestions)
+}
+
+// HandleMemorySuggestionDecision returns a handler that accepts (stores) or
+// rejects a pending memory suggestion
+func (h *Handler) HandleMemorySuggestionDecision(accept bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if h.memoryService == nil {
+			JSONError(w, "Memory service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		var req struct {
+			ID string `json:"id"`
+			By string `json:"by"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+			JSONError(w, "id is required", http.StatusBadRequest)
+			return
+		}
+		// Authenticated callers are recorded as who they are
+		if p := domain.MemoryPrincipalFromContext(r.Context()); p != nil {
+			req.By = p.String()
+		} else if req.By == "" {
+			req.By = "user"
+		}
+
+		if !accept {
+			if err := h.memoryService.RejectSuggestion(r.Context(), req.ID, req.By); err != nil {
+				JSONError(w, err.Error(), memoryErrorStatus(err))
+				return
+			}
+			JSONResponse(w, map[string]interface{}{"success": true})
+			return
+		}
+		mem, err := h.memoryService.AcceptSuggestion(r.Context(), req.ID, req.By)
+		if err != nil {
+			JSONError(w, err.Error(), memoryErrorStatus(err))
+			return
+		}
+		JSONResponse(w, map[string]interface{}{"success": true, "memory": mem})
+	}
+}
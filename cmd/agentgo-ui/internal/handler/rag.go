@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/liliang-cn/agent-go/pkg/rag"
 )
 
@@ -204,14 +205,22 @@ func (h *Handler) handleAISDKAgentChat(w http.ResponseWriter, r *http.Request, r
 		agentName = "Captain"
 	}
 
-	events, err := h.squadManager.DispatchTaskStream(r.Context(), agentName, prompt)
+	// MCP input requests raised by this turn are shown in this chat only
+	chatID := strings.TrimSpace(stringValue(raw["id"]))
+	session := chatID
+	if session == "" {
+		session = uuid.New().String()
+	}
+	prompts, unsubscribe := h.elicitations.subscribe(session)
+	defer unsubscribe()
+
+	events, err := h.squadManager.DispatchTaskStream(mcp.WithElicitationSession(r.Context(), session), agentName, prompt)
 	if err != nil {
 		JSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	chatID := strings.TrimSpace(stringValue(raw["id"]))
-	streamAISDKAgentChat(w, r, chatID, agentName, events)
+	streamAISDKAgentChat(w, r, chatID, agentName, events, session, prompts)
 }
 
 func (h *Handler) getOrCreateAISDKSession(ctx context.Context, externalID string) (string, error) {
@@ -369,7 +378,7 @@ func streamAISDKChat(w http.ResponseWriter, r *http.Request, chatID, text string
 	})
 }
 
-func streamAISDKAgentChat(w http.ResponseWriter, r *http.Request, chatID, agentName string, events <-chan *agent.Event) {
+func streamAISDKAgentChat(w http.ResponseWriter, r *http.Request, chatID, agentName string, events <-chan *agent.Event, session string, prompts <-chan *mcp.ElicitationRequest) {
 	setSSEHeaders(w)
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -396,11 +405,22 @@ func streamAISDKAgentChat(w http.ResponseWriter, r *http.Request, chatID, agentN
 		},
 	})
 
-	for evt := range events {
+	for {
+		var evt *agent.Event
 		select {
 		case <-r.Context().Done():
 			return
-		default:
+		case req := <-prompts:
+			writeSSEChunk(w, flusher, map[string]any{
+				"type":      "data-elicitation",
+				"transient": true,
+				"data":      elicitationEvent(session, req),
+			})
+			continue
+		case evt = <-events:
+		}
+		if evt == nil {
+			break
 		}
 
 		switch evt.Type {
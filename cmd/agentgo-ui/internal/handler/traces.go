@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+)
+
+// traceStore returns the agent's trace store, or the configured trace
+// database when the agent does not own one. That database is opened on
+// first use and kept for the life of the handler.
+func (h *Handler) traceStore() (*agent.TraceStore, error) {
+	if h.agentService != nil {
+		if store := h.agentService.TraceStore(); store != nil {
+			return store, nil
+		}
+	}
+	h.tracesMu.Lock()
+	defer h.tracesMu.Unlock()
+	if h.traces == nil {
+		store, err := agent.NewTraceStore(h.cfg.TracesPath(), h.cfg.TraceRetention())
+		if err != nil {
+			return nil, err
+		}
+		h.traces = store
+	}
+	return h.traces, nil
+}
+
+// HandleTraces lists persisted traces, newest first
+func (h *Handler) HandleTraces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	store, err := h.traceStore()
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := agent.TraceFilter{
+		SessionID: q.Get("session_id"),
+		AgentID:   q.Get("agent_id"),
+		Limit:     50,
+	}
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if since, err := time.ParseDuration(q.Get("since")); err == nil && since > 0 {
+		filter.Since = time.Now().Add(-since)
+	}
+
+	traces, err := store.ListTraces(r.Context(), filter)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if traces == nil {
+		traces = []*agent.TraceRecord{}
+	}
+	JSONResponse(w, map[string]interface{}{"traces": traces})
+}
+
+// HandleTraceOperation returns one trace with its spans and the history and
+// usage records written under it
+func (h *Handler) HandleTraceOperation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Path[len("/api/traces/"):]
+	if id == "" {
+		JSONError(w, "trace id is required", http.StatusBadRequest)
+		return
+	}
+	store, err := h.traceStore()
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	var history *agent.HistoryStore
+	if h.agentService != nil {
+		history = h.agentService.GetHistoryStore()
+	}
+	var usageSvc *usage.Service
+	if _, err := os.Stat(filepath.Join(h.cfg.DataDir(), "usage.db")); err == nil {
+		if usageSvc, err = usage.NewService(h.cfg); err == nil {
+			defer usageSvc.Close()
+		}
+	}
+
+	detail, err := agent.LoadTraceDetail(r.Context(), store, id, history, usageSvc)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	JSONResponse(w, detail)
+}
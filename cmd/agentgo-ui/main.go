@@ -7,11 +7,15 @@ import (
 	"io/fs"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/liliang-cn/agent-go/cmd/agentgo-ui/internal/handler"
 	"github.com/liliang-cn/agent-go/pkg/agent"
 	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
 	agentgolog "github.com/liliang-cn/agent-go/pkg/log"
 	"github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/liliang-cn/agent-go/pkg/memory"
@@ -114,11 +118,12 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create workspace directory: %w", err)
 	}
 	mcpConfig := &mcp.Config{
-		Enabled:           cfg.MCP.Enabled,
-		Servers:           cfg.MCP.Servers,
-		ServersConfigPath: cfg.MCP.ServersConfigPath,
-		FilesystemDirs:    cfg.MCP.FilesystemDirs,
-		LoadedServers:     mcp.GetBuiltInServers(cfg.MCP.FilesystemDirs),
+		Enabled:            cfg.MCP.Enabled,
+		Servers:            cfg.MCP.Servers,
+		ServersConfigPath:  cfg.MCP.ServersConfigPath,
+		FilesystemDirs:     cfg.MCP.FilesystemDirs,
+		ElicitationTimeout: cfg.MCP.ElicitationTimeout,
+		LoadedServers:      mcp.GetBuiltInServers(cfg.MCP.FilesystemDirs),
 	}
 	var mcpService *mcp.Service
 	if cfg.MCP.Enabled {
@@ -136,10 +141,37 @@ func runServer(cmd *cobra.Command, args []string) error {
 	memoryStore, err := store.NewFileMemoryStore(cfg.Memory.MemoryPath)
 	if err != nil {
 		agentgolog.Warn("Failed to create memory store: %v", err)
+	} else if cfg.Memory.Git.Enabled {
+		if err := memoryStore.EnableGit(); err != nil {
+			return fmt.Errorf("failed to enable memory git versioning: %w", err)
+		}
 	}
 	var memoryService *memory.Service
 	if memoryStore != nil {
 		memoryService = memory.NewService(memoryStore, llm, embedder, memory.DefaultConfig())
+		if access := cfg.Memory.Access; access.Enabled {
+			if err := memoryService.ConfigureAccess(access.Read, access.Write, access.AuditLog); err != nil {
+				return fmt.Errorf("failed to configure memory access control: %w", err)
+			}
+		}
+		// Share the agent's pending suggestions so they can be reviewed here
+		sc := cfg.Memory.Suggestions
+		suggestionPath := sc.Path
+		if suggestionPath == "" {
+			suggestionPath = filepath.Join(cfg.Memory.MemoryPath, "suggestions.json")
+		}
+		suggestionStore, err := store.NewFileSuggestionStore(suggestionPath)
+		if err != nil {
+			return fmt.Errorf("failed to open memory suggestions: %w", err)
+		}
+		if err := memoryService.SetSuggestionPolicy(memory.SuggestionPolicy{
+			Mode:                 memory.SuggestionMode(sc.Mode),
+			Timeout:              sc.Timeout,
+			OnTimeout:            sc.OnTimeout,
+			AutoAcceptConfidence: sc.AutoAcceptConfidence,
+		}, suggestionStore); err != nil {
+			return fmt.Errorf("failed to configure memory suggestions: %w", err)
+		}
 	}
 
 	var squadManager *agent.SquadManager
@@ -209,11 +241,19 @@ func runServer(cmd *cobra.Command, args []string) error {
 	mux.HandleFunc("/api/mcp/tools", h.HandleMCPTools)
 	mux.HandleFunc("/api/mcp/add", h.HandleMCPAddServer)
 	mux.HandleFunc("/api/mcp/call", h.HandleMCPCallTool)
+	mux.HandleFunc("/api/mcp/elicitations", h.HandleMCPElicitations)
+	mux.HandleFunc("/api/mcp/elicitations/respond", h.HandleMCPElicitationRespond)
 
 	// Memory endpoints
 	mux.HandleFunc("/api/memories", h.HandleMemories)
 	mux.HandleFunc("/api/memories/add", h.HandleMemoryAdd)
 	mux.HandleFunc("/api/memories/search", h.HandleMemorySearch)
+	mux.HandleFunc("/api/memories/explain", h.HandleMemoryExplain)
+	mux.HandleFunc("/api/memories/conflicts", h.HandleMemoryConflicts)
+	mux.HandleFunc("/api/memories/conflicts/resolve", h.HandleMemoryConflictResolve)
+	mux.HandleFunc("/api/memories/suggestions", h.HandleMemorySuggestions)
+	mux.HandleFunc("/api/memories/suggestions/accept", h.HandleMemorySuggestionDecision(true))
+	mux.HandleFunc("/api/memories/suggestions/reject", h.HandleMemorySuggestionDecision(false))
 	mux.HandleFunc("/api/memories/", h.HandleMemoryOperation)
 
 	// Agent endpoints
@@ -223,6 +263,10 @@ func runServer(cmd *cobra.Command, args []string) error {
 	mux.HandleFunc("/api/agents/", h.HandleAgentOperation)
 	mux.HandleFunc("/api/ops/logs", h.HandleOpsLogs)
 
+	// Trace endpoints
+	mux.HandleFunc("/api/traces", h.HandleTraces)
+	mux.HandleFunc("/api/traces/", h.HandleTraceOperation)
+
 	mux.HandleFunc("/api/config", h.ConfigHandler.HandleConfig)
 	mux.HandleFunc("/api/setup", h.SetupHandler.HandleSetup)
 
@@ -252,7 +296,7 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      corsMiddleware(mux),
+		Handler:      corsMiddleware(memoryPrincipalMiddleware(cfg.Memory.Access, mux), "Authorization"),
 		ReadTimeout:  300 * time.Second,
 		WriteTimeout: 600 * time.Second,
 		IdleTimeout:  600 * time.Second,
@@ -261,11 +305,17 @@ func runServer(cmd *cobra.Command, args []string) error {
 	return server.ListenAndServe()
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
+func corsMiddleware(next http.Handler, extraHeaders ...string) http.Handler {
+	allowHeaders := "Content-Type"
+	for _, h := range extraHeaders {
+		if h != "" {
+			allowHeaders += ", " + h
+		}
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -275,3 +325,55 @@ func corsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// memoryTokenCookie keeps the memory access token for browser sessions
+const memoryTokenCookie = "agentgo_token"
+
+// memoryPrincipalMiddleware authenticates the caller by bearer token and
+// puts the matching user on the request context so memory access control
+// can apply. Browsers open the UI once with ?token=..., which is moved into
+// a same-site cookie and stripped from the URL by a redirect, so it does not
+// linger in history, logs or Referer headers. Requests without a known token
+// are anonymous and only see scopes readable by anyone; admin comes from the
+// authenticated user.
+func memoryPrincipalMiddleware(access config.MemoryAccessConfig, next http.Handler) http.Handler {
+	if !access.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if q := query.Get("token"); q != "" && r.Method == http.MethodGet {
+			if _, ok := access.Authenticate(strings.TrimSpace(q)); ok {
+				http.SetCookie(w, &http.Cookie{
+					Name:     memoryTokenCookie,
+					Value:    q,
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteStrictMode,
+					Secure:   r.TLS != nil,
+				})
+			}
+			query.Del("token")
+			clean := *r.URL
+			clean.RawQuery = query.Encode()
+			http.Redirect(w, r, clean.RequestURI(), http.StatusSeeOther)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = ""
+			if c, err := r.Cookie(memoryTokenCookie); err == nil {
+				token = c.Value
+			}
+		}
+		var principal *domain.MemoryPrincipal
+		if user, ok := access.Authenticate(strings.TrimSpace(token)); ok {
+			principal = &domain.MemoryPrincipal{
+				UserID: user.ID,
+				Admin:  slices.Contains(access.Admins, user.ID),
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(domain.WithMemoryPrincipal(r.Context(), principal)))
+	})
+}
@@ -0,0 +1,129 @@
+package acpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	acp "github.com/coder/acp-go-sdk"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+// ElicitationRuntime routes MCP servers' requests for user input when
+// supported by the session implementation.
+type ElicitationRuntime interface {
+	SessionRuntime
+	SetElicitor(e mcp.Elicitor)
+}
+
+// elicitationChoice is the answer behind one permission option
+type elicitationChoice struct {
+	action  string
+	content map[string]any
+}
+
+// elicitor answers MCP elicitation through session/request_permission. ACP
+// clients only pick from options, so forms are offered as choices: a plain
+// confirmation, one option per value of a single enum or boolean field, or
+// accepting the defaults. Forms that need typed input are declined.
+func (s *Server) elicitor(sessionID acp.SessionId) mcp.Elicitor {
+	return mcp.ElicitorFunc(func(ctx context.Context, req *mcp.ElicitationRequest) (*mcp.ElicitationResponse, error) {
+		if s.conn == nil {
+			return &mcp.ElicitationResponse{Action: mcp.ElicitationCancel}, nil
+		}
+
+		options, choices := elicitationOptions(req)
+		if options == nil {
+			s.logger.Warn("declining MCP elicitation that needs typed input", "server", req.Server, "fields", len(req.Fields))
+			return &mcp.ElicitationResponse{Action: mcp.ElicitationDecline}, nil
+		}
+
+		rawInput := map[string]any{"server": req.Server, "message": req.Message}
+		if req.URL != "" {
+			rawInput["url"] = req.URL
+		}
+		if req.Schema != nil {
+			rawInput["schema"] = req.Schema
+		}
+		resp, err := s.conn.RequestPermission(ctx, acp.RequestPermissionRequest{
+			SessionId: sessionID,
+			ToolCall: acp.RequestPermissionToolCall{
+				ToolCallId: acp.ToolCallId("elicitation_" + req.ID),
+				Title:      acp.Ptr(fmt.Sprintf("%s asks: %s", req.Server, req.Message)),
+				Kind:       acp.Ptr(acp.ToolKindOther),
+				Status:     acp.Ptr(acp.ToolCallStatusPending),
+				RawInput:   rawInput,
+			},
+			Options: options,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.Outcome.Cancelled != nil || resp.Outcome.Selected == nil {
+			return &mcp.ElicitationResponse{Action: mcp.ElicitationCancel}, nil
+		}
+		choice, ok := choices[string(resp.Outcome.Selected.OptionId)]
+		if !ok {
+			return &mcp.ElicitationResponse{Action: mcp.ElicitationDecline}, nil
+		}
+		return &mcp.ElicitationResponse{Action: choice.action, Content: choice.content}, nil
+	})
+}
+
+// elicitationOptions maps a request onto permission options, or returns nil
+// when it cannot be answered by picking one
+func elicitationOptions(req *mcp.ElicitationRequest) ([]acp.PermissionOption, map[string]elicitationChoice) {
+	var options []acp.PermissionOption
+	choices := make(map[string]elicitationChoice)
+	add := func(id, name string, kind acp.PermissionOptionKind, choice elicitationChoice) {
+		options = append(options, acp.PermissionOption{Kind: kind, Name: name, OptionId: acp.PermissionOptionId(id)})
+		choices[id] = choice
+	}
+
+	defaults := map[string]any{}
+	missing := false
+	for _, f := range req.Fields {
+		if f.Default != nil {
+			defaults[f.Name] = f.Default
+		} else if f.Required {
+			missing = true
+		}
+	}
+
+	switch {
+	case len(req.Fields) == 0:
+		name := "Accept"
+		if req.URL != "" {
+			name = "Open " + req.URL
+		}
+		add("accept", name, acp.PermissionOptionKindAllowOnce, elicitationChoice{action: mcp.ElicitationAccept, content: map[string]any{}})
+	case len(req.Fields) == 1 && (len(req.Fields[0].Enum) > 0 || req.Fields[0].Type == "boolean"):
+		f := req.Fields[0]
+		values := f.Enum
+		if f.Type == "boolean" {
+			values = []string{"yes", "no"}
+		}
+		for _, v := range values {
+			value, err := mcp.ParseElicitationValue(f, v)
+			if err != nil {
+				continue
+			}
+			add("value:"+v, fmt.Sprintf("%s: %s", f.Label(), v), acp.PermissionOptionKindAllowOnce,
+				elicitationChoice{action: mcp.ElicitationAccept, content: map[string]any{f.Name: value}})
+		}
+	case !missing:
+		names := make([]string, 0, len(defaults))
+		for _, f := range req.Fields {
+			if v, ok := defaults[f.Name]; ok {
+				names = append(names, fmt.Sprintf("%s=%v", f.Name, v))
+			}
+		}
+		add("accept", "Accept defaults ("+strings.Join(names, ", ")+")", acp.PermissionOptionKindAllowOnce,
+			elicitationChoice{action: mcp.ElicitationAccept, content: defaults})
+	default:
+		return nil, nil
+	}
+
+	add("decline", "Decline", acp.PermissionOptionKindRejectOnce, elicitationChoice{action: mcp.ElicitationDecline})
+	return options, choices
+}
@@ -11,6 +11,7 @@ import (
 	acp "github.com/coder/acp-go-sdk"
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/domain"
 )
 
 // SessionRuntime is the subset of agent.Service needed by the ACP bridge.
@@ -35,6 +36,12 @@ type PermissionRuntime interface {
 	SetPermissionPolicy(policy agent.PermissionPolicy)
 }
 
+// MemorySuggestionRuntime decides memory suggestions when supported by the session implementation.
+type MemorySuggestionRuntime interface {
+	SessionRuntime
+	DecideMemorySuggestion(ctx context.Context, id string, accept bool, by string) error
+}
+
 // SessionConfig contains the data needed to create a new ACP session runtime.
 type SessionConfig struct {
 	CWD        string
@@ -239,6 +246,10 @@ func (s *Server) Prompt(ctx context.Context, params acp.PromptRequest) (acp.Prom
 		})
 	}
 
+	if elicitationRuntime, ok := session.runtime.(ElicitationRuntime); ok {
+		elicitationRuntime.SetElicitor(s.elicitor(params.SessionId))
+	}
+
 	promptText := renderPrompt(params.Prompt)
 	if strings.TrimSpace(promptText) == "" {
 		return acp.PromptResponse{}, fmt.Errorf("prompt did not contain supported text content")
@@ -318,6 +329,23 @@ func (s *Server) Prompt(ctx context.Context, params acp.PromptRequest) (acp.Prom
 					return acp.PromptResponse{}, err
 				}
 			}
+		case agent.EventTypeToolsChanged:
+			if err := s.sendUpdate(ctx, params.SessionId, acp.UpdateAgentThoughtText(evt.Content)); err != nil {
+				return acp.PromptResponse{}, err
+			}
+		case agent.EventTypeMemorySuggestion:
+			if evt.MemorySuggestion == nil {
+				continue
+			}
+			if err := s.sendUpdate(ctx, params.SessionId, memorySuggestionUpdate(evt.MemorySuggestion)); err != nil {
+				return acp.PromptResponse{}, err
+			}
+			if evt.MemorySuggestion.Status == domain.MemorySuggestionPending {
+				update := s.reviewMemorySuggestion(ctx, params.SessionId, session.runtime, evt.MemorySuggestion)
+				if err := s.sendUpdate(ctx, params.SessionId, update); err != nil {
+					return acp.PromptResponse{}, err
+				}
+			}
 		case agent.EventTypeError:
 			if context.Cause(ctx) != nil {
 				return acp.PromptResponse{StopReason: acp.StopReasonCancelled}, nil
@@ -617,3 +645,72 @@ func (s *Server) requestToolPermission(ctx context.Context, sessionID acp.Sessio
 	}
 	return &agent.PermissionResponse{Allowed: false, Reason: "permission denied by user"}, nil
 }
+
+// memorySuggestionUpdate surfaces a proposed memory as a pending tool call
+// so the client can show it; the suggestion itself is the raw input.
+// Suggestions already accepted by policy are reported as completed.
+func memorySuggestionUpdate(sg *domain.MemorySuggestion) acp.SessionUpdate {
+	status := acp.ToolCallStatusPending
+	if sg.Status == domain.MemorySuggestionAccepted {
+		status = acp.ToolCallStatusCompleted
+	}
+	return acp.StartToolCall(
+		memorySuggestionCallID(sg),
+		memorySuggestionTitle(sg),
+		acp.WithStartKind(acp.ToolKindOther),
+		acp.WithStartStatus(status),
+		acp.WithStartRawInput(sg),
+	)
+}
+
+// reviewMemorySuggestion asks the client whether to keep a pending
+// suggestion, decides it and returns the update that closes its tool call.
+// Runtimes that cannot decide suggestions leave them queued for review
+// with the CLI or UI.
+func (s *Server) reviewMemorySuggestion(ctx context.Context, sessionID acp.SessionId, runtime SessionRuntime, sg *domain.MemorySuggestion) acp.SessionUpdate {
+	callID := memorySuggestionCallID(sg)
+	closeCall := func(status acp.ToolCallStatus, text string) acp.SessionUpdate {
+		return acp.UpdateToolCall(callID,
+			acp.WithUpdateStatus(status),
+			acp.WithUpdateContent([]acp.ToolCallContent{acp.ToolContent(acp.TextBlock(text))}),
+		)
+	}
+	decider, ok := runtime.(MemorySuggestionRuntime)
+	if !ok || s.conn == nil {
+		return closeCall(acp.ToolCallStatusCompleted, "Queued for review: agentgo memory suggestions")
+	}
+
+	resp, err := s.conn.RequestPermission(ctx, acp.RequestPermissionRequest{
+		SessionId: sessionID,
+		ToolCall: acp.RequestPermissionToolCall{
+			ToolCallId: callID,
+			Title:      acp.Ptr(memorySuggestionTitle(sg)),
+			Kind:       acp.Ptr(acp.ToolKindOther),
+			Status:     acp.Ptr(acp.ToolCallStatusPending),
+			RawInput:   sg,
+		},
+		Options: []acp.PermissionOption{
+			{Kind: acp.PermissionOptionKindAllowOnce, Name: "Remember", OptionId: acp.PermissionOptionId("allow")},
+			{Kind: acp.PermissionOptionKindRejectOnce, Name: "Discard", OptionId: acp.PermissionOptionId("reject")},
+		},
+	})
+	if err != nil {
+		return closeCall(acp.ToolCallStatusFailed, fmt.Sprintf("Review failed, still queued: %v", err))
+	}
+	accept := resp.Outcome.Selected != nil && string(resp.Outcome.Selected.OptionId) == "allow"
+	if err := decider.DecideMemorySuggestion(ctx, sg.ID, accept, "acp"); err != nil {
+		return closeCall(acp.ToolCallStatusFailed, err.Error())
+	}
+	if !accept {
+		return closeCall(acp.ToolCallStatusFailed, "Discarded")
+	}
+	return closeCall(acp.ToolCallStatusCompleted, "Remembered")
+}
+
+func memorySuggestionCallID(sg *domain.MemorySuggestion) acp.ToolCallId {
+	return acp.ToolCallId("memory_suggestion_" + sg.ID)
+}
+
+func memorySuggestionTitle(sg *domain.MemorySuggestion) string {
+	return fmt.Sprintf("Remember (%s, %.0f%%): %s", sg.Type, sg.Confidence*100, sg.Content)
+}
@@ -10,6 +10,7 @@ import (
 	acp "github.com/coder/acp-go-sdk"
 	"github.com/liliang-cn/agent-go/pkg/agent"
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 )
 
 type fakeRuntime struct {
@@ -534,3 +535,135 @@ func newTestACPBridge(t *testing.T, factory SessionFactory) (*Server, *acp.Clien
 	clientConn := acp.NewClientSideConnection(client, c2aW, a2cR)
 	return server, clientConn, client
 }
+
+func TestMemorySuggestionUpdate(t *testing.T) {
+	sg := &domain.MemorySuggestion{
+		ID:         "s1",
+		Type:       domain.MemoryTypeFact,
+		Content:    "Staging runs Postgres 16",
+		Confidence: 0.8,
+		Status:     domain.MemorySuggestionPending,
+	}
+
+	update := memorySuggestionUpdate(sg)
+	tc := update.ToolCall
+	if tc == nil {
+		t.Fatalf("expected a tool call update, got %+v", update)
+	}
+	if tc.ToolCallId != "memory_suggestion_s1" || tc.Status != acp.ToolCallStatusPending || tc.Kind != acp.ToolKindOther {
+		t.Fatalf("unexpected tool call: %+v", tc)
+	}
+	if tc.RawInput != sg {
+		t.Fatalf("raw input = %v, want the suggestion", tc.RawInput)
+	}
+
+	sg.Status = domain.MemorySuggestionAccepted
+	if got := memorySuggestionUpdate(sg).ToolCall.Status; got != acp.ToolCallStatusCompleted {
+		t.Fatalf("accepted suggestion status = %s, want completed", got)
+	}
+}
+
+type fakeSuggestionRuntime struct {
+	*fakeRuntime
+	mu        sync.Mutex
+	decisions map[string]bool
+}
+
+func (f *fakeSuggestionRuntime) DecideMemorySuggestion(ctx context.Context, id string, accept bool, by string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.decisions[id] = accept
+	return nil
+}
+
+func TestServerPromptReviewsMemorySuggestions(t *testing.T) {
+	t.Parallel()
+
+	for _, outcome := range []string{"allow", "reject"} {
+		rt := &fakeSuggestionRuntime{fakeRuntime: &fakeRuntime{}, decisions: map[string]bool{}}
+		rt.getSessionFunc = func(sessionID string) (*agent.Session, error) {
+			return agent.NewSessionWithID(sessionID, "agent"), nil
+		}
+		rt.runStreamFunc = func(ctx context.Context, goal string) (<-chan *agent.Event, error) {
+			ch := make(chan *agent.Event, 2)
+			ch <- &agent.Event{Type: agent.EventTypeComplete, Content: "done"}
+			ch <- &agent.Event{Type: agent.EventTypeMemorySuggestion, MemorySuggestion: &domain.MemorySuggestion{
+				ID: "s1", Type: domain.MemoryTypeFact, Content: "Staging runs Postgres 16", Status: domain.MemorySuggestionPending,
+			}}
+			close(ch)
+			return ch, nil
+		}
+
+		server, clientConn, client := newTestACPBridge(t, func(ctx context.Context, cfg SessionConfig) (SessionRuntime, error) {
+			return rt, nil
+		})
+		client.permissionOutcome = outcome
+		newResp, err := clientConn.NewSession(context.Background(), acp.NewSessionRequest{Cwd: "/tmp/project", McpServers: []acp.McpServer{}})
+		if err != nil {
+			t.Fatalf("new session: %v", err)
+		}
+		if _, err := clientConn.Prompt(context.Background(), acp.PromptRequest{
+			SessionId: newResp.SessionId,
+			Prompt:    []acp.ContentBlock{acp.TextBlock("set up staging")},
+		}); err != nil {
+			t.Fatalf("prompt: %v", err)
+		}
+		if client.permissionCount() != 1 {
+			t.Fatalf("expected 1 review request, got %d", client.permissionCount())
+		}
+		if accepted, ok := rt.decisions["s1"]; !ok || accepted != (outcome == "allow") {
+			t.Fatalf("%s: decisions = %v", outcome, rt.decisions)
+		}
+
+		want := acp.ToolCallStatusCompleted
+		if outcome == "reject" {
+			want = acp.ToolCallStatusFailed
+		}
+		var closed bool
+		for _, update := range client.waitForUpdates(t, 3) {
+			tc := update.Update.ToolCallUpdate
+			if tc != nil && tc.ToolCallId == "memory_suggestion_s1" && tc.Status != nil && *tc.Status == want {
+				closed = true
+			}
+		}
+		if !closed {
+			t.Fatalf("%s: suggestion tool call not closed with %s: %#v", outcome, want, client.snapshot())
+		}
+		_ = server.Close()
+	}
+}
+
+func TestElicitationOptions(t *testing.T) {
+	confirm, choices := elicitationOptions(&mcp.ElicitationRequest{Message: "Proceed?"})
+	if len(confirm) != 2 || choices["accept"].action != mcp.ElicitationAccept || choices["decline"].action != mcp.ElicitationDecline {
+		t.Fatalf("confirmation options = %+v", confirm)
+	}
+
+	pick, choices := elicitationOptions(&mcp.ElicitationRequest{Fields: []mcp.ElicitationField{
+		{Name: "env", Type: "string", Enum: []string{"staging", "prod"}, Required: true},
+	}})
+	if len(pick) != 3 || choices["value:prod"].content["env"] != "prod" {
+		t.Fatalf("enum options = %+v", pick)
+	}
+
+	flag, choices := elicitationOptions(&mcp.ElicitationRequest{Fields: []mcp.ElicitationField{
+		{Name: "force", Type: "boolean"},
+	}})
+	if len(flag) != 3 || choices["value:no"].content["force"] != false {
+		t.Fatalf("boolean options = %+v", flag)
+	}
+
+	defaults, choices := elicitationOptions(&mcp.ElicitationRequest{Fields: []mcp.ElicitationField{
+		{Name: "branch", Type: "string", Default: "main", Required: true},
+		{Name: "note", Type: "string"},
+	}})
+	if len(defaults) != 2 || choices["accept"].content["branch"] != "main" {
+		t.Fatalf("defaults options = %+v", defaults)
+	}
+
+	if typed, _ := elicitationOptions(&mcp.ElicitationRequest{Fields: []mcp.ElicitationField{
+		{Name: "title", Type: "string", Required: true},
+	}}); typed != nil {
+		t.Fatalf("typed input should not map to options: %+v", typed)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/liliang-cn/agent-go/pkg/cassette"
 	"github.com/liliang-cn/agent-go/pkg/config"
 	"github.com/liliang-cn/agent-go/pkg/domain"
 	"github.com/liliang-cn/agent-go/pkg/mcp"
@@ -19,6 +20,7 @@ import (
 	"github.com/liliang-cn/agent-go/pkg/services"
 	"github.com/liliang-cn/agent-go/pkg/skills"
 	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 )
 
 // ============================================================
@@ -96,11 +98,18 @@ type Builder struct {
 	progressCb        ProgressCallback
 	permissionHandler PermissionHandler
 	permissionPolicy  PermissionPolicy
+	// Front end answering MCP servers' requests for user input (optional)
+	elicitor mcp.Elicitor
 
 	// Custom LLM service (optional - if not set, uses global pool)
 	llmService domain.Generator
 	// Custom Embedder service (optional - used with custom LLM for RAG/Memory)
 	embedService domain.Embedder
+	// Cassette recording/replay of LLM calls (optional)
+	cassetteCfg cassette.Config
+	// Span tracing and OTLP export (optional)
+	tracer       *Tracer
+	telemetryCfg telemetry.Config
 
 	enableRAG       bool
 	ragCfg          RAGConfig
@@ -278,6 +287,12 @@ func (b *Builder) WithPermissionPolicy(policy PermissionPolicy) *Builder {
 	return b
 }
 
+// WithElicitor routes MCP servers' requests for user input to e.
+func (b *Builder) WithElicitor(e mcp.Elicitor) *Builder {
+	b.elicitor = e
+	return b
+}
+
 // WithConfig sets agentgo config
 func (b *Builder) WithConfig(cfg *config.Config) *Builder {
 	b.agentgoCfg = cfg
@@ -315,6 +330,51 @@ func (b *Builder) WithEmbedder(embedder domain.Embedder) *Builder {
 	return b
 }
 
+// WithCassette records the agent's LLM calls to a cassette file, or replays
+// them from one so tests run deterministically without network access.
+// In replay mode the global pool is not used at all.
+//
+// Example:
+//
+//	svc, err := agent.New("test-agent").
+//	    WithCassette(cassette.Config{
+//	        Mode:  cassette.ModeReplay,
+//	        Path:  "testdata/weather.cassette.json",
+//	        Match: cassette.MatchLenient,
+//	    }).
+//	    Build()
+func (b *Builder) WithCassette(cfg cassette.Config) *Builder {
+	b.cassetteCfg = cfg
+	return b
+}
+
+// WithTelemetry exports the agent's traces and metrics over OTLP. Agent runs,
+// LLM calls, tool calls, sub-agents, and RAG retrievals become OpenTelemetry
+// spans with GenAI semantic-convention attributes. It overrides the
+// telemetry section of the loaded config.
+//
+// Example:
+//
+//	svc, err := agent.New("traced-agent").
+//	    WithTelemetry(telemetry.Config{
+//	        Enabled:  true,
+//	        Protocol: telemetry.ProtocolGRPC,
+//	        Endpoint: "localhost:4317",
+//	        Insecure: true,
+//	    }).
+//	    Build()
+func (b *Builder) WithTelemetry(cfg telemetry.Config) *Builder {
+	b.telemetryCfg = cfg
+	return b
+}
+
+// WithTracer records spans into an existing tracer, for example one shared
+// by several agents or attached to a custom telemetry provider.
+func (b *Builder) WithTracer(tracer *Tracer) *Builder {
+	b.tracer = tracer
+	return b
+}
+
 // WithTool adds a single tool to the agent inline in the builder chain.
 // Tools registered here are available at Build() time, before PTC sync,
 // so they are reachable via callTool() in JS sandboxes as well.
@@ -382,6 +442,8 @@ func (b *Builder) build() (*Service, error) {
 	var llmSvc domain.Generator
 	if b.llmService != nil {
 		llmSvc = b.llmService
+	} else if b.cassetteCfg.Mode == cassette.ModeReplay {
+		// Replay never calls a model, so the pool is not needed
 	} else {
 		// Initialize global pool for LLM
 		globalPool := services.GetGlobalPoolService()
@@ -394,6 +456,43 @@ func (b *Builder) build() (*Service, error) {
 		}
 	}
 
+	if b.cassetteCfg.Enabled() {
+		llmSvc, err = cassette.Wrap(llmSvc, b.cassetteCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up cassette: %w", err)
+		}
+	}
+
+	tracer := b.tracer
+	telemetryCfg := b.telemetryCfg
+	if !telemetryCfg.Enabled {
+		telemetryCfg = agentgoCfg.Telemetry
+	}
+	var telemetryProvider *telemetry.Provider
+	if telemetryCfg.Enabled {
+		telemetryProvider, err = telemetry.Setup(context.Background(), telemetryCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up telemetry: %w", err)
+		}
+		if tracer == nil {
+			tracer = NewTracer()
+		}
+		tracer.UseTelemetry(telemetryProvider)
+	}
+	var traceStore *TraceStore
+	if agentgoCfg.Tracing.Enabled {
+		traceStore, err = NewTraceStore(agentgoCfg.TracesPath(), agentgoCfg.TraceRetention())
+		if err != nil {
+			log.Printf("[Agent] Warning: trace persistence disabled: %v", err)
+		} else {
+			if tracer == nil {
+				tracer = NewTracer()
+			}
+			tracer.UseStore(traceStore)
+		}
+	}
+	llmSvc = traceGenerator(llmSvc, tracer)
+
 	// Determine Embedder service: use custom if provided, otherwise try global pool
 	var embedSvc domain.Embedder
 	if b.embedService != nil {
@@ -412,6 +511,9 @@ func (b *Builder) build() (*Service, error) {
 	// Build MCP
 	var mcpSvc *mcp.Service
 	var mcpAdapter MCPToolExecutor
+	var sampling *mcp.SamplingHandler
+	var samplingRecorder *samplingUsage
+	var watchMCP bool
 	if b.enableMCP {
 		mcpCfg := &agentgoCfg.MCP
 		if len(b.mcpCfgPaths) > 0 {
@@ -425,10 +527,18 @@ func (b *Builder) build() (*Service, error) {
 		if err != nil {
 			log.Printf("[WARN] Failed to create MCP service: %v", err)
 		} else {
+			if mcpCfg.Sampling.Enabled {
+				sampling, samplingRecorder = newSamplingHandler(agentgoCfg, mcpCfg.Sampling, llmSvc)
+				mcpSvc.SetSamplingHandler(sampling)
+			}
+			if b.elicitor != nil {
+				mcpSvc.SetElicitor(b.elicitor)
+			}
 			if startErr := mcpSvc.StartServers(context.Background(), nil); startErr != nil {
 				log.Printf("[WARN] Failed to start MCP servers: %v", startErr)
 			}
 			mcpAdapter = &mcpToolAdapter{service: mcpSvc}
+			watchMCP = mcpCfg.WatchServers
 		}
 	}
 
@@ -451,6 +561,7 @@ func (b *Builder) build() (*Service, error) {
 			if err != nil {
 				return nil, fmt.Errorf("failed to create RAG processor: %w", err)
 			}
+			b.shareRAGGraph(agentgoCfg, ragProcessor, memSvc)
 		}
 	}
 
@@ -488,6 +599,9 @@ func (b *Builder) build() (*Service, error) {
 		return nil, fmt.Errorf("failed to create service: %w", err)
 	}
 	svc.cfg = agentgoCfg
+	svc.tracer = tracer
+	svc.telemetry = telemetryProvider
+	svc.traceStore = traceStore
 
 	// Apply debug config: either from WithDebug() builder call or global agentgoCfg.Debug (e.g. from DEBUG=1 env var)
 	if agentgoCfg.Debug {
@@ -496,7 +610,7 @@ func (b *Builder) build() (*Service, error) {
 	svc.SetDebug(b.debug)
 
 	// Register module tools into the unified ToolRegistry.
-	// Built-in modules (RAG, Memory) are registered first, then any extra
+	// Built-in modules (RAG, Memory, MCP context) are registered first, then any extra
 	// modules added via WithModule(). All registered tools are available to
 	// both collectAllAvailableTools() and PTC's callTool().
 	if ragProcessor != nil {
@@ -511,6 +625,12 @@ func (b *Builder) build() (*Service, error) {
 			return nil, fmt.Errorf("memory module registration failed: %w", err)
 		}
 	}
+	if mcpSvc != nil {
+		mcpMod := NewMCPContextModule(mcpSvc, ragProcessor, svc.watchResource)
+		if err := mcpMod.RegisterTools(svc.toolRegistry); err != nil {
+			return nil, fmt.Errorf("mcp context module registration failed: %w", err)
+		}
+	}
 	for _, mod := range b.extraModules {
 		if err := mod.RegisterTools(svc.toolRegistry); err != nil {
 			return nil, fmt.Errorf("module %q registration failed: %w", mod.ID(), err)
@@ -575,6 +695,10 @@ func (b *Builder) build() (*Service, error) {
 	if b.permissionPolicy != nil {
 		svc.SetPermissionPolicy(b.permissionPolicy)
 	}
+	if sampling != nil {
+		sampling.SetApprover(svc.approveSampling)
+		svc.samplingUsage = samplingRecorder
+	}
 
 	// PTC
 	if b.enablePTC && b.ptcCfg != nil {
@@ -630,6 +754,9 @@ func (b *Builder) build() (*Service, error) {
 	}
 	if mcpSvc != nil {
 		svc.SetMCPService(mcpSvc)
+		if watchMCP {
+			svc.stopMCPWatch = mcpSvc.WatchServers(2 * time.Second)
+		}
 	}
 
 	return svc, nil
@@ -648,6 +775,23 @@ func resolveServiceModelInfo(llmSvc domain.Generator, cfg *config.Config) (strin
 	return "", ""
 }
 
+// shareRAGGraph points the memory entity graph at the GraphRAG store when
+// memory.graph.backend is "rag"
+func (b *Builder) shareRAGGraph(agentgoCfg *config.Config, ragProcessor domain.Processor, memSvc domain.MemoryService) {
+	if graph := agentgoCfg.Memory.Graph; !graph.Enabled || graph.Backend != "rag" {
+		return
+	}
+	ms, ok := memSvc.(*memory.Service)
+	rp, rok := ragProcessor.(*ragprocessor.Service)
+	if !ok || !rok || rp.GraphStore() == nil {
+		log.Printf("[WARN] memory.graph.backend is 'rag' but no RAG graph store is available. Memory graph disabled.")
+		return
+	}
+	if err := ms.SetGraphStore(rp.GraphStore()); err != nil {
+		log.Printf("[WARN] Memory graph disabled: %v", err)
+	}
+}
+
 func (b *Builder) buildMemoryService(agentgoCfg *config.Config, embedSvc domain.Embedder, llmSvc domain.Generator) (domain.MemoryService, error) {
 	var memStore domain.MemoryStore
 	var shadowStore domain.MemoryStore
@@ -671,10 +815,16 @@ func (b *Builder) buildMemoryService(agentgoCfg *config.Config, embedSvc domain.
 
 	switch storeType {
 	case "file":
-		memStore, err = store.NewFileMemoryStore(memPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create file memory store: %w", err)
+		fileStore, ferr := store.NewFileMemoryStore(memPath)
+		if ferr != nil {
+			return nil, fmt.Errorf("failed to create file memory store: %w", ferr)
+		}
+		if agentgoCfg.Memory.Git.Enabled {
+			if err := fileStore.EnableGit(); err != nil {
+				return nil, err
+			}
 		}
+		memStore = fileStore
 	case "vector":
 		sqlitePath := filepath.Join(agentgoCfg.DataDir(), "agentgo.db")
 		memStore, err = store.NewMemoryStore(sqlitePath)
@@ -693,6 +843,11 @@ func (b *Builder) buildMemoryService(agentgoCfg *config.Config, embedSvc domain.
 		if llmSvc != nil {
 			fileStore.WithLLM(llmSvc)
 		}
+		if agentgoCfg.Memory.Git.Enabled {
+			if err := fileStore.EnableGit(); err != nil {
+				return nil, err
+			}
+		}
 		memStore = fileStore
 		sqlitePath := filepath.Join(agentgoCfg.DataDir(), "agentgo.db")
 		if sqliteStore, serr := store.NewMemoryStore(sqlitePath); serr == nil {
@@ -707,16 +862,62 @@ func (b *Builder) buildMemoryService(agentgoCfg *config.Config, embedSvc domain.
 	if b.memoryCfg.ReflectThreshold > 0 {
 		memCfg.ReflectThreshold = b.memoryCfg.ReflectThreshold
 	}
+	episodes := agentgoCfg.Memory.Episodes
+	memCfg.Episodes = &memory.EpisodeConfig{
+		Enabled:       episodes.Enabled,
+		MaxExamples:   episodes.MaxExamples,
+		MinSimilarity: episodes.MinSimilarity,
+	}
+	if episodes.FailureWeight > 0 {
+		memCfg.ScoringConfig.FailureWeight = episodes.FailureWeight
+	}
 
 	memSvc := memory.NewService(memStore, llmSvc, embedSvc, memCfg)
 	if shadowStore != nil {
 		memSvc.SetShadowIndex(shadowStore)
 	}
+	if access := agentgoCfg.Memory.Access; access.Enabled {
+		if err := memSvc.ConfigureAccess(access.Read, access.Write, access.AuditLog); err != nil {
+			return nil, fmt.Errorf("failed to configure memory access control: %w", err)
+		}
+	}
+	if graph := agentgoCfg.Memory.Graph; graph.Enabled && graph.Backend != "rag" {
+		graphPath := graph.Path
+		if graphPath == "" {
+			graphPath = filepath.Join(memPath, "graph.json")
+		}
+		graphStore, err := store.NewFileGraphStore(graphPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open memory graph: %w", err)
+		}
+		if err := memSvc.SetGraphStore(graphStore); err != nil {
+			return nil, fmt.Errorf("failed to enable memory graph: %w", err)
+		}
+	}
+
+	if suggestions := agentgoCfg.Memory.Suggestions; suggestions.Mode != "" && suggestions.Mode != string(memory.SuggestionModeAuto) {
+		suggestionPath := suggestions.Path
+		if suggestionPath == "" {
+			suggestionPath = filepath.Join(memPath, "suggestions.json")
+		}
+		suggestionStore, err := store.NewFileSuggestionStore(suggestionPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open memory suggestions: %w", err)
+		}
+		if err := memSvc.SetSuggestionPolicy(memory.SuggestionPolicy{
+			Mode:                 memory.SuggestionMode(suggestions.Mode),
+			Timeout:              suggestions.Timeout,
+			OnTimeout:            suggestions.OnTimeout,
+			AutoAcceptConfidence: suggestions.AutoAcceptConfidence,
+		}, suggestionStore); err != nil {
+			return nil, fmt.Errorf("failed to configure memory suggestions: %w", err)
+		}
+	}
 
 	// Seed MemoryBank directives as high-priority preference memories
 	if b.memoryCfg.Mission != "" || len(b.memoryCfg.Directives) > 0 {
 		go func() {
-			bCtx := context.Background()
+			bCtx := domain.WithSystemMemoryPrincipal(context.Background())
 			if b.memoryCfg.Mission != "" {
 				_ = memSvc.Add(bCtx, &domain.Memory{
 					Type:       domain.MemoryTypePreference,
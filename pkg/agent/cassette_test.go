@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/cassette"
+	"github.com/liliang-cn/agent-go/pkg/config"
+)
+
+func TestBuilderWithCassetteReplaysOffline(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "chat.cassette.json")
+
+	recorder, err := New("cassette-agent").
+		WithConfig(cassetteTestConfig(t)).
+		WithLLM(&fileMemoryTestLLM{}).
+		WithCassette(cassette.Config{Mode: cassette.ModeRecord, Path: path}).
+		Build()
+	if err != nil {
+		t.Fatalf("build recorder failed: %v", err)
+	}
+	recorded, err := recorder.Chat(ctx, "remember: Alice likes tea")
+	recorder.Close()
+	if err != nil {
+		t.Fatalf("recorded chat failed: %v", err)
+	}
+
+	// No LLM is configured for replay; every response comes from the cassette
+	replayer, err := New("cassette-agent").
+		WithConfig(cassetteTestConfig(t)).
+		WithCassette(cassette.Config{Mode: cassette.ModeReplay, Path: path, Match: cassette.MatchLenient}).
+		Build()
+	if err != nil {
+		t.Fatalf("build replayer failed: %v", err)
+	}
+	defer replayer.Close()
+
+	replayed, err := replayer.Chat(ctx, "remember: Alice likes tea")
+	if err != nil {
+		t.Fatalf("replayed chat failed: %v", err)
+	}
+	if replayed.Text() != recorded.Text() {
+		t.Fatalf("replayed %q, recorded %q", replayed.Text(), recorded.Text())
+	}
+}
+
+func cassetteTestConfig(t *testing.T) *config.Config {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "data"), 0755); err != nil {
+		t.Fatalf("create data dir: %v", err)
+	}
+	return testAgentConfig(home)
+}
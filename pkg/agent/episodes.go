@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// episodicMemory is implemented by memory services that remember past runs
+// (memory.Service). Other domain.MemoryService implementations simply get no
+// episode examples.
+type episodicMemory interface {
+	EpisodeContext(ctx context.Context, goal string) (string, error)
+	RecordEpisode(ctx context.Context, ep *domain.Episode) (*domain.Memory, error)
+}
+
+// episodeContext returns "last time a similar task..." examples for goal
+func (s *Service) episodeContext(ctx context.Context, goal string) string {
+	em, ok := s.memoryService.(episodicMemory)
+	if !ok {
+		return ""
+	}
+	text, err := em.EpisodeContext(ctx, goal)
+	if err != nil {
+		s.logger.Warn("episode recall failed", slog.String("error", err.Error()))
+		return ""
+	}
+	return text
+}
+
+// recordEpisode distills a finished run into episodic memory
+func (s *Service) recordEpisode(ctx context.Context, ep *domain.Episode) {
+	em, ok := s.memoryService.(episodicMemory)
+	if !ok {
+		return
+	}
+	if _, err := em.RecordEpisode(ctx, ep); err != nil {
+		s.logger.Warn("failed to record episode", slog.String("error", err.Error()))
+	}
+}
+
+// recordPlanEpisode distills an executed plan into episodic memory. The
+// episode's plan is the path that was finally taken; its tool sequence also
+// covers steps later replaced by a revision.
+func (s *Service) recordPlanEpisode(ctx context.Context, plan *Plan, result *ExecutionResult) {
+	ep := &domain.Episode{
+		SessionID:    plan.SessionID,
+		AgentID:      s.agent.ID(),
+		Goal:         plan.Goal,
+		Plan:         planOutline(plan),
+		ToolSequence: planToolSequence(plan),
+		Outcome:      domain.EpisodeSuccess,
+		Result:       formatResultForContent(result.FinalResult),
+	}
+	if !result.Success {
+		ep.Outcome = domain.EpisodeFailure
+		ep.Error = result.Error
+	}
+	if result.StartedAt != nil && result.CompletedAt != nil {
+		ep.DurationMs = result.CompletedAt.Sub(*result.StartedAt).Milliseconds()
+	}
+	s.recordEpisode(ctx, ep)
+}
+
+// planOutline lists the plan's steps as numbered lines
+func planOutline(plan *Plan) string {
+	var sb strings.Builder
+	for i, step := range plan.Steps {
+		fmt.Fprintf(&sb, "%d. %s (%s)\n", i+1, step.Description, step.Tool)
+	}
+	if n := len(plan.Revisions); n > 0 {
+		fmt.Fprintf(&sb, "Revisions: %d\n", n)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// planToolSequence returns the tools of every step that ran, including
+// steps replaced by a revision, in the order they started
+func planToolSequence(plan *Plan) []string {
+	var ran []Step
+	collect := func(steps []Step) {
+		for _, step := range steps {
+			if step.StartedAt != nil && step.Tool != "" && step.Tool != "llm" {
+				ran = append(ran, step)
+			}
+		}
+	}
+	for _, rev := range plan.Revisions {
+		collect(rev.ReplacedSteps)
+	}
+	collect(plan.Steps)
+	sort.SliceStable(ran, func(i, j int) bool { return ran[i].StartedAt.Before(*ran[j].StartedAt) })
+
+	tools := make([]string, len(ran))
+	for i, step := range ran {
+		tools[i] = step.Tool
+	}
+	return tools
+}
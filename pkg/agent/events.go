@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 )
 
 // EventType defines the type of event in the runtime loop
@@ -31,6 +32,12 @@ const (
 
 	// Debug (prompts/responses, emitted when debug=true)
 	EventTypeDebug EventType = "debug"
+
+	// Memory suggestions (emitted after completion in memory suggest mode)
+	EventTypeMemorySuggestion EventType = "memory_suggestion"
+
+	// MCP servers changed their tools since the previous run (emitted after start)
+	EventTypeToolsChanged EventType = "tools_changed"
 )
 
 // Event represents a discrete occurrence in the agent execution loop
@@ -56,6 +63,12 @@ type Event struct {
 	Round     int    `json:"round,omitempty"`
 	DebugType string `json:"debug_type,omitempty"` // "prompt" or "response"
 
+	// Candidate memory awaiting review (EventTypeMemorySuggestion only)
+	MemorySuggestion *domain.MemorySuggestion `json:"memory_suggestion,omitempty"`
+
+	// MCP catalog changes (EventTypeToolsChanged only)
+	CatalogChanges []mcp.CatalogEvent `json:"catalog_changes,omitempty"`
+
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -42,6 +42,12 @@ type EventHandlers struct {
 	// OnDebug is called when debug information is available
 	OnDebug EventHandler
 
+	// OnMemorySuggestion is called for each memory proposed for review
+	OnMemorySuggestion EventHandler
+
+	// OnToolsChanged is called when MCP servers changed their tools
+	OnToolsChanged EventHandler
+
 	// OnAny is called for all events (catch-all)
 	OnAny EventHandler
 }
@@ -72,6 +78,10 @@ func (h *EventHandlers) SetHandler(eventType EventType, handler EventHandler) {
 		h.OnError = handler
 	case EventTypeDebug:
 		h.OnDebug = handler
+	case EventTypeMemorySuggestion:
+		h.OnMemorySuggestion = handler
+	case EventTypeToolsChanged:
+		h.OnToolsChanged = handler
 	}
 }
 
@@ -126,6 +136,14 @@ func (h *EventHandlers) Handle(event *Event) {
 		if h.OnDebug != nil {
 			h.OnDebug(event)
 		}
+	case EventTypeMemorySuggestion:
+		if h.OnMemorySuggestion != nil {
+			h.OnMemorySuggestion(event)
+		}
+	case EventTypeToolsChanged:
+		if h.OnToolsChanged != nil {
+			h.OnToolsChanged(event)
+		}
 	}
 }
 
@@ -137,24 +137,30 @@ func (e *Executor) ExecutePlan(ctx context.Context, plan *Plan, session *Session
 	}
 
 	// Store memories after successful task completion
-	if e.memoryService != nil {
-		log.Println("[Agent] Analyzing task for long-term memory storage...")
-		err := e.memoryService.StoreIfWorthwhile(context.Background(), &domain.MemoryStoreRequest{
-			SessionID:    plan.SessionID,
-			TaskGoal:     plan.Goal,
-			TaskResult:   formatResultForContent(finalResult),
-			ExecutionLog: e.buildExecutionLog(plan),
-		})
-		if err != nil {
-			log.Printf("[Agent] Warning: memory storage failed: %v", err)
-		} else {
-			log.Println("[Agent] Memory analysis completed.")
-		}
-	}
+	e.storePlanMemories(ctx, plan, finalResult)
 
 	return result, nil
 }
 
+// storePlanMemories lets the memory service extract long-term memories from a finished plan
+func (e *Executor) storePlanMemories(ctx context.Context, plan *Plan, finalResult interface{}) {
+	if e.memoryService == nil {
+		return
+	}
+	log.Println("[Agent] Analyzing task for long-term memory storage...")
+	err := e.memoryService.StoreIfWorthwhile(context.WithoutCancel(ctx), &domain.MemoryStoreRequest{
+		SessionID:    plan.SessionID,
+		TaskGoal:     plan.Goal,
+		TaskResult:   formatResultForContent(finalResult),
+		ExecutionLog: e.buildExecutionLog(plan),
+	})
+	if err != nil {
+		log.Printf("[Agent] Warning: memory storage failed: %v", err)
+	} else {
+		log.Println("[Agent] Memory analysis completed.")
+	}
+}
+
 // ExecuteStep executes a single step
 func (e *Executor) ExecuteStep(ctx context.Context, step *Step, plan *Plan, session *Session) (interface{}, error) {
 	step.Status = StepStatusRunning
@@ -364,7 +364,7 @@ func (s *LongRunService) processChecklistItem(ctx context.Context, item Checklis
 	// Save result to unified memory
 	if result.Success && result.FinalResult != nil {
 		entry := fmt.Sprintf("Checklist item '%s': %s", item.Description, result.Text())
-		if err := s.saveMemory(context.Background(), entry); err != nil {
+		if err := s.saveMemory(domain.WithSystemMemoryPrincipal(context.Background()), entry); err != nil {
 			s.logger.Warn("Failed to save to memory", "error", err)
 		}
 	}
@@ -431,7 +431,7 @@ func (s *LongRunService) executeTask(ctx context.Context, task *Task) error {
 	// Save result to unified memory
 	if task.Result != "" {
 		entry := fmt.Sprintf("Task '%s': %s", task.Goal, task.Result)
-		if err := s.saveMemory(context.Background(), entry); err != nil {
+		if err := s.saveMemory(domain.WithSystemMemoryPrincipal(context.Background()), entry); err != nil {
 			s.logger.Warn("Failed to save to memory", "error", err)
 		}
 	}
@@ -523,7 +523,7 @@ func (s *LongRunService) buildContextPrompt(goal string) (string, error) {
 
 	// 2. Relevant memories from DB (semantic search), or fall back to file MEMORY.md
 	if s.memSvc != nil {
-		memContext, _, err := s.memSvc.RetrieveAndInject(context.Background(), goal, "longrun")
+		memContext, _, err := s.memSvc.RetrieveAndInject(domain.WithSystemMemoryPrincipal(context.Background()), goal, "longrun")
 		if err == nil && memContext != "" {
 			sb.WriteString("\n\n# Relevant Memory\n\n")
 			sb.WriteString(memContext)
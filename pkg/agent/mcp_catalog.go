@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+// maxPendingCatalogChanges bounds the changes kept between runs; a long idle
+// agent only needs to know that the catalog moved, not every step
+const maxPendingCatalogChanges = 50
+
+// onMCPCatalogChange keeps the tool registry in step with the MCP servers
+// and remembers the change for the next run
+func (s *Service) onMCPCatalogChange(ev mcp.CatalogEvent) {
+	if ev.Reason == mcp.CatalogUpdated {
+		s.onResourceUpdated(ev)
+		return
+	}
+	if !ev.ChangesTools() {
+		return
+	}
+	s.mcpCatalogMu.Lock()
+	s.mcpCatalogChanges = append(s.mcpCatalogChanges, ev)
+	if n := len(s.mcpCatalogChanges); n > maxPendingCatalogChanges {
+		s.mcpCatalogChanges = s.mcpCatalogChanges[n-maxPendingCatalogChanges:]
+	}
+	s.mcpCatalogMu.Unlock()
+
+	if mcpSvc := s.MCP; mcpSvc != nil && s.toolRegistry != nil {
+		go s.syncMCPToolsInRegistry(mcpSvc)
+	}
+}
+
+// takeMCPCatalogChanges returns and clears the changes seen since the last run
+func (s *Service) takeMCPCatalogChanges() []mcp.CatalogEvent {
+	s.mcpCatalogMu.Lock()
+	defer s.mcpCatalogMu.Unlock()
+	changes := s.mcpCatalogChanges
+	s.mcpCatalogChanges = nil
+	return changes
+}
+
+// takeMCPChanges returns the MCP catalog changes and the updated watched
+// resources seen since the last run. Every run calls it while preparing its
+// context, whichever way it executes.
+func (s *Service) takeMCPChanges(ctx context.Context) ([]mcp.CatalogEvent, string) {
+	return s.takeMCPCatalogChanges(), s.takeResourceUpdates(ctx)
+}
+
+// describeCatalogChanges summarizes changes for the tools_changed event,
+// e.g. "MCP tools changed: github restarted, files list_changed"
+func describeCatalogChanges(changes []mcp.CatalogEvent) string {
+	seen := make(map[string]bool, len(changes))
+	parts := make([]string, 0, len(changes))
+	for _, c := range changes {
+		part := c.Server + " " + c.Reason
+		if seen[part] {
+			continue
+		}
+		seen[part] = true
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("MCP tools changed: %s", strings.Join(parts, ", "))
+}
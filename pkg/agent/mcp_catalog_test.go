@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+func TestMCPCatalogChangesReportedOnce(t *testing.T) {
+	svc := &Service{}
+	svc.onMCPCatalogChange(mcp.CatalogEvent{Server: "docs", Kind: mcp.CatalogResources, Reason: mcp.CatalogListChanged})
+	svc.onMCPCatalogChange(mcp.CatalogEvent{Server: "github", Kind: mcp.CatalogServers, Reason: mcp.CatalogRestarted})
+	svc.onMCPCatalogChange(mcp.CatalogEvent{Server: "files", Kind: mcp.CatalogTools, Reason: mcp.CatalogListChanged})
+	svc.onMCPCatalogChange(mcp.CatalogEvent{Server: "files", Kind: mcp.CatalogTools, Reason: mcp.CatalogListChanged})
+
+	changes := svc.takeMCPCatalogChanges()
+	if len(changes) != 3 {
+		t.Fatalf("changes = %+v, want the three tool changes", changes)
+	}
+	if got, want := describeCatalogChanges(changes), "MCP tools changed: github restarted, files list_changed"; got != want {
+		t.Fatalf("describeCatalogChanges() = %q, want %q", got, want)
+	}
+	if again := svc.takeMCPCatalogChanges(); len(again) != 0 {
+		t.Fatalf("changes reported twice: %+v", again)
+	}
+
+	for i := 0; i < maxPendingCatalogChanges+10; i++ {
+		svc.onMCPCatalogChange(mcp.CatalogEvent{Server: fmt.Sprint(i), Kind: mcp.CatalogTools, Reason: mcp.CatalogListChanged})
+	}
+	changes = svc.takeMCPCatalogChanges()
+	if len(changes) != maxPendingCatalogChanges || changes[0].Server != "10" {
+		t.Fatalf("kept %d changes starting at %q", len(changes), changes[0].Server)
+	}
+}
+
+func TestToolRegistryNamesInCategory(t *testing.T) {
+	r := NewToolRegistry()
+	for name, category := range map[string]string{"mcp_files_read": CategoryMCP, "memory_save": CategoryMemory} {
+		r.Register(domain.ToolDefinition{Type: "function", Function: domain.ToolFunction{Name: name}}, nil, category)
+	}
+
+	names := r.NamesInCategory(CategoryMCP)
+	if len(names) != 1 || names[0] != "mcp_files_read" {
+		t.Fatalf("NamesInCategory(mcp) = %v", names)
+	}
+}
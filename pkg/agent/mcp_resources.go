@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+)
+
+// maxResourceContext caps how much of one resource is put into a prompt
+const maxResourceContext = 32 * 1024
+
+// resourceRef identifies a resource on a server
+type resourceRef struct {
+	Server string
+	URI    string
+}
+
+func (r resourceRef) String() string { return "@" + r.Server + ":" + r.URI }
+
+// ── MCP Context Module ────────────────────────────────────────────────────────
+
+type mcpContextModule struct {
+	mcp    *mcp.Service
+	proc   domain.Processor
+	onRead func(ctx context.Context, server, uri string) // called after read_resource
+}
+
+// NewMCPContextModule creates a Module that registers read_resource and
+// use_prompt tools over the resources and prompts of the running MCP
+// servers, plus ingest_resource when proc is not nil.
+// onRead is invoked after each resource read so the caller can watch it
+// (may be nil).
+func NewMCPContextModule(mcpSvc *mcp.Service, proc domain.Processor, onRead func(ctx context.Context, server, uri string)) Module {
+	return &mcpContextModule{mcp: mcpSvc, proc: proc, onRead: onRead}
+}
+
+func (m *mcpContextModule) ID() string { return "mcp_context" }
+
+func (m *mcpContextModule) RegisterTools(registry *ToolRegistry) error {
+	registry.Register(domain.ToolDefinition{
+		Type: "function",
+		Function: domain.ToolFunction{
+			Name:        "read_resource",
+			Description: "Read a resource (file, document, record) offered by an MCP server. Call without 'uri' to list the available resources and resource templates.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"uri":    map[string]interface{}{"type": "string", "description": "Resource URI"},
+					"server": map[string]interface{}{"type": "string", "description": "MCP server name (optional, looked up by URI)"},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		uri, _ := args["uri"].(string)
+		server, _ := args["server"].(string)
+		if uri == "" {
+			return map[string]interface{}{
+				"resources": m.mcp.ListResources(),
+				"templates": m.mcp.ListResourceTemplates(),
+			}, nil
+		}
+		res, err := m.mcp.ReadResource(ctx, server, uri)
+		if err != nil {
+			return nil, err
+		}
+		if m.onRead != nil {
+			m.onRead(ctx, res.ServerName, res.URI)
+		}
+		return map[string]interface{}{"server": res.ServerName, "uri": res.URI, "mime_type": res.MIMEType, "content": truncateResource(res.Text())}, nil
+	}, CategoryMCPContext)
+
+	registry.Register(domain.ToolDefinition{
+		Type: "function",
+		Function: domain.ToolFunction{
+			Name:        "use_prompt",
+			Description: "Render a prompt template offered by an MCP server and return its messages. Call without 'name' to list the available prompts and their arguments.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":      map[string]interface{}{"type": "string", "description": "Prompt name"},
+					"server":    map[string]interface{}{"type": "string", "description": "MCP server name (optional, looked up by name)"},
+					"arguments": map[string]interface{}{"type": "object", "description": "Prompt arguments as string values"},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		name, _ := args["name"].(string)
+		server, _ := args["server"].(string)
+		if name == "" {
+			return map[string]interface{}{"prompts": m.mcp.ListPrompts()}, nil
+		}
+		promptArgs := map[string]string{}
+		if raw, ok := args["arguments"].(map[string]interface{}); ok {
+			for k, v := range raw {
+				promptArgs[k] = fmt.Sprint(v)
+			}
+		}
+		prompt, err := m.mcp.GetPrompt(ctx, server, name, promptArgs)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"server": prompt.ServerName, "name": prompt.Name, "prompt": prompt.Text()}, nil
+	}, CategoryMCPContext)
+
+	if m.proc == nil {
+		return nil
+	}
+	registry.Register(domain.ToolDefinition{
+		Type: "function",
+		Function: domain.ToolFunction{
+			Name:        "ingest_resource",
+			Description: "Ingest a text resource from an MCP server into the RAG knowledge base",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"uri":    map[string]interface{}{"type": "string", "description": "Resource URI"},
+					"server": map[string]interface{}{"type": "string", "description": "MCP server name (optional, looked up by URI)"},
+				},
+				"required": []string{"uri"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		uri, _ := args["uri"].(string)
+		server, _ := args["server"].(string)
+		if uri == "" {
+			return nil, fmt.Errorf("ingest_resource: 'uri' argument is required")
+		}
+		resp, err := m.mcp.IngestResource(ctx, m.proc, server, uri)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"status": "ingested", "document_id": resp.DocumentID, "chunks": resp.ChunkCount}, nil
+	}, CategoryMCPContext)
+
+	return nil
+}
+
+// ── Mentions and watched resources ────────────────────────────────────────────
+
+// AttachResourceMentions reads the MCP resources referenced as @server:uri in
+// text and appends them as context. The resources are watched: when a server
+// reports an update, the next run receives the new content. Mentions that
+// cannot be read are reported in the error; the others are still attached.
+func (s *Service) AttachResourceMentions(ctx context.Context, text string) (string, error) {
+	mentions := mcp.ParseResourceMentions(text)
+	if len(mentions) == 0 || s.MCP == nil {
+		return text, nil
+	}
+	var sb strings.Builder
+	sb.WriteString(text)
+	var errs []error
+	seen := make(map[resourceRef]bool, len(mentions))
+	for _, mention := range mentions {
+		ref := resourceRef{Server: mention.Server, URI: mention.URI}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		res, err := s.MCP.ReadResource(ctx, ref.Server, ref.URI)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			continue
+		}
+		s.watchResource(ctx, ref.Server, ref.URI)
+		fmt.Fprintf(&sb, "\n\n--- MCP Resource %s ---\n%s", ref, truncateResource(res.Text()))
+	}
+	return sb.String(), errors.Join(errs...)
+}
+
+// watchResource subscribes to updates of a resource the agent has seen.
+// Servers without subscription support are still read, just not watched.
+func (s *Service) watchResource(ctx context.Context, server, uri string) {
+	if s.MCP == nil || server == "" {
+		return
+	}
+	ref := resourceRef{Server: server, URI: uri}
+	s.mcpCatalogMu.Lock()
+	if s.mcpWatched[ref] {
+		s.mcpCatalogMu.Unlock()
+		return
+	}
+	if s.mcpWatched == nil {
+		s.mcpWatched = make(map[resourceRef]bool)
+	}
+	s.mcpWatched[ref] = true
+	s.mcpCatalogMu.Unlock()
+
+	if err := s.MCP.SubscribeResource(ctx, server, uri); err != nil && s.debug {
+		fmt.Printf("[Agent] not watching %s: %v\n", ref, err)
+	}
+}
+
+// onResourceUpdated queues a watched resource for re-reading on the next run
+func (s *Service) onResourceUpdated(ev mcp.CatalogEvent) {
+	ref := resourceRef{Server: ev.Server, URI: ev.URI}
+	s.mcpCatalogMu.Lock()
+	defer s.mcpCatalogMu.Unlock()
+	if !s.mcpWatched[ref] {
+		return
+	}
+	for _, pending := range s.mcpUpdated {
+		if pending == ref {
+			return
+		}
+	}
+	s.mcpUpdated = append(s.mcpUpdated, ref)
+}
+
+// takeResourceUpdates re-reads the watched resources that changed since the
+// last run and returns them as prompt context
+func (s *Service) takeResourceUpdates(ctx context.Context) string {
+	s.mcpCatalogMu.Lock()
+	updated := s.mcpUpdated
+	s.mcpUpdated = nil
+	s.mcpCatalogMu.Unlock()
+	if len(updated) == 0 || s.MCP == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, ref := range updated {
+		res, err := s.MCP.ReadResource(ctx, ref.Server, ref.URI)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s changed but could not be read: %v\n\n", ref, err)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s changed, current content:\n%s\n\n", ref, truncateResource(res.Text()))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func truncateResource(text string) string {
+	if len(text) <= maxResourceContext {
+		return text
+	}
+	return text[:maxResourceContext] + "\n[... truncated]"
+}
@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type recordingProcessor struct {
+	domain.Processor
+	ingested []domain.IngestRequest
+}
+
+func (p *recordingProcessor) Ingest(ctx context.Context, req domain.IngestRequest) (domain.IngestResponse, error) {
+	p.ingested = append(p.ingested, req)
+	return domain.IngestResponse{Success: true, DocumentID: "doc-1", ChunkCount: 1}, nil
+}
+
+// newResourceMCP starts an MCP service connected to a server with one
+// subscribable resource whose text can be changed with set
+func newResourceMCP(t *testing.T) (*mcp.Service, func(text string)) {
+	t.Helper()
+	var text atomic.Value
+	text.Store("v1")
+	server := sdk.NewServer(&sdk.Implementation{Name: "notes", Version: "1.0.0"}, &sdk.ServerOptions{
+		SubscribeHandler:   func(context.Context, *sdk.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *sdk.UnsubscribeRequest) error { return nil },
+	})
+	server.AddResource(&sdk.Resource{URI: "notes://todo", Name: "todo"},
+		func(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+			return &sdk.ReadResourceResult{Contents: []*sdk.ResourceContents{{URI: req.Params.URI, Text: text.Load().(string)}}}, nil
+		})
+	httpSrv := httptest.NewServer(sdk.NewStreamableHTTPHandler(func(*http.Request) *sdk.Server { return server }, nil))
+	t.Cleanup(httpSrv.Close)
+
+	cfg := mcp.DefaultConfig()
+	mcpSvc, err := mcp.NewService(&cfg, nil)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	cfg.AddServer(&mcp.ServerConfig{Name: "notes", Type: mcp.ServerTypeHTTP, URL: httpSrv.URL})
+	t.Cleanup(func() { mcpSvc.Close() })
+	if err := mcpSvc.StartServers(context.Background(), []string{"notes"}); err != nil {
+		t.Fatalf("StartServers: %v", err)
+	}
+	return mcpSvc, func(v string) {
+		text.Store(v)
+		_ = server.ResourceUpdated(context.Background(), &sdk.ResourceUpdatedNotificationParams{URI: "notes://todo"})
+	}
+}
+
+func TestMCPContextModuleTools(t *testing.T) {
+	mcpSvc, _ := newResourceMCP(t)
+	proc := &recordingProcessor{}
+	registry := NewToolRegistry()
+	var read []string
+	mod := NewMCPContextModule(mcpSvc, proc, func(ctx context.Context, server, uri string) {
+		read = append(read, server+" "+uri)
+	})
+	if err := mod.RegisterTools(registry); err != nil {
+		t.Fatalf("RegisterTools: %v", err)
+	}
+	if names := registry.NamesInCategory(CategoryMCPContext); len(names) != 3 {
+		t.Fatalf("registered %v, want read_resource, use_prompt and ingest_resource", names)
+	}
+
+	ctx := context.Background()
+	out, err := registry.Call(ctx, "read_resource", map[string]interface{}{"uri": "notes://todo"})
+	if err != nil {
+		t.Fatalf("read_resource: %v", err)
+	}
+	if got := out.(map[string]interface{})["content"]; got != "v1" {
+		t.Fatalf("content = %v, want v1", got)
+	}
+	if len(read) != 1 || read[0] != "notes notes://todo" {
+		t.Fatalf("onRead calls = %v", read)
+	}
+
+	if _, err := registry.Call(ctx, "ingest_resource", map[string]interface{}{"uri": "notes://todo"}); err != nil {
+		t.Fatalf("ingest_resource: %v", err)
+	}
+	if len(proc.ingested) != 1 || proc.ingested[0].Content != "v1" {
+		t.Fatalf("ingested %+v", proc.ingested)
+	}
+
+	if _, err := registry.Call(ctx, "use_prompt", map[string]interface{}{"name": "missing"}); err == nil {
+		t.Fatal("use_prompt with an unknown prompt should fail")
+	}
+}
+
+func TestAttachResourceMentionsWatchesUpdates(t *testing.T) {
+	mcpSvc, set := newResourceMCP(t)
+	svc := &Service{}
+	svc.SetMCPService(mcpSvc)
+	defer svc.mcpCatalogUnsub()
+	ctx := context.Background()
+
+	text, err := svc.AttachResourceMentions(ctx, "summarize @notes:notes://todo and @notes:notes://gone")
+	if err == nil || !strings.Contains(err.Error(), "@notes:notes://gone") {
+		t.Fatalf("err = %v, want the unreadable mention reported", err)
+	}
+	if !strings.Contains(text, "--- MCP Resource @notes:notes://todo ---\nv1") {
+		t.Fatalf("resource not attached:\n%s", text)
+	}
+
+	set("v2")
+	deadline := time.Now().Add(5 * time.Second)
+	var updates string
+	for updates == "" && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		updates = svc.takeResourceUpdates(ctx)
+	}
+	if !strings.Contains(updates, "@notes:notes://todo changed, current content:\nv2") {
+		t.Fatalf("updates = %q", updates)
+	}
+	if again := svc.takeResourceUpdates(ctx); again != "" {
+		t.Fatalf("update reported twice: %q", again)
+	}
+}
+
+// promptRecordingLLM answers every turn with "OK" and keeps the last user
+// message it was sent
+type promptRecordingLLM struct {
+	fileMemoryTestLLM
+	lastPrompt string
+}
+
+func (p *promptRecordingLLM) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	if len(messages) > 0 {
+		p.lastPrompt = messages[len(messages)-1].Content
+	}
+	return &domain.GenerationResult{Content: "OK"}, nil
+}
+
+func TestRunInjectsResourceUpdates(t *testing.T) {
+	mcpSvc, set := newResourceMCP(t)
+	llm := &promptRecordingLLM{}
+	home := t.TempDir()
+	svc, err := New("resource-agent").
+		WithConfig(testAgentConfig(home)).
+		WithDBPath(filepath.Join(home, "agent.db")).
+		WithLLM(llm).
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	defer svc.Close()
+	svc.SetMCPService(mcpSvc)
+	ctx := context.Background()
+
+	if _, err := svc.AttachResourceMentions(ctx, "watch @notes:notes://todo"); err != nil {
+		t.Fatalf("AttachResourceMentions: %v", err)
+	}
+	set("v2")
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		svc.mcpCatalogMu.Lock()
+		pending := len(svc.mcpUpdated)
+		svc.mcpCatalogMu.Unlock()
+		if pending > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if _, err := svc.Run(ctx, "what is left to do?"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(llm.lastPrompt, "--- Updated MCP Resources ---\n@notes:notes://todo changed, current content:\nv2") {
+		t.Fatalf("prompt missing resource update:\n%s", llm.lastPrompt)
+	}
+	if again := svc.takeResourceUpdates(ctx); again != "" {
+		t.Fatalf("update left pending after the run: %q", again)
+	}
+}
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
+	"github.com/liliang-cn/agent-go/pkg/pool"
+	"github.com/liliang-cn/agent-go/pkg/services"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+)
+
+// SamplingToolName is the PermissionRequest.ToolName used when an MCP server
+// asks to borrow the LLM via sampling/createMessage.
+const SamplingToolName = "mcp_sampling"
+
+// samplingModels serves sampling from the global pool, falling back to the
+// agent's own LLM when the pool is not initialized (e.g. WithLLM).
+type samplingModels struct {
+	fallback domain.Generator
+}
+
+func (m samplingModels) GetLLMServiceWithHint(hint pool.SelectionHint) (domain.Generator, error) {
+	if gp := services.GetGlobalPoolService(); gp.IsInitialized() {
+		return gp.GetLLMServiceWithHint(hint)
+	}
+	if m.fallback != nil {
+		return m.fallback, nil
+	}
+	return nil, fmt.Errorf("LLM pool not initialized")
+}
+
+// newSamplingHandler builds the MCP sampling handler for a builder run.
+// Usage is recorded per requesting server in the usage database, which is
+// opened on the first sampled request and released by the returned
+// samplingUsage's Close.
+func newSamplingHandler(cfg *config.Config, samplingCfg mcp.SamplingConfig, llm domain.Generator) (*mcp.SamplingHandler, *samplingUsage) {
+	h := mcp.NewSamplingHandler(samplingCfg, samplingModels{fallback: llm})
+	recorder := &samplingUsage{cfg: cfg}
+	h.SetUsageRecorder(recorder.record)
+	return h, recorder
+}
+
+// samplingUsage records sampling calls in a lazily opened usage database
+type samplingUsage struct {
+	cfg *config.Config
+
+	mu     sync.Mutex
+	svc    *usage.Service
+	opened bool
+	closed bool
+}
+
+func (u *samplingUsage) record(ctx context.Context, server, model, input, output string, start time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.closed {
+		return
+	}
+	if !u.opened {
+		u.opened = true
+		var err error
+		if u.svc, err = usage.NewService(u.cfg); err != nil {
+			log.Printf("[WARN] MCP sampling usage will not be recorded: %v", err)
+		}
+	}
+	if u.svc == nil {
+		return
+	}
+	if _, err := u.svc.TrackSamplingCall(ctx, server, model, input, output, start); err != nil {
+		log.Printf("[WARN] Failed to record MCP sampling usage for %s: %v", server, err)
+	}
+}
+
+// Close releases the usage database; later calls are no longer recorded
+func (u *samplingUsage) Close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.closed = true
+	if u.svc == nil {
+		return nil
+	}
+	err := u.svc.Close()
+	u.svc = nil
+	return err
+}
+
+// approveSampling asks the PermissionHandler whether an MCP server may run a
+// sampling request. Without a handler the request is declined.
+func (s *Service) approveSampling(ctx context.Context, req *mcp.SamplingRequest) (bool, error) {
+	s.permissionMu.RLock()
+	handler := s.permissionHandler
+	s.permissionMu.RUnlock()
+
+	if handler == nil {
+		return false, nil
+	}
+
+	prompt := ""
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			prompt = req.Messages[i].Content
+			break
+		}
+	}
+	resp, err := handler(ctx, PermissionRequest{
+		ToolName: SamplingToolName,
+		ToolArgs: map[string]interface{}{
+			"server":     req.Server,
+			"prompt":     prompt,
+			"messages":   len(req.Messages),
+			"max_tokens": req.MaxTokens,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp != nil && resp.Allowed, nil
+}
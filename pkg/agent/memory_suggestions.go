@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// memorySuggester is implemented by memory services that can propose
+// memories for review instead of storing them silently (memory.Service)
+type memorySuggester interface {
+	SuggestsMemories() bool
+	SuggestMemories(ctx context.Context, req *domain.MemoryStoreRequest) ([]*domain.MemorySuggestion, error)
+	AcceptSuggestion(ctx context.Context, id, by string) (*domain.Memory, error)
+	RejectSuggestion(ctx context.Context, id, by string) error
+}
+
+// suggestsMemories reports whether extracted memories wait for review
+func (s *Service) suggestsMemories() bool {
+	ms, ok := s.memoryService.(memorySuggester)
+	return ok && ms.SuggestsMemories()
+}
+
+// suggestMemories extracts candidate memories from a finished task and
+// queues them for review
+func (s *Service) suggestMemories(ctx context.Context, sessionID, goal, result, log string) []*domain.MemorySuggestion {
+	ms, ok := s.memoryService.(memorySuggester)
+	if !ok {
+		return nil
+	}
+	suggestions, err := ms.SuggestMemories(ctx, &domain.MemoryStoreRequest{
+		SessionID:    sessionID,
+		TaskGoal:     goal,
+		TaskResult:   result,
+		ExecutionLog: log,
+	})
+	if err != nil {
+		s.logger.Warn("failed to suggest memories", slog.String("error", err.Error()))
+	}
+	return suggestions
+}
+
+// DecideMemorySuggestion accepts (stores) or rejects a pending memory
+// suggestion on behalf of by
+func (s *Service) DecideMemorySuggestion(ctx context.Context, id string, accept bool, by string) error {
+	ms, ok := s.memoryService.(memorySuggester)
+	if !ok {
+		return fmt.Errorf("memory suggestions are not enabled")
+	}
+	if !accept {
+		return ms.RejectSuggestion(ctx, id, by)
+	}
+	_, err := ms.AcceptSuggestion(ctx, id, by)
+	return err
+}
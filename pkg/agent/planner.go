@@ -112,38 +112,7 @@ func (p *Planner) Plan(ctx context.Context, goal string, session *Session) (*Pla
 	}
 
 	// Define the expected response schema
-	schema := map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"reasoning": map[string]interface{}{
-				"type":        "string",
-				"description": "Explanation of the plan and why these steps are necessary",
-			},
-			"steps": map[string]interface{}{
-				"type": "array",
-				"items": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"description": map[string]interface{}{
-							"type":        "string",
-							"description": "What this step does",
-						},
-						"tool": map[string]interface{}{
-							"type":        "string",
-							"description": "The tool to use for this step. MUST be one of the available tools listed above, or 'llm' for general reasoning.",
-							"enum":        p.buildToolEnum(),
-						},
-						"arguments": map[string]interface{}{
-							"type":        "object",
-							"description": "Arguments for the tool (use null if not needed)",
-						},
-					},
-					"required": []string{"description", "tool", "arguments"},
-				},
-			},
-		},
-		"required": []string{"reasoning", "steps"},
-	}
+	schema := p.planResponseSchema()
 
 	// Generate structured plan
 	opts := &domain.GenerationOptions{
@@ -232,6 +201,42 @@ func (p *Planner) Plan(ctx context.Context, goal string, session *Session) (*Pla
 	return plan, nil
 }
 
+// planResponseSchema returns the structured-output schema shared by planning and replanning
+func (p *Planner) planResponseSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"reasoning": map[string]interface{}{
+				"type":        "string",
+				"description": "Explanation of the plan and why these steps are necessary",
+			},
+			"steps": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"description": map[string]interface{}{
+							"type":        "string",
+							"description": "What this step does",
+						},
+						"tool": map[string]interface{}{
+							"type":        "string",
+							"description": "The tool to use for this step. MUST be one of the available tools listed above, or 'llm' for general reasoning.",
+							"enum":        p.buildToolEnum(),
+						},
+						"arguments": map[string]interface{}{
+							"type":        "object",
+							"description": "Arguments for the tool (use null if not needed)",
+						},
+					},
+					"required": []string{"description", "tool", "arguments"},
+				},
+			},
+		},
+		"required": []string{"reasoning", "steps"},
+	}
+}
+
 // buildToolEnum returns the list of valid tool names for the schema enum
 func (p *Planner) buildToolEnum() []string {
 	// Start with "llm" as the default
@@ -0,0 +1,304 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/prompt"
+)
+
+// AdaptiveConfig controls adaptive plan execution
+type AdaptiveConfig struct {
+	// MaxReplans bounds how many times the remaining steps may be revised (default: 3)
+	MaxReplans int
+	// VerifySteps checks each successful step result with the LLM before moving on
+	VerifySteps bool
+}
+
+// DefaultAdaptiveConfig returns the default adaptive execution configuration
+func DefaultAdaptiveConfig() *AdaptiveConfig {
+	return &AdaptiveConfig{
+		MaxReplans:  3,
+		VerifySteps: true,
+	}
+}
+
+// AdaptiveOption modifies AdaptiveConfig
+type AdaptiveOption func(*AdaptiveConfig)
+
+// WithMaxReplans sets the maximum number of automatic plan revisions
+func WithMaxReplans(n int) AdaptiveOption {
+	return func(c *AdaptiveConfig) { c.MaxReplans = n }
+}
+
+// WithStepVerification enables or disables LLM verification of step results
+func WithStepVerification(verify bool) AdaptiveOption {
+	return func(c *AdaptiveConfig) { c.VerifySteps = verify }
+}
+
+// ExecutePlanAdaptive executes the plan like ExecutePlan, but when a step fails
+// or its result does not pass verification, the planner revises the remaining
+// steps using the failure as context. Every revision is recorded in
+// plan.Revisions and persisted with the plan.
+func (s *Service) ExecutePlanAdaptive(ctx context.Context, plan *Plan, opts ...AdaptiveOption) (*ExecutionResult, error) {
+	cfg := DefaultAdaptiveConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	result, err := s.executor.ExecutePlanAdaptive(ctx, plan, nil, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("execution failed: %w", err)
+	}
+
+	// Save the plan state
+	if err := s.store.SavePlan(plan); err != nil {
+		return nil, fmt.Errorf("failed to save plan: %w", err)
+	}
+	s.recordPlanEpisode(context.WithoutCancel(ctx), plan, result)
+
+	if !result.Success {
+		return result, fmt.Errorf("plan execution completed with errors: %s", result.Error)
+	}
+
+	return result, nil
+}
+
+// ExecutePlanAdaptive executes a plan step by step, replanning the remaining
+// steps after a failure until cfg.MaxReplans is exhausted. A failed step is
+// moved out of plan.Steps into the revision that replaced it, so plan.Steps
+// always reflects the path that was actually executed.
+func (e *Executor) ExecutePlanAdaptive(ctx context.Context, plan *Plan, session *Session, cfg *AdaptiveConfig) (*ExecutionResult, error) {
+	if cfg == nil {
+		cfg = DefaultAdaptiveConfig()
+	}
+
+	startTime := time.Now()
+	plan.Status = PlanStatusRunning
+
+	replans := 0
+	toolCalls := 0
+	var finalResult interface{}
+	var firstError string
+
+	for i := 0; i < len(plan.Steps); i++ {
+		step := &plan.Steps[i]
+
+		// Steps completed by a previous run are kept as-is
+		if step.Status == StepStatusCompleted {
+			finalResult = step.Result
+			continue
+		}
+
+		trigger := RevisionTriggerStepFailed
+		var failure string
+		if !e.dependenciesSatisfied(step, plan.Steps) {
+			failure = "Dependencies not satisfied"
+		} else {
+			result, err := e.ExecuteStep(ctx, step, plan, session)
+			toolCalls++
+			if err != nil {
+				failure = err.Error()
+			} else {
+				step.Result = result
+				if cfg.VerifySteps {
+					if ok, reason := e.service.verifyStepResult(ctx, plan, step, result); !ok {
+						trigger = RevisionTriggerVerification
+						failure = "verification failed: " + reason
+					}
+				}
+			}
+		}
+
+		step.CompletedAt = &[]time.Time{time.Now()}[0]
+		plan.UpdatedAt = time.Now()
+
+		if failure == "" {
+			step.Status = StepStatusCompleted
+			finalResult = step.Result
+			continue
+		}
+
+		step.Status = StepStatusFailed
+		step.Error = failure
+		if ctx.Err() != nil || replans >= cfg.MaxReplans {
+			firstError = failure
+			break
+		}
+
+		e.service.emitProgress("replan", fmt.Sprintf("Step failed, revising plan: %s", failure), replans+1, step.Tool)
+		newSteps, reasoning, err := e.service.planner.ReplanRemaining(ctx, plan, i, failure)
+		if err != nil {
+			firstError = fmt.Sprintf("%s (replanning failed: %v)", failure, err)
+			break
+		}
+		replans++
+
+		replaced := append([]Step(nil), plan.Steps[i:]...)
+		plan.Revisions = append(plan.Revisions, PlanRevision{
+			Number:        len(plan.Revisions) + 1,
+			Trigger:       trigger,
+			FailedStepID:  step.ID,
+			Failure:       failure,
+			Reasoning:     reasoning,
+			ReplacedSteps: replaced,
+			NewSteps:      append([]Step(nil), newSteps...),
+			CreatedAt:     time.Now(),
+		})
+		plan.Steps = append(plan.Steps[:i:i], newSteps...)
+		plan.UpdatedAt = time.Now()
+
+		if err := e.service.store.SavePlan(plan); err != nil {
+			log.Printf("[Agent] Warning: failed to save revised plan: %v", err)
+		}
+
+		// Re-run the loop at the same index, which now holds the first new step
+		i--
+	}
+
+	stepsDone := 0
+	stepsFailed := 0
+	for i := range plan.Steps {
+		switch plan.Steps[i].Status {
+		case StepStatusCompleted:
+			stepsDone++
+		case StepStatusFailed:
+			stepsFailed++
+		case StepStatusPending:
+			if firstError != "" {
+				plan.Steps[i].Status = StepStatusSkipped
+			}
+		}
+	}
+
+	if firstError == "" {
+		plan.Status = PlanStatusCompleted
+		plan.Error = ""
+	} else {
+		plan.Status = PlanStatusFailed
+		plan.Error = firstError
+	}
+
+	duration := time.Since(startTime)
+
+	result := &ExecutionResult{
+		PlanID:          plan.ID,
+		SessionID:       plan.SessionID,
+		Success:         firstError == "",
+		StepsTotal:      len(plan.Steps),
+		StepsDone:       stepsDone,
+		StepsFailed:     stepsFailed,
+		StartedAt:       &startTime,
+		CompletedAt:     &[]time.Time{startTime.Add(duration)}[0],
+		ToolCalls:       toolCalls,
+		EstimatedTokens: e.service.estimateTextTokens(plan.Goal) + e.service.estimateTextTokens(formatResultForContent(finalResult)),
+		FinalResult:     finalResult,
+		Error:           firstError,
+		Duration:        duration.String(),
+		Metadata: map[string]interface{}{
+			"replans": replans,
+		},
+	}
+
+	e.storePlanMemories(ctx, plan, finalResult)
+
+	return result, nil
+}
+
+// verifyStepResult checks a single step result against the step's intent
+func (s *Service) verifyStepResult(ctx context.Context, plan *Plan, step *Step, result interface{}) (bool, string) {
+	goal := fmt.Sprintf("%s (step of the overall goal: %s)", step.Description, plan.Goal)
+	verified, reason, _, _ := s.verifyResult(ctx, goal, result)
+	return verified, reason
+}
+
+// replanStepView is the compact step representation rendered into the replan prompt
+type replanStepView struct {
+	Tool        string
+	Description string
+	Arguments   string
+	Result      string
+}
+
+// ReplanRemaining asks the LLM for a new sequence of steps to replace the step
+// at failedIndex and everything after it. Completed steps before failedIndex
+// are passed as context and are not repeated.
+func (p *Planner) ReplanRemaining(ctx context.Context, plan *Plan, failedIndex int, failure string) ([]Step, string, error) {
+	if failedIndex < 0 || failedIndex >= len(plan.Steps) {
+		return nil, "", fmt.Errorf("invalid failed step index: %d", failedIndex)
+	}
+
+	var completed, remaining []replanStepView
+	for i, step := range plan.Steps {
+		if i < failedIndex && step.Status == StepStatusCompleted {
+			preview := formatResultForContent(step.Result)
+			if len(preview) > 500 {
+				preview = preview[:500] + "..."
+			}
+			completed = append(completed, replanStepView{Tool: step.Tool, Description: step.Description, Result: preview})
+		}
+		if i > failedIndex {
+			remaining = append(remaining, replanStepView{Tool: step.Tool, Description: step.Description})
+		}
+	}
+
+	failed := plan.Steps[failedIndex]
+	argsJSON, _ := json.Marshal(failed.Arguments)
+
+	data := map[string]interface{}{
+		"Goal":             plan.Goal,
+		"ToolDescriptions": p.describeAvailableTools(),
+		"CompletedSteps":   completed,
+		"FailedStep":       replanStepView{Tool: failed.Tool, Description: failed.Description, Arguments: string(argsJSON)},
+		"Failure":          failure,
+		"RemainingSteps":   remaining,
+	}
+
+	rendered, err := p.promptManager.Render(prompt.AgentReplanSteps, data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render replan prompt: %w", err)
+	}
+
+	if p.service != nil && p.service.debug {
+		p.service.EmitDebugPrint(0, "replan_prompt", rendered)
+	}
+
+	result, err := p.llmService.GenerateStructured(ctx, rendered, p.planResponseSchema(), &domain.GenerationOptions{
+		Temperature: 0.3,
+		MaxTokens:   2000,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate revised steps: %w", err)
+	}
+
+	var planResp PlanResponse
+	if err := json.Unmarshal([]byte(result.Raw), &planResp); err != nil {
+		return nil, "", fmt.Errorf("failed to parse revised steps: %w", err)
+	}
+	if len(planResp.Steps) == 0 {
+		return nil, "", fmt.Errorf("planner returned no steps")
+	}
+
+	fallbackIntent := &IntentRecognitionResult{IntentType: "general"}
+	steps := make([]Step, len(planResp.Steps))
+	for i, step := range planResp.Steps {
+		tool := step.Tool
+		if !p.isValidTool(tool) && (p.service == nil || !p.service.toolRegistry.Has(tool)) {
+			tool = p.inferToolFromIntent(step.Description, fallbackIntent)
+		}
+		steps[i] = Step{
+			ID:          uuid.New().String(),
+			Description: step.Description,
+			Tool:        tool,
+			Arguments:   step.Arguments,
+			Status:      StepStatusPending,
+		}
+	}
+
+	return steps, planResp.Reasoning, nil
+}
@@ -0,0 +1,233 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+type replanTestLLM struct {
+	structuredCalls int
+	raw             string
+	reject          string // step verification fails for results containing this
+}
+
+func (r *replanTestLLM) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	if r.reject != "" && strings.Contains(prompt, r.reject) {
+		return `{"verified": false, "reason": "the report is stale"}`, nil
+	}
+	return `{"verified": true}`, nil
+}
+
+func (r *replanTestLLM) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	return nil
+}
+
+func (r *replanTestLLM) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	return &domain.GenerationResult{Content: "done"}, nil
+}
+
+func (r *replanTestLLM) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	return callback(&domain.GenerationResult{Content: "done"})
+}
+
+func (r *replanTestLLM) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	r.structuredCalls++
+	return &domain.StructuredResult{Raw: r.raw, Valid: true}, nil
+}
+
+func (r *replanTestLLM) RecognizeIntent(ctx context.Context, request string) (*domain.IntentResult, error) {
+	return nil, nil
+}
+
+func newReplanTestService(t *testing.T, llm *replanTestLLM) *Service {
+	t.Helper()
+
+	svc, err := NewService(llm, nil, nil, filepath.Join(t.TempDir(), "agent.db"), nil)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	for _, name := range []string{"flaky_tool", "steady_tool"} {
+		name := name
+		svc.RegisterTool(domain.ToolDefinition{
+			Type: "function",
+			Function: domain.ToolFunction{
+				Name:       name,
+				Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			},
+		}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			if name == "flaky_tool" {
+				return nil, errors.New("upstream unavailable")
+			}
+			return "steady result", nil
+		})
+	}
+	return svc
+}
+
+func newReplanTestPlan() *Plan {
+	return &Plan{
+		ID:     "plan-1",
+		Goal:   "fetch the report",
+		Status: PlanStatusPending,
+		Steps: []Step{
+			{ID: "s1", Description: "fetch with the flaky tool", Tool: "flaky_tool", Status: StepStatusPending},
+			{ID: "s2", Description: "summarize", Tool: "llm", Status: StepStatusPending},
+		},
+	}
+}
+
+func TestExecutePlanAdaptiveReplansAfterFailure(t *testing.T) {
+	llm := &replanTestLLM{raw: `{"reasoning":"use the steady tool instead","steps":[{"description":"fetch with the steady tool","tool":"steady_tool","arguments":{}}]}`}
+	svc := newReplanTestService(t, llm)
+	plan := newReplanTestPlan()
+
+	result, err := svc.ExecutePlanAdaptive(context.Background(), plan, WithStepVerification(false))
+	if err != nil {
+		t.Fatalf("ExecutePlanAdaptive() error = %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error %q", result.Error)
+	}
+	if result.FinalResult != "steady result" {
+		t.Fatalf("FinalResult = %v, want steady result", result.FinalResult)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].Tool != "steady_tool" {
+		t.Fatalf("unexpected steps after replan: %+v", plan.Steps)
+	}
+	if len(plan.Revisions) != 1 {
+		t.Fatalf("len(Revisions) = %d, want 1", len(plan.Revisions))
+	}
+	rev := plan.Revisions[0]
+	if rev.Trigger != RevisionTriggerStepFailed || rev.FailedStepID != "s1" {
+		t.Fatalf("unexpected revision: %+v", rev)
+	}
+	if len(rev.ReplacedSteps) != 2 || rev.ReplacedSteps[0].Status != StepStatusFailed {
+		t.Fatalf("unexpected replaced steps: %+v", rev.ReplacedSteps)
+	}
+
+	stored, err := svc.GetPlan(plan.ID)
+	if err != nil {
+		t.Fatalf("GetPlan() error = %v", err)
+	}
+	if len(stored.Revisions) != 1 || stored.Revisions[0].Reasoning != "use the steady tool instead" {
+		t.Fatalf("revision history not persisted: %+v", stored.Revisions)
+	}
+}
+
+func TestExecutePlanAdaptiveStopsAtMaxReplans(t *testing.T) {
+	llm := &replanTestLLM{raw: `{"reasoning":"retry","steps":[{"description":"retry flaky","tool":"flaky_tool","arguments":{}}]}`}
+	svc := newReplanTestService(t, llm)
+	plan := newReplanTestPlan()
+
+	result, err := svc.ExecutePlanAdaptive(context.Background(), plan, WithMaxReplans(2), WithStepVerification(false))
+	if err == nil {
+		t.Fatal("expected an error after exhausting replans")
+	}
+	if result == nil || result.Success {
+		t.Fatalf("expected unsuccessful result, got %+v", result)
+	}
+	if llm.structuredCalls != 2 || len(plan.Revisions) != 2 {
+		t.Fatalf("replans = %d, revisions = %d, want 2", llm.structuredCalls, len(plan.Revisions))
+	}
+	if plan.Status != PlanStatusFailed {
+		t.Fatalf("plan status = %s, want failed", plan.Status)
+	}
+}
+
+func TestExecutePlanAdaptiveReplansAfterFailedVerification(t *testing.T) {
+	llm := &replanTestLLM{
+		raw:    `{"reasoning":"fetch a fresh copy","steps":[{"description":"fetch a fresh report","tool":"fresh_tool","arguments":{}}]}`,
+		reject: "steady result",
+	}
+	svc := newReplanTestService(t, llm)
+	freshCalls := 0
+	svc.RegisterTool(domain.ToolDefinition{
+		Type: "function",
+		Function: domain.ToolFunction{
+			Name:       "fresh_tool",
+			Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		freshCalls++
+		return "fresh result", nil
+	})
+	plan := newReplanTestPlan()
+	plan.Steps[0] = Step{ID: "s1", Description: "fetch with the steady tool", Tool: "steady_tool", Status: StepStatusPending}
+
+	result, err := svc.ExecutePlanAdaptive(context.Background(), plan, WithStepVerification(true))
+	if err != nil {
+		t.Fatalf("ExecutePlanAdaptive() error = %v", err)
+	}
+	if !result.Success || result.FinalResult != "fresh result" {
+		t.Fatalf("result = %+v, want success with the fresh result", result)
+	}
+	if freshCalls != 1 {
+		t.Fatalf("fresh_tool called %d times, want 1", freshCalls)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].Tool != "fresh_tool" || plan.Steps[0].Status != StepStatusCompleted {
+		t.Fatalf("unexpected steps after replan: %+v", plan.Steps)
+	}
+
+	if len(plan.Revisions) != 1 {
+		t.Fatalf("len(Revisions) = %d, want 1", len(plan.Revisions))
+	}
+	rev := plan.Revisions[0]
+	if rev.Trigger != RevisionTriggerVerification || rev.FailedStepID != "s1" {
+		t.Fatalf("unexpected revision: %+v", rev)
+	}
+	if !strings.Contains(rev.Failure, "the report is stale") {
+		t.Fatalf("Failure = %q, want the verifier's reason", rev.Failure)
+	}
+	if len(rev.NewSteps) != 1 || rev.NewSteps[0].Tool != "fresh_tool" {
+		t.Fatalf("unexpected new steps: %+v", rev.NewSteps)
+	}
+	rejected := rev.ReplacedSteps[0]
+	if rejected.Status != StepStatusFailed || rejected.Result != "steady result" {
+		t.Fatalf("rejected step = %+v, want failed with its result kept", rejected)
+	}
+}
+
+// episodeRecorder keeps the episodes a run records
+type episodeRecorder struct {
+	domain.MemoryService
+	episodes []*domain.Episode
+}
+
+func (r *episodeRecorder) EpisodeContext(ctx context.Context, goal string) (string, error) {
+	return "", nil
+}
+
+func (r *episodeRecorder) RecordEpisode(ctx context.Context, ep *domain.Episode) (*domain.Memory, error) {
+	r.episodes = append(r.episodes, ep)
+	return &domain.Memory{}, nil
+}
+
+func TestExecutePlanAdaptiveRecordsEpisode(t *testing.T) {
+	llm := &replanTestLLM{raw: `{"reasoning":"use the steady tool instead","steps":[{"description":"fetch with the steady tool","tool":"steady_tool","arguments":{}}]}`}
+	svc := newReplanTestService(t, llm)
+	recorder := &episodeRecorder{}
+	svc.memoryService = recorder
+	plan := newReplanTestPlan()
+
+	if _, err := svc.ExecutePlanAdaptive(context.Background(), plan, WithStepVerification(false)); err != nil {
+		t.Fatalf("ExecutePlanAdaptive() error = %v", err)
+	}
+	if len(recorder.episodes) != 1 {
+		t.Fatalf("recorded %d episodes, want 1", len(recorder.episodes))
+	}
+	ep := recorder.episodes[0]
+	if ep.Goal != plan.Goal || ep.Outcome != domain.EpisodeSuccess || ep.Result != "steady result" {
+		t.Fatalf("unexpected episode: %+v", ep)
+	}
+	if want := "1. fetch with the steady tool (steady_tool)\nRevisions: 1"; ep.Plan != want {
+		t.Fatalf("Plan = %q, want %q", ep.Plan, want)
+	}
+	if got := strings.Join(ep.ToolSequence, ","); got != "flaky_tool,steady_tool" {
+		t.Fatalf("ToolSequence = %q, want the replaced step first", got)
+	}
+}
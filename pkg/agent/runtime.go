@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
 	"github.com/liliang-cn/agent-go/pkg/skills"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -26,6 +27,9 @@ type Runtime struct {
 	session      *Session
 	cfg          *RunConfig
 	sources      []domain.Chunk // Collect RAG sources during execution
+	runErr       error          // First error event, recorded on the run span
+	toolsUsed    []string       // Tool calls in order, for episodic memory
+	startedAt    time.Time
 }
 
 // NewRuntime creates a new runtime instance
@@ -55,7 +59,16 @@ func (r *Runtime) RunStream(ctx context.Context, goal string) <-chan *Event {
 
 // loop is the core event loop
 func (r *Runtime) loop(ctx context.Context, goal string) {
+	span, ctx := r.svc.startSpan(ctx, telemetry.OperationInvokeAgent+" "+r.currentAgent.Name(), SpanKindAgent,
+		WithSpanAgentID(r.currentAgent.ID()),
+		WithSpanSessionID(r.session.GetID()),
+		WithSpanAttributes(map[string]string{string(telemetry.AttrGenAIAgentName): r.currentAgent.Name()}))
+	r.startedAt = time.Now()
 	defer func() {
+		r.svc.endSpan(span, r.runErr)
+		if r.runErr != nil && ctx.Err() == nil {
+			go r.svc.recordEpisode(context.WithoutCancel(ctx), r.episode(goal, domain.EpisodeFailure, ""))
+		}
 		close(r.eventChan)
 	}()
 
@@ -77,7 +90,19 @@ func (r *Runtime) loop(ctx context.Context, goal string) {
 	// model or unreachable LLM doesn't block the entire run forever.
 	prepCtx, prepCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer prepCancel()
-	memoryContext, ragContext := r.prepareContext(prepCtx, goal)
+	memoryContext, episodeContext, ragContext := r.prepareContext(prepCtx, goal)
+	catalogChanges, resourceUpdates := r.svc.takeMCPChanges(prepCtx)
+	if len(catalogChanges) > 0 {
+		r.eventChan <- &Event{
+			ID:             uuid.New().String(),
+			Type:           EventTypeToolsChanged,
+			AgentName:      r.currentAgent.Name(),
+			AgentID:        r.currentAgent.ID(),
+			Content:        describeCatalogChanges(catalogChanges),
+			CatalogChanges: catalogChanges,
+			Timestamp:      time.Now(),
+		}
+	}
 
 	// 2. Build initial messages
 	messages := []domain.Message{
@@ -92,6 +117,12 @@ func (r *Runtime) loop(ctx context.Context, goal string) {
 	if memoryContext != "" {
 		messages[len(messages)-1].Content += "\n\n--- Memory ---\n" + memoryContext
 	}
+	if episodeContext != "" {
+		messages[len(messages)-1].Content += "\n\n--- Similar Past Tasks ---\n" + episodeContext
+	}
+	if resourceUpdates != "" {
+		messages[len(messages)-1].Content += "\n\n--- Updated MCP Resources ---\n" + resourceUpdates
+	}
 
 	const maxRounds = 20
 	for round := 0; round < maxRounds; round++ {
@@ -215,7 +246,7 @@ func (r *Runtime) loop(ctx context.Context, goal string) {
 				Sources:   allSources,
 				Timestamp: time.Now(),
 			}
-			go r.saveToMemory(context.Background(), goal, result)
+			r.afterComplete(ctx, goal, result)
 			return
 		}
 
@@ -266,7 +297,7 @@ func (r *Runtime) loop(ctx context.Context, goal string) {
 						Sources:   allSources,
 						Timestamp: time.Now(),
 					}
-					go r.saveToMemory(context.Background(), goal, result)
+					r.afterComplete(ctx, goal, result)
 					return
 				}
 			}
@@ -490,8 +521,8 @@ func (r *Runtime) loop(ctx context.Context, goal string) {
 			r.svc.ragSources = nil
 			r.svc.ragSourcesMu.Unlock()
 
-			// Auto-save to memory ASYNC to prevent lag at the end
-			go r.saveToMemory(context.Background(), goal, fullContent.String())
+			// Auto-save to memory (or propose memories in suggest mode)
+			r.afterComplete(ctx, goal, fullContent.String())
 			return
 		}
 	}
@@ -499,6 +530,21 @@ func (r *Runtime) loop(ctx context.Context, goal string) {
 
 // executeToolOrHandoff executes a tool call and handles agent switching
 func (r *Runtime) executeToolOrHandoff(ctx context.Context, tc domain.ToolCall) (interface{}, error, bool) {
+	span, ctx := r.svc.startSpan(ctx, telemetry.OperationExecuteTool+" "+tc.Function.Name, SpanKindTool,
+		WithSpanAgentID(r.currentAgent.ID()),
+		WithSpanSessionID(r.session.GetID()),
+		WithSpanAttributes(map[string]string{
+			string(telemetry.AttrGenAIToolName):   tc.Function.Name,
+			string(telemetry.AttrGenAIToolCallID): tc.ID,
+		}))
+	result, err, handoff := r.dispatchToolCall(ctx, tc)
+	r.svc.endSpan(span, err)
+	return result, err, handoff
+}
+
+// dispatchToolCall runs hooks and permission checks, then routes the call to
+// the handler, registry, MCP server, skill, or PTC sandbox that owns it
+func (r *Runtime) dispatchToolCall(ctx context.Context, tc domain.ToolCall) (interface{}, error, bool) {
 	toolName := tc.Function.Name
 	resolvedToolName := r.resolveExecutableToolName(toolName)
 	ctx = withEventSink(ctx, r.forwardSubAgentEvent)
@@ -660,8 +706,8 @@ func (r *Runtime) resolveExecutableToolName(name string) string {
 	return name
 }
 
-func (r *Runtime) prepareContext(ctx context.Context, goal string) (string, string) {
-	var ragCtx, memCtx string
+func (r *Runtime) prepareContext(ctx context.Context, goal string) (string, string, string) {
+	var ragCtx, memCtx, episodeCtx string
 
 	g, groupCtx := errgroup.WithContext(ctx)
 
@@ -687,10 +733,38 @@ func (r *Runtime) prepareContext(ctx context.Context, goal string) (string, stri
 			}
 			return nil
 		})
+		g.Go(func() error {
+			episodeCtx = r.svc.episodeContext(groupCtx, goal)
+			return nil
+		})
 	}
 
 	_ = g.Wait()
-	return memCtx, ragCtx
+	return memCtx, episodeCtx, ragCtx
+}
+
+// afterComplete saves what the run taught us. In memory suggest mode the
+// candidates are proposed as events before the stream closes; otherwise
+// saving runs in the background to prevent lag at the end. The background
+// work keeps ctx's values, such as the memory principal, but not its deadline.
+func (r *Runtime) afterComplete(ctx context.Context, goal, result string) {
+	ctx = context.WithoutCancel(ctx)
+	if r.svc.suggestsMemories() {
+		for _, sg := range r.svc.suggestMemories(ctx, r.session.GetID(), goal, result, "") {
+			r.eventChan <- &Event{
+				ID:               uuid.New().String(),
+				Type:             EventTypeMemorySuggestion,
+				AgentName:        r.currentAgent.Name(),
+				AgentID:          r.currentAgent.ID(),
+				Content:          sg.Content,
+				MemorySuggestion: sg,
+				Timestamp:        time.Now(),
+			}
+		}
+		go r.svc.recordEpisode(ctx, r.episode(goal, domain.EpisodeSuccess, result))
+		return
+	}
+	go r.saveToMemory(ctx, goal, result)
 }
 
 func (r *Runtime) saveToMemory(ctx context.Context, goal, result string) {
@@ -702,11 +776,32 @@ func (r *Runtime) saveToMemory(ctx context.Context, goal, result string) {
 		}); err != nil {
 			r.svc.logger.Warn("failed to store memory after run", slog.String("error", err.Error()))
 		}
+		r.svc.recordEpisode(ctx, r.episode(goal, domain.EpisodeSuccess, result))
 	}
 }
 
+// episode describes this run for episodic memory
+func (r *Runtime) episode(goal string, outcome domain.EpisodeOutcome, result string) *domain.Episode {
+	ep := &domain.Episode{
+		SessionID:    r.session.GetID(),
+		AgentID:      r.currentAgent.ID(),
+		Goal:         goal,
+		ToolSequence: append([]string(nil), r.toolsUsed...),
+		Outcome:      outcome,
+		Result:       result,
+		DurationMs:   time.Since(r.startedAt).Milliseconds(),
+	}
+	if r.runErr != nil {
+		ep.Error = r.runErr.Error()
+	}
+	return ep
+}
+
 // Helpers to emit events
 func (r *Runtime) emit(t EventType, content string) {
+	if t == EventTypeError && r.runErr == nil {
+		r.runErr = errors.New(content)
+	}
 	r.eventChan <- &Event{
 		ID:        uuid.New().String(),
 		Type:      t,
@@ -718,6 +813,7 @@ func (r *Runtime) emit(t EventType, content string) {
 }
 
 func (r *Runtime) emitToolCall(name string, args map[string]interface{}) {
+	r.toolsUsed = append(r.toolsUsed, name)
 	r.eventChan <- &Event{
 		ID:        uuid.New().String(),
 		Type:      EventTypeToolCall,
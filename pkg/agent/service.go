@@ -20,13 +20,14 @@ import (
 	"github.com/liliang-cn/agent-go/pkg/ptc"
 	"github.com/liliang-cn/agent-go/pkg/router"
 	"github.com/liliang-cn/agent-go/pkg/skills"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 	"github.com/liliang-cn/agent-go/pkg/usage"
 	"golang.org/x/sync/errgroup"
 )
 
 // ProgressEvent 进度事件
 type ProgressEvent struct {
-	Type    string // "thinking", "tool_call", "tool_result", "done"
+	Type    string // "thinking", "tool_call", "tool_result", "tools_changed", "done"
 	Round   int
 	Message string
 	Tool    string
@@ -85,6 +86,12 @@ type Service struct {
 	// Execution history storage
 	historyStore *HistoryStore
 
+	// Span tracing, mirrored to OTLP when telemetry is configured and
+	// persisted when a trace store is attached
+	tracer     *Tracer
+	telemetry  *telemetry.Provider
+	traceStore *TraceStore
+
 	// Public access to underlying services
 	LLM     domain.Generator
 	MCP     *mcp.Service // Full access to MCP service (Chat, StartServers, etc.)
@@ -97,6 +104,15 @@ type Service struct {
 
 	tokenCounter *usage.TokenCounter
 	cfg          *config.Config
+
+	// MCP catalog changes not yet reported to a run, see mcp_catalog.go
+	mcpCatalogMu      sync.Mutex
+	mcpCatalogChanges []mcp.CatalogEvent
+	mcpCatalogUnsub   func()
+	mcpWatched        map[resourceRef]bool // resources read by the agent, see mcp_resources.go
+	mcpUpdated        []resourceRef        // watched resources changed since the last run
+	stopMCPWatch      func()
+	samplingUsage     *samplingUsage // usage database of MCP sampling, see mcp_sampling.go
 }
 
 // Ensure Service implements ptc.SearchProvider
@@ -325,6 +341,17 @@ func (s *Service) RevisePlan(ctx context.Context, plan *Plan, instruction string
 		})
 	}
 
+	// Carry the revision history over to the new plan
+	newPlan.Revisions = append(append([]PlanRevision(nil), plan.Revisions...), PlanRevision{
+		Number:        len(plan.Revisions) + 1,
+		Trigger:       RevisionTriggerUser,
+		Instruction:   instruction,
+		Reasoning:     revisedPlan.Reasoning,
+		ReplacedSteps: plan.Steps,
+		NewSteps:      newPlan.Steps,
+		CreatedAt:     newPlan.CreatedAt,
+	})
+
 	// Save revised plan
 	if err := s.store.SavePlan(newPlan); err != nil {
 		return nil, fmt.Errorf("failed to save revised plan: %w", err)
@@ -345,6 +372,7 @@ func (s *Service) ExecutePlan(ctx context.Context, plan *Plan) (*ExecutionResult
 	if err := s.store.SavePlan(plan); err != nil {
 		return nil, fmt.Errorf("failed to save plan: %w", err)
 	}
+	s.recordPlanEpisode(context.WithoutCancel(ctx), plan, result)
 
 	if !result.Success {
 		return result, fmt.Errorf("plan execution completed with errors: %s", result.Error)
@@ -409,10 +437,15 @@ func (s *Service) Run(ctx context.Context, goal string, opts ...RunOption) (*Exe
 }
 
 // runWithConfig is the internal implementation
-func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig) (*ExecutionResult, error) {
+func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig) (result *ExecutionResult, err error) {
 	if cfg == nil {
 		cfg = DefaultRunConfig()
 	}
+	span, ctx := s.startSpan(ctx, telemetry.OperationInvokeAgent+" "+s.agent.Name(), SpanKindAgent,
+		WithSpanAgentID(s.agent.ID()),
+		WithSpanSessionID(cfg.SessionID),
+		WithSpanAttributes(map[string]string{string(telemetry.AttrGenAIAgentName): s.agent.Name()}))
+	defer func() { s.endSpan(span, err) }()
 	startTime := time.Now()
 	s.resetRunMemorySaved()
 	s.setRunning(true)
@@ -443,6 +476,9 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 	} else {
 		session = NewSession(s.agent.ID())
 	}
+	if span != nil {
+		span.SessionID = session.GetID()
+	}
 
 	// Parallel Context Collection
 	var (
@@ -451,6 +487,9 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 		memoryContext  string
 		memoryMemories []*domain.MemoryWithScore
 		memoryLogic    string
+		episodeContext string
+		catalogChanges []mcp.CatalogEvent
+		mcpContext     string
 	)
 
 	g, groupCtx := errgroup.WithContext(runCtx)
@@ -483,12 +522,26 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 			memoryContext, memoryMemories, memoryLogic, err = s.memoryService.RetrieveAndInjectWithLogic(groupCtx, goal, session.GetID())
 			return err
 		})
+		// 4. Similar past runs as examples
+		g.Go(func() error {
+			episodeContext = s.episodeContext(groupCtx, goal)
+			return nil
+		})
 	}
 
+	// 5. MCP tools and watched resources that changed since the last run
+	g.Go(func() error {
+		catalogChanges, mcpContext = s.takeMCPChanges(groupCtx)
+		return nil
+	})
+
 	// Wait for all context collection to finish
 	if err := g.Wait(); err != nil {
 		s.logger.Warn("Context collection partial failure", slog.Any("error", err))
 	}
+	if len(catalogChanges) > 0 {
+		s.emitProgress("tools_changed", describeCatalogChanges(catalogChanges), 0, "")
+	}
 
 	// Execute: PTC is just a transport mode — branch internally, same public API.
 	var finalResult interface{}
@@ -497,7 +550,11 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 
 	if s.isPTCEnabled() {
 		var err error
-		finalResult, ptcRes, err = s.runPTCExecution(runCtx, goal, session, cfg)
+		ptcGoal := goal
+		if mcpContext != "" {
+			ptcGoal += "\n\n--- Updated MCP Resources ---\n" + mcpContext
+		}
+		finalResult, ptcRes, err = s.runPTCExecution(runCtx, ptcGoal, session, cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -507,8 +564,19 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 		}
 	} else {
 		var err error
-		finalResult, execMetrics, err = s.executeWithLLM(runCtx, goal, intent, session, memoryContext, ragContext, cfg)
+		finalResult, execMetrics, err = s.executeWithLLM(runCtx, goal, intent, session, memoryContext, episodeContext, ragContext, mcpContext, cfg)
 		if err != nil {
+			if runCtx.Err() == nil {
+				s.recordEpisode(runCtx, &domain.Episode{
+					SessionID:    session.GetID(),
+					AgentID:      session.AgentID,
+					Goal:         goal,
+					ToolSequence: execMetrics.toolsUsed,
+					Outcome:      domain.EpisodeFailure,
+					Error:        err.Error(),
+					DurationMs:   time.Since(startTime).Milliseconds(),
+				})
+			}
 			return nil, err
 		}
 	}
@@ -551,7 +619,7 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 		log.Printf("[Agent] Failed to save plan: %v", err)
 	}
 
-	result, err := s.finalizeExecution(runCtx, session, goal, intent, memoryMemories, memoryLogic, "", currentResult)
+	result, err = s.finalizeExecution(runCtx, session, goal, intent, memoryMemories, memoryLogic, "", currentResult)
 	if err != nil {
 		return nil, err
 	}
@@ -572,6 +640,22 @@ func (s *Service) runWithConfig(ctx context.Context, goal string, cfg *RunConfig
 		result.ToolsUsed = uniqueStrings(toolNamesFromPTC(ptcRes))
 		result.EstimatedTokens = s.estimateRunTokens(goal, currentResult) + s.estimatePTCTokens(ptcRes)
 	}
+
+	var toolSequence []string
+	if execMetrics != nil {
+		toolSequence = execMetrics.toolsUsed
+	} else if ptcRes != nil {
+		toolSequence = toolNamesFromPTC(ptcRes)
+	}
+	s.recordEpisode(runCtx, &domain.Episode{
+		SessionID:    session.GetID(),
+		AgentID:      session.AgentID,
+		Goal:         goal,
+		ToolSequence: toolSequence,
+		Outcome:      domain.EpisodeSuccess,
+		Result:       formatResultForContent(currentResult),
+		DurationMs:   completedAt.Sub(startTime).Milliseconds(),
+	})
 	return result, nil
 }
 
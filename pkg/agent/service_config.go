@@ -13,16 +13,42 @@ import (
 
 // SetMCPService sets the MCP service for full public access
 func (s *Service) SetMCPService(mcpSvc *mcp.Service) {
+	if s.mcpCatalogUnsub != nil {
+		s.mcpCatalogUnsub()
+		s.mcpCatalogUnsub = nil
+	}
 	s.MCP = mcpSvc
+	if mcpSvc == nil {
+		return
+	}
+	s.mcpCatalogUnsub = mcpSvc.OnCatalogChange(s.onMCPCatalogChange)
 	// Register MCP tools in registry for tool search
-	if mcpSvc != nil && s.toolRegistry != nil {
-		go s.registerMCPToolsInRegistry(mcpSvc)
+	if s.toolRegistry != nil {
+		go s.syncMCPToolsInRegistry(mcpSvc)
+	}
+}
+
+// SetElicitor routes MCP servers' requests for user input to e. It has no
+// effect when MCP is disabled.
+func (s *Service) SetElicitor(e mcp.Elicitor) {
+	if s.MCP != nil {
+		s.MCP.SetElicitor(e)
 	}
 }
 
-// registerMCPToolsInRegistry registers MCP tools in the registry for tool search
-func (s *Service) registerMCPToolsInRegistry(mcpSvc *mcp.Service) {
+// syncMCPToolsInRegistry registers MCP tools in the registry for tool search
+// and drops those no longer offered by any server
+func (s *Service) syncMCPToolsInRegistry(mcpSvc *mcp.Service) {
 	tools := mcpSvc.GetAvailableTools(context.Background())
+	current := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		current[t.Name] = true
+	}
+	for _, name := range s.toolRegistry.NamesInCategory(CategoryMCP) {
+		if !current[name] {
+			s.toolRegistry.Unregister(name)
+		}
+	}
 	for _, t := range tools {
 		params := t.InputSchema
 		if params == nil {
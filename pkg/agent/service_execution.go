@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
 	"github.com/liliang-cn/agent-go/pkg/prompt"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -22,7 +23,7 @@ type executionMetrics struct {
 }
 
 // executeWithLLM lets LLM decide which tool to use and executes with multi-round support
-func (s *Service) executeWithLLM(ctx context.Context, goal string, intent *IntentRecognitionResult, session *Session, memoryContext string, ragContext string, cfg *RunConfig) (interface{}, *executionMetrics, error) {
+func (s *Service) executeWithLLM(ctx context.Context, goal string, intent *IntentRecognitionResult, session *Session, memoryContext, episodeContext, ragContext, mcpContext string, cfg *RunConfig) (interface{}, *executionMetrics, error) {
 	maxRounds := cfg.MaxTurns
 	if maxRounds <= 0 {
 		maxRounds = 20
@@ -43,7 +44,7 @@ func (s *Service) executeWithLLM(ctx context.Context, goal string, intent *Inten
 	if session != nil {
 		summary = session.Summary
 	}
-	messages := s.buildConversationMessages(session, goal, ragContext, memoryContext, summary)
+	messages := s.buildConversationMessages(session, goal, ragContext, memoryContext, episodeContext, mcpContext, summary)
 
 	if cfg.StoreHistory && s.historyStore != nil {
 		s.historyStore.RecordMessage(ctx, session.GetID(), currentAgent.ID(), goal, messages[len(messages)-1], 0)
@@ -131,7 +132,8 @@ func (s *Service) executeWithLLM(ctx context.Context, goal string, intent *Inten
 }
 
 // buildConversationMessages constructs the next-turn user message and prepends prior session history when available.
-func (s *Service) buildConversationMessages(session *Session, goal, ragContext, memoryContext, summary string) []domain.Message {
+// episodeContext carries examples of similar past runs from episodic memory.
+func (s *Service) buildConversationMessages(session *Session, goal, ragContext, memoryContext, episodeContext, mcpContext, summary string) []domain.Message {
 	content := goal
 	history := make([]domain.Message, 0)
 	if session != nil {
@@ -146,6 +148,12 @@ func (s *Service) buildConversationMessages(session *Session, goal, ragContext,
 	if memoryContext != "" {
 		content += "\n\nRelevant context from memory:\n" + memoryContext
 	}
+	if episodeContext != "" {
+		content += "\n\nExperience from similar past tasks (reuse what worked, avoid what failed):\n" + episodeContext
+	}
+	if mcpContext != "" {
+		content += "\n\n--- Updated MCP Resources ---\n" + mcpContext
+	}
 	messages := append([]domain.Message{}, history...)
 	messages = append(messages, domain.Message{Role: "user", Content: content})
 	return messages
@@ -709,6 +717,7 @@ func (s *Service) executeLLMToolCalls(ctx context.Context, toolCalls []domain.To
 // finalizeExecution finalizes the execution result
 func (s *Service) finalizeExecution(ctx context.Context, session *Session, goal string, intent *IntentRecognitionResult, memoryMemories []*domain.MemoryWithScore, memoryLogic string, ragResult string, finalResult interface{}) (*ExecutionResult, error) {
 	// Store to memory after completion
+	var suggestions []*domain.MemorySuggestion
 	if s.memoryService != nil {
 		// Auto-store for explicit memory request patterns
 		goalLower := strings.ToLower(goal)
@@ -743,12 +752,16 @@ func (s *Service) finalizeExecution(ctx context.Context, session *Session, goal
 		}
 
 		// LLM-based extraction for complex memories
-		if err := s.memoryService.StoreIfWorthwhile(ctx, &domain.MemoryStoreRequest{
-			SessionID:  session.GetID(),
-			TaskGoal:   goal,
-			TaskResult: formatResultForContent(finalResult),
-			ExecutionLog: fmt.Sprintf("Intent: %s\nMemory: %d items\nRAG: %d chars",
-				intent.IntentType, len(memoryMemories), len(ragResult)),
+		executionLog := fmt.Sprintf("Intent: %s\nMemory: %d items\nRAG: %d chars",
+			intent.IntentType, len(memoryMemories), len(ragResult))
+		if s.suggestsMemories() {
+			// Suggest mode: propose memories for review instead of storing them
+			suggestions = s.suggestMemories(ctx, session.GetID(), goal, formatResultForContent(finalResult), executionLog)
+		} else if err := s.memoryService.StoreIfWorthwhile(ctx, &domain.MemoryStoreRequest{
+			SessionID:    session.GetID(),
+			TaskGoal:     goal,
+			TaskResult:   formatResultForContent(finalResult),
+			ExecutionLog: executionLog,
 		}); err != nil {
 			s.logger.Warn("failed to store memory", slog.String("error", err.Error()))
 		}
@@ -770,6 +783,8 @@ func (s *Service) finalizeExecution(ctx context.Context, session *Session, goal
 		Memories:    memoryMemories,
 		MemoryLogic: memoryLogic,
 		Duration:    "completed",
+
+		MemorySuggestions: suggestions,
 	}
 
 	// Collect RAG sources
@@ -803,6 +818,7 @@ func (s *Service) performRAGQuery(ctx context.Context, query string) (string, er
 	if s.ragProcessor == nil {
 		return "", nil
 	}
+	span, ctx := s.startSpan(ctx, telemetry.OperationRetrieval, SpanKindRetrieval)
 
 	// Use the RAG processor to query
 	request := domain.QueryRequest{
@@ -814,6 +830,10 @@ func (s *Service) performRAGQuery(ctx context.Context, query string) (string, er
 	}
 
 	results, err := s.ragProcessor.Query(ctx, request)
+	if err == nil && span != nil {
+		s.tracer.SetIntAttribute(span, "agentgo.rag.sources", len(results.Sources))
+	}
+	s.endSpan(span, err)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/liliang-cn/agent-go/pkg/domain"
@@ -12,7 +13,7 @@ func TestBuildConversationMessagesIncludesSessionHistory(t *testing.T) {
 	session.AddMessage(domainMessage("assistant", "我已经给你做了一版摘要。"))
 
 	svc := &Service{}
-	messages := svc.buildConversationMessages(session, "筛一版", "", "", "")
+	messages := svc.buildConversationMessages(session, "筛一版", "", "", "", "", "")
 
 	if len(messages) != 3 {
 		t.Fatalf("expected 3 messages, got %d", len(messages))
@@ -30,7 +31,7 @@ func TestBuildConversationMessagesIncludesSessionHistory(t *testing.T) {
 
 func TestBuildConversationMessagesUsesSummaryWhenHistoryEmpty(t *testing.T) {
 	svc := &Service{}
-	messages := svc.buildConversationMessages(NewSession("agent-1"), "继续", "", "", "之前讨论了今天新闻摘要。")
+	messages := svc.buildConversationMessages(NewSession("agent-1"), "继续", "", "", "", "", "之前讨论了今天新闻摘要。")
 
 	if len(messages) != 1 {
 		t.Fatalf("expected 1 message, got %d", len(messages))
@@ -43,6 +44,19 @@ func TestBuildConversationMessagesUsesSummaryWhenHistoryEmpty(t *testing.T) {
 	}
 }
 
+func TestBuildConversationMessagesIncludesEpisodes(t *testing.T) {
+	svc := &Service{}
+	episodes := "- Last time a similar task (\"deploy staging\") succeeded by doing: called build_image."
+	messages := svc.buildConversationMessages(NewSession("agent-1"), "deploy staging", "", "", episodes, "", "")
+
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	if !strings.HasPrefix(messages[0].Content, "deploy staging") || !strings.Contains(messages[0].Content, episodes) {
+		t.Fatalf("expected episode examples after the goal, got %q", messages[0].Content)
+	}
+}
+
 func domainMessage(role, content string) domain.Message {
 	return domain.Message{Role: role, Content: content}
 }
@@ -3,8 +3,10 @@ package agent
 import (
 	"context"
 	"fmt"
+	"log"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
@@ -258,6 +260,29 @@ func (s *Service) RunRealtime(ctx context.Context, opts *domain.GenerationOption
 
 // Close closes the service and releases resources
 func (s *Service) Close() error {
+	if s.stopMCPWatch != nil {
+		s.stopMCPWatch()
+	}
+	if s.mcpCatalogUnsub != nil {
+		s.mcpCatalogUnsub()
+	}
+	if s.telemetry != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.telemetry.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[Agent] Warning: failed to flush telemetry: %v", err)
+		}
+	}
+	if s.traceStore != nil {
+		if err := s.traceStore.Close(); err != nil {
+			log.Printf("[Agent] Warning: failed to close trace store: %v", err)
+		}
+	}
+	if s.samplingUsage != nil {
+		if err := s.samplingUsage.Close(); err != nil {
+			log.Printf("[Agent] Warning: failed to close sampling usage store: %v", err)
+		}
+	}
 	return s.store.Close()
 }
 
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+)
+
+// Tracer returns the service tracer, or nil when tracing is disabled
+func (s *Service) Tracer() *Tracer {
+	return s.tracer
+}
+
+// TraceStore returns the store traces are persisted to, or nil
+func (s *Service) TraceStore() *TraceStore {
+	return s.traceStore
+}
+
+// startSpan starts a span when tracing is enabled; it is a no-op otherwise
+func (s *Service) startSpan(ctx context.Context, name string, kind SpanKind, opts ...SpanOption) (*Span, context.Context) {
+	if s == nil || s.tracer == nil {
+		return nil, ctx
+	}
+	return s.tracer.StartSpan(ctx, name, kind, opts...)
+}
+
+// endSpan ends a span started with startSpan
+func (s *Service) endSpan(span *Span, err error) {
+	if s == nil || s.tracer == nil {
+		return
+	}
+	s.tracer.EndSpan(span, err)
+}
+
+// tracedGenerator records a chat span with GenAI attributes around every LLM call
+type tracedGenerator struct {
+	inner        domain.Generator
+	tracer       *Tracer
+	tokenCounter *usage.TokenCounter
+}
+
+// tracedRealtimeGenerator keeps realtime support visible to type assertions
+type tracedRealtimeGenerator struct {
+	*tracedGenerator
+	domain.RealtimeGenerator
+}
+
+// traceGenerator wraps gen so its calls are traced by tracer
+func traceGenerator(gen domain.Generator, tracer *Tracer) domain.Generator {
+	if gen == nil || tracer == nil {
+		return gen
+	}
+	traced := &tracedGenerator{inner: gen, tracer: tracer, tokenCounter: usage.NewTokenCounter()}
+	if rt, ok := gen.(domain.RealtimeGenerator); ok {
+		return &tracedRealtimeGenerator{tracedGenerator: traced, RealtimeGenerator: rt}
+	}
+	return traced
+}
+
+func (g *tracedGenerator) GetModelName() string {
+	if info, ok := g.inner.(modelInfoProvider); ok {
+		return info.GetModelName()
+	}
+	return ""
+}
+
+func (g *tracedGenerator) GetBaseURL() string {
+	if info, ok := g.inner.(modelInfoProvider); ok {
+		return info.GetBaseURL()
+	}
+	return ""
+}
+
+func (g *tracedGenerator) start(ctx context.Context, operation string) (*Span, context.Context) {
+	model := g.GetModelName()
+	name := operation
+	if model != "" {
+		name += " " + model
+	}
+	return g.tracer.StartSpan(ctx, name, SpanKindLLM, WithSpanAttributes(map[string]string{
+		string(telemetry.AttrGenAIRequestModel): model,
+		"agentgo.llm.method":                    operation,
+	}))
+}
+
+// end records estimated token usage and closes the span
+func (g *tracedGenerator) end(span *Span, input, output string, toolCalls []domain.ToolCall, err error) {
+	model := g.GetModelName()
+	g.tracer.SetIntAttribute(span, string(telemetry.AttrGenAIInputTokens), g.tokenCounter.EstimateTokens(input, model))
+	g.tracer.SetIntAttribute(span, string(telemetry.AttrGenAIOutputTokens), g.tokenCounter.EstimateTokens(output, model))
+	if len(toolCalls) > 0 {
+		g.tracer.SetIntAttribute(span, "agentgo.llm.tool_calls", len(toolCalls))
+	}
+	// Stopping a stream early on task_complete is not a failure
+	if errors.Is(err, errTaskComplete) {
+		err = nil
+	}
+	g.tracer.EndSpan(span, err)
+}
+
+func messagesText(messages []domain.Message) string {
+	var text string
+	for _, m := range messages {
+		text += m.Content + "\n"
+	}
+	return text
+}
+
+func (g *tracedGenerator) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	span, ctx := g.start(ctx, "generate")
+	out, err := g.inner.Generate(ctx, prompt, opts)
+	g.end(span, prompt, out, nil, err)
+	return out, err
+}
+
+func (g *tracedGenerator) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	span, ctx := g.start(ctx, "stream")
+	var out string
+	err := g.inner.Stream(ctx, prompt, opts, func(chunk string) {
+		out += chunk
+		callback(chunk)
+	})
+	g.end(span, prompt, out, nil, err)
+	return err
+}
+
+func (g *tracedGenerator) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	span, ctx := g.start(ctx, telemetry.OperationChat)
+	result, err := g.inner.GenerateWithTools(ctx, messages, tools, opts)
+	var out string
+	var calls []domain.ToolCall
+	if result != nil {
+		out, calls = result.Content, result.ToolCalls
+	}
+	g.end(span, messagesText(messages), out, calls, err)
+	return result, err
+}
+
+func (g *tracedGenerator) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	span, ctx := g.start(ctx, telemetry.OperationChat)
+	var out string
+	var calls []domain.ToolCall
+	err := g.inner.StreamWithTools(ctx, messages, tools, opts, func(delta *domain.GenerationResult) error {
+		if delta != nil {
+			out += delta.Content
+			calls = append(calls, delta.ToolCalls...)
+		}
+		return callback(delta)
+	})
+	g.end(span, messagesText(messages), out, calls, err)
+	return err
+}
+
+func (g *tracedGenerator) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	span, ctx := g.start(ctx, "generate_structured")
+	result, err := g.inner.GenerateStructured(ctx, prompt, schema, opts)
+	var out string
+	if result != nil {
+		out = result.Raw
+	}
+	g.end(span, prompt, out, nil, err)
+	return result, err
+}
+
+func (g *tracedGenerator) RecognizeIntent(ctx context.Context, request string) (*domain.IntentResult, error) {
+	span, ctx := g.start(ctx, "recognize_intent")
+	result, err := g.inner.RecognizeIntent(ctx, request)
+	g.end(span, request, "", nil, err)
+	return result, err
+}
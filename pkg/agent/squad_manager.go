@@ -16,6 +16,7 @@ import (
 
 	"github.com/liliang-cn/agent-go/pkg/config"
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/liliang-cn/agent-go/pkg/services"
 )
 
@@ -36,6 +37,7 @@ type SquadManager struct {
 	sessionTasks   map[string][]string
 	taskSubs       map[string]map[chan *TaskEvent]struct{}
 	taskCancels    map[string]context.CancelFunc
+	elicitor       mcp.Elicitor
 }
 
 // TeamManager is kept as a compatibility alias for older call sites.
@@ -616,6 +618,17 @@ func (m *SquadManager) GetMemberByNameInSquad(name, squadID string) (*AgentModel
 	return nil, fmt.Errorf("agent '%s' is not in squad %s", model.Name, squadID)
 }
 
+// SetElicitor routes MCP servers' requests for user input from every member,
+// including those already built, to e.
+func (m *SquadManager) SetElicitor(e mcp.Elicitor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.elicitor = e
+	for _, svc := range m.services {
+		svc.SetElicitor(e)
+	}
+}
+
 // getOrBuildService returns a cached service or builds a new one for the agent model.
 func (m *SquadManager) getOrBuildService(name string) (*Service, error) {
 	m.mu.RLock()
@@ -678,6 +691,9 @@ func (m *SquadManager) getOrBuildService(name string) (*Service, error) {
 	}
 	if model.EnableMCP {
 		builder.WithMCP()
+		if m.elicitor != nil {
+			builder.WithElicitor(m.elicitor)
+		}
 	}
 
 	// If the model specifies an LLM model string, this logic would require pool support to select specifically.
@@ -64,6 +64,9 @@ func (s *Store) initSchema() error {
 	if err != nil {
 		return fmt.Errorf("failed to create agent_plans table: %w", err)
 	}
+	if _, err := s.db.Exec(`ALTER TABLE agent_plans ADD COLUMN revisions TEXT`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("failed to migrate agent_plans.revisions: %w", err)
+	}
 
 	_, err = s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS teams (
@@ -156,17 +159,19 @@ func (s *Store) SavePlan(plan *Plan) error {
 	defer s.mu.Unlock()
 
 	stepsJSON, _ := json.Marshal(plan.Steps)
+	revisionsJSON, _ := json.Marshal(plan.Revisions)
 	_, err := s.db.Exec(`
-		INSERT INTO agent_plans (id, goal, session_id, steps, status, reasoning, error, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO agent_plans (id, goal, session_id, steps, status, reasoning, error, revisions, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			goal = excluded.goal,
 			steps = excluded.steps,
 			status = excluded.status,
 			reasoning = excluded.reasoning,
 			error = excluded.error,
+			revisions = excluded.revisions,
 			updated_at = excluded.updated_at
-	`, plan.ID, plan.Goal, plan.SessionID, string(stepsJSON), plan.Status, plan.Reasoning, plan.Error, plan.CreatedAt, plan.UpdatedAt)
+	`, plan.ID, plan.Goal, plan.SessionID, string(stepsJSON), plan.Status, plan.Reasoning, plan.Error, string(revisionsJSON), plan.CreatedAt, plan.UpdatedAt)
 	return err
 }
 
@@ -176,19 +181,22 @@ func (s *Store) GetPlan(id string) (*Plan, error) {
 	defer s.mu.RUnlock()
 
 	var plan Plan
-	var stepsJSON string
+	var stepsJSON, revisionsJSON string
 	err := s.db.QueryRow(`
-		SELECT id, goal, session_id, steps, status, reasoning, error, created_at, updated_at
+		SELECT id, goal, session_id, steps, status, reasoning, error, COALESCE(revisions, ''), created_at, updated_at
 		FROM agent_plans
 		WHERE id = ?
 	`, id).Scan(&plan.ID, &plan.Goal, &plan.SessionID, &stepsJSON,
-		&plan.Status, &plan.Reasoning, &plan.Error, &plan.CreatedAt, &plan.UpdatedAt)
+		&plan.Status, &plan.Reasoning, &plan.Error, &revisionsJSON, &plan.CreatedAt, &plan.UpdatedAt)
 
 	if err != nil {
 		return nil, err
 	}
 
 	_ = json.Unmarshal([]byte(stepsJSON), &plan.Steps)
+	if revisionsJSON != "" {
+		_ = json.Unmarshal([]byte(revisionsJSON), &plan.Revisions)
+	}
 	return &plan, nil
 }
 
@@ -203,7 +211,7 @@ func (s *Store) ListPlans(sessionID string, limit int) ([]*Plan, error) {
 
 	if sessionID != "" {
 		query = `
-			SELECT id, goal, session_id, steps, status, reasoning, error, created_at, updated_at
+			SELECT id, goal, session_id, steps, status, reasoning, error, COALESCE(revisions, ''), created_at, updated_at
 			FROM agent_plans WHERE session_id = ?
 			ORDER BY created_at DESC
 		`
@@ -214,7 +222,7 @@ func (s *Store) ListPlans(sessionID string, limit int) ([]*Plan, error) {
 		}
 	} else {
 		query = `
-			SELECT id, goal, session_id, steps, status, reasoning, error, created_at, updated_at
+			SELECT id, goal, session_id, steps, status, reasoning, error, COALESCE(revisions, ''), created_at, updated_at
 			FROM agent_plans
 			ORDER BY created_at DESC
 		`
@@ -233,13 +241,16 @@ func (s *Store) ListPlans(sessionID string, limit int) ([]*Plan, error) {
 	var plans []*Plan
 	for rows.Next() {
 		var plan Plan
-		var stepsJSON string
+		var stepsJSON, revisionsJSON string
 		err := rows.Scan(&plan.ID, &plan.Goal, &plan.SessionID, &stepsJSON,
-			&plan.Status, &plan.Reasoning, &plan.Error, &plan.CreatedAt, &plan.UpdatedAt)
+			&plan.Status, &plan.Reasoning, &plan.Error, &revisionsJSON, &plan.CreatedAt, &plan.UpdatedAt)
 		if err != nil {
 			continue
 		}
 		_ = json.Unmarshal([]byte(stepsJSON), &plan.Steps)
+		if revisionsJSON != "" {
+			_ = json.Unmarshal([]byte(revisionsJSON), &plan.Revisions)
+		}
 		plans = append(plans, &plan)
 	}
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 )
 
 // SubAgentMode defines how the sub-agent runs
@@ -229,6 +230,19 @@ func (sa *SubAgent) Run(parentCtx context.Context) (interface{}, error) {
 		sa.ctx = sa.timeoutCtx
 	}
 
+	// Tool-call sub-agents are already traced as execute_tool spans
+	if sa.config.ToolCall == nil {
+		var span *Span
+		span, sa.ctx = sa.config.Service.startSpan(sa.ctx, telemetry.OperationInvokeAgent+" "+sa.config.Agent.Name(), SpanKindAgent,
+			WithSpanAgentID(sa.config.Agent.ID()),
+			WithSpanSessionID(sa.session.GetID()),
+			WithSpanAttributes(map[string]string{
+				string(telemetry.AttrGenAIAgentName): sa.config.Agent.Name(),
+				"agentgo.subagent.id":                sa.id,
+			}))
+		defer func() { sa.config.Service.endSpan(span, sa.err) }()
+	}
+
 	// Emit SubagentStart hook
 	sa.hooks.Emit(HookEventSubagentStart, HookData{
 		SubagentID:   sa.id,
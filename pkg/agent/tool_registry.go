@@ -22,6 +22,8 @@ const (
 	CategoryMemory = "memory" // memory_save/recall/update/delete
 	CategorySkill  = "skill"  // skill tools (currently managed via ptcRouter)
 	CategoryMCP    = "mcp"    // MCP tools (dynamically managed via ptcRouter)
+
+	CategoryMCPContext = "mcp_context" // read_resource, use_prompt, ingest_resource
 )
 
 type registeredTool struct {
@@ -158,6 +160,19 @@ func (r *ToolRegistry) CategoryOf(name string) string {
 	return ""
 }
 
+// NamesInCategory returns the names of the tools registered in category.
+func (r *ToolRegistry) NamesInCategory(category string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for name, t := range r.tools {
+		if t.category == category {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // ListForLLM returns the tool definitions that should be passed to the LLM.
 //
 //   - ptcEnabled=false: all registered tools (they appear as direct function calls)
@@ -33,7 +33,12 @@ func (e *TraceExporter) Export(traceID string, format ExportFormat) (string, err
 	if !ok {
 		return "", fmt.Errorf("trace not found: %s", traceID)
 	}
+	return e.ExportTrace(trace, format)
+}
 
+// ExportTrace exports a trace that is not held by the tracer, such as one
+// loaded from a TraceStore
+func (e *TraceExporter) ExportTrace(trace *Trace, format ExportFormat) (string, error) {
 	switch format {
 	case ExportFormatJSON:
 		return e.exportJSON(trace)
@@ -0,0 +1,350 @@
+package agent
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+	_ "modernc.org/sqlite"
+)
+
+// TraceStore persists spans to SQLite so traces survive process restarts
+type TraceStore struct {
+	db        *sql.DB
+	path      string
+	retention time.Duration
+}
+
+// TraceRecord is a persisted trace aggregated from its spans
+type TraceRecord struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	SessionID    string        `json:"session_id,omitempty"`
+	AgentID      string        `json:"agent_id,omitempty"`
+	Status       SpanStatus    `json:"status"`
+	StartTime    time.Time     `json:"start_time"`
+	EndTime      time.Time     `json:"end_time"`
+	Duration     time.Duration `json:"duration"`
+	SpanCount    int           `json:"span_count"`
+	LLMCalls     int           `json:"llm_calls"`
+	ToolCalls    int           `json:"tool_calls"`
+	InputTokens  int           `json:"input_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	Errors       int           `json:"errors"`
+}
+
+// TraceFilter narrows ListTraces
+type TraceFilter struct {
+	SessionID string
+	AgentID   string
+	Since     time.Time
+	Limit     int
+}
+
+// TraceDetail is a persisted trace joined with the history records written
+// while it ran and the usage records written under it
+type TraceDetail struct {
+	Trace   *TraceRecord         `json:"trace"`
+	Spans   []*Span              `json:"spans"`
+	History []*HistoryRecord     `json:"history,omitempty"`
+	Usage   []*usage.UsageRecord `json:"usage,omitempty"`
+}
+
+// NewTraceStore opens (or creates) a trace database and drops traces older
+// than retention; zero or less keeps them forever
+func NewTraceStore(dbPath string, retention time.Duration) (*TraceStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	store := &TraceStore{db: db, path: dbPath, retention: retention}
+	if err := store.initSchema(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize trace schema: %w", err)
+	}
+	if _, err := store.Prune(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prune traces: %w", err)
+	}
+	return store, nil
+}
+
+func (s *TraceStore) initSchema() error {
+	_, err := s.db.Exec(`
+	PRAGMA journal_mode=WAL;
+	PRAGMA busy_timeout=5000;
+
+	CREATE TABLE IF NOT EXISTS trace_spans (
+		id TEXT NOT NULL,
+		trace_id TEXT NOT NULL,
+		parent_id TEXT,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL,
+		start_time INTEGER NOT NULL,
+		end_time INTEGER NOT NULL,
+		duration_ms INTEGER DEFAULT 0,
+		agent_id TEXT,
+		session_id TEXT,
+		plan_id TEXT,
+		step_id TEXT,
+		input_tokens INTEGER DEFAULT 0,
+		output_tokens INTEGER DEFAULT 0,
+		attributes TEXT,
+		events TEXT,
+		PRIMARY KEY (trace_id, id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_trace_spans_session ON trace_spans(session_id);
+	CREATE INDEX IF NOT EXISTS idx_trace_spans_start ON trace_spans(start_time);
+	`)
+	return err
+}
+
+// Path returns the database file path
+func (s *TraceStore) Path() string {
+	return s.path
+}
+
+// SaveSpan stores (or replaces) a finished span
+func (s *TraceStore) SaveSpan(ctx context.Context, span *Span) error {
+	attrs, err := json.Marshal(span.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to encode span attributes: %w", err)
+	}
+	events, err := json.Marshal(span.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode span events: %w", err)
+	}
+	input, _ := strconv.Atoi(span.Attributes[string(telemetry.AttrGenAIInputTokens)])
+	output, _ := strconv.Atoi(span.Attributes[string(telemetry.AttrGenAIOutputTokens)])
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO trace_spans
+		(id, trace_id, parent_id, name, kind, status, start_time, end_time, duration_ms,
+		 agent_id, session_id, plan_id, step_id, input_tokens, output_tokens, attributes, events)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, span.ID, span.TraceID, span.ParentID, span.Name, string(span.Kind), string(span.Status),
+		span.StartTime.UnixNano(), span.EndTime.UnixNano(), span.Duration.Milliseconds(),
+		span.AgentID, span.SessionID, span.PlanID, span.StepID, input, output, string(attrs), string(events))
+	return err
+}
+
+// ListTraces returns persisted traces, newest first
+func (s *TraceStore) ListTraces(ctx context.Context, filter TraceFilter) ([]*TraceRecord, error) {
+	var where []string
+	var args []interface{}
+	if filter.SessionID != "" {
+		where = append(where, "trace_id IN (SELECT trace_id FROM trace_spans WHERE session_id = ?)")
+		args = append(args, filter.SessionID)
+	}
+	if filter.AgentID != "" {
+		where = append(where, "trace_id IN (SELECT trace_id FROM trace_spans WHERE agent_id = ?)")
+		args = append(args, filter.AgentID)
+	}
+	query := summaryQuery
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " GROUP BY trace_id"
+	if !filter.Since.IsZero() {
+		query += " HAVING MIN(start_time) >= ?"
+		args = append(args, filter.Since.UnixNano())
+	}
+	query += " ORDER BY MIN(start_time) DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list traces: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*TraceRecord
+	for rows.Next() {
+		summary, err := scanTraceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// GetTrace returns a persisted trace and its spans ordered by start time
+func (s *TraceStore) GetTrace(ctx context.Context, traceID string) (*TraceRecord, []*Span, error) {
+	summary, err := scanTraceRecord(s.db.QueryRowContext(ctx, summaryQuery+" WHERE trace_id = ? GROUP BY trace_id", traceID))
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("trace not found: %s", traceID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, trace_id, parent_id, name, kind, status, start_time, end_time,
+		       agent_id, session_id, plan_id, step_id, attributes, events
+		FROM trace_spans WHERE trace_id = ? ORDER BY start_time
+	`, traceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load spans: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []*Span
+	for rows.Next() {
+		var span Span
+		var parentID, agentID, sessionID, planID, stepID, attrs, events sql.NullString
+		var kind, status string
+		var start, end int64
+		if err := rows.Scan(&span.ID, &span.TraceID, &parentID, &span.Name, &kind, &status, &start, &end,
+			&agentID, &sessionID, &planID, &stepID, &attrs, &events); err != nil {
+			return nil, nil, err
+		}
+		span.ParentID, span.AgentID, span.SessionID = parentID.String, agentID.String, sessionID.String
+		span.PlanID, span.StepID = planID.String, stepID.String
+		span.Kind, span.Status = SpanKind(kind), SpanStatus(status)
+		span.StartTime, span.EndTime = time.Unix(0, start), time.Unix(0, end)
+		span.Duration = span.EndTime.Sub(span.StartTime)
+		if attrs.Valid && attrs.String != "" {
+			_ = json.Unmarshal([]byte(attrs.String), &span.Attributes)
+		}
+		if events.Valid && events.String != "" {
+			_ = json.Unmarshal([]byte(events.String), &span.Events)
+		}
+		spans = append(spans, &span)
+	}
+	return summary, spans, rows.Err()
+}
+
+// Prune deletes traces whose last span ended before the retention window
+func (s *TraceStore) Prune(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	return s.PruneBefore(ctx, time.Now().Add(-s.retention))
+}
+
+// PruneBefore deletes traces whose last span ended before cutoff
+func (s *TraceStore) PruneBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM trace_spans WHERE trace_id IN (
+			SELECT trace_id FROM trace_spans GROUP BY trace_id HAVING MAX(end_time) < ?
+		)
+	`, cutoff.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Close closes the database
+func (s *TraceStore) Close() error {
+	return s.db.Close()
+}
+
+// summaryQuery aggregates spans into one row per trace. The root span
+// (empty parent) supplies the trace name.
+const summaryQuery = `
+	SELECT trace_id,
+	       COALESCE(MAX(CASE WHEN parent_id = '' OR parent_id IS NULL THEN name END), MIN(name)),
+	       COALESCE(MAX(session_id), ''),
+	       COALESCE(MAX(agent_id), ''),
+	       MIN(start_time), MAX(end_time), COUNT(*),
+	       SUM(CASE WHEN kind = 'llm' THEN 1 ELSE 0 END),
+	       SUM(CASE WHEN kind = 'tool' THEN 1 ELSE 0 END),
+	       SUM(input_tokens), SUM(output_tokens),
+	       SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END)
+	FROM trace_spans`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTraceRecord(row rowScanner) (*TraceRecord, error) {
+	var summary TraceRecord
+	var start, end int64
+	if err := row.Scan(&summary.ID, &summary.Name, &summary.SessionID, &summary.AgentID,
+		&start, &end, &summary.SpanCount, &summary.LLMCalls, &summary.ToolCalls,
+		&summary.InputTokens, &summary.OutputTokens, &summary.Errors); err != nil {
+		return nil, err
+	}
+	summary.StartTime, summary.EndTime = time.Unix(0, start), time.Unix(0, end)
+	summary.Duration = summary.EndTime.Sub(summary.StartTime)
+	summary.Status = SpanStatusOK
+	if summary.Errors > 0 {
+		summary.Status = SpanStatusError
+	}
+	return &summary, nil
+}
+
+// ToTrace rebuilds the in-memory form of a persisted trace, for use with
+// TraceExporter
+func (r *TraceRecord) ToTrace(spans []*Span) *Trace {
+	trace := &Trace{
+		ID:        r.ID,
+		Spans:     make(map[string]*Span, len(spans)),
+		StartTime: r.StartTime,
+		EndTime:   r.EndTime,
+		Duration:  r.Duration,
+		Metadata:  map[string]interface{}{"name": r.Name},
+	}
+	for _, span := range spans {
+		trace.Spans[span.ID] = span
+		if span.ParentID == "" {
+			trace.RootSpan = span
+		}
+	}
+	return trace
+}
+
+// LoadTraceDetail loads a trace and links it to the history records of its
+// session and the usage records written under it. history and usageSvc are
+// optional.
+func LoadTraceDetail(ctx context.Context, store *TraceStore, traceID string, history *HistoryStore, usageSvc *usage.Service) (*TraceDetail, error) {
+	summary, spans, err := store.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	detail := &TraceDetail{Trace: summary, Spans: spans}
+
+	if history != nil && summary.SessionID != "" {
+		records, err := history.GetSessionHistory(ctx, summary.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load history: %w", err)
+		}
+		for _, r := range records {
+			if !r.CreatedAt.Before(summary.StartTime) && !r.CreatedAt.After(summary.EndTime) {
+				detail.History = append(detail.History, r)
+			}
+		}
+	}
+
+	if usageSvc != nil {
+		records, err := usageSvc.ListUsageRecords(ctx, &usage.UsageFilter{TraceID: traceID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load usage: %w", err)
+		}
+		sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+		detail.Usage = records
+	}
+	return detail, nil
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+)
+
+func TestTracerPersistsSpansToTraceStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "traces.db")
+	store, err := NewTraceStore(path, 0)
+	if err != nil {
+		t.Fatalf("open trace store: %v", err)
+	}
+
+	usageSvc, err := usage.NewServiceWithDataDir(&config.Config{}, t.TempDir())
+	if err != nil {
+		t.Fatalf("open usage service: %v", err)
+	}
+	defer usageSvc.Close()
+
+	tracer := NewTracer()
+	tracer.UseStore(store)
+
+	root, runCtx := tracer.StartSpan(ctx, "invoke_agent tester", SpanKindAgent, WithSpanSessionID("session-1"))
+	llm, llmCtx := tracer.StartSpan(runCtx, "chat test-model", SpanKindLLM)
+	if _, err := usageSvc.TrackLLMCallWithTokens(llmCtx, "test", "test-model", 12, 4, time.Now()); err != nil {
+		t.Fatalf("track usage: %v", err)
+	}
+	// Written while the trace ran but not under it
+	if _, err := usageSvc.TrackLLMCallWithTokens(ctx, "test", "other-model", 1, 1, time.Now()); err != nil {
+		t.Fatalf("track usage: %v", err)
+	}
+	tracer.SetIntAttribute(llm, "gen_ai.usage.input_tokens", 12)
+	tracer.SetIntAttribute(llm, "gen_ai.usage.output_tokens", 4)
+	tracer.EndSpan(llm, nil)
+	tool, _ := tracer.StartSpan(runCtx, "execute_tool search", SpanKindTool)
+	tracer.EndSpan(tool, context.DeadlineExceeded)
+	tracer.EndSpan(root, nil)
+	store.Close()
+
+	// Reopen to prove the trace survives the process
+	store, err = NewTraceStore(path, 0)
+	if err != nil {
+		t.Fatalf("reopen trace store: %v", err)
+	}
+	defer store.Close()
+
+	traces, err := store.ListTraces(ctx, TraceFilter{SessionID: "session-1"})
+	if err != nil {
+		t.Fatalf("list traces: %v", err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("expected 1 trace, got %d", len(traces))
+	}
+	record := traces[0]
+	if record.Name != "invoke_agent tester" || record.SpanCount != 3 {
+		t.Fatalf("unexpected trace record: %+v", record)
+	}
+	if record.LLMCalls != 1 || record.ToolCalls != 1 || record.Errors != 1 || record.Status != SpanStatusError {
+		t.Fatalf("unexpected trace counters: %+v", record)
+	}
+	if record.InputTokens != 12 || record.OutputTokens != 4 {
+		t.Fatalf("unexpected token totals: %d/%d", record.InputTokens, record.OutputTokens)
+	}
+
+	detail, err := LoadTraceDetail(ctx, store, record.ID, nil, usageSvc)
+	if err != nil {
+		t.Fatalf("load trace detail: %v", err)
+	}
+	if len(detail.Usage) != 1 || detail.Usage[0].Model != "test-model" {
+		t.Fatalf("expected only the usage written under the trace, got %+v", detail.Usage)
+	}
+	if len(detail.Spans) != 3 || detail.Spans[0].ID != root.ID {
+		t.Fatalf("expected root span first, got %+v", detail.Spans)
+	}
+	if detail.Spans[1].ParentID != root.ID {
+		t.Fatalf("expected child span parented to root")
+	}
+
+	exported, err := NewTraceExporter(nil).ExportTrace(record.ToTrace(detail.Spans), ExportFormatJSON)
+	if err != nil || exported == "" {
+		t.Fatalf("export trace: %v", err)
+	}
+}
+
+func TestTraceStorePrunesExpiredTraces(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "traces.db")
+	store, err := NewTraceStore(path, 0)
+	if err != nil {
+		t.Fatalf("open trace store: %v", err)
+	}
+	defer store.Close()
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, span := range []*Span{
+		{ID: "a", TraceID: "old", Name: "old", Kind: SpanKindAgent, Status: SpanStatusOK, StartTime: old, EndTime: old.Add(time.Second)},
+		{ID: "b", TraceID: "new", Name: "new", Kind: SpanKindAgent, Status: SpanStatusOK, StartTime: time.Now(), EndTime: time.Now()},
+	} {
+		if err := store.SaveSpan(ctx, span); err != nil {
+			t.Fatalf("save span: %v", err)
+		}
+	}
+
+	if removed, err := store.Prune(ctx); err != nil || removed != 0 {
+		t.Fatalf("expected nothing pruned without retention, got %d (%v)", removed, err)
+	}
+	store.Close()
+
+	// Reopening with a retention prunes before the store is used
+	store, err = NewTraceStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("reopen trace store: %v", err)
+	}
+	defer store.Close()
+	if _, _, err := store.GetTrace(ctx, "old"); err == nil {
+		t.Fatalf("expected old trace to be pruned")
+	}
+	if _, _, err := store.GetTrace(ctx, "new"); err != nil {
+		t.Fatalf("expected new trace to remain: %v", err)
+	}
+}
@@ -3,8 +3,17 @@ package agent
 import (
 	"context"
 	"fmt"
+	"log"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // SpanKind represents the type of span
@@ -17,6 +26,7 @@ const (
 	SpanKindGuardrail SpanKind = "guardrail" // Guardrail checks
 	SpanKindHandoff   SpanKind = "handoff"   // Handoff operations
 	SpanKindLLM       SpanKind = "llm"       // LLM calls
+	SpanKindRetrieval SpanKind = "retrieval" // RAG retrievals
 )
 
 // SpanStatus represents the status of a span
@@ -45,6 +55,9 @@ type Span struct {
 	SessionID  string            `json:"session_id,omitempty"`
 	PlanID     string            `json:"plan_id,omitempty"`
 	StepID     string            `json:"step_id,omitempty"`
+
+	// otel is the mirrored OpenTelemetry span when telemetry is enabled
+	otel trace.Span
 }
 
 // SpanEvent represents an event within a span
@@ -65,12 +78,23 @@ type Trace struct {
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// maxTraces bounds how many traces a Tracer keeps in memory
+const maxTraces = 1000
+
 // Tracer creates and manages spans
 type Tracer struct {
 	mu           sync.RWMutex
 	traces       map[string]*Trace
+	traceOrder   []string
 	currentSpans map[string]*Span
 	enabled      bool
+
+	// OpenTelemetry mirroring (optional)
+	otelTracer trace.Tracer
+	metrics    *telemetry.Metrics
+
+	// store persists finished spans (optional)
+	store *TraceStore
 }
 
 // NewTracer creates a new tracer
@@ -96,6 +120,26 @@ func (t *Tracer) SetEnabled(enabled bool) {
 	t.enabled = enabled
 }
 
+// UseTelemetry mirrors every span to OpenTelemetry and records latency and
+// token metrics through the provider
+func (t *Tracer) UseTelemetry(p *telemetry.Provider) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p == nil {
+		t.otelTracer, t.metrics = nil, nil
+		return
+	}
+	t.otelTracer = p.Tracer()
+	t.metrics = p.Metrics()
+}
+
+// UseStore persists every finished span to store
+func (t *Tracer) UseStore(store *TraceStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+}
+
 // StartSpan starts a new span
 func (t *Tracer) StartSpan(ctx context.Context, name string, kind SpanKind, opts ...SpanOption) (*Span, context.Context) {
 	if !t.enabled {
@@ -105,21 +149,9 @@ func (t *Tracer) StartSpan(ctx context.Context, name string, kind SpanKind, opts
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	// Get trace ID from context or create new
-	traceID := getTraceID(ctx)
-	if traceID == "" {
-		traceID = newID()
-		ctx = withTraceID(ctx, traceID)
-	}
-
-	// Get parent span ID from context
-	parentID := getParentSpanID(ctx)
-
 	// Create span
 	span := &Span{
 		ID:         newID(),
-		TraceID:    traceID,
-		ParentID:   parentID,
 		Name:       name,
 		Kind:       kind,
 		Status:     SpanStatusOK,
@@ -133,6 +165,29 @@ func (t *Tracer) StartSpan(ctx context.Context, name string, kind SpanKind, opts
 		opt(span)
 	}
 
+	// Mirror to OpenTelemetry; the OTel span context travels in ctx so
+	// outbound MCP/PTC calls carry it, and its IDs become ours
+	traceID := getTraceID(ctx)
+	if t.otelTracer != nil {
+		ctx, span.otel = t.otelTracer.Start(ctx, name,
+			trace.WithSpanKind(otelSpanKind(kind)),
+			trace.WithTimestamp(span.StartTime),
+			trace.WithAttributes(otelAttributes(span)...))
+		sc := span.otel.SpanContext()
+		span.ID = sc.SpanID().String()
+		traceID = sc.TraceID().String()
+	}
+
+	// Get trace ID from context or create new
+	if traceID == "" {
+		traceID = newID()
+	}
+	ctx = usage.WithTraceID(withTraceID(ctx, traceID), traceID)
+	span.TraceID = traceID
+
+	// Get parent span ID from context
+	span.ParentID = getParentSpanID(ctx)
+
 	// Get or create trace
 	trace, exists := t.traces[traceID]
 	if !exists {
@@ -143,6 +198,11 @@ func (t *Tracer) StartSpan(ctx context.Context, name string, kind SpanKind, opts
 			Metadata:  make(map[string]interface{}),
 		}
 		t.traces[traceID] = trace
+		t.traceOrder = append(t.traceOrder, traceID)
+		if len(t.traceOrder) > maxTraces {
+			delete(t.traces, t.traceOrder[0])
+			t.traceOrder = t.traceOrder[1:]
+		}
 	}
 
 	// Add span to trace
@@ -167,6 +227,16 @@ func (t *Tracer) EndSpan(span *Span, err error) {
 		return
 	}
 
+	store, finished := t.endSpan(span, err)
+	if store != nil {
+		if saveErr := store.SaveSpan(context.Background(), finished); saveErr != nil {
+			log.Printf("[Agent] Warning: failed to persist span %s: %v", finished.Name, saveErr)
+		}
+	}
+}
+
+// endSpan finishes span under the lock and returns a copy to persist
+func (t *Tracer) endSpan(span *Span, err error) (*TraceStore, *Span) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -178,6 +248,17 @@ func (t *Tracer) EndSpan(span *Span, err error) {
 		span.Attributes["error"] = err.Error()
 	}
 
+	if span.otel != nil {
+		if err != nil {
+			span.otel.RecordError(err)
+			span.otel.SetStatus(codes.Error, err.Error())
+		} else {
+			span.otel.SetStatus(codes.Ok, "")
+		}
+		span.otel.End(trace.WithTimestamp(span.EndTime))
+	}
+	t.recordMetrics(span, err)
+
 	// Update trace if this is the root span
 	if trace, ok := t.traces[span.TraceID]; ok {
 		if trace.RootSpan != nil && trace.RootSpan.ID == span.ID {
@@ -187,6 +268,17 @@ func (t *Tracer) EndSpan(span *Span, err error) {
 	}
 
 	delete(t.currentSpans, span.ID)
+
+	if t.store == nil {
+		return nil, nil
+	}
+	finished := *span
+	finished.Attributes = make(map[string]string, len(span.Attributes))
+	for k, v := range span.Attributes {
+		finished.Attributes[k] = v
+	}
+	finished.Events = append([]SpanEvent(nil), span.Events...)
+	return t.store, &finished
 }
 
 // AddEvent adds an event to a span
@@ -198,11 +290,20 @@ func (t *Tracer) AddEvent(span *Span, name string, attrs map[string]string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	span.Events = append(span.Events, SpanEvent{
+	event := SpanEvent{
 		Time:       time.Now(),
 		Name:       name,
 		Attributes: attrs,
-	})
+	}
+	span.Events = append(span.Events, event)
+
+	if span.otel != nil {
+		kvs := make([]attribute.KeyValue, 0, len(attrs))
+		for k, v := range attrs {
+			kvs = append(kvs, attribute.String(k, v))
+		}
+		span.otel.AddEvent(name, trace.WithTimestamp(event.Time), trace.WithAttributes(kvs...))
+	}
 }
 
 // SetAttribute sets an attribute on a span
@@ -215,6 +316,24 @@ func (t *Tracer) SetAttribute(span *Span, key, value string) {
 	defer t.mu.Unlock()
 
 	span.Attributes[key] = value
+	if span.otel != nil {
+		span.otel.SetAttributes(attribute.String(key, value))
+	}
+}
+
+// SetIntAttribute sets a numeric attribute, such as a token count, on a span
+func (t *Tracer) SetIntAttribute(span *Span, key string, value int) {
+	if span == nil || !t.enabled {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span.Attributes[key] = strconv.Itoa(value)
+	if span.otel != nil {
+		span.otel.SetAttributes(attribute.Int(key, value))
+	}
 }
 
 // GetTrace retrieves a trace by ID
@@ -244,6 +363,7 @@ func (t *Tracer) Clear() {
 	defer t.mu.Unlock()
 
 	t.traces = make(map[string]*Trace)
+	t.traceOrder = nil
 	t.currentSpans = make(map[string]*Span)
 }
 
@@ -287,6 +407,85 @@ func WithSpanAttributes(attrs map[string]string) SpanOption {
 	}
 }
 
+// otelSpanKind maps a span kind to the OpenTelemetry span kind
+func otelSpanKind(kind SpanKind) trace.SpanKind {
+	if kind == SpanKindLLM || kind == SpanKindRetrieval {
+		return trace.SpanKindClient
+	}
+	return trace.SpanKindInternal
+}
+
+// operationName maps a span kind to its GenAI operation name
+func operationName(kind SpanKind) string {
+	switch kind {
+	case SpanKindLLM:
+		return telemetry.OperationChat
+	case SpanKindAgent, SpanKindHandoff:
+		return telemetry.OperationInvokeAgent
+	case SpanKindTool:
+		return telemetry.OperationExecuteTool
+	case SpanKindRetrieval:
+		return telemetry.OperationRetrieval
+	}
+	return ""
+}
+
+// otelAttributes converts a span's identity and attributes to OTel attributes
+func otelAttributes(span *Span) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("agentgo.span.kind", string(span.Kind))}
+	if op := operationName(span.Kind); op != "" {
+		attrs = append(attrs, telemetry.AttrGenAIOperationName.String(op))
+	}
+	if span.AgentID != "" {
+		attrs = append(attrs, telemetry.AttrGenAIAgentID.String(span.AgentID))
+	}
+	if span.SessionID != "" {
+		attrs = append(attrs, telemetry.AttrGenAIConversationID.String(span.SessionID))
+	}
+	if span.PlanID != "" {
+		attrs = append(attrs, attribute.String("agentgo.plan.id", span.PlanID))
+	}
+	if span.StepID != "" {
+		attrs = append(attrs, attribute.String("agentgo.step.id", span.StepID))
+	}
+	for k, v := range span.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return attrs
+}
+
+// recordMetrics records the span's latency and token usage
+func (t *Tracer) recordMetrics(span *Span, err error) {
+	m := t.metrics
+	if m == nil {
+		return
+	}
+	ctx := context.Background()
+	seconds := span.Duration.Seconds()
+	attrs := []attribute.KeyValue{telemetry.AttrGenAIOperationName.String(operationName(span.Kind))}
+	if err != nil {
+		attrs = append(attrs, telemetry.AttrErrorType.String(fmt.Sprintf("%T", err)))
+	}
+
+	switch span.Kind {
+	case SpanKindLLM:
+		model := span.Attributes[string(telemetry.AttrGenAIRequestModel)]
+		attrs = append(attrs, telemetry.AttrGenAIRequestModel.String(model))
+		m.LLMDuration.Record(ctx, seconds, metric.WithAttributes(attrs...))
+		input, _ := strconv.Atoi(span.Attributes[string(telemetry.AttrGenAIInputTokens)])
+		output, _ := strconv.Atoi(span.Attributes[string(telemetry.AttrGenAIOutputTokens)])
+		m.RecordTokens(ctx, model, input, output)
+	case SpanKindTool:
+		attrs = append(attrs, telemetry.AttrGenAIToolName.String(span.Attributes[string(telemetry.AttrGenAIToolName)]))
+		m.ToolDuration.Record(ctx, seconds, metric.WithAttributes(attrs...))
+	case SpanKindAgent, SpanKindHandoff:
+		attrs = append(attrs, telemetry.AttrGenAIAgentName.String(span.Attributes[string(telemetry.AttrGenAIAgentName)]))
+		m.AgentDuration.Record(ctx, seconds, metric.WithAttributes(attrs...))
+	case SpanKindRetrieval:
+		m.RetrievalDuration.Record(ctx, seconds, metric.WithAttributes(attrs...))
+	}
+}
+
 // Context key types for trace context
 type contextKey string
 
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestBuilderWithTracerExportsGenAISpans(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := telemetry.NewProvider(telemetry.Config{ServiceName: "agent-test"}, exporter, nil)
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	tracer := NewTracer()
+	tracer.UseTelemetry(provider)
+
+	svc, err := New("traced-agent").
+		WithConfig(cassetteTestConfig(t)).
+		WithLLM(&fileMemoryTestLLM{}).
+		WithTracer(tracer).
+		Build()
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	defer svc.Close()
+
+	if _, err := svc.Chat(ctx, "remember: Alice likes tea"); err != nil {
+		t.Fatalf("chat failed: %v", err)
+	}
+	if err := provider.ForceFlush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	var agentSpan, chatSpan *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i := range spans {
+		switch {
+		case spans[i].Name == "invoke_agent traced-agent":
+			agentSpan = &spans[i]
+		case strings.HasPrefix(spans[i].Name, "chat") && chatSpan == nil:
+			chatSpan = &spans[i]
+		}
+	}
+	if agentSpan == nil || chatSpan == nil {
+		t.Fatalf("expected invoke_agent and chat spans, got %d spans", len(spans))
+	}
+	if chatSpan.SpanContext.TraceID() != agentSpan.SpanContext.TraceID() {
+		t.Fatalf("chat span is not part of the agent trace")
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range chatSpan.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs[string(telemetry.AttrGenAIOperationName)] != telemetry.OperationChat {
+		t.Fatalf("chat span operation = %q", attrs[string(telemetry.AttrGenAIOperationName)])
+	}
+	if attrs[string(telemetry.AttrGenAIInputTokens)] == "" {
+		t.Fatalf("chat span missing input token count: %v", attrs)
+	}
+
+	if len(tracer.ListTraces()) == 0 {
+		t.Fatalf("expected spans to be kept in the in-memory tracer")
+	}
+}
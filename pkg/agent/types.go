@@ -63,6 +63,29 @@ type Plan struct {
 	Status    string    `json:"status"`
 	Error     string    `json:"error,omitempty"`
 	Reasoning string    `json:"reasoning,omitempty"` // LLM's reasoning for the plan
+	// Revisions records every change made to the plan's steps, oldest first.
+	Revisions []PlanRevision `json:"revisions,omitempty"`
+}
+
+// Plan revision triggers
+const (
+	RevisionTriggerStepFailed   = "step_failed"
+	RevisionTriggerVerification = "verification_failed"
+	RevisionTriggerUser         = "user_instruction"
+)
+
+// PlanRevision describes one revision of a plan's steps, either requested by
+// the user through RevisePlan or made automatically by ExecutePlanAdaptive.
+type PlanRevision struct {
+	Number        int       `json:"number"`
+	Trigger       string    `json:"trigger"`                  // step_failed, verification_failed, user_instruction
+	FailedStepID  string    `json:"failed_step_id,omitempty"` // Step that caused an automatic revision
+	Failure       string    `json:"failure,omitempty"`        // Error or verification reason
+	Instruction   string    `json:"instruction,omitempty"`    // User instruction for manual revisions
+	Reasoning     string    `json:"reasoning,omitempty"`      // Planner's explanation of the new steps
+	ReplacedSteps []Step    `json:"replaced_steps,omitempty"` // Steps discarded by this revision
+	NewSteps      []Step    `json:"new_steps,omitempty"`      // Steps introduced by this revision
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // ExecutionResult represents the result of an agent execution
@@ -87,6 +110,8 @@ type ExecutionResult struct {
 	Metadata        map[string]interface{}    `json:"metadata,omitempty"`
 	// PTCResult contains PTC execution details when PTC mode is active.
 	PTCResult *PTCResult `json:"ptc_result,omitempty"`
+	// MemorySuggestions are memories proposed for review (memory suggest mode)
+	MemorySuggestions []*domain.MemorySuggestion `json:"memory_suggestions,omitempty"`
 }
 
 // AgentInfo contains information about an agent's status and configuration
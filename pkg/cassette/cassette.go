@@ -0,0 +1,373 @@
+// Package cassette records LLM interactions to a file and replays them, so
+// agent tests can run deterministically without network access.
+package cassette
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// Version is the cassette file format version
+const Version = 1
+
+// Mode selects whether a cassette is recorded or replayed
+type Mode string
+
+const (
+	// ModeOff disables cassettes
+	ModeOff Mode = ""
+	// ModeRecord calls the real LLM and writes every interaction to the cassette
+	ModeRecord Mode = "record"
+	// ModeReplay serves recorded responses and never calls the real LLM
+	ModeReplay Mode = "replay"
+)
+
+// MatchMode controls how replayed requests are matched to recorded ones
+type MatchMode string
+
+const (
+	// MatchStrict requires the full normalized request (messages, tools,
+	// options, schema) to hash identically
+	MatchStrict MatchMode = "strict"
+	// MatchLenient ignores system prompts and generation options and, when
+	// nothing matches, serves the next unused interaction of the same kind
+	MatchLenient MatchMode = "lenient"
+)
+
+// Kind identifies the Generator method an interaction was recorded from
+type Kind string
+
+const (
+	KindGenerate           Kind = "generate"
+	KindStream             Kind = "stream"
+	KindGenerateWithTools  Kind = "generate_with_tools"
+	KindStreamWithTools    Kind = "stream_with_tools"
+	KindGenerateStructured Kind = "generate_structured"
+	KindRecognizeIntent    Kind = "recognize_intent"
+)
+
+// ErrNoMatch is returned by replay when no recorded interaction matches a request
+var ErrNoMatch = errors.New("cassette: no recorded interaction matches request")
+
+// Config configures cassette recording or replay
+type Config struct {
+	Mode  Mode      `mapstructure:"mode" json:"mode"`
+	Path  string    `mapstructure:"path" json:"path"`
+	Match MatchMode `mapstructure:"match" json:"match"`
+}
+
+// Enabled reports whether the config turns cassettes on
+func (c Config) Enabled() bool {
+	return c.Mode != ModeOff
+}
+
+// Validate checks the mode, match mode and path
+func (c Config) Validate() error {
+	switch c.Mode {
+	case ModeOff:
+		return nil
+	case ModeRecord, ModeReplay:
+	default:
+		return fmt.Errorf("invalid cassette mode %q (use record or replay)", c.Mode)
+	}
+	switch c.Match {
+	case "", MatchStrict, MatchLenient:
+	default:
+		return fmt.Errorf("invalid cassette match mode %q (use strict or lenient)", c.Match)
+	}
+	if c.Path == "" {
+		return fmt.Errorf("cassette path is required")
+	}
+	return nil
+}
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Kind        Kind            `json:"kind"`
+	Hash        string          `json:"hash"`
+	LenientHash string          `json:"lenient_hash"`
+	Request     json.RawMessage `json:"request"`
+	Response    Response        `json:"response"`
+	RecordedAt  time.Time       `json:"recorded_at"`
+}
+
+// Response holds whatever the recorded method returned
+type Response struct {
+	// Text is the result of Generate and the concatenated output of Stream
+	Text string `json:"text,omitempty"`
+	// Chunks are the Stream callback chunks in order
+	Chunks []string `json:"chunks,omitempty"`
+	// Result is the result of GenerateWithTools
+	Result *domain.GenerationResult `json:"result,omitempty"`
+	// Deltas are the StreamWithTools callback deltas in order
+	Deltas []*domain.GenerationResult `json:"deltas,omitempty"`
+	// Structured is the result of GenerateStructured
+	Structured *domain.StructuredResult `json:"structured,omitempty"`
+	// Intent is the result of RecognizeIntent
+	Intent *domain.IntentResult `json:"intent,omitempty"`
+	// Error is the error message when the call failed
+	Error string `json:"error,omitempty"`
+}
+
+// Err returns the recorded error, if any
+func (r Response) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return errors.New(r.Error)
+}
+
+// Cassette is an ordered list of interactions backed by a JSON file.
+// It is safe for concurrent use.
+type Cassette struct {
+	Version      int            `json:"version"`
+	Model        string         `json:"model,omitempty"`
+	Interactions []*Interaction `json:"interactions"`
+
+	path string
+	used map[*Interaction]bool
+	mu   sync.Mutex
+}
+
+// New creates an empty cassette that saves to path
+func New(path string) *Cassette {
+	return &Cassette{
+		Version: Version,
+		path:    path,
+		used:    make(map[*Interaction]bool),
+	}
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	c := New(path)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if c.Version > Version {
+		return nil, fmt.Errorf("cassette %s has unsupported version %d", path, c.Version)
+	}
+	return c, nil
+}
+
+// Open returns the cassette for cfg: a fresh cassette when recording, the
+// loaded file when replaying
+func Open(cfg Config) (*Cassette, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Mode {
+	case ModeRecord:
+		return New(cfg.Path), nil
+	case ModeReplay:
+		return Load(cfg.Path)
+	}
+	return nil, fmt.Errorf("cassettes are disabled")
+}
+
+// Path returns the file the cassette saves to
+func (c *Cassette) Path() string {
+	return c.path
+}
+
+// Len returns the number of recorded interactions
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Interactions)
+}
+
+// Save writes the cassette to its path, creating parent directories
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.saveLocked()
+}
+
+func (c *Cassette) saveLocked() error {
+	if c.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Record appends an interaction for req and saves the cassette
+func (c *Cassette) Record(kind Kind, req Request, resp Response) error {
+	raw, err := json.Marshal(req.normalize(false))
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	interaction := &Interaction{
+		Kind:        kind,
+		Hash:        req.Hash(kind, MatchStrict),
+		LenientHash: req.Hash(kind, MatchLenient),
+		Request:     raw,
+		Response:    resp,
+		RecordedAt:  time.Now(),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, interaction)
+	return c.saveLocked()
+}
+
+// Find returns the recorded interaction that answers req. Unused
+// interactions are served in recording order; once every match has been
+// used, the last match is served again.
+func (c *Cassette) Find(kind Kind, req Request, match MatchMode) (*Interaction, error) {
+	if match == "" {
+		match = MatchStrict
+	}
+	hash := req.Hash(kind, match)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matches := func(i *Interaction) bool {
+		if i.Kind != kind {
+			return false
+		}
+		if match == MatchLenient {
+			return i.LenientHash == hash
+		}
+		return i.Hash == hash
+	}
+
+	if found := c.take(matches); found != nil {
+		return found, nil
+	}
+	if match == MatchLenient {
+		if found := c.take(func(i *Interaction) bool { return i.Kind == kind }); found != nil {
+			return found, nil
+		}
+	}
+	return nil, fmt.Errorf("%w (kind=%s hash=%s)", ErrNoMatch, kind, hash[:12])
+}
+
+// take marks and returns the first unused interaction satisfying pred, or
+// the last used one when all are consumed
+func (c *Cassette) take(pred func(*Interaction) bool) *Interaction {
+	var last *Interaction
+	for _, i := range c.Interactions {
+		if !pred(i) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+// Request is the normalized input of a Generator call
+type Request struct {
+	Prompt   string                    `json:"prompt,omitempty"`
+	Messages []domain.Message          `json:"messages,omitempty"`
+	Tools    []domain.ToolDefinition   `json:"tools,omitempty"`
+	Options  *domain.GenerationOptions `json:"options,omitempty"`
+	Schema   interface{}               `json:"schema,omitempty"`
+}
+
+// normalized is the hashed form of a request
+type normalized struct {
+	Prompt   string                 `json:"prompt,omitempty"`
+	Messages []normalizedMessage    `json:"messages,omitempty"`
+	Tools    []domain.ToolFunction  `json:"tools,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+	Schema   interface{}            `json:"schema,omitempty"`
+}
+
+type normalizedMessage struct {
+	Role      string                `json:"role"`
+	Content   string                `json:"content,omitempty"`
+	ToolCalls []domain.FunctionCall `json:"tool_calls,omitempty"`
+}
+
+// normalize collapses whitespace, drops volatile IDs and sorts tools. In
+// lenient form it also drops system messages, tool schemas, options and
+// letter case.
+func (r Request) normalize(lenient bool) normalized {
+	text := func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		if lenient {
+			s = strings.ToLower(s)
+		}
+		return s
+	}
+
+	n := normalized{Prompt: text(r.Prompt)}
+	for _, m := range r.Messages {
+		if lenient && m.Role == "system" {
+			continue
+		}
+		nm := normalizedMessage{Role: m.Role, Content: text(m.Content)}
+		for _, tc := range m.ToolCalls {
+			nm.ToolCalls = append(nm.ToolCalls, tc.Function)
+		}
+		n.Messages = append(n.Messages, nm)
+	}
+
+	for _, t := range r.Tools {
+		fn := t.Function
+		if lenient {
+			fn = domain.ToolFunction{Name: fn.Name}
+		}
+		n.Tools = append(n.Tools, fn)
+	}
+	sort.Slice(n.Tools, func(i, j int) bool { return n.Tools[i].Name < n.Tools[j].Name })
+
+	if lenient {
+		return n
+	}
+	n.Schema = r.Schema
+	if o := r.Options; o != nil {
+		n.Options = map[string]interface{}{
+			"temperature": o.Temperature,
+			"max_tokens":  o.MaxTokens,
+			"tool_choice": o.ToolChoice,
+		}
+		if o.Think != nil {
+			n.Options["think"] = *o.Think
+		}
+	}
+	return n
+}
+
+// Hash returns the hex SHA-256 of the normalized request for kind
+func (r Request) Hash(kind Kind, match MatchMode) string {
+	data, _ := json.Marshal(struct {
+		Kind    Kind       `json:"kind"`
+		Request normalized `json:"request"`
+	}{kind, r.normalize(match == MatchLenient)})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
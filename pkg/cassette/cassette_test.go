@@ -0,0 +1,147 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLLM struct {
+	domain.Generator
+	calls int
+}
+
+func (f *fakeLLM) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	f.calls++
+	return &domain.GenerationResult{
+		ToolCalls: []domain.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: domain.FunctionCall{Name: "weather", Arguments: map[string]interface{}{"city": "Paris"}},
+		}},
+	}, nil
+}
+
+func (f *fakeLLM) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	f.calls++
+	for _, chunk := range []string{"It is ", "sunny."} {
+		if err := callback(&domain.GenerationResult{Content: chunk}); err != nil {
+			return err
+		}
+	}
+	return callback(&domain.GenerationResult{Finished: true})
+}
+
+func (f *fakeLLM) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	f.calls++
+	return nil, errors.New("rate limited")
+}
+
+var weatherTools = []domain.ToolDefinition{{
+	Type:     "function",
+	Function: domain.ToolFunction{Name: "weather", Description: "Get weather"},
+}}
+
+func conversation(system string) []domain.Message {
+	return []domain.Message{
+		{Role: "system", Content: system},
+		{Role: "user", Content: "What is the  weather in Paris?"},
+	}
+}
+
+func TestRecordAndReplayStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tape.json")
+	ctx := context.Background()
+	llm := &fakeLLM{}
+
+	rec := NewRecorder(llm, New(path))
+	result, err := rec.GenerateWithTools(ctx, conversation("sys"), weatherTools, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "weather", result.ToolCalls[0].Function.Name)
+
+	var streamed string
+	require.NoError(t, rec.StreamWithTools(ctx, conversation("sys"), weatherTools, nil, func(d *domain.GenerationResult) error {
+		streamed += d.Content
+		return nil
+	}))
+	assert.Equal(t, "It is sunny.", streamed)
+
+	_, err = rec.GenerateStructured(ctx, "extract", map[string]interface{}{"type": "object"}, nil)
+	require.Error(t, err)
+	assert.Equal(t, 3, llm.calls)
+
+	tape, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, tape.Len())
+	replay := NewReplayer(tape, MatchStrict)
+
+	// Whitespace differences are normalized away
+	messages := conversation("sys")
+	messages[1].Content = "What is the weather in Paris?"
+	replayed, err := replay.GenerateWithTools(ctx, messages, weatherTools, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"city": "Paris"}, replayed.ToolCalls[0].Function.Arguments)
+
+	var deltas []*domain.GenerationResult
+	require.NoError(t, replay.StreamWithTools(ctx, conversation("sys"), weatherTools, nil, func(d *domain.GenerationResult) error {
+		deltas = append(deltas, d)
+		return nil
+	}))
+	require.Len(t, deltas, 3)
+	assert.True(t, deltas[2].Finished)
+
+	_, err = replay.GenerateStructured(ctx, "extract", map[string]interface{}{"type": "object"}, nil)
+	assert.EqualError(t, err, "rate limited")
+
+	// A different system prompt does not match strictly
+	_, err = replay.GenerateWithTools(ctx, conversation("other"), weatherTools, nil)
+	assert.ErrorIs(t, err, ErrNoMatch)
+}
+
+func TestReplayLenient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tape.json")
+	ctx := context.Background()
+
+	rec := NewRecorder(&fakeLLM{}, New(path))
+	_, err := rec.GenerateWithTools(ctx, conversation("Today is Monday"), weatherTools, &domain.GenerationOptions{Temperature: 0.7})
+	require.NoError(t, err)
+
+	tape, err := Load(path)
+	require.NoError(t, err)
+	replay := NewReplayer(tape, MatchLenient)
+
+	// System prompt and options are ignored
+	result, err := replay.GenerateWithTools(ctx, conversation("Today is Tuesday"), weatherTools, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "weather", result.ToolCalls[0].Function.Name)
+
+	// Unmatched requests fall back to the next interaction of the same kind,
+	// and a consumed interaction is served again
+	result, err = replay.GenerateWithTools(ctx, []domain.Message{{Role: "user", Content: "hi"}}, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, result.ToolCalls, 1)
+
+	_, err = replay.Generate(ctx, "hello", nil)
+	assert.ErrorIs(t, err, ErrNoMatch)
+}
+
+func TestWrapAndValidate(t *testing.T) {
+	llm := &fakeLLM{}
+	gen, err := Wrap(llm, Config{})
+	require.NoError(t, err)
+	assert.Same(t, llm, gen)
+
+	_, err = Wrap(llm, Config{Mode: "rewind", Path: "x"})
+	assert.Error(t, err)
+	_, err = Wrap(nil, Config{Mode: ModeReplay, Path: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+
+	gen, err = Wrap(llm, Config{Mode: ModeRecord, Path: filepath.Join(t.TempDir(), "new.json")})
+	require.NoError(t, err)
+	assert.IsType(t, &Recorder{}, gen)
+}
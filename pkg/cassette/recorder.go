@@ -0,0 +1,148 @@
+package cassette
+
+import (
+	"context"
+	"log"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+type modelInfoProvider interface {
+	GetModelName() string
+	GetBaseURL() string
+}
+
+// Recorder is a domain.Generator decorator that forwards every call to the
+// wrapped generator and records the request and response to a cassette
+type Recorder struct {
+	inner    domain.Generator
+	cassette *Cassette
+}
+
+// NewRecorder wraps inner so that its calls are recorded to c
+func NewRecorder(inner domain.Generator, c *Cassette) *Recorder {
+	if info, ok := inner.(modelInfoProvider); ok && c.Model == "" {
+		c.Model = info.GetModelName()
+	}
+	return &Recorder{inner: inner, cassette: c}
+}
+
+// Cassette returns the cassette being recorded
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// GetModelName returns the wrapped generator's model name, if it exposes one
+func (r *Recorder) GetModelName() string {
+	if info, ok := r.inner.(modelInfoProvider); ok {
+		return info.GetModelName()
+	}
+	return r.cassette.Model
+}
+
+// GetBaseURL returns the wrapped generator's base URL, if it exposes one
+func (r *Recorder) GetBaseURL() string {
+	if info, ok := r.inner.(modelInfoProvider); ok {
+		return info.GetBaseURL()
+	}
+	return ""
+}
+
+func (r *Recorder) record(kind Kind, req Request, resp Response, err error) {
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if saveErr := r.cassette.Record(kind, req, resp); saveErr != nil {
+		log.Printf("[Cassette] Warning: failed to record %s interaction: %v", kind, saveErr)
+	}
+}
+
+// Generate records a text generation
+func (r *Recorder) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	text, err := r.inner.Generate(ctx, prompt, opts)
+	r.record(KindGenerate, Request{Prompt: prompt, Options: opts}, Response{Text: text}, err)
+	return text, err
+}
+
+// Stream records a streamed generation chunk by chunk
+func (r *Recorder) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	var resp Response
+	err := r.inner.Stream(ctx, prompt, opts, func(chunk string) {
+		resp.Chunks = append(resp.Chunks, chunk)
+		resp.Text += chunk
+		callback(chunk)
+	})
+	r.record(KindStream, Request{Prompt: prompt, Options: opts}, resp, err)
+	return err
+}
+
+// GenerateWithTools records a tool-calling generation
+func (r *Recorder) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	result, err := r.inner.GenerateWithTools(ctx, messages, tools, opts)
+	r.record(KindGenerateWithTools, Request{Messages: messages, Tools: tools, Options: opts}, Response{Result: result}, err)
+	return result, err
+}
+
+// StreamWithTools records every streamed delta, including tool calls
+func (r *Recorder) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	var resp Response
+	err := r.inner.StreamWithTools(ctx, messages, tools, opts, func(delta *domain.GenerationResult) error {
+		if delta != nil {
+			copied := *delta
+			resp.Deltas = append(resp.Deltas, &copied)
+		}
+		return callback(delta)
+	})
+	r.record(KindStreamWithTools, Request{Messages: messages, Tools: tools, Options: opts}, resp, err)
+	return err
+}
+
+// GenerateStructured records a schema-constrained generation
+func (r *Recorder) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	result, err := r.inner.GenerateStructured(ctx, prompt, schema, opts)
+	r.record(KindGenerateStructured, Request{Prompt: prompt, Schema: schema, Options: opts}, Response{Structured: result}, err)
+	return result, err
+}
+
+// RecognizeIntent records an intent recognition
+func (r *Recorder) RecognizeIntent(ctx context.Context, request string) (*domain.IntentResult, error) {
+	result, err := r.inner.RecognizeIntent(ctx, request)
+	r.record(KindRecognizeIntent, Request{Prompt: request}, Response{Intent: result}, err)
+	return result, err
+}
+
+// recordingProvider keeps the non-Generator methods of an LLMProvider while
+// routing generation through a Recorder
+type recordingProvider struct {
+	domain.LLMProvider
+	rec *Recorder
+}
+
+// NewRecordingProvider wraps an LLMProvider so its generation calls are recorded to c
+func NewRecordingProvider(provider domain.LLMProvider, c *Cassette) domain.LLMProvider {
+	return &recordingProvider{LLMProvider: provider, rec: NewRecorder(provider, c)}
+}
+
+func (p *recordingProvider) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	return p.rec.Generate(ctx, prompt, opts)
+}
+
+func (p *recordingProvider) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	return p.rec.Stream(ctx, prompt, opts, callback)
+}
+
+func (p *recordingProvider) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	return p.rec.GenerateWithTools(ctx, messages, tools, opts)
+}
+
+func (p *recordingProvider) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	return p.rec.StreamWithTools(ctx, messages, tools, opts, callback)
+}
+
+func (p *recordingProvider) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	return p.rec.GenerateStructured(ctx, prompt, schema, opts)
+}
+
+func (p *recordingProvider) RecognizeIntent(ctx context.Context, request string) (*domain.IntentResult, error) {
+	return p.rec.RecognizeIntent(ctx, request)
+}
@@ -0,0 +1,182 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// ProviderCassette is the provider type reported by a Replayer
+const ProviderCassette domain.ProviderType = "cassette"
+
+// Replayer is a domain.LLMProvider that serves responses from a cassette
+// instead of calling a model
+type Replayer struct {
+	cassette *Cassette
+	match    MatchMode
+}
+
+// NewReplayer creates a replay provider over c. An empty match mode means strict.
+func NewReplayer(c *Cassette, match MatchMode) *Replayer {
+	if match == "" {
+		match = MatchStrict
+	}
+	return &Replayer{cassette: c, match: match}
+}
+
+// Cassette returns the cassette being replayed
+func (r *Replayer) Cassette() *Cassette {
+	return r.cassette
+}
+
+// GetModelName returns the model the cassette was recorded with
+func (r *Replayer) GetModelName() string {
+	return r.cassette.Model
+}
+
+// GetBaseURL returns the cassette path, since replay has no endpoint
+func (r *Replayer) GetBaseURL() string {
+	return "cassette://" + r.cassette.Path()
+}
+
+func (r *Replayer) find(kind Kind, req Request) (Response, error) {
+	interaction, err := r.cassette.Find(kind, req, r.match)
+	if err != nil {
+		return Response{}, err
+	}
+	return interaction.Response, nil
+}
+
+// Generate replays a text generation
+func (r *Replayer) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	resp, err := r.find(KindGenerate, Request{Prompt: prompt, Options: opts})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, resp.Err()
+}
+
+// Stream replays the recorded chunks through callback
+func (r *Replayer) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	resp, err := r.find(KindStream, Request{Prompt: prompt, Options: opts})
+	if err != nil {
+		return err
+	}
+	chunks := resp.Chunks
+	if len(chunks) == 0 && resp.Text != "" {
+		chunks = []string{resp.Text}
+	}
+	for _, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		callback(chunk)
+	}
+	return resp.Err()
+}
+
+// GenerateWithTools replays a tool-calling generation
+func (r *Replayer) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	resp, err := r.find(KindGenerateWithTools, Request{Messages: messages, Tools: tools, Options: opts})
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	if resp.Result == nil {
+		return nil, fmt.Errorf("cassette: recorded %s interaction has no result", KindGenerateWithTools)
+	}
+	result := *resp.Result
+	return &result, nil
+}
+
+// StreamWithTools replays the recorded deltas, including tool calls, through callback
+func (r *Replayer) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	resp, err := r.find(KindStreamWithTools, Request{Messages: messages, Tools: tools, Options: opts})
+	if err != nil {
+		return err
+	}
+	for _, delta := range resp.Deltas {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		copied := *delta
+		if err := callback(&copied); err != nil {
+			return err
+		}
+	}
+	return resp.Err()
+}
+
+// GenerateStructured replays a schema-constrained generation
+func (r *Replayer) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	resp, err := r.find(KindGenerateStructured, Request{Prompt: prompt, Schema: schema, Options: opts})
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	if resp.Structured == nil {
+		return nil, fmt.Errorf("cassette: recorded %s interaction has no result", KindGenerateStructured)
+	}
+	result := *resp.Structured
+	return &result, nil
+}
+
+// RecognizeIntent replays an intent recognition
+func (r *Replayer) RecognizeIntent(ctx context.Context, request string) (*domain.IntentResult, error) {
+	resp, err := r.find(KindRecognizeIntent, Request{Prompt: request})
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	if resp.Intent == nil {
+		return nil, fmt.Errorf("cassette: recorded %s interaction has no result", KindRecognizeIntent)
+	}
+	result := *resp.Intent
+	return &result, nil
+}
+
+// NewSession is not supported during replay
+func (r *Replayer) NewSession(ctx context.Context, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (domain.RealtimeSession, error) {
+	return nil, fmt.Errorf("cassette: realtime sessions cannot be replayed")
+}
+
+// ProviderType returns ProviderCassette
+func (r *Replayer) ProviderType() domain.ProviderType {
+	return ProviderCassette
+}
+
+// Health always succeeds; replay has no backend
+func (r *Replayer) Health(ctx context.Context) error {
+	return nil
+}
+
+// ExtractMetadata is not supported during replay
+func (r *Replayer) ExtractMetadata(ctx context.Context, content string, model string) (*domain.ExtractedMetadata, error) {
+	return nil, fmt.Errorf("cassette: metadata extraction is not recorded")
+}
+
+// Wrap applies cfg to gen: recording wraps it in a Recorder, replay returns
+// a Replayer and ignores gen (which may be nil). A disabled config returns gen.
+func Wrap(gen domain.Generator, cfg Config) (domain.Generator, error) {
+	if !cfg.Enabled() {
+		return gen, nil
+	}
+	c, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Mode == ModeReplay {
+		return NewReplayer(c, cfg.Match), nil
+	}
+	if gen == nil {
+		return nil, fmt.Errorf("cassette: recording requires an LLM")
+	}
+	return NewRecorder(gen, c), nil
+}
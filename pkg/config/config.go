@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -11,8 +12,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/liliang-cn/agent-go/pkg/cassette"
 	"github.com/liliang-cn/agent-go/pkg/mcp"
 	"github.com/liliang-cn/agent-go/pkg/pool"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 	"github.com/spf13/viper"
 )
 
@@ -29,12 +32,17 @@ type Config struct {
 	Memory  MemoryConfig  `mapstructure:"memory"`
 	Cache   CacheConfig   `mapstructure:"cache"`
 	Tooling ToolingConfig `mapstructure:"tooling"`
+	// Telemetry exports agent traces and metrics over OTLP
+	Telemetry telemetry.Config `mapstructure:"telemetry"`
+	Tracing   TracingConfig    `mapstructure:"tracing"`
 }
 
 type LLMConfig struct {
 	Enabled   bool                   `mapstructure:"enabled"`
 	Strategy  pool.SelectionStrategy `mapstructure:"strategy"`
 	Providers []pool.Provider        `mapstructure:"providers"`
+	// Cassette records or replays LLM calls for deterministic, offline tests
+	Cassette cassette.Config `mapstructure:"cassette"`
 }
 
 type RAGConfig struct {
@@ -106,6 +114,42 @@ type MemoryConfig struct {
 	NoiseFilter MemoryNoiseFilterConfig `mapstructure:"noise_filter"`
 	Adaptive    MemoryAdaptiveConfig    `mapstructure:"adaptive"`
 	Hybrid      MemoryHybridConfig      `mapstructure:"hybrid"`
+	Maintenance MemoryMaintenanceConfig `mapstructure:"maintenance"`
+	Access      MemoryAccessConfig      `mapstructure:"access"`
+	Episodes    MemoryEpisodesConfig    `mapstructure:"episodes"`
+	Graph       MemoryGraphConfig       `mapstructure:"graph"`
+	Suggestions MemorySuggestionsConfig `mapstructure:"suggestions"`
+	Git         MemoryGitConfig         `mapstructure:"git"`
+}
+
+// MemoryGitConfig configures git versioning of the file memory store
+type MemoryGitConfig struct {
+	Enabled bool `mapstructure:"enabled"` // commit every memory change to a git repo in memory_path
+}
+
+// MemorySuggestionsConfig configures whether extracted memories are stored
+// silently or proposed for the user to accept or reject
+type MemorySuggestionsConfig struct {
+	Mode                 string        `mapstructure:"mode"`                   // "auto" (store silently), "suggest" or "off"
+	Timeout              time.Duration `mapstructure:"timeout"`                // decide pending suggestions after this long (0 = never)
+	OnTimeout            string        `mapstructure:"on_timeout"`             // "accept" or "reject"
+	AutoAcceptConfidence float64       `mapstructure:"auto_accept_confidence"` // accept at or above this confidence without asking (0 = never)
+	Path                 string        `mapstructure:"path"`                   // pending store (default <memory_path>/suggestions.json)
+}
+
+// MemoryGraphConfig configures the entity graph built from stored facts
+type MemoryGraphConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Backend string `mapstructure:"backend"` // "file" (JSON next to the memories) or "rag" (share the GraphRAG store)
+	Path    string `mapstructure:"path"`    // file backend location (default <memory_path>/graph.json)
+}
+
+// MemoryEpisodesConfig configures episodic memory of past agent runs
+type MemoryEpisodesConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	MaxExamples   int     `mapstructure:"max_examples"`   // similar runs injected per run
+	MinSimilarity float64 `mapstructure:"min_similarity"` // goal similarity needed to recall a run
+	FailureWeight float64 `mapstructure:"failure_weight"` // score factor for failed runs relative to successes
 }
 
 // MemoryScoringConfig configures memory scoring
@@ -147,6 +191,48 @@ type MemoryHybridConfig struct {
 	BM25Weight   float64 `mapstructure:"bm25_weight"`
 }
 
+// MemoryMaintenanceConfig configures the memory garbage collection job
+type MemoryMaintenanceConfig struct {
+	ArchiveThreshold float64 `mapstructure:"archive_threshold"` // archive below this decayed score (0 disables)
+	MinAgeDays       int     `mapstructure:"min_age_days"`      // never archive memories younger than this
+	ScopeQuota       int     `mapstructure:"scope_quota"`       // max current memories per scope (0 = unlimited)
+	PurgeAfterDays   int     `mapstructure:"purge_after_days"`  // delete archived/superseded memories after this (0 = keep)
+	Consolidate      bool    `mapstructure:"consolidate"`
+	CompactIndex     bool    `mapstructure:"compact_index"`
+}
+
+// MemoryAccessConfig configures scope-aware memory access control. Rules
+// are keyed by scope type (global, agent, project, user, session) and take
+// any, owner, admin or none; unset scopes keep the default policy.
+type MemoryAccessConfig struct {
+	Enabled  bool               `mapstructure:"enabled"`
+	AuditLog string             `mapstructure:"audit_log"` // JSONL audit file ("" disables auditing)
+	Users    []MemoryAccessUser `mapstructure:"users"`     // bearer tokens HTTP callers authenticate with
+	Admins   []string           `mapstructure:"admins"`    // user IDs with unrestricted access
+	Read     map[string]string  `mapstructure:"read"`
+	Write    map[string]string  `mapstructure:"write"`
+}
+
+// MemoryAccessUser maps a bearer token to the user ID it authenticates.
+// Callers without a known token are anonymous.
+type MemoryAccessUser struct {
+	ID    string `mapstructure:"id"`
+	Token string `mapstructure:"token"`
+}
+
+// Authenticate returns the user whose token matches, comparing in constant time
+func (c MemoryAccessConfig) Authenticate(token string) (MemoryAccessUser, bool) {
+	if token == "" {
+		return MemoryAccessUser{}, false
+	}
+	for _, u := range c.Users {
+		if u.ID != "" && u.Token != "" && subtle.ConstantTimeCompare([]byte(u.Token), []byte(token)) == 1 {
+			return u, true
+		}
+	}
+	return MemoryAccessUser{}, false
+}
+
 // CacheConfig configures the transient cache subsystem.
 type CacheConfig struct {
 	StoreType         string        `mapstructure:"store_type"` // "memory" or "file"
@@ -162,6 +248,13 @@ type CacheConfig struct {
 	ChunkCacheTTL     time.Duration `mapstructure:"chunk_ttl"`
 }
 
+// TracingConfig controls local persistence of agent traces.
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RetentionDays is how long persisted traces are kept; 0 keeps them forever
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
 // ToolingConfig controls how tool definitions are exposed to the model.
 type ToolingConfig struct {
 	SavingMode        bool            `mapstructure:"saving_mode"`
@@ -350,6 +443,13 @@ func setDefaults() {
 	viper.SetDefault("mcp.max_concurrent_requests", mcpConfig.MaxConcurrentRequests)
 	viper.SetDefault("mcp.health_check_interval", mcpConfig.HealthCheckInterval)
 	viper.SetDefault("mcp.servers", []string{})
+	viper.SetDefault("mcp.sampling.enabled", mcpConfig.Sampling.Enabled)
+	viper.SetDefault("mcp.sampling.max_tokens", mcpConfig.Sampling.MaxTokens)
+	viper.SetDefault("mcp.sampling.max_tokens_per_server", mcpConfig.Sampling.MaxTokensPerServer)
+	viper.SetDefault("mcp.sampling.require_approval", mcpConfig.Sampling.RequireApproval)
+	viper.SetDefault("mcp.elicitation_timeout", mcpConfig.ElicitationTimeout)
+	viper.SetDefault("mcp.watch_servers", mcpConfig.WatchServers)
+	viper.SetDefault("mcp.gateway.addr", mcpConfig.Gateway.Addr)
 
 	viper.SetDefault("skills.enabled", true)
 	viper.SetDefault("skills.paths", []string{})
@@ -375,6 +475,10 @@ func setDefaults() {
 	viper.SetDefault("cache.llm_ttl", "1h")
 	viper.SetDefault("cache.chunk_ttl", "24h")
 
+	// Trace persistence defaults
+	viper.SetDefault("tracing.enabled", true)
+	viper.SetDefault("tracing.retention_days", 7)
+
 	// Memory scoring defaults
 	viper.SetDefault("memory.scoring.enabled", true)
 	viper.SetDefault("memory.scoring.recency_weight", 0.3)
@@ -406,6 +510,39 @@ func setDefaults() {
 	viper.SetDefault("memory.hybrid.vector_weight", 0.7)
 	viper.SetDefault("memory.hybrid.bm25_weight", 0.3)
 
+	// Memory maintenance defaults
+	viper.SetDefault("memory.maintenance.archive_threshold", 0.35)
+	viper.SetDefault("memory.maintenance.min_age_days", 30)
+	viper.SetDefault("memory.maintenance.scope_quota", 0)
+	viper.SetDefault("memory.maintenance.purge_after_days", 0)
+	viper.SetDefault("memory.maintenance.consolidate", true)
+	viper.SetDefault("memory.maintenance.compact_index", true)
+
+	// Memory episode defaults
+	viper.SetDefault("memory.episodes.enabled", true)
+	viper.SetDefault("memory.episodes.max_examples", 2)
+	viper.SetDefault("memory.episodes.min_similarity", 0.2)
+	viper.SetDefault("memory.episodes.failure_weight", 0.6)
+
+	// Memory entity graph defaults
+	viper.SetDefault("memory.graph.enabled", false)
+	viper.SetDefault("memory.graph.backend", "file")
+	viper.SetDefault("memory.graph.path", "")
+
+	// Memory suggestion defaults
+	viper.SetDefault("memory.suggestions.mode", "auto")
+	viper.SetDefault("memory.suggestions.timeout", "0s")
+	viper.SetDefault("memory.suggestions.on_timeout", "reject")
+	viper.SetDefault("memory.suggestions.auto_accept_confidence", 0.0)
+	viper.SetDefault("memory.suggestions.path", "")
+
+	// Memory git versioning defaults
+	viper.SetDefault("memory.git.enabled", false)
+
+	// Memory access control defaults
+	viper.SetDefault("memory.access.enabled", false)
+	viper.SetDefault("memory.access.audit_log", "")
+
 	// GraphRAG defaults
 	viper.SetDefault("rag.graph.enabled", false)
 	viper.SetDefault("rag.graph.entity_types", []string{"person", "organization", "location", "concept", "event", "product"})
@@ -438,11 +575,21 @@ func bindEnvVars() {
 	viper.BindEnv("rag.chunker.chunk_size", "AgentGo_RAG_CHUNKER_CHUNK_SIZE")
 	viper.BindEnv("rag.chunker.overlap", "AgentGo_RAG_CHUNKER_OVERLAP")
 	viper.BindEnv("rag.chunker.method", "AgentGo_RAG_CHUNKER_METHOD")
+	viper.BindEnv("llm.cassette.mode", "AgentGo_LLM_CASSETTE_MODE")
+	viper.BindEnv("llm.cassette.path", "AgentGo_LLM_CASSETTE_PATH")
+	viper.BindEnv("llm.cassette.match", "AgentGo_LLM_CASSETTE_MATCH")
+	viper.BindEnv("telemetry.enabled", "AgentGo_TELEMETRY_ENABLED")
+	viper.BindEnv("telemetry.protocol", "AgentGo_TELEMETRY_PROTOCOL")
+	viper.BindEnv("telemetry.endpoint", "AgentGo_TELEMETRY_ENDPOINT")
+	viper.BindEnv("telemetry.insecure", "AgentGo_TELEMETRY_INSECURE")
+	viper.BindEnv("tracing.enabled", "AgentGo_TRACING_ENABLED")
 	viper.BindEnv("mcp.enabled", "AgentGo_MCP_ENABLED")
 	viper.BindEnv("mcp.log_level", "AgentGo_MCP_LOG_LEVEL")
 	viper.BindEnv("mcp.default_timeout", "AgentGo_MCP_DEFAULT_TIMEOUT")
 	viper.BindEnv("mcp.max_concurrent_requests", "AgentGo_MCP_MAX_CONCURRENT_REQUESTS")
 	viper.BindEnv("mcp.health_check_interval", "AgentGo_MCP_HEALTH_CHECK_INTERVAL")
+	viper.BindEnv("mcp.gateway.url", "AgentGo_MCP_GATEWAY_URL")
+	viper.BindEnv("mcp.gateway.token", "AgentGo_MCP_GATEWAY_TOKEN")
 	viper.BindEnv("skills.enabled", "AgentGo_SKILLS_ENABLED")
 	viper.BindEnv("skills.auto_load", "AgentGo_SKILLS_AUTO_LOAD")
 	viper.BindEnv("skills.allow_command_injection", "AgentGo_SKILLS_ALLOW_COMMAND_INJECTION")
@@ -475,6 +622,22 @@ func (c *Config) DataDir() string {
 	return filepath.Join(c.Home, "data")
 }
 
+// TracesPath returns the path to the persisted trace database
+func (c *Config) TracesPath() string {
+	return filepath.Join(c.DataDir(), "traces.db")
+}
+
+// TraceRetention returns how long persisted traces are kept; zero keeps them
+// forever
+func (c *Config) TraceRetention() time.Duration {
+	return time.Duration(c.Tracing.RetentionDays) * 24 * time.Hour
+}
+
+// HistoryPath returns the path to the execution history database
+func (c *Config) HistoryPath() string {
+	return filepath.Join(c.DataDir(), "history.db")
+}
+
 // SkillsDir returns the path to the skills directory
 func (c *Config) SkillsDir() string {
 	return filepath.Join(c.Home, "skills")
@@ -688,6 +851,9 @@ func (c *Config) ApplyHomeLayout() {
 	}
 	c.Cache.Path = filepath.Join(c.DataDir(), "cache")
 	c.MCP.FilesystemDirs = []string{c.WorkspaceDir()}
+	if c.MCP.OAuthDir == "" {
+		c.MCP.OAuthDir = filepath.Join(c.Home, "mcp-auth")
+	}
 	c.resolveMCPServerPaths()
 	c.expandPaths()
 }
@@ -157,6 +157,63 @@ type Memory struct {
 	RevisionHistory []MemoryRevision `json:"revision_history,omitempty"` // ordered list of changes to this memory
 }
 
+// EffectiveFrom returns when the memory became valid, falling back to its
+// creation time for memories stored before ValidFrom was recorded
+func (m *Memory) EffectiveFrom() time.Time {
+	if !m.ValidFrom.IsZero() {
+		return m.ValidFrom
+	}
+	return m.CreatedAt
+}
+
+// ValidAt reports whether the memory was recorded and still valid at t
+func (m *Memory) ValidAt(t time.Time) bool {
+	if !m.CreatedAt.IsZero() && m.CreatedAt.After(t) {
+		return false
+	}
+	if m.EffectiveFrom().After(t) {
+		return false
+	}
+	return m.ValidTo == nil || t.Before(*m.ValidTo)
+}
+
+// ValidDuring reports whether the memory's validity window overlaps [from, to].
+// A zero bound is open-ended.
+func (m *Memory) ValidDuring(from, to time.Time) bool {
+	if !to.IsZero() && m.EffectiveFrom().After(to) {
+		return false
+	}
+	return from.IsZero() || m.ValidTo == nil || m.ValidTo.After(from)
+}
+
+// MemoryTemporalQuery selects memories by when they were believed to be true.
+// AsOf takes precedence over From/To; with neither set only currently valid
+// memories match.
+type MemoryTemporalQuery struct {
+	Text      string    `json:"text,omitempty"`       // optional substring filter
+	SessionID string    `json:"session_id,omitempty"` // optional bank/session filter
+	AsOf      time.Time `json:"as_of,omitempty"`      // point-in-time query
+	From      time.Time `json:"from,omitempty"`       // range start (inclusive)
+	To        time.Time `json:"to,omitempty"`         // range end (inclusive)
+	TopK      int       `json:"top_k,omitempty"`      // 0 means no limit
+}
+
+// Matches reports whether a memory satisfies the query's session and time
+// constraints. Text matching is left to the store.
+func (q *MemoryTemporalQuery) Matches(m *Memory) bool {
+	if q.SessionID != "" && m.SessionID != q.SessionID {
+		return false
+	}
+	switch {
+	case !q.AsOf.IsZero():
+		return m.ValidAt(q.AsOf)
+	case !q.From.IsZero() || !q.To.IsZero():
+		return m.ValidDuring(q.From, q.To)
+	default:
+		return m.ValidTo == nil
+	}
+}
+
 // MemoryWithScore represents a memory with its similarity score
 type MemoryWithScore struct {
 	*Memory
@@ -192,6 +249,7 @@ type MemoryItem struct {
 	Type       MemoryType          `json:"type"`
 	Content    string              `json:"content"`
 	Importance float64             `json:"importance"`
+	Confidence float64             `json:"confidence,omitempty"` // how sure the extractor is this is true and worth keeping
 	Tags       FlexibleStringArray `json:"tags,omitempty"`
 	Entities   FlexibleStringArray `json:"entities,omitempty"`
 }
@@ -252,6 +310,21 @@ type MemoryStore interface {
 	InitSchema(ctx context.Context) error
 }
 
+// TemporalMemoryStore is a MemoryStore that keeps superseded versions and
+// can answer point-in-time queries. It is optional: memory.Service checks
+// for it and reports temporal queries as unsupported otherwise.
+type TemporalMemoryStore interface {
+	MemoryStore
+
+	// SearchTemporal returns memories that were valid at a point in time or
+	// during a time range, newest validity first
+	SearchTemporal(ctx context.Context, query *MemoryTemporalQuery) ([]*MemoryWithScore, error)
+
+	// History returns every version of a memory along its SupersededBy chain,
+	// oldest first
+	History(ctx context.Context, id string) ([]*Memory, error)
+}
+
 // MemoryService defines the interface for memory management
 type MemoryService interface {
 	// RetrieveAndInject searches relevant memories and formats them for LLM context
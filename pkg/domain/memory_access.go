@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MemoryPrincipal identifies who is reading or writing memories. It travels
+// on the context; callers without one are anonymous and get the least
+// privileged access. Trusted in-process callers (CLI, background jobs)
+// attach SystemMemoryPrincipal.
+type MemoryPrincipal struct {
+	UserID     string   `json:"user_id,omitempty"`
+	AgentID    string   `json:"agent_id,omitempty"`
+	ProjectIDs []string `json:"project_ids,omitempty"`
+	SessionIDs []string `json:"session_ids,omitempty"`
+	Admin      bool     `json:"admin,omitempty"`
+	System     bool     `json:"-"`
+}
+
+// SystemMemoryPrincipal returns the principal of the local process itself,
+// which may read and write every scope. It is never derived from a request.
+func SystemMemoryPrincipal() *MemoryPrincipal {
+	return &MemoryPrincipal{System: true}
+}
+
+// WithSystemMemoryPrincipal marks ctx as coming from the local process
+func WithSystemMemoryPrincipal(ctx context.Context) context.Context {
+	return WithMemoryPrincipal(ctx, SystemMemoryPrincipal())
+}
+
+// Unrestricted reports whether the principal bypasses scope rules
+func (p *MemoryPrincipal) Unrestricted() bool {
+	return p != nil && (p.System || p.Admin)
+}
+
+// String returns a short label for audit logs
+func (p *MemoryPrincipal) String() string {
+	switch {
+	case p == nil:
+		return "anonymous"
+	case p.System:
+		return "system"
+	case p.UserID != "":
+		return "user:" + p.UserID
+	case p.AgentID != "":
+		return "agent:" + p.AgentID
+	default:
+		return "anonymous"
+	}
+}
+
+// Owns reports whether the scope belongs to the principal
+func (p *MemoryPrincipal) Owns(scope MemoryScope) bool {
+	if p == nil || scope.ID == "" {
+		return false
+	}
+	switch scope.Type {
+	case MemoryScopeUser:
+		return scope.ID == p.UserID
+	case MemoryScopeAgent:
+		return scope.ID == p.AgentID
+	case MemoryScopeProject:
+		return containsString(p.ProjectIDs, scope.ID)
+	case MemoryScopeSession:
+		return containsString(p.SessionIDs, scope.ID)
+	}
+	return false
+}
+
+// MemoryAccessMode distinguishes reads from writes
+type MemoryAccessMode string
+
+const (
+	MemoryAccessRead  MemoryAccessMode = "read"
+	MemoryAccessWrite MemoryAccessMode = "write"
+)
+
+// MemoryAccessPolicy decides whether a principal may read or write a scope
+type MemoryAccessPolicy interface {
+	Allow(principal *MemoryPrincipal, scope MemoryScope, mode MemoryAccessMode) bool
+}
+
+// MemoryAuditEvent records one memory read or write
+type MemoryAuditEvent struct {
+	At        time.Time        `json:"at"`
+	Principal string           `json:"principal"`
+	Operation string           `json:"operation"`
+	Mode      MemoryAccessMode `json:"mode"`
+	MemoryIDs []string         `json:"memory_ids,omitempty"`
+	Scope     string           `json:"scope,omitempty"`
+	Allowed   bool             `json:"allowed"`
+	Redacted  int              `json:"redacted,omitempty"`
+}
+
+// MemoryAuditor receives audit events for memory access
+type MemoryAuditor interface {
+	RecordMemoryAccess(ctx context.Context, event *MemoryAuditEvent)
+}
+
+type memoryPrincipalKey struct{}
+
+// WithMemoryPrincipal attaches the principal memory access is checked against
+func WithMemoryPrincipal(ctx context.Context, principal *MemoryPrincipal) context.Context {
+	return context.WithValue(ctx, memoryPrincipalKey{}, principal)
+}
+
+// MemoryPrincipalFromContext returns the principal on ctx, or nil
+func MemoryPrincipalFromContext(ctx context.Context) *MemoryPrincipal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(memoryPrincipalKey{}).(*MemoryPrincipal)
+	return p
+}
+
+// MemoryScopeOf derives a memory's scope from its SessionID, which holds the
+// bank ID: "" or "global"/"default" for global memories, "type:id" for
+// agent/project/user/session banks and a bare session ID otherwise
+func MemoryScopeOf(m *Memory) MemoryScope {
+	return MemoryScopeFromBankID(m.SessionID)
+}
+
+// MemoryScopeFromBankID parses a bank ID into a scope; see MemoryScopeOf
+func MemoryScopeFromBankID(bankID string) MemoryScope {
+	switch bankID {
+	case "", "global", "default":
+		return MemoryScope{Type: MemoryScopeGlobal}
+	}
+	if typ, id, ok := strings.Cut(bankID, ":"); ok {
+		switch t := MemoryScopeType(typ); t {
+		case MemoryScopeAgent, MemoryScopeProject, MemoryScopeUser, MemoryScopeSession:
+			return MemoryScope{Type: t, ID: id}
+		}
+	}
+	return MemoryScope{Type: MemoryScopeSession, ID: bankID}
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
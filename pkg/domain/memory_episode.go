@@ -0,0 +1,40 @@
+package domain
+
+// MemoryTypeEpisode is a distilled past agent run: what was asked, how it
+// was approached and how it turned out. Episodes are recalled by goal
+// similarity as few-shot examples rather than injected as ordinary context.
+const MemoryTypeEpisode MemoryType = "episode"
+
+// EpisodeOutcome is how an agent run ended
+type EpisodeOutcome string
+
+const (
+	EpisodeSuccess EpisodeOutcome = "success"
+	EpisodeFailure EpisodeOutcome = "failure"
+)
+
+// Metadata keys carried by episode memories. Values are strings so they
+// survive stores that stringify metadata.
+const (
+	MetaEpisodeGoal     = "episode_goal"
+	MetaEpisodeOutcome  = "episode_outcome"
+	MetaEpisodeTools    = "episode_tools"    // comma-separated, in call order
+	MetaEpisodeFeedback = "episode_feedback" // "positive", "negative" or ""
+	MetaEpisodeSession  = "history_session_id"
+)
+
+// Episode describes a completed agent run to be distilled into memory
+type Episode struct {
+	SessionID    string         `json:"session_id"` // HistoryStore session the run was recorded under
+	AgentID      string         `json:"agent_id,omitempty"`
+	Goal         string         `json:"goal"`
+	Plan         string         `json:"plan,omitempty"`
+	ToolSequence []string       `json:"tool_sequence,omitempty"`
+	Outcome      EpisodeOutcome `json:"outcome"`
+	Result       string         `json:"result,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	Feedback     string         `json:"feedback,omitempty"` // free-form user feedback
+	Positive     *bool          `json:"positive,omitempty"` // user rating, if any
+	Turns        int            `json:"turns,omitempty"`
+	DurationMs   int64          `json:"duration_ms,omitempty"`
+}
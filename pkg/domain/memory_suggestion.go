@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MemorySuggestionStatus is where a suggested memory is in review
+type MemorySuggestionStatus string
+
+const (
+	MemorySuggestionPending  MemorySuggestionStatus = "pending"
+	MemorySuggestionAccepted MemorySuggestionStatus = "accepted"
+	MemorySuggestionRejected MemorySuggestionStatus = "rejected"
+)
+
+// MemorySuggestion is a candidate memory extracted from a conversation that
+// is only persisted once a user (or the timeout policy) accepts it
+type MemorySuggestion struct {
+	ID         string                 `json:"id"`
+	SessionID  string                 `json:"session_id"`
+	Type       MemoryType             `json:"type"`
+	Content    string                 `json:"content"`
+	Importance float64                `json:"importance"`
+	Confidence float64                `json:"confidence"`
+	Scope      string                 `json:"scope"` // scope the memory will be stored in
+	Status     MemorySuggestionStatus `json:"status"`
+	CreatedAt  time.Time              `json:"created_at"`
+	ExpiresAt  *time.Time             `json:"expires_at,omitempty"` // when the timeout policy decides it
+	DecidedAt  *time.Time             `json:"decided_at,omitempty"`
+	DecidedBy  string                 `json:"decided_by,omitempty"` // user, "policy" or "timeout"
+	MemoryID   string                 `json:"memory_id,omitempty"`  // stored memory once accepted
+}
+
+// MemorySuggestionStore persists suggestions awaiting review
+type MemorySuggestionStore interface {
+	SaveSuggestion(ctx context.Context, s *MemorySuggestion) error
+	GetSuggestion(ctx context.Context, id string) (*MemorySuggestion, error)
+	// ListSuggestions returns suggestions with the given status ("" for
+	// all), oldest first
+	ListSuggestions(ctx context.Context, status MemorySuggestionStatus) ([]*MemorySuggestion, error)
+	// DecideSuggestion saves a decided suggestion only if the stored one is
+	// still pending, so of two concurrent decisions exactly one wins
+	DecideSuggestion(ctx context.Context, s *MemorySuggestion) error
+	DeleteSuggestion(ctx context.Context, id string) error
+}
@@ -278,6 +278,24 @@ type GraphStore interface {
 	InitGraphSchema(ctx context.Context) error
 }
 
+// GraphReader is implemented by graph stores whose nodes and edges can be
+// read back, which graph traversal (e.g. the memory entity graph) needs
+type GraphReader interface {
+	GetNode(ctx context.Context, id string) (*GraphNode, error)
+	// GetEdges returns edges touching nodeID; direction is "out", "in" or "both"
+	GetEdges(ctx context.Context, nodeID string, direction string) ([]GraphEdge, error)
+	// ListNodes returns all nodes of nodeType ("" for every node)
+	ListNodes(ctx context.Context, nodeType string) ([]GraphNode, error)
+}
+
+// GraphDeleter is implemented by graph stores that can remove nodes and
+// edges, which keeping the memory entity graph in step with deletions needs
+type GraphDeleter interface {
+	// DeleteNode removes a node and every edge touching it
+	DeleteNode(ctx context.Context, id string) error
+	DeleteEdge(ctx context.Context, id string) error
+}
+
 // Chat types for domain layer
 
 type ChatSession struct {
@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSuiteYAML = `
+name: smoke
+defaults:
+  k: 2
+cases:
+  - id: capital
+    input: What is the capital of France?
+    expected: Paris
+    required_tools: [rag_query]
+    forbidden_tools: [web_search]
+    relevant_docs: [doc-fr]
+  - id: exact
+    input: Say OK
+    expected: ok
+    match: exact
+  - id: broken
+    input: fail please
+`
+
+type fakeJudge struct {
+	domain.Generator
+	raw string
+}
+
+func (f *fakeJudge) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	return &domain.StructuredResult{Raw: f.raw, Valid: true}, nil
+}
+
+func TestParseSuiteAppliesDefaults(t *testing.T) {
+	suite, err := ParseSuite([]byte(testSuiteYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, TargetAgent, suite.Target)
+	assert.Equal(t, MatchContains, suite.Cases[0].Match)
+	assert.Equal(t, 2, suite.Cases[0].K)
+	assert.Equal(t, MatchExact, suite.Cases[1].Match)
+
+	_, err = ParseSuite([]byte("name: empty\ncases: []\n"))
+	assert.Error(t, err)
+	_, err = ParseSuite([]byte("name: bad\ncases:\n  - id: a\n    input: x\n    match: fuzzy\n"))
+	assert.Error(t, err)
+}
+
+func TestRetrievalAndToolMetrics(t *testing.T) {
+	assert.InDelta(t, 0.5, RecallAtK([]string{"a", "b"}, []string{"x", "a", "b"}, 2), 1e-9)
+	assert.InDelta(t, 0.5, ReciprocalRank([]string{"a"}, []string{"x", "a"}), 1e-9)
+	assert.Equal(t, 0.0, ReciprocalRank([]string{"a"}, []string{"x"}))
+	assert.InDelta(t, 0.5, ToolPrecision([]string{"rag_query"}, nil, []string{"rag_query", "other"}), 1e-9)
+	assert.Equal(t, 0.0, ToolPrecision(nil, []string{"web_search"}, []string{"web_search"}))
+	assert.InDelta(t, 1.0, CosineSimilarity([]float64{1, 0}, []float64{2, 0}), 1e-9)
+	assert.True(t, ExactMatch("OK", " ok "))
+}
+
+func TestRunnerScoresCases(t *testing.T) {
+	suite, err := ParseSuite([]byte(testSuiteYAML))
+	require.NoError(t, err)
+	suite.Cases[1].Rubric = "Must acknowledge"
+
+	target := TargetFunc(func(ctx context.Context, c Case) (*Observation, error) {
+		switch c.ID {
+		case "capital":
+			return &Observation{
+				Answer:        "The capital is Paris.",
+				ToolCalls:     []string{"rag_query"},
+				RetrievedDocs: []string{"doc-other", "doc-fr"},
+			}, nil
+		case "exact":
+			return &Observation{Answer: "OK"}, nil
+		}
+		return nil, errors.New("boom")
+	})
+
+	runner := NewRunner(target, WithJudge(&fakeJudge{raw: `{"score": 0.9, "reason": "acknowledged"}`}))
+	report, err := runner.Run(context.Background(), suite)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+
+	capital := report.Results[0]
+	assert.True(t, capital.Passed, "checks: %+v", capital.Checks)
+	require.NotNil(t, capital.MRR)
+	assert.InDelta(t, 0.5, *capital.MRR, 1e-9)
+	require.NotNil(t, capital.ToolPrecision)
+	assert.InDelta(t, 1.0, *capital.ToolPrecision, 1e-9)
+
+	exact := report.Results[1]
+	assert.True(t, exact.Passed)
+	require.NotNil(t, exact.JudgeScore)
+	assert.InDelta(t, 0.9, *exact.JudgeScore, 1e-9)
+
+	assert.Equal(t, "boom", report.Results[2].Error)
+	assert.Equal(t, 2, report.Summary.Passed)
+	assert.Equal(t, 1, report.Summary.Errored)
+	assert.Greater(t, report.Summary.TotalTokens, 0)
+}
+
+func TestRunnerSkipsChecksWithoutJudgeOrEmbedder(t *testing.T) {
+	suite := &Suite{Name: "skips", Cases: []Case{
+		{ID: "rubric", Input: "Greet", Rubric: "Must be polite"},
+		{ID: "semantic", Input: "Greet", Expected: "hello", Match: MatchSemantic},
+		{ID: "mixed", Input: "Greet", Expected: "hello", Match: MatchContains, Rubric: "Must be polite"},
+		{ID: "wrong", Input: "Greet", Expected: "bye", Match: MatchContains},
+	}}
+	target := TargetFunc(func(ctx context.Context, c Case) (*Observation, error) {
+		return &Observation{Answer: "hello there"}, nil
+	})
+
+	report, err := NewRunner(target).Run(context.Background(), suite)
+	require.NoError(t, err)
+
+	for _, res := range report.Results[:2] {
+		assert.True(t, res.Skipped, res.ID)
+		assert.False(t, res.Passed, res.ID)
+		require.Len(t, res.Checks, 1)
+		assert.True(t, res.Checks[0].Skipped)
+	}
+	mixed := report.Results[2]
+	assert.True(t, mixed.Passed, "the skipped rubric does not fail the case")
+	assert.False(t, mixed.Skipped)
+
+	assert.Equal(t, 2, report.Summary.Skipped)
+	assert.Equal(t, 1, report.Summary.Passed)
+	assert.Equal(t, 1, report.Summary.Failed)
+	assert.InDelta(t, 0.5, report.Summary.PassRate, 1e-9, "skipped cases are left out of the pass rate")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&buf))
+	assert.Contains(t, buf.String(), `skipped="2"`)
+	assert.Contains(t, buf.String(), `<skipped message="checks skipped">`)
+}
+
+func TestReportCompareAndJUnit(t *testing.T) {
+	baseline := &Report{
+		Suite: "smoke",
+		Results: []CaseResult{
+			{ID: "a", Passed: true, Answer: "yes"},
+			{ID: "b", Passed: false},
+			{ID: "gone", Passed: true},
+		},
+	}
+	baseline.Summary = summarize(baseline.Results)
+
+	current := &Report{
+		Suite: "smoke",
+		Results: []CaseResult{
+			{ID: "a", Passed: false, Answer: "no", Checks: []Check{{Name: "answer_contains"}}},
+			{ID: "b", Passed: true},
+			{ID: "new", Error: "timeout"},
+		},
+	}
+	current.Summary = summarize(current.Results)
+
+	cmp := current.Compare(baseline)
+	require.True(t, cmp.HasRegressions())
+	assert.Equal(t, "a", cmp.Regressions[0].ID)
+	assert.Equal(t, "failed checks: answer_contains", cmp.Regressions[0].Detail)
+	assert.Equal(t, "b", cmp.Fixes[0].ID)
+	assert.Equal(t, []string{"new"}, cmp.Added)
+	assert.Equal(t, []string{"gone"}, cmp.Removed)
+
+	var buf bytes.Buffer
+	require.NoError(t, current.WriteJUnit(&buf))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "<?xml"))
+	assert.Contains(t, out, `<testsuite name="smoke" tests="3" failures="1" errors="1"`)
+	assert.Contains(t, out, `<error message="timeout">`)
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"math"
+	"strings"
+)
+
+// normalizeAnswer lowercases and collapses whitespace for answer comparison
+func normalizeAnswer(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// ExactMatch reports whether two answers are equal after normalization
+func ExactMatch(expected, actual string) bool {
+	return normalizeAnswer(expected) == normalizeAnswer(actual)
+}
+
+// ContainsMatch reports whether the actual answer contains the expected one after normalization
+func ContainsMatch(expected, actual string) bool {
+	return strings.Contains(normalizeAnswer(actual), normalizeAnswer(expected))
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 when undefined
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// RecallAtK returns the fraction of relevant IDs found in the first k retrieved IDs
+func RecallAtK(relevant, retrieved []string, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	if k <= 0 || k > len(retrieved) {
+		k = len(retrieved)
+	}
+	want := toSet(relevant)
+	found := make(map[string]bool)
+	for _, id := range retrieved[:k] {
+		if want[id] {
+			found[id] = true
+		}
+	}
+	return float64(len(found)) / float64(len(want))
+}
+
+// ReciprocalRank returns 1/rank of the first relevant ID in retrieved, or 0 if none is found
+func ReciprocalRank(relevant, retrieved []string) float64 {
+	want := toSet(relevant)
+	for i, id := range retrieved {
+		if want[id] {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// ToolPrecision returns the fraction of called tools that were expected (required)
+// and not forbidden. With no required tools, any non-forbidden call counts as correct.
+func ToolPrecision(required, forbidden, called []string) float64 {
+	if len(called) == 0 {
+		if len(required) == 0 {
+			return 1
+		}
+		return 0
+	}
+	req := toSet(required)
+	bad := toSet(forbidden)
+	correct := 0
+	for _, name := range called {
+		if bad[name] {
+			continue
+		}
+		if len(req) == 0 || req[name] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(called))
+}
+
+// missingTools returns required tools that were not called
+func missingTools(required, called []string) []string {
+	have := toSet(called)
+	var missing []string
+	for _, name := range required {
+		if !have[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// forbiddenCalls returns forbidden tools that were called
+func forbiddenCalls(forbidden, called []string) []string {
+	bad := toSet(forbidden)
+	var hits []string
+	seen := make(map[string]bool)
+	for _, name := range called {
+		if bad[name] && !seen[name] {
+			hits = append(hits, name)
+			seen[name] = true
+		}
+	}
+	return hits
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
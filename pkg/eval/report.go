@@ -0,0 +1,339 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report is the outcome of running a suite
+type Report struct {
+	Suite     string       `json:"suite"`
+	Target    string       `json:"target"`
+	StartedAt time.Time    `json:"started_at"`
+	Duration  string       `json:"duration"`
+	Summary   Summary      `json:"summary"`
+	Results   []CaseResult `json:"results"`
+	// Comparison is set when the report was compared against a baseline
+	Comparison *Comparison `json:"comparison,omitempty"`
+}
+
+// CaseResult holds the observations and scores for one case
+type CaseResult struct {
+	ID            string   `json:"id"`
+	Input         string   `json:"input"`
+	Expected      string   `json:"expected,omitempty"`
+	Answer        string   `json:"answer,omitempty"`
+	Passed        bool     `json:"passed"`
+	Skipped       bool     `json:"skipped,omitempty"` // every check was skipped
+	Error         string   `json:"error,omitempty"`
+	Checks        []Check  `json:"checks,omitempty"`
+	ToolCalls     []string `json:"tool_calls,omitempty"`
+	RetrievedDocs []string `json:"retrieved_docs,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+
+	// Metrics; pointers are nil when the metric does not apply to the case
+	Similarity    *float64 `json:"similarity,omitempty"`
+	Recall        *float64 `json:"recall,omitempty"`
+	MRR           *float64 `json:"mrr,omitempty"`
+	ToolPrecision *float64 `json:"tool_precision,omitempty"`
+	JudgeScore    *float64 `json:"judge_score,omitempty"`
+
+	LatencyMs    int64   `json:"latency_ms"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// Check is a single pass/fail assertion within a case. Checks the runner
+// cannot perform, such as rubrics without a judge, are skipped instead.
+type Check struct {
+	Name    string  `json:"name"`
+	Passed  bool    `json:"passed"`
+	Skipped bool    `json:"skipped,omitempty"`
+	Score   float64 `json:"score"`
+	Message string  `json:"message,omitempty"`
+}
+
+// Summary aggregates case results
+type Summary struct {
+	Total             int     `json:"total"`
+	Passed            int     `json:"passed"`
+	Failed            int     `json:"failed"`
+	Errored           int     `json:"errored"`
+	Skipped           int     `json:"skipped"`
+	PassRate          float64 `json:"pass_rate"` // over the cases that were not skipped
+	MeanRecall        float64 `json:"mean_recall"`
+	MeanMRR           float64 `json:"mean_mrr"`
+	MeanToolPrecision float64 `json:"mean_tool_precision"`
+	MeanJudgeScore    float64 `json:"mean_judge_score"`
+	MeanLatencyMs     float64 `json:"mean_latency_ms"`
+	P95LatencyMs      int64   `json:"p95_latency_ms"`
+	TotalTokens       int     `json:"total_tokens"`
+	TotalCost         float64 `json:"total_cost"`
+}
+
+// summarize computes aggregate metrics over case results
+func summarize(results []CaseResult) Summary {
+	s := Summary{Total: len(results)}
+	var recall, mrr, precision, judge meanAccumulator
+	var latencies []int64
+	var latencySum int64
+
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			s.Errored++
+		case r.Skipped:
+			s.Skipped++
+		case r.Passed:
+			s.Passed++
+		default:
+			s.Failed++
+		}
+		recall.add(r.Recall)
+		mrr.add(r.MRR)
+		precision.add(r.ToolPrecision)
+		judge.add(r.JudgeScore)
+		latencies = append(latencies, r.LatencyMs)
+		latencySum += r.LatencyMs
+		s.TotalTokens += r.InputTokens + r.OutputTokens
+		s.TotalCost += r.Cost
+	}
+
+	if scored := s.Total - s.Skipped; scored > 0 {
+		s.PassRate = float64(s.Passed) / float64(scored)
+	}
+	if s.Total > 0 {
+		s.MeanLatencyMs = float64(latencySum) / float64(s.Total)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		idx := (len(latencies)*95+99)/100 - 1
+		s.P95LatencyMs = latencies[max(idx, 0)]
+	}
+	s.MeanRecall = recall.mean()
+	s.MeanMRR = mrr.mean()
+	s.MeanToolPrecision = precision.mean()
+	s.MeanJudgeScore = judge.mean()
+	return s
+}
+
+type meanAccumulator struct {
+	sum   float64
+	count int
+}
+
+func (m *meanAccumulator) add(v *float64) {
+	if v != nil {
+		m.sum += *v
+		m.count++
+	}
+}
+
+func (m *meanAccumulator) mean() float64 {
+	if m.count == 0 {
+		return 0
+	}
+	return m.sum / float64(m.count)
+}
+
+// LoadReport reads a JSON report, typically a saved baseline
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
+
+// SaveReport writes the report as indented JSON to path
+func (r *Report) SaveReport(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+	return r.WriteJSON(f)
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML for CI systems
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:     r.Suite,
+		Tests:    r.Summary.Total,
+		Failures: r.Summary.Failed,
+		Errors:   r.Summary.Errored,
+		Skipped:  r.Summary.Skipped,
+	}
+	var totalMs int64
+	for _, res := range r.Results {
+		totalMs += res.LatencyMs
+		tc := junitTestCase{
+			Name:      res.ID,
+			ClassName: r.Suite,
+			Time:      fmt.Sprintf("%.3f", float64(res.LatencyMs)/1000),
+			SystemOut: res.Answer,
+		}
+		switch {
+		case res.Error != "":
+			tc.Error = &junitMessage{Message: res.Error, Body: res.Error}
+		case res.Skipped:
+			var reasons []string
+			for _, check := range res.Checks {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", check.Name, check.Message))
+			}
+			tc.Skipped = &junitMessage{Message: "checks skipped", Body: strings.Join(reasons, "\n")}
+		case !res.Passed:
+			var failed []string
+			for _, check := range res.Checks {
+				if !check.Passed && !check.Skipped {
+					failed = append(failed, fmt.Sprintf("%s: %s", check.Name, check.Message))
+				}
+			}
+			tc.Failure = &junitMessage{Message: "checks failed", Body: strings.Join(failed, "\n")}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", float64(totalMs)/1000)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Comparison describes how a report differs from a baseline
+type Comparison struct {
+	Baseline     string             `json:"baseline"`
+	Regressions  []CaseDiff         `json:"regressions,omitempty"`
+	Fixes        []CaseDiff         `json:"fixes,omitempty"`
+	Added        []string           `json:"added,omitempty"`
+	Removed      []string           `json:"removed,omitempty"`
+	MetricDeltas map[string]float64 `json:"metric_deltas"`
+}
+
+// CaseDiff describes a case whose outcome changed
+type CaseDiff struct {
+	ID             string `json:"id"`
+	BaselineAnswer string `json:"baseline_answer,omitempty"`
+	Answer         string `json:"answer,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// HasRegressions reports whether any previously passing case now fails
+func (c *Comparison) HasRegressions() bool {
+	return c != nil && len(c.Regressions) > 0
+}
+
+// Compare diffs the report against a baseline and stores the result in r.Comparison
+func (r *Report) Compare(baseline *Report) *Comparison {
+	cmp := &Comparison{
+		Baseline: baseline.StartedAt.Format(time.RFC3339),
+		MetricDeltas: map[string]float64{
+			"pass_rate":           r.Summary.PassRate - baseline.Summary.PassRate,
+			"mean_recall":         r.Summary.MeanRecall - baseline.Summary.MeanRecall,
+			"mean_mrr":            r.Summary.MeanMRR - baseline.Summary.MeanMRR,
+			"mean_tool_precision": r.Summary.MeanToolPrecision - baseline.Summary.MeanToolPrecision,
+			"mean_judge_score":    r.Summary.MeanJudgeScore - baseline.Summary.MeanJudgeScore,
+			"mean_latency_ms":     r.Summary.MeanLatencyMs - baseline.Summary.MeanLatencyMs,
+			"total_cost":          r.Summary.TotalCost - baseline.Summary.TotalCost,
+		},
+	}
+
+	previous := make(map[string]CaseResult, len(baseline.Results))
+	for _, res := range baseline.Results {
+		previous[res.ID] = res
+	}
+	current := make(map[string]bool, len(r.Results))
+	for _, res := range r.Results {
+		current[res.ID] = true
+		old, ok := previous[res.ID]
+		if !ok {
+			cmp.Added = append(cmp.Added, res.ID)
+			continue
+		}
+		diff := CaseDiff{ID: res.ID, BaselineAnswer: old.Answer, Answer: res.Answer}
+		switch {
+		case old.Skipped || res.Skipped:
+			// nothing was measured on one side
+		case old.Passed && !res.Passed:
+			diff.Detail = failureDetail(res)
+			cmp.Regressions = append(cmp.Regressions, diff)
+		case !old.Passed && res.Passed:
+			cmp.Fixes = append(cmp.Fixes, diff)
+		}
+	}
+	for _, res := range baseline.Results {
+		if !current[res.ID] {
+			cmp.Removed = append(cmp.Removed, res.ID)
+		}
+	}
+
+	r.Comparison = cmp
+	return cmp
+}
+
+// failureDetail summarizes why a case failed
+func failureDetail(res CaseResult) string {
+	if res.Error != "" {
+		return "error: " + res.Error
+	}
+	var parts []string
+	for _, check := range res.Checks {
+		if !check.Passed && !check.Skipped {
+			parts = append(parts, check.Name)
+		}
+	}
+	return "failed checks: " + strings.Join(parts, ", ")
+}
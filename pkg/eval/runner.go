@@ -0,0 +1,290 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/prompt"
+	"github.com/liliang-cn/agent-go/pkg/usage"
+)
+
+// Runner executes a suite against a target and scores the results
+type Runner struct {
+	target        Target
+	embedder      domain.Embedder  // Optional: required for semantic answer matching
+	judge         domain.Generator // Optional: required for rubric grading
+	promptManager *prompt.Manager
+	tokenCounter  *usage.TokenCounter
+	progress      func(CaseResult)
+}
+
+// RunnerOption configures a Runner
+type RunnerOption func(*Runner)
+
+// WithEmbedder sets the embedder used for semantic answer matching
+func WithEmbedder(embedder domain.Embedder) RunnerOption {
+	return func(r *Runner) { r.embedder = embedder }
+}
+
+// WithJudge sets the LLM used to grade rubrics
+func WithJudge(judge domain.Generator) RunnerOption {
+	return func(r *Runner) { r.judge = judge }
+}
+
+// WithPromptManager sets a custom prompt manager for the judge prompt
+func WithPromptManager(m *prompt.Manager) RunnerOption {
+	return func(r *Runner) { r.promptManager = m }
+}
+
+// WithProgress registers a callback invoked after each case completes
+func WithProgress(fn func(CaseResult)) RunnerOption {
+	return func(r *Runner) { r.progress = fn }
+}
+
+// NewRunner creates a runner for the given target
+func NewRunner(target Target, opts ...RunnerOption) *Runner {
+	r := &Runner{
+		target:        target,
+		promptManager: prompt.NewManager(),
+		tokenCounter:  usage.NewTokenCounter(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run executes every case in the suite sequentially so latencies are comparable
+func (r *Runner) Run(ctx context.Context, suite *Suite) (*Report, error) {
+	if suite == nil {
+		return nil, fmt.Errorf("suite is nil")
+	}
+	if r.target == nil {
+		return nil, fmt.Errorf("target is nil")
+	}
+
+	report := &Report{
+		Suite:     suite.Name,
+		Target:    suite.Target,
+		StartedAt: time.Now(),
+	}
+
+	for _, c := range suite.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := r.runCase(ctx, suite, c)
+		report.Results = append(report.Results, result)
+		if r.progress != nil {
+			r.progress(result)
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt).String()
+	report.Summary = summarize(report.Results)
+	return report, nil
+}
+
+// runCase executes and scores a single case
+func (r *Runner) runCase(ctx context.Context, suite *Suite, c Case) CaseResult {
+	result := CaseResult{
+		ID:       c.ID,
+		Input:    c.Input,
+		Expected: c.Expected,
+		Tags:     c.Tags,
+	}
+
+	start := time.Now()
+	obs, err := r.target.Run(ctx, c)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Answer = obs.Answer
+	result.ToolCalls = obs.ToolCalls
+	result.RetrievedDocs = obs.RetrievedDocs
+
+	// Token usage and cost
+	model := obs.Model
+	if model == "" {
+		model = suite.Model
+	}
+	result.InputTokens = obs.InputTokens
+	if result.InputTokens == 0 {
+		result.InputTokens = r.tokenCounter.EstimateTokens(c.Input, model)
+	}
+	result.OutputTokens = obs.OutputTokens
+	if result.OutputTokens == 0 {
+		result.OutputTokens = r.tokenCounter.EstimateTokens(obs.Answer, model)
+	}
+	result.Cost = usage.CalculateCost(model, result.InputTokens, result.OutputTokens)
+
+	// Answer match
+	if c.Expected != "" {
+		result.Checks = append(result.Checks, r.checkAnswer(ctx, c, obs.Answer, &result))
+	}
+
+	// Tool-call expectations
+	if len(c.RequiredTools) > 0 || len(c.ForbiddenTools) > 0 {
+		precision := ToolPrecision(c.RequiredTools, c.ForbiddenTools, obs.ToolCalls)
+		result.ToolPrecision = &precision
+
+		missing := missingTools(c.RequiredTools, obs.ToolCalls)
+		result.Checks = append(result.Checks, Check{
+			Name:    "required_tools",
+			Passed:  len(missing) == 0,
+			Score:   1 - float64(len(missing))/float64(max(len(c.RequiredTools), 1)),
+			Message: formatList("missing", missing),
+		})
+		hits := forbiddenCalls(c.ForbiddenTools, obs.ToolCalls)
+		result.Checks = append(result.Checks, Check{
+			Name:    "forbidden_tools",
+			Passed:  len(hits) == 0,
+			Score:   boolScore(len(hits) == 0),
+			Message: formatList("called", hits),
+		})
+	}
+
+	// Retrieval ground truth
+	if len(c.RelevantDocs) > 0 {
+		recall := RecallAtK(c.RelevantDocs, obs.RetrievedDocs, c.K)
+		mrr := ReciprocalRank(c.RelevantDocs, obs.RetrievedDocs)
+		result.Recall = &recall
+		result.MRR = &mrr
+		result.Checks = append(result.Checks, Check{
+			Name:    fmt.Sprintf("recall@%d", c.K),
+			Passed:  recall > 0,
+			Score:   recall,
+			Message: fmt.Sprintf("recall@%d=%.2f mrr=%.2f", c.K, recall, mrr),
+		})
+	}
+
+	// LLM-judge rubric
+	if c.Rubric != "" {
+		result.Checks = append(result.Checks, r.checkRubric(ctx, c, obs.Answer, &result))
+	}
+
+	// Skipped checks neither pass nor fail the case; a case whose checks
+	// were all skipped is itself skipped
+	result.Passed = true
+	ran := len(result.Checks) == 0
+	for _, check := range result.Checks {
+		if check.Skipped {
+			continue
+		}
+		ran = true
+		if !check.Passed {
+			result.Passed = false
+		}
+	}
+	if !ran {
+		result.Passed = false
+		result.Skipped = true
+	}
+	return result
+}
+
+// checkAnswer compares the answer using the case's match mode
+func (r *Runner) checkAnswer(ctx context.Context, c Case, answer string, result *CaseResult) Check {
+	check := Check{Name: "answer_" + c.Match}
+	switch c.Match {
+	case MatchExact:
+		check.Passed = ExactMatch(c.Expected, answer)
+		check.Score = boolScore(check.Passed)
+	case MatchContains:
+		check.Passed = ContainsMatch(c.Expected, answer)
+		check.Score = boolScore(check.Passed)
+	case MatchSemantic:
+		if r.embedder == nil {
+			check.Skipped = true
+			check.Message = "semantic match requires an embedder"
+			return check
+		}
+		vectors, err := r.embedder.EmbedBatch(ctx, []string{c.Expected, answer})
+		if err != nil || len(vectors) != 2 {
+			check.Message = fmt.Sprintf("embedding failed: %v", err)
+			return check
+		}
+		similarity := CosineSimilarity(vectors[0], vectors[1])
+		result.Similarity = &similarity
+		check.Score = similarity
+		check.Passed = similarity >= c.SemanticThreshold
+		check.Message = fmt.Sprintf("similarity=%.3f threshold=%.2f", similarity, c.SemanticThreshold)
+	}
+	if !check.Passed && check.Message == "" {
+		check.Message = fmt.Sprintf("expected %q", c.Expected)
+	}
+	return check
+}
+
+// checkRubric grades the answer against the case rubric with the judge LLM
+func (r *Runner) checkRubric(ctx context.Context, c Case, answer string, result *CaseResult) Check {
+	check := Check{Name: "rubric"}
+	if r.judge == nil {
+		check.Skipped = true
+		check.Message = "rubric grading requires a judge LLM"
+		return check
+	}
+
+	rendered, err := r.promptManager.Render(prompt.EvalJudge, map[string]interface{}{
+		"Input":    c.Input,
+		"Expected": c.Expected,
+		"Answer":   answer,
+		"Rubric":   c.Rubric,
+	})
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"score":  map[string]interface{}{"type": "number", "description": "Score from 0.0 to 1.0"},
+			"reason": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"score", "reason"},
+	}
+	resp, err := r.judge.GenerateStructured(ctx, rendered, schema, &domain.GenerationOptions{
+		Temperature: 0,
+		MaxTokens:   300,
+	})
+	if err != nil {
+		check.Message = fmt.Sprintf("judge failed: %v", err)
+		return check
+	}
+
+	var verdict struct {
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(resp.Raw), &verdict); err != nil {
+		check.Message = fmt.Sprintf("failed to parse judge verdict: %v", err)
+		return check
+	}
+
+	result.JudgeScore = &verdict.Score
+	check.Score = verdict.Score
+	check.Passed = verdict.Score >= c.JudgeThreshold
+	check.Message = verdict.Reason
+	return check
+}
+
+func boolScore(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+func formatList(label string, items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s: %v", label, items)
+}
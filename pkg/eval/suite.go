@@ -0,0 +1,159 @@
+// Package eval provides a regression harness for agents and RAG pipelines.
+//
+// A Suite lists test cases with expected answers, required and forbidden
+// tool calls, retrieval ground truth and LLM-judge rubrics. A Runner executes
+// each case against a Target (an agent.Service or rag.Client adapter), scores
+// the observations and produces a Report that can be written as JSON or JUnit
+// XML and compared against a saved baseline.
+package eval
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Target kinds
+const (
+	TargetAgent = "agent"
+	TargetRAG   = "rag"
+)
+
+// Answer match modes
+const (
+	MatchExact    = "exact"
+	MatchContains = "contains"
+	MatchSemantic = "semantic"
+)
+
+// Suite is a named collection of evaluation cases loaded from YAML
+type Suite struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Target selects what the cases run against: "agent" (default) or "rag"
+	Target string `yaml:"target,omitempty" json:"target,omitempty"`
+	// Model is used for cost estimation when the target cannot report it
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Defaults are applied to every case that leaves the field empty
+	Defaults CaseDefaults `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	Cases    []Case       `yaml:"cases" json:"cases"`
+}
+
+// CaseDefaults holds suite-wide defaults for cases
+type CaseDefaults struct {
+	Match             string  `yaml:"match,omitempty" json:"match,omitempty"`
+	SemanticThreshold float64 `yaml:"semantic_threshold,omitempty" json:"semantic_threshold,omitempty"`
+	K                 int     `yaml:"k,omitempty" json:"k,omitempty"`
+	JudgeThreshold    float64 `yaml:"judge_threshold,omitempty" json:"judge_threshold,omitempty"`
+}
+
+// Case is a single evaluation input with its expectations
+type Case struct {
+	ID    string `yaml:"id" json:"id"`
+	Input string `yaml:"input" json:"input"`
+
+	// Expected answer and how to compare it
+	Expected          string  `yaml:"expected,omitempty" json:"expected,omitempty"`
+	Match             string  `yaml:"match,omitempty" json:"match,omitempty"`
+	SemanticThreshold float64 `yaml:"semantic_threshold,omitempty" json:"semantic_threshold,omitempty"`
+
+	// Tool-call expectations (agent targets)
+	RequiredTools  []string `yaml:"required_tools,omitempty" json:"required_tools,omitempty"`
+	ForbiddenTools []string `yaml:"forbidden_tools,omitempty" json:"forbidden_tools,omitempty"`
+
+	// Retrieval ground truth: document IDs that should be retrieved within the top K
+	RelevantDocs []string `yaml:"relevant_docs,omitempty" json:"relevant_docs,omitempty"`
+	K            int      `yaml:"k,omitempty" json:"k,omitempty"`
+
+	// Rubric is graded by an LLM judge on a 0-1 scale
+	Rubric         string  `yaml:"rubric,omitempty" json:"rubric,omitempty"`
+	JudgeThreshold float64 `yaml:"judge_threshold,omitempty" json:"judge_threshold,omitempty"`
+
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// LoadSuite reads and validates a suite from a YAML file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+	return ParseSuite(data)
+}
+
+// ParseSuite parses and validates a suite from YAML bytes
+func ParseSuite(data []byte) (*Suite, error) {
+	var suite Suite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite: %w", err)
+	}
+	suite.applyDefaults()
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// Validate checks the suite for missing or conflicting fields
+func (s *Suite) Validate() error {
+	if s.Target != TargetAgent && s.Target != TargetRAG {
+		return fmt.Errorf("invalid target %q: must be %q or %q", s.Target, TargetAgent, TargetRAG)
+	}
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("suite %q has no cases", s.Name)
+	}
+	seen := make(map[string]bool, len(s.Cases))
+	for i, c := range s.Cases {
+		if c.ID == "" {
+			return fmt.Errorf("case %d: id is required", i+1)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("case %s: duplicate id", c.ID)
+		}
+		seen[c.ID] = true
+		if strings.TrimSpace(c.Input) == "" {
+			return fmt.Errorf("case %s: input is required", c.ID)
+		}
+		switch c.Match {
+		case MatchExact, MatchContains, MatchSemantic:
+		default:
+			return fmt.Errorf("case %s: invalid match mode %q", c.ID, c.Match)
+		}
+	}
+	return nil
+}
+
+func (s *Suite) applyDefaults() {
+	if s.Target == "" {
+		s.Target = TargetAgent
+	}
+	if s.Defaults.Match == "" {
+		s.Defaults.Match = MatchContains
+	}
+	if s.Defaults.SemanticThreshold == 0 {
+		s.Defaults.SemanticThreshold = 0.8
+	}
+	if s.Defaults.K == 0 {
+		s.Defaults.K = 5
+	}
+	if s.Defaults.JudgeThreshold == 0 {
+		s.Defaults.JudgeThreshold = 0.7
+	}
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Match == "" {
+			c.Match = s.Defaults.Match
+		}
+		if c.SemanticThreshold == 0 {
+			c.SemanticThreshold = s.Defaults.SemanticThreshold
+		}
+		if c.K == 0 {
+			c.K = s.Defaults.K
+		}
+		if c.JudgeThreshold == 0 {
+			c.JudgeThreshold = s.Defaults.JudgeThreshold
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/rag"
+)
+
+// Observation is what a target produced for one case
+type Observation struct {
+	Answer        string
+	ToolCalls     []string
+	RetrievedDocs []string
+	// Model is used to price the call; empty falls back to Suite.Model
+	Model string
+	// InputTokens and OutputTokens are optional; when zero they are estimated
+	InputTokens  int
+	OutputTokens int
+}
+
+// Target executes a single evaluation input
+type Target interface {
+	Run(ctx context.Context, c Case) (*Observation, error)
+}
+
+// TargetFunc adapts a function to the Target interface
+type TargetFunc func(ctx context.Context, c Case) (*Observation, error)
+
+// Run calls f(ctx, c)
+func (f TargetFunc) Run(ctx context.Context, c Case) (*Observation, error) {
+	return f(ctx, c)
+}
+
+// AgentTarget runs cases through agent.Service, one fresh session per case
+type AgentTarget struct {
+	svc  *agent.Service
+	opts []agent.RunOption
+}
+
+// NewAgentTarget creates a target backed by an agent service
+func NewAgentTarget(svc *agent.Service, opts ...agent.RunOption) *AgentTarget {
+	return &AgentTarget{svc: svc, opts: opts}
+}
+
+// Run executes the case input as an agent goal
+func (t *AgentTarget) Run(ctx context.Context, c Case) (*Observation, error) {
+	opts := append([]agent.RunOption{agent.WithSessionID("eval-" + uuid.New().String())}, t.opts...)
+	result, err := t.svc.Run(ctx, c.Input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return &Observation{
+		Answer:        result.Text(),
+		ToolCalls:     result.ToolsUsed,
+		RetrievedDocs: documentIDs(result.Sources),
+		Model:         t.svc.Info().Model,
+	}, nil
+}
+
+// RAGTarget runs cases as RAG queries
+type RAGTarget struct {
+	client *rag.Client
+	opts   *rag.QueryOptions
+	model  string
+}
+
+// NewRAGTarget creates a target backed by a RAG client. opts may be nil.
+func NewRAGTarget(client *rag.Client, opts *rag.QueryOptions, model string) *RAGTarget {
+	if opts == nil {
+		opts = rag.DefaultQueryOptions()
+	}
+	return &RAGTarget{client: client, opts: opts, model: model}
+}
+
+// Run executes the case input as a RAG query, retrieving at least K documents
+func (t *RAGTarget) Run(ctx context.Context, c Case) (*Observation, error) {
+	opts := *t.opts
+	opts.ShowSources = true
+	if c.K > opts.TopK {
+		opts.TopK = c.K
+	}
+	resp, err := t.client.Query(ctx, c.Input, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("empty RAG response")
+	}
+	return &Observation{
+		Answer:        resp.Answer,
+		ToolCalls:     resp.ToolsUsed,
+		RetrievedDocs: documentIDs(resp.Sources),
+		Model:         t.model,
+	}, nil
+}
+
+// documentIDs returns the distinct document IDs of chunks in rank order
+func documentIDs(chunks []domain.Chunk) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, chunk := range chunks {
+		id := chunk.DocumentID
+		if id == "" {
+			id = chunk.ID
+		}
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
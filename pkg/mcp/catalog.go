@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"time"
+)
+
+// CatalogKind names what changed in the MCP catalog
+type CatalogKind string
+
+const (
+	CatalogTools     CatalogKind = "tools"
+	CatalogResources CatalogKind = "resources"
+	CatalogPrompts   CatalogKind = "prompts"
+	CatalogServers   CatalogKind = "servers" // a server was started, stopped or restarted
+)
+
+// Reasons reported in CatalogEvent
+const (
+	CatalogListChanged = "list_changed"
+	CatalogStarted     = "started"
+	CatalogStopped     = "stopped"
+	CatalogRestarted   = "restarted"
+	CatalogUpdated     = "updated" // a subscribed resource changed
+)
+
+// CatalogEvent reports a change to the tools, resources or prompts offered
+// by the running MCP servers
+type CatalogEvent struct {
+	Server string      `json:"server"`
+	Kind   CatalogKind `json:"kind"`
+	Reason string      `json:"reason"`
+	URI    string      `json:"uri,omitempty"` // set for CatalogUpdated
+}
+
+// ChangesTools reports whether the event may have added or removed tools
+func (e CatalogEvent) ChangesTools() bool {
+	return e.Kind == CatalogTools || e.Kind == CatalogServers
+}
+
+// refreshCatalog reloads one part of the catalog after the server announced
+// a change and reports it through the CatalogChangedHandler
+func (c *Client) refreshCatalog(kind CatalogKind) {
+	if !c.IsConnected() {
+		return
+	}
+	timeout := c.config.DefaultTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	before := c.catalogSnapshot(kind)
+	var err error
+	switch kind {
+	case CatalogTools:
+		err = c.loadTools(ctx)
+	case CatalogResources:
+		err = c.loadResources(ctx)
+	case CatalogPrompts:
+		err = c.loadPrompts(ctx)
+	}
+	if err != nil {
+		log.Printf("[WARN] Failed to refresh %s from %s: %v", kind, c.config.Name, err)
+		return
+	}
+	// The SDK server also announces what was registered just before we
+	// connected, which the initial load already has
+	if reflect.DeepEqual(before, c.catalogSnapshot(kind)) {
+		return
+	}
+	if c.options != nil && c.options.CatalogChangedHandler != nil {
+		c.options.CatalogChangedHandler(kind)
+	}
+}
+
+// catalogSnapshot returns the cached lists of one kind. The loaders replace
+// the maps rather than change them, so the result stays as it was.
+func (c *Client) catalogSnapshot(kind CatalogKind) []any {
+	c.catalogMu.RLock()
+	defer c.catalogMu.RUnlock()
+	switch kind {
+	case CatalogTools:
+		return []any{c.tools}
+	case CatalogResources:
+		return []any{c.resources, c.resourceTemplates}
+	case CatalogPrompts:
+		return []any{c.prompts}
+	}
+	return nil
+}
+
+// OnCatalogChange registers fn to be called for every catalog change. The
+// returned function removes it. fn runs on the goroutine that observed the
+// change and must not block.
+func (m *Manager) OnCatalogChange(fn func(CatalogEvent)) func() {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	id := m.nextListener
+	m.nextListener++
+	m.listeners[id] = fn
+	return func() {
+		m.listenersMu.Lock()
+		defer m.listenersMu.Unlock()
+		delete(m.listeners, id)
+	}
+}
+
+func (m *Manager) emit(ev CatalogEvent) {
+	m.listenersMu.RLock()
+	fns := make([]func(CatalogEvent), 0, len(m.listeners))
+	for _, fn := range m.listeners {
+		fns = append(fns, fn)
+	}
+	m.listenersMu.RUnlock()
+	for _, fn := range fns {
+		fn(ev)
+	}
+}
+
+// Reload re-reads the server config files and reconciles the running
+// servers: removed servers are stopped, running servers whose config changed
+// are restarted and new auto-start servers are started.
+func (m *Manager) Reload(ctx context.Context) error {
+	before, after, err := m.config.ReloadServers()
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]ServerConfig, len(before))
+	for _, s := range before {
+		previous[s.Name] = s
+	}
+	current := make(map[string]bool, len(after))
+	for _, s := range after {
+		current[s.Name] = true
+	}
+
+	var errs []error
+	for name := range previous {
+		if current[name] {
+			continue
+		}
+		if _, running := m.GetClient(name); running {
+			if err := m.StopServer(name); err != nil {
+				errs = append(errs, fmt.Errorf("stop %s: %w", name, err))
+			}
+		}
+	}
+
+	for _, cfg := range after {
+		old, existed := previous[cfg.Name]
+		if existed && reflect.DeepEqual(old, cfg) {
+			continue
+		}
+		_, running := m.GetClient(cfg.Name)
+		switch {
+		case running:
+			if err := m.restartServer(ctx, cfg.Name); err != nil {
+				errs = append(errs, fmt.Errorf("restart %s: %w", cfg.Name, err))
+			}
+		case cfg.AutoStart:
+			if _, err := m.StartServer(ctx, cfg.Name); err != nil {
+				errs = append(errs, fmt.Errorf("start %s: %w", cfg.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) restartServer(ctx context.Context, name string) error {
+	m.mutex.Lock()
+	if client, ok := m.clients[name]; ok {
+		_ = client.Close()
+		delete(m.clients, name)
+	}
+	m.mutex.Unlock()
+
+	if _, _, err := m.startServer(ctx, name); err != nil {
+		m.emit(CatalogEvent{Server: name, Kind: CatalogServers, Reason: CatalogStopped})
+		return err
+	}
+	m.emit(CatalogEvent{Server: name, Kind: CatalogServers, Reason: CatalogRestarted})
+	return nil
+}
+
+// WatchConfig polls the server config files every interval and reloads the
+// servers when one of them changes, until ctx is done
+func (m *Manager) WatchConfig(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	last := m.configStamp()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stamp := m.configStamp()
+		if stamp == last {
+			continue
+		}
+		last = stamp
+		if err := m.Reload(ctx); err != nil {
+			log.Printf("[WARN] Failed to reload MCP servers: %v", err)
+		}
+	}
+}
+
+// configStamp summarizes the modification state of the server files
+func (m *Manager) configStamp() string {
+	var stamp string
+	for _, path := range m.config.ServerFiles() {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamp += path + ":missing;"
+			continue
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp
+}
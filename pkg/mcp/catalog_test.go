@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+func echoTool(name string) (*mcp.Tool, mcp.ToolHandlerFor[echoInput, any]) {
+	return &mcp.Tool{Name: name, Description: "echo"},
+		func(ctx context.Context, req *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+		}
+}
+
+// catalogServer serves one echo tool over streamable HTTP
+func catalogServer(t *testing.T) (*mcp.Server, string) {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "catalog-test", Version: "1.0.0"}, nil)
+	tool, handler := echoTool("echo")
+	mcp.AddTool(server, tool, handler)
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(srv.Close)
+	return server, srv.URL
+}
+
+func writeServers(t *testing.T, path, body string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+}
+
+// collectEvents records the manager's catalog events of one kind. The SDK
+// server announces tools added shortly before a client connects, so tests
+// only look at the kind they exercise.
+func collectEvents(m *Manager, kind CatalogKind) (<-chan CatalogEvent, func()) {
+	events := make(chan CatalogEvent, 16)
+	remove := m.OnCatalogChange(func(ev CatalogEvent) {
+		if ev.Kind == kind {
+			events <- ev
+		}
+	})
+	return events, remove
+}
+
+func nextEvent(t *testing.T, events <-chan CatalogEvent) CatalogEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no catalog event")
+		return CatalogEvent{}
+	}
+}
+
+func TestConfigReloadServers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpServers.json")
+	writeServers(t, path, `{"mcpServers": {"a": {"url": "http://localhost:1"}, "b": {"url": "http://localhost:2"}}}`)
+
+	cfg := DefaultConfig()
+	cfg.Servers = []string{path}
+	require.NoError(t, cfg.LoadServersFromJSON())
+	cfg.AddServer(&ServerConfig{Name: "dynamic", Type: ServerTypeStdio})
+
+	writeServers(t, path, `{"mcpServers": {"a": {"url": "http://localhost:3"}}}`)
+	before, after, err := cfg.ReloadServers()
+	require.NoError(t, err)
+	assert.Len(t, before, 3)
+
+	names := map[string]string{}
+	for _, s := range after {
+		names[s.Name] = s.URL
+	}
+	assert.Equal(t, map[string]string{"a": "http://localhost:3", "dynamic": ""}, names)
+
+	writeServers(t, path, `{not json`)
+	_, _, err = cfg.ReloadServers()
+	assert.Error(t, err)
+	assert.Len(t, cfg.GetLoadedServers(), 2, "failed reload keeps the previous servers")
+}
+
+func TestManagerReload(t *testing.T) {
+	_, url := catalogServer(t)
+	path := filepath.Join(t.TempDir(), "mcpServers.json")
+	writeServers(t, path, `{"mcpServers": {}}`)
+
+	cfg := DefaultConfig()
+	cfg.Servers = []string{path}
+	require.NoError(t, cfg.LoadServersFromJSON())
+	m := NewManager(&cfg)
+	defer m.Close()
+	events, remove := collectEvents(m, CatalogServers)
+	defer remove()
+	ctx := context.Background()
+
+	writeServers(t, path, `{"mcpServers": {"remote": {"url": "`+url+`"}}}`)
+	require.NoError(t, m.Reload(ctx))
+	assert.Equal(t, CatalogEvent{Server: "remote", Kind: CatalogServers, Reason: CatalogStarted}, nextEvent(t, events))
+	client, ok := m.GetClient("remote")
+	require.True(t, ok)
+	assert.Contains(t, client.GetTools(), "echo")
+
+	require.NoError(t, m.Reload(ctx))
+	assert.Empty(t, events, "unchanged config restarts nothing")
+
+	writeServers(t, path, `{"mcpServers": {"remote": {"url": "`+url+`", "headers": {"X-Test": "1"}}}}`)
+	require.NoError(t, m.Reload(ctx))
+	assert.Equal(t, CatalogRestarted, nextEvent(t, events).Reason)
+
+	writeServers(t, path, `{"mcpServers": {}}`)
+	require.NoError(t, m.Reload(ctx))
+	assert.Equal(t, CatalogStopped, nextEvent(t, events).Reason)
+	_, ok = m.GetClient("remote")
+	assert.False(t, ok)
+}
+
+func TestToolListChangedRefreshesCatalog(t *testing.T) {
+	server, url := catalogServer(t)
+	cfg := DefaultConfig()
+	cfg.AddServer(&ServerConfig{Name: "remote", Type: ServerTypeHTTP, URL: url})
+	m := NewManager(&cfg)
+	defer m.Close()
+
+	client, err := m.StartServer(context.Background(), "remote")
+	require.NoError(t, err)
+	events, remove := collectEvents(m, CatalogTools)
+	defer remove()
+
+	tool, handler := echoTool("shout")
+	mcp.AddTool(server, tool, handler)
+
+	assert.Equal(t, CatalogEvent{Server: "remote", Kind: CatalogTools, Reason: CatalogListChanged}, nextEvent(t, events))
+	assert.Contains(t, client.GetTools(), "shout")
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -86,6 +87,10 @@ func NewClient(config *ServerConfig, opts *ClientOptions) (*Client, error) {
 		}
 	case ServerTypeInProcess:
 		// No specific validation needed, handled in Connect
+	case ServerTypeOpenAPI:
+		if config.OpenAPI == nil || config.OpenAPI.Spec == "" {
+			return nil, fmt.Errorf("an OpenAPI spec is required for openapi server")
+		}
 	case ServerTypeStdio, "":
 		if len(config.Command) == 0 {
 			return nil, fmt.Errorf("command is required for stdio server")
@@ -94,6 +99,20 @@ func NewClient(config *ServerConfig, opts *ClientOptions) (*Client, error) {
 		return nil, fmt.Errorf("unsupported server type: %s", config.Type)
 	}
 
+	// Every server is checked here, wherever its config came from
+	if config.Sandbox != nil {
+		if config.Type != ServerTypeStdio && config.Type != "" {
+			return nil, fmt.Errorf("server %s: sandbox only applies to stdio servers", config.Name)
+		}
+		sandbox, err := config.Sandbox.prepared(config.WorkingDir)
+		if err != nil {
+			return nil, fmt.Errorf("server %s: %w", config.Name, err)
+		}
+		prepared := *config
+		prepared.Sandbox = sandbox
+		config = &prepared
+	}
+
 	if opts == nil {
 		opts = &ClientOptions{}
 	}
@@ -148,6 +167,12 @@ func (c *Client) Connect(ctx context.Context) error {
 			return fmt.Errorf("failed to create in-process transport for %s: %w", c.config.Name, err)
 		}
 
+	case ServerTypeOpenAPI:
+		transport, err = c.createOpenAPITransport(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create OpenAPI bridge for %s: %w", c.config.Name, err)
+		}
+
 	case ServerTypeStdio, "":
 		// Default to stdio for backward compatibility
 		if len(c.config.Command) == 0 {
@@ -181,6 +206,23 @@ func (c *Client) Connect(ctx context.Context) error {
 			sdkOpts.LoggingMessageHandler = c.options.LoggingMessageHandler
 		}
 	}
+	// Notifications are handled on the connection's read loop, so the
+	// refresh (which sends requests) must run elsewhere
+	sdkOpts.ToolListChangedHandler = func(context.Context, *mcp.ToolListChangedRequest) {
+		go c.refreshCatalog(CatalogTools)
+	}
+	sdkOpts.ResourceListChangedHandler = func(context.Context, *mcp.ResourceListChangedRequest) {
+		go c.refreshCatalog(CatalogResources)
+	}
+	sdkOpts.PromptListChangedHandler = func(context.Context, *mcp.PromptListChangedRequest) {
+		go c.refreshCatalog(CatalogPrompts)
+	}
+	if c.options != nil && c.options.ResourceUpdatedHandler != nil {
+		handler := c.options.ResourceUpdatedHandler
+		sdkOpts.ResourceUpdatedHandler = func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			go handler(req.Params.URI)
+		}
+	}
 
 	client := mcp.NewClient(clientImpl, sdkOpts)
 
@@ -209,8 +251,11 @@ func (c *Client) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to connect to MCP server %s (initialize handshake failed): %w", c.config.Name, err)
 	}
 
+	// Locked because list_changed refreshes may already be running
+	c.mu.Lock()
 	c.session = session
 	c.connected = true
+	c.mu.Unlock()
 
 	// Start health monitoring based on server type
 	c.startHealthMonitoring()
@@ -281,6 +326,20 @@ func (c *Client) createStdioTransport(ctx context.Context) (mcp.Transport, error
 		}
 	}
 
+	// Sandboxed servers get a scrubbed environment and only the access
+	// their config declares
+	if sandbox := c.config.Sandbox; sandbox != nil {
+		cmd, report, err := sandbox.command(ctx, execPath, args, c.config.WorkingDir, c.config.Env)
+		if err != nil {
+			return nil, err
+		}
+		for _, problem := range report.Problems {
+			log.Printf("[WARN] MCP server %s sandbox: %s", c.config.Name, problem)
+		}
+		c.cmd = cmd
+		return &mcp.CommandTransport{Command: cmd}, nil
+	}
+
 	// Create command for the MCP server
 	cmd := exec.CommandContext(ctx, execPath, args...)
 
@@ -310,15 +369,16 @@ func (c *Client) createHTTPTransport() (mcp.Transport, error) {
 	// MCP SDK provides StreamableClientTransport for HTTP connections
 	// This uses Server-Sent Events (SSE) for bidirectional communication
 
-	// Create HTTP client with custom headers if needed
-	httpClient := &http.Client{}
+	// Create HTTP client with custom headers if needed; requests carry the
+	// caller's trace context so server spans join the agent trace
+	httpClient := &http.Client{Transport: c.authTransport(telemetry.HTTPTransport(http.DefaultTransport))}
 
 	// If headers are specified, we'll need to wrap the HTTP client
 	// to add them to each request (this would need custom RoundTripper)
 	if len(c.config.Headers) > 0 {
 		httpClient.Transport = &headerTransport{
 			headers: c.config.Headers,
-			base:    http.DefaultTransport,
+			base:    httpClient.Transport,
 		}
 	}
 
@@ -333,11 +393,11 @@ func (c *Client) createHTTPTransport() (mcp.Transport, error) {
 
 // createSSETransport creates an SSE transport for legacy SSE-based MCP servers.
 func (c *Client) createSSETransport() (mcp.Transport, error) {
-	httpClient := &http.Client{}
+	httpClient := &http.Client{Transport: c.authTransport(telemetry.HTTPTransport(http.DefaultTransport))}
 	if len(c.config.Headers) > 0 {
 		httpClient.Transport = &headerTransport{
 			headers: c.config.Headers,
-			base:    http.DefaultTransport,
+			base:    httpClient.Transport,
 		}
 	}
 
@@ -349,6 +409,14 @@ func (c *Client) createSSETransport() (mcp.Transport, error) {
 	return transport, nil
 }
 
+// authTransport sends the OAuth token stored by `mcp login`, if any
+func (c *Client) authTransport(base http.RoundTripper) http.RoundTripper {
+	if c.options == nil || c.options.OAuthStore == nil {
+		return base
+	}
+	return newOAuthTransport(c.config.Name, c.config.URL, c.options.OAuthStore, base)
+}
+
 // headerTransport adds custom headers to all HTTP requests
 type headerTransport struct {
 	headers map[string]string
@@ -381,7 +449,7 @@ func (c *Client) startHealthMonitoring() {
 	case ServerTypeStdio, "":
 		go c.monitorProcessExit()
 	case ServerTypeHTTP:
-		go c.startPingHeartbeat()
+		go c.startPingHeartbeat(c.stopHealthCheck)
 	}
 }
 
@@ -414,13 +482,13 @@ func (c *Client) monitorProcessExit() {
 
 // startPingHeartbeat starts periodic ping requests for HTTP servers
 // This is the recommended approach for HTTP servers per MCP best practices
-func (c *Client) startPingHeartbeat() {
+func (c *Client) startPingHeartbeat(stop <-chan struct{}) {
 	ticker := time.NewTicker(30 * time.Second) // Ping every 30 seconds
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-c.stopHealthCheck:
+		case <-stop:
 			return
 		case <-ticker.C:
 			if !c.doPing() {
@@ -477,12 +545,14 @@ func (c *Client) loadTools(ctx context.Context) error {
 		return fmt.Errorf("failed to list tools: %w", err)
 	}
 
-	// Clear existing tools and load new ones
-	c.tools = make(map[string]*mcp.Tool)
+	tools := make(map[string]*mcp.Tool, len(toolsResponse.Tools))
 	for _, tool := range toolsResponse.Tools {
-		c.tools[tool.Name] = tool
+		tools[tool.Name] = tool
 	}
 
+	c.catalogMu.Lock()
+	c.tools = tools
+	c.catalogMu.Unlock()
 	return nil
 }
 
@@ -498,10 +568,13 @@ func (c *Client) loadResources(ctx context.Context) error {
 		return fmt.Errorf("failed to list resources: %w", err)
 	}
 
-	c.resources = make(map[string]*mcp.Resource)
+	resources := make(map[string]*mcp.Resource, len(resourcesResponse.Resources))
 	for _, resource := range resourcesResponse.Resources {
-		c.resources[resource.URI] = resource
+		resources[resource.URI] = resource
 	}
+	c.catalogMu.Lock()
+	c.resources = resources
+	c.catalogMu.Unlock()
 
 	// Load resource templates
 	templatesResponse, err := c.session.ListResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{})
@@ -510,10 +583,13 @@ func (c *Client) loadResources(ctx context.Context) error {
 		return nil
 	}
 
-	c.resourceTemplates = make(map[string]*mcp.ResourceTemplate)
+	templates := make(map[string]*mcp.ResourceTemplate, len(templatesResponse.ResourceTemplates))
 	for _, template := range templatesResponse.ResourceTemplates {
-		c.resourceTemplates[template.URITemplate] = template
+		templates[template.URITemplate] = template
 	}
+	c.catalogMu.Lock()
+	c.resourceTemplates = templates
+	c.catalogMu.Unlock()
 
 	return nil
 }
@@ -529,31 +605,43 @@ func (c *Client) loadPrompts(ctx context.Context) error {
 		return fmt.Errorf("failed to list prompts: %w", err)
 	}
 
-	c.prompts = make(map[string]*mcp.Prompt)
+	prompts := make(map[string]*mcp.Prompt, len(promptsResponse.Prompts))
 	for _, prompt := range promptsResponse.Prompts {
-		c.prompts[prompt.Name] = prompt
+		prompts[prompt.Name] = prompt
 	}
 
+	c.catalogMu.Lock()
+	c.prompts = prompts
+	c.catalogMu.Unlock()
 	return nil
 }
 
-// GetTools returns the available tools from this server
+// GetTools returns the available tools from this server. The map is replaced,
+// never modified, when the server's tool list changes.
 func (c *Client) GetTools() map[string]*mcp.Tool {
+	c.catalogMu.RLock()
+	defer c.catalogMu.RUnlock()
 	return c.tools
 }
 
 // GetResources returns the available resources from this server
 func (c *Client) GetResources() map[string]*mcp.Resource {
+	c.catalogMu.RLock()
+	defer c.catalogMu.RUnlock()
 	return c.resources
 }
 
 // GetResourceTemplates returns the available resource templates from this server
 func (c *Client) GetResourceTemplates() map[string]*mcp.ResourceTemplate {
+	c.catalogMu.RLock()
+	defer c.catalogMu.RUnlock()
 	return c.resourceTemplates
 }
 
 // GetPrompts returns the available prompts from this server
 func (c *Client) GetPrompts() map[string]*mcp.Prompt {
+	c.catalogMu.RLock()
+	defer c.catalogMu.RUnlock()
 	return c.prompts
 }
 
@@ -564,7 +652,7 @@ func (c *Client) CallTool(ctx context.Context, toolName string, arguments map[st
 	}
 
 	// Check if tool exists
-	tool, exists := c.tools[toolName]
+	tool, exists := c.GetTools()[toolName]
 	if !exists {
 		return nil, fmt.Errorf("tool '%s' not found on server '%s'", toolName, c.config.Name)
 	}
@@ -711,9 +799,18 @@ func (c *Client) GetServerInfo() *mcp.Implementation {
 
 // Manager manages multiple MCP clients
 type Manager struct {
-	clients map[string]*Client
-	config  *Config
-	mutex   sync.RWMutex
+	clients     map[string]*Client
+	config      *Config
+	sampling    *SamplingHandler
+	elicitation *ElicitationBroker
+	oauth       *OAuthStore
+	policies    *policyEnforcer
+	mutex       sync.RWMutex
+
+	// catalog change listeners, see OnCatalogChange
+	listenersMu  sync.RWMutex
+	listeners    map[int]func(CatalogEvent)
+	nextListener int
 }
 
 // NewManager creates a new MCP manager
@@ -723,21 +820,40 @@ func NewManager(config *Config) *Manager {
 		config = &defaultConfig
 	}
 
+	var oauth *OAuthStore
+	if config.OAuthDir != "" {
+		oauth = NewOAuthStore(config.OAuthDir)
+	}
+
 	return &Manager{
-		clients: make(map[string]*Client),
-		config:  config,
+		clients:     make(map[string]*Client),
+		oauth:       oauth,
+		config:      config,
+		elicitation: NewElicitationBroker(config.ElicitationTimeout),
+		policies:    newPolicyEnforcer(),
+		listeners:   make(map[int]func(CatalogEvent)),
 	}
 }
 
 // StartServer starts an MCP server and creates a client connection
 func (m *Manager) StartServer(ctx context.Context, serverName string) (*Client, error) {
+	client, started, err := m.startServer(ctx, serverName)
+	if started {
+		m.emit(CatalogEvent{Server: serverName, Kind: CatalogServers, Reason: CatalogStarted})
+	}
+	return client, err
+}
+
+// startServer connects the server unless it is already running, reporting
+// whether a new connection was made
+func (m *Manager) startServer(ctx context.Context, serverName string) (*Client, bool, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	// Check if client already exists
 	if client, exists := m.clients[serverName]; exists {
 		if client.IsConnected() {
-			return client, nil
+			return client, false, nil
 		}
 		// Remove disconnected client
 		delete(m.clients, serverName)
@@ -755,21 +871,60 @@ func (m *Manager) StartServer(ctx context.Context, serverName string) (*Client,
 	}
 
 	if serverConfig == nil {
-		return nil, fmt.Errorf("server configuration not found: %s", serverName)
+		return nil, false, fmt.Errorf("server configuration not found: %s", serverName)
 	}
 
 	// Create and connect client
-	client, err := NewClient(serverConfig, nil)
+	client, err := NewClient(serverConfig, m.clientOptions(serverName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create client for %s: %w", serverName, err)
+		return nil, false, fmt.Errorf("failed to create client for %s: %w", serverName, err)
 	}
 
 	if err := client.Connect(ctx); err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", serverName, err)
+		return nil, false, fmt.Errorf("failed to connect to %s: %w", serverName, err)
 	}
 
 	m.clients[serverName] = client
-	return client, nil
+	return client, true, nil
+}
+
+// SetSamplingHandler lets servers started afterwards request completions
+// through h, subject to its per-server policy
+func (m *Manager) SetSamplingHandler(h *SamplingHandler) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sampling = h
+}
+
+// SetElicitor routes input requests from every server, including those
+// already running, to e
+func (m *Manager) SetElicitor(e Elicitor) {
+	m.elicitation.SetElicitor(e)
+}
+
+// clientOptions builds the client options for one server. Callers hold m.mutex.
+func (m *Manager) clientOptions(serverName string) *ClientOptions {
+	opts := &ClientOptions{
+		ElicitationHandler: m.elicitation.HandlerFor(serverName),
+		OAuthStore:         m.oauth,
+		CatalogChangedHandler: func(kind CatalogKind) {
+			m.emit(CatalogEvent{Server: serverName, Kind: kind, Reason: CatalogListChanged})
+		},
+		ResourceUpdatedHandler: func(uri string) {
+			server := serverName
+			if serverName == GatewayServerName {
+				// report the server behind the gateway that owns the resource
+				if _, origin, err := m.resourceClient("", uri); err == nil {
+					server = origin
+				}
+			}
+			m.emit(CatalogEvent{Server: server, Kind: CatalogResources, Reason: CatalogUpdated, URI: uri})
+		},
+	}
+	if m.sampling != nil {
+		opts.CreateMessageHandler = m.sampling.HandlerFor(serverName)
+	}
+	return opts
 }
 
 // GetClient returns an existing client by server name
@@ -796,15 +951,17 @@ func (m *Manager) ListClients() map[string]*Client {
 // StopServer stops an MCP server and closes its client connection
 func (m *Manager) StopServer(serverName string) error {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
 	client, exists := m.clients[serverName]
 	if !exists {
+		m.mutex.Unlock()
 		return fmt.Errorf("server not found: %s", serverName)
 	}
 
 	err := client.Close()
 	delete(m.clients, serverName)
+	m.mutex.Unlock()
+
+	m.emit(CatalogEvent{Server: serverName, Kind: CatalogServers, Reason: CatalogStopped})
 	return err
 }
 
@@ -871,9 +1028,10 @@ func (m *Manager) GetAvailableTools(ctx context.Context) []AgentToolInfo {
 				}
 			}
 
+			name, origin := toolIdentity(serverName, toolName, tool)
 			tools = append(tools, AgentToolInfo{
-				Name:        fmt.Sprintf("mcp_%s_%s", serverName, toolName),
-				ServerName:  serverName,
+				Name:        name,
+				ServerName:  origin,
 				ActualName:  toolName,
 				Description: tool.Description,
 				Parameters:  params,
@@ -932,22 +1090,23 @@ func (m *Manager) FindToolProvider(ctx context.Context, toolName string) (*Agent
 
 		tools := client.GetTools()
 		for actualToolName, tool := range tools {
+			prefixedName, origin := toolIdentity(serverName, actualToolName, tool)
+
 			// Check exact match
 			if actualToolName == toolName {
 				return &AgentToolInfo{
-					Name:        fmt.Sprintf("mcp_%s_%s", serverName, actualToolName),
-					ServerName:  serverName,
+					Name:        prefixedName,
+					ServerName:  origin,
 					ActualName:  actualToolName,
 					Description: tool.Description,
 				}, client, nil
 			}
 
 			// Check prefixed format match (mcp_server_tool)
-			prefixedName := fmt.Sprintf("mcp_%s_%s", serverName, actualToolName)
 			if prefixedName == toolName {
 				return &AgentToolInfo{
 					Name:        prefixedName,
-					ServerName:  serverName,
+					ServerName:  origin,
 					ActualName:  actualToolName,
 					Description: tool.Description,
 				}, client, nil
@@ -983,8 +1142,42 @@ func (m *Manager) CallTool(ctx context.Context, toolName string, arguments map[s
 		}
 	}
 
-	// Call the tool with its actual name (without prefix)
-	return client.CallTool(ctx, toolInfo.ActualName, arguments)
+	// Call the tool with its actual name (without prefix), under the
+	// policies of its server
+	var result *ToolResult
+	tool := client.GetTools()[toolInfo.ActualName]
+	var inputSchema any
+	if tool != nil {
+		inputSchema = tool.InputSchema
+	}
+	defer m.elicitation.track(ctx, client.config.Name)()
+	err = m.policies.call(ctx, client.config, toolInfo.ActualName, inputSchema, arguments, func(ctx context.Context) error {
+		result, err = client.CallTool(ctx, toolInfo.ActualName, arguments)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if text, ok := result.Data.(string); ok {
+		if result.Data, err = m.policies.filterText(client.config, toolInfo.ActualName, text); err != nil {
+			return nil, err
+		}
+	}
+	if result.Error != "" {
+		if result.Error, err = m.policies.filterText(client.config, toolInfo.ActualName, result.Error); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// toolIdentity returns the name agents use for a tool and the server it
+// comes from. Tools proxied by a gateway are already namespaced.
+func toolIdentity(serverName, toolName string, tool *mcp.Tool) (name, origin string) {
+	if origin := originServer(tool.Meta); origin != "" {
+		return toolName, origin
+	}
+	return fmt.Sprintf("mcp_%s_%s", serverName, toolName), serverName
 }
 
 // extractServerNameFromTool extracts server name from tool name
@@ -274,6 +274,11 @@ func ServerConfigToSimple(cfg *ServerConfig) SimpleServerConfig {
 		Headers:    cfg.Headers,
 		WorkingDir: cfg.WorkingDir,
 		Env:        cfg.Env,
+		OAuth:      cfg.OAuth,
+		OpenAPI:    cfg.OpenAPI,
+		Policy:     cfg.Policy,
+		Tools:      cfg.Tools,
+		Sandbox:    cfg.Sandbox,
 	}
 
 	// Extract command (first element if present)
@@ -291,6 +296,8 @@ func SimpleToServerConfig(name string, simple SimpleServerConfig) ServerConfig {
 		serverType = ServerType(simple.Type)
 	} else if simple.URL != "" {
 		serverType = ServerTypeHTTP
+	} else if simple.OpenAPI != nil {
+		serverType = ServerTypeOpenAPI
 	}
 
 	return ServerConfig{
@@ -303,6 +310,11 @@ func SimpleToServerConfig(name string, simple SimpleServerConfig) ServerConfig {
 		Headers:     simple.Headers,
 		WorkingDir:  simple.WorkingDir,
 		Env:         simple.Env,
+		OAuth:       simple.OAuth,
+		OpenAPI:     simple.OpenAPI,
+		Policy:      simple.Policy,
+		Tools:       simple.Tools,
+		Sandbox:     simple.Sandbox,
 		AutoStart:   true,
 	}
 }
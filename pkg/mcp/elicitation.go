@@ -0,0 +1,334 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Elicitation actions, as defined by the MCP specification
+const (
+	ElicitationAccept  = "accept"
+	ElicitationDecline = "decline"
+	ElicitationCancel  = "cancel"
+)
+
+// ElicitationField is one top-level property of a requested schema. The
+// specification restricts elicitation schemas to flat objects of primitives.
+type ElicitationField struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"` // string, number, integer or boolean
+	Format      string   `json:"format,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Default     any      `json:"default,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+}
+
+// Label returns the title of the field, or its name when it has none
+func (f ElicitationField) Label() string {
+	if f.Title != "" {
+		return f.Title
+	}
+	return f.Name
+}
+
+// ElicitationRequest is a server's request for user input, ready to be shown
+// by a front end
+type ElicitationRequest struct {
+	ID      string             `json:"id"`
+	Server  string             `json:"server"`
+	Message string             `json:"message"`
+	URL     string             `json:"url,omitempty"` // set for url-mode requests
+	Fields  []ElicitationField `json:"fields,omitempty"`
+	Schema  map[string]any     `json:"schema,omitempty"`
+}
+
+// ElicitationResponse is the user's answer to an ElicitationRequest
+type ElicitationResponse struct {
+	Action  string         `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// Elicitor collects input from the user. Implementations should return
+// promptly once ctx is done.
+type Elicitor interface {
+	Elicit(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error)
+}
+
+// ElicitorFunc adapts a function to the Elicitor interface
+type ElicitorFunc func(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error)
+
+// Elicit implements Elicitor
+func (f ElicitorFunc) Elicit(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error) {
+	return f(ctx, req)
+}
+
+type elicitationSessionKey struct{}
+
+// WithElicitationSession marks ctx as belonging to a front-end session. Input
+// requests raised by tool calls made under ctx are routed to that session.
+func WithElicitationSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, elicitationSessionKey{}, session)
+}
+
+// ElicitationSession returns the session set by WithElicitationSession
+func ElicitationSession(ctx context.Context) string {
+	session, _ := ctx.Value(elicitationSessionKey{}).(string)
+	return session
+}
+
+// ElicitationBroker routes elicitation requests from every MCP server to the
+// front end currently attached. Without one, requests are cancelled so the
+// server can carry on instead of failing.
+type ElicitationBroker struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	elicitor Elicitor
+	calls    map[string][]string // server -> sessions of its in-flight tool calls
+}
+
+// NewElicitationBroker creates a broker that gives the user timeout to answer
+// each request (0 = wait until the server gives up)
+func NewElicitationBroker(timeout time.Duration) *ElicitationBroker {
+	return &ElicitationBroker{timeout: timeout}
+}
+
+// SetElicitor attaches the front end that answers requests (nil detaches it)
+func (b *ElicitationBroker) SetElicitor(e Elicitor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.elicitor = e
+}
+
+// track records a tool call to server made under ctx until the returned func
+// is called, so requests the server raises meanwhile reach the caller's session
+func (b *ElicitationBroker) track(ctx context.Context, server string) func() {
+	session := ElicitationSession(ctx)
+	if session == "" {
+		return func() {}
+	}
+	b.mu.Lock()
+	if b.calls == nil {
+		b.calls = make(map[string][]string)
+	}
+	b.calls[server] = append(b.calls[server], session)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		calls := b.calls[server]
+		if i := slices.Index(calls, session); i >= 0 {
+			calls = slices.Delete(calls, i, i+1)
+		}
+		if len(calls) == 0 {
+			delete(b.calls, server)
+		} else {
+			b.calls[server] = calls
+		}
+	}
+}
+
+// callerSession returns the session of the in-flight tool calls to server,
+// or "" when there are none or they belong to different sessions
+func (b *ElicitationBroker) callerSession(server string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	calls := b.calls[server]
+	if len(calls) == 0 {
+		return ""
+	}
+	for _, session := range calls[1:] {
+		if session != calls[0] {
+			return ""
+		}
+	}
+	return calls[0]
+}
+
+// HandlerFor returns the SDK ElicitationHandler for one server
+func (b *ElicitationBroker) HandlerFor(server string) func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	return func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		return b.Elicit(ctx, server, req.Params)
+	}
+}
+
+// Elicit asks the attached front end to answer one request from server. A
+// request the user does not answer in time is reported as cancelled.
+func (b *ElicitationBroker) Elicit(ctx context.Context, server string, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	if params == nil {
+		return nil, fmt.Errorf("elicitation request from %s has no parameters", server)
+	}
+
+	b.mu.RLock()
+	elicitor := b.elicitor
+	b.mu.RUnlock()
+	if elicitor == nil {
+		return &mcp.ElicitResult{Action: ElicitationCancel}, nil
+	}
+
+	req := &ElicitationRequest{
+		ID:      uuid.NewString(),
+		Server:  server,
+		Message: params.Message,
+		URL:     params.URL,
+	}
+	if params.RequestedSchema != nil {
+		schema, fields, err := ParseElicitationSchema(params.RequestedSchema)
+		if err != nil {
+			return nil, fmt.Errorf("elicitation request from %s: %w", server, err)
+		}
+		req.Schema, req.Fields = schema, fields
+	}
+
+	// The SDK calls us on the connection's context, so the session comes
+	// from the tool call that is waiting on this server
+	askCtx := ctx
+	if ElicitationSession(ctx) == "" {
+		if session := b.callerSession(server); session != "" {
+			askCtx = WithElicitationSession(askCtx, session)
+		}
+	}
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		askCtx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	resp, err := elicitor.Elicit(askCtx, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return &mcp.ElicitResult{Action: ElicitationCancel}, nil
+		}
+		return nil, err
+	}
+	if resp == nil {
+		return &mcp.ElicitResult{Action: ElicitationCancel}, nil
+	}
+
+	switch resp.Action {
+	case ElicitationAccept:
+		content := resp.Content
+		if content == nil {
+			content = map[string]any{}
+		}
+		return &mcp.ElicitResult{Action: ElicitationAccept, Content: content}, nil
+	case ElicitationDecline, ElicitationCancel:
+		return &mcp.ElicitResult{Action: resp.Action}, nil
+	default:
+		return nil, fmt.Errorf("unknown elicitation action %q", resp.Action)
+	}
+}
+
+// ParseElicitationSchema decodes a requested schema into a generic map and its
+// fields, required fields first
+func ParseElicitationSchema(schema any) (map[string]any, []ElicitationField, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid requested schema: %w", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid requested schema: %w", err)
+	}
+	var parsed struct {
+		Properties map[string]struct {
+			Type        string `json:"type"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			Format      string `json:"format"`
+			Enum        []any  `json:"enum"`
+			Default     any    `json:"default"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, nil, fmt.Errorf("invalid requested schema: %w", err)
+	}
+
+	fields := make([]ElicitationField, 0, len(parsed.Properties))
+	for name, p := range parsed.Properties {
+		field := ElicitationField{
+			Name:        name,
+			Title:       p.Title,
+			Description: p.Description,
+			Type:        p.Type,
+			Format:      p.Format,
+			Default:     p.Default,
+			Required:    slices.Contains(parsed.Required, name),
+		}
+		if field.Type == "" {
+			field.Type = "string"
+		}
+		for _, v := range p.Enum {
+			field.Enum = append(field.Enum, fmt.Sprint(v))
+		}
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Required != fields[j].Required {
+			return fields[i].Required
+		}
+		return fields[i].Name < fields[j].Name
+	})
+	return raw, fields, nil
+}
+
+// ParseElicitationValue converts text typed by the user into a value of the
+// field's type. Empty input yields the default, or nil for optional fields.
+func ParseElicitationValue(field ElicitationField, input string) (any, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		if field.Default != nil {
+			return field.Default, nil
+		}
+		if field.Required {
+			return nil, fmt.Errorf("%s is required", field.Label())
+		}
+		return nil, nil
+	}
+
+	switch field.Type {
+	case "boolean":
+		switch strings.ToLower(input) {
+		case "y", "yes", "true", "1":
+			return true, nil
+		case "n", "no", "false", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s must be yes or no", field.Label())
+	case "integer":
+		v, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number", field.Label())
+		}
+		return v, nil
+	case "number":
+		v, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", field.Label())
+		}
+		return v, nil
+	default:
+		if len(field.Enum) > 0 && !slices.Contains(field.Enum, input) {
+			return nil, fmt.Errorf("%s must be one of %s", field.Label(), strings.Join(field.Enum, ", "))
+		}
+		return input, nil
+	}
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var elicitationSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"repo":    map[string]any{"type": "string", "title": "Repository"},
+		"private": map[string]any{"type": "boolean", "default": true},
+		"kind":    map[string]any{"type": "string", "enum": []any{"bug", "feature"}},
+	},
+	"required": []any{"repo"},
+}
+
+func TestParseElicitationSchema(t *testing.T) {
+	raw, fields, err := ParseElicitationSchema(elicitationSchema)
+	require.NoError(t, err)
+	assert.Equal(t, "object", raw["type"])
+	require.Len(t, fields, 3)
+
+	assert.Equal(t, "repo", fields[0].Name, "required fields come first")
+	assert.True(t, fields[0].Required)
+	assert.Equal(t, "Repository", fields[0].Label())
+	assert.Equal(t, []string{"bug", "feature"}, fields[1].Enum)
+	assert.Equal(t, true, fields[2].Default)
+}
+
+func TestParseElicitationValue(t *testing.T) {
+	_, fields, err := ParseElicitationSchema(elicitationSchema)
+	require.NoError(t, err)
+	repo, kind, private := fields[0], fields[1], fields[2]
+
+	_, err = ParseElicitationValue(repo, "")
+	assert.Error(t, err, "required field")
+
+	v, err := ParseElicitationValue(private, "")
+	require.NoError(t, err)
+	assert.Equal(t, true, v, "default applies to empty input")
+
+	v, err = ParseElicitationValue(private, "no")
+	require.NoError(t, err)
+	assert.Equal(t, false, v)
+
+	_, err = ParseElicitationValue(kind, "docs")
+	assert.Error(t, err)
+
+	v, err = ParseElicitationValue(kind, "")
+	require.NoError(t, err)
+	assert.Nil(t, v, "optional field left empty is omitted")
+
+	v, err = ParseElicitationValue(ElicitationField{Name: "n", Type: "integer"}, "42")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), v)
+}
+
+func TestElicitationBroker(t *testing.T) {
+	params := &mcp.ElicitParams{Message: "Which repository?", RequestedSchema: elicitationSchema}
+
+	t.Run("no front end cancels", func(t *testing.T) {
+		b := NewElicitationBroker(time.Minute)
+		res, err := b.Elicit(context.Background(), "github", params)
+		require.NoError(t, err)
+		assert.Equal(t, ElicitationCancel, res.Action)
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		b := NewElicitationBroker(time.Minute)
+		var got *ElicitationRequest
+		b.SetElicitor(ElicitorFunc(func(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error) {
+			got = req
+			return &ElicitationResponse{Action: ElicitationAccept, Content: map[string]any{"repo": "agent-go"}}, nil
+		}))
+
+		res, err := b.HandlerFor("github")(context.Background(), &mcp.ElicitRequest{Params: params})
+		require.NoError(t, err)
+		assert.Equal(t, ElicitationAccept, res.Action)
+		assert.Equal(t, "agent-go", res.Content["repo"])
+		require.NotNil(t, got)
+		assert.Equal(t, "github", got.Server)
+		assert.NotEmpty(t, got.ID)
+		assert.Len(t, got.Fields, 3)
+	})
+
+	t.Run("timeout cancels", func(t *testing.T) {
+		b := NewElicitationBroker(10 * time.Millisecond)
+		b.SetElicitor(ElicitorFunc(func(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+		res, err := b.Elicit(context.Background(), "github", params)
+		require.NoError(t, err)
+		assert.Equal(t, ElicitationCancel, res.Action)
+	})
+
+	t.Run("caller cancellation is an error", func(t *testing.T) {
+		b := NewElicitationBroker(0)
+		b.SetElicitor(ElicitorFunc(func(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := b.Elicit(ctx, "github", params)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("routes to the calling session", func(t *testing.T) {
+		b := NewElicitationBroker(0)
+		var session string
+		b.SetElicitor(ElicitorFunc(func(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error) {
+			session = ElicitationSession(ctx)
+			return &ElicitationResponse{Action: ElicitationDecline}, nil
+		}))
+
+		done := b.track(WithElicitationSession(context.Background(), "tab-1"), "github")
+		_, err := b.Elicit(context.Background(), "github", params)
+		require.NoError(t, err)
+		assert.Equal(t, "tab-1", session)
+
+		// Concurrent calls from two sessions cannot be told apart
+		other := b.track(WithElicitationSession(context.Background(), "tab-2"), "github")
+		_, err = b.Elicit(context.Background(), "github", params)
+		require.NoError(t, err)
+		assert.Empty(t, session)
+
+		other()
+		done()
+		_, err = b.Elicit(context.Background(), "github", params)
+		require.NoError(t, err)
+		assert.Empty(t, session)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		b := NewElicitationBroker(0)
+		b.SetElicitor(ElicitorFunc(func(ctx context.Context, req *ElicitationRequest) (*ElicitationResponse, error) {
+			return &ElicitationResponse{Action: "maybe"}, nil
+		}))
+		_, err := b.Elicit(context.Background(), "github", params)
+		assert.Error(t, err)
+	})
+}
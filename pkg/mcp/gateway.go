@@ -0,0 +1,515 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GatewayServerName is the server a Service connects to when it uses a
+// gateway instead of starting the servers itself
+const GatewayServerName = "gateway"
+
+// gatewayOriginMeta is the _meta key naming the server a proxied tool,
+// resource or prompt comes from
+const gatewayOriginMeta = "agentgo/server"
+
+// GatewayConfig configures `agentgo mcp gateway` and how other AgentGo
+// processes reach it
+type GatewayConfig struct {
+	// URL of a running gateway's MCP endpoint. When set, Service connects to
+	// the gateway instead of spawning the servers itself.
+	URL string `toml:"url" json:"url" mapstructure:"url"`
+	// Token this process presents to the gateway
+	Token string `toml:"token" json:"token" mapstructure:"token"`
+	// Addr the gateway listens on
+	Addr string `toml:"addr" json:"addr" mapstructure:"addr"`
+	// Clients the gateway accepts. With none configured any local client may
+	// use every server.
+	Clients []GatewayClient `toml:"clients" json:"clients" mapstructure:"clients"`
+}
+
+// GatewayClient is a client of the gateway and what it may use
+type GatewayClient struct {
+	Name  string `toml:"name" json:"name" mapstructure:"name"`
+	Token string `toml:"token" json:"token" mapstructure:"token"`
+	// Servers the client may use (empty = all)
+	Servers []string `toml:"servers" json:"servers" mapstructure:"servers"`
+	// Tools are glob patterns over namespaced tool names such as
+	// "mcp_github_*" (empty = all tools of the allowed servers)
+	Tools []string `toml:"tools" json:"tools" mapstructure:"tools"`
+}
+
+func (c GatewayClient) allowsServer(server string) bool {
+	return len(c.Servers) == 0 || slices.Contains(c.Servers, server)
+}
+
+func (c GatewayClient) allowsTool(server, name string) bool {
+	if !c.allowsServer(server) {
+		return false
+	}
+	if len(c.Tools) == 0 {
+		return true
+	}
+	for _, pattern := range c.Tools {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Gateway owns one Manager and serves the tools, resources and prompts of
+// all its servers to many clients over streamable HTTP. Each upstream server
+// runs once, however many clients use it. Tools are namespaced per server
+// ("mcp_<server>_<tool>"); resources and prompts keep their names, and when
+// two servers offer the same one the server first by name wins. The
+// filesystem guard and the per-client allowlists are applied here.
+type Gateway struct {
+	manager *Manager
+	config  *Config
+	logger  *slog.Logger
+
+	mu        sync.Mutex
+	sessions  map[string]*gatewaySession // by client name
+	unsub     func()
+	stop      context.CancelFunc
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	// subscribers holds the client sessions subscribed to each resource URI.
+	// The upstream subscription lives while any of them does.
+	subsMu      sync.Mutex
+	subscribers map[string]map[*mcp.ServerSession]bool
+}
+
+// gatewaySession is the MCP server one client sees and what was published
+// on it
+type gatewaySession struct {
+	client    GatewayClient
+	server    *mcp.Server
+	tools     map[string]*mcp.Tool
+	resources map[string]*mcp.Resource
+	templates map[string]*mcp.ResourceTemplate
+	prompts   map[string]*mcp.Prompt
+}
+
+// NewGateway loads the configured servers into a new gateway. The gateway
+// ignores config.Gateway.URL: it always runs the servers itself.
+func NewGateway(config *Config, logger *slog.Logger) (*Gateway, error) {
+	if err := prepareServers(config); err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Gateway{
+		manager:     NewManager(config),
+		config:      config,
+		logger:      logger,
+		sessions:    make(map[string]*gatewaySession),
+		ready:       make(chan struct{}),
+		subscribers: make(map[string]map[*mcp.ServerSession]bool),
+	}, nil
+}
+
+// Manager returns the manager that owns the upstream servers
+func (g *Gateway) Manager() *Manager {
+	return g.manager
+}
+
+// Ready is closed once the first Start has connected the configured
+// servers. The handler holds clients back until then, so they never see the
+// catalog filling up.
+func (g *Gateway) Ready() <-chan struct{} {
+	return g.ready
+}
+
+// Start connects to every configured server and keeps the published
+// catalogs in step with them. Servers that fail to start are logged and
+// skipped.
+func (g *Gateway) Start(ctx context.Context) {
+	defer g.readyOnce.Do(func() { close(g.ready) })
+	for _, server := range g.config.GetLoadedServers() {
+		if _, err := g.manager.StartServer(ctx, server.Name); err != nil {
+			g.logger.Warn("MCP server not started", "server", server.Name, "error", err)
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.unsub == nil {
+		g.unsub = g.manager.OnCatalogChange(g.onCatalogChange)
+	}
+	if g.config.WatchServers && g.stop == nil {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		g.stop = cancel
+		go g.manager.WatchConfig(watchCtx, 2*time.Second)
+	}
+}
+
+// Close stops the upstream servers
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	if g.unsub != nil {
+		g.unsub()
+		g.unsub = nil
+	}
+	if g.stop != nil {
+		g.stop()
+		g.stop = nil
+	}
+	g.mu.Unlock()
+	return g.manager.Close()
+}
+
+// Handler serves the gateway over streamable HTTP once it is ready. Clients
+// authenticate with their bearer token when clients are configured.
+func (g *Gateway) Handler() http.Handler {
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		client, _ := g.authenticate(r)
+		return g.session(client).server
+	}, &mcp.StreamableHTTPOptions{Logger: g.logger})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := g.authenticate(r); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="agentgo-mcp-gateway"`)
+			http.Error(w, "unknown gateway client", http.StatusUnauthorized)
+			return
+		}
+		select {
+		case <-g.ready:
+		case <-r.Context().Done():
+			return
+		}
+		mcpHandler.ServeHTTP(w, r)
+	})
+}
+
+// authenticate finds the client presenting the request's bearer token
+func (g *Gateway) authenticate(r *http.Request) (GatewayClient, bool) {
+	clients := g.config.Gateway.Clients
+	if len(clients) == 0 {
+		return GatewayClient{Name: "default"}, true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return GatewayClient{}, false
+	}
+	for _, c := range clients {
+		if c.Token != "" && subtle.ConstantTimeCompare([]byte(c.Token), []byte(token)) == 1 {
+			return c, true
+		}
+	}
+	return GatewayClient{}, false
+}
+
+// session returns the MCP server published to client, creating it on first
+// use. All sessions of one client share it.
+func (g *Gateway) session(client GatewayClient) *gatewaySession {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if s, ok := g.sessions[client.Name]; ok {
+		return s
+	}
+	s := &gatewaySession{client: client}
+	s.server = mcp.NewServer(&mcp.Implementation{Name: "agentgo-gateway", Version: "1.0.0"}, &mcp.ServerOptions{
+		Instructions: "Tools, resources and prompts of the MCP servers managed by the AgentGo gateway.",
+		Logger:       g.logger,
+		SubscribeHandler: func(ctx context.Context, req *mcp.SubscribeRequest) error {
+			return g.subscribe(ctx, client, req.Session, req.Params.URI)
+		},
+		UnsubscribeHandler: func(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+			g.unsubscribe(ctx, req.Session, req.Params.URI)
+			return nil
+		},
+	})
+	g.sync(s)
+	g.sessions[client.Name] = s
+	return s
+}
+
+func (g *Gateway) onCatalogChange(ev CatalogEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range g.sessions {
+		if ev.Reason == CatalogUpdated {
+			if s.client.allowsServer(ev.Server) {
+				_ = s.server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: ev.URI})
+			}
+			continue
+		}
+		g.sync(s)
+	}
+}
+
+// sync publishes the current catalog of the allowed servers to s and
+// withdraws what is gone. The SDK tells connected clients about the change,
+// so only what actually differs is published again.
+func (g *Gateway) sync(s *gatewaySession) {
+	clients := g.manager.ListClients()
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tools := map[string]*mcp.Tool{}
+	resources := map[string]*mcp.Resource{}
+	templates := map[string]*mcp.ResourceTemplate{}
+	prompts := map[string]*mcp.Prompt{}
+	for _, serverName := range names {
+		if !s.client.allowsServer(serverName) {
+			continue
+		}
+		client := clients[serverName]
+		for toolName, tool := range client.GetTools() {
+			name := fmt.Sprintf("mcp_%s_%s", serverName, toolName)
+			if !s.client.allowsTool(serverName, name) {
+				continue
+			}
+			proxied := *tool
+			proxied.Name = name
+			proxied.Meta = originMeta(tool.Meta, serverName)
+			if proxied.InputSchema == nil {
+				proxied.InputSchema = map[string]any{"type": "object"}
+			}
+			tools[name] = &proxied
+			if !reflect.DeepEqual(s.tools[name], &proxied) {
+				s.server.AddTool(&proxied, g.callTool(serverName, toolName, name))
+			}
+		}
+		for _, r := range client.GetResources() {
+			if _, ok := resources[r.URI]; ok {
+				g.logger.Warn("duplicate MCP resource not published", "server", serverName, "uri", r.URI)
+				continue
+			}
+			proxied := *r
+			proxied.Meta = originMeta(r.Meta, serverName)
+			resources[r.URI] = &proxied
+			if !reflect.DeepEqual(s.resources[r.URI], &proxied) {
+				s.server.AddResource(&proxied, g.readResource(serverName))
+			}
+		}
+		for _, t := range client.GetResourceTemplates() {
+			if _, ok := templates[t.URITemplate]; ok {
+				g.logger.Warn("duplicate MCP resource template not published", "server", serverName, "template", t.URITemplate)
+				continue
+			}
+			proxied := *t
+			proxied.Meta = originMeta(t.Meta, serverName)
+			templates[t.URITemplate] = &proxied
+			if !reflect.DeepEqual(s.templates[t.URITemplate], &proxied) {
+				s.server.AddResourceTemplate(&proxied, g.readResource(serverName))
+			}
+		}
+		for _, p := range client.GetPrompts() {
+			if _, ok := prompts[p.Name]; ok {
+				g.logger.Warn("duplicate MCP prompt not published", "server", serverName, "prompt", p.Name)
+				continue
+			}
+			proxied := *p
+			proxied.Meta = originMeta(p.Meta, serverName)
+			prompts[p.Name] = &proxied
+			if !reflect.DeepEqual(s.prompts[p.Name], &proxied) {
+				s.server.AddPrompt(&proxied, g.getPrompt(serverName))
+			}
+		}
+	}
+	s.server.RemoveTools(missing(s.tools, tools)...)
+	s.server.RemoveResources(missing(s.resources, resources)...)
+	s.server.RemoveResourceTemplates(missing(s.templates, templates)...)
+	s.server.RemovePrompts(missing(s.prompts, prompts)...)
+	s.tools, s.resources, s.templates, s.prompts = tools, resources, templates, prompts
+}
+
+// callTool forwards a call to the upstream server, applying the filesystem
+// guard on the way in and out
+func (g *Gateway) callTool(serverName, toolName, name string) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args map[string]interface{}
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments for %s: %w", name, err)
+			}
+		}
+		if err := validateFilesystemToolArgs(name, args, g.config.FilesystemIgnore); err != nil {
+			return toolError(err), nil
+		}
+		args = sanitizeFilesystemToolArgs(name, args)
+
+		client, ok := g.manager.GetClient(serverName)
+		if !ok || !client.IsConnected() {
+			return toolError(fmt.Errorf("MCP server %s is not running", serverName)), nil
+		}
+		var inputSchema any
+		if tool := client.GetTools()[toolName]; tool != nil {
+			inputSchema = tool.InputSchema
+		}
+		var result *mcp.CallToolResult
+		err := g.manager.policies.call(ctx, client.config, toolName, inputSchema, args, func(ctx context.Context) error {
+			var err error
+			result, err = client.session.CallTool(ctx, &mcp.CallToolParams{Name: toolName, Arguments: args})
+			return err
+		})
+		if err != nil {
+			return toolError(err), nil
+		}
+		for i, content := range result.Content {
+			text, ok := content.(*mcp.TextContent)
+			if !ok {
+				continue
+			}
+			filtered := text.Text
+			if isFilesystemTool(name) && !result.IsError {
+				filtered = filterFilesystemToolResult(name, &ToolResult{Success: true, Data: filtered}, g.config.FilesystemIgnore).Data.(string)
+			}
+			if filtered, err = g.manager.policies.filterText(client.config, toolName, filtered); err != nil {
+				return toolError(err), nil
+			}
+			result.Content[i] = &mcp.TextContent{Text: filtered, Annotations: text.Annotations, Meta: text.Meta}
+		}
+		return result, nil
+	}
+}
+
+func (g *Gateway) readResource(serverName string) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		client, ok := g.manager.GetClient(serverName)
+		if !ok || !client.IsConnected() {
+			return nil, fmt.Errorf("MCP server %s is not running", serverName)
+		}
+		return client.session.ReadResource(ctx, req.Params)
+	}
+}
+
+func (g *Gateway) getPrompt(serverName string) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		client, ok := g.manager.GetClient(serverName)
+		if !ok || !client.IsConnected() {
+			return nil, fmt.Errorf("MCP server %s is not running", serverName)
+		}
+		return client.session.GetPrompt(ctx, req.Params)
+	}
+}
+
+// subscribe records that session wants updates of uri and subscribes
+// upstream for the first subscriber. The upstream subscription is released
+// when the last subscriber unsubscribes or disconnects.
+func (g *Gateway) subscribe(ctx context.Context, client GatewayClient, session *mcp.ServerSession, uri string) error {
+	upstream, server, err := g.manager.resourceClient("", uri)
+	if err != nil {
+		return err
+	}
+	if !client.allowsServer(server) {
+		return fmt.Errorf("resource %s is not available to %s", uri, client.Name)
+	}
+
+	g.subsMu.Lock()
+	defer g.subsMu.Unlock()
+	if len(g.subscribers[uri]) == 0 {
+		if err := upstream.SubscribeResource(ctx, uri); err != nil {
+			return err
+		}
+		g.subscribers[uri] = make(map[*mcp.ServerSession]bool)
+	}
+	if !g.subscribedLocked(session) {
+		go func() {
+			_ = session.Wait()
+			g.release(session)
+		}()
+	}
+	g.subscribers[uri][session] = true
+	return nil
+}
+
+// unsubscribe drops the subscription of session to uri
+func (g *Gateway) unsubscribe(ctx context.Context, session *mcp.ServerSession, uri string) {
+	g.subsMu.Lock()
+	defer g.subsMu.Unlock()
+	g.dropLocked(ctx, session, uri)
+}
+
+// release drops every subscription of a session that has closed
+func (g *Gateway) release(session *mcp.ServerSession) {
+	g.subsMu.Lock()
+	defer g.subsMu.Unlock()
+	for uri := range g.subscribers {
+		g.dropLocked(context.Background(), session, uri)
+	}
+}
+
+func (g *Gateway) subscribedLocked(session *mcp.ServerSession) bool {
+	for _, sessions := range g.subscribers {
+		if sessions[session] {
+			return true
+		}
+	}
+	return false
+}
+
+// dropLocked removes session from the subscribers of uri and unsubscribes
+// upstream when it was the last one. Callers hold g.subsMu.
+func (g *Gateway) dropLocked(ctx context.Context, session *mcp.ServerSession, uri string) {
+	sessions := g.subscribers[uri]
+	if !sessions[session] {
+		return
+	}
+	delete(sessions, session)
+	if len(sessions) > 0 {
+		return
+	}
+	delete(g.subscribers, uri)
+	if upstream, _, err := g.manager.resourceClient("", uri); err == nil {
+		if err := upstream.UnsubscribeResource(ctx, uri); err != nil {
+			g.logger.Warn("MCP resource not unsubscribed", "uri", uri, "error", err)
+		}
+	}
+}
+
+func toolError(err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}
+}
+
+// originMeta copies meta and records the server it came from
+func originMeta(meta mcp.Meta, server string) mcp.Meta {
+	out := make(mcp.Meta, len(meta)+1)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[gatewayOriginMeta] = server
+	return out
+}
+
+// originServer is the server a gateway proxied item comes from, or "" for
+// items that were not proxied
+func originServer(meta mcp.Meta) string {
+	server, _ := meta[gatewayOriginMeta].(string)
+	return server
+}
+
+// missing returns the keys of before that are not in after
+func missing[T any](before, after map[string]T) []string {
+	var out []string
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// errGatewayMode is returned for operations a gateway client cannot perform
+var errGatewayMode = errors.New("MCP servers are managed by the gateway; add them to the gateway's configuration")
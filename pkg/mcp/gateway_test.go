@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startGateway runs a gateway over the echo server "a", the resource server
+// "notes" and the built-in filesystem server rooted at a temp dir. It
+// returns the gateway URL, the filesystem root and the notes server.
+func startGateway(t *testing.T, clients []GatewayClient) (string, string, *mcp.Server) {
+	t.Helper()
+	_, echoURL := catalogServer(t)
+	notes, notesURL := resourceServer(t)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "node_modules", "x.js"), []byte("x"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), []byte("hello"), 0o644))
+
+	cfg := DefaultConfig()
+	cfg.WatchServers = false
+	cfg.FilesystemDirs = []string{root}
+	cfg.FilesystemIgnore = []string{"node_modules"} // the default list blocks the temp dir itself
+	cfg.Gateway.Clients = clients
+	gateway, err := NewGateway(&cfg, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	cfg.AddServer(&ServerConfig{Name: "a", Type: ServerTypeHTTP, URL: echoURL})
+	cfg.AddServer(&ServerConfig{Name: "notes", Type: ServerTypeHTTP, URL: notesURL})
+	t.Cleanup(func() { gateway.Close() })
+	go gateway.Start(context.Background())
+
+	srv := httptest.NewServer(gateway.Handler())
+	t.Cleanup(srv.Close)
+	<-gateway.Ready()
+	return srv.URL, root, notes
+}
+
+// gatewayService is an AgentGo process that uses the gateway
+func gatewayService(t *testing.T, url, token string) *Service {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Gateway.URL = url
+	cfg.Gateway.Token = token
+	svc, err := NewService(&cfg, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.Close() })
+	return svc
+}
+
+func toolServers(svc *Service) map[string]string {
+	servers := map[string]string{}
+	for _, tool := range svc.GetAvailableTools(context.Background()) {
+		servers[tool.Name] = tool.ServerName
+	}
+	return servers
+}
+
+func TestGatewayProxiesServers(t *testing.T) {
+	url, _, notes := startGateway(t, nil)
+	svc := gatewayService(t, url, "")
+	ctx := context.Background()
+
+	require.NoError(t, svc.StartServers(ctx, []string{"a"}), "any server name maps to the gateway")
+	assert.Equal(t, 1, svc.GetServerCount())
+	assert.ErrorIs(t, svc.AddDynamicServer(ctx, "x", "cat", nil), errGatewayMode)
+
+	tools := toolServers(svc)
+	assert.Equal(t, "a", tools["mcp_a_echo"], "namespaced tool keeps its origin server")
+	assert.Equal(t, "filesystem", tools["mcp_filesystem_read_file"])
+
+	result, err := svc.CallTool(ctx, "mcp_a_echo", map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Data)
+
+	res, err := svc.ReadResource(ctx, "notes", "notes://todo")
+	require.NoError(t, err)
+	assert.Equal(t, "notes", res.ServerName)
+	assert.Equal(t, "buy milk", res.Text())
+
+	prompt, err := svc.GetPrompt(ctx, "", "review", map[string]string{"topic": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "notes", prompt.ServerName)
+
+	events, remove := collectEvents(svc.GetManager(), CatalogResources)
+	defer remove()
+	require.NoError(t, svc.SubscribeResource(ctx, "notes", "notes://todo"))
+	require.NoError(t, notes.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "notes://todo"}))
+	assert.Equal(t, CatalogEvent{Server: "notes", Kind: CatalogResources, Reason: CatalogUpdated, URI: "notes://todo"}, nextEvent(t, events))
+}
+
+// countingResourceServer serves notes://todo and counts the subscriptions
+// held on it
+func countingResourceServer(t *testing.T, text string) (*atomic.Int32, string) {
+	t.Helper()
+	var subscribed atomic.Int32
+	server := mcp.NewServer(&mcp.Implementation{Name: "counting", Version: "1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { subscribed.Add(1); return nil },
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { subscribed.Add(-1); return nil },
+	})
+	server.AddResource(&mcp.Resource{URI: "notes://todo", Name: "todo"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: text}}}, nil
+		})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(srv.Close)
+	return &subscribed, srv.URL
+}
+
+func TestGatewayResourceDuplicatesAndSubscriptions(t *testing.T) {
+	first, firstURL := countingResourceServer(t, "from a")
+	second, secondURL := countingResourceServer(t, "from b")
+	cfg := DefaultConfig()
+	cfg.WatchServers = false
+	gateway, err := NewGateway(&cfg, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	cfg.AddServer(&ServerConfig{Name: "b", Type: ServerTypeHTTP, URL: secondURL})
+	cfg.AddServer(&ServerConfig{Name: "a", Type: ServerTypeHTTP, URL: firstURL})
+	defer gateway.Close()
+	gateway.Start(context.Background())
+	srv := httptest.NewServer(gateway.Handler())
+	defer srv.Close()
+	ctx := context.Background()
+
+	connect := func() *mcp.ClientSession {
+		client := mcp.NewClient(&mcp.Implementation{Name: "plain", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: srv.URL}, nil)
+		require.NoError(t, err)
+		return session
+	}
+	one, two := connect(), connect()
+	defer one.Close()
+	defer two.Close()
+
+	listed, err := one.ListResources(ctx, nil)
+	require.NoError(t, err)
+	var todo []*mcp.Resource
+	for _, r := range listed.Resources {
+		if r.URI == "notes://todo" {
+			todo = append(todo, r)
+		}
+	}
+	require.Len(t, todo, 1, "the URI both servers offer is published once")
+	assert.Equal(t, "a", originServer(todo[0].Meta), "the server first by name wins")
+	read, err := one.ReadResource(ctx, &mcp.ReadResourceParams{URI: "notes://todo"})
+	require.NoError(t, err)
+	assert.Equal(t, "from a", read.Contents[0].Text)
+
+	require.NoError(t, one.Subscribe(ctx, &mcp.SubscribeParams{URI: "notes://todo"}))
+	require.NoError(t, two.Subscribe(ctx, &mcp.SubscribeParams{URI: "notes://todo"}))
+	assert.Equal(t, int32(1), first.Load(), "one upstream subscription for both clients")
+	assert.Zero(t, second.Load())
+
+	require.NoError(t, two.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: "notes://todo"}))
+	assert.Equal(t, int32(1), first.Load(), "still held for the first client")
+
+	require.NoError(t, one.Close())
+	require.Eventually(t, func() bool { return first.Load() == 0 }, 5*time.Second, 10*time.Millisecond,
+		"the last subscriber disconnecting releases the upstream subscription")
+}
+
+func TestGatewayClientAllowlists(t *testing.T) {
+	url, _, _ := startGateway(t, []GatewayClient{
+		{Name: "full", Token: "t1"},
+		{Name: "reader", Token: "t2", Servers: []string{"notes", "filesystem"}, Tools: []string{"mcp_filesystem_read_*"}},
+	})
+	ctx := context.Background()
+
+	full := gatewayService(t, url, "t1")
+	require.NoError(t, full.StartServers(ctx, nil))
+	assert.Contains(t, toolServers(full), "mcp_a_echo")
+
+	reader := gatewayService(t, url, "t2")
+	require.NoError(t, reader.StartServers(ctx, nil))
+	tools := toolServers(reader)
+	assert.Contains(t, tools, "mcp_filesystem_read_file")
+	assert.NotContains(t, tools, "mcp_filesystem_list_directory")
+	assert.NotContains(t, tools, "mcp_a_echo")
+	for _, r := range reader.ListResources() {
+		assert.Contains(t, []string{"notes", "filesystem"}, r.ServerName)
+	}
+
+	stranger := gatewayService(t, url, "nope")
+	assert.Error(t, stranger.StartServers(ctx, nil))
+}
+
+func TestGatewayFilesystemGuard(t *testing.T) {
+	url, root, _ := startGateway(t, nil)
+	ctx := context.Background()
+
+	// a plain MCP client, so only the gateway's guard is in the way
+	client := mcp.NewClient(&mcp.Implementation{Name: "plain", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: url}, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	blocked, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "mcp_filesystem_read_file",
+		Arguments: map[string]any{"path": filepath.Join(root, "node_modules", "x.js")},
+	})
+	require.NoError(t, err)
+	assert.True(t, blocked.IsError)
+	assert.Contains(t, blocked.Content[0].(*mcp.TextContent).Text, "blocked")
+
+	listing, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "mcp_filesystem_list_directory",
+		Arguments: map[string]any{"path": root},
+	})
+	require.NoError(t, err)
+	text := listing.Content[0].(*mcp.TextContent).Text
+	require.False(t, listing.IsError, text)
+	assert.Contains(t, text, "notes.txt")
+	assert.NotContains(t, text, "node_modules")
+}
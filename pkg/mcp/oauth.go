@@ -0,0 +1,532 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// OAuthConfig configures OAuth 2.1 authorization for an HTTP or SSE server.
+// It is optional: servers that answer 401 are authorized with dynamic client
+// registration and the scopes they advertise.
+type OAuthConfig struct {
+	ClientID     string   `toml:"client_id" json:"client_id,omitempty" mapstructure:"client_id"`             // Pre-registered client, skips dynamic registration
+	ClientSecret string   `toml:"client_secret" json:"client_secret,omitempty" mapstructure:"client_secret"` // Secret of the pre-registered client, if confidential
+	Scopes       []string `toml:"scopes" json:"scopes,omitempty" mapstructure:"scopes"`                      // Scopes to request instead of the advertised ones
+	CallbackPort int      `toml:"callback_port" json:"callback_port,omitempty" mapstructure:"callback_port"` // Loopback port for the redirect; 0 picks a free port
+}
+
+// OAuthCredentials is what a login stores for one server
+type OAuthCredentials struct {
+	ServerURL    string        `json:"server_url"`
+	Resource     string        `json:"resource"`
+	Issuer       string        `json:"issuer,omitempty"`
+	AuthURL      string        `json:"authorization_endpoint"`
+	TokenURL     string        `json:"token_endpoint"`
+	ClientID     string        `json:"client_id"`
+	ClientSecret string        `json:"client_secret,omitempty"`
+	RedirectURL  string        `json:"redirect_uri"`
+	Scopes       []string      `json:"scopes,omitempty"`
+	Token        *oauth2.Token `json:"token"`
+}
+
+func (c *OAuthCredentials) oauth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		Endpoint:     oauth2.Endpoint{AuthURL: c.AuthURL, TokenURL: c.TokenURL},
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.Scopes,
+	}
+}
+
+// AuthRequiredError reports that a server rejected the request for lack of a
+// valid access token
+type AuthRequiredError struct {
+	Server string
+	Reason string
+}
+
+func (e *AuthRequiredError) Error() string {
+	msg := fmt.Sprintf("MCP server %s requires authorization", e.Server)
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg + fmt.Sprintf(": run `agentgo mcp login %s`", e.Server)
+}
+
+// OAuthStore keeps OAuth credentials as one JSON file per server, readable
+// only by the user
+type OAuthStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewOAuthStore creates a store in dir
+func NewOAuthStore(dir string) *OAuthStore {
+	return &OAuthStore{dir: dir}
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Path returns the file holding the server's credentials
+func (s *OAuthStore) Path(server string) string {
+	return filepath.Join(s.dir, unsafeNameChars.ReplaceAllString(server, "_")+".json")
+}
+
+// Load returns the stored credentials, or nil when the server has none
+func (s *OAuthStore) Load(server string) (*OAuthCredentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.Path(server))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth credentials for %s: %w", server, err)
+	}
+	var creds OAuthCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth credentials for %s: %w", server, err)
+	}
+	return &creds, nil
+}
+
+// Save replaces the server's credentials
+func (s *OAuthStore) Save(server string, creds *OAuthCredentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create OAuth directory: %w", err)
+	}
+	path := s.Path(server)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write OAuth credentials: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Delete removes the server's credentials. The error wraps os.ErrNotExist
+// when there were none.
+func (s *OAuthStore) Delete(server string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.Remove(s.Path(server))
+}
+
+// OAuthLogin runs the authorization code flow with PKCE for one server:
+// protected resource and authorization server discovery, dynamic client
+// registration, and a loopback redirect to receive the code.
+type OAuthLogin struct {
+	Store      *OAuthStore
+	HTTPClient *http.Client
+	// OpenURL shows the authorization URL to the user, e.g. in a browser.
+	// The flow waits for the redirect after it returns.
+	OpenURL func(authURL string) error
+	// ClientName is sent during dynamic client registration
+	ClientName string
+}
+
+type protectedResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported"`
+}
+
+type authServerMetadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint"`
+	ScopesSupported               []string `json:"scopes_supported"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+type oauthCallback struct {
+	code string
+	err  error
+}
+
+// Login authorizes the server and stores the resulting tokens
+func (l *OAuthLogin) Login(ctx context.Context, server ServerConfig) (*OAuthCredentials, error) {
+	if server.URL == "" || (server.Type != ServerTypeHTTP && server.Type != ServerTypeSSE) {
+		return nil, fmt.Errorf("server %s is not an HTTP or SSE server", server.Name)
+	}
+	if l.OpenURL == nil {
+		return nil, fmt.Errorf("OpenURL is required")
+	}
+	opts := server.OAuth
+	if opts == nil {
+		opts = &OAuthConfig{}
+	}
+
+	prm, challengeScopes, err := l.discoverResource(ctx, server.URL)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := l.discoverAuthServer(ctx, prm.AuthorizationServers[0])
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(meta.CodeChallengeMethodsSupported, "S256") {
+		return nil, fmt.Errorf("authorization server %s does not support PKCE (S256)", meta.Issuer)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.CallbackPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OAuth callback: %w", err)
+	}
+	defer listener.Close()
+
+	creds := &OAuthCredentials{
+		ServerURL:    server.URL,
+		Resource:     prm.Resource,
+		Issuer:       meta.Issuer,
+		AuthURL:      meta.AuthorizationEndpoint,
+		TokenURL:     meta.TokenEndpoint,
+		ClientID:     opts.ClientID,
+		ClientSecret: opts.ClientSecret,
+		RedirectURL:  fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port),
+		Scopes:       firstNonEmpty(opts.Scopes, challengeScopes, prm.ScopesSupported),
+	}
+	if creds.Resource == "" {
+		creds.Resource = server.URL
+	}
+	if creds.ClientID == "" {
+		if meta.RegistrationEndpoint == "" {
+			return nil, fmt.Errorf("authorization server %s does not support dynamic client registration; set oauth.client_id for %s", meta.Issuer, server.Name)
+		}
+		if err := l.register(ctx, meta.RegistrationEndpoint, creds); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := creds.oauth2Config()
+	verifier := oauth2.GenerateVerifier()
+	state := oauth2.GenerateVerifier()
+	resource := oauth2.SetAuthURLParam("resource", creds.Resource)
+
+	callbacks := make(chan oauthCallback, 1)
+	srv := &http.Server{Handler: oauthCallbackHandler(state, callbacks), ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	if err := l.OpenURL(cfg.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), resource)); err != nil {
+		return nil, err
+	}
+
+	var cb oauthCallback
+	select {
+	case cb = <-callbacks:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if cb.err != nil {
+		return nil, cb.err
+	}
+
+	tok, err := cfg.Exchange(context.WithValue(ctx, oauth2.HTTPClient, l.httpClient()), cb.code, oauth2.VerifierOption(verifier), resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the authorization code: %w", err)
+	}
+	creds.Token = tok
+	if err := l.Store.Save(server.Name, creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+func (l *OAuthLogin) httpClient() *http.Client {
+	if l.HTTPClient != nil {
+		return l.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// discoverResource finds the protected resource metadata, preferring the
+// location the server names in its 401 challenge
+func (l *OAuthLogin) discoverResource(ctx context.Context, serverURL string) (*protectedResourceMetadata, []string, error) {
+	probe := []byte(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"agentgo","version":"1.0.0"}}}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL, bytes.NewReader(probe))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reach %s: %w", serverURL, err)
+	}
+	resp.Body.Close()
+
+	var candidates, scopes []string
+	if resp.StatusCode == http.StatusUnauthorized {
+		for _, m := range challengeParam.FindAllStringSubmatch(strings.Join(resp.Header.Values("WWW-Authenticate"), ","), -1) {
+			switch m[1] {
+			case "resource_metadata":
+				candidates = append(candidates, m[2])
+			case "scope":
+				scopes = strings.Fields(m[2])
+			}
+		}
+	}
+	candidates = append(candidates, wellKnownURLs(serverURL, "oauth-protected-resource")...)
+
+	for _, u := range candidates {
+		var prm protectedResourceMetadata
+		if err := l.getJSON(ctx, u, &prm); err == nil && len(prm.AuthorizationServers) > 0 {
+			return &prm, scopes, nil
+		}
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil, nil, fmt.Errorf("%s did not ask for authorization (HTTP %d)", serverURL, resp.StatusCode)
+	}
+	return nil, nil, fmt.Errorf("no protected resource metadata found for %s", serverURL)
+}
+
+// discoverAuthServer fetches the authorization server metadata (RFC 8414,
+// falling back to OpenID Connect discovery)
+func (l *OAuthLogin) discoverAuthServer(ctx context.Context, issuer string) (*authServerMetadata, error) {
+	candidates := append(wellKnownURLs(issuer, "oauth-authorization-server"), wellKnownURLs(issuer, "openid-configuration")...)
+	candidates = append(candidates, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration")
+
+	for _, u := range candidates {
+		var meta authServerMetadata
+		if err := l.getJSON(ctx, u, &meta); err == nil && meta.AuthorizationEndpoint != "" && meta.TokenEndpoint != "" {
+			if meta.Issuer == "" {
+				meta.Issuer = issuer
+			}
+			return &meta, nil
+		}
+	}
+	return nil, fmt.Errorf("no authorization server metadata found for %s", issuer)
+}
+
+// register performs dynamic client registration (RFC 7591) for a public
+// client using the loopback redirect
+func (l *OAuthLogin) register(ctx context.Context, endpoint string, creds *OAuthCredentials) error {
+	name := l.ClientName
+	if name == "" {
+		name = "agentgo"
+	}
+	body := map[string]any{
+		"client_name":                name,
+		"redirect_uris":              []string{creds.RedirectURL},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	}
+	if len(creds.Scopes) > 0 {
+		body["scope"] = strings.Join(creds.Scopes, " ")
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("client registration failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("client registration failed: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var reg struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reg); err != nil {
+		return fmt.Errorf("invalid client registration response: %w", err)
+	}
+	if reg.ClientID == "" {
+		return fmt.Errorf("client registration returned no client_id")
+	}
+	creds.ClientID, creds.ClientSecret = reg.ClientID, reg.ClientSecret
+	return nil
+}
+
+func (l *OAuthLogin) getJSON(ctx context.Context, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: HTTP %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// wellKnownURLs returns the well-known locations for a URL, path-specific
+// first (RFC 8414 / RFC 9728 insertion) and then at the host root
+func wellKnownURLs(raw, suffix string) []string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	root := u.Scheme + "://" + u.Host + "/.well-known/" + suffix
+	if path := strings.TrimSuffix(u.Path, "/"); path != "" {
+		return []string{root + path, root}
+	}
+	return []string{root}
+}
+
+func oauthCallbackHandler(state string, results chan<- oauthCallback) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var res oauthCallback
+		switch {
+		case q.Get("state") != state:
+			res.err = fmt.Errorf("OAuth callback state mismatch")
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = fmt.Errorf("OAuth callback without a code")
+		default:
+			res.code = q.Get("code")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, "Authorization complete. You can close this window and return to agentgo.")
+	})
+}
+
+func firstNonEmpty(lists ...[]string) []string {
+	for _, l := range lists {
+		if len(l) > 0 {
+			return l
+		}
+	}
+	return nil
+}
+
+// oauthTransport adds the stored access token to requests, refreshing and
+// saving it when it expires. A 401 is reported as AuthRequiredError.
+type oauthTransport struct {
+	server    string
+	serverURL string
+	store     *OAuthStore
+	base      http.RoundTripper
+
+	mu     sync.Mutex
+	creds  *OAuthCredentials
+	source oauth2.TokenSource
+}
+
+func newOAuthTransport(server, serverURL string, store *OAuthStore, base http.RoundTripper) *oauthTransport {
+	return &oauthTransport{server: server, serverURL: serverURL, store: store, base: base}
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A configured Authorization header takes precedence
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	tok, err := t.token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != nil {
+		req = req.Clone(req.Context())
+		tok.SetAuthHeader(req)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	reason := "not logged in"
+	if tok != nil {
+		reason = "access token rejected"
+		// Reload on the next request in case the user logged in again
+		t.mu.Lock()
+		t.creds, t.source = nil, nil
+		t.mu.Unlock()
+	}
+	return nil, &AuthRequiredError{Server: t.server, Reason: reason}
+}
+
+// token returns the current access token, or nil when the server has no
+// stored credentials
+func (t *oauthTransport) token() (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.creds == nil {
+		creds, err := t.store.Load(t.server)
+		if err != nil {
+			return nil, err
+		}
+		if creds == nil || creds.Token == nil || creds.ServerURL != t.serverURL {
+			return nil, nil
+		}
+		refreshCtx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: t.base})
+		t.creds = creds
+		t.source = creds.oauth2Config().TokenSource(refreshCtx, creds.Token)
+	}
+
+	tok, err := t.source.Token()
+	if err != nil {
+		t.creds, t.source = nil, nil
+		return nil, &AuthRequiredError{Server: t.server, Reason: "token refresh failed: " + err.Error()}
+	}
+	if tok.AccessToken != t.creds.Token.AccessToken {
+		t.creds.Token = tok
+		if err := t.store.Save(t.server, t.creds); err != nil {
+			log.Printf("[WARN] Failed to save refreshed OAuth token for %s: %v", t.server, err)
+		}
+	}
+	return tok, nil
+}
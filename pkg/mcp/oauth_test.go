@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// fakeAuthServer is an MCP server protected by an in-process OAuth 2.1
+// authorization server with dynamic client registration and PKCE
+type fakeAuthServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	redirects []string
+	challenge string
+	resource  string
+	valid     map[string]bool // access tokens the MCP endpoint accepts
+	refreshed int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	f := &fakeAuthServer{valid: map[string]bool{}}
+	server := mcp.NewServer(&mcp.Implementation{Name: "oauth-test", Version: "1.0.0"}, nil)
+	tool, handler := echoTool("echo")
+	mcp.AddTool(server, tool, handler)
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		ok := f.valid[r.Header.Get("Authorization")]
+		f.mu.Unlock()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata="%s/.well-known/oauth-protected-resource/mcp", scope="tools:read"`, f.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mcpHandler.ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/.well-known/oauth-protected-resource/mcp", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"resource": f.URL + "/mcp", "authorization_servers": []string{f.URL}})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                           f.URL,
+			"authorization_endpoint":           f.URL + "/authorize",
+			"token_endpoint":                   f.URL + "/token",
+			"registration_endpoint":            f.URL + "/register",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RedirectURIs []string `json:"redirect_uris"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.redirects = req.RedirectURIs
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, map[string]any{"client_id": "client-1", "redirect_uris": req.RedirectURIs})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if q.Get("client_id") != "client-1" || q.Get("code_challenge_method") != "S256" || len(f.redirects) == 0 || q.Get("redirect_uri") != f.redirects[0] {
+			http.Error(w, "bad authorization request", http.StatusBadRequest)
+			return
+		}
+		f.challenge, f.resource = q.Get("code_challenge"), q.Get("resource")
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code=code-1&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			if r.Form.Get("code") != "code-1" || base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				writeJSON(w, map[string]any{"error": "invalid_grant"})
+				return
+			}
+			f.valid["Bearer access-1"] = true
+			writeJSON(w, map[string]any{"access_token": "access-1", "refresh_token": "refresh-1", "token_type": "Bearer", "expires_in": 3600})
+		case "refresh_token":
+			f.refreshed++
+			f.valid["Bearer access-2"] = true
+			writeJSON(w, map[string]any{"access_token": "access-2", "refresh_token": "refresh-2", "token_type": "Bearer", "expires_in": 3600})
+		}
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// browse follows the authorization URL like a browser that is already
+// signed in, ending at the loopback callback
+func browse(authURL string) error {
+	resp, err := http.Get(authURL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("callback returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func TestOAuthLogin(t *testing.T) {
+	f := newFakeAuthServer(t)
+	store := NewOAuthStore(t.TempDir())
+	server := ServerConfig{Name: "remote", Type: ServerTypeHTTP, URL: f.URL + "/mcp"}
+
+	login := &OAuthLogin{Store: store, OpenURL: browse}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	creds, err := login.Login(ctx, server)
+	require.NoError(t, err)
+
+	assert.Equal(t, "client-1", creds.ClientID)
+	assert.Equal(t, []string{"tools:read"}, creds.Scopes, "scope from the 401 challenge")
+	assert.Equal(t, f.URL+"/mcp", f.resource, "resource indicator sent")
+
+	stored, err := store.Load("remote")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "access-1", stored.Token.AccessToken)
+	info, err := os.Stat(store.Path("remote"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, store.Delete("remote"))
+	assert.ErrorIs(t, store.Delete("remote"), os.ErrNotExist)
+}
+
+func TestOAuthLoginRequiresChallenge(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	login := &OAuthLogin{Store: NewOAuthStore(t.TempDir()), OpenURL: browse}
+	_, err := login.Login(context.Background(), ServerConfig{Name: "open", Type: ServerTypeHTTP, URL: srv.URL + "/mcp"})
+	assert.ErrorContains(t, err, "did not ask for authorization")
+}
+
+func TestOAuthTransport(t *testing.T) {
+	f := newFakeAuthServer(t)
+	dir := t.TempDir()
+	store := NewOAuthStore(dir)
+	serverURL := f.URL + "/mcp"
+
+	cfg := DefaultConfig()
+	cfg.OAuthDir = dir
+	cfg.AddServer(&ServerConfig{Name: "remote", Type: ServerTypeHTTP, URL: serverURL})
+	m := NewManager(&cfg)
+	defer m.Close()
+	ctx := context.Background()
+
+	_, err := m.StartServer(ctx, "remote")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agentgo mcp login remote")
+
+	// An expired token is refreshed and the new one saved
+	f.mu.Lock()
+	f.valid["Bearer access-1"] = true
+	f.mu.Unlock()
+	require.NoError(t, store.Save("remote", &OAuthCredentials{
+		ServerURL: serverURL,
+		Resource:  serverURL,
+		AuthURL:   f.URL + "/authorize",
+		TokenURL:  f.URL + "/token",
+		ClientID:  "client-1",
+		Token:     &oauth2.Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Minute)},
+	}))
+
+	client, err := m.StartServer(ctx, "remote")
+	require.NoError(t, err)
+	assert.Contains(t, client.GetTools(), "echo")
+
+	stored, err := store.Load("remote")
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", stored.Token.AccessToken)
+	f.mu.Lock()
+	assert.Equal(t, 1, f.refreshed)
+	f.mu.Unlock()
+}
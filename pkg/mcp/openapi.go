@@ -0,0 +1,782 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIConfig describes an HTTP API whose operations the built-in bridge
+// serves as MCP tools (server type "openapi"). OpenAPI 3.0, 3.1 and Swagger
+// 2.0 specs are read as JSON or YAML.
+//
+// In mcpServers.json:
+//
+//	"billing": {
+//	  "type": "openapi",
+//	  "openapi": {
+//	    "spec": "https://billing.internal/openapi.yaml",
+//	    "tags": ["invoices"],
+//	    "auth": {"type": "oauth2", "client_id": "agentgo", "client_secret": "${BILLING_SECRET}"}
+//	  }
+//	}
+type OpenAPIConfig struct {
+	Spec       string             `toml:"spec" json:"spec" mapstructure:"spec"`                                // URL or file of the spec; files are relative to the servers config
+	BaseURL    string             `toml:"base_url" json:"base_url,omitempty" mapstructure:"base_url"`          // Overrides the first server URL of the spec
+	Tags       []string           `toml:"tags" json:"tags,omitempty" mapstructure:"tags"`                      // Only serve operations with one of these tags
+	Operations []string           `toml:"operations" json:"operations,omitempty" mapstructure:"operations"`    // Only serve these operationIds (path.Match globs)
+	Exclude    []string           `toml:"exclude" json:"exclude,omitempty" mapstructure:"exclude"`             // operationIds (globs) to leave out
+	Headers    map[string]string  `toml:"headers" json:"headers,omitempty" mapstructure:"headers"`             // Sent with every request
+	Auth       *OpenAPIAuth       `toml:"auth" json:"auth,omitempty" mapstructure:"auth"`                      // How requests are authorized
+	Pagination *OpenAPIPagination `toml:"pagination" json:"pagination,omitempty" mapstructure:"pagination"`    // Cursor pagination; detected when not set
+	UploadDirs []string           `toml:"upload_dirs" json:"upload_dirs,omitempty" mapstructure:"upload_dirs"` // Directories multipart file fields may upload from; none disables uploads
+}
+
+// OpenAPIAuth authorizes the bridge's requests. Secrets may reference
+// environment variables as ${NAME}. Missing API key names and token URLs are
+// taken from the security schemes of the spec.
+type OpenAPIAuth struct {
+	Type         string   `toml:"type" json:"type" mapstructure:"type"`                                      // bearer, basic, apikey or oauth2 (client credentials)
+	Token        string   `toml:"token" json:"token,omitempty" mapstructure:"token"`                         // Bearer token or API key
+	Name         string   `toml:"name" json:"name,omitempty" mapstructure:"name"`                            // API key parameter name
+	In           string   `toml:"in" json:"in,omitempty" mapstructure:"in"`                                  // Where the API key goes: header (default), query or cookie
+	Username     string   `toml:"username" json:"username,omitempty" mapstructure:"username"`                // For basic auth
+	Password     string   `toml:"password" json:"password,omitempty" mapstructure:"password"`                // For basic auth
+	ClientID     string   `toml:"client_id" json:"client_id,omitempty" mapstructure:"client_id"`             // For oauth2
+	ClientSecret string   `toml:"client_secret" json:"client_secret,omitempty" mapstructure:"client_secret"` // For oauth2
+	TokenURL     string   `toml:"token_url" json:"token_url,omitempty" mapstructure:"token_url"`             // For oauth2
+	Scopes       []string `toml:"scopes" json:"scopes,omitempty" mapstructure:"scopes"`                      // For oauth2
+}
+
+// OpenAPIPagination configures how GET operations are paged through. The
+// bridge follows the next cursor, or a Link header with rel="next", and
+// returns the items of all pages as one result.
+type OpenAPIPagination struct {
+	CursorParam string `toml:"cursor_param" json:"cursor_param,omitempty" mapstructure:"cursor_param"` // Query parameter that takes the cursor
+	NextCursor  string `toml:"next_cursor" json:"next_cursor,omitempty" mapstructure:"next_cursor"`    // Dotted path of the next cursor in a response
+	Items       string `toml:"items" json:"items,omitempty" mapstructure:"items"`                      // Dotted path of the items in a response
+	MaxPages    int    `toml:"max_pages" json:"max_pages,omitempty" mapstructure:"max_pages"`          // Pages fetched per call, default 5; 1 turns paging off
+}
+
+const defaultOpenAPIMaxPages = 5
+
+// Query parameters and response fields that commonly carry a page cursor,
+// tried in order when the pagination is not configured
+var (
+	openAPICursorParams = []string{"cursor", "page_token", "pageToken", "next_token", "nextToken", "starting_after", "after"}
+	openAPINextCursors  = []string{"next_cursor", "nextCursor", "next_page_token", "nextPageToken", "next_token", "nextToken",
+		"meta.next_cursor", "pagination.next_cursor", "response_metadata.next_cursor", "paging.next_cursor"}
+	openAPIItemFields = []string{"data", "items", "results", "records", "entries", "values"}
+)
+
+// OpenAPIBridge calls the operations of an HTTP API described by an
+// OpenAPI spec. Each operation is one tool.
+type OpenAPIBridge struct {
+	config   *OpenAPIConfig
+	baseURL  *url.URL
+	client   *http.Client
+	auth     *OpenAPIAuth
+	maxPages int
+	ops      []*openAPIOperation
+	byName   map[string]*openAPIOperation
+}
+
+// openAPIOperation is one operation of the spec and the tool serving it
+type openAPIOperation struct {
+	tool        *mcp.Tool
+	method      string
+	path        string
+	params      []openAPIParam
+	body        string          // media type of the request body, empty without one
+	files       map[string]bool // multipart fields that upload local files
+	cursorParam string          // query parameter the operation is paged with
+}
+
+type openAPIParam struct {
+	name string
+	in   string // path, query, header or cookie
+}
+
+// NewOpenAPIBridge loads the spec of cfg and prepares its operations
+func NewOpenAPIBridge(cfg *OpenAPIConfig) (*OpenAPIBridge, error) {
+	if cfg == nil || cfg.Spec == "" {
+		return nil, errors.New("an OpenAPI spec is required")
+	}
+	spec, err := loadOpenAPISpec(cfg.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return newOpenAPIBridge(cfg, spec)
+}
+
+func newOpenAPIBridge(cfg *OpenAPIConfig, spec map[string]any) (*OpenAPIBridge, error) {
+	_, swagger2 := spec["swagger"]
+	if _, ok := spec["openapi"]; !ok && !swagger2 {
+		return nil, fmt.Errorf("%s is not an OpenAPI or Swagger spec", cfg.Spec)
+	}
+
+	b := &OpenAPIBridge{config: cfg, maxPages: defaultOpenAPIMaxPages, byName: make(map[string]*openAPIOperation)}
+	if cfg.Pagination != nil && cfg.Pagination.MaxPages > 0 {
+		b.maxPages = cfg.Pagination.MaxPages
+	}
+	var err error
+	if b.baseURL, err = openAPIBaseURL(cfg, spec, swagger2); err != nil {
+		return nil, err
+	}
+	if b.auth, err = cfg.Auth.resolve(spec); err != nil {
+		return nil, err
+	}
+	b.client = b.auth.httpClient()
+
+	resolver := &schemaResolver{root: spec}
+	paths, _ := spec["paths"].(map[string]any)
+	for _, p := range sortedKeys(paths) {
+		item := resolver.deref(paths[p])
+		for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			operation := b.operation(resolver, method, p, item, op, swagger2)
+			if operation == nil {
+				continue
+			}
+			b.ops = append(b.ops, operation)
+			b.byName[operation.tool.Name] = operation
+		}
+	}
+	if len(b.ops) == 0 {
+		return nil, fmt.Errorf("no operations of %s match the configured filters", cfg.Spec)
+	}
+	return b, nil
+}
+
+// Tools returns one tool per served operation
+func (b *OpenAPIBridge) Tools() []*mcp.Tool {
+	tools := make([]*mcp.Tool, len(b.ops))
+	for i, op := range b.ops {
+		tools[i] = op.tool
+	}
+	return tools
+}
+
+// Server returns an MCP server offering the tools of the bridge
+func (b *OpenAPIBridge) Server() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "agentgo-openapi", Version: "1.0.0"}, nil)
+	for _, op := range b.ops {
+		server.AddTool(op.tool, b.handler(op))
+	}
+	return server
+}
+
+// CallTool calls the operation behind tool name and returns the response body
+func (b *OpenAPIBridge) CallTool(ctx context.Context, name string, args map[string]any) (string, error) {
+	op, ok := b.byName[name]
+	if !ok {
+		return "", fmt.Errorf("unknown operation %s", name)
+	}
+	return b.call(ctx, op, args)
+}
+
+func (b *OpenAPIBridge) handler(op *openAPIOperation) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args map[string]any
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return toolError(fmt.Errorf("invalid arguments for %s: %w", op.tool.Name, err)), nil
+			}
+		}
+		text, err := b.call(ctx, op, args)
+		if err != nil {
+			return toolError(err), nil
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+	}
+}
+
+// createOpenAPITransport serves the API of an "openapi" server in process
+func (c *Client) createOpenAPITransport(ctx context.Context) (mcp.Transport, error) {
+	bridge, err := NewOpenAPIBridge(c.config.OpenAPI)
+	if err != nil {
+		return nil, err
+	}
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := bridge.Server().Connect(ctx, serverTransport, nil); err != nil {
+		return nil, err
+	}
+	return clientTransport, nil
+}
+
+// includes reports whether the operation is served under the filters
+func (c *OpenAPIConfig) includes(name string, tags []string) bool {
+	if len(c.Tags) > 0 && !slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(c.Tags, tag) }) {
+		return false
+	}
+	if len(c.Operations) > 0 && !matchesAny(c.Operations, name) {
+		return false
+	}
+	return !matchesAny(c.Exclude, name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// operation builds the tool of one operation, or returns nil when the
+// filters leave it out
+func (b *OpenAPIBridge) operation(r *schemaResolver, method, p string, item, op map[string]any, swagger2 bool) *openAPIOperation {
+	name, _ := op["operationId"].(string)
+	if name == "" {
+		name = method + "_" + p
+	}
+	name = openAPIToolName(name)
+	var tags []string
+	for _, tag := range asSlice(op["tags"]) {
+		if s, ok := tag.(string); ok {
+			tags = append(tags, s)
+		}
+	}
+	if !b.config.includes(name, tags) {
+		return nil
+	}
+	for i := 2; b.byName[name] != nil; i++ {
+		name = fmt.Sprintf("%s_%d", strings.TrimRight(name, "_0123456789"), i)
+	}
+
+	operation := &openAPIOperation{method: strings.ToUpper(method), path: p, files: map[string]bool{}}
+	properties := map[string]any{}
+	var required []string
+	var form map[string]any // Swagger 2.0 formData parameters
+	var queryParams []string
+
+	for _, param := range openAPIParams(r, item, op) {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		if name == "" {
+			continue
+		}
+		var schema map[string]any
+		if s, ok := param["schema"]; ok {
+			schema, _ = r.schema(s, nil).(map[string]any)
+		} else {
+			// Swagger 2.0 keeps the type on the parameter itself
+			schema = map[string]any{}
+			for _, key := range []string{"type", "format", "items", "enum", "default", "minimum", "maximum", "pattern"} {
+				if v, ok := param[key]; ok {
+					schema[key] = r.schema(v, nil)
+				}
+			}
+		}
+		if schema == nil {
+			schema = map[string]any{}
+		}
+		if desc, ok := param["description"].(string); ok && schema["description"] == nil {
+			schema["description"] = desc
+		}
+		isRequired, _ := param["required"].(bool)
+
+		switch in {
+		case "body":
+			operation.body = "application/json"
+			properties["body"] = schema
+		case "formData":
+			if form == nil {
+				form = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			if schema["type"] == "file" {
+				schema = map[string]any{"type": "string", "description": "path of a local file to upload"}
+				operation.files[name] = true
+			}
+			form["properties"].(map[string]any)[name] = schema
+			if isRequired {
+				form["required"] = append(asSlice(form["required"]), name)
+			}
+			continue
+		case "header":
+			switch strings.ToLower(name) {
+			case "authorization", "content-type", "accept":
+				continue // set by the bridge
+			}
+			fallthrough
+		case "path", "query", "cookie":
+			properties[name] = schema
+			operation.params = append(operation.params, openAPIParam{name: name, in: in})
+			if in == "query" {
+				queryParams = append(queryParams, name)
+			}
+		default:
+			continue
+		}
+		if in == "body" {
+			name = "body"
+		}
+		if isRequired || in == "path" {
+			required = append(required, name)
+		}
+	}
+
+	if form != nil {
+		operation.body = "application/x-www-form-urlencoded"
+		if len(operation.files) > 0 || slices.Contains(stringSlice(op["consumes"]), "multipart/form-data") {
+			operation.body = "multipart/form-data"
+		}
+		properties["body"] = form
+		if len(asSlice(form["required"])) > 0 {
+			required = append(required, "body")
+		}
+	}
+	if !swagger2 {
+		if body := r.deref(op["requestBody"]); body != nil {
+			mediaType, schema := openAPIRequestBody(r, body)
+			if mediaType != "" {
+				operation.body = mediaType
+				if mediaType == "multipart/form-data" {
+					markUploads(schema, operation.files)
+				}
+				if schema["description"] == nil {
+					schema["description"] = strings.TrimSpace(mediaType + " request body. " + orEmpty(body["description"]))
+				}
+				properties["body"] = schema
+				if isRequired, _ := body["required"].(bool); isRequired {
+					required = append(required, "body")
+				}
+			}
+		}
+	}
+
+	if operation.method == http.MethodGet && b.maxPages > 1 {
+		candidates := openAPICursorParams
+		if b.config.Pagination != nil && b.config.Pagination.CursorParam != "" {
+			candidates = []string{b.config.Pagination.CursorParam}
+		}
+		for _, candidate := range candidates {
+			if slices.Contains(queryParams, candidate) {
+				operation.cursorParam = candidate
+				break
+			}
+		}
+	}
+
+	inputSchema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		inputSchema["required"] = required
+	}
+	description := strings.TrimSpace(fmt.Sprintf("%v\n\n%v", orEmpty(op["summary"]), orEmpty(op["description"])))
+	description = strings.TrimSpace(description + "\n\n" + operation.method + " " + p)
+	if operation.cursorParam != "" {
+		description += fmt.Sprintf("\nUp to %d pages are fetched and combined; pass %s to continue from a cursor.", b.maxPages, operation.cursorParam)
+	}
+	operation.tool = &mcp.Tool{Name: name, Description: description, InputSchema: inputSchema}
+	return operation
+}
+
+// openAPIParams merges the path item parameters with the operation's,
+// which win for the same name and location
+func openAPIParams(r *schemaResolver, item, op map[string]any) []map[string]any {
+	var params []map[string]any
+	index := map[string]int{}
+	for _, list := range []any{item["parameters"], op["parameters"]} {
+		for _, p := range asSlice(list) {
+			param := r.deref(p)
+			if param == nil {
+				continue
+			}
+			key := fmt.Sprintf("%v/%v", param["in"], param["name"])
+			if i, ok := index[key]; ok {
+				params[i] = param
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// openAPIRequestBody picks the media type the bridge sends: JSON first,
+// then forms, then anything else as text
+func openAPIRequestBody(r *schemaResolver, body map[string]any) (string, map[string]any) {
+	content, _ := body["content"].(map[string]any)
+	types := sortedKeys(content)
+	pick := ""
+	for _, want := range []func(string) bool{
+		func(t string) bool { return t == "application/json" || strings.HasSuffix(t, "+json") },
+		func(t string) bool { return t == "multipart/form-data" },
+		func(t string) bool { return t == "application/x-www-form-urlencoded" },
+		func(t string) bool { return true },
+	} {
+		if i := slices.IndexFunc(types, want); i >= 0 {
+			pick = types[i]
+			break
+		}
+	}
+	if pick == "" {
+		return "", nil
+	}
+	media, _ := content[pick].(map[string]any)
+	schema, _ := r.schema(media["schema"], nil).(map[string]any)
+	if schema == nil {
+		schema = map[string]any{}
+	}
+	if strings.HasPrefix(pick, "text/") && schema["type"] == nil {
+		schema["type"] = "string"
+	}
+	return pick, schema
+}
+
+// markUploads replaces the binary fields of a multipart body with local
+// file paths to upload
+func markUploads(schema map[string]any, files map[string]bool) {
+	properties, _ := schema["properties"].(map[string]any)
+	for name, p := range properties {
+		prop, _ := p.(map[string]any)
+		switch {
+		case isBinarySchema(prop):
+			properties[name] = map[string]any{"type": "string", "description": "path of a local file to upload"}
+			files[name] = true
+		case prop["type"] == "array" && isBinarySchema(asMap(prop["items"])):
+			properties[name] = map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "paths of local files to upload"}
+			files[name] = true
+		}
+	}
+}
+
+func isBinarySchema(schema map[string]any) bool {
+	if schema == nil {
+		return false
+	}
+	// 3.0 marks files with a format, 3.1 with a media type
+	_, media := schema["contentMediaType"]
+	return schema["format"] == "binary" || media
+}
+
+var invalidToolChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// openAPIToolName turns an operationId or method and path into a tool name
+func openAPIToolName(name string) string {
+	name = strings.Trim(invalidToolChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// openAPIBaseURL is the configured base URL or the first server of the
+// spec, resolved against the spec's own URL when relative
+func openAPIBaseURL(cfg *OpenAPIConfig, spec map[string]any, swagger2 bool) (*url.URL, error) {
+	raw := cfg.BaseURL
+	if raw == "" && swagger2 {
+		if host, _ := spec["host"].(string); host != "" {
+			scheme := "https"
+			if schemes := stringSlice(spec["schemes"]); len(schemes) > 0 && !slices.Contains(schemes, "https") {
+				scheme = schemes[0]
+			}
+			raw = scheme + "://" + host
+		}
+		raw += orEmpty(spec["basePath"])
+	}
+	if raw == "" {
+		if servers := asSlice(spec["servers"]); len(servers) > 0 {
+			server := asMap(servers[0])
+			raw, _ = server["url"].(string)
+			variables := asMap(server["variables"])
+			for name, v := range variables {
+				raw = strings.ReplaceAll(raw, "{"+name+"}", orEmpty(asMap(v)["default"]))
+			}
+		}
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API base URL %q: %w", raw, err)
+	}
+	if !base.IsAbs() {
+		specURL, err := url.Parse(cfg.Spec)
+		if err != nil || (specURL.Scheme != "http" && specURL.Scheme != "https") {
+			return nil, fmt.Errorf("the spec has no absolute server URL, set base_url")
+		}
+		base = specURL.ResolveReference(base)
+	}
+	return base, nil
+}
+
+// loadOpenAPISpec reads a spec from a URL or file, as JSON or YAML
+func loadOpenAPISpec(location string) (map[string]any, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "file://") {
+		data, err = fetchSwaggerSpec(location, nil, 30*time.Second)
+	} else {
+		data, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec %s: %w", location, err)
+	}
+
+	return parseOpenAPISpec(data, location)
+}
+
+// parseOpenAPISpec decodes a JSON or YAML spec
+func parseOpenAPISpec(data []byte, location string) (map[string]any, error) {
+	var spec map[string]any
+	if err := json.Unmarshal(data, &spec); err == nil {
+		return spec, nil
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec %s: %w", location, err)
+	}
+	spec, ok := normalizeYAML(doc).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an OpenAPI or Swagger spec", location)
+	}
+	return spec, nil
+}
+
+// normalizeYAML gives YAML maps string keys, as JSON has; status codes
+// like 200 are read as numbers otherwise
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			v[k] = normalizeYAML(val)
+		}
+		return v
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return out
+	case []any:
+		for i, val := range v {
+			v[i] = normalizeYAML(val)
+		}
+		return v
+	}
+	return v
+}
+
+// resolveSpec makes a relative spec file and upload directories relative
+// to dir
+func (c *OpenAPIConfig) resolveSpec(dir string) {
+	for i, d := range c.UploadDirs {
+		if !filepath.IsAbs(d) {
+			c.UploadDirs[i] = filepath.Join(dir, d)
+		}
+	}
+	if c.Spec == "" || strings.Contains(c.Spec, "://") || filepath.IsAbs(c.Spec) {
+		return
+	}
+	c.Spec = filepath.Join(dir, c.Spec)
+}
+
+// schemaResolver turns OpenAPI schemas into self-contained JSON Schemas
+type schemaResolver struct {
+	root map[string]any
+}
+
+// deref follows $refs to the object they point at
+func (r *schemaResolver) deref(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	for i := 0; i < 16 && m != nil; i++ {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		m, _ = r.lookup(ref).(map[string]any)
+	}
+	return m
+}
+
+// lookup resolves a local reference such as #/components/schemas/Pet
+func (r *schemaResolver) lookup(ref string) any {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var cur any = r.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// Keywords whose value is a schema, a list of schemas or a map of schemas
+var (
+	schemaKeywords     = []string{"items", "additionalProperties", "not", "contains", "propertyNames", "if", "then", "else", "unevaluatedItems", "unevaluatedProperties"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "$defs", "dependentSchemas"}
+)
+
+// schema resolves the $refs in v, merges allOf object schemas and drops
+// keywords that only OpenAPI knows. seen holds the references being
+// resolved, so recursive schemas end in a plain object.
+func (r *schemaResolver) schema(v any, seen []string) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if ref, ok := m["$ref"].(string); ok {
+		name := ref[strings.LastIndex(ref, "/")+1:]
+		if slices.Contains(seen, ref) {
+			return map[string]any{"type": "object", "description": "a nested " + name}
+		}
+		target := r.lookup(ref)
+		if target == nil {
+			return map[string]any{"description": "unresolved reference " + ref}
+		}
+		resolved, ok := r.schema(target, append(seen, ref)).(map[string]any)
+		if !ok {
+			return target
+		}
+		// OpenAPI 3.1 allows keywords next to $ref, they refine the target
+		for k, val := range m {
+			if k != "$ref" {
+				resolved[k] = r.schema(val, seen)
+			}
+		}
+		return resolved
+	}
+
+	out := make(map[string]any, len(m))
+	for k, val := range m {
+		switch {
+		case k == "discriminator" || k == "xml" || k == "externalDocs" || k == "example" || k == "nullable":
+		case slices.Contains(schemaKeywords, k):
+			out[k] = r.schema(val, seen)
+		case slices.Contains(schemaListKeywords, k):
+			var list []any
+			for _, item := range asSlice(val) {
+				list = append(list, r.schema(item, seen))
+			}
+			out[k] = list
+		case slices.Contains(schemaMapKeywords, k):
+			props := map[string]any{}
+			for name, prop := range asMap(val) {
+				props[name] = r.schema(prop, seen)
+			}
+			out[k] = props
+		default:
+			out[k] = val
+		}
+	}
+	// OpenAPI 3.0 marks nullable values with a keyword, 3.1 with a type list
+	if nullable, _ := m["nullable"].(bool); nullable {
+		if t, ok := out["type"].(string); ok {
+			out["type"] = []any{t, "null"}
+		}
+	}
+	if parts, ok := out["allOf"].([]any); ok {
+		if merged := mergeAllOf(parts); merged != nil {
+			delete(out, "allOf")
+			mergeObjectSchema(merged, out)
+			return merged
+		}
+	}
+	return out
+}
+
+// mergeAllOf combines object schemas into one, which tool callers handle
+// far better than allOf. It returns nil when a part is not a plain object.
+func mergeAllOf(parts []any) map[string]any {
+	merged := map[string]any{"type": "object", "properties": map[string]any{}}
+	for _, p := range parts {
+		part, ok := p.(map[string]any)
+		if !ok || (part["type"] != nil && part["type"] != "object") || part["oneOf"] != nil || part["anyOf"] != nil || part["allOf"] != nil {
+			return nil
+		}
+		mergeObjectSchema(merged, part)
+	}
+	return merged
+}
+
+// mergeObjectSchema adds the properties and required fields of from to into
+func mergeObjectSchema(into, from map[string]any) {
+	for k, v := range from {
+		switch k {
+		case "properties":
+			props := asMap(into["properties"])
+			if props == nil {
+				props = map[string]any{}
+			}
+			for name, prop := range asMap(v) {
+				props[name] = prop
+			}
+			into["properties"] = props
+		case "required":
+			required := asSlice(into["required"])
+			for _, name := range asSlice(v) {
+				if !slices.Contains(required, name) {
+					required = append(required, name)
+				}
+			}
+			into["required"] = required
+		case "type":
+			into["type"] = "object"
+		default:
+			if _, exists := into[k]; !exists {
+				into[k] = v
+			}
+		}
+	}
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func stringSlice(v any) []string {
+	var out []string
+	for _, item := range asSlice(v) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func orEmpty(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
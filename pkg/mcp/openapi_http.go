@@ -0,0 +1,499 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// maxOpenAPIResponse caps how much of a response body is read
+const maxOpenAPIResponse = 8 << 20
+
+// resolve expands environment references in the secrets and fills what is
+// missing from the security schemes of the spec
+func (a *OpenAPIAuth) resolve(spec map[string]any) (*OpenAPIAuth, error) {
+	if a == nil || a.Type == "" {
+		return nil, nil
+	}
+	auth := *a
+	for _, field := range []*string{&auth.Token, &auth.Username, &auth.Password, &auth.ClientID, &auth.ClientSecret} {
+		*field = os.ExpandEnv(*field)
+	}
+
+	schemes := asMap(asMap(spec["components"])["securitySchemes"])
+	if schemes == nil {
+		schemes = asMap(spec["securityDefinitions"]) // Swagger 2.0
+	}
+	for _, name := range sortedKeys(schemes) {
+		scheme := asMap(schemes[name])
+		switch {
+		case auth.Type == "apikey" && scheme["type"] == "apiKey" && auth.Name == "":
+			auth.Name = orEmpty(scheme["name"])
+			auth.In = orEmpty(scheme["in"])
+		case auth.Type == "oauth2" && scheme["type"] == "oauth2" && auth.TokenURL == "":
+			if flow := asMap(asMap(scheme["flows"])["clientCredentials"]); flow != nil {
+				auth.TokenURL = orEmpty(flow["tokenUrl"])
+			} else if scheme["flow"] == "application" {
+				auth.TokenURL = orEmpty(scheme["tokenUrl"])
+			}
+		}
+	}
+
+	switch auth.Type {
+	case "bearer":
+		if auth.Token == "" {
+			return nil, fmt.Errorf("bearer auth needs a token")
+		}
+	case "basic":
+		if auth.Username == "" {
+			return nil, fmt.Errorf("basic auth needs a username")
+		}
+	case "apikey":
+		if auth.Token == "" || auth.Name == "" {
+			return nil, fmt.Errorf("apikey auth needs a token and a parameter name")
+		}
+		if auth.In == "" {
+			auth.In = "header"
+		}
+	case "oauth2":
+		if auth.ClientID == "" || auth.TokenURL == "" {
+			return nil, fmt.Errorf("oauth2 auth needs a client_id and a token_url")
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth type %q (use bearer, basic, apikey or oauth2)", auth.Type)
+	}
+	return &auth, nil
+}
+
+// httpClient returns the client requests are sent with. For OAuth client
+// credentials it fetches and refreshes the token itself.
+func (a *OpenAPIAuth) httpClient() *http.Client {
+	if a == nil || a.Type != "oauth2" {
+		return &http.Client{}
+	}
+	cfg := &clientcredentials.Config{
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		TokenURL:     a.TokenURL,
+		Scopes:       a.Scopes,
+	}
+	return cfg.Client(context.Background())
+}
+
+// authorize adds the configured headers and credentials to req
+func (b *OpenAPIBridge) authorize(req *http.Request) {
+	for k, v := range b.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if b.auth == nil {
+		return
+	}
+	switch b.auth.Type {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+b.auth.Token)
+	case "basic":
+		req.SetBasicAuth(b.auth.Username, b.auth.Password)
+	case "apikey":
+		switch b.auth.In {
+		case "query":
+			q := req.URL.Query()
+			q.Set(b.auth.Name, b.auth.Token)
+			req.URL.RawQuery = q.Encode()
+		case "cookie":
+			req.AddCookie(&http.Cookie{Name: b.auth.Name, Value: b.auth.Token})
+		default:
+			req.Header.Set(b.auth.Name, b.auth.Token)
+		}
+	}
+}
+
+// call runs the operation and pages through the results of paginated GETs
+func (b *OpenAPIBridge) call(ctx context.Context, op *openAPIOperation, args map[string]any) (string, error) {
+	req, err := b.request(ctx, op, args)
+	if err != nil {
+		return "", err
+	}
+	body, next, err := b.do(req)
+	if err != nil || op.method != http.MethodGet || b.maxPages <= 1 {
+		return string(body), err
+	}
+	return b.paginate(ctx, op, args, body, next)
+}
+
+// paginate fetches further pages after first and combines their items.
+// The result keeps the shape of the first page with the items of all
+// pages, and the cursor of the last page so the caller can go on.
+func (b *OpenAPIBridge) paginate(ctx context.Context, op *openAPIOperation, args map[string]any, first []byte, next string) (string, error) {
+	var doc any
+	if err := json.Unmarshal(first, &doc); err != nil {
+		return string(first), nil
+	}
+	var cfg OpenAPIPagination
+	if b.config.Pagination != nil {
+		cfg = *b.config.Pagination
+	}
+	itemsPath, ok := cfg.Items, cfg.Items != ""
+	if !ok {
+		itemsPath, ok = detectItems(doc)
+	}
+	items, isList := valueAt(doc, itemsPath).([]any)
+	if !ok || !isList {
+		return string(first), nil
+	}
+	cursorPath := cfg.NextCursor
+	if cursorPath == "" {
+		for _, candidate := range openAPINextCursors {
+			if _, ok := valueAt(doc, candidate).(string); ok {
+				cursorPath = candidate
+				break
+			}
+		}
+	}
+
+	page, pages := doc, 1
+	for ; pages < b.maxPages; pages++ {
+		var req *http.Request
+		var err error
+		cursor, _ := valueAt(page, cursorPath).(string)
+		hasMore, known := valueAt(page, "has_more").(bool)
+		switch {
+		case known && !hasMore:
+		case op.cursorParam != "" && cursorPath != "" && cursor != "":
+			pageArgs := make(map[string]any, len(args)+1)
+			for k, v := range args {
+				pageArgs[k] = v
+			}
+			pageArgs[op.cursorParam] = cursor
+			req, err = b.request(ctx, op, pageArgs)
+		case next != "":
+			req, err = http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		}
+		if err != nil {
+			return "", err
+		}
+		if req == nil {
+			break
+		}
+		var body []byte
+		if body, next, err = b.do(req); err != nil {
+			return "", fmt.Errorf("page %d: %w", pages+1, err)
+		}
+		var more any
+		if json.Unmarshal(body, &more) != nil {
+			break
+		}
+		moreItems, ok := valueAt(more, itemsPath).([]any)
+		if !ok {
+			break
+		}
+		items = append(items, moreItems...)
+		page = more
+	}
+
+	doc = setValueAt(doc, itemsPath, items)
+	if m, ok := doc.(map[string]any); ok {
+		if cursorPath != "" {
+			doc = setValueAt(m, cursorPath, valueAt(page, cursorPath))
+		}
+		m["pages_fetched"] = pages
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// detectItems finds the list a page holds: the page itself, a well-known
+// field or else the first field with a list
+func detectItems(doc any) (string, bool) {
+	if _, ok := doc.([]any); ok {
+		return "", true
+	}
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	for _, field := range openAPIItemFields {
+		if _, ok := m[field].([]any); ok {
+			return field, true
+		}
+	}
+	for _, field := range sortedKeys(m) {
+		if _, ok := m[field].([]any); ok {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// valueAt follows a dotted path into decoded JSON; the empty path is doc
+func valueAt(doc any, path string) any {
+	if path == "" {
+		return doc
+	}
+	for _, key := range strings.Split(path, ".") {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return nil
+		}
+		doc = m[key]
+	}
+	return doc
+}
+
+// setValueAt sets the value at a dotted path and returns the new doc
+func setValueAt(doc any, path string, value any) any {
+	if path == "" {
+		return value
+	}
+	keys := strings.Split(path, ".")
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return doc
+	}
+	cur := m
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := cur[key].(map[string]any)
+		if !ok {
+			return doc
+		}
+		cur = next
+	}
+	if value == nil {
+		delete(cur, keys[len(keys)-1])
+	} else {
+		cur[keys[len(keys)-1]] = value
+	}
+	return doc
+}
+
+// request builds the HTTP request of op from the tool arguments
+func (b *OpenAPIBridge) request(ctx context.Context, op *openAPIOperation, args map[string]any) (*http.Request, error) {
+	p := op.path
+	query := b.baseURL.Query()
+	header := http.Header{}
+	var cookies []*http.Cookie
+	for _, param := range op.params {
+		v, ok := args[param.name]
+		if !ok || v == nil {
+			continue
+		}
+		switch param.in {
+		case "path":
+			p = strings.ReplaceAll(p, "{"+param.name+"}", url.PathEscape(paramString(v)))
+		case "query":
+			for _, s := range paramValues(v) {
+				query.Add(param.name, s)
+			}
+		case "header":
+			header.Set(param.name, paramString(v))
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: param.name, Value: paramString(v)})
+		}
+	}
+	if i := strings.Index(p, "{"); i >= 0 {
+		return nil, fmt.Errorf("missing path parameter %s", p[i:strings.IndexByte(p[i:], '}')+i+1])
+	}
+
+	// p is escaped already, so the URL is put together as text
+	u, err := url.Parse(b.baseURL.Scheme + "://" + b.baseURL.Host + strings.TrimRight(b.baseURL.EscapedPath(), "/") + p)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	body, contentType, err := encodeOpenAPIBody(op, args["body"], b.config.UploadDirs)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, op.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json, */*;q=0.5")
+	return req, nil
+}
+
+// encodeOpenAPIBody encodes the body argument in the operation's media
+// type. Multipart file fields may only name files under uploadDirs.
+func encodeOpenAPIBody(op *openAPIOperation, v any, uploadDirs []string) (io.Reader, string, error) {
+	if op.body == "" || v == nil {
+		return nil, "", nil
+	}
+	switch {
+	case op.body == "application/x-www-form-urlencoded":
+		form := url.Values{}
+		for k, val := range asMap(v) {
+			for _, s := range paramValues(val) {
+				form.Add(k, s)
+			}
+		}
+		return strings.NewReader(form.Encode()), op.body, nil
+	case op.body == "multipart/form-data":
+		return encodeMultipart(op, asMap(v), uploadDirs)
+	case strings.HasPrefix(op.body, "text/"):
+		return strings.NewReader(paramString(v)), op.body, nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), op.body, nil
+	}
+}
+
+// encodeMultipart writes the fields of a multipart body, uploading the
+// local files named by its file fields
+func encodeMultipart(op *openAPIOperation, fields map[string]any, uploadDirs []string) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range paramValues(fields[name]) {
+			if !op.files[name] {
+				if err := w.WriteField(name, value); err != nil {
+					return nil, "", err
+				}
+				continue
+			}
+			if err := addMultipartFile(w, name, value, uploadDirs); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
+
+func addMultipartFile(w *multipart.Writer, field, path string, uploadDirs []string) error {
+	resolved, err := filepath.Abs(path)
+	if err == nil {
+		resolved, err = filepath.EvalSymlinks(resolved)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %w", path, err)
+	}
+	if !inDirs(resolved, uploadDirs) {
+		return fmt.Errorf("cannot upload %s: not in an upload directory of this server", path)
+	}
+	f, err := os.Open(resolved)
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %w", path, err)
+	}
+	defer f.Close()
+	part, err := w.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
+
+// inDirs reports whether the resolved path lies in one of dirs
+func inDirs(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		root, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if r, err := filepath.EvalSymlinks(root); err == nil {
+			root = r
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// do sends req and returns the body and the next page URL of a Link header
+func (b *OpenAPIBridge) do(req *http.Request) ([]byte, string, error) {
+	b.authorize(req)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOpenAPIResponse))
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("%s %s: HTTP %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, linkNext(resp.Header.Values("Link"), req.URL), nil
+}
+
+// linkNext returns the rel="next" target of Link headers. Targets on
+// another origin than base are ignored: requests carry the credentials.
+func linkNext(links []string, base *url.URL) string {
+	for _, header := range links {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+				continue
+			}
+			next, err := base.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+			if err == nil && next.Scheme == base.Scheme && strings.EqualFold(next.Host, base.Host) {
+				return next.String()
+			}
+		}
+	}
+	return ""
+}
+
+// paramString formats a JSON value for a URL, header or form field
+func paramString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// paramValues formats each value of a list, or a single value
+func paramValues(v any) []string {
+	list, ok := v.([]any)
+	if !ok {
+		return []string{paramString(v)}
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		out[i] = paramString(item)
+	}
+	return out
+}
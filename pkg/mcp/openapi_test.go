@@ -0,0 +1,315 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petSpec = `openapi: 3.1.0
+info: {title: pets, version: "1"}
+servers:
+  - url: /v1
+components:
+  securitySchemes:
+    cc:
+      type: oauth2
+      flows:
+        clientCredentials: {tokenUrl: %s/token, scopes: {}}
+  parameters:
+    PetID: {name: petId, in: path, required: true, schema: {type: integer}}
+  schemas:
+    PetBase:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+    NewPet:
+      allOf:
+        - $ref: '#/components/schemas/PetBase'
+        - properties:
+            tag: {type: [string, "null"]}
+    Owner:
+      type: object
+      properties:
+        name: {type: string}
+        pets: {type: array, items: {$ref: '#/components/schemas/Owner'}}
+    Pet:
+      description: a pet
+      oneOf:
+        - $ref: '#/components/schemas/NewPet'
+        - {type: string}
+      discriminator: {propertyName: kind}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - {name: cursor, in: query, schema: {type: string}}
+        - {name: owner, in: query, schema: {$ref: '#/components/schemas/Owner'}}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetID'
+    get:
+      operationId: getPet
+      tags: [pets]
+  /pets/{petId}/photo:
+    post:
+      operationId: uploadPhoto
+      tags: [pets]
+      parameters:
+        - $ref: '#/components/parameters/PetID'
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                photo: {type: string, format: binary}
+                caption: {type: string}
+  /admin/reset:
+    post:
+      operationId: resetAll
+      tags: [admin]
+`
+
+// petAPI serves the pets spec, an OAuth token endpoint and the API, which
+// only answers requests with the issued token
+func petAPI(t *testing.T) (string, *int) {
+	t.Helper()
+	tokens := 0
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, petSpec, srv.URL)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "agentgo" || pass != "s3cret" {
+			http.Error(w, "bad client", http.StatusUnauthorized)
+			return
+		}
+		tokens++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "tok", "token_type": "bearer", "expires_in": 3600}`)
+	})
+	api := http.NewServeMux()
+	api.HandleFunc("GET /v1/pets", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		next := any(strconv.Itoa(page + 1))
+		if page == 2 {
+			next = nil
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data":        []string{fmt.Sprintf("pet%d", 2*page), fmt.Sprintf("pet%d", 2*page+1)},
+			"next_cursor": next,
+		})
+	})
+	api.HandleFunc("POST /v1/pets", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, `{"created": %s}`, body)
+	})
+	api.HandleFunc("GET /v1/pets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no pet "+r.PathValue("id"), http.StatusNotFound)
+	})
+	api.HandleFunc("POST /v1/pets/{id}/photo", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("photo")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		fmt.Fprintf(w, "%s %s %s %s", r.PathValue("id"), header.Filename, data, r.FormValue("caption"))
+	})
+	mux.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL, &tokens
+}
+
+func petConfig(url string) *OpenAPIConfig {
+	return &OpenAPIConfig{
+		Spec: url + "/openapi.yaml",
+		Tags: []string{"pets"},
+		Auth: &OpenAPIAuth{Type: "oauth2", ClientID: "agentgo", ClientSecret: "${PET_SECRET}"},
+	}
+}
+
+func TestOpenAPIBridgeSchemas(t *testing.T) {
+	url, _ := petAPI(t)
+	t.Setenv("PET_SECRET", "s3cret")
+	bridge, err := NewOpenAPIBridge(petConfig(url))
+	require.NoError(t, err)
+
+	tools := map[string]map[string]any{}
+	for _, tool := range bridge.Tools() {
+		tools[tool.Name] = tool.InputSchema.(map[string]any)
+	}
+	assert.Len(t, tools, 4, "resetAll is not tagged pets")
+	assert.NotContains(t, tools, "resetAll")
+
+	body := tools["createPet"]["properties"].(map[string]any)["body"].(map[string]any)
+	assert.Equal(t, "object", body["type"], "allOf is merged")
+	assert.Contains(t, body["properties"], "name")
+	assert.Contains(t, body["properties"], "tag")
+	assert.Equal(t, []any{"name"}, body["required"])
+	assert.Equal(t, []any{"body"}, toAnySlice(tools["createPet"]["required"]))
+
+	assert.Equal(t, []any{"petId"}, toAnySlice(tools["getPet"]["required"]), "path item parameters apply")
+	owner := tools["listPets"]["properties"].(map[string]any)["owner"].(map[string]any)
+	nested := owner["properties"].(map[string]any)["pets"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, "a nested Owner", nested["description"], "recursion ends")
+
+	photo := tools["uploadPhoto"]["properties"].(map[string]any)["body"].(map[string]any)["properties"].(map[string]any)["photo"]
+	assert.Equal(t, "path of a local file to upload", photo.(map[string]any)["description"])
+
+	resolver := &schemaResolver{root: map[string]any{}}
+	pet := resolver.schema(map[string]any{"type": "string", "nullable": true, "example": "x", "discriminator": map[string]any{}}, nil)
+	assert.Equal(t, map[string]any{"type": []any{"string", "null"}}, pet)
+
+	_, err = NewOpenAPIBridge(&OpenAPIConfig{Spec: url + "/openapi.yaml", Operations: []string{"nothing*"}})
+	assert.ErrorContains(t, err, "no operations")
+}
+
+func toAnySlice(v any) []any {
+	switch v := v.(type) {
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	case []any:
+		return v
+	}
+	return nil
+}
+
+func TestOpenAPIServerCalls(t *testing.T) {
+	url, tokens := petAPI(t)
+	t.Setenv("PET_SECRET", "s3cret")
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "rex.png")
+	require.NoError(t, os.WriteFile(photo, []byte("PNG"), 0o644))
+
+	outside := filepath.Join(t.TempDir(), "passwd")
+	require.NoError(t, os.WriteFile(outside, []byte("root"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link.png")))
+
+	openapi := petConfig(url)
+	openapi.UploadDirs = []string{dir}
+	cfg := DefaultConfig()
+	cfg.AddServer(&ServerConfig{Name: "pets", Type: ServerTypeOpenAPI, OpenAPI: openapi})
+	m := NewManager(&cfg)
+	defer m.Close()
+	ctx := context.Background()
+	_, err := m.StartServer(ctx, "pets")
+	require.NoError(t, err)
+
+	result, err := m.CallTool(ctx, "mcp_pets_listPets", nil)
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+	assert.JSONEq(t, `{"data": ["pet0","pet1","pet2","pet3","pet4","pet5"], "pages_fetched": 3}`, result.Data.(string))
+
+	result, err = m.CallTool(ctx, "mcp_pets_createPet", map[string]interface{}{"body": map[string]any{"name": "rex"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"created": {"name": "rex"}}`, result.Data.(string))
+
+	result, err = m.CallTool(ctx, "mcp_pets_uploadPhoto", map[string]interface{}{
+		"petId": 7, "body": map[string]any{"photo": photo, "caption": "smile"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "7 rex.png PNG smile", result.Data)
+
+	// Files outside the upload directories are not sent, symlinked or not
+	for _, p := range []string{outside, filepath.Join(dir, "link.png")} {
+		result, err = m.CallTool(ctx, "mcp_pets_uploadPhoto", map[string]interface{}{
+			"petId": 7, "body": map[string]any{"photo": p},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result.Data, "not in an upload directory", p)
+	}
+
+	result, err = m.CallTool(ctx, "mcp_pets_getPet", map[string]interface{}{"petId": 3})
+	require.NoError(t, err)
+	assert.Contains(t, result.Data, "HTTP 404: no pet 3")
+
+	assert.Equal(t, 1, *tokens, "the client credentials token is reused")
+}
+
+func TestOpenAPIPageCapAndLinks(t *testing.T) {
+	url, _ := petAPI(t)
+	t.Setenv("PET_SECRET", "s3cret")
+	cfg := petConfig(url)
+	cfg.Pagination = &OpenAPIPagination{MaxPages: 2}
+	bridge, err := NewOpenAPIBridge(cfg)
+	require.NoError(t, err)
+
+	out, err := bridge.CallTool(context.Background(), "listPets", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": ["pet0","pet1","pet2","pet3"], "next_cursor": "2", "pages_fetched": 2}`, out)
+
+	// a Swagger 2.0 API paged with Link headers and an API key in the query
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "k" {
+			http.Error(w, "no key", http.StatusUnauthorized)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 2 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/items?page=%d>; rel="next"`, api.URL, page+1))
+		}
+		fmt.Fprintf(w, `[%d]`, page)
+	}))
+	defer api.Close()
+	spec := fmt.Sprintf(`{"swagger": "2.0", "host": %q, "schemes": ["http"], "basePath": "/api",
+		"securityDefinitions": {"key": {"type": "apiKey", "name": "key", "in": "query"}},
+		"paths": {"/items": {"get": {"operationId": "items"}}}}`, api.Listener.Addr().String())
+	swagger, err := NewSwaggerServer(&SwaggerConfig{Name: "items", SwaggerData: []byte(spec), Auth: &SwaggerAuthConfig{Type: "apikey", Value: "k"}})
+	require.NoError(t, err)
+	raw, err := swagger.CallTool(context.Background(), "items", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[0, 1, 2]`, string(raw))
+
+	// A next link to another host is not followed, so the key stays home
+	leaked := false
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = true
+		fmt.Fprint(w, `[99]`)
+	}))
+	defer other.Close()
+	api.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/items?page=1>; rel="next"`, other.URL))
+		fmt.Fprint(w, `[0]`)
+	})
+	raw, err = swagger.CallTool(context.Background(), "items", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[0]`, string(raw))
+	assert.False(t, leaked)
+}
@@ -0,0 +1,454 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ToolPolicy limits how the tools of a server are called. A server's policy
+// applies to each of its tools, the entries of ServerConfig.Tools override
+// it for single tools. Zero values mean no limit.
+//
+// In mcpServers.json:
+//
+//	"github": {
+//	  "url": "https://api.githubcopilot.com/mcp/",
+//	  "policy": {"timeout": "30s", "rate_limit": 60, "max_result_bytes": 20000, "redact": ["ghp_[A-Za-z0-9]{36}"]},
+//	  "tools": {"create_issue": {"max_concurrency": 1, "validate_args": true}}
+//	}
+type ToolPolicy struct {
+	Timeout        time.Duration    `toml:"timeout" json:"timeout,omitempty" mapstructure:"timeout"`                            // Per-call timeout
+	MaxConcurrency int              `toml:"max_concurrency" json:"max_concurrency,omitempty" mapstructure:"max_concurrency"`    // Calls in flight at once; more calls wait for a slot until the timeout
+	RateLimit      int              `toml:"rate_limit" json:"rate_limit,omitempty" mapstructure:"rate_limit"`                   // Calls allowed per RatePeriod; more calls fail right away
+	RatePeriod     time.Duration    `toml:"rate_period" json:"rate_period,omitempty" mapstructure:"rate_period"`                // Defaults to one minute
+	MaxResultBytes int              `toml:"max_result_bytes" json:"max_result_bytes,omitempty" mapstructure:"max_result_bytes"` // Cap on the text result handed to the LLM
+	Truncate       TruncateStrategy `toml:"truncate" json:"truncate,omitempty" mapstructure:"truncate"`                         // What to do with results over MaxResultBytes
+	ArgsSchema     map[string]any   `toml:"args_schema" json:"args_schema,omitempty" mapstructure:"args_schema"`                // JSON Schema the arguments must satisfy
+	ValidateArgs   bool             `toml:"validate_args" json:"validate_args,omitempty" mapstructure:"validate_args"`          // Also validate against the input schema the tool advertises
+	Redact         []string         `toml:"redact" json:"redact,omitempty" mapstructure:"redact"`                               // Regexes whose matches are replaced in results
+}
+
+// TruncateStrategy says which part of an oversized result is kept
+type TruncateStrategy string
+
+const (
+	TruncateHead   TruncateStrategy = "head"   // keep the beginning (default)
+	TruncateTail   TruncateStrategy = "tail"   // keep the end
+	TruncateMiddle TruncateStrategy = "middle" // keep both ends and drop the middle
+	TruncateError  TruncateStrategy = "error"  // fail the call instead
+)
+
+// RedactedText replaces whatever a redact pattern matched
+const RedactedText = "[REDACTED]"
+
+const defaultRatePeriod = time.Minute
+
+// UnmarshalJSON accepts durations as Go duration strings ("30s") or as a
+// number of seconds
+func (p *ToolPolicy) UnmarshalJSON(data []byte) error {
+	type plain ToolPolicy
+	aux := struct {
+		*plain
+		Timeout    jsonDuration `json:"timeout,omitempty"`
+		RatePeriod jsonDuration `json:"rate_period,omitempty"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Timeout = time.Duration(aux.Timeout)
+	p.RatePeriod = time.Duration(aux.RatePeriod)
+	return nil
+}
+
+// MarshalJSON writes durations the way UnmarshalJSON reads them, so
+// policies survive rewrites of mcpServers.json
+func (p ToolPolicy) MarshalJSON() ([]byte, error) {
+	type plain ToolPolicy
+	aux := struct {
+		plain
+		Timeout    string `json:"timeout,omitempty"`
+		RatePeriod string `json:"rate_period,omitempty"`
+	}{plain: plain(p)}
+	if p.Timeout > 0 {
+		aux.Timeout = p.Timeout.String()
+	}
+	if p.RatePeriod > 0 {
+		aux.RatePeriod = p.RatePeriod.String()
+	}
+	return json.Marshal(aux)
+}
+
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	if s, err := strconv.Unquote(string(data)); err == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = jsonDuration(v)
+		return nil
+	}
+	seconds, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = jsonDuration(seconds * float64(time.Second))
+	return nil
+}
+
+func (p ToolPolicy) validate() error {
+	switch p.Truncate {
+	case "", TruncateHead, TruncateTail, TruncateMiddle, TruncateError:
+	default:
+		return fmt.Errorf("unknown truncate strategy %q (use head, tail, middle or error)", p.Truncate)
+	}
+	if p.Timeout < 0 || p.MaxConcurrency < 0 || p.RateLimit < 0 || p.RatePeriod < 0 || p.MaxResultBytes < 0 {
+		return errors.New("policy limits must not be negative")
+	}
+	for _, pattern := range p.Redact {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+	}
+	if p.ArgsSchema != nil {
+		if _, err := resolveSchema(p.ArgsSchema); err != nil {
+			return fmt.Errorf("invalid args_schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// validatePolicies checks the server policy and every tool policy
+func (s *ServerConfig) validatePolicies() error {
+	if err := s.Policy.validate(); err != nil {
+		return fmt.Errorf("server %s: %w", s.Name, err)
+	}
+	for tool, policy := range s.Tools {
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("server %s, tool %s: %w", s.Name, tool, err)
+		}
+	}
+	return nil
+}
+
+// toolPolicy merges the server policy with the one for tool. Concurrency
+// and rate limits are not merged: each level enforces its own.
+func (s *ServerConfig) toolPolicy(tool string) ToolPolicy {
+	merged := s.Policy
+	override, ok := s.Tools[tool]
+	if !ok {
+		return merged
+	}
+	if override.Timeout > 0 {
+		merged.Timeout = override.Timeout
+	}
+	if override.MaxResultBytes > 0 {
+		merged.MaxResultBytes = override.MaxResultBytes
+	}
+	if override.Truncate != "" {
+		merged.Truncate = override.Truncate
+	}
+	if override.ArgsSchema != nil {
+		merged.ArgsSchema = override.ArgsSchema
+	}
+	merged.ValidateArgs = merged.ValidateArgs || override.ValidateArgs
+	merged.Redact = append(append([]string{}, merged.Redact...), override.Redact...)
+	return merged
+}
+
+// PolicyRule names the policy a call violated
+type PolicyRule string
+
+const (
+	PolicyArguments   PolicyRule = "arguments"
+	PolicyRateLimit   PolicyRule = "rate_limit"
+	PolicyConcurrency PolicyRule = "concurrency"
+	PolicyTimeout     PolicyRule = "timeout"
+	PolicyResultSize  PolicyRule = "result_size"
+)
+
+// PolicyError reports a tool call refused or cut short by a ToolPolicy
+type PolicyError struct {
+	Server     string        `json:"server"`
+	Tool       string        `json:"tool"`
+	Rule       PolicyRule    `json:"rule"`
+	Detail     string        `json:"detail"`
+	RetryAfter time.Duration `json:"retry_after,omitempty"` // set for rate limits
+}
+
+func (e *PolicyError) Error() string {
+	msg := fmt.Sprintf("MCP tool %s on server %s violated the %s policy: %s", e.Tool, e.Server, e.Rule, e.Detail)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter.Round(time.Millisecond))
+	}
+	return msg
+}
+
+// policyEnforcer keeps the rate limit and concurrency state of the policies
+type policyEnforcer struct {
+	mu       sync.Mutex
+	limits   map[string]*callLimit
+	patterns map[string]*regexp.Regexp
+	now      func() time.Time // refills the rate buckets, replaced in tests
+}
+
+// callLimit is the state of one server or tool level limit. It is rebuilt
+// when the limits in the policy change.
+type callLimit struct {
+	maxConcurrency int
+	rateLimit      int
+	ratePeriod     time.Duration
+
+	slots chan struct{}
+
+	tokens float64
+	last   time.Time
+}
+
+func newPolicyEnforcer() *policyEnforcer {
+	return &policyEnforcer{
+		limits:   make(map[string]*callLimit),
+		patterns: make(map[string]*regexp.Regexp),
+		now:      time.Now,
+	}
+}
+
+// call runs fn under the policy of tool on server. inputSchema is the schema
+// the tool advertises, used when the policy asks to validate arguments.
+func (e *policyEnforcer) call(ctx context.Context, server *ServerConfig, tool string, inputSchema any, args map[string]interface{}, fn func(ctx context.Context) error) error {
+	policy := server.toolPolicy(tool)
+	fail := func(rule PolicyRule, detail string) *PolicyError {
+		return &PolicyError{Server: server.Name, Tool: tool, Rule: rule, Detail: detail}
+	}
+
+	if err := validateArgs(policy, inputSchema, args); err != nil {
+		return fail(PolicyArguments, err.Error())
+	}
+
+	levels := []struct {
+		key    string
+		policy ToolPolicy
+	}{
+		{server.Name, server.Policy},
+		{server.Name + "/" + tool, server.Tools[tool]},
+	}
+	var limits []*callLimit
+	for _, level := range levels {
+		if level.policy.MaxConcurrency == 0 && level.policy.RateLimit == 0 {
+			continue
+		}
+		limits = append(limits, e.limit(level.key, level.policy))
+	}
+	if limit, wait := e.take(limits); wait > 0 {
+		err := fail(PolicyRateLimit, fmt.Sprintf("more than %d calls per %s", limit.rateLimit, limit.ratePeriod))
+		err.RetryAfter = wait
+		return err
+	}
+
+	parent := ctx
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+	// only our own deadline is a policy violation, not the caller's
+	timedOut := func() bool {
+		return policy.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil
+	}
+
+	for _, limit := range limits {
+		if limit.slots == nil {
+			continue
+		}
+		select {
+		case limit.slots <- struct{}{}:
+			defer func() { <-limit.slots }()
+		case <-ctx.Done():
+			if timedOut() {
+				return fail(PolicyConcurrency, fmt.Sprintf("no free slot among %d within %s", limit.maxConcurrency, policy.Timeout))
+			}
+			return ctx.Err()
+		}
+	}
+
+	err := fn(ctx)
+	if timedOut() {
+		return fail(PolicyTimeout, fmt.Sprintf("no result within %s", policy.Timeout))
+	}
+	return err
+}
+
+func (e *policyEnforcer) limit(key string, policy ToolPolicy) *callLimit {
+	period := policy.RatePeriod
+	if period == 0 {
+		period = defaultRatePeriod
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	limit, ok := e.limits[key]
+	if ok && limit.maxConcurrency == policy.MaxConcurrency && limit.rateLimit == policy.RateLimit && limit.ratePeriod == period {
+		return limit
+	}
+	limit = &callLimit{
+		maxConcurrency: policy.MaxConcurrency,
+		rateLimit:      policy.RateLimit,
+		ratePeriod:     period,
+		tokens:         float64(policy.RateLimit),
+		last:           e.now(),
+	}
+	if policy.MaxConcurrency > 0 {
+		limit.slots = make(chan struct{}, policy.MaxConcurrency)
+	}
+	e.limits[key] = limit
+	return limit
+}
+
+// take removes a token from the bucket of every limit, or from none of
+// them: a call refused at one level must not use up the others. When a
+// bucket is empty it returns that limit and how long to wait for its next
+// token.
+func (e *policyEnforcer) take(limits []*callLimit) (*callLimit, time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	for _, limit := range limits {
+		if limit.rateLimit == 0 {
+			continue
+		}
+		perToken := limit.ratePeriod / time.Duration(limit.rateLimit)
+		limit.tokens += float64(now.Sub(limit.last)) / float64(perToken)
+		limit.tokens = min(limit.tokens, float64(limit.rateLimit))
+		limit.last = now
+		if limit.tokens < 1 {
+			return limit, time.Duration((1 - limit.tokens) * float64(perToken))
+		}
+	}
+	for _, limit := range limits {
+		if limit.rateLimit > 0 {
+			limit.tokens--
+		}
+	}
+	return nil, 0
+}
+
+// filterText redacts and caps a text result of tool on server
+func (e *policyEnforcer) filterText(server *ServerConfig, tool, text string) (string, error) {
+	policy := server.toolPolicy(tool)
+	for _, pattern := range policy.Redact {
+		re, err := e.pattern(pattern)
+		if err != nil {
+			return "", err
+		}
+		text = re.ReplaceAllString(text, RedactedText)
+	}
+	if policy.MaxResultBytes == 0 || len(text) <= policy.MaxResultBytes {
+		return text, nil
+	}
+	if policy.Truncate == TruncateError {
+		return "", &PolicyError{Server: server.Name, Tool: tool, Rule: PolicyResultSize,
+			Detail: fmt.Sprintf("result is %d bytes, the limit is %d", len(text), policy.MaxResultBytes)}
+	}
+	return truncateText(text, policy.MaxResultBytes, policy.Truncate), nil
+}
+
+func (e *policyEnforcer) pattern(pattern string) (*regexp.Regexp, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if re, ok := e.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+	}
+	e.patterns[pattern] = re
+	return re, nil
+}
+
+// truncateText keeps at most max bytes of text, cut at rune boundaries, and
+// marks where text was dropped
+func truncateText(text string, max int, strategy TruncateStrategy) string {
+	dropped := func(n int) string { return fmt.Sprintf("[... %d bytes truncated ...]", n) }
+	switch strategy {
+	case TruncateTail:
+		start := runeAfter(text, len(text)-max)
+		return dropped(start) + "\n" + text[start:]
+	case TruncateMiddle:
+		head := runeBefore(text, max/2)
+		tail := runeAfter(text, len(text)-(max-head))
+		return text[:head] + "\n" + dropped(tail-head) + "\n" + text[tail:]
+	default:
+		end := runeBefore(text, max)
+		return text[:end] + "\n" + dropped(len(text)-end)
+	}
+}
+
+// runeBefore moves i back to the start of the rune it falls in
+func runeBefore(text string, i int) int {
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
+
+// runeAfter moves i forward to the start of the next rune
+func runeAfter(text string, i int) int {
+	for i < len(text) && !utf8.RuneStart(text[i]) {
+		i++
+	}
+	return i
+}
+
+func validateArgs(policy ToolPolicy, inputSchema any, args map[string]interface{}) error {
+	var schemas []any
+	if policy.ArgsSchema != nil {
+		schemas = append(schemas, policy.ArgsSchema)
+	}
+	if policy.ValidateArgs && inputSchema != nil {
+		schemas = append(schemas, inputSchema)
+	}
+	if len(schemas) == 0 {
+		return nil
+	}
+	instance := map[string]any{}
+	for k, v := range args {
+		instance[k] = v
+	}
+	for _, schema := range schemas {
+		resolved, err := resolveSchema(schema)
+		if err != nil {
+			return fmt.Errorf("unusable argument schema: %w", err)
+		}
+		if err := resolved.Validate(instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSchema turns a JSON Schema in any JSON-compatible form into one
+// that can validate values
+func resolveSchema(schema any) (*jsonschema.Resolved, error) {
+	s, ok := schema.(*jsonschema.Schema)
+	if !ok {
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+		s = &jsonschema.Schema{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+	}
+	return s.Resolve(nil)
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyServer serves the echo tool and a "hang" tool that only returns
+// when its call is cancelled
+func policyServer(t *testing.T) string {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "policy-test", Version: "1.0.0"}, nil)
+	tool, handler := echoTool("echo")
+	mcp.AddTool(server, tool, handler)
+	server.AddTool(&mcp.Tool{Name: "hang", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func policyError(t *testing.T, err error) *PolicyError {
+	t.Helper()
+	var policyErr *PolicyError
+	require.True(t, errors.As(err, &policyErr), "want a PolicyError, got %v", err)
+	return policyErr
+}
+
+func TestLoadServerPolicies(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcpServers.json")
+	writeServers(t, path, `{"mcpServers": {"gh": {
+		"url": "http://localhost/mcp",
+		"policy": {"timeout": "30s", "rate_limit": 60, "max_result_bytes": 1000, "truncate": "middle", "redact": ["ghp_\\w+"]},
+		"tools": {"create_issue": {"timeout": 5, "max_concurrency": 1, "redact": ["sk-\\w+"], "args_schema": {"type": "object", "required": ["title"]}}}
+	}}}`)
+	cfg := DefaultConfig()
+	cfg.Servers = []string{path}
+	require.NoError(t, cfg.LoadServersFromJSON())
+
+	var server ServerConfig
+	for _, s := range cfg.GetLoadedServers() {
+		if s.Name == "gh" {
+			server = s
+		}
+	}
+	assert.Equal(t, 30*time.Second, server.Policy.Timeout)
+	assert.Equal(t, 5*time.Second, server.Tools["create_issue"].Timeout, "numbers are seconds")
+
+	merged := server.toolPolicy("create_issue")
+	assert.Equal(t, 5*time.Second, merged.Timeout)
+	assert.Equal(t, 1000, merged.MaxResultBytes)
+	assert.Equal(t, TruncateMiddle, merged.Truncate)
+	assert.Equal(t, []string{`ghp_\w+`, `sk-\w+`}, merged.Redact)
+	assert.NotNil(t, merged.ArgsSchema)
+	assert.Equal(t, server.Policy, server.toolPolicy("list_issues"))
+
+	writeServers(t, path, `{"mcpServers": {"gh": {"url": "http://localhost/mcp", "policy": {"redact": ["("]}}}}`)
+	assert.ErrorContains(t, cfg.LoadServersFromJSON(), "invalid redact pattern")
+	writeServers(t, path, `{"mcpServers": {"gh": {"url": "http://localhost/mcp", "tools": {"x": {"truncate": "sideways"}}}}}`)
+	assert.ErrorContains(t, cfg.LoadServersFromJSON(), "unknown truncate strategy")
+}
+
+func TestManagerCallToolPolicies(t *testing.T) {
+	url := policyServer(t)
+	cfg := DefaultConfig()
+	cfg.AddServer(&ServerConfig{
+		Name:   "p",
+		Type:   ServerTypeHTTP,
+		URL:    url,
+		Policy: ToolPolicy{Redact: []string{`ghp_\w+`}, MaxResultBytes: 40},
+		Tools: map[string]ToolPolicy{
+			"echo": {
+				RateLimit:  3,
+				RatePeriod: time.Hour,
+				ArgsSchema: map[string]any{"type": "object", "required": []any{"text"}},
+			},
+			"hang": {Timeout: 50 * time.Millisecond},
+		},
+	})
+	m := NewManager(&cfg)
+	defer m.Close()
+	ctx := context.Background()
+	_, err := m.StartServer(ctx, "p")
+	require.NoError(t, err)
+
+	result, err := m.CallTool(ctx, "mcp_p_echo", map[string]interface{}{"text": "token ghp_abc123 here"})
+	require.NoError(t, err)
+	assert.Equal(t, "token [REDACTED] here", result.Data)
+
+	_, err = m.CallTool(ctx, "mcp_p_echo", map[string]interface{}{"txt": "typo"})
+	assert.Equal(t, PolicyArguments, policyError(t, err).Rule)
+
+	result, err = m.CallTool(ctx, "mcp_p_echo", map[string]interface{}{"text": strings.Repeat("a", 100)})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Data.(string), strings.Repeat("a", 40)+"\n"))
+	assert.Contains(t, result.Data, "[... 60 bytes truncated ...]")
+
+	_, err = m.CallTool(ctx, "mcp_p_echo", map[string]interface{}{"text": "three"})
+	require.NoError(t, err)
+	_, err = m.CallTool(ctx, "mcp_p_echo", map[string]interface{}{"text": "four"})
+	limited := policyError(t, err)
+	assert.Equal(t, PolicyRateLimit, limited.Rule)
+	assert.Equal(t, "p", limited.Server)
+	assert.Equal(t, "echo", limited.Tool)
+	assert.Greater(t, limited.RetryAfter, time.Duration(0))
+
+	start := time.Now()
+	_, err = m.CallTool(ctx, "mcp_p_hang", nil)
+	assert.Equal(t, PolicyTimeout, policyError(t, err).Rule)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestPolicyConcurrency(t *testing.T) {
+	e := newPolicyEnforcer()
+	// only b waits with a timeout, so the outcome of every call is fixed
+	// however slow the scheduler is
+	server := &ServerConfig{
+		Name:   "s",
+		Policy: ToolPolicy{MaxConcurrency: 1},
+		Tools:  map[string]ToolPolicy{"b": {Timeout: 50 * time.Millisecond}},
+	}
+	ctx := context.Background()
+
+	running, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- e.call(ctx, server, "a", nil, nil, func(context.Context) error {
+			close(running)
+			<-release
+			return nil
+		})
+	}()
+	<-running
+
+	calls := 0
+	err := e.call(ctx, server, "b", nil, nil, func(context.Context) error { calls++; return nil })
+	assert.Equal(t, PolicyConcurrency, policyError(t, err).Rule, "the server-wide slot is shared by its tools")
+	assert.Zero(t, calls)
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.NoError(t, e.call(ctx, server, "c", nil, nil, func(context.Context) error { calls++; return nil }))
+	assert.Equal(t, 1, calls)
+}
+
+func TestPolicyRateLimitLevels(t *testing.T) {
+	e := newPolicyEnforcer()
+	now := time.Unix(0, 0)
+	e.now = func() time.Time { return now }
+	server := &ServerConfig{
+		Name:   "s",
+		Policy: ToolPolicy{RateLimit: 2, RatePeriod: time.Minute},
+		Tools:  map[string]ToolPolicy{"a": {RateLimit: 1, RatePeriod: time.Minute}},
+	}
+	ctx := context.Background()
+	calls := 0
+	call := func(tool string) error {
+		return e.call(ctx, server, tool, nil, nil, func(context.Context) error { calls++; return nil })
+	}
+
+	require.NoError(t, call("a"))
+	limited := policyError(t, call("a"))
+	assert.Equal(t, PolicyRateLimit, limited.Rule)
+	assert.Equal(t, time.Minute, limited.RetryAfter)
+	require.NoError(t, call("b"), "the refused call to a left the server token alone")
+	limited = policyError(t, call("b"))
+	assert.Equal(t, 30*time.Second, limited.RetryAfter)
+	assert.Equal(t, 2, calls)
+
+	now = now.Add(30 * time.Second)
+	require.NoError(t, call("b"))
+	assert.Equal(t, 3, calls)
+}
+
+func TestPolicyResultSizeError(t *testing.T) {
+	e := newPolicyEnforcer()
+	server := &ServerConfig{Name: "s", Tools: map[string]ToolPolicy{"big": {MaxResultBytes: 4, Truncate: TruncateError}}}
+
+	_, err := e.filterText(server, "big", "too long")
+	assert.Equal(t, PolicyResultSize, policyError(t, err).Rule)
+	text, err := e.filterText(server, "small", "too long")
+	require.NoError(t, err)
+	assert.Equal(t, "too long", text)
+}
+
+func TestTruncateText(t *testing.T) {
+	text := "héllo wörld"
+	assert.Equal(t, "h\n[... 12 bytes truncated ...]", truncateText(text, 2, TruncateHead), "cut before the two-byte é")
+	assert.Equal(t, "[... 10 bytes truncated ...]\nrld", truncateText(text, 4, TruncateTail), "cut after the two-byte ö")
+	assert.Equal(t, "hé\n[... 7 bytes truncated ...]\nrld", truncateText(text, 7, TruncateMiddle))
+}
@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SubscribeResource asks the server to report changes to uri. Servers that
+// do not support subscriptions return an error.
+func (c *Client) SubscribeResource(ctx context.Context, uri string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+	init := c.session.InitializeResult()
+	if init == nil || init.Capabilities == nil || init.Capabilities.Resources == nil || !init.Capabilities.Resources.Subscribe {
+		return fmt.Errorf("server %s does not support resource subscriptions", c.config.Name)
+	}
+	return c.session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri})
+}
+
+// UnsubscribeResource stops the updates SubscribeResource asked for
+func (c *Client) UnsubscribeResource(ctx context.Context, uri string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+	return c.session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: uri})
+}
+
+// ListResources returns the resources of the running servers, ordered by
+// server and URI
+func (m *Manager) ListResources() []ResourceInfo {
+	var out []ResourceInfo
+	for name, client := range m.ListClients() {
+		for _, r := range client.GetResources() {
+			out = append(out, ResourceInfo{
+				ServerName:  originOr(r.Meta, name),
+				URI:         r.URI,
+				Name:        r.Name,
+				Title:       r.Title,
+				Description: r.Description,
+				MIMEType:    r.MIMEType,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ServerName != out[j].ServerName {
+			return out[i].ServerName < out[j].ServerName
+		}
+		return out[i].URI < out[j].URI
+	})
+	return out
+}
+
+// ListResourceTemplates returns the resource templates of the running servers
+func (m *Manager) ListResourceTemplates() []ResourceTemplateInfo {
+	var out []ResourceTemplateInfo
+	for name, client := range m.ListClients() {
+		for _, t := range client.GetResourceTemplates() {
+			out = append(out, ResourceTemplateInfo{
+				ServerName:  originOr(t.Meta, name),
+				URITemplate: t.URITemplate,
+				Name:        t.Name,
+				Title:       t.Title,
+				Description: t.Description,
+				MIMEType:    t.MIMEType,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ServerName != out[j].ServerName {
+			return out[i].ServerName < out[j].ServerName
+		}
+		return out[i].URITemplate < out[j].URITemplate
+	})
+	return out
+}
+
+// ListPrompts returns the prompts of the running servers, ordered by server
+// and name
+func (m *Manager) ListPrompts() []PromptInfo {
+	var out []PromptInfo
+	for name, client := range m.ListClients() {
+		for _, p := range client.GetPrompts() {
+			info := PromptInfo{ServerName: originOr(p.Meta, name), Name: p.Name, Title: p.Title, Description: p.Description}
+			for _, a := range p.Arguments {
+				info.Arguments = append(info.Arguments, PromptArgumentInfo{Name: a.Name, Description: a.Description, Required: a.Required})
+			}
+			out = append(out, info)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ServerName != out[j].ServerName {
+			return out[i].ServerName < out[j].ServerName
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// ReadResource reads uri from server. With no server, the running server
+// that lists the resource is used.
+func (m *Manager) ReadResource(ctx context.Context, server, uri string) (*ResourceContent, error) {
+	client, server, err := m.resourceClient(server, uri)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	res.ServerName = server
+	return res, nil
+}
+
+// SubscribeResource subscribes to changes of uri on server. Updates are
+// reported to OnCatalogChange listeners with the CatalogUpdated reason.
+func (m *Manager) SubscribeResource(ctx context.Context, server, uri string) error {
+	client, _, err := m.resourceClient(server, uri)
+	if err != nil {
+		return err
+	}
+	return client.SubscribeResource(ctx, uri)
+}
+
+// resourceClient returns the client serving uri and the server it belongs to
+func (m *Manager) resourceClient(server, uri string) (*Client, string, error) {
+	if server == "" {
+		for _, r := range m.ListResources() {
+			if r.URI == uri {
+				server = r.ServerName
+				break
+			}
+		}
+		if server == "" {
+			return nil, "", fmt.Errorf("no running MCP server lists resource %s", uri)
+		}
+	}
+	client, err := m.clientFor(server)
+	return client, server, err
+}
+
+// clientFor returns the client of a running server. Through a gateway every
+// server is reached over the gateway's client.
+func (m *Manager) clientFor(server string) (*Client, error) {
+	if client, ok := m.GetClient(server); ok {
+		return client, nil
+	}
+	if m.config.Gateway.URL != "" {
+		if client, ok := m.GetClient(GatewayServerName); ok {
+			return client, nil
+		}
+	}
+	return nil, fmt.Errorf("MCP server %s is not running", server)
+}
+
+// originOr is the server a gateway proxied item comes from, or server
+func originOr(meta mcp.Meta, server string) string {
+	if origin := originServer(meta); origin != "" {
+		return origin
+	}
+	return server
+}
+
+// GetPrompt renders prompt name from server with args. With no server, the
+// running server that offers the prompt is used.
+func (m *Manager) GetPrompt(ctx context.Context, server, name string, args map[string]string) (*PromptContent, error) {
+	if server == "" {
+		for _, p := range m.ListPrompts() {
+			if p.Name == name {
+				server = p.ServerName
+				break
+			}
+		}
+		if server == "" {
+			return nil, fmt.Errorf("no running MCP server offers prompt %s", name)
+		}
+	}
+	client, err := m.clientFor(server)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := client.GetPrompt(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	prompt.ServerName = server
+	return prompt, nil
+}
+
+// IngestResource reads a text resource and ingests it into the RAG
+// knowledge base through proc. The chunks keep the server and URI as their
+// source.
+func (s *Service) IngestResource(ctx context.Context, proc domain.Processor, server, uri string) (domain.IngestResponse, error) {
+	client, server, err := s.manager.resourceClient(server, uri)
+	if err != nil {
+		return domain.IngestResponse{}, err
+	}
+	res, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return domain.IngestResponse{}, err
+	}
+	text, ok := res.Content.(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return domain.IngestResponse{}, fmt.Errorf("resource %s has no text content to ingest", uri)
+	}
+	return proc.Ingest(ctx, domain.IngestRequest{
+		Content: text,
+		Metadata: map[string]interface{}{
+			"source":     uri,
+			"type":       "mcp_resource",
+			"mcp_server": server,
+			"mime_type":  res.MIMEType,
+		},
+	})
+}
+
+// Text returns the resource as text; binary content is described instead
+func (r *ResourceContent) Text() string {
+	switch v := r.Content.(type) {
+	case string:
+		return v
+	case []byte:
+		return fmt.Sprintf("[binary %s, %d bytes]", r.MIMEType, len(v))
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Text renders the prompt messages as "role: text" lines
+func (p *PromptContent) Text() string {
+	var sb strings.Builder
+	for i, msg := range p.Messages {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(msg.Role)
+		sb.WriteString(": ")
+		switch c := msg.Content.(type) {
+		case *mcp.TextContent:
+			sb.WriteString(c.Text)
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil {
+				sb.WriteString(c.Resource.Text)
+			}
+		default:
+			fmt.Fprintf(&sb, "%v", c)
+		}
+	}
+	return sb.String()
+}
+
+// ResourceMention is an @server:uri reference in user input
+type ResourceMention struct {
+	Raw    string
+	Server string
+	URI    string
+}
+
+var mentionPattern = regexp.MustCompile(`(^|\s)@([A-Za-z0-9_.-]+):(\S+)`)
+
+// ParseResourceMentions finds @server:uri references in text. Trailing
+// punctuation is not part of the URI.
+func ParseResourceMentions(text string) []ResourceMention {
+	var out []ResourceMention
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		uri := strings.TrimRight(m[3], ".,;!?)")
+		if uri == "" {
+			continue
+		}
+		out = append(out, ResourceMention{Raw: "@" + m[2] + ":" + uri, Server: m[2], URI: uri})
+	}
+	return out
+}
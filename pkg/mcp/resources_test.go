@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resourceServer serves one subscribable text resource and one prompt
+func resourceServer(t *testing.T) (*mcp.Server, string) {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "resource-test", Version: "1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+	})
+	server.AddResource(&mcp.Resource{URI: "notes://todo", Name: "todo", MIMEType: "text/plain"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, MIMEType: "text/plain", Text: "buy milk"}}}, nil
+		})
+	server.AddPrompt(&mcp.Prompt{Name: "review", Arguments: []*mcp.PromptArgument{{Name: "topic", Required: true}}},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: "Review " + req.Params.Arguments["topic"]}},
+			}}, nil
+		})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(srv.Close)
+	return server, srv.URL
+}
+
+type ingestRecorder struct {
+	domain.Processor
+	req domain.IngestRequest
+}
+
+func (r *ingestRecorder) Ingest(ctx context.Context, req domain.IngestRequest) (domain.IngestResponse, error) {
+	r.req = req
+	return domain.IngestResponse{Success: true, DocumentID: "doc-1", ChunkCount: 1}, nil
+}
+
+func TestManagerResourcesAndPrompts(t *testing.T) {
+	server, url := resourceServer(t)
+	cfg := DefaultConfig()
+	cfg.AddServer(&ServerConfig{Name: "notes", Type: ServerTypeHTTP, URL: url})
+	m := NewManager(&cfg)
+	defer m.Close()
+	ctx := context.Background()
+
+	_, err := m.StartServer(ctx, "notes")
+	require.NoError(t, err)
+
+	resources := m.ListResources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, "notes", resources[0].ServerName)
+
+	res, err := m.ReadResource(ctx, "", "notes://todo")
+	require.NoError(t, err, "server looked up by URI")
+	assert.Equal(t, "notes", res.ServerName)
+	assert.Equal(t, "buy milk", res.Text())
+
+	_, err = m.ReadResource(ctx, "", "notes://missing")
+	assert.ErrorContains(t, err, "no running MCP server")
+
+	prompts := m.ListPrompts()
+	require.Len(t, prompts, 1)
+	assert.True(t, prompts[0].Arguments[0].Required)
+	prompt, err := m.GetPrompt(ctx, "", "review", map[string]string{"topic": "the plan"})
+	require.NoError(t, err)
+	assert.Equal(t, "notes", prompt.ServerName)
+	assert.Equal(t, "user: Review the plan", prompt.Text())
+
+	events, remove := collectEvents(m, CatalogResources)
+	defer remove()
+	require.NoError(t, m.SubscribeResource(ctx, "notes", "notes://todo"))
+	require.NoError(t, server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "notes://todo"}))
+	assert.Equal(t, CatalogEvent{Server: "notes", Kind: CatalogResources, Reason: CatalogUpdated, URI: "notes://todo"}, nextEvent(t, events))
+
+	proc := &ingestRecorder{}
+	resp, err := (&Service{manager: m}).IngestResource(ctx, proc, "", "notes://todo")
+	require.NoError(t, err)
+	assert.Equal(t, "doc-1", resp.DocumentID)
+	assert.Equal(t, "buy milk", proc.req.Content)
+	assert.Equal(t, "notes", proc.req.Metadata["mcp_server"])
+	assert.Equal(t, "notes://todo", proc.req.Metadata["source"])
+}
+
+func TestSubscribeResourceUnsupported(t *testing.T) {
+	_, url := catalogServer(t)
+	cfg := DefaultConfig()
+	cfg.AddServer(&ServerConfig{Name: "plain", Type: ServerTypeHTTP, URL: url})
+	m := NewManager(&cfg)
+	defer m.Close()
+
+	_, err := m.StartServer(context.Background(), "plain")
+	require.NoError(t, err)
+	assert.ErrorContains(t, m.SubscribeResource(context.Background(), "plain", "notes://todo"), "does not support resource subscriptions")
+}
+
+func TestParseResourceMentions(t *testing.T) {
+	mentions := ParseResourceMentions("compare @notes:notes://todo and @files:docs/plan.md, not me@example.com")
+	assert.Equal(t, []ResourceMention{
+		{Raw: "@notes:notes://todo", Server: "notes", URI: "notes://todo"},
+		{Raw: "@files:docs/plan.md", Server: "files", URI: "docs/plan.md"},
+	}, mentions)
+	assert.Empty(t, ParseResourceMentions("@Coder write tests"))
+}
@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/pool"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SamplingConfig controls whether MCP servers may borrow AgentGo's LLMs
+// through sampling/createMessage
+type SamplingConfig struct {
+	Enabled bool `toml:"enabled" json:"enabled" mapstructure:"enabled"`
+	// AllowServers limits sampling to these servers (empty = every server)
+	AllowServers []string `toml:"allow_servers" json:"allow_servers" mapstructure:"allow_servers"`
+	// DenyServers never get sampling, even when allowed above
+	DenyServers []string `toml:"deny_servers" json:"deny_servers" mapstructure:"deny_servers"`
+	// MaxTokens caps the completion length of a single request (0 = no cap)
+	MaxTokens int `toml:"max_tokens" json:"max_tokens" mapstructure:"max_tokens"`
+	// MaxTokensPerServer is the estimated token budget each server may spend
+	// while the process runs (0 = unlimited)
+	MaxTokensPerServer int `toml:"max_tokens_per_server" json:"max_tokens_per_server" mapstructure:"max_tokens_per_server"`
+	// RequireApproval asks the user before every request
+	RequireApproval bool `toml:"require_approval" json:"require_approval" mapstructure:"require_approval"`
+}
+
+// DefaultSamplingConfig returns the default sampling configuration
+func DefaultSamplingConfig() SamplingConfig {
+	return SamplingConfig{
+		Enabled:         false,
+		MaxTokens:       2048,
+		RequireApproval: true,
+	}
+}
+
+// SamplingRequest is a server's sampling request after translation
+type SamplingRequest struct {
+	Server    string
+	Messages  []domain.Message
+	MaxTokens int
+	Hint      pool.SelectionHint
+}
+
+// SamplingModels resolves a generator for a selection hint. It is satisfied
+// by services.GlobalPoolService.
+type SamplingModels interface {
+	GetLLMServiceWithHint(hint pool.SelectionHint) (domain.Generator, error)
+}
+
+// SamplingApprover asks the user whether a sampling request may run
+type SamplingApprover func(ctx context.Context, req *SamplingRequest) (bool, error)
+
+// SamplingUsageRecorder attributes a completed sampling request to the
+// server that asked for it
+type SamplingUsageRecorder func(ctx context.Context, server, model, input, output string, start time.Time)
+
+// SamplingHandler answers sampling/createMessage requests from MCP servers
+// with the AgentGo LLM pool
+type SamplingHandler struct {
+	cfg    SamplingConfig
+	models SamplingModels
+
+	mu      sync.Mutex
+	approve SamplingApprover
+	record  SamplingUsageRecorder
+	spent   map[string]int
+}
+
+// NewSamplingHandler creates a sampling handler backed by models
+func NewSamplingHandler(cfg SamplingConfig, models SamplingModels) *SamplingHandler {
+	return &SamplingHandler{
+		cfg:    cfg,
+		models: models,
+		spent:  make(map[string]int),
+	}
+}
+
+// SetApprover sets the approval callback used when RequireApproval is on
+func (h *SamplingHandler) SetApprover(approve SamplingApprover) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.approve = approve
+}
+
+// SetUsageRecorder sets where completed requests are recorded
+func (h *SamplingHandler) SetUsageRecorder(record SamplingUsageRecorder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.record = record
+}
+
+// Allows reports whether server may use sampling at all
+func (h *SamplingHandler) Allows(server string) bool {
+	if h == nil || !h.cfg.Enabled {
+		return false
+	}
+	if containsFold(h.cfg.DenyServers, server) {
+		return false
+	}
+	return len(h.cfg.AllowServers) == 0 || containsFold(h.cfg.AllowServers, server)
+}
+
+// Spent returns the estimated tokens server has used so far, counting
+// requests in flight at their reserved size
+func (h *SamplingHandler) Spent(server string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.spent[server]
+}
+
+// HandlerFor returns the SDK CreateMessageHandler for one server, or nil
+// when the server may not sample (so the capability is not advertised)
+func (h *SamplingHandler) HandlerFor(server string) func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	if !h.Allows(server) {
+		return nil
+	}
+	return func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return h.CreateMessage(ctx, server, req.Params)
+	}
+}
+
+// CreateMessage runs one sampling request on behalf of server
+func (h *SamplingHandler) CreateMessage(ctx context.Context, server string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	if !h.Allows(server) {
+		return nil, fmt.Errorf("sampling is not allowed for MCP server %s", server)
+	}
+	if params == nil {
+		return nil, fmt.Errorf("sampling request from %s has no parameters", server)
+	}
+
+	req, err := h.translate(server, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve the prompt and the longest allowed completion up front, so
+	// concurrent requests cannot overspend the budget together
+	input := samplingTranscript(req.Messages)
+	reserved := estimateTokens(input) + req.MaxTokens
+	used := 0
+	h.mu.Lock()
+	approve, record := h.approve, h.record
+	if limit := h.cfg.MaxTokensPerServer; limit > 0 && h.spent[server]+reserved > limit {
+		h.mu.Unlock()
+		return nil, fmt.Errorf("sampling request from MCP server %s would exceed its budget of %d tokens", server, limit)
+	}
+	h.spent[server] += reserved
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.spent[server] += used - reserved
+		h.mu.Unlock()
+	}()
+	if h.cfg.RequireApproval {
+		if approve == nil {
+			return nil, fmt.Errorf("sampling for MCP server %s requires approval but no approver is available", server)
+		}
+		ok, err := approve(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("sampling request from MCP server %s was declined", server)
+		}
+	}
+
+	llm, err := h.models.GetLLMServiceWithHint(req.Hint)
+	if err != nil {
+		return nil, fmt.Errorf("no LLM available for sampling: %w", err)
+	}
+
+	start := time.Now()
+	opts := &domain.GenerationOptions{MaxTokens: req.MaxTokens, Temperature: params.Temperature}
+	result, err := llm.GenerateWithTools(ctx, req.Messages, nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("sampling failed: %w", err)
+	}
+
+	model := ""
+	if named, ok := llm.(interface{ GetModelName() string }); ok {
+		model = named.GetModelName()
+	}
+
+	used = estimateTokens(input) + estimateTokens(result.Content)
+	if record != nil {
+		record(ctx, server, model, input, result.Content, start)
+	}
+
+	return &mcp.CreateMessageResult{
+		Content:    &mcp.TextContent{Text: result.Content},
+		Model:      model,
+		Role:       "assistant",
+		StopReason: "endTurn",
+	}, nil
+}
+
+func (h *SamplingHandler) translate(server string, params *mcp.CreateMessageParams) (*SamplingRequest, error) {
+	req := &SamplingRequest{
+		Server:    server,
+		MaxTokens: int(params.MaxTokens),
+		Hint:      SelectionHintFor(params.ModelPreferences),
+	}
+	if limit := h.cfg.MaxTokens; limit > 0 && (req.MaxTokens <= 0 || req.MaxTokens > limit) {
+		req.MaxTokens = limit
+	}
+
+	if params.SystemPrompt != "" {
+		req.Messages = append(req.Messages, domain.Message{Role: "system", Content: params.SystemPrompt})
+	}
+	for _, msg := range params.Messages {
+		text, ok := msg.Content.(*mcp.TextContent)
+		if !ok {
+			return nil, fmt.Errorf("sampling request from %s contains unsupported %T content", server, msg.Content)
+		}
+		req.Messages = append(req.Messages, domain.Message{Role: string(msg.Role), Content: text.Text})
+	}
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("sampling request from %s has no messages", server)
+	}
+	return req, nil
+}
+
+// SelectionHintFor maps MCP model preferences onto a pool selection hint.
+// The first model hint names a preferred provider or model, and a high
+// intelligence priority asks for a more capable provider.
+func SelectionHintFor(prefs *mcp.ModelPreferences) pool.SelectionHint {
+	hint := pool.SelectionHint{}
+	if prefs == nil {
+		return hint
+	}
+	for _, h := range prefs.Hints {
+		if h != nil && strings.TrimSpace(h.Name) != "" {
+			hint.PreferredProvider = strings.TrimSpace(h.Name)
+			hint.PreferredModel = strings.TrimSpace(h.Name)
+			break
+		}
+	}
+	if prefs.IntelligencePriority > prefs.CostPriority && prefs.IntelligencePriority > prefs.SpeedPriority {
+		hint.MinCapability = int(math.Round(prefs.IntelligencePriority * 5))
+	}
+	return hint
+}
+
+func samplingTranscript(messages []domain.Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		b.WriteString(m.Content)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// estimateTokens is a rough four-characters-per-token estimate used for
+// per-server budgets
+func estimateTokens(s string) int {
+	return (len([]rune(s)) + 3) / 4
+}
+
+func containsFold(list []string, s string) bool {
+	return slices.ContainsFunc(list, func(v string) bool { return strings.EqualFold(v, s) })
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/pool"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type samplingGenerator struct {
+	domain.Generator
+	messages []domain.Message
+	opts     *domain.GenerationOptions
+}
+
+func (g *samplingGenerator) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	g.messages = messages
+	g.opts = opts
+	return &domain.GenerationResult{Content: "sampled answer"}, nil
+}
+
+func (g *samplingGenerator) GetModelName() string { return "test-model" }
+
+type samplingModelsFunc func(hint pool.SelectionHint) (domain.Generator, error)
+
+func (f samplingModelsFunc) GetLLMServiceWithHint(hint pool.SelectionHint) (domain.Generator, error) {
+	return f(hint)
+}
+
+func samplingParams(text string) *mcp.CreateMessageParams {
+	return &mcp.CreateMessageParams{
+		SystemPrompt: "be brief",
+		MaxTokens:    10000,
+		Messages:     []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: text}}},
+		ModelPreferences: &mcp.ModelPreferences{
+			Hints:                []*mcp.ModelHint{{Name: "claude"}},
+			IntelligencePriority: 0.8,
+		},
+	}
+}
+
+func TestSamplingHandler_CreateMessage(t *testing.T) {
+	gen := &samplingGenerator{}
+	var gotHint pool.SelectionHint
+	h := NewSamplingHandler(SamplingConfig{Enabled: true, MaxTokens: 500}, samplingModelsFunc(func(hint pool.SelectionHint) (domain.Generator, error) {
+		gotHint = hint
+		return gen, nil
+	}))
+
+	var recordedServer, recordedModel string
+	h.SetUsageRecorder(func(ctx context.Context, server, model, input, output string, start time.Time) {
+		recordedServer, recordedModel = server, model
+	})
+
+	res, err := h.CreateMessage(context.Background(), "github", samplingParams("hello"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "sampled answer", res.Content.(*mcp.TextContent).Text)
+	assert.Equal(t, "test-model", res.Model)
+	assert.Equal(t, pool.SelectionHint{PreferredProvider: "claude", PreferredModel: "claude", MinCapability: 4}, gotHint)
+	assert.Equal(t, 500, gen.opts.MaxTokens, "request max tokens is capped by config")
+	require.Len(t, gen.messages, 2)
+	assert.Equal(t, "system", gen.messages[0].Role)
+	assert.Equal(t, "github", recordedServer)
+	assert.Equal(t, "test-model", recordedModel)
+	assert.Greater(t, h.Spent("github"), 0)
+}
+
+func TestSamplingHandler_Policy(t *testing.T) {
+	models := samplingModelsFunc(func(pool.SelectionHint) (domain.Generator, error) {
+		return &samplingGenerator{}, nil
+	})
+
+	t.Run("allow and deny lists", func(t *testing.T) {
+		h := NewSamplingHandler(SamplingConfig{Enabled: true, AllowServers: []string{"a", "b"}, DenyServers: []string{"B"}}, models)
+		assert.True(t, h.Allows("a"))
+		assert.False(t, h.Allows("b"))
+		assert.False(t, h.Allows("c"))
+		assert.Nil(t, h.HandlerFor("c"))
+		assert.NotNil(t, h.HandlerFor("a"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := NewSamplingHandler(SamplingConfig{}, models)
+		_, err := h.CreateMessage(context.Background(), "a", samplingParams("x"))
+		assert.Error(t, err)
+	})
+
+	t.Run("approval", func(t *testing.T) {
+		h := NewSamplingHandler(SamplingConfig{Enabled: true, RequireApproval: true}, models)
+		_, err := h.CreateMessage(context.Background(), "a", samplingParams("x"))
+		assert.Error(t, err, "no approver means no sampling")
+
+		var asked *SamplingRequest
+		h.SetApprover(func(ctx context.Context, req *SamplingRequest) (bool, error) {
+			asked = req
+			return false, nil
+		})
+		_, err = h.CreateMessage(context.Background(), "a", samplingParams("x"))
+		assert.ErrorContains(t, err, "declined")
+		require.NotNil(t, asked)
+		assert.Equal(t, "a", asked.Server)
+	})
+
+	t.Run("per-server budget", func(t *testing.T) {
+		// each request reserves 3 prompt tokens plus the 10 token cap and
+		// then spends 3 + 4 for "sampled answer"
+		h := NewSamplingHandler(SamplingConfig{Enabled: true, MaxTokens: 10, MaxTokensPerServer: 15}, models)
+		_, err := h.CreateMessage(context.Background(), "a", samplingParams("x"))
+		require.NoError(t, err)
+		assert.Equal(t, 7, h.Spent("a"), "the reservation is settled to the estimated use")
+		_, err = h.CreateMessage(context.Background(), "a", samplingParams("x"))
+		assert.ErrorContains(t, err, "budget")
+		_, err = h.CreateMessage(context.Background(), "b", samplingParams("x"))
+		assert.NoError(t, err, "budgets are per server")
+	})
+
+	t.Run("budget reserved while in flight", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		blocking := samplingModelsFunc(func(pool.SelectionHint) (domain.Generator, error) {
+			return &blockingSamplingGenerator{started: started, release: release}, nil
+		})
+		h := NewSamplingHandler(SamplingConfig{Enabled: true, MaxTokens: 10, MaxTokensPerServer: 15}, blocking)
+		done := make(chan error, 1)
+		go func() {
+			_, err := h.CreateMessage(context.Background(), "a", samplingParams("x"))
+			done <- err
+		}()
+		<-started
+		_, err := h.CreateMessage(context.Background(), "a", samplingParams("x"))
+		assert.ErrorContains(t, err, "budget", "a concurrent request cannot spend the reserved budget")
+		close(release)
+		require.NoError(t, <-done)
+	})
+}
+
+// blockingSamplingGenerator holds a request until release is closed
+type blockingSamplingGenerator struct {
+	domain.Generator
+	started, release chan struct{}
+}
+
+func (g *blockingSamplingGenerator) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	close(g.started)
+	<-g.release
+	return &domain.GenerationResult{Content: "sampled answer"}, nil
+}
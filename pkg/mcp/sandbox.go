@@ -0,0 +1,346 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SandboxBackend names the isolation a sandboxed stdio server runs under
+type SandboxBackend string
+
+const (
+	SandboxAuto       SandboxBackend = ""           // bubblewrap when installed, namespaces otherwise
+	SandboxBwrap      SandboxBackend = "bwrap"      // bubblewrap: private filesystem, network, PIDs and IPC
+	SandboxNamespaces SandboxBackend = "namespaces" // a private network namespace, no filesystem isolation
+	SandboxNone       SandboxBackend = "none"       // environment and resource limits only
+)
+
+// SandboxConfig confines a stdio server. With bubblewrap the server sees
+// the system directories read-only, its own install directory, a private
+// /tmp and the declared paths; nothing else of the host filesystem.
+type SandboxConfig struct {
+	Backend      SandboxBackend `toml:"backend" json:"backend,omitempty" mapstructure:"backend"`
+	Require      bool           `toml:"require" json:"require,omitempty" mapstructure:"require"`                      // Refuse to start when part of the sandbox is unavailable
+	Paths        []string       `toml:"paths" json:"paths,omitempty" mapstructure:"paths"`                            // Read-write paths
+	ReadOnly     []string       `toml:"read_only" json:"read_only,omitempty" mapstructure:"read_only"`                // Read-only paths
+	Network      bool           `toml:"network" json:"network,omitempty" mapstructure:"network"`                      // Allow network access
+	Env          []string       `toml:"env" json:"env,omitempty" mapstructure:"env"`                                  // Inherited variables on top of the defaults, globs allowed
+	MemoryMB     int            `toml:"memory_mb" json:"memory_mb,omitempty" mapstructure:"memory_mb"`                // Address space limit
+	CPUSeconds   int            `toml:"cpu_seconds" json:"cpu_seconds,omitempty" mapstructure:"cpu_seconds"`          // CPU time limit
+	MaxProcesses int            `toml:"max_processes" json:"max_processes,omitempty" mapstructure:"max_processes"`    // Limit on the user's processes
+	MaxOpenFiles int            `toml:"max_open_files" json:"max_open_files,omitempty" mapstructure:"max_open_files"` // Limit on open file descriptors
+}
+
+// sandboxEnv lists the variables a sandboxed server inherits by default.
+// Variables from the server's env are always set.
+var sandboxEnv = []string{"PATH", "HOME", "USER", "LANG", "LC_*", "TERM", "TZ", "TMPDIR"}
+
+// sandboxSystemDirs are bound read-only when they exist
+var sandboxSystemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc"}
+
+// SandboxReport says how a sandbox is enforced on this machine
+type SandboxReport struct {
+	Backend    SandboxBackend // the backend that runs, after falling back
+	Filesystem bool           // access is limited to the system directories and declared paths
+	Network    bool           // network access is blocked
+	Limits     []string       // enforced resource limits, like "memory 512MB"
+	Env        []string       // inherited variables, globs allowed
+	Problems   []string       // requested isolation this machine cannot provide
+	Blocked    bool           // the server refuses to start because of the problems
+}
+
+// validate checks the backend, limits and env patterns
+func (s *SandboxConfig) validate() error {
+	if s == nil {
+		return nil
+	}
+	switch s.Backend {
+	case SandboxAuto, SandboxBwrap:
+	case SandboxNamespaces, SandboxNone:
+		if s.Require {
+			return fmt.Errorf("sandbox backend %s cannot isolate the filesystem, drop require", s.Backend)
+		}
+	default:
+		return fmt.Errorf("unknown sandbox backend %q (use bwrap, namespaces or none)", s.Backend)
+	}
+	if s.MemoryMB < 0 || s.CPUSeconds < 0 || s.MaxProcesses < 0 || s.MaxOpenFiles < 0 {
+		return fmt.Errorf("sandbox limits cannot be negative")
+	}
+	for _, pattern := range s.Env {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid sandbox env pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// prepared validates s and returns a copy with its paths resolved against
+// dir, or the working directory when dir is empty. Servers from the JSON
+// files were already resolved against the file's directory; this catches
+// servers from the TOML config or code before they run.
+func (s *SandboxConfig) prepared(dir string) (*SandboxConfig, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	out := *s
+	out.Paths = slices.Clone(s.Paths)
+	out.ReadOnly = slices.Clone(s.ReadOnly)
+	out.resolvePaths(dir)
+	return &out, nil
+}
+
+// resolvePaths expands ~ and makes relative paths relative to dir
+func (s *SandboxConfig) resolvePaths(dir string) {
+	if s == nil {
+		return
+	}
+	home, _ := os.UserHomeDir()
+	resolve := func(paths []string) {
+		for i, p := range paths {
+			if p == "~" || strings.HasPrefix(p, "~/") {
+				p = filepath.Join(home, p[1:])
+			} else if !filepath.IsAbs(p) {
+				p = filepath.Join(dir, p)
+			}
+			paths[i] = filepath.Clean(p)
+		}
+	}
+	resolve(s.Paths)
+	resolve(s.ReadOnly)
+}
+
+// Report picks the backend this machine supports and lists what the
+// sandbox enforces
+func (s *SandboxConfig) Report() SandboxReport {
+	r := SandboxReport{Backend: s.Backend, Env: append(slices.Clone(sandboxEnv), s.Env...)}
+	bwrap := lookBwrap() != ""
+	switch s.Backend {
+	case SandboxAuto:
+		switch {
+		case bwrap:
+			r.Backend = SandboxBwrap
+		case !s.Network && namespacesAvailable():
+			r.Backend = SandboxNamespaces
+			r.Problems = append(r.Problems, "bwrap is not installed, so the filesystem is not restricted")
+		default:
+			r.Backend = SandboxNone
+			r.Problems = append(r.Problems, "bwrap is not installed, so the filesystem is not restricted")
+			if !s.Network {
+				r.Problems = append(r.Problems, "user namespaces are unavailable, so the network is not blocked")
+			}
+		}
+	case SandboxBwrap:
+		if !bwrap {
+			r.Backend = SandboxNone
+			r.Problems = append(r.Problems, "bwrap is not installed")
+		}
+	case SandboxNamespaces:
+		if !namespacesAvailable() {
+			r.Backend = SandboxNone
+			r.Problems = append(r.Problems, "user namespaces are unavailable")
+		}
+	}
+	r.Filesystem = r.Backend == SandboxBwrap
+	r.Network = !s.Network && (r.Backend == SandboxBwrap || r.Backend == SandboxNamespaces)
+
+	if len(s.prlimitArgs()) > 0 && lookPrlimit() == "" {
+		r.Problems = append(r.Problems, "prlimit is not installed, so resource limits are not enforced")
+	} else {
+		if s.MemoryMB > 0 {
+			r.Limits = append(r.Limits, fmt.Sprintf("memory %dMB", s.MemoryMB))
+		}
+		if s.CPUSeconds > 0 {
+			r.Limits = append(r.Limits, fmt.Sprintf("cpu %ds", s.CPUSeconds))
+		}
+		if s.MaxProcesses > 0 {
+			r.Limits = append(r.Limits, fmt.Sprintf("processes %d", s.MaxProcesses))
+		}
+		if s.MaxOpenFiles > 0 {
+			r.Limits = append(r.Limits, fmt.Sprintf("open files %d", s.MaxOpenFiles))
+		}
+	}
+
+	// A backend asked for by name or Require turns problems into errors
+	r.Blocked = len(r.Problems) > 0 && (s.Require || s.Backend != SandboxAuto && r.Backend != s.Backend)
+	return r
+}
+
+// command builds the command running execPath in the sandbox. Unless the
+// report is blocked it runs with whatever isolation is available.
+func (s *SandboxConfig) command(ctx context.Context, execPath string, args []string, workingDir string, env map[string]string) (*exec.Cmd, SandboxReport, error) {
+	report := s.Report()
+	if report.Blocked {
+		return nil, report, fmt.Errorf("sandbox unavailable: %s", strings.Join(report.Problems, "; "))
+	}
+	for _, p := range append(slices.Clone(s.ReadOnly), s.Paths...) {
+		if _, err := os.Stat(p); err != nil {
+			return nil, report, fmt.Errorf("sandbox path: %w", err)
+		}
+	}
+
+	argv := append([]string{execPath}, args...)
+	if report.Backend == SandboxBwrap {
+		argv = append(append([]string{lookBwrap()}, s.bwrapArgs(execPath, workingDir)...), argv...)
+	}
+	if len(report.Limits) > 0 {
+		argv = append(append([]string{lookPrlimit()}, s.prlimitArgs()...), argv...)
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = workingDir
+	cmd.Env = s.environ(os.Environ(), env)
+	if report.Backend == SandboxNamespaces && report.Network {
+		cmd.SysProcAttr = namespaceAttr()
+	}
+	return cmd, report, nil
+}
+
+// bwrapArgs mounts the system directories, the command's install
+// directory and the declared paths into an otherwise empty root
+func (s *SandboxConfig) bwrapArgs(execPath, workingDir string) []string {
+	args := []string{"--die-with-parent", "--new-session", "--unshare-all"}
+	if s.Network {
+		args = append(args, "--share-net")
+	}
+	args = append(args, "--proc", "/proc", "--dev", "/dev", "--tmpfs", "/tmp")
+	for _, dir := range sandboxSystemDirs {
+		args = append(args, "--ro-bind-try", dir, dir)
+	}
+	for _, dir := range installDirs(execPath) {
+		args = append(args, "--ro-bind", dir, dir)
+	}
+	if workingDir != "" && !slices.Contains(s.Paths, workingDir) {
+		args = append(args, "--ro-bind", workingDir, workingDir)
+	}
+	for _, p := range s.ReadOnly {
+		args = append(args, "--ro-bind", p, p)
+	}
+	for _, p := range s.Paths {
+		args = append(args, "--bind", p, p)
+	}
+	if workingDir != "" {
+		args = append(args, "--chdir", workingDir)
+	}
+	return append(args, "--")
+}
+
+// installDirs returns the directories outside the system directories that
+// execPath and its symlink target live in. Commands in the bin directory of
+// a known package layout get the package root, so npm and pip installs find
+// their packages. The home directory and its ancestors are never returned.
+func installDirs(execPath string) []string {
+	resolved, err := exec.LookPath(execPath)
+	if err != nil {
+		return nil
+	}
+	candidates := []string{resolved}
+	if target, err := filepath.EvalSymlinks(resolved); err == nil && target != resolved {
+		candidates = append(candidates, target)
+	}
+	home, _ := os.UserHomeDir()
+
+	var dirs []string
+	for _, file := range candidates {
+		dir, err := filepath.Abs(filepath.Dir(file))
+		if err != nil {
+			continue
+		}
+		if root, ok := packageRoot(dir); ok {
+			dir = root
+		}
+		if dir == "/" || slices.Contains(dirs, dir) || slices.ContainsFunc(sandboxSystemDirs, func(system string) bool {
+			return dir == system || strings.HasPrefix(dir, system+"/")
+		}) {
+			continue
+		}
+		if home != "" && (dir == home || strings.HasPrefix(home, dir+"/")) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// packageRoot returns the root of the package whose bin directory is dir:
+// a Python virtualenv, a Node package or install prefix, or /opt/<pkg>
+func packageRoot(dir string) (string, bool) {
+	if filepath.Base(dir) != "bin" {
+		return "", false
+	}
+	parent := filepath.Dir(dir)
+	if filepath.Dir(parent) == "/opt" {
+		return parent, true
+	}
+	for _, marker := range []string{"pyvenv.cfg", "package.json", filepath.Join("lib", "node_modules")} {
+		if _, err := os.Stat(filepath.Join(parent, marker)); err == nil {
+			return parent, true
+		}
+	}
+	return "", false
+}
+
+// prlimitArgs returns the prlimit flags for the configured limits
+func (s *SandboxConfig) prlimitArgs() []string {
+	var args []string
+	if s.MemoryMB > 0 {
+		args = append(args, "--as="+strconv.Itoa(s.MemoryMB*1024*1024))
+	}
+	if s.CPUSeconds > 0 {
+		args = append(args, "--cpu="+strconv.Itoa(s.CPUSeconds))
+	}
+	if s.MaxProcesses > 0 {
+		args = append(args, "--nproc="+strconv.Itoa(s.MaxProcesses))
+	}
+	if s.MaxOpenFiles > 0 {
+		args = append(args, "--nofile="+strconv.Itoa(s.MaxOpenFiles))
+	}
+	return args
+}
+
+// environ keeps the allowed variables of parent and adds the server's own
+func (s *SandboxConfig) environ(parent []string, env map[string]string) []string {
+	allowed := append(slices.Clone(sandboxEnv), s.Env...)
+	var out []string
+	for _, kv := range parent {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, set := env[name]; set {
+			continue
+		}
+		if slices.ContainsFunc(allowed, func(pattern string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok
+		}) {
+			out = append(out, kv)
+		}
+	}
+	for _, name := range sortedKeys(env) {
+		out = append(out, name+"="+env[name])
+	}
+	return out
+}
+
+func lookBwrap() string {
+	if !sandboxSupported {
+		return ""
+	}
+	p, _ := exec.LookPath("bwrap")
+	return p
+}
+
+func lookPrlimit() string {
+	p, _ := exec.LookPath("prlimit")
+	return p
+}
@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"os"
+	"strings"
+	"syscall"
+)
+
+const sandboxSupported = true
+
+// namespacesAvailable reports whether this process may create the user
+// and network namespaces of the namespaces backend
+func namespacesAvailable() bool {
+	if os.Geteuid() == 0 {
+		return true
+	}
+	for file, disabled := range map[string]string{
+		"/proc/sys/user/max_user_namespaces":                     "0",
+		"/proc/sys/kernel/unprivileged_userns_clone":             "0",
+		"/proc/sys/kernel/apparmor_restrict_unprivileged_userns": "1",
+	} {
+		if data, err := os.ReadFile(file); err == nil && strings.TrimSpace(string(data)) == disabled {
+			return false
+		}
+	}
+	return true
+}
+
+// namespaceAttr starts the process in a new network namespace, inside a
+// user namespace that maps the current user when not running as root
+func namespaceAttr() *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNET, Pdeathsig: syscall.SIGKILL}
+	if uid := os.Geteuid(); uid != 0 {
+		gid := os.Getegid()
+		attr.Cloneflags |= syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+	}
+	return attr
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package mcp
+
+import "syscall"
+
+// Sandboxing relies on Linux namespaces; elsewhere only the environment
+// and resource limits apply
+const sandboxSupported = false
+
+func namespacesAvailable() bool { return false }
+
+func namespaceAttr() *syscall.SysProcAttr { return nil }
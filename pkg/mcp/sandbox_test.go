@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadServerSandbox(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcpServers.json")
+	writeServers(t, path, `{"mcpServers": {"fs": {
+		"command": "npx", "args": ["-y", "server-filesystem"],
+		"sandbox": {"paths": ["data"], "read_only": ["~/docs"], "env": ["GITHUB_*"], "memory_mb": 512}
+	}}}`)
+	cfg := DefaultConfig()
+	cfg.Servers = []string{path}
+	require.NoError(t, cfg.LoadServersFromJSON())
+
+	servers := cfg.GetLoadedServers()
+	i := slices.IndexFunc(servers, func(s ServerConfig) bool { return s.Name == "fs" })
+	require.GreaterOrEqual(t, i, 0)
+	sandbox := servers[i].Sandbox
+	require.NotNil(t, sandbox)
+	home, _ := os.UserHomeDir()
+	assert.Equal(t, []string{filepath.Join(dir, "data")}, sandbox.Paths, "relative to the config file")
+	assert.Equal(t, []string{filepath.Join(home, "docs")}, sandbox.ReadOnly)
+	assert.Equal(t, 512, sandbox.MemoryMB)
+
+	writeServers(t, path, `{"mcpServers": {"web": {"url": "http://localhost/mcp", "sandbox": {}}}}`)
+	assert.ErrorContains(t, cfg.LoadServersFromJSON(), "only applies to stdio servers")
+	writeServers(t, path, `{"mcpServers": {"fs": {"command": "x", "sandbox": {"backend": "jail"}}}}`)
+	assert.ErrorContains(t, cfg.LoadServersFromJSON(), "unknown sandbox backend")
+	writeServers(t, path, `{"mcpServers": {"fs": {"command": "x", "sandbox": {"backend": "none", "require": true}}}}`)
+	assert.ErrorContains(t, cfg.LoadServersFromJSON(), "cannot isolate the filesystem")
+}
+
+func TestNewClientChecksSandbox(t *testing.T) {
+	// servers from the TOML config or code never pass through the JSON loader
+	_, err := NewClient(&ServerConfig{Name: "fs", Command: []string{"x"}, Sandbox: &SandboxConfig{Backend: "jail"}}, nil)
+	assert.ErrorContains(t, err, "unknown sandbox backend")
+	_, err = NewClient(&ServerConfig{Name: "web", Type: ServerTypeHTTP, URL: "http://localhost/mcp", Sandbox: &SandboxConfig{}}, nil)
+	assert.ErrorContains(t, err, "only applies to stdio servers")
+
+	dir := t.TempDir()
+	config := &ServerConfig{Name: "fs", Type: ServerTypeStdio, Command: []string{"x"}, WorkingDir: dir,
+		Sandbox: &SandboxConfig{Paths: []string{"data"}, ReadOnly: []string{"~/docs"}}}
+	client, err := NewClient(config, nil)
+	require.NoError(t, err)
+	home, _ := os.UserHomeDir()
+	assert.Equal(t, []string{filepath.Join(dir, "data")}, client.config.Sandbox.Paths, "relative to the working dir")
+	assert.Equal(t, []string{filepath.Join(home, "docs")}, client.config.Sandbox.ReadOnly)
+	assert.Equal(t, []string{"data"}, config.Sandbox.Paths, "the caller's config is left alone")
+}
+
+func TestSandboxEnviron(t *testing.T) {
+	s := &SandboxConfig{Env: []string{"GITHUB_*"}}
+	env := s.environ([]string{
+		"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "AWS_SECRET_ACCESS_KEY=x", "GITHUB_TOKEN=t", "DEBUG=1",
+	}, map[string]string{"DEBUG": "0", "API_URL": "http://api"})
+	assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "GITHUB_TOKEN=t", "API_URL=http://api", "DEBUG=0"}, env)
+}
+
+func TestSandboxBwrapArgs(t *testing.T) {
+	work := t.TempDir()
+	s := &SandboxConfig{Paths: []string{"/srv/data"}, ReadOnly: []string{"/srv/docs"}}
+	args := strings.Join(s.bwrapArgs("sh", work), " ")
+
+	assert.True(t, strings.HasPrefix(args, "--die-with-parent --new-session --unshare-all --proc /proc"))
+	assert.NotContains(t, args, "--share-net")
+	assert.Contains(t, args, "--ro-bind-try /usr /usr")
+	assert.Contains(t, args, "--ro-bind "+work+" "+work+" --ro-bind /srv/docs /srv/docs --bind /srv/data /srv/data --chdir "+work)
+	assert.True(t, strings.HasSuffix(args, " --"))
+
+	s.Network = true
+	s.Paths = append(s.Paths, work)
+	args = strings.Join(s.bwrapArgs("sh", work), " ")
+	assert.Contains(t, args, "--unshare-all --share-net")
+	assert.NotContains(t, args, "--ro-bind "+work, "a read-write working dir is only bound once")
+}
+
+func TestSandboxInstallDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	executable := func(path string) string {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+		return path
+	}
+
+	tool := executable(filepath.Join(home, "bin", "tool"))
+	assert.Equal(t, []string{filepath.Join(home, "bin")}, installDirs(tool), "a bin directory in $HOME does not expose $HOME")
+	assert.Empty(t, installDirs(executable(filepath.Join(home, "tool"))), "$HOME itself is never mounted")
+
+	venv := filepath.Join(home, "venv")
+	python := executable(filepath.Join(venv, "bin", "python"))
+	require.NoError(t, os.WriteFile(filepath.Join(venv, "pyvenv.cfg"), nil, 0o644))
+	assert.Equal(t, []string{venv}, installDirs(python), "a virtualenv is mounted whole")
+}
+
+func TestSandboxCommand(t *testing.T) {
+	if runtime.GOOS != "linux" || !namespacesAvailable() || lookPrlimit() == "" {
+		t.Skip("needs Linux with user namespaces and prlimit")
+	}
+	t.Setenv("SANDBOX_SECRET", "leaked")
+	s := &SandboxConfig{Backend: SandboxNamespaces, MaxOpenFiles: 64}
+	report := s.Report()
+	assert.Equal(t, SandboxReport{
+		Backend: SandboxNamespaces,
+		Network: true,
+		Limits:  []string{"open files 64"},
+		Env:     sandboxEnv,
+	}, report)
+
+	cmd, _, err := s.command(context.Background(), "sh", []string{"-c", `ulimit -n; echo "[$SANDBOX_SECRET] $TOKEN"; grep -c : /proc/net/dev`}, "", map[string]string{"TOKEN": "t"})
+	require.NoError(t, err)
+	out, err := cmd.CombinedOutput()
+	if err != nil && strings.Contains(string(out), "Operation not permitted") {
+		t.Skip("namespaces are restricted here: " + string(out))
+	}
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "64\n[] t\n1\n", string(out), "limited files, scrubbed env, loopback only")
+
+	_, _, err = (&SandboxConfig{ReadOnly: []string{"/does/not/exist"}}).command(context.Background(), "sh", nil, "", nil)
+	assert.ErrorContains(t, err, "sandbox path")
+}
+
+func TestSandboxReportBlocked(t *testing.T) {
+	if lookBwrap() != "" {
+		t.Skip("bwrap is installed")
+	}
+	report := (&SandboxConfig{Backend: SandboxBwrap}).Report()
+	assert.Equal(t, SandboxNone, report.Backend)
+	assert.True(t, report.Blocked, "bwrap was asked for by name")
+
+	report = (&SandboxConfig{}).Report()
+	assert.False(t, report.Filesystem)
+	assert.False(t, report.Blocked, "auto falls back")
+	assert.Contains(t, report.Problems, "bwrap is not installed, so the filesystem is not restricted")
+
+	_, _, err := (&SandboxConfig{Require: true}).command(context.Background(), "sh", nil, "", nil)
+	assert.ErrorContains(t, err, "sandbox unavailable: bwrap is not installed")
+}
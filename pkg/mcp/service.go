@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
@@ -17,6 +18,7 @@ type Service struct {
 	mcpConfig     *Config
 	conversations map[string]*Conversation
 	convMu        sync.RWMutex
+	stopWatch     context.CancelFunc
 }
 
 // Conversation represents a chat conversation with history
@@ -25,11 +27,31 @@ type Conversation struct {
 	Messages []domain.Message
 }
 
-// NewService creates a new MCP service
+// NewService creates a new MCP service. With a gateway URL configured the
+// service connects to the gateway instead of starting the servers itself.
 func NewService(mcpConfig *Config, llm domain.Generator) (*Service, error) {
+	if mcpConfig.Gateway.URL != "" {
+		mcpConfig.useGateway()
+	} else if err := prepareServers(mcpConfig); err != nil {
+		return nil, err
+	}
+
+	// Create MCP manager
+	manager := NewManager(mcpConfig)
+
+	return &Service{
+		manager:       manager,
+		llm:           llm,
+		mcpConfig:     mcpConfig,
+		conversations: make(map[string]*Conversation),
+	}, nil
+}
+
+// prepareServers loads the configured servers and the built-in ones
+func prepareServers(mcpConfig *Config) error {
 	// Load MCP servers from JSON
 	if err := mcpConfig.LoadServersFromJSON(); err != nil {
-		return nil, fmt.Errorf("failed to load MCP servers: %w", err)
+		return fmt.Errorf("failed to load MCP servers: %w", err)
 	}
 
 	// Ensure built-in servers (filesystem, websearch) are always present.
@@ -48,20 +70,20 @@ func NewService(mcpConfig *Config, llm domain.Generator) (*Service, error) {
 			mcpConfig.AddServer(&builtin)
 		}
 	}
+	return nil
+}
 
-	// Create MCP manager
-	manager := NewManager(mcpConfig)
-
-	return &Service{
-		manager:       manager,
-		llm:           llm,
-		mcpConfig:     mcpConfig,
-		conversations: make(map[string]*Conversation),
-	}, nil
+// usesGateway reports whether the servers run in a gateway
+func (s *Service) usesGateway() bool {
+	return s.mcpConfig.Gateway.URL != ""
 }
 
-// StartServers starts MCP servers based on configuration
+// StartServers starts MCP servers based on configuration. Through a gateway
+// every server is reached over the one gateway connection.
 func (s *Service) StartServers(ctx context.Context, serverNames []string) error {
+	if s.usesGateway() {
+		serverNames = []string{GatewayServerName}
+	}
 	if len(serverNames) == 0 {
 		// Start all configured servers
 		loadedServers := s.mcpConfig.GetLoadedServers()
@@ -88,6 +110,10 @@ func (s *Service) StopServer(serverName string) error {
 
 // AddDynamicServer adds and starts a server dynamically
 func (s *Service) AddDynamicServer(ctx context.Context, name string, command string, args []string) error {
+	if s.usesGateway() {
+		return errGatewayMode
+	}
+
 	// Create server configuration
 	serverConfig := &ServerConfig{
 		Name:      name,
@@ -520,12 +546,79 @@ func (s *Service) TestTool(ctx context.Context, toolName string, arguments map[s
 
 // Close closes the MCP service and all connections
 func (s *Service) Close() error {
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
 	if s.manager != nil {
 		return s.manager.Close()
 	}
 	return nil
 }
 
+// SetSamplingHandler answers sampling requests from servers started after
+// this call
+func (s *Service) SetSamplingHandler(h *SamplingHandler) {
+	s.manager.SetSamplingHandler(h)
+}
+
+// SetElicitor attaches the front end that answers servers' requests for
+// user input
+func (s *Service) SetElicitor(e Elicitor) {
+	s.manager.SetElicitor(e)
+}
+
+// OnCatalogChange calls fn whenever a server's tools, resources or prompts
+// change, or a server is started or stopped
+func (s *Service) OnCatalogChange(fn func(CatalogEvent)) func() {
+	return s.manager.OnCatalogChange(fn)
+}
+
+// WatchServers reloads the servers whenever the server config files change,
+// until the returned function or Close is called
+func (s *Service) WatchServers(interval time.Duration) func() {
+	if s.usesGateway() {
+		return func() {} // the gateway watches its own config
+	}
+	if s.stopWatch == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopWatch = cancel
+		go s.manager.WatchConfig(ctx, interval)
+	}
+	return s.stopWatch
+}
+
+// ListResources returns the resources offered by the running servers
+func (s *Service) ListResources() []ResourceInfo {
+	return s.manager.ListResources()
+}
+
+// ListResourceTemplates returns the resource templates offered by the
+// running servers
+func (s *Service) ListResourceTemplates() []ResourceTemplateInfo {
+	return s.manager.ListResourceTemplates()
+}
+
+// ListPrompts returns the prompts offered by the running servers
+func (s *Service) ListPrompts() []PromptInfo {
+	return s.manager.ListPrompts()
+}
+
+// ReadResource reads a resource; server may be empty to look it up by URI
+func (s *Service) ReadResource(ctx context.Context, server, uri string) (*ResourceContent, error) {
+	return s.manager.ReadResource(ctx, server, uri)
+}
+
+// SubscribeResource asks server to report changes to uri through
+// OnCatalogChange
+func (s *Service) SubscribeResource(ctx context.Context, server, uri string) error {
+	return s.manager.SubscribeResource(ctx, server, uri)
+}
+
+// GetPrompt renders a prompt; server may be empty to look it up by name
+func (s *Service) GetPrompt(ctx context.Context, server, name string, args map[string]string) (*PromptContent, error) {
+	return s.manager.GetPrompt(ctx, server, name, args)
+}
+
 // GetManager returns the underlying manager for advanced operations
 func (s *Service) GetManager() *Manager {
 	return s.manager
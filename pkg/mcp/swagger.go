@@ -9,9 +9,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	mcpswagger "github.com/liliang-cn/mcp-swagger-server/mcp"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -51,7 +51,7 @@ type SwaggerConfig struct {
 // SwaggerAuthConfig represents authentication configuration
 type SwaggerAuthConfig struct {
 	Type   string `json:"type" yaml:"type"`                         // basic, bearer, apikey
-	Value  string `json:"value" yaml:"value"`                       // token or key value
+	Value  string `json:"value" yaml:"value"`                       // token, key value, or user:password for basic
 	Header string `json:"header,omitempty" yaml:"header,omitempty"` // header name for API key
 }
 
@@ -61,12 +61,13 @@ type HTTPTransportConfig struct {
 	Port int    `json:"port" yaml:"port"`
 }
 
-// SwaggerServer wraps an mcp-swagger-server instance
+// SwaggerServer serves a Swagger or OpenAPI spec with the OpenAPI bridge
 type SwaggerServer struct {
-	config    *SwaggerConfig
-	server    *mcpswagger.Server
-	transport mcp.Transport
-	running   bool
+	config     *SwaggerConfig
+	bridge     *OpenAPIBridge
+	httpServer *http.Server
+	cancel     context.CancelFunc
+	running    bool
 }
 
 // NewSwaggerServer creates a new Swagger-based MCP server
@@ -83,41 +84,60 @@ func NewSwaggerServer(config *SwaggerConfig) (*SwaggerServer, error) {
 		config.Timeout = 30 * time.Second
 	}
 
-	// Create the server
-	var server *mcpswagger.Server
-	var err error
-
-	// Prepare API key
-	apiKey := ""
-	if config.Auth != nil && config.Auth.Type == "apikey" {
-		apiKey = config.Auth.Value
+	openapi, err := config.openAPIConfig()
+	if err != nil {
+		return nil, err
 	}
 
+	var bridge *OpenAPIBridge
 	if len(config.SwaggerData) > 0 {
-		// Use raw data
-		server, err = mcpswagger.NewFromSwaggerData(config.SwaggerData, config.BaseURL, apiKey)
-	} else if config.SwaggerFile != "" {
-		// Load from file
-		server, err = mcpswagger.NewFromSwaggerFile(config.SwaggerFile, config.BaseURL, apiKey)
-	} else if config.SwaggerURL != "" {
-		// Fetch from URL
-		server, err = mcpswagger.NewFromSwaggerURL(config.SwaggerURL, config.BaseURL, apiKey)
+		var spec map[string]any
+		spec, err = parseOpenAPISpec(config.SwaggerData, config.Name)
+		if err == nil {
+			bridge, err = newOpenAPIBridge(openapi, spec)
+		}
+	} else if openapi.Spec != "" {
+		bridge, err = NewOpenAPIBridge(openapi)
 	} else {
 		return nil, fmt.Errorf("no swagger source specified (need URL, file, or data)")
 	}
-
 	if err != nil {
 		return nil, fmt.Errorf("failed to create swagger server: %w", err)
 	}
 
-	// Auth and base URL are already configured during server creation
-
 	return &SwaggerServer{
 		config: config,
-		server: server,
+		bridge: bridge,
 	}, nil
 }
 
+// openAPIConfig translates the Swagger settings for the OpenAPI bridge
+func (c *SwaggerConfig) openAPIConfig() (*OpenAPIConfig, error) {
+	cfg := &OpenAPIConfig{
+		Spec:    c.SwaggerFile,
+		BaseURL: c.BaseURL,
+		Headers: c.Headers,
+	}
+	if c.SwaggerURL != "" {
+		cfg.Spec = c.SwaggerURL
+	}
+	if c.Auth == nil {
+		return cfg, nil
+	}
+	switch c.Auth.Type {
+	case "bearer":
+		cfg.Auth = &OpenAPIAuth{Type: "bearer", Token: c.Auth.Value}
+	case "apikey":
+		cfg.Auth = &OpenAPIAuth{Type: "apikey", Token: c.Auth.Value, Name: c.Auth.Header}
+	case "basic":
+		username, password, _ := strings.Cut(c.Auth.Value, ":")
+		cfg.Auth = &OpenAPIAuth{Type: "basic", Username: username, Password: password}
+	default:
+		return nil, fmt.Errorf("unsupported swagger auth type: %s", c.Auth.Type)
+	}
+	return cfg, nil
+}
+
 // Start starts the Swagger MCP server
 func (s *SwaggerServer) Start(ctx context.Context) error {
 	if s.running {
@@ -143,7 +163,7 @@ func (s *SwaggerServer) Start(ctx context.Context) error {
 	return nil
 }
 
-// startHTTP starts the server with HTTP transport
+// startHTTP serves the tools over streamable HTTP
 func (s *SwaggerServer) startHTTP(ctx context.Context) error {
 	if s.config.HTTPConfig == nil {
 		s.config.HTTPConfig = &HTTPTransportConfig{
@@ -152,11 +172,13 @@ func (s *SwaggerServer) startHTTP(ctx context.Context) error {
 		}
 	}
 
-	// The mcp-swagger-server v0.4.0 supports HTTP transport
-	// Run the server with HTTP transport
+	server := s.bridge.Server()
+	s.httpServer = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", s.config.HTTPConfig.Host, s.config.HTTPConfig.Port),
+		Handler: mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil),
+	}
 	go func() {
-		err := s.server.Run(ctx)
-		if err != nil {
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			fmt.Printf("Swagger server error: %v\n", err)
 		}
 	}()
@@ -164,13 +186,12 @@ func (s *SwaggerServer) startHTTP(ctx context.Context) error {
 	return nil
 }
 
-// startStdio starts the server with stdio transport
+// startStdio serves the tools over stdin and stdout
 func (s *SwaggerServer) startStdio(ctx context.Context) error {
-	// The mcp-swagger-server v0.4.0 auto-detects transport
-	// Run with stdio by default
+	ctx, s.cancel = context.WithCancel(ctx)
 	go func() {
-		err := s.server.Run(ctx)
-		if err != nil {
+		err := s.bridge.Server().Run(ctx, &mcp.StdioTransport{})
+		if err != nil && ctx.Err() == nil {
 			fmt.Printf("Swagger server error: %v\n", err)
 		}
 	}()
@@ -184,55 +205,46 @@ func (s *SwaggerServer) Stop() error {
 		return fmt.Errorf("server not running")
 	}
 
-	// Stop the server (implementation depends on mcp-swagger-server API)
-	// For now, just mark as not running
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.httpServer != nil {
+		_ = s.httpServer.Close()
+	}
 	s.running = false
 	return nil
 }
 
 // GetTools returns the available tools from the Swagger spec
 func (s *SwaggerServer) GetTools() ([]*mcp.Tool, error) {
-	if s.server == nil {
+	if s.bridge == nil {
 		return nil, fmt.Errorf("server not initialized")
 	}
-
-	// Get tools from the swagger server
-	tools := s.server.ListTools()
-
-	// Convert to MCP SDK tools format
-	mcpTools := make([]*mcp.Tool, 0, len(tools))
-	for _, toolName := range tools {
-		mcpTool := &mcp.Tool{
-			Name:        toolName,
-			Description: fmt.Sprintf("Tool: %s", toolName),
-			InputSchema: json.RawMessage(`{"type":"object"}`),
-		}
-		mcpTools = append(mcpTools, mcpTool)
-	}
-
-	return mcpTools, nil
+	return s.bridge.Tools(), nil
 }
 
-// CallTool calls a tool with the given arguments
+// CallTool calls a tool with the given arguments and returns the API response
 func (s *SwaggerServer) CallTool(ctx context.Context, name string, args json.RawMessage) (json.RawMessage, error) {
-	if s.server == nil {
+	if s.bridge == nil {
 		return nil, fmt.Errorf("server not initialized")
 	}
 
-	// The mcp-swagger-server doesn't expose a direct CallTool method
-	// This would need to be implemented by accessing the underlying MCP server
-	mcpServer := s.server.GetMCPServer()
-	if mcpServer == nil {
-		return nil, fmt.Errorf("MCP server not available")
+	var arguments map[string]any
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &arguments); err != nil {
+			return nil, fmt.Errorf("invalid arguments for %s: %w", name, err)
+		}
 	}
-
-	// For now, return a placeholder result
-	result := map[string]interface{}{
-		"success": true,
-		"message": fmt.Sprintf("Called tool %s", name),
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	body, err := s.bridge.CallTool(ctx, name, arguments)
+	if err != nil {
+		return nil, err
 	}
-
-	return json.Marshal(result)
+	if json.Valid([]byte(body)) {
+		return json.RawMessage(body), nil
+	}
+	return json.Marshal(body)
 }
 
 // fetchSwaggerSpec fetches a Swagger spec from a URL
@@ -290,8 +302,6 @@ func fetchSwaggerSpec(specURL string, headers map[string]string, timeout time.Du
 	return data, nil
 }
 
-// configureAuth is no longer needed as auth is configured during server creation
-
 // LoadSwaggerServers loads Swagger server configurations from config
 func LoadSwaggerServers(configPath string) (map[string]*SwaggerConfig, error) {
 	data, err := os.ReadFile(configPath)
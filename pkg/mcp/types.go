@@ -8,6 +8,8 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -22,25 +24,31 @@ const (
 	ServerTypeHTTP      ServerType = "http"
 	ServerTypeSSE       ServerType = "sse"
 	ServerTypeInProcess ServerType = "inprocess"
+	ServerTypeOpenAPI   ServerType = "openapi" // an HTTP API served by the built-in OpenAPI bridge
 )
 
 // ServerConfig represents the configuration for an MCP server
 type ServerConfig struct {
-	Name             string            `toml:"name" json:"name" mapstructure:"name"`
-	Description      string            `toml:"description" json:"description" mapstructure:"description"`
-	Type             ServerType        `toml:"type" json:"type" mapstructure:"type"`                      // "stdio", "http", or "sse"
-	Command          []string          `toml:"command" json:"command" mapstructure:"command"`             // For stdio type
-	Args             []string          `toml:"args" json:"args" mapstructure:"args"`                      // For stdio type
-	URL              string            `toml:"url" json:"url" mapstructure:"url"`                         // For http type
-	Headers          map[string]string `toml:"headers" json:"headers" mapstructure:"headers"`             // For http type
-	WorkingDir       string            `toml:"working_dir" json:"working_dir" mapstructure:"working_dir"` // For stdio type
-	Env              map[string]string `toml:"env" json:"env" mapstructure:"env"`                         // For stdio type
-	AutoStart        bool              `toml:"auto_start" json:"auto_start" mapstructure:"auto_start"`
-	RestartOnFailure bool              `toml:"restart_on_failure" json:"restart_on_failure" mapstructure:"restart_on_failure"`
-	MaxRestarts      int               `toml:"max_restarts" json:"max_restarts" mapstructure:"max_restarts"`
-	RestartDelay     time.Duration     `toml:"restart_delay" json:"restart_delay" mapstructure:"restart_delay"`
-	DefaultTimeout   time.Duration     `toml:"default_timeout" json:"default_timeout" mapstructure:"default_timeout"` // Timeout for initialize handshake
-	Capabilities     []string          `toml:"capabilities" json:"capabilities" mapstructure:"capabilities"`
+	Name             string                `toml:"name" json:"name" mapstructure:"name"`
+	Description      string                `toml:"description" json:"description" mapstructure:"description"`
+	Type             ServerType            `toml:"type" json:"type" mapstructure:"type"`                      // "stdio", "http", or "sse"
+	Command          []string              `toml:"command" json:"command" mapstructure:"command"`             // For stdio type
+	Args             []string              `toml:"args" json:"args" mapstructure:"args"`                      // For stdio type
+	URL              string                `toml:"url" json:"url" mapstructure:"url"`                         // For http type
+	Headers          map[string]string     `toml:"headers" json:"headers" mapstructure:"headers"`             // For http type
+	WorkingDir       string                `toml:"working_dir" json:"working_dir" mapstructure:"working_dir"` // For stdio type
+	Env              map[string]string     `toml:"env" json:"env" mapstructure:"env"`                         // For stdio type
+	AutoStart        bool                  `toml:"auto_start" json:"auto_start" mapstructure:"auto_start"`
+	RestartOnFailure bool                  `toml:"restart_on_failure" json:"restart_on_failure" mapstructure:"restart_on_failure"`
+	MaxRestarts      int                   `toml:"max_restarts" json:"max_restarts" mapstructure:"max_restarts"`
+	RestartDelay     time.Duration         `toml:"restart_delay" json:"restart_delay" mapstructure:"restart_delay"`
+	DefaultTimeout   time.Duration         `toml:"default_timeout" json:"default_timeout" mapstructure:"default_timeout"` // Timeout for initialize handshake
+	Capabilities     []string              `toml:"capabilities" json:"capabilities" mapstructure:"capabilities"`
+	OAuth            *OAuthConfig          `toml:"oauth" json:"oauth,omitempty" mapstructure:"oauth"`       // For http/sse types that require authorization
+	OpenAPI          *OpenAPIConfig        `toml:"openapi" json:"openapi,omitempty" mapstructure:"openapi"` // For openapi type
+	Policy           ToolPolicy            `toml:"policy" json:"policy,omitempty" mapstructure:"policy"`    // Limits for every tool of the server
+	Tools            map[string]ToolPolicy `toml:"tools" json:"tools,omitempty" mapstructure:"tools"`       // Per-tool limits, keyed by the server's tool name
+	Sandbox          *SandboxConfig        `toml:"sandbox" json:"sandbox,omitempty" mapstructure:"sandbox"` // For stdio type, isolates the server process
 }
 
 // ClientOptions configures the MCP client behavior
@@ -56,6 +64,16 @@ type ClientOptions struct {
 
 	// Roots - filesystem boundaries for server operations
 	Roots []*mcp.Root
+
+	// CatalogChangedHandler is called after the client refreshed its tools,
+	// resources or prompts because the server announced a change
+	CatalogChangedHandler func(kind CatalogKind)
+
+	// ResourceUpdatedHandler is called when a subscribed resource changed
+	ResourceUpdatedHandler func(uri string)
+
+	// OAuthStore supplies tokens for HTTP and SSE servers
+	OAuthStore *OAuthStore
 }
 
 // Config represents the overall MCP configuration
@@ -69,8 +87,17 @@ type Config struct {
 	ServersConfigPath     string         `toml:"servers_config_path" json:"servers_config_path" mapstructure:"servers_config_path"` // Deprecated: use Servers instead
 	FilesystemDirs        []string       `toml:"filesystem_dirs" json:"filesystem_dirs" mapstructure:"filesystem_dirs"`             // Allowed dirs for built-in filesystem server; defaults to home dir
 	FilesystemIgnore      []string       `toml:"filesystem_ignore" json:"filesystem_ignore" mapstructure:"filesystem_ignore"`       // Blacklisted directory names filtered from filesystem MCP operations
+	Sampling              SamplingConfig `toml:"sampling" json:"sampling" mapstructure:"sampling"`                                  // Lets servers request completions from the LLM pool
+	ElicitationTimeout    time.Duration  `toml:"elicitation_timeout" json:"elicitation_timeout" mapstructure:"elicitation_timeout"` // How long the user has to answer a server's input request
+	WatchServers          bool           `toml:"watch_servers" json:"watch_servers" mapstructure:"watch_servers"`                   // Start, stop and restart servers when their config files change
+	OAuthDir              string         `toml:"oauth_dir" json:"oauth_dir" mapstructure:"oauth_dir"`                               // Where `mcp login` stores OAuth tokens
+	Gateway               GatewayConfig  `toml:"gateway" json:"gateway" mapstructure:"gateway"`                                     // Shared `mcp gateway` that runs the servers for every process
 	LoadedServers         []ServerConfig `toml:"-" json:"-" mapstructure:"-"`                                                       // Internal: loaded server configurations
 	mu                    sync.Mutex     `toml:"-" json:"-" mapstructure:"-"`                                                       // Protects LoadedServers
+
+	// Names of LoadedServers that came from Servers files, so reloads can
+	// tell them from built-in and dynamically added servers
+	fileServers map[string]bool
 }
 
 // DefaultConfig returns default MCP configuration
@@ -84,10 +111,23 @@ func DefaultConfig() Config {
 		Servers:               []string{}, // Empty by default, resolved by resolveMCPServerPaths()
 		ServersConfigPath:     "",         // Deprecated
 		FilesystemIgnore:      DefaultFilesystemIgnoreNames(),
+		Sampling:              DefaultSamplingConfig(),
+		ElicitationTimeout:    5 * time.Minute,
+		WatchServers:          true,
+		OAuthDir:              defaultOAuthDir(),
+		Gateway:               GatewayConfig{Addr: "127.0.0.1:8766"},
 		LoadedServers:         []ServerConfig{},
 	}
 }
 
+func defaultOAuthDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".agentgo", "mcp-auth")
+}
+
 // GetBuiltInServers returns the list of built-in in-process MCP servers.
 // Both servers are compiled into the binary — no external commands needed.
 // filesystemDirs sets the allowed directories for the filesystem server;
@@ -120,13 +160,18 @@ func GetBuiltInServers(filesystemDirs []string) []ServerConfig {
 
 // SimpleServerConfig represents a simplified server configuration for JSON files
 type SimpleServerConfig struct {
-	Type       string            `json:"type,omitempty"`        // "stdio", "http", or "sse"; defaults to "stdio"
-	Command    string            `json:"command,omitempty"`     // For stdio type
-	Args       []string          `json:"args,omitempty"`        // For stdio type
-	URL        string            `json:"url,omitempty"`         // For http type
-	Headers    map[string]string `json:"headers,omitempty"`     // For http type
-	WorkingDir string            `json:"working_dir,omitempty"` // For stdio type
-	Env        map[string]string `json:"env,omitempty"`         // For stdio type
+	Type       string                `json:"type,omitempty"`        // "stdio", "http", or "sse"; defaults to "stdio"
+	Command    string                `json:"command,omitempty"`     // For stdio type
+	Args       []string              `json:"args,omitempty"`        // For stdio type
+	URL        string                `json:"url,omitempty"`         // For http type
+	Headers    map[string]string     `json:"headers,omitempty"`     // For http type
+	WorkingDir string                `json:"working_dir,omitempty"` // For stdio type
+	Env        map[string]string     `json:"env,omitempty"`         // For stdio type
+	OAuth      *OAuthConfig          `json:"oauth,omitempty"`       // For http/sse types
+	OpenAPI    *OpenAPIConfig        `json:"openapi,omitempty"`     // For openapi type
+	Policy     ToolPolicy            `json:"policy,omitempty"`      // Limits for every tool of the server
+	Tools      map[string]ToolPolicy `json:"tools,omitempty"`       // Per-tool limits, keyed by tool name
+	Sandbox    *SandboxConfig        `json:"sandbox,omitempty"`     // For stdio type
 }
 
 // JSONServersConfig represents the root structure of the JSON MCP servers config file
@@ -148,6 +193,7 @@ func (c *Config) LoadServersFromJSON() error {
 
 	// Add back built-in servers first
 	c.LoadedServers = append(c.LoadedServers, builtinServers...)
+	c.fileServers = make(map[string]bool)
 
 	// Load from new Servers array
 	for _, serverFile := range c.Servers {
@@ -175,6 +221,43 @@ func (c *Config) LoadServersFromJSON() error {
 	return nil
 }
 
+// ServerFiles returns the JSON files servers are loaded from
+func (c *Config) ServerFiles() []string {
+	files := slices.Clone(c.Servers)
+	if c.ServersConfigPath != "" {
+		files = append(files, c.ServersConfigPath)
+	}
+	return files
+}
+
+// ReloadServers re-reads the server files, keeping built-in and dynamically
+// added servers. It returns the servers before and after the reload; on
+// error the previous servers stay loaded.
+func (c *Config) ReloadServers() (before, after []ServerConfig, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	before = slices.Clone(c.LoadedServers)
+	prevFiles := c.fileServers
+
+	kept := make([]ServerConfig, 0, len(before))
+	for _, server := range before {
+		if !prevFiles[server.Name] {
+			kept = append(kept, server)
+		}
+	}
+	c.LoadedServers = kept
+	c.fileServers = make(map[string]bool)
+
+	for _, serverFile := range c.ServerFiles() {
+		if err := c.loadServerFile(serverFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			c.LoadedServers, c.fileServers = before, prevFiles
+			return nil, nil, fmt.Errorf("failed to load server file %s: %w", serverFile, err)
+		}
+	}
+	return before, slices.Clone(c.LoadedServers), nil
+}
+
 func filterBuiltInServers(servers []ServerConfig) []ServerConfig {
 	filtered := make([]ServerConfig, 0, len(servers))
 	for _, server := range servers {
@@ -203,6 +286,24 @@ func (c *Config) AddServer(serverConfig *ServerConfig) {
 	c.LoadedServers = append(c.LoadedServers, *serverConfig)
 }
 
+// useGateway replaces the loaded servers with the gateway at URL
+func (c *Config) useGateway() {
+	server := ServerConfig{
+		Name:        GatewayServerName,
+		Description: "AgentGo MCP gateway",
+		Type:        ServerTypeHTTP,
+		URL:         c.Gateway.URL,
+		AutoStart:   true,
+	}
+	if c.Gateway.Token != "" {
+		server.Headers = map[string]string{"Authorization": "Bearer " + c.Gateway.Token}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.LoadedServers = []ServerConfig{server}
+	c.fileServers = nil
+}
+
 // loadServerFile loads a single server configuration file
 func (c *Config) loadServerFile(serverFile string) error {
 	configPath := serverFile
@@ -228,7 +329,19 @@ func (c *Config) loadServerFile(serverFile string) error {
 		} else if simpleConfig.URL != "" {
 			// Auto-detect HTTP type if URL is provided
 			serverType = ServerTypeHTTP
+		} else if simpleConfig.OpenAPI != nil {
+			serverType = ServerTypeOpenAPI
+		}
+		if simpleConfig.OpenAPI != nil {
+			simpleConfig.OpenAPI.resolveSpec(filepath.Dir(configPath))
 		}
+		if simpleConfig.Sandbox != nil && serverType != ServerTypeStdio {
+			return fmt.Errorf("server %s in %s: sandbox only applies to stdio servers", name, configPath)
+		}
+		if err := simpleConfig.Sandbox.validate(); err != nil {
+			return fmt.Errorf("server %s in %s: %w", name, configPath, err)
+		}
+		simpleConfig.Sandbox.resolvePaths(filepath.Dir(configPath))
 
 		serverConfig := ServerConfig{
 			Name:             name,
@@ -245,6 +358,14 @@ func (c *Config) loadServerFile(serverFile string) error {
 			MaxRestarts:      3,
 			RestartDelay:     5 * time.Second,
 			Capabilities:     []string{}, // Will be discovered at runtime
+			OAuth:            simpleConfig.OAuth,
+			OpenAPI:          simpleConfig.OpenAPI,
+			Policy:           simpleConfig.Policy,
+			Tools:            simpleConfig.Tools,
+			Sandbox:          simpleConfig.Sandbox,
+		}
+		if err := serverConfig.validatePolicies(); err != nil {
+			return fmt.Errorf("invalid policy in %s: %w", configPath, err)
 		}
 
 		// Set command based on type
@@ -254,6 +375,10 @@ func (c *Config) loadServerFile(serverFile string) error {
 
 		// Add to loaded servers list
 		c.LoadedServers = append(c.LoadedServers, serverConfig)
+		if c.fileServers == nil {
+			c.fileServers = make(map[string]bool)
+		}
+		c.fileServers[name] = true
 	}
 
 	return nil
@@ -267,6 +392,7 @@ type Client struct {
 	resources         map[string]*mcp.Resource
 	resourceTemplates map[string]*mcp.ResourceTemplate
 	prompts           map[string]*mcp.Prompt
+	catalogMu         sync.RWMutex // Protects tools, resources, templates and prompts
 	connected         bool
 
 	// Health monitoring
@@ -320,9 +446,10 @@ type ResourceTemplateInfo struct {
 
 // ResourceContent represents the content of a read resource
 type ResourceContent struct {
-	URI      string      `json:"uri"`
-	MIMEType string      `json:"mime_type,omitempty"`
-	Content  interface{} `json:"content"`
+	ServerName string      `json:"server_name,omitempty"`
+	URI        string      `json:"uri"`
+	MIMEType   string      `json:"mime_type,omitempty"`
+	Content    interface{} `json:"content"`
 }
 
 // PromptInfo represents information about an MCP prompt
@@ -0,0 +1,158 @@
+package mcpserver
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const documentURIPrefix = "agentgo://documents/"
+
+func documentURI(id string) string {
+	return documentURIPrefix + id
+}
+
+func documentName(doc domain.Document) string {
+	switch {
+	case doc.Path != "":
+		return filepath.Base(doc.Path)
+	case doc.URL != "":
+		return doc.URL
+	}
+	if source, ok := doc.Metadata["source"].(string); ok && source != "" {
+		return source
+	}
+	return doc.ID
+}
+
+// registerDocumentResources publishes every document currently in the
+// knowledge base, plus a template so documents ingested by other processes
+// can still be read by ID.
+func (s *Server) registerDocumentResources(ctx context.Context) error {
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "document",
+		Description: "A document in the AgentGo knowledge base",
+		MIMEType:    "text/plain",
+		URITemplate: documentURIPrefix + "{id}",
+	}, s.readDocument)
+
+	docs, err := s.opts.RAG.ListDocuments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		s.addDocumentResource(doc)
+	}
+	return nil
+}
+
+func (s *Server) addDocumentResource(doc domain.Document) {
+	s.server.AddResource(&mcp.Resource{
+		Name:     documentName(doc),
+		URI:      documentURI(doc.ID),
+		MIMEType: "text/plain",
+	}, s.readDocument)
+}
+
+func (s *Server) readDocument(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id := strings.TrimPrefix(uri, documentURIPrefix)
+	if id == uri || id == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	doc, err := s.opts.RAG.GetDocument(ctx, id)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "text/plain", Text: doc.Content}},
+	}, nil
+}
+
+// registerPrompts publishes each prompt.Manager template as an MCP prompt
+// whose arguments are the template's top-level fields.
+func (s *Server) registerPrompts() {
+	for _, key := range s.opts.Prompts.Keys() {
+		var args []*mcp.PromptArgument
+		for _, name := range templateFields(key, s.opts.Prompts.Get(key)) {
+			args = append(args, &mcp.PromptArgument{Name: name})
+		}
+		s.server.AddPrompt(&mcp.Prompt{
+			Name:      key,
+			Arguments: args,
+		}, func(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			data := make(map[string]string, len(req.Params.Arguments))
+			for k, v := range req.Params.Arguments {
+				data[k] = v
+			}
+			text, err := s.opts.Prompts.Render(key, data)
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.GetPromptResult{
+				Messages: []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: text}}},
+			}, nil
+		})
+	}
+}
+
+// templateFields lists the top-level {{.Field}} references in a template,
+// sorted. Fields inside range and with blocks refer to a different dot and
+// are skipped.
+func templateFields(name, content string) []string {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(content, "", "", map[string]*parse.Tree{}); err != nil || tree.Root == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			seen[n.Ident[0]] = true
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+		case *parse.WithNode:
+			walk(n.Pipe)
+		}
+	}
+	walk(tree.Root)
+
+	fields := make([]string, 0, len(seen))
+	for f := range seen {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,521 @@
+// Package mcpserver publishes AgentGo's knowledge base, memory, skills,
+// agents and prompt templates as a Model Context Protocol server, so other
+// MCP hosts can use them without linking against Go.
+package mcpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/prompt"
+	"github.com/liliang-cn/agent-go/pkg/rag"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KnowledgeBase is the subset of rag.Client exposed over MCP.
+type KnowledgeBase interface {
+	Query(ctx context.Context, query string, opts *rag.QueryOptions) (*domain.QueryResponse, error)
+	IngestText(ctx context.Context, text, source string, opts *rag.IngestOptions) (*domain.IngestResponse, error)
+	IngestFile(ctx context.Context, filePath string, opts *rag.IngestOptions) (*domain.IngestResponse, error)
+	ListDocuments(ctx context.Context) ([]domain.Document, error)
+	GetDocument(ctx context.Context, documentID string) (domain.Document, error)
+}
+
+// SkillRunner is the subset of skills.Service exposed over MCP.
+type SkillRunner interface {
+	RegisterAsMCPTools() ([]domain.ToolDefinition, error)
+	RunSkill(ctx context.Context, id string, vars map[string]interface{}) (string, error)
+}
+
+// AgentRunner is the subset of agent.SquadManager exposed over MCP.
+type AgentRunner interface {
+	ListAgents() ([]*agent.AgentModel, error)
+	ListSquads() ([]*agent.Squad, error)
+	DispatchTask(ctx context.Context, agentName string, instruction string) (string, error)
+	SubmitSquadTask(ctx context.Context, sessionID, squadID, prompt string, agentNames []string) (*agent.AsyncTask, error)
+	SubscribeTask(taskID string) (<-chan *agent.TaskEvent, func(), error)
+	GetTask(taskID string) (*agent.AsyncTask, error)
+}
+
+// Options selects what the server publishes. Nil backends are skipped.
+type Options struct {
+	Name    string
+	Version string
+
+	RAG     KnowledgeBase
+	Memory  domain.MemoryService
+	Skills  SkillRunner
+	Agents  AgentRunner
+	Prompts *prompt.Manager
+
+	// IngestDirs are the directories rag_ingest may read files from. Without
+	// any, only text can be ingested.
+	IngestDirs []string
+
+	// Principal is the memory principal of stdio clients, which act for the
+	// local user. HTTP callers are authenticated by Authenticate from their
+	// bearer token and are anonymous when it is nil or returns nil.
+	Principal    *domain.MemoryPrincipal
+	Authenticate func(token string) *domain.MemoryPrincipal
+
+	// Token is a bearer token that admits HTTP clients as anonymous memory
+	// callers. HTTPHandler rejects requests whose token neither matches it
+	// nor passes Authenticate, so without either it serves nobody.
+	Token string
+
+	Logger *slog.Logger
+}
+
+// Server is an MCP server backed by AgentGo services.
+type Server struct {
+	opts   Options
+	server *mcp.Server
+	logger *slog.Logger
+}
+
+// New creates a server and registers tools, resources and prompts for every
+// configured backend.
+func New(ctx context.Context, opts Options) (*Server, error) {
+	if opts.Name == "" {
+		opts.Name = "agentgo"
+	}
+	if opts.Version == "" {
+		opts.Version = "dev"
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &Server{
+		opts:   opts,
+		logger: logger,
+		server: mcp.NewServer(&mcp.Implementation{Name: opts.Name, Version: opts.Version}, &mcp.ServerOptions{Logger: logger}),
+	}
+
+	if opts.RAG != nil {
+		s.registerRAGTools()
+		if err := s.registerDocumentResources(ctx); err != nil {
+			return nil, fmt.Errorf("failed to publish documents: %w", err)
+		}
+	}
+	if opts.Memory != nil {
+		s.registerMemoryTools()
+	}
+	if opts.Skills != nil {
+		if err := s.registerSkillTools(); err != nil {
+			return nil, fmt.Errorf("failed to publish skills: %w", err)
+		}
+	}
+	if opts.Agents != nil {
+		if err := s.registerAgentTools(); err != nil {
+			return nil, fmt.Errorf("failed to publish agents: %w", err)
+		}
+	}
+	if opts.Prompts != nil {
+		s.registerPrompts()
+	}
+	return s, nil
+}
+
+// MCPServer returns the underlying SDK server.
+func (s *Server) MCPServer() *mcp.Server {
+	return s.server
+}
+
+// ServeStdio serves a single client over stdin/stdout until ctx is done or
+// the client disconnects.
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.server.Run(ctx, &mcp.StdioTransport{})
+}
+
+// HTTPHandler returns a streamable HTTP handler serving this server to
+// clients with an accepted bearer token; see Options.Token.
+func (s *Server) HTTPHandler() http.Handler {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return s.server
+	}, &mcp.StreamableHTTPOptions{Logger: s.logger})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.admits(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// admits reports whether the request carries the server token or one that
+// Authenticate accepts
+func (s *Server) admits(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	if s.opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1 {
+		return true
+	}
+	return s.opts.Authenticate != nil && s.opts.Authenticate(token) != nil
+}
+
+// principal returns the memory principal a tool call acts for. Calls that
+// did not arrive over HTTP come from the stdio client.
+func (s *Server) principal(req *mcp.CallToolRequest) *domain.MemoryPrincipal {
+	if req == nil || req.Extra == nil || req.Extra.Header == nil {
+		return s.opts.Principal
+	}
+	token, ok := strings.CutPrefix(req.Extra.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" || s.opts.Authenticate == nil {
+		return nil
+	}
+	return s.opts.Authenticate(token)
+}
+
+type ragQueryInput struct {
+	Query string `json:"query" jsonschema:"the question to answer from the knowledge base"`
+	TopK  int    `json:"top_k,omitempty" jsonschema:"number of chunks to retrieve (default 5)"`
+}
+
+type ragIngestInput struct {
+	Text   string `json:"text,omitempty" jsonschema:"text content to ingest"`
+	Source string `json:"source,omitempty" jsonschema:"name recorded as the document source when ingesting text"`
+	Path   string `json:"path,omitempty" jsonschema:"file in one of the server's ingest directories to ingest instead of text"`
+}
+
+type emptyInput struct{}
+
+// ingestPath resolves path, following symlinks, and checks that it lies in
+// one of the ingest directories
+func (s *Server) ingestPath(path string) (string, error) {
+	if len(s.opts.IngestDirs) == 0 {
+		return "", fmt.Errorf("ingesting files is disabled on this server")
+	}
+	resolved, err := filepath.Abs(path)
+	if err == nil {
+		resolved, err = filepath.EvalSymlinks(resolved)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot ingest %s: %w", path, err)
+	}
+	for _, dir := range s.opts.IngestDirs {
+		root, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if r, err := filepath.EvalSymlinks(root); err == nil {
+			root = r
+		}
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside the ingest directories", path)
+}
+
+func (s *Server) registerRAGTools() {
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "rag_query",
+		Description: "Answer a question from the AgentGo knowledge base and return the answer with its sources.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, in ragQueryInput) (*mcp.CallToolResult, any, error) {
+		if strings.TrimSpace(in.Query) == "" {
+			return nil, nil, fmt.Errorf("query is required")
+		}
+		opts := rag.DefaultQueryOptions()
+		if in.TopK > 0 {
+			opts.TopK = in.TopK
+		}
+		resp, err := s.opts.RAG.Query(ctx, in.Query, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, resp, nil
+	})
+
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "rag_ingest",
+		Description: "Add text, or a file from the server's ingest directories, to the AgentGo knowledge base.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, in ragIngestInput) (*mcp.CallToolResult, any, error) {
+		var (
+			resp *domain.IngestResponse
+			err  error
+		)
+		switch {
+		case in.Path != "":
+			path, pathErr := s.ingestPath(in.Path)
+			if pathErr != nil {
+				return nil, nil, pathErr
+			}
+			resp, err = s.opts.RAG.IngestFile(ctx, path, rag.DefaultIngestOptions())
+		case strings.TrimSpace(in.Text) != "":
+			source := in.Source
+			if source == "" {
+				source = "mcp"
+			}
+			resp, err = s.opts.RAG.IngestText(ctx, in.Text, source, rag.DefaultIngestOptions())
+		default:
+			return nil, nil, fmt.Errorf("text or path is required")
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.DocumentID != "" {
+			if doc, getErr := s.opts.RAG.GetDocument(ctx, resp.DocumentID); getErr == nil {
+				s.addDocumentResource(doc)
+			}
+		}
+		return nil, resp, nil
+	})
+
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "rag_list",
+		Description: "List documents in the AgentGo knowledge base.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, _ emptyInput) (*mcp.CallToolResult, any, error) {
+		docs, err := s.opts.RAG.ListDocuments(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		out := make([]map[string]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			out = append(out, map[string]interface{}{
+				"id":      doc.ID,
+				"name":    documentName(doc),
+				"uri":     documentURI(doc.ID),
+				"created": doc.Created,
+			})
+		}
+		return nil, map[string]interface{}{"count": len(out), "documents": out}, nil
+	})
+}
+
+type memorySearchInput struct {
+	Query string `json:"query" jsonschema:"what to search long-term memory for"`
+	Limit int    `json:"limit,omitempty" jsonschema:"maximum number of memories to return (default 5)"`
+}
+
+type memoryAddInput struct {
+	Content    string  `json:"content" jsonschema:"the memory content to store"`
+	Type       string  `json:"type,omitempty" jsonschema:"fact, skill, pattern, context or preference (default fact)"`
+	Importance float64 `json:"importance,omitempty" jsonschema:"importance between 0 and 1 (default 0.5)"`
+	SessionID  string  `json:"session_id,omitempty" jsonschema:"session to associate the memory with"`
+}
+
+func (s *Server) registerMemoryTools() {
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "memory_search",
+		Description: "Search AgentGo long-term memory.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in memorySearchInput) (*mcp.CallToolResult, any, error) {
+		if strings.TrimSpace(in.Query) == "" {
+			return nil, nil, fmt.Errorf("query is required")
+		}
+		ctx = domain.WithMemoryPrincipal(ctx, s.principal(req))
+		limit := in.Limit
+		if limit <= 0 {
+			limit = 5
+		}
+		memories, err := s.opts.Memory.Search(ctx, in.Query, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		out := make([]map[string]interface{}, 0, len(memories))
+		for _, mem := range memories {
+			out = append(out, map[string]interface{}{
+				"id":         mem.ID,
+				"type":       mem.Type,
+				"content":    mem.Content,
+				"score":      mem.Score,
+				"importance": mem.Importance,
+			})
+		}
+		return nil, map[string]interface{}{"count": len(out), "memories": out}, nil
+	})
+
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "memory_add",
+		Description: "Store a fact, preference or pattern in AgentGo long-term memory.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in memoryAddInput) (*mcp.CallToolResult, any, error) {
+		if strings.TrimSpace(in.Content) == "" {
+			return nil, nil, fmt.Errorf("content is required")
+		}
+		ctx = domain.WithMemoryPrincipal(ctx, s.principal(req))
+		memType := domain.MemoryType(in.Type)
+		if memType == "" {
+			memType = domain.MemoryTypeFact
+		}
+		importance := in.Importance
+		if importance <= 0 {
+			importance = 0.5
+		}
+		mem := &domain.Memory{
+			ID:         uuid.New().String(),
+			SessionID:  in.SessionID,
+			Type:       memType,
+			Content:    in.Content,
+			Importance: importance,
+		}
+		if err := s.opts.Memory.Add(ctx, mem); err != nil {
+			return nil, nil, err
+		}
+		return nil, map[string]interface{}{"id": mem.ID}, nil
+	})
+}
+
+func (s *Server) registerSkillTools() error {
+	defs, err := s.opts.Skills.RegisterAsMCPTools()
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		skillID := strings.TrimPrefix(def.Function.Name, "skill_")
+		s.server.AddTool(&mcp.Tool{
+			Name:        def.Function.Name,
+			Description: def.Function.Description,
+			InputSchema: def.Function.Parameters,
+		}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			vars := map[string]interface{}{}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &vars); err != nil {
+					return errorResult(fmt.Errorf("invalid arguments: %w", err)), nil
+				}
+			}
+			out, err := s.opts.Skills.RunSkill(ctx, skillID, vars)
+			if err != nil {
+				return errorResult(err), nil
+			}
+			return textResult(out), nil
+		})
+	}
+	return nil
+}
+
+type runAgentInput struct {
+	Agent string `json:"agent" jsonschema:"name of the agent to run"`
+	Task  string `json:"task" jsonschema:"instruction for the agent"`
+}
+
+type squadGoInput struct {
+	Task   string   `json:"task" jsonschema:"task for the squad"`
+	Squad  string   `json:"squad,omitempty" jsonschema:"squad name or ID (default squad when empty)"`
+	Agents []string `json:"agents,omitempty" jsonschema:"squad members to involve (the lead agent when empty)"`
+}
+
+func (s *Server) registerAgentTools() error {
+	agents, err := s.opts.Agents.ListAgents()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(agents))
+	for _, a := range agents {
+		names = append(names, a.Name)
+	}
+
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "run_agent",
+		Description: "Run an AgentGo agent on a task and return its answer. Available agents: " + strings.Join(names, ", ") + ".",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in runAgentInput) (*mcp.CallToolResult, any, error) {
+		if strings.TrimSpace(in.Agent) == "" || strings.TrimSpace(in.Task) == "" {
+			return nil, nil, fmt.Errorf("agent and task are required")
+		}
+		out, err := s.opts.Agents.DispatchTask(domain.WithMemoryPrincipal(ctx, s.principal(req)), in.Agent, in.Task)
+		if err != nil {
+			return nil, nil, err
+		}
+		return textResult(out), nil, nil
+	})
+
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "squad_go",
+		Description: "Hand a task to an AgentGo squad, wait for its lead agent to finish and return the result.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, in squadGoInput) (*mcp.CallToolResult, any, error) {
+		if strings.TrimSpace(in.Task) == "" {
+			return nil, nil, fmt.Errorf("task is required")
+		}
+		squadID, err := s.resolveSquad(in.Squad)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := s.runSquadTask(ctx, squadID, in.Task, in.Agents)
+		if err != nil {
+			return nil, nil, err
+		}
+		return textResult(out), nil, nil
+	})
+	return nil
+}
+
+func (s *Server) resolveSquad(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", nil
+	}
+	squads, err := s.opts.Agents.ListSquads()
+	if err != nil {
+		return "", err
+	}
+	for _, sq := range squads {
+		if sq.ID == ref || strings.EqualFold(sq.Name, ref) {
+			return sq.ID, nil
+		}
+	}
+	return "", fmt.Errorf("squad %q not found", ref)
+}
+
+// runSquadTask submits a squad task and blocks until it reaches a terminal
+// state, since MCP tool calls are request/response.
+func (s *Server) runSquadTask(ctx context.Context, squadID, task string, agentNames []string) (string, error) {
+	submitted, err := s.opts.Agents.SubmitSquadTask(ctx, "", squadID, task, agentNames)
+	if err != nil {
+		return "", err
+	}
+	events, unsubscribe, err := s.opts.Agents.SubscribeTask(submitted.ID)
+	if err != nil {
+		return "", err
+	}
+	defer unsubscribe()
+
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case evt, ok := <-events:
+			if !ok {
+				break wait
+			}
+			switch evt.Type {
+			case agent.TaskEventTypeCompleted, agent.TaskEventTypeFailed, agent.TaskEventTypeCancelled:
+				break wait
+			}
+		}
+	}
+
+	final, err := s.opts.Agents.GetTask(submitted.ID)
+	if err != nil {
+		return "", err
+	}
+	if final.Status != agent.AsyncTaskStatusCompleted {
+		if final.Error != "" {
+			return "", fmt.Errorf("squad task %s: %s", final.Status, final.Error)
+		}
+		return "", fmt.Errorf("squad task %s", final.Status)
+	}
+	return final.ResultText, nil
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+}
+
+func errorResult(err error) *mcp.CallToolResult {
+	res := &mcp.CallToolResult{}
+	res.SetError(err)
+	return res
+}
@@ -0,0 +1,335 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/agent"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/memory"
+	"github.com/liliang-cn/agent-go/pkg/prompt"
+	"github.com/liliang-cn/agent-go/pkg/rag"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type fakeKnowledgeBase struct {
+	docs     map[string]domain.Document
+	ingested []string
+}
+
+func (f *fakeKnowledgeBase) Query(ctx context.Context, query string, opts *rag.QueryOptions) (*domain.QueryResponse, error) {
+	return &domain.QueryResponse{Answer: "answer to " + query}, nil
+}
+
+func (f *fakeKnowledgeBase) IngestText(ctx context.Context, text, source string, opts *rag.IngestOptions) (*domain.IngestResponse, error) {
+	id := fmt.Sprintf("doc-%d", len(f.docs)+1)
+	f.docs[id] = domain.Document{ID: id, Content: text, Metadata: map[string]interface{}{"source": source}}
+	return &domain.IngestResponse{Success: true, DocumentID: id, ChunkCount: 1}, nil
+}
+
+func (f *fakeKnowledgeBase) IngestFile(ctx context.Context, filePath string, opts *rag.IngestOptions) (*domain.IngestResponse, error) {
+	f.ingested = append(f.ingested, filePath)
+	return &domain.IngestResponse{Success: true, ChunkCount: 1}, nil
+}
+
+func (f *fakeKnowledgeBase) ListDocuments(ctx context.Context) ([]domain.Document, error) {
+	var out []domain.Document
+	for _, d := range f.docs {
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func (f *fakeKnowledgeBase) GetDocument(ctx context.Context, id string) (domain.Document, error) {
+	d, ok := f.docs[id]
+	if !ok {
+		return domain.Document{}, fmt.Errorf("not found")
+	}
+	return d, nil
+}
+
+type fakeAgents struct {
+	dispatched []string
+}
+
+func (f *fakeAgents) ListAgents() ([]*agent.AgentModel, error) {
+	return []*agent.AgentModel{{Name: "Writer"}}, nil
+}
+
+func (f *fakeAgents) ListSquads() ([]*agent.Squad, error) { return nil, nil }
+
+func (f *fakeAgents) DispatchTask(ctx context.Context, agentName, instruction string) (string, error) {
+	f.dispatched = append(f.dispatched, agentName+": "+instruction)
+	return "done by " + agentName, nil
+}
+
+func (f *fakeAgents) SubmitSquadTask(ctx context.Context, sessionID, squadID, prompt string, agentNames []string) (*agent.AsyncTask, error) {
+	return &agent.AsyncTask{ID: "t1"}, nil
+}
+
+func (f *fakeAgents) SubscribeTask(taskID string) (<-chan *agent.TaskEvent, func(), error) {
+	ch := make(chan *agent.TaskEvent, 1)
+	ch <- &agent.TaskEvent{TaskID: taskID, Type: agent.TaskEventTypeCompleted}
+	return ch, func() {}, nil
+}
+
+func (f *fakeAgents) GetTask(taskID string) (*agent.AsyncTask, error) {
+	return &agent.AsyncTask{ID: taskID, Status: agent.AsyncTaskStatusCompleted, ResultText: "squad result"}, nil
+}
+
+func connect(t *testing.T, opts Options) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	srv, err := New(ctx, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.MCPServer().Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect error = %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect error = %v", err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return cs
+}
+
+func callText(t *testing.T, cs *mcp.ClientSession, name string, args map[string]any) string {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) error = %v", name, err)
+	}
+	if res.IsError {
+		t.Fatalf("CallTool(%s) returned tool error: %v", name, res.Content)
+	}
+	var b strings.Builder
+	for _, c := range res.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			b.WriteString(tc.Text)
+		}
+	}
+	return b.String()
+}
+
+func TestServerPublishesRAGAndAgents(t *testing.T) {
+	kb := &fakeKnowledgeBase{docs: map[string]domain.Document{
+		"a": {ID: "a", Path: "/docs/guide.md", Content: "guide body"},
+	}}
+	agents := &fakeAgents{}
+	cs := connect(t, Options{RAG: kb, Agents: agents})
+	ctx := context.Background()
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	want := []string{"rag_ingest", "rag_list", "rag_query", "run_agent", "squad_go"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("tools = %v, want %v", names, want)
+	}
+
+	if got := callText(t, cs, "rag_query", map[string]any{"query": "why"}); !strings.Contains(got, "answer to why") {
+		t.Fatalf("rag_query = %q", got)
+	}
+	if got := callText(t, cs, "run_agent", map[string]any{"agent": "Writer", "task": "draft"}); got != "done by Writer" {
+		t.Fatalf("run_agent = %q", got)
+	}
+	if got := callText(t, cs, "squad_go", map[string]any{"task": "ship it"}); got != "squad result" {
+		t.Fatalf("squad_go = %q", got)
+	}
+
+	read, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: documentURI("a")})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if len(read.Contents) != 1 || read.Contents[0].Text != "guide body" {
+		t.Fatalf("ReadResource() = %+v", read.Contents)
+	}
+
+	callText(t, cs, "rag_ingest", map[string]any{"text": "new notes", "source": "notes.txt"})
+	resources, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	found := false
+	for _, r := range resources.Resources {
+		if r.Name == "notes.txt" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("ingested document not published as a resource: %+v", resources.Resources)
+	}
+}
+
+func TestServerRestrictsIngestPaths(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "notes.md")
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	for _, p := range []string{inside, outside} {
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	kb := &fakeKnowledgeBase{docs: map[string]domain.Document{}}
+	cs := connect(t, Options{RAG: kb, IngestDirs: []string{root}})
+	callText(t, cs, "rag_ingest", map[string]any{"path": inside})
+
+	for _, p := range []string{outside, filepath.Join(root, "link.txt"), filepath.Join(root, "..", filepath.Base(filepath.Dir(outside)), "secret.txt")} {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "rag_ingest", Arguments: map[string]any{"path": p}})
+		if err != nil {
+			t.Fatalf("CallTool(%s) error = %v", p, err)
+		}
+		if !res.IsError {
+			t.Fatalf("rag_ingest(%s) succeeded outside the ingest directories", p)
+		}
+	}
+
+	// Without ingest directories only text is accepted
+	cs = connect(t, Options{RAG: kb})
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "rag_ingest", Arguments: map[string]any{"path": inside}})
+	if err != nil || !res.IsError {
+		t.Fatalf("rag_ingest without ingest directories = %+v, %v", res, err)
+	}
+	if len(kb.ingested) != 1 || kb.ingested[0] != inside {
+		t.Fatalf("ingested = %v, want [%s]", kb.ingested, inside)
+	}
+}
+
+func TestServerPublishesPrompts(t *testing.T) {
+	pm := prompt.NewManager()
+	pm.SetPrompt("test.greet", "Hello {{.Name}}{{if .Title}}, {{.Title}}{{end}}{{range .Items}}{{.Ignored}}{{end}}")
+	cs := connect(t, Options{Prompts: pm})
+	ctx := context.Background()
+
+	prompts, err := cs.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts() error = %v", err)
+	}
+	var greet *mcp.Prompt
+	for _, p := range prompts.Prompts {
+		if p.Name == "test.greet" {
+			greet = p
+		}
+	}
+	if greet == nil {
+		t.Fatal("test.greet not published")
+	}
+	var args []string
+	for _, a := range greet.Arguments {
+		args = append(args, a.Name)
+	}
+	if want := []string{"Items", "Name", "Title"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("arguments = %v, want %v", args, want)
+	}
+
+	res, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: "test.greet", Arguments: map[string]string{"Name": "Ada", "Title": "Dr"}})
+	if err != nil {
+		t.Fatalf("GetPrompt() error = %v", err)
+	}
+	if text := res.Messages[0].Content.(*mcp.TextContent).Text; text != "Hello Ada, Dr" {
+		t.Fatalf("GetPrompt() = %q", text)
+	}
+}
+
+type bearerTransport struct{ token string }
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	if b.token != "" {
+		r.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestServerMemoryToolsApplyPrincipal(t *testing.T) {
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*domain.Memory{
+		{ID: "alice-1", SessionID: "user:alice", Content: "alice secret"},
+		{ID: "bob-1", SessionID: "user:bob", Content: "bob secret"},
+	} {
+		m.Type = domain.MemoryTypeFact
+		if err := fileStore.Store(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	memSvc := memory.NewService(fileStore, nil, nil, nil)
+	memSvc.SetAccessControl(nil, nil)
+	opts := Options{
+		Memory:    memSvc,
+		Principal: domain.SystemMemoryPrincipal(),
+		Authenticate: func(token string) *domain.MemoryPrincipal {
+			if token == "alice-token" {
+				return &domain.MemoryPrincipal{UserID: "alice"}
+			}
+			return nil
+		},
+		Token: "shared-token",
+	}
+
+	// stdio clients act for the local user
+	if got := callText(t, connect(t, opts), "memory_search", map[string]any{"query": "secret"}); !strings.Contains(got, "alice secret") || !strings.Contains(got, "bob secret") {
+		t.Fatalf("stdio memory_search = %q", got)
+	}
+
+	srv, err := New(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpSrv := httptest.NewServer(srv.HTTPHandler())
+	t.Cleanup(httpSrv.Close)
+	dial := func(token string) (*mcp.ClientSession, error) {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1"}, nil)
+		return client.Connect(context.Background(), &mcp.StreamableClientTransport{
+			Endpoint:   httpSrv.URL,
+			HTTPClient: &http.Client{Transport: bearerTransport{token: token}},
+		}, nil)
+	}
+	httpClient := func(token string) *mcp.ClientSession {
+		cs, err := dial(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = cs.Close() })
+		return cs
+	}
+	for _, token := range []string{"", "wrong-token"} {
+		if cs, err := dial(token); err == nil {
+			_ = cs.Close()
+			t.Fatalf("HTTP client with token %q was admitted", token)
+		}
+	}
+
+	got := callText(t, httpClient("alice-token"), "memory_search", map[string]any{"query": "secret"})
+	if !strings.Contains(got, "alice secret") || strings.Contains(got, "bob secret") {
+		t.Fatalf("alice memory_search = %q", got)
+	}
+	// the server token admits clients as anonymous memory callers
+	got = callText(t, httpClient("shared-token"), "memory_search", map[string]any{"query": "secret"})
+	if strings.Contains(got, "secret") {
+		t.Fatalf("anonymous memory_search = %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessTestService(t *testing.T) *Service {
+	t.Helper()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	for _, m := range []*domain.Memory{
+		{ID: "alice-1", SessionID: "user:alice", Content: "Alice prefers dark mode in every editor"},
+		{ID: "bob-1", SessionID: "user:bob", Content: "Bob prefers light mode in every editor"},
+	} {
+		m.Type = domain.MemoryTypePreference
+		m.Importance = 0.9
+		m.CreatedAt = time.Now()
+		require.NoError(t, fileStore.Store(context.Background(), m))
+	}
+	svc := NewService(fileStore, nil, nil, nil)
+	svc.SetAccessControl(nil, nil)
+	return svc
+}
+
+func TestRetrieveAndInject_RedactsOtherUsers(t *testing.T) {
+	svc := newAccessTestService(t)
+	ctx := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "alice"})
+
+	text, mems, err := svc.RetrieveAndInject(ctx, "which editor mode is preferred", "")
+	require.NoError(t, err)
+	require.Len(t, mems, 1)
+	assert.Equal(t, "alice-1", mems[0].ID)
+	assert.NotContains(t, text, "Bob")
+
+	// Without a principal the caller is anonymous and sees neither user scope
+	_, mems, err = svc.RetrieveAndInject(context.Background(), "which editor mode is preferred", "")
+	require.NoError(t, err)
+	assert.Empty(t, mems)
+
+	// The local process sees everything
+	_, mems, err = svc.RetrieveAndInject(domain.WithSystemMemoryPrincipal(context.Background()), "which editor mode is preferred", "")
+	require.NoError(t, err)
+	assert.Len(t, mems, 2)
+}
+
+func TestService_AccessControlledWrites(t *testing.T) {
+	svc := newAccessTestService(t)
+	ctx := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "alice"})
+
+	err := svc.Delete(ctx, "bob-1")
+	assert.ErrorIs(t, err, store.ErrMemoryNotFound)
+
+	err = svc.Add(ctx, &domain.Memory{Type: domain.MemoryTypeFact, Content: "A shared fact everyone should see"})
+	assert.ErrorIs(t, err, store.ErrMemoryAccessDenied)
+
+	// Maintenance still reaches the file store through the wrapper
+	_, ok := svc.fileStore()
+	assert.True(t, ok)
+}
+
+func TestStoreIfWorthwhile_UnderAccessControl(t *testing.T) {
+	svc := newAccessTestService(t)
+	llm := new(MockGenerator)
+	llm.On("GenerateStructured", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&domain.StructuredResult{
+		Valid: true,
+		Raw:   `{"should_store":true,"memories":[{"type":"preference","content":"Alice deploys on Fridays","importance":0.8}]}`,
+	}, nil)
+	svc.llm = llm
+	req := &domain.MemoryStoreRequest{SessionID: "chat-1", TaskGoal: "plan the release", TaskResult: "done"}
+
+	// A signed-in user's memories land in their own scope, not the session's
+	alice := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "alice"})
+	require.NoError(t, svc.StoreIfWorthwhile(alice, req))
+	_, mems, err := svc.RetrieveAndInject(alice, "when does Alice deploy", "chat-1")
+	require.NoError(t, err)
+	var stored *domain.Memory
+	for _, m := range mems {
+		if m.Content == "Alice deploys on Fridays" {
+			stored = m.Memory
+		}
+	}
+	require.NotNil(t, stored, "the user reads back what the agent stored for them")
+	assert.Equal(t, "user:alice", stored.SessionID)
+
+	// Anonymous callers own no scope, and the denied write is reported
+	err = svc.StoreIfWorthwhile(context.Background(), req)
+	assert.ErrorIs(t, err, store.ErrMemoryAccessDenied)
+}
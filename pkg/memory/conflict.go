@@ -0,0 +1,385 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// ConflictOutcome is the LLM's verdict when a new memory is compared against
+// semantically close existing memories
+type ConflictOutcome string
+
+const (
+	ConflictOutcomeNew           ConflictOutcome = "new"           // unrelated; store as-is
+	ConflictOutcomeDuplicate     ConflictOutcome = "duplicate"     // same fact; merge evidence into the existing memory
+	ConflictOutcomeUpdate        ConflictOutcome = "update"        // newer version; supersede the existing memory
+	ConflictOutcomeContradiction ConflictOutcome = "contradiction" // incompatible; keep both flagged for review
+)
+
+// Metadata keys used to pair contradicting memories for the review queue
+const (
+	metaConflictsWith  = "conflicts_with"
+	metaConflictReason = "conflict_reason"
+)
+
+const (
+	// conflictCandidates is how many close memories are shown to the LLM
+	conflictCandidates = 5
+	// conflictPenalty scales the confidence of both sides of a contradiction
+	conflictPenalty = 0.5
+)
+
+// ConflictDecision is the parsed result of a conflict check
+type ConflictDecision struct {
+	Outcome    ConflictOutcome `json:"outcome"`
+	ExistingID string          `json:"existing_id,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+}
+
+// MemoryConflict is an unresolved contradiction awaiting human review
+type MemoryConflict struct {
+	Memories   []*domain.Memory `json:"memories"`
+	Reason     string           `json:"reason,omitempty"`
+	DetectedAt time.Time        `json:"detected_at"`
+}
+
+// checksConflicts reports whether a memory type carries claims that can
+// contradict one another
+func checksConflicts(t domain.MemoryType) bool {
+	return t == domain.MemoryTypeFact || t == domain.MemoryTypePreference || t == domain.MemoryTypeObservation
+}
+
+// findConflictCandidates returns currently valid memories close to mem, by vector
+// similarity when an embedding is available and by keyword overlap otherwise
+func (s *Service) findConflictCandidates(ctx context.Context, mem *domain.Memory) []*domain.Memory {
+	var hits []*domain.MemoryWithScore
+	if len(mem.Vector) > 0 {
+		searchStore := s.store
+		if s.shadowIndex != nil {
+			searchStore = s.shadowIndex
+		}
+		hits, _ = searchStore.Search(ctx, mem.Vector, conflictCandidates, s.conflictThreshold)
+	} else if temporal, err := s.temporalStore(); err == nil {
+		hits, _ = temporal.SearchTemporal(ctx, &domain.MemoryTemporalQuery{Text: mem.Content, TopK: conflictCandidates})
+	} else {
+		hits, _ = s.store.SearchByText(ctx, mem.Content, conflictCandidates)
+	}
+
+	var candidates []*domain.Memory
+	for _, h := range hits {
+		if h == nil || h.Memory == nil || h.ID == mem.ID || h.Score < s.conflictThreshold {
+			continue
+		}
+		if h.ValidTo != nil || h.SupersededBy != "" || !checksConflicts(h.Type) {
+			continue
+		}
+		candidates = append(candidates, h.Memory)
+	}
+	return candidates
+}
+
+// detectConflict asks the LLM how mem relates to its closest existing memories
+func (s *Service) detectConflict(ctx context.Context, mem *domain.Memory, candidates []*domain.Memory) *ConflictDecision {
+	var existing strings.Builder
+	for _, c := range candidates {
+		existing.WriteString(fmt.Sprintf("- [%s] (%s) %s\n", c.ID, c.Type, c.Content))
+	}
+
+	prompt := fmt.Sprintf(`You maintain a long-term memory store. Compare the NEW memory against the EXISTING ones.
+
+NEW memory (%s):
+%s
+
+EXISTING memories:
+%s
+Decide exactly one outcome:
+- "new": the new memory is about something else; nothing to change.
+- "duplicate": it states the same thing as one existing memory.
+- "update": it is a newer version of one existing memory (the old one is now outdated).
+- "contradiction": it conflicts with one existing memory and it is unclear which is right.
+
+For duplicate, update and contradiction set existing_id to the ID of the existing memory involved.
+Return JSON only.`, mem.Type, mem.Content, existing.String())
+
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"outcome":     map[string]interface{}{"type": "string", "enum": []string{"new", "duplicate", "update", "contradiction"}},
+			"existing_id": map[string]interface{}{"type": "string"},
+			"reason":      map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"outcome"},
+	}
+
+	result, err := s.llm.GenerateStructured(ctx, prompt, schema, &domain.GenerationOptions{Temperature: 0.1})
+	if err != nil || result == nil || result.Raw == "" || !result.Valid {
+		return &ConflictDecision{Outcome: ConflictOutcomeNew}
+	}
+	var decision ConflictDecision
+	if err := json.Unmarshal([]byte(result.Raw), &decision); err != nil {
+		return &ConflictDecision{Outcome: ConflictOutcomeNew}
+	}
+	if decision.Outcome != ConflictOutcomeNew {
+		found := false
+		for _, c := range candidates {
+			if c.ID == decision.ExistingID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			// The LLM referenced a memory it was not shown
+			return &ConflictDecision{Outcome: ConflictOutcomeNew}
+		}
+	}
+	return &decision
+}
+
+// checkConflict compares a memory about to be stored with its closest
+// existing memories. It returns nil when conflict detection does not apply
+// or the memory is unrelated to anything stored.
+func (s *Service) checkConflict(ctx context.Context, mem *domain.Memory) (*ConflictDecision, *domain.Memory) {
+	if s.llm == nil || !s.detectConflicts || !checksConflicts(mem.Type) {
+		return nil, nil
+	}
+	candidates := s.findConflictCandidates(ctx, mem)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	decision := s.detectConflict(ctx, mem, candidates)
+	for _, c := range candidates {
+		if c.ID != decision.ExistingID {
+			continue
+		}
+		// Candidates may come from the shadow index, whose copy is not the
+		// record of truth; changes are made to the primary record
+		existing, err := s.store.Get(ctx, c.ID)
+		if err != nil || existing == nil {
+			return nil, nil
+		}
+		return decision, existing
+	}
+	return nil, nil
+}
+
+// mergeDuplicate folds a duplicate memory's evidence into the existing one
+func (s *Service) mergeDuplicate(ctx context.Context, existing, dup *domain.Memory, by string) error {
+	now := time.Now()
+	existing.EvidenceIDs = appendUnique(existing.EvidenceIDs, dup.EvidenceIDs...)
+	if dup.Importance > existing.Importance {
+		existing.Importance = dup.Importance
+	}
+	existing.UpdatedAt = now
+	existing.RevisionHistory = append(existing.RevisionHistory, domain.MemoryRevision{
+		At: now, By: by, Summary: "merged duplicate: " + truncateContent(dup.Content),
+	})
+	return s.store.Update(ctx, existing)
+}
+
+// flagConflict marks m as part of a contradiction with otherID and lowers
+// its confidence until a human resolves it
+func flagConflict(m *domain.Memory, otherID, reason, summary string, at time.Time) {
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]interface{})
+	}
+	ids := appendUnique(splitIDs(m.Metadata[metaConflictsWith]), otherID)
+	m.Metadata[metaConflictsWith] = strings.Join(ids, ",")
+	if reason != "" {
+		m.Metadata[metaConflictReason] = reason
+	}
+	if !m.Conflicting {
+		m.Confidence = effectiveConfidence(m) * conflictPenalty
+	}
+	m.Conflicting = true
+	m.UpdatedAt = at
+	m.RevisionHistory = append(m.RevisionHistory, domain.MemoryRevision{At: at, By: "agent", Summary: summary})
+}
+
+// supersede closes old's validity window in favour of newID
+func (s *Service) supersede(ctx context.Context, old *domain.Memory, newID, by, reason string) error {
+	now := time.Now()
+	old.ValidTo = &now
+	old.SupersededBy = newID
+	old.UpdatedAt = now
+	summary := fmt.Sprintf("superseded by %s", newID)
+	if reason != "" {
+		summary += ": " + reason
+	}
+	old.RevisionHistory = append(old.RevisionHistory, domain.MemoryRevision{At: now, By: by, Summary: summary})
+	return s.store.Update(ctx, old)
+}
+
+// Conflicts returns unresolved contradictions, newest first. Each entry
+// groups the memories that contradict one another.
+func (s *Service) Conflicts(ctx context.Context) ([]*MemoryConflict, error) {
+	all, _, err := s.store.List(ctx, 10000, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	flagged := make(map[string]*domain.Memory)
+	for _, m := range all {
+		if m.Conflicting && m.ValidTo == nil {
+			flagged[m.ID] = m
+		}
+	}
+
+	seen := make(map[string]bool)
+	var conflicts []*MemoryConflict
+	for _, m := range all {
+		if flagged[m.ID] == nil || seen[m.ID] {
+			continue
+		}
+		conflict := &MemoryConflict{}
+		queue := []*domain.Memory{m}
+		seen[m.ID] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			conflict.Memories = append(conflict.Memories, current)
+			if reason, _ := current.Metadata[metaConflictReason].(string); reason != "" && conflict.Reason == "" {
+				conflict.Reason = reason
+			}
+			if current.UpdatedAt.After(conflict.DetectedAt) {
+				conflict.DetectedAt = current.UpdatedAt
+			}
+			for _, id := range splitIDs(current.Metadata[metaConflictsWith]) {
+				if other := flagged[id]; other != nil && !seen[id] {
+					seen[id] = true
+					queue = append(queue, other)
+				}
+			}
+		}
+		sort.SliceStable(conflict.Memories, func(i, j int) bool {
+			return conflict.Memories[i].EffectiveFrom().Before(conflict.Memories[j].EffectiveFrom())
+		})
+		conflicts = append(conflicts, conflict)
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].DetectedAt.After(conflicts[j].DetectedAt)
+	})
+	return conflicts, nil
+}
+
+// ResolveConflict settles a contradiction in favour of winnerID: the winner
+// is unflagged with its confidence restored and every memory it conflicted
+// with is superseded by it. by names the reviewer in the revision history.
+func (s *Service) ResolveConflict(ctx context.Context, winnerID, by string) error {
+	if by == "" {
+		by = "user"
+	}
+	ctx = store.WithMemoryChange(ctx, by, "resolve conflict in favour of "+winnerID)
+	winner, err := s.store.Get(ctx, winnerID)
+	if err != nil {
+		return fmt.Errorf("memory %s not found: %w", winnerID, err)
+	}
+	if !winner.Conflicting {
+		return fmt.Errorf("memory %s has no open conflict", winnerID)
+	}
+
+	losers := splitIDs(winner.Metadata[metaConflictsWith])
+	for _, id := range losers {
+		loser, err := s.store.Get(ctx, id)
+		if err != nil || loser.ValidTo != nil {
+			continue
+		}
+		remaining := removeID(splitIDs(loser.Metadata[metaConflictsWith]), winnerID)
+		setConflictsWith(loser, remaining)
+		if len(remaining) == 0 {
+			loser.Conflicting = false
+			delete(loser.Metadata, metaConflictReason)
+		}
+		if err := s.supersede(ctx, loser, winnerID, by, "conflict resolved in favour of "+winnerID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	winner.Conflicting = false
+	winner.Confidence = effectiveConfidence(winner) / conflictPenalty
+	if winner.Confidence > 1 {
+		winner.Confidence = 1
+	}
+	setConflictsWith(winner, nil)
+	delete(winner.Metadata, metaConflictReason)
+	winner.UpdatedAt = now
+	winner.RevisionHistory = append(winner.RevisionHistory, domain.MemoryRevision{
+		At: now, By: by, Summary: fmt.Sprintf("conflict resolved: kept over %s", strings.Join(losers, ", ")),
+	})
+	if err := s.store.Update(ctx, winner); err != nil {
+		return err
+	}
+
+	if s.navigator != nil {
+		s.navigator.InvalidateCache()
+	}
+	return nil
+}
+
+func setConflictsWith(m *domain.Memory, ids []string) {
+	if len(ids) == 0 {
+		delete(m.Metadata, metaConflictsWith)
+		return
+	}
+	m.Metadata[metaConflictsWith] = strings.Join(ids, ",")
+}
+
+// effectiveConfidence treats an unset confidence as fully trusted
+func effectiveConfidence(m *domain.Memory) float64 {
+	if m.Confidence <= 0 {
+		return 1.0
+	}
+	return m.Confidence
+}
+
+func splitIDs(v interface{}) []string {
+	raw, _ := v.(string)
+	var ids []string
+	for _, id := range strings.Split(raw, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func removeID(ids []string, id string) []string {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found && v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func truncateContent(s string) string {
+	if r := []rune(s); len(r) > 80 {
+		return string(r[:80]) + "…"
+	}
+	return s
+}
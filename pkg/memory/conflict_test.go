@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newConflictTestService(t *testing.T) (*Service, *MockGenerator) {
+	t.Helper()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	llm := new(MockGenerator)
+	return NewService(fileStore, llm, nil, nil), llm
+}
+
+func expectDecision(llm *MockGenerator, decision ConflictDecision) {
+	raw, _ := json.Marshal(decision)
+	llm.On("GenerateStructured", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&domain.StructuredResult{Raw: string(raw), Valid: true}, nil).Once()
+}
+
+func addFact(t *testing.T, svc *Service, id, content string) {
+	t.Helper()
+	require.NoError(t, svc.Add(context.Background(), &domain.Memory{
+		ID: id, Type: domain.MemoryTypeFact, Content: content, Importance: 0.8, CreatedAt: time.Now(),
+	}))
+}
+
+func TestConflictDetection_ContradictionAndResolve(t *testing.T) {
+	ctx := context.Background()
+	svc, llm := newConflictTestService(t)
+
+	addFact(t, svc, "editor-vim", "User's favourite editor is Vim")
+	expectDecision(llm, ConflictDecision{Outcome: ConflictOutcomeContradiction, ExistingID: "editor-vim", Reason: "different editors"})
+	addFact(t, svc, "editor-emacs", "User's favourite editor is Emacs")
+	llm.AssertExpectations(t)
+
+	for _, id := range []string{"editor-vim", "editor-emacs"} {
+		m, err := svc.Get(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, m.Conflicting, id)
+		assert.InDelta(t, 0.5, m.Confidence, 1e-9, id)
+		assert.Nil(t, m.ValidTo, "both sides stay valid until reviewed")
+	}
+
+	conflicts, err := svc.Conflicts(ctx)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Len(t, conflicts[0].Memories, 2)
+	assert.Equal(t, "different editors", conflicts[0].Reason)
+
+	require.NoError(t, svc.ResolveConflict(ctx, "editor-emacs", "alice"))
+
+	winner, _ := svc.Get(ctx, "editor-emacs")
+	assert.False(t, winner.Conflicting)
+	assert.InDelta(t, 1.0, winner.Confidence, 1e-9)
+	assert.Equal(t, "alice", winner.RevisionHistory[len(winner.RevisionHistory)-1].By)
+
+	loser, _ := svc.Get(ctx, "editor-vim")
+	assert.False(t, loser.Conflicting)
+	assert.Equal(t, "editor-emacs", loser.SupersededBy)
+	require.NotNil(t, loser.ValidTo)
+
+	conflicts, err = svc.Conflicts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	assert.Error(t, svc.ResolveConflict(ctx, "editor-emacs", "alice"), "already resolved")
+}
+
+func TestConflictDetection_UpdateSupersedes(t *testing.T) {
+	ctx := context.Background()
+	svc, llm := newConflictTestService(t)
+
+	addFact(t, svc, "deploy-old", "Production deploys run on Fridays")
+	expectDecision(llm, ConflictDecision{Outcome: ConflictOutcomeUpdate, ExistingID: "deploy-old"})
+	addFact(t, svc, "deploy-new", "Production deploys run on Tuesdays")
+
+	old, err := svc.Get(ctx, "deploy-old")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy-new", old.SupersededBy)
+	require.NotNil(t, old.ValidTo)
+
+	history, err := svc.History(ctx, "deploy-new")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "deploy-old", history[0].ID)
+}
+
+func TestConflictDetection_DuplicateMerges(t *testing.T) {
+	ctx := context.Background()
+	svc, llm := newConflictTestService(t)
+
+	addFact(t, svc, "tz", "User works in the Berlin timezone")
+	expectDecision(llm, ConflictDecision{Outcome: ConflictOutcomeDuplicate, ExistingID: "tz"})
+	dup := &domain.Memory{
+		ID: "tz-dup", Type: domain.MemoryTypeFact, Content: "User works in the Berlin timezone (CET)",
+		Importance: 0.95, EvidenceIDs: []string{"msg-42"},
+	}
+	require.NoError(t, svc.Add(ctx, dup))
+	assert.Equal(t, "tz", dup.ID, "caller is pointed at the merged memory")
+
+	_, err := svc.Get(ctx, "tz-dup")
+	assert.Error(t, err, "duplicate must not be stored")
+
+	merged, err := svc.Get(ctx, "tz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"msg-42"}, merged.EvidenceIDs)
+	assert.Equal(t, 0.95, merged.Importance)
+	assert.Len(t, merged.RevisionHistory, 1)
+}
+
+func TestConflictDetection_UnknownIDIsIgnored(t *testing.T) {
+	ctx := context.Background()
+	svc, llm := newConflictTestService(t)
+
+	addFact(t, svc, "lang", "The backend is written in Go")
+	expectDecision(llm, ConflictDecision{Outcome: ConflictOutcomeUpdate, ExistingID: "made-up"})
+	addFact(t, svc, "lang-2", "The backend is written in Go 1.22")
+
+	m, err := svc.Get(ctx, "lang")
+	require.NoError(t, err)
+	assert.Empty(t, m.SupersededBy)
+}
+
+// staleIndex is a shadow index whose copies lag behind the primary store
+type staleIndex struct {
+	domain.MemoryStore
+	hits []*domain.MemoryWithScore
+}
+
+func (s *staleIndex) Search(ctx context.Context, vector []float64, topK int, minScore float64) ([]*domain.MemoryWithScore, error) {
+	return s.hits, nil
+}
+
+func (s *staleIndex) Store(ctx context.Context, m *domain.Memory) error  { return nil }
+func (s *staleIndex) Update(ctx context.Context, m *domain.Memory) error { return nil }
+
+func TestConflictDetection_ShadowCandidateIsRefetched(t *testing.T) {
+	ctx := context.Background()
+	svc, llm := newConflictTestService(t)
+	require.NoError(t, svc.store.Store(ctx, &domain.Memory{
+		ID: "tz", Type: domain.MemoryTypeFact, Content: "User works in the Berlin timezone",
+		Importance: 0.8, EvidenceIDs: []string{"msg-1"}, CreatedAt: time.Now(),
+	}))
+	svc.SetShadowIndex(&staleIndex{hits: []*domain.MemoryWithScore{{
+		Memory: &domain.Memory{ID: "tz", Type: domain.MemoryTypeFact, Content: "stale index copy", Importance: 0.1},
+		Score:  0.99,
+	}}})
+
+	expectDecision(llm, ConflictDecision{Outcome: ConflictOutcomeDuplicate, ExistingID: "tz"})
+	require.NoError(t, svc.Add(ctx, &domain.Memory{
+		ID: "tz-dup", Type: domain.MemoryTypeFact, Content: "User is in CET",
+		Importance: 0.5, EvidenceIDs: []string{"msg-42"}, Vector: []float64{1, 0},
+	}))
+
+	merged, err := svc.Get(ctx, "tz")
+	require.NoError(t, err)
+	assert.Equal(t, "User works in the Berlin timezone", merged.Content, "the index copy must not overwrite the record")
+	assert.Equal(t, []string{"msg-1", "msg-42"}, merged.EvidenceIDs)
+	assert.Equal(t, 0.8, merged.Importance)
+}
+
+func TestRetrieve_SkipsSupersededAndExpired(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	for _, m := range []*domain.Memory{
+		{ID: "old", Content: "Deploys run on Fridays", SupersededBy: "new", ValidTo: &past},
+		{ID: "new", Content: "Deploys run on Tuesdays"},
+		{ID: "expired", Content: "Office closed this week", ValidTo: &past},
+		{ID: "expiring", Content: "Freeze until next month", ValidTo: &future},
+	} {
+		m.Type = domain.MemoryTypeFact
+		m.Importance = 0.9
+		m.CreatedAt = time.Now().Add(-2 * time.Hour)
+		require.NoError(t, fileStore.Store(ctx, m))
+	}
+	svc := NewService(fileStore, nil, nil, nil)
+
+	// No keyword matches, so retrieval falls back to recent memories
+	_, mems, err := svc.RetrieveAndInject(ctx, "what did we decide before?", "")
+	require.NoError(t, err)
+	var ids []string
+	for _, m := range mems {
+		ids = append(ids, m.ID)
+	}
+	assert.ElementsMatch(t, []string{"new", "expiring"}, ids)
+}
@@ -13,6 +13,7 @@ import (
 type EntityMemory struct {
 	store    domain.MemoryStore
 	embedder domain.Embedder
+	graph    *EntityGraph // optional; entities are also upserted as graph nodes
 }
 
 // NewEntityMemory creates a new entity memory manager
@@ -41,7 +42,17 @@ func (em *EntityMemory) SaveEntity(ctx context.Context, entity domain.Entity) er
 		},
 	}
 
-	return em.store.Store(ctx, memory)
+	if err := em.store.Store(ctx, memory); err != nil {
+		return err
+	}
+	if em.graph != nil {
+		e, err := em.graph.UpsertEntity(ctx, entity)
+		if err != nil {
+			return err
+		}
+		return em.graph.LinkFact(ctx, memory, e)
+	}
+	return nil
 }
 
 // SearchEntities searches for entities related to a query
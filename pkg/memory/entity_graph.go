@@ -0,0 +1,789 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// Node and edge types the entity graph writes. They are namespaced so the
+// graph can share a backend with GraphRAG.
+const (
+	EntityNodeType    = "memory_entity"
+	FactNodeType      = "memory_fact"
+	MentionsEdgeType  = "mentions"
+	entityNodePrefix  = "entity:"
+	factNodePrefix    = "memory:"
+	relationEdgeIDFmt = "rel:%s|%s|%s"
+	mentionEdgeIDFmt  = "mention:%s|%s"
+)
+
+// GraphEntity is a canonical entity node
+type GraphEntity struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Depth       int      `json:"depth"` // hops from the queried entity
+}
+
+// EntityRelation is a typed edge between two entities
+type EntityRelation struct {
+	From     string   `json:"from"`
+	Relation string   `json:"relation"`
+	To       string   `json:"to"`
+	FactIDs  []string `json:"fact_ids,omitempty"` // memories the relation was extracted from
+}
+
+// EntityQuery controls a graph traversal
+type EntityQuery struct {
+	Depth     int      // hops to follow from the entity (default 1)
+	Relations []string // only follow these relation types (empty = all)
+	MaxFacts  int      // cap on returned facts (default 20)
+}
+
+// EntityGraphResult is everything known about an entity and its neighbourhood
+type EntityGraphResult struct {
+	Entity    *GraphEntity     `json:"entity"`
+	Entities  []*GraphEntity   `json:"entities"`
+	Relations []EntityRelation `json:"relations"`
+	Facts     []*domain.Memory `json:"facts"`
+}
+
+// EntityExtraction is what the LLM finds in a memory
+type EntityExtraction struct {
+	Entities  []domain.Entity `json:"entities"`
+	Relations []struct {
+		Subject  string `json:"subject"`
+		Relation string `json:"relation"`
+		Object   string `json:"object"`
+	} `json:"relations"`
+}
+
+// EntityGraph keeps entities mentioned in memories as canonical graph nodes,
+// resolving aliases, with typed relations between entities and "mentions"
+// edges from memories to the entities they talk about. Any domain.GraphStore
+// that is also a domain.GraphReader can back it, including the RAG graph.
+type EntityGraph struct {
+	graph  domain.GraphStore
+	reader domain.GraphReader
+	llm    domain.Generator
+
+	mu      sync.Mutex
+	aliases map[string]string // lowercased name or alias -> node ID
+}
+
+// NewEntityGraph creates an entity graph over g. llm is only needed to
+// extract entities from memories.
+func NewEntityGraph(g domain.GraphStore, llm domain.Generator) (*EntityGraph, error) {
+	reader, ok := g.(domain.GraphReader)
+	if !ok {
+		return nil, fmt.Errorf("graph store %T cannot be traversed (no domain.GraphReader)", g)
+	}
+	return &EntityGraph{graph: g, reader: reader, llm: llm}, nil
+}
+
+// loadAliases builds the alias index on first use. Caller must hold eg.mu.
+func (eg *EntityGraph) loadAliases(ctx context.Context) error {
+	if eg.aliases != nil {
+		return nil
+	}
+	nodes, err := eg.reader.ListNodes(ctx, EntityNodeType)
+	if err != nil {
+		return err
+	}
+	eg.aliases = make(map[string]string)
+	for _, n := range nodes {
+		e := entityFromNode(&n)
+		eg.aliases[aliasKey(e.Name)] = n.ID
+		for _, a := range e.Aliases {
+			eg.aliases[aliasKey(a)] = n.ID
+		}
+	}
+	return nil
+}
+
+// Resolve finds the canonical entity for a name or alias
+func (eg *EntityGraph) Resolve(ctx context.Context, name string) (*GraphEntity, error) {
+	eg.mu.Lock()
+	err := eg.loadAliases(ctx)
+	id, ok := eg.aliases[aliasKey(name)]
+	eg.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %q", name)
+	}
+	node, err := eg.reader.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return entityFromNode(node), nil
+}
+
+// UpsertEntity adds an entity or merges it into the existing node its name
+// or any alias resolves to. The first name seen stays canonical; later
+// names become aliases.
+func (eg *EntityGraph) UpsertEntity(ctx context.Context, entity domain.Entity) (*GraphEntity, error) {
+	name := strings.TrimSpace(entity.Name)
+	if name == "" {
+		return nil, fmt.Errorf("entity requires a name")
+	}
+
+	eg.mu.Lock()
+	defer eg.mu.Unlock()
+	if err := eg.loadAliases(ctx); err != nil {
+		return nil, err
+	}
+
+	var existing *GraphEntity
+	for _, n := range append([]string{name}, entity.Aliases...) {
+		if id, ok := eg.aliases[aliasKey(n)]; ok {
+			if node, err := eg.reader.GetNode(ctx, id); err == nil {
+				existing = entityFromNode(node)
+				break
+			}
+		}
+	}
+
+	e := existing
+	if e == nil {
+		e = &GraphEntity{ID: entityNodePrefix + slug(name), Name: name}
+	}
+	if e.Type == "" {
+		e.Type = entity.Type
+	}
+	if e.Description == "" || len(entity.Description) > len(e.Description) {
+		if entity.Description != "" {
+			e.Description = entity.Description
+		}
+	}
+	for _, a := range append([]string{name}, entity.Aliases...) {
+		a = strings.TrimSpace(a)
+		if a != "" && aliasKey(a) != aliasKey(e.Name) && !containsFold(e.Aliases, a) {
+			e.Aliases = append(e.Aliases, a)
+		}
+	}
+
+	if err := eg.graph.UpsertNode(ctx, entityNode(e)); err != nil {
+		return nil, err
+	}
+	eg.aliases[aliasKey(e.Name)] = e.ID
+	for _, a := range e.Aliases {
+		eg.aliases[aliasKey(a)] = e.ID
+	}
+	return e, nil
+}
+
+// Relate records "from relation to", creating either entity if needed.
+// factID, if set, is the memory the relation came from.
+func (eg *EntityGraph) Relate(ctx context.Context, from, relation, to string, factID string) error {
+	relation = normalizeRelation(relation)
+	if relation == "" || relation == MentionsEdgeType {
+		return fmt.Errorf("invalid relation %q", relation)
+	}
+	fromE, err := eg.UpsertEntity(ctx, domain.Entity{Name: from})
+	if err != nil {
+		return err
+	}
+	toE, err := eg.UpsertEntity(ctx, domain.Entity{Name: to})
+	if err != nil {
+		return err
+	}
+
+	edgeID := fmt.Sprintf(relationEdgeIDFmt, fromE.ID, relation, toE.ID)
+	var factIDs []string
+	if edges, err := eg.reader.GetEdges(ctx, fromE.ID, "out"); err == nil {
+		for _, e := range edges {
+			if e.ID == edgeID {
+				factIDs = propStrings(e.Properties, "fact_ids")
+			}
+		}
+	}
+	if factID != "" && !containsFold(factIDs, factID) {
+		factIDs = append(factIDs, factID)
+	}
+
+	return eg.graph.UpsertEdge(ctx, domain.GraphEdge{
+		ID:         edgeID,
+		FromNodeID: fromE.ID,
+		ToNodeID:   toE.ID,
+		EdgeType:   relation,
+		Weight:     1.0,
+		Properties: map[string]interface{}{"fact_ids": factIDs},
+	})
+}
+
+// LinkFact records that memory m mentions each entity
+func (eg *EntityGraph) LinkFact(ctx context.Context, m *domain.Memory, entities ...*GraphEntity) error {
+	factNode := domain.GraphNode{
+		ID:         factNodePrefix + m.ID,
+		Content:    truncateRunes(m.Content, 200),
+		NodeType:   FactNodeType,
+		Properties: map[string]interface{}{"memory_id": m.ID, "memory_type": string(m.Type)},
+	}
+	if err := eg.graph.UpsertNode(ctx, factNode); err != nil {
+		return err
+	}
+	for _, e := range entities {
+		if err := eg.graph.UpsertEdge(ctx, domain.GraphEdge{
+			ID:         fmt.Sprintf(mentionEdgeIDFmt, factNode.ID, e.ID),
+			FromNodeID: factNode.ID,
+			ToNodeID:   e.ID,
+			EdgeType:   MentionsEdgeType,
+			Weight:     1.0,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extract asks the LLM for the entities and relations a memory states
+func (eg *EntityGraph) Extract(ctx context.Context, m *domain.Memory) (*EntityExtraction, error) {
+	if eg.llm == nil {
+		return nil, fmt.Errorf("LLM required for entity extraction")
+	}
+
+	prompt := fmt.Sprintf(`Extract the named entities (people, projects, organizations, products, places, concepts) mentioned in this memory and the relationships it states between them. RETURN VALID JSON ONLY.
+
+Memory: %s
+
+Use short snake_case relation names such as owns, works_on, member_of, depends_on, located_in. List other names used for the same entity as aliases.`, m.Content)
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"entities": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name":        map[string]interface{}{"type": "string"},
+						"type":        map[string]interface{}{"type": "string"},
+						"description": map[string]interface{}{"type": "string"},
+						"aliases":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					},
+					"required": []string{"name", "type"},
+				},
+			},
+			"relations": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"subject":  map[string]interface{}{"type": "string"},
+						"relation": map[string]interface{}{"type": "string"},
+						"object":   map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"subject", "relation", "object"},
+				},
+			},
+		},
+		"required": []string{"entities", "relations"},
+	}
+
+	result, err := eg.llm.GenerateStructured(ctx, prompt, schema, &domain.GenerationOptions{Temperature: 0.1})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || !result.Valid {
+		return nil, fmt.Errorf("invalid entity extraction response")
+	}
+	var ex EntityExtraction
+	if err := json.Unmarshal([]byte(result.Raw), &ex); err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
+
+// Index extracts entities and relations from m and links it to the
+// entities it mentions
+func (eg *EntityGraph) Index(ctx context.Context, m *domain.Memory) error {
+	ex, err := eg.Extract(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	var mentioned []*GraphEntity
+	for _, ent := range ex.Entities {
+		e, err := eg.UpsertEntity(ctx, ent)
+		if err != nil {
+			continue
+		}
+		mentioned = append(mentioned, e)
+	}
+	for _, r := range ex.Relations {
+		if strings.TrimSpace(r.Subject) == "" || strings.TrimSpace(r.Object) == "" {
+			continue
+		}
+		if err := eg.Relate(ctx, r.Subject, r.Relation, r.Object, m.ID); err != nil {
+			continue
+		}
+		for _, name := range []string{r.Subject, r.Object} {
+			if e, err := eg.Resolve(ctx, name); err == nil && !containsEntity(mentioned, e.ID) {
+				mentioned = append(mentioned, e)
+			}
+		}
+	}
+	if len(mentioned) == 0 {
+		return nil
+	}
+	return eg.LinkFact(ctx, m, mentioned...)
+}
+
+// Forget removes a deleted memory from the graph: its fact node and
+// mentions, its evidence on relations, relations no memory supports any
+// more and entities left without edges. Stores that cannot delete (no
+// domain.GraphDeleter) are left unchanged.
+func (eg *EntityGraph) Forget(ctx context.Context, memoryID string) error {
+	deleter, ok := eg.graph.(domain.GraphDeleter)
+	if !ok {
+		return nil
+	}
+	factID := factNodePrefix + memoryID
+	if _, err := eg.reader.GetNode(ctx, factID); err != nil {
+		return nil // never indexed
+	}
+	mentions, err := eg.reader.GetEdges(ctx, factID, "out")
+	if err != nil {
+		return err
+	}
+	if err := deleter.DeleteNode(ctx, factID); err != nil {
+		return err
+	}
+
+	eg.mu.Lock()
+	defer eg.mu.Unlock()
+	touched := make(map[string]bool)
+	for _, m := range mentions {
+		touched[m.ToNodeID] = true
+	}
+	for entityID := range touched {
+		edges, err := eg.reader.GetEdges(ctx, entityID, "out")
+		if err != nil {
+			return err
+		}
+		for _, edge := range edges {
+			factIDs := propStrings(edge.Properties, "fact_ids")
+			if edge.EdgeType == MentionsEdgeType || !containsFold(factIDs, memoryID) {
+				continue
+			}
+			var kept []string
+			for _, id := range factIDs {
+				if !strings.EqualFold(id, memoryID) {
+					kept = append(kept, id)
+				}
+			}
+			if len(kept) == 0 {
+				err = deleter.DeleteEdge(ctx, edge.ID)
+			} else {
+				edge.Properties["fact_ids"] = kept
+				err = eg.graph.UpsertEdge(ctx, edge)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for entityID := range touched {
+		if edges, err := eg.reader.GetEdges(ctx, entityID, "both"); err == nil && len(edges) == 0 {
+			if err := deleter.DeleteNode(ctx, entityID); err != nil {
+				return err
+			}
+			eg.aliases = nil // rebuilt on next use
+		}
+	}
+	return nil
+}
+
+// Mentions returns known entities whose name or alias appears in text
+func (eg *EntityGraph) Mentions(ctx context.Context, text string) ([]*GraphEntity, error) {
+	eg.mu.Lock()
+	err := eg.loadAliases(ctx)
+	var ids []string
+	if err == nil {
+		haystack := " " + aliasKey(text) + " "
+		seen := make(map[string]bool)
+		for alias, id := range eg.aliases {
+			if !seen[id] && strings.Contains(haystack, " "+alias+" ") {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	eg.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(ids)
+	var entities []*GraphEntity
+	for _, id := range ids {
+		if node, err := eg.reader.GetNode(ctx, id); err == nil {
+			entities = append(entities, entityFromNode(node))
+		}
+	}
+	return entities, nil
+}
+
+// Query walks the graph from the entity named name (or one of its aliases)
+// and collects the entities and relations reached and the memories that
+// mention them. load fetches a memory by ID; memories it cannot return are
+// skipped, which is how access control and deletions apply. Relations
+// extracted only from such memories are neither reported nor followed.
+func (eg *EntityGraph) Query(ctx context.Context, name string, q EntityQuery, load func(ctx context.Context, id string) (*domain.Memory, error)) (*EntityGraphResult, error) {
+	if q.Depth <= 0 {
+		q.Depth = 1
+	}
+	if q.MaxFacts <= 0 {
+		q.MaxFacts = 20
+	}
+	follow := make(map[string]bool)
+	for _, r := range q.Relations {
+		follow[normalizeRelation(r)] = true
+	}
+
+	root, err := eg.Resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	res := &EntityGraphResult{Entity: root, Entities: []*GraphEntity{root}}
+
+	visited := map[string]*GraphEntity{root.ID: root}
+	seenEdges := make(map[string]bool)
+	factIDs := make(map[string]bool)
+	var factOrder []string
+	loadable := make(map[string]bool)
+	canLoad := func(id string) bool {
+		ok, seen := loadable[id]
+		if !seen {
+			m, err := load(ctx, id)
+			ok = err == nil && m != nil
+			loadable[id] = ok
+		}
+		return ok
+	}
+	addFact := func(id string) {
+		if id != "" && !factIDs[id] {
+			factIDs[id] = true
+			factOrder = append(factOrder, id)
+		}
+	}
+
+	frontier := []*GraphEntity{root}
+	for depth := 0; depth <= q.Depth && len(frontier) > 0; depth++ {
+		var next []*GraphEntity
+		for _, e := range frontier {
+			edges, err := eg.reader.GetEdges(ctx, e.ID, "both")
+			if err != nil {
+				continue
+			}
+			for _, edge := range edges {
+				if edge.EdgeType == MentionsEdgeType {
+					if edge.ToNodeID == e.ID {
+						addFact(strings.TrimPrefix(edge.FromNodeID, factNodePrefix))
+					}
+					continue
+				}
+				if depth == q.Depth || seenEdges[edge.ID] || (len(follow) > 0 && !follow[edge.EdgeType]) {
+					continue
+				}
+				other := edge.ToNodeID
+				if other == e.ID {
+					other = edge.FromNodeID
+				}
+				if !strings.HasPrefix(other, entityNodePrefix) {
+					continue
+				}
+				seenEdges[edge.ID] = true
+
+				// Relations recorded without a source memory are public
+				sources := propStrings(edge.Properties, "fact_ids")
+				var visible []string
+				for _, id := range sources {
+					if canLoad(id) {
+						visible = append(visible, id)
+					}
+				}
+				if len(sources) > 0 && len(visible) == 0 {
+					continue
+				}
+
+				neighbour, ok := visited[other]
+				if !ok {
+					node, err := eg.reader.GetNode(ctx, other)
+					if err != nil {
+						continue
+					}
+					neighbour = entityFromNode(node)
+					neighbour.Depth = depth + 1
+					visited[other] = neighbour
+					res.Entities = append(res.Entities, neighbour)
+					next = append(next, neighbour)
+				}
+
+				fromName, toName := e.Name, neighbour.Name
+				if edge.FromNodeID != e.ID {
+					fromName, toName = toName, fromName
+				}
+				rel := EntityRelation{From: fromName, Relation: edge.EdgeType, To: toName, FactIDs: visible}
+				res.Relations = append(res.Relations, rel)
+				for _, id := range rel.FactIDs {
+					addFact(id)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	for _, id := range factOrder {
+		if len(res.Facts) >= q.MaxFacts {
+			break
+		}
+		m, err := load(ctx, id)
+		if err != nil || m == nil || m.ValidTo != nil || m.SupersededBy != "" || store.IsArchived(m) {
+			continue
+		}
+		res.Facts = append(res.Facts, m)
+	}
+	return res, nil
+}
+
+// Format renders a query result as memory context
+func (r *EntityGraphResult) Format() string {
+	if r == nil || r.Entity == nil {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Entity: %s", r.Entity.Name)
+	if r.Entity.Type != "" {
+		fmt.Fprintf(&sb, " (%s)", r.Entity.Type)
+	}
+	if r.Entity.Description != "" {
+		fmt.Fprintf(&sb, " - %s", r.Entity.Description)
+	}
+	if len(r.Entity.Aliases) > 0 {
+		fmt.Fprintf(&sb, " [also: %s]", strings.Join(r.Entity.Aliases, ", "))
+	}
+	for _, rel := range r.Relations {
+		fmt.Fprintf(&sb, "\n- %s %s %s", rel.From, strings.ReplaceAll(rel.Relation, "_", " "), rel.To)
+	}
+	return sb.String()
+}
+
+// SetGraphStore enables the entity graph on g. Facts, preferences and
+// observations added afterwards are linked to the entities they mention,
+// and retrieval pulls in facts about entities named in the query.
+func (s *Service) SetGraphStore(g domain.GraphStore) error {
+	if g == nil {
+		s.entityGraph = nil
+		s.entityMemory.graph = nil
+		return nil
+	}
+	eg, err := NewEntityGraph(g, s.llm)
+	if err != nil {
+		return err
+	}
+	if err := g.InitGraphSchema(context.Background()); err != nil {
+		return err
+	}
+	s.entityGraph = eg
+	s.entityMemory.graph = eg
+	return nil
+}
+
+// EntityGraph returns the entity graph, or nil if none is configured
+func (s *Service) EntityGraph() *EntityGraph {
+	return s.entityGraph
+}
+
+// QueryEntity returns what memory knows about the named entity and its
+// neighbourhood, e.g. a project with Relations ["owns"] and Depth 1 for the
+// project and its owners. Facts the caller may not read are left out.
+func (s *Service) QueryEntity(ctx context.Context, name string, q EntityQuery) (*EntityGraphResult, error) {
+	if s.entityGraph == nil {
+		return nil, fmt.Errorf("entity graph is not enabled")
+	}
+	return s.entityGraph.Query(ctx, name, q, s.store.Get)
+}
+
+// RebuildEntityGraph re-extracts entities from every current fact,
+// preference and observation. It returns the number of memories indexed.
+func (s *Service) RebuildEntityGraph(ctx context.Context) (int, error) {
+	if s.entityGraph == nil {
+		return 0, fmt.Errorf("entity graph is not enabled")
+	}
+	if s.llm == nil {
+		return 0, fmt.Errorf("LLM required for entity extraction")
+	}
+
+	const page = 200
+	indexed := 0
+	for offset := 0; ; offset += page {
+		mems, _, err := s.store.List(ctx, page, offset)
+		if err != nil {
+			return indexed, err
+		}
+		for _, m := range mems {
+			if !graphIndexable(m) || m.ValidTo != nil || m.SupersededBy != "" || store.IsArchived(m) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return indexed, err
+			}
+			if err := s.entityGraph.Index(ctx, m); err != nil {
+				continue
+			}
+			indexed++
+		}
+		if len(mems) < page {
+			return indexed, nil
+		}
+	}
+}
+
+// forgetInGraph prunes a deleted memory from the entity graph. The memory
+// is already gone, so a failure only leaves stale graph entries behind.
+func (s *Service) forgetInGraph(ctx context.Context, id string) {
+	if s.entityGraph != nil {
+		_ = s.entityGraph.Forget(ctx, id)
+	}
+}
+
+// graphMemories turns entities named in query into retrieval results: a
+// summary of each entity's relations and the facts linked to it
+func (s *Service) graphMemories(ctx context.Context, query string) []*domain.MemoryWithScore {
+	entities, err := s.entityGraph.Mentions(ctx, query)
+	if err != nil {
+		return nil
+	}
+	if len(entities) > 3 {
+		entities = entities[:3]
+	}
+
+	var out []*domain.MemoryWithScore
+	for _, e := range entities {
+		res, err := s.QueryEntity(ctx, e.Name, EntityQuery{Depth: 1, MaxFacts: s.maxMemories})
+		if err != nil {
+			continue
+		}
+		if len(res.Relations) > 0 || res.Entity.Description != "" {
+			out = append(out, &domain.MemoryWithScore{
+				Memory: &domain.Memory{
+					ID:         "ent_" + e.ID,
+					Type:       domain.MemoryTypeFact,
+					Content:    res.Format(),
+					Importance: 1.0,
+				},
+				Score: 1.0,
+			})
+		}
+		for _, m := range res.Facts {
+			out = append(out, &domain.MemoryWithScore{Memory: m, Score: 0.9})
+		}
+	}
+	return out
+}
+
+// graphIndexable reports whether m is the kind of memory entities are
+// extracted from
+func graphIndexable(m *domain.Memory) bool {
+	switch m.Type {
+	case domain.MemoryTypeFact, domain.MemoryTypePreference, domain.MemoryTypeObservation:
+		return true
+	}
+	return false
+}
+
+func entityNode(e *GraphEntity) domain.GraphNode {
+	content := e.Name
+	if e.Description != "" {
+		content += ": " + e.Description
+	}
+	return domain.GraphNode{
+		ID:       e.ID,
+		Content:  content,
+		NodeType: EntityNodeType,
+		Properties: map[string]interface{}{
+			"name":        e.Name,
+			"entity_type": e.Type,
+			"description": e.Description,
+			"aliases":     e.Aliases,
+		},
+	}
+}
+
+func entityFromNode(n *domain.GraphNode) *GraphEntity {
+	e := &GraphEntity{
+		ID:          n.ID,
+		Name:        metaString(n.Properties, "name"),
+		Type:        metaString(n.Properties, "entity_type"),
+		Description: metaString(n.Properties, "description"),
+		Aliases:     propStrings(n.Properties, "aliases"),
+	}
+	if e.Name == "" {
+		e.Name = strings.TrimPrefix(n.ID, entityNodePrefix)
+	}
+	return e
+}
+
+// propStrings reads a string list property, which comes back as
+// []interface{} from JSON-backed stores
+func propStrings(props map[string]interface{}, key string) []string {
+	switch v := props[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// aliasKey normalizes a name for alias matching: lowercase words separated
+// by single spaces
+func aliasKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func slug(name string) string {
+	return strings.ReplaceAll(aliasKey(name), " ", "-")
+}
+
+func normalizeRelation(r string) string {
+	return strings.ReplaceAll(aliasKey(r), " ", "_")
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEntity(list []*GraphEntity, id string) bool {
+	for _, e := range list {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphTestService(t *testing.T) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	fileStore, err := store.NewFileMemoryStore(filepath.Join(dir, "memories"))
+	require.NoError(t, err)
+	graphStore, err := store.NewFileGraphStore(filepath.Join(dir, "graph.json"))
+	require.NoError(t, err)
+
+	svc := NewService(fileStore, nil, nil, nil)
+	require.NoError(t, svc.SetGraphStore(graphStore))
+	return svc
+}
+
+func TestEntityGraph_AliasResolution(t *testing.T) {
+	ctx := context.Background()
+	svc := newGraphTestService(t)
+	eg := svc.EntityGraph()
+
+	first, err := eg.UpsertEntity(ctx, domain.Entity{Name: "Project Apollo", Type: "project", Aliases: []string{"Apollo"}})
+	require.NoError(t, err)
+
+	// A later mention under an alias merges into the canonical node
+	second, err := eg.UpsertEntity(ctx, domain.Entity{Name: "apollo", Aliases: []string{"APL"}, Description: "the billing rewrite"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, "Project Apollo", second.Name)
+	assert.Equal(t, "project", second.Type)
+	assert.Contains(t, second.Aliases, "APL")
+
+	resolved, err := eg.Resolve(ctx, "apl")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, resolved.ID)
+	assert.Equal(t, "the billing rewrite", resolved.Description)
+
+	_, err = eg.Resolve(ctx, "Gemini")
+	assert.Error(t, err)
+}
+
+func TestEntityGraph_QueryProjectAndOwners(t *testing.T) {
+	ctx := context.Background()
+	svc := newGraphTestService(t)
+	eg := svc.EntityGraph()
+
+	owner := &domain.Memory{ID: "m-owner", Type: domain.MemoryTypeFact, Content: "Alice owns Project Apollo"}
+	dep := &domain.Memory{ID: "m-dep", Type: domain.MemoryTypeFact, Content: "Apollo depends on Postgres"}
+	team := &domain.Memory{ID: "m-team", Type: domain.MemoryTypeFact, Content: "Alice is on the platform team"}
+	for _, m := range []*domain.Memory{owner, dep, team} {
+		require.NoError(t, svc.store.Store(ctx, m))
+	}
+
+	_, err := eg.UpsertEntity(ctx, domain.Entity{Name: "Project Apollo", Aliases: []string{"Apollo"}})
+	require.NoError(t, err)
+	require.NoError(t, eg.Relate(ctx, "Alice", "owns", "Apollo", owner.ID))
+	require.NoError(t, eg.Relate(ctx, "Apollo", "depends on", "Postgres", dep.ID))
+	require.NoError(t, eg.Relate(ctx, "Alice", "member_of", "Platform Team", team.ID))
+	alice, err := eg.Resolve(ctx, "Alice")
+	require.NoError(t, err)
+	require.NoError(t, eg.LinkFact(ctx, team, alice))
+
+	res, err := svc.QueryEntity(ctx, "apollo", EntityQuery{Relations: []string{"owns"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Project Apollo", res.Entity.Name)
+	require.Len(t, res.Relations, 1)
+	assert.Equal(t, EntityRelation{From: "Alice", Relation: "owns", To: "Project Apollo", FactIDs: []string{"m-owner"}}, res.Relations[0])
+
+	var factIDs []string
+	for _, m := range res.Facts {
+		factIDs = append(factIDs, m.ID)
+	}
+	// The owner's own facts come along; the unfollowed dependency does not
+	assert.ElementsMatch(t, []string{"m-owner", "m-team"}, factIDs)
+
+	// Two hops reach the owner's team
+	res, err = svc.QueryEntity(ctx, "Apollo", EntityQuery{Depth: 2})
+	require.NoError(t, err)
+	var names []string
+	for _, e := range res.Entities {
+		names = append(names, e.Name)
+	}
+	assert.ElementsMatch(t, []string{"Project Apollo", "Alice", "Postgres", "Platform Team"}, names)
+
+	// Deleted facts drop out of results, along with the relations they supported
+	require.NoError(t, svc.Delete(ctx, dep.ID))
+	res, err = svc.QueryEntity(ctx, "Apollo", EntityQuery{Relations: []string{"depends_on"}})
+	require.NoError(t, err)
+	assert.Empty(t, res.Relations)
+	assert.Empty(t, res.Facts)
+}
+
+func TestEntityGraph_QueryHidesRelationsFromUnreadableFacts(t *testing.T) {
+	ctx := context.Background()
+	svc := newGraphTestService(t)
+	svc.SetAccessControl(nil, nil)
+	eg := svc.EntityGraph()
+
+	mine := &domain.Memory{ID: "alice-1", SessionID: "user:alice", Type: domain.MemoryTypeFact, Content: "Alice owns Apollo"}
+	theirs := &domain.Memory{ID: "bob-1", SessionID: "user:bob", Type: domain.MemoryTypeFact, Content: "Bob wants to cancel Apollo"}
+	for _, m := range []*domain.Memory{mine, theirs} {
+		require.NoError(t, svc.store.Store(domain.WithSystemMemoryPrincipal(ctx), m))
+	}
+	require.NoError(t, eg.Relate(ctx, "Alice", "owns", "Apollo", mine.ID))
+	require.NoError(t, eg.Relate(ctx, "Bob", "wants to cancel", "Apollo", theirs.ID))
+
+	alice := domain.WithMemoryPrincipal(ctx, &domain.MemoryPrincipal{UserID: "alice"})
+	res, err := svc.QueryEntity(alice, "Apollo", EntityQuery{})
+	require.NoError(t, err)
+	require.Len(t, res.Relations, 1)
+	assert.Equal(t, "owns", res.Relations[0].Relation)
+	var names []string
+	for _, e := range res.Entities {
+		names = append(names, e.Name)
+	}
+	assert.ElementsMatch(t, []string{"Apollo", "Alice"}, names)
+
+	res, err = svc.QueryEntity(domain.WithSystemMemoryPrincipal(ctx), "Apollo", EntityQuery{})
+	require.NoError(t, err)
+	assert.Len(t, res.Relations, 2)
+}
+
+func TestEntityGraph_DeletePrunesGraph(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	fileStore, err := store.NewFileMemoryStore(filepath.Join(dir, "memories"))
+	require.NoError(t, err)
+	graphStore, err := store.NewFileGraphStore(filepath.Join(dir, "graph.json"))
+	require.NoError(t, err)
+	svc := NewService(fileStore, nil, nil, nil)
+	require.NoError(t, svc.SetGraphStore(graphStore))
+	eg := svc.EntityGraph()
+
+	first := &domain.Memory{ID: "m1", Type: domain.MemoryTypeFact, Content: "Dana maintains Hermes"}
+	second := &domain.Memory{ID: "m2", Type: domain.MemoryTypeFact, Content: "Dana maintains Hermes and Iris"}
+	for _, m := range []*domain.Memory{first, second} {
+		require.NoError(t, svc.store.Store(ctx, m))
+	}
+	require.NoError(t, eg.Relate(ctx, "Dana", "maintains", "Hermes", first.ID))
+	require.NoError(t, eg.Relate(ctx, "Dana", "maintains", "Hermes", second.ID))
+	require.NoError(t, eg.Relate(ctx, "Dana", "maintains", "Iris", second.ID))
+	var linked []*GraphEntity
+	for _, name := range []string{"Dana", "Hermes", "Iris"} {
+		e, err := eg.Resolve(ctx, name)
+		require.NoError(t, err)
+		linked = append(linked, e)
+	}
+	require.NoError(t, eg.LinkFact(ctx, first, linked[:2]...))
+	require.NoError(t, eg.LinkFact(ctx, second, linked...))
+
+	require.NoError(t, svc.Delete(ctx, second.ID))
+
+	_, err = graphStore.GetNode(ctx, factNodePrefix+second.ID)
+	assert.Error(t, err)
+	// Iris was only known from the deleted fact
+	_, err = eg.Resolve(ctx, "Iris")
+	assert.Error(t, err)
+
+	edges, err := graphStore.GetEdges(ctx, linked[0].ID, "out")
+	require.NoError(t, err)
+	var rels []string
+	for _, e := range edges {
+		if e.EdgeType == "maintains" {
+			rels = append(rels, e.ToNodeID)
+			assert.Equal(t, []string{first.ID}, propStrings(e.Properties, "fact_ids"))
+		}
+	}
+	assert.Equal(t, []string{linked[1].ID}, rels)
+
+	require.NoError(t, svc.Delete(ctx, first.ID))
+	for _, e := range linked {
+		_, err = graphStore.GetNode(ctx, e.ID)
+		assert.Error(t, err, e.Name)
+	}
+}
+
+func TestEntityGraph_IndexExtractsFromFact(t *testing.T) {
+	ctx := context.Background()
+	graphStore, err := store.NewFileGraphStore(filepath.Join(t.TempDir(), "graph.json"))
+	require.NoError(t, err)
+
+	llm := new(MockGenerator)
+	llm.On("GenerateStructured", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&domain.StructuredResult{
+		Valid: true,
+		Raw: `{"entities":[{"name":"Bob","type":"person"},{"name":"Hermes","type":"project","aliases":["the gateway"]}],
+			"relations":[{"subject":"Bob","relation":"Maintains","object":"Hermes"}]}`,
+	}, nil)
+
+	eg, err := NewEntityGraph(graphStore, llm)
+	require.NoError(t, err)
+
+	fact := &domain.Memory{ID: "m1", Type: domain.MemoryTypeFact, Content: "Bob maintains the gateway (Hermes)"}
+	require.NoError(t, eg.Index(ctx, fact))
+
+	hermes, err := eg.Resolve(ctx, "The Gateway")
+	require.NoError(t, err)
+	assert.Equal(t, "Hermes", hermes.Name)
+
+	edges, err := graphStore.GetEdges(ctx, hermes.ID, "in")
+	require.NoError(t, err)
+	var types []string
+	for _, e := range edges {
+		types = append(types, e.EdgeType)
+	}
+	assert.ElementsMatch(t, []string{"maintains", MentionsEdgeType}, types)
+
+	mentioned, err := eg.Mentions(ctx, "who looks after the gateway?")
+	require.NoError(t, err)
+	require.Len(t, mentioned, 1)
+	assert.Equal(t, hermes.ID, mentioned[0].ID)
+}
+
+func TestRetrieveAndInject_IncludesGraphFacts(t *testing.T) {
+	ctx := context.Background()
+	svc := newGraphTestService(t)
+	eg := svc.EntityGraph()
+
+	fact := &domain.Memory{ID: "m-owner", Type: domain.MemoryTypeFact, Content: "Carol owns the Zephyr service", Importance: 0.8}
+	require.NoError(t, svc.store.Store(ctx, fact))
+	require.NoError(t, eg.Relate(ctx, "Carol", "owns", "Zephyr", fact.ID))
+
+	_, mems, _, err := svc.RetrieveAndInjectWithLogic(ctx, "Who is responsible for Zephyr these days?", "")
+	require.NoError(t, err)
+
+	var ids []string
+	for _, m := range mems {
+		ids = append(ids, m.ID)
+	}
+	assert.Contains(t, ids, "m-owner")
+	assert.Contains(t, ids, "ent_entity:zephyr")
+}
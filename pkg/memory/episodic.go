@@ -0,0 +1,418 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// Episode metadata keys holding the distilled approach and lesson
+const (
+	metaEpisodeApproach = "episode_approach"
+	metaEpisodeLesson   = "episode_lesson"
+)
+
+// EpisodeConfig controls episodic memory of agent runs
+type EpisodeConfig struct {
+	Enabled       bool    // record runs and recall them as examples
+	MaxExamples   int     // episodes injected per run (default 2)
+	MinSimilarity float64 // minimum goal similarity to recall an episode (default 0.2)
+	MaxCandidates int     // episodes considered per recall (default 500)
+}
+
+// DefaultEpisodeConfig returns default episodic memory configuration
+func DefaultEpisodeConfig() *EpisodeConfig {
+	return &EpisodeConfig{
+		Enabled:       true,
+		MaxExamples:   2,
+		MinSimilarity: 0.2,
+		MaxCandidates: 500,
+	}
+}
+
+// episodeDistillation is what the LLM returns when distilling a run
+type episodeDistillation struct {
+	Approach string `json:"approach"`
+	Lesson   string `json:"lesson"`
+}
+
+// RecordEpisode distills a completed agent run into an episode memory linked
+// to its history session. With an LLM the approach and lesson are
+// summarized; otherwise they are derived from the tool sequence and result.
+// Episodes skip conflict detection: two runs of the same task are both worth
+// remembering.
+func (s *Service) RecordEpisode(ctx context.Context, ep *domain.Episode) (*domain.Memory, error) {
+	// Greetings and one-word goals teach nothing
+	if !s.episodes.Enabled || ep == nil || len([]rune(strings.TrimSpace(ep.Goal))) < 5 {
+		return nil, nil
+	}
+	ctx = store.WithMemoryChange(ctx, "", "record episode")
+	if ep.Outcome == "" {
+		ep.Outcome = domain.EpisodeSuccess
+		if ep.Error != "" {
+			ep.Outcome = domain.EpisodeFailure
+		}
+	}
+
+	d := s.distillEpisode(ctx, ep)
+	outcome := "succeeded"
+	importance := 0.7
+	if ep.Outcome == domain.EpisodeFailure {
+		outcome = "failed"
+		importance = 0.6
+	}
+
+	feedback := ""
+	if ep.Positive != nil {
+		feedback = "negative"
+		if *ep.Positive {
+			feedback = "positive"
+		}
+	}
+
+	now := time.Now()
+	mem := &domain.Memory{
+		ID:         uuid.New().String(),
+		SessionID:  episodeBank(ctx),
+		Type:       domain.MemoryTypeEpisode,
+		Content:    fmt.Sprintf("Task: %s\nApproach: %s\nOutcome: %s - %s", ep.Goal, d.Approach, outcome, d.Lesson),
+		Importance: importance,
+		SourceType: domain.MemorySourceInferred,
+		CreatedAt:  now,
+		ValidFrom:  now,
+		Metadata: map[string]interface{}{
+			domain.MetaEpisodeGoal:     ep.Goal,
+			domain.MetaEpisodeOutcome:  string(ep.Outcome),
+			domain.MetaEpisodeTools:    strings.Join(ep.ToolSequence, ","),
+			domain.MetaEpisodeFeedback: feedback,
+			domain.MetaEpisodeSession:  ep.SessionID,
+			metaEpisodeApproach:        d.Approach,
+			metaEpisodeLesson:          d.Lesson,
+		},
+	}
+	if ep.AgentID != "" {
+		mem.Metadata["agent_id"] = ep.AgentID
+	}
+	if ep.Feedback != "" {
+		mem.Metadata["feedback_comment"] = ep.Feedback
+	}
+	if s.embedder != nil {
+		if vec, err := s.embedder.Embed(ctx, ep.Goal); err == nil {
+			mem.Vector = vec
+		}
+	}
+
+	if err := s.store.Store(ctx, mem); err != nil {
+		return nil, err
+	}
+	if s.shadowIndex != nil && len(mem.Vector) > 0 {
+		_ = s.shadowIndex.Store(ctx, mem)
+	}
+	return mem, nil
+}
+
+// distillEpisode summarizes how a run went, falling back to heuristics when
+// there is no LLM or it fails
+func (s *Service) distillEpisode(ctx context.Context, ep *domain.Episode) episodeDistillation {
+	d := heuristicDistillation(ep)
+	if s.llm == nil {
+		return d
+	}
+
+	prompt := fmt.Sprintf(`Summarize this completed agent run so it can guide similar tasks later. RETURN VALID JSON ONLY.
+
+Goal: %s
+Plan: %s
+Tools called (in order): %s
+Outcome: %s
+Result: %s
+Error: %s
+User feedback: %s
+
+"approach": one sentence describing how the task was solved or attempted.
+"lesson": one sentence on why it succeeded or failed.`,
+		ep.Goal, ep.Plan, strings.Join(ep.ToolSequence, " -> "), ep.Outcome,
+		truncateRunes(ep.Result, 1000), ep.Error, ep.Feedback)
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"approach": map[string]interface{}{"type": "string"},
+			"lesson":   map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"approach", "lesson"},
+	}
+
+	result, err := s.llm.GenerateStructured(ctx, prompt, schema, &domain.GenerationOptions{Temperature: 0.1})
+	if err != nil || result == nil || !result.Valid {
+		return d
+	}
+	var llmD episodeDistillation
+	if err := json.Unmarshal([]byte(result.Raw), &llmD); err != nil {
+		return d
+	}
+	if a := strings.TrimSpace(llmD.Approach); a != "" {
+		d.Approach = a
+	}
+	if l := strings.TrimSpace(llmD.Lesson); l != "" {
+		d.Lesson = l
+	}
+	return d
+}
+
+func heuristicDistillation(ep *domain.Episode) episodeDistillation {
+	var d episodeDistillation
+	switch {
+	case ep.Plan != "":
+		d.Approach = truncateRunes(ep.Plan, 200)
+	case len(ep.ToolSequence) > 0:
+		d.Approach = "called " + strings.Join(ep.ToolSequence, ", then ")
+	default:
+		d.Approach = "answered directly without tools"
+	}
+
+	switch {
+	case ep.Outcome == domain.EpisodeFailure && ep.Error != "":
+		d.Lesson = truncateRunes(ep.Error, 200)
+	case ep.Outcome == domain.EpisodeFailure:
+		d.Lesson = "the run did not produce a result"
+	case ep.Feedback != "":
+		d.Lesson = "user said: " + truncateRunes(ep.Feedback, 160)
+	default:
+		d.Lesson = "produced: " + truncateRunes(ep.Result, 160)
+	}
+	return d
+}
+
+// RecallEpisodes returns past runs whose goals resemble goal, best first.
+// Similarity is cosine over goal embeddings when both sides have one and
+// token overlap otherwise; the result is then weighted by the scorer's
+// outcome factor so successes outrank failures of similar tasks.
+func (s *Service) RecallEpisodes(ctx context.Context, goal string, topK int) ([]*domain.MemoryWithScore, error) {
+	if !s.episodes.Enabled || strings.TrimSpace(goal) == "" {
+		return nil, nil
+	}
+	if topK <= 0 {
+		topK = s.episodes.MaxExamples
+	}
+
+	candidates, err := s.store.GetByType(ctx, domain.MemoryTypeEpisode, s.episodes.MaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var goalVec []float64
+	if s.embedder != nil {
+		goalVec, _ = s.embedder.Embed(ctx, goal)
+	}
+	goalTokens := tokenSet(goal)
+
+	scorer := s.scorer
+	if scorer == nil {
+		scorer = NewMemoryScorer(nil)
+	}
+
+	var hits []*domain.MemoryWithScore
+	for _, m := range candidates {
+		if m.ValidTo != nil || m.SupersededBy != "" || store.IsArchived(m) {
+			continue
+		}
+		epGoal := metaString(m.Metadata, domain.MetaEpisodeGoal)
+		if epGoal == "" {
+			epGoal = m.Content
+		}
+		var sim float64
+		if len(goalVec) > 0 && len(m.Vector) == len(goalVec) {
+			sim = cosine(goalVec, m.Vector)
+		} else {
+			sim = jaccard(goalTokens, tokenSet(epGoal))
+		}
+		if sim < s.episodes.MinSimilarity {
+			continue
+		}
+		hits = append(hits, &domain.MemoryWithScore{Memory: m, Score: sim * scorer.OutcomeFactor(m)})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+// ListEpisodes returns recorded episodes, newest first
+func (s *Service) ListEpisodes(ctx context.Context, limit int) ([]*domain.Memory, error) {
+	episodes, err := s.store.GetByType(ctx, domain.MemoryTypeEpisode, s.episodes.MaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(episodes, func(i, j int) bool { return episodes[i].CreatedAt.After(episodes[j].CreatedAt) })
+	if limit > 0 && len(episodes) > limit {
+		episodes = episodes[:limit]
+	}
+	return episodes, nil
+}
+
+// EpisodeContext recalls episodes for goal and formats them as few-shot
+// examples for the prompt; empty when nothing similar was found
+func (s *Service) EpisodeContext(ctx context.Context, goal string) (string, error) {
+	hits, err := s.RecallEpisodes(ctx, goal, 0)
+	if err != nil || len(hits) == 0 {
+		return "", err
+	}
+	return FormatEpisodes(hits), nil
+}
+
+// FormatEpisodes renders episodes as "last time a similar task ..." lines
+func FormatEpisodes(episodes []*domain.MemoryWithScore) string {
+	if len(episodes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Similar Past Tasks\n\n")
+	for _, e := range episodes {
+		m := e.Memory
+		goal := metaString(m.Metadata, domain.MetaEpisodeGoal)
+		approach := metaString(m.Metadata, metaEpisodeApproach)
+		lesson := metaString(m.Metadata, metaEpisodeLesson)
+		if goal == "" || approach == "" {
+			sb.WriteString("- " + strings.ReplaceAll(m.Content, "\n", "; ") + "\n")
+			continue
+		}
+		if metaString(m.Metadata, domain.MetaEpisodeOutcome) == string(domain.EpisodeFailure) {
+			fmt.Fprintf(&sb, "- Last time a similar task (%q) failed because %s. It had %s.\n", goal, lesson, approach)
+		} else {
+			fmt.Fprintf(&sb, "- Last time a similar task (%q) succeeded by doing: %s.", goal, approach)
+			if metaString(m.Metadata, domain.MetaEpisodeFeedback) == "negative" {
+				sb.WriteString(" The user was not satisfied with the result.")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// AddEpisodeFeedback records the user's rating of the run recorded under
+// historySessionID on its most recent episode
+func (s *Service) AddEpisodeFeedback(ctx context.Context, historySessionID string, positive bool, comment string) (*domain.Memory, error) {
+	ctx = store.WithMemoryChange(ctx, "", "episode feedback")
+	episodes, err := s.store.GetByType(ctx, domain.MemoryTypeEpisode, s.episodes.MaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+	var latest *domain.Memory
+	for _, m := range episodes {
+		if metaString(m.Metadata, domain.MetaEpisodeSession) != historySessionID {
+			continue
+		}
+		if latest == nil || m.CreatedAt.After(latest.CreatedAt) {
+			latest = m
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no episode for session %s: %w", historySessionID, store.ErrMemoryNotFound)
+	}
+
+	rating := "negative"
+	if positive {
+		rating = "positive"
+	}
+	if latest.Metadata == nil {
+		latest.Metadata = make(map[string]interface{})
+	}
+	latest.Metadata[domain.MetaEpisodeFeedback] = rating
+	if comment != "" {
+		latest.Metadata["feedback_comment"] = comment
+	}
+	latest.UpdatedAt = time.Now()
+	latest.RevisionHistory = append(latest.RevisionHistory, domain.MemoryRevision{
+		At:      latest.UpdatedAt,
+		By:      "user",
+		Summary: "feedback: " + rating,
+	})
+	if err := s.store.Update(ctx, latest); err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
+
+// episodeBank scopes episodes to the calling user when there is one, so they
+// stay writable under access control; otherwise episodes are global
+func episodeBank(ctx context.Context) string {
+	return principalBank(ctx, "")
+}
+
+// principalBank returns the user bank of a signed-in, non-admin principal on
+// ctx, or fallback for everyone else
+func principalBank(ctx context.Context, fallback string) string {
+	if p := domain.MemoryPrincipalFromContext(ctx); p != nil && !p.Admin && p.UserID != "" {
+		return string(domain.MemoryScopeUser) + ":" + p.UserID
+	}
+	return fallback
+}
+
+func metaString(meta map[string]interface{}, key string) string {
+	if meta == nil {
+		return ""
+	}
+	if v, ok := meta[key]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
+
+func tokenSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, tok := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(tok)) > 2 {
+			set[tok] = true
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for t := range a {
+		if b[t] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
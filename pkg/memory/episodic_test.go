@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEpisodeTestService(t *testing.T) *Service {
+	t.Helper()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	return NewService(fileStore, nil, nil, nil)
+}
+
+func TestRecordEpisode_Heuristic(t *testing.T) {
+	ctx := context.Background()
+	svc := newEpisodeTestService(t)
+
+	mem, err := svc.RecordEpisode(ctx, &domain.Episode{
+		SessionID:    "sess-1",
+		Goal:         "Summarize today's tech news",
+		ToolSequence: []string{"web_search", "fetch_url"},
+		Result:       "Five headlines about AI chips",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mem)
+
+	stored, err := svc.Get(ctx, mem.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MemoryTypeEpisode, stored.Type)
+	assert.Contains(t, stored.Content, "called web_search, then fetch_url")
+	assert.Equal(t, "success", metaString(stored.Metadata, domain.MetaEpisodeOutcome))
+	assert.Equal(t, "sess-1", metaString(stored.Metadata, domain.MetaEpisodeSession))
+
+	// Trivial goals are not worth an episode
+	mem, err = svc.RecordEpisode(ctx, &domain.Episode{Goal: "hi"})
+	require.NoError(t, err)
+	assert.Nil(t, mem)
+}
+
+func TestRecallEpisodes_SimilarityAndOutcome(t *testing.T) {
+	ctx := context.Background()
+	svc := newEpisodeTestService(t)
+
+	_, err := svc.RecordEpisode(ctx, &domain.Episode{
+		SessionID: "s-fail", Goal: "deploy the billing service to staging",
+		ToolSequence: []string{"kubectl_apply"}, Outcome: domain.EpisodeFailure, Error: "missing image tag",
+	})
+	require.NoError(t, err)
+	_, err = svc.RecordEpisode(ctx, &domain.Episode{
+		SessionID: "s-ok", Goal: "deploy the billing service to staging",
+		ToolSequence: []string{"build_image", "kubectl_apply"}, Result: "rolled out",
+	})
+	require.NoError(t, err)
+	_, err = svc.RecordEpisode(ctx, &domain.Episode{
+		SessionID: "s-other", Goal: "write a haiku about autumn leaves", Result: "done",
+	})
+	require.NoError(t, err)
+
+	hits, err := svc.RecallEpisodes(ctx, "deploy billing service to staging again", 5)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "s-ok", metaString(hits[0].Metadata, domain.MetaEpisodeSession), "success should outrank failure")
+	assert.Equal(t, "s-fail", metaString(hits[1].Metadata, domain.MetaEpisodeSession))
+
+	text := FormatEpisodes(hits)
+	assert.Contains(t, text, "succeeded by doing: called build_image, then kubectl_apply")
+	assert.Contains(t, text, "failed because missing image tag")
+
+	// Episodes are examples, not ordinary context
+	_, mems, err := svc.RetrieveAndInject(ctx, "deploy billing service to staging", "")
+	require.NoError(t, err)
+	for _, m := range mems {
+		assert.NotEqual(t, domain.MemoryTypeEpisode, m.Type)
+	}
+}
+
+func TestAddEpisodeFeedback(t *testing.T) {
+	ctx := context.Background()
+	svc := newEpisodeTestService(t)
+
+	_, err := svc.RecordEpisode(ctx, &domain.Episode{SessionID: "sess-9", Goal: "plan a team offsite agenda", Result: "agenda"})
+	require.NoError(t, err)
+
+	ep, err := svc.AddEpisodeFeedback(ctx, "sess-9", false, "too long")
+	require.NoError(t, err)
+	assert.Equal(t, "negative", metaString(ep.Metadata, domain.MetaEpisodeFeedback))
+
+	stored, err := svc.Get(ctx, ep.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "negative", metaString(stored.Metadata, domain.MetaEpisodeFeedback))
+	assert.Len(t, stored.RevisionHistory, 1)
+
+	_, err = svc.AddEpisodeFeedback(ctx, "unknown", true, "")
+	assert.ErrorIs(t, err, store.ErrMemoryNotFound)
+}
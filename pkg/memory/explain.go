@@ -0,0 +1,298 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// Retrieval sources a candidate memory can come from
+const (
+	SourceEntity    = "entity"    // EntityMemory similarity search
+	SourceGraph     = "graph"     // entity graph traversal
+	SourceVector    = "vector"    // vector search over the query's scopes
+	SourceText      = "text"      // BM25/keyword search
+	SourceNavigator = "navigator" // LLM index navigator
+	SourceRecent    = "recent"    // plain list fallback when nothing matched
+)
+
+// RetrieveOptions adjusts a single retrieval without changing the service
+type RetrieveOptions struct {
+	Scoring     *ScoringConfig // replaces the scoring config for this query
+	MaxMemories int            // overrides how many memories are returned
+}
+
+// MemoryExplanation is how one candidate memory fared in retrieval
+type MemoryExplanation struct {
+	MemoryID string            `json:"memory_id"`
+	Type     domain.MemoryType `json:"type"`
+	Content  string            `json:"content"`
+	Sources  []string          `json:"sources"`
+
+	VectorScore   float64 `json:"vector_score,omitempty"`
+	VectorRank    int     `json:"vector_rank,omitempty"` // 1-based; 0 = not returned
+	TextScore     float64 `json:"text_score,omitempty"`  // BM25/keyword score
+	TextRank      int     `json:"text_rank,omitempty"`
+	NavigatorRank int     `json:"navigator_rank,omitempty"`
+	RRFScore      float64 `json:"rrf_score,omitempty"`
+	RRFRank       int     `json:"rrf_rank,omitempty"`
+
+	Scope       string          `json:"scope"`
+	ScopeWeight float64         `json:"scope_weight"` // configured weight of the memory's scope
+	Scoring     *ScoreBreakdown `json:"scoring,omitempty"`
+
+	Excluded string `json:"excluded,omitempty"` // why it was dropped ("archived", "episode", "noise:<verdict>", "limit")
+	Selected bool   `json:"selected"`
+	Rank     int    `json:"rank,omitempty"` // position in the injected context
+}
+
+// RetrievalExplanation describes one run of the retrieval pipeline
+type RetrievalExplanation struct {
+	Query       string               `json:"query"`
+	QueryType   string               `json:"query_type,omitempty"` // classifier decision
+	NeedsMemory bool                 `json:"needs_memory"`
+	Scoring     *ScoringConfig       `json:"scoring,omitempty"` // effective scoring config
+	Logic       string               `json:"logic,omitempty"`   // navigator reasoning
+	Redacted    int                  `json:"redacted,omitempty"`
+	Memories    []*MemoryExplanation `json:"memories"`
+}
+
+// Explain runs retrieval for query as the agent would and explains, for
+// every candidate, which sources found it, how it was scored and why it
+// was kept or dropped. opts can override scoring to tune weights; access
+// counts are not updated.
+func (s *Service) Explain(ctx context.Context, query string, sessionID string, opts *RetrieveOptions) ([]*domain.MemoryWithScore, *RetrievalExplanation, error) {
+	ex := &RetrievalExplanation{Query: query, NeedsMemory: true}
+	if opts != nil && opts.Scoring != nil {
+		ex.Scoring = opts.Scoring
+	} else if s.scorer != nil {
+		ex.Scoring = s.scorer.Config()
+	}
+
+	tr := &retrievalTrace{ex: ex, byKey: make(map[string]*MemoryExplanation), scopeWeights: s.scopeWeights}
+	mems, logic := s.retrieve(ctx, query, sessionID, opts, tr)
+	ex.Logic = logic
+	tr.finish()
+	return mems, ex, nil
+}
+
+// ApplyScoringOverrides returns a copy of base with the named fields set.
+// Keys are the memory.scoring config names (recency_weight, half_life_days,
+// enable_recency, importance_weight, min_importance, enable_importance,
+// length_norm_weight, anchor_length, enable_length_norm,
+// access_boost_weight, enable_access_boost, vector_score_weight,
+// success_weight, failure_weight, enable_outcome).
+func ApplyScoringOverrides(base *ScoringConfig, overrides map[string]string) (*ScoringConfig, error) {
+	if base == nil {
+		base = DefaultScoringConfig()
+	}
+	cfg := *base
+
+	floats := map[string]*float64{
+		"recency_weight":      &cfg.RecencyWeight,
+		"half_life_days":      &cfg.HalfLifeDays,
+		"importance_weight":   &cfg.ImportanceWeight,
+		"min_importance":      &cfg.MinImportance,
+		"length_norm_weight":  &cfg.LengthNormWeight,
+		"access_boost_weight": &cfg.AccessBoostWeight,
+		"vector_score_weight": &cfg.VectorScoreWeight,
+		"success_weight":      &cfg.SuccessWeight,
+		"failure_weight":      &cfg.FailureWeight,
+	}
+	bools := map[string]*bool{
+		"enable_recency":      &cfg.EnableRecency,
+		"enable_importance":   &cfg.EnableImportance,
+		"enable_length_norm":  &cfg.EnableLengthNorm,
+		"enable_access_boost": &cfg.EnableAccessBoost,
+		"enable_outcome":      &cfg.EnableOutcome,
+	}
+
+	for key, value := range overrides {
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch {
+		case floats[key] != nil:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("scoring override %s: %w", key, err)
+			}
+			*floats[key] = f
+		case bools[key] != nil:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("scoring override %s: %w", key, err)
+			}
+			*bools[key] = b
+		case key == "anchor_length":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("scoring override %s: %w", key, err)
+			}
+			cfg.AnchorLength = n
+		default:
+			return nil, fmt.Errorf("unknown scoring setting %q", key)
+		}
+	}
+	return &cfg, nil
+}
+
+// ScoringConfig returns the service's scoring configuration
+func (s *Service) ScoringConfig() *ScoringConfig {
+	if s.scorer == nil {
+		return nil
+	}
+	return s.scorer.Config()
+}
+
+// retrievalTrace collects explanations while retrieve runs. All methods
+// are no-ops on a nil trace so the normal path pays nothing.
+type retrievalTrace struct {
+	ex           *RetrievalExplanation
+	byKey        map[string]*MemoryExplanation
+	scopeWeights *ScopeWeightConfig
+}
+
+func traceKey(m *domain.MemoryWithScore) string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return m.Content
+}
+
+func (t *retrievalTrace) entry(m *domain.MemoryWithScore) *MemoryExplanation {
+	key := traceKey(m)
+	if e, ok := t.byKey[key]; ok {
+		return e
+	}
+	scope := domain.MemoryScopeOf(m.Memory)
+	e := &MemoryExplanation{
+		MemoryID: m.ID,
+		Type:     m.Type,
+		Content:  m.Content,
+		Scope:    ScopeString(scope),
+	}
+	if t.scopeWeights != nil {
+		e.ScopeWeight = t.scopeWeights.GetWeight(scope.Type)
+	}
+	t.byKey[key] = e
+	t.ex.Memories = append(t.ex.Memories, e)
+	return e
+}
+
+func (t *retrievalTrace) classified(qt QueryType, needs bool) {
+	if t == nil {
+		return
+	}
+	t.ex.QueryType = qt.String()
+	t.ex.NeedsMemory = needs
+}
+
+func (t *retrievalTrace) found(source string, results []*domain.MemoryWithScore) {
+	if t == nil {
+		return
+	}
+	for i, m := range results {
+		if m == nil || m.Memory == nil {
+			continue
+		}
+		e := t.entry(m)
+		if !containsFold(e.Sources, source) {
+			e.Sources = append(e.Sources, source)
+		}
+		switch source {
+		case SourceVector:
+			e.VectorScore, e.VectorRank = m.Score, i+1
+		case SourceText:
+			e.TextScore, e.TextRank = m.Score, i+1
+		case SourceNavigator:
+			e.NavigatorRank = i + 1
+		}
+	}
+}
+
+func (t *retrievalTrace) fused(results []*domain.MemoryWithScore) {
+	if t == nil {
+		return
+	}
+	for i, m := range results {
+		if m == nil || m.Memory == nil {
+			continue
+		}
+		e := t.entry(m)
+		e.RRFScore, e.RRFRank = m.Score, i+1
+	}
+}
+
+func (t *retrievalTrace) excluded(m *domain.MemoryWithScore, reason string) {
+	if t == nil || m == nil || m.Memory == nil {
+		return
+	}
+	t.entry(m).Excluded = reason
+}
+
+// redacted drops a memory the caller may not read from the explanation
+// entirely, so explaining cannot leak it
+func (t *retrievalTrace) redacted(m *domain.MemoryWithScore) {
+	if t == nil || m == nil || m.Memory == nil {
+		return
+	}
+	t.ex.Redacted++
+	key := traceKey(m)
+	if e, ok := t.byKey[key]; ok {
+		delete(t.byKey, key)
+		for i, other := range t.ex.Memories {
+			if other == e {
+				t.ex.Memories = append(t.ex.Memories[:i], t.ex.Memories[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (t *retrievalTrace) scored(m *domain.MemoryWithScore, b ScoreBreakdown) {
+	if t == nil {
+		return
+	}
+	t.entry(m).Scoring = &b
+}
+
+func (t *retrievalTrace) selected(final []*domain.MemoryWithScore) {
+	if t == nil {
+		return
+	}
+	for i, m := range final {
+		e := t.entry(m)
+		e.Selected, e.Rank, e.Excluded = true, i+1, ""
+	}
+}
+
+// finish marks candidates that survived filtering but missed the cut and
+// orders the explanation: selected memories by rank, then the rest by score
+func (t *retrievalTrace) finish() {
+	for _, e := range t.ex.Memories {
+		if !e.Selected && e.Excluded == "" {
+			e.Excluded = "limit"
+		}
+	}
+	sort.SliceStable(t.ex.Memories, func(i, j int) bool {
+		a, b := t.ex.Memories[i], t.ex.Memories[j]
+		if a.Selected != b.Selected {
+			return a.Selected
+		}
+		if a.Selected {
+			return a.Rank < b.Rank
+		}
+		return finalScore(a) > finalScore(b)
+	})
+}
+
+func finalScore(e *MemoryExplanation) float64 {
+	if e.Scoring == nil {
+		return 0
+	}
+	return e.Scoring.Final
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExplainTestService(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+
+	cfg := DefaultConfig()
+	cfg.ClassifierConfig = &ClassifierConfig{Enabled: false}
+	svc := NewService(fileStore, nil, nil, cfg)
+
+	now := time.Now()
+	for _, m := range []*domain.Memory{
+		{ID: "important", Type: domain.MemoryTypeFact, Content: "The deploy target is the eu-west cluster", Importance: 1.0, CreatedAt: now.AddDate(0, 0, -60)},
+		{ID: "recent", Type: domain.MemoryTypeFact, Content: "The deploy target moved to us-east last week", Importance: 0.0, CreatedAt: now},
+		{ID: "archived", Type: domain.MemoryTypeFact, Content: "The deploy target was the old VPS", Importance: 0.5, CreatedAt: now.AddDate(-1, 0, 0),
+			Metadata: map[string]interface{}{store.MetaArchivedAt: now.Format(time.RFC3339)}},
+		{ID: "refusal", Type: domain.MemoryTypeFact, Content: "I'm sorry but I can't help with the deploy target", Importance: 0.5, CreatedAt: now},
+	} {
+		require.NoError(t, fileStore.Store(ctx, m))
+	}
+	return svc
+}
+
+func explanationByID(ex *RetrievalExplanation) map[string]*MemoryExplanation {
+	out := make(map[string]*MemoryExplanation)
+	for _, m := range ex.Memories {
+		out[m.MemoryID] = m
+	}
+	return out
+}
+
+func TestExplain_ReportsEveryCandidate(t *testing.T) {
+	ctx := context.Background()
+	svc := newExplainTestService(t)
+
+	mems, ex, err := svc.Explain(ctx, "deploy target", "", nil)
+	require.NoError(t, err)
+	require.Len(t, mems, 2)
+	assert.True(t, ex.NeedsMemory)
+	assert.Equal(t, svc.ScoringConfig(), ex.Scoring)
+
+	byID := explanationByID(ex)
+	require.Len(t, byID, 4)
+	assert.Equal(t, "archived", byID["archived"].Excluded)
+	assert.Equal(t, "noise:refusal", byID["refusal"].Excluded)
+
+	winner := byID[mems[0].ID]
+	assert.True(t, winner.Selected)
+	assert.Equal(t, 1, winner.Rank)
+	assert.Equal(t, ex.Memories[0], winner, "selected memories come first")
+	assert.Equal(t, []string{SourceText}, winner.Sources)
+	assert.Greater(t, winner.TextRank, 0)
+	assert.Equal(t, "global", winner.Scope)
+	assert.InDelta(t, 0.6, winner.ScopeWeight, 1e-9)
+	require.NotNil(t, winner.Scoring)
+	assert.InDelta(t, mems[0].Score, winner.Scoring.Final, 1e-9)
+
+	for id, e := range byID {
+		if e.Selected == (e.Excluded != "") {
+			t.Errorf("%s: selected=%v excluded=%q", id, e.Selected, e.Excluded)
+		}
+	}
+
+	// Explaining is a diagnostic read and leaves access counts alone
+	stored, err := svc.Get(ctx, mems[0].ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.AccessCount)
+}
+
+func TestExplain_ScoringOverridesChangeRanking(t *testing.T) {
+	ctx := context.Background()
+	svc := newExplainTestService(t)
+
+	byImportance, err := ApplyScoringOverrides(svc.ScoringConfig(), map[string]string{"enable_recency": "false", "importance_weight": "0.9"})
+	require.NoError(t, err)
+	mems, ex, err := svc.Explain(ctx, "deploy target", "", &RetrieveOptions{Scoring: byImportance})
+	require.NoError(t, err)
+	require.Len(t, mems, 2)
+	assert.Equal(t, "important", mems[0].ID)
+	assert.Same(t, byImportance, ex.Scoring)
+	assert.Zero(t, explanationByID(ex)["important"].Scoring.Recency, "disabled factors are reported as 0")
+
+	byRecency, err := ApplyScoringOverrides(svc.ScoringConfig(), map[string]string{"recency_weight": "0.9", "importance_weight": "0"})
+	require.NoError(t, err)
+	mems, _, err = svc.Explain(ctx, "deploy target", "", &RetrieveOptions{Scoring: byRecency})
+	require.NoError(t, err)
+	require.Len(t, mems, 2)
+	assert.Equal(t, "recent", mems[0].ID)
+
+	// The service's own config is untouched
+	assert.InDelta(t, 0.3, svc.ScoringConfig().RecencyWeight, 1e-9)
+}
+
+func TestExplain_ClassifierSkip(t *testing.T) {
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	svc := NewService(fileStore, nil, nil, nil)
+
+	mems, ex, err := svc.Explain(context.Background(), "hello", "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, mems)
+	assert.False(t, ex.NeedsMemory)
+	assert.Equal(t, "greeting", ex.QueryType)
+}
+
+func TestApplyScoringOverrides(t *testing.T) {
+	base := DefaultScoringConfig()
+
+	cfg, err := ApplyScoringOverrides(base, map[string]string{
+		"half_life_days": "7",
+		"enable_recency": "false",
+		"anchor_length":  "250",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, cfg.HalfLifeDays)
+	assert.False(t, cfg.EnableRecency)
+	assert.Equal(t, 250, cfg.AnchorLength)
+	assert.Equal(t, 30.0, base.HalfLifeDays, "base must not be modified")
+
+	_, err = ApplyScoringOverrides(base, map[string]string{"recency": "1"})
+	assert.Error(t, err)
+	_, err = ApplyScoringOverrides(base, map[string]string{"recency_weight": "high"})
+	assert.Error(t, err)
+}
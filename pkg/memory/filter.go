@@ -89,43 +89,57 @@ func (f *NoiseFilter) Filter(memories []*domain.MemoryWithScore) []*domain.Memor
 	}
 
 	filtered := make([]*domain.MemoryWithScore, 0, len(memories))
-	seen := make(map[string]bool)
-
-	for _, m := range memories {
-		if f.shouldKeep(m, seen) {
-			filtered = append(filtered, m)
+	for i, verdict := range f.Verdicts(memories) {
+		if verdict == "" {
+			filtered = append(filtered, memories[i])
 		}
 	}
 
 	return filtered
 }
 
-// shouldKeep determines if a memory should be kept
-func (f *NoiseFilter) shouldKeep(memory *domain.MemoryWithScore, seen map[string]bool) bool {
+// Verdicts returns, for each memory in order, why Filter would drop it
+// ("too_short", "refusal", "meta", "generic", "duplicate" or "invalid"),
+// or "" if it is kept
+func (f *NoiseFilter) Verdicts(memories []*domain.MemoryWithScore) []string {
+	verdicts := make([]string, len(memories))
+	if !f.config.Enabled {
+		return verdicts
+	}
+
+	seen := make(map[string]bool)
+	for i, m := range memories {
+		verdicts[i] = f.reject(m, seen)
+	}
+	return verdicts
+}
+
+// reject returns why a memory should be dropped, or "" to keep it
+func (f *NoiseFilter) reject(memory *domain.MemoryWithScore, seen map[string]bool) string {
 	if memory == nil || memory.Memory == nil {
-		return false
+		return "invalid"
 	}
 
 	content := strings.TrimSpace(memory.Content)
 
 	// Check minimum length
 	if len(content) < f.config.MinContentLength {
-		return false
+		return "too_short"
 	}
 
 	// Check refusal patterns
 	if f.config.FilterRefusals && f.isRefusal(content) {
-		return false
+		return "refusal"
 	}
 
 	// Check meta patterns
 	if f.config.FilterMeta && f.isMeta(content) {
-		return false
+		return "meta"
 	}
 
 	// Check generic patterns
 	if f.isGeneric(content) {
-		return false
+		return "generic"
 	}
 
 	// Check duplicates (case-insensitive)
@@ -134,12 +148,12 @@ func (f *NoiseFilter) shouldKeep(memory *domain.MemoryWithScore, seen map[string
 		// Normalize whitespace for duplicate detection
 		normalized := strings.Join(strings.Fields(contentLower), " ")
 		if seen[normalized] {
-			return false
+			return "duplicate"
 		}
 		seen[normalized] = true
 	}
 
-	return true
+	return ""
 }
 
 // isRefusal checks if content is a refusal response
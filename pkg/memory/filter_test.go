@@ -109,3 +109,25 @@ func TestNoiseFilter_Disabled(t *testing.T) {
 		t.Errorf("Filter() should return all memories when disabled, got %d", len(result))
 	}
 }
+
+func TestNoiseFilter_Verdicts(t *testing.T) {
+	filter := NewNoiseFilter(nil)
+	memories := []*domain.MemoryWithScore{
+		{Memory: &domain.Memory{ID: "1", Content: "ok"}},
+		{Memory: &domain.Memory{ID: "2", Content: "I'm sorry but I can't help with that request"}},
+		{Memory: &domain.Memory{ID: "3", Content: "The staging database runs on port 5433"}},
+		{Memory: &domain.Memory{ID: "4", Content: "the staging  database runs on port 5433"}},
+		nil,
+	}
+
+	want := []string{"too_short", "refusal", "", "duplicate", "invalid"}
+	got := filter.Verdicts(memories)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("verdict[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if kept := filter.Filter(memories); len(kept) != 1 || kept[0].ID != "3" {
+		t.Errorf("Filter kept %v, want only memory 3", kept)
+	}
+}
@@ -1,9 +1,12 @@
 package memory
 
 import (
+	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/liliang-cn/agent-go/pkg/domain"
 	"github.com/liliang-cn/agent-go/pkg/store"
@@ -55,3 +58,130 @@ func TestMemoryIntegration(t *testing.T) {
 	assert.Equal(t, 0, total)
 	assert.Empty(t, mems)
 }
+
+func TestMemoryTemporalIntegration(t *testing.T) {
+	ctx := context.Background()
+	memStore, err := store.NewMemoryStore(filepath.Join(t.TempDir(), "memory.db"))
+	require.NoError(t, err)
+	defer memStore.Close()
+
+	service := NewService(memStore, nil, nil, nil)
+
+	before := time.Now().Add(-time.Hour)
+	require.NoError(t, service.Add(ctx, &domain.Memory{ID: "lang-v1", Type: domain.MemoryTypePreference, Content: "User prefers Python", CreatedAt: before, ValidFrom: before}))
+	require.NoError(t, service.Add(ctx, &domain.Memory{ID: "lang-v2", Type: domain.MemoryTypePreference, Content: "User prefers Go"}))
+	require.NoError(t, memStore.MarkStale(ctx, "lang-v1", "lang-v2"))
+
+	// Temporal fields survive the round trip through hindsight metadata
+	old, err := service.Get(ctx, "lang-v1")
+	require.NoError(t, err)
+	assert.Equal(t, "lang-v2", old.SupersededBy)
+	require.NotNil(t, old.ValidTo)
+	assert.Len(t, old.RevisionHistory, 1)
+	assert.WithinDuration(t, before, old.ValidFrom, time.Millisecond)
+
+	past, err := service.SearchAsOf(ctx, "prefers", before.Add(time.Minute), 5)
+	require.NoError(t, err)
+	require.Len(t, past, 1)
+	assert.Equal(t, "lang-v1", past[0].ID)
+
+	current, err := service.SearchTemporal(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, current, 1)
+	assert.Equal(t, "lang-v2", current[0].ID)
+
+	text, mems, err := service.RetrieveAsOf(ctx, "language", "", before.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, mems, 1)
+	assert.Contains(t, text, "User prefers Python")
+
+	history, err := service.History(ctx, "lang-v2")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "lang-v1", history[0].ID)
+}
+
+func TestMemoryConflictFieldsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	memStore, err := store.NewMemoryStore(filepath.Join(t.TempDir(), "memory.db"))
+	require.NoError(t, err)
+	defer memStore.Close()
+
+	service := NewService(memStore, nil, nil, nil)
+	require.NoError(t, service.Add(ctx, &domain.Memory{
+		ID: "a", Type: domain.MemoryTypeFact, Content: "Sky is green",
+		Conflicting: true, Confidence: 0.4, EvidenceIDs: []string{"e1", "e2"},
+		Metadata: map[string]interface{}{metaConflictsWith: "b"},
+	}))
+
+	got, err := service.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, got.Conflicting)
+	assert.InDelta(t, 0.4, got.Confidence, 1e-9)
+	assert.Equal(t, []string{"e1", "e2"}, got.EvidenceIDs)
+	assert.Equal(t, "b", got.Metadata[metaConflictsWith])
+}
+
+func TestMemoryExportFileToSQLite(t *testing.T) {
+	ctx := context.Background()
+	src := newPortabilityTestService(t)
+	seedPortability(t, src)
+
+	var buf bytes.Buffer
+	_, err := src.Export(ctx, &buf, nil)
+	require.NoError(t, err)
+
+	memStore, err := store.NewMemoryStore(filepath.Join(t.TempDir(), "memory.db"))
+	require.NoError(t, err)
+	defer memStore.Close()
+	dst := NewService(memStore, nil, nil, nil)
+
+	stats, err := dst.Import(ctx, &buf, nil)
+	require.NoError(t, err)
+	assert.Empty(t, stats.Errors)
+	assert.Equal(t, 4, stats.Created)
+
+	old, err := dst.Get(ctx, "city-old")
+	require.NoError(t, err)
+	require.NotNil(t, old.ValidTo)
+	assert.Equal(t, "city-new", old.SupersededBy)
+	assert.InDelta(t, 0.7, old.Importance, 1e-9)
+	assert.Equal(t, "", old.SessionID, "global memories come back without a session")
+
+	pref, err := dst.Get(ctx, "pref")
+	require.NoError(t, err)
+	assert.Equal(t, "project-x", pref.SessionID)
+
+	models, err := memStore.MentalModels(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "Release rules", models[0].Name)
+
+	banks, err := memStore.BankConfigs(ctx)
+	require.NoError(t, err)
+	require.Contains(t, banks, "project-x")
+	assert.Equal(t, "Project X assistant", banks["project-x"].Mission)
+	assert.Equal(t, []string{"Never push to main"}, banks["project-x"].Directives)
+	assert.Equal(t, 4, banks["project-x"].Skepticism)
+
+	// Round trip back out of SQLite keeps the same records
+	var again bytes.Buffer
+	exported, err := dst.Export(ctx, &again, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &ExportStats{Banks: 1, MentalModels: 1, Memories: 4}, exported)
+}
+
+func TestTemporalQueriesNeedTemporalStore(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+
+	// A third-party store with only the MemoryStore methods
+	service := NewService(struct{ domain.MemoryStore }{fileStore}, nil, nil, nil)
+	require.NoError(t, service.Add(ctx, &domain.Memory{ID: "m1", Type: domain.MemoryTypeFact, Content: "The team deploys on Fridays", CreatedAt: time.Now()}))
+
+	_, err = service.History(ctx, "m1")
+	assert.ErrorIs(t, err, store.ErrTemporalUnsupported)
+	_, err = service.SearchAsOf(ctx, "deploys", time.Now(), 5)
+	assert.ErrorIs(t, err, store.ErrTemporalUnsupported)
+}
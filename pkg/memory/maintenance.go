@@ -0,0 +1,367 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// MaintenanceActionKind identifies what a maintenance pass did (or would do)
+type MaintenanceActionKind string
+
+const (
+	MaintenanceArchive     MaintenanceActionKind = "archive"     // decayed score below threshold
+	MaintenanceQuota       MaintenanceActionKind = "quota"       // archived to keep a scope under its quota
+	MaintenancePurge       MaintenanceActionKind = "purge"       // archived/superseded long enough to delete
+	MaintenanceMerge       MaintenanceActionKind = "merge"       // near-duplicate superseded by the memory it repeats
+	MaintenanceConsolidate MaintenanceActionKind = "consolidate" // facts merged into observations via Reflect
+	MaintenanceCompact     MaintenanceActionKind = "compact"     // file store index rewritten
+)
+
+// MaintenanceConfig controls a maintenance pass
+type MaintenanceConfig struct {
+	// ArchiveThreshold archives current memories whose retention score falls
+	// below it (0 disables decay archiving)
+	ArchiveThreshold float64
+	// MinAge protects recently created memories from decay archiving
+	MinAge time.Duration
+	// ScopeQuota caps current memories per scope; the lowest scoring are
+	// archived first (0 = unlimited)
+	ScopeQuota int
+	// PurgeAfter deletes memories that stopped being valid at least this
+	// long ago (0 = keep forever)
+	PurgeAfter time.Duration
+	// Consolidate merges near-duplicate memories within a scope and runs
+	// Reflect for scopes with at least ConsolidateMinFacts active facts
+	// (needs an LLM; Reflect runs for file stores only)
+	Consolidate         bool
+	ConsolidateMinFacts int
+	// CompactIndex rewrites the file store index without archived entries
+	CompactIndex bool
+	// DryRun reports what would change without touching the store
+	DryRun bool
+}
+
+// DefaultMaintenanceConfig returns default maintenance configuration
+func DefaultMaintenanceConfig() *MaintenanceConfig {
+	return &MaintenanceConfig{
+		ArchiveThreshold:    0.35,
+		MinAge:              30 * 24 * time.Hour,
+		Consolidate:         true,
+		ConsolidateMinFacts: 3,
+		CompactIndex:        true,
+	}
+}
+
+// MaintenanceAction is a single change made (or planned) by a maintenance pass
+type MaintenanceAction struct {
+	Kind      MaintenanceActionKind `json:"kind"`
+	MemoryID  string                `json:"memory_id,omitempty"`
+	SessionID string                `json:"session_id,omitempty"`
+	Type      domain.MemoryType     `json:"type,omitempty"`
+	Score     float64               `json:"score,omitempty"`
+	Content   string                `json:"content,omitempty"`
+	Reason    string                `json:"reason"`
+}
+
+// MaintenanceReport summarizes a maintenance pass
+type MaintenanceReport struct {
+	DryRun     bool                `json:"dry_run"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+	Scanned    int                 `json:"scanned"`
+	Actions    []MaintenanceAction `json:"actions"`
+	Errors     []string            `json:"errors,omitempty"`
+}
+
+// Count returns how many actions of the given kind the report contains
+func (r *MaintenanceReport) Count(kind MaintenanceActionKind) int {
+	n := 0
+	for _, a := range r.Actions {
+		if a.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// RunMaintenance purges long-invalid memories, archives decayed ones,
+// enforces per-scope quotas, merges near-duplicates, consolidates facts
+// into observations and compacts the file store index. Archiving closes the memory's validity
+// window rather than deleting it, so History and as-of queries still see it
+// until it is purged.
+func (s *Service) RunMaintenance(ctx context.Context, cfg *MaintenanceConfig) (*MaintenanceReport, error) {
+	if cfg == nil {
+		cfg = DefaultMaintenanceConfig()
+	}
+	ctx = store.WithMemoryChange(ctx, "maintenance", "memory gc")
+	now := time.Now()
+	report := &MaintenanceReport{DryRun: cfg.DryRun, StartedAt: now}
+
+	all, _, err := s.store.List(ctx, 10000, 0)
+	if err != nil {
+		return nil, err
+	}
+	report.Scanned = len(all)
+
+	scorer := s.scorer
+	if scorer == nil {
+		scorer = NewMemoryScorer(nil)
+	}
+
+	var active []*domain.Memory
+	for _, m := range all {
+		if m.ValidTo != nil || m.SupersededBy != "" {
+			if cfg.PurgeAfter > 0 && m.ValidTo != nil && now.Sub(*m.ValidTo) >= cfg.PurgeAfter {
+				action := newMaintenanceAction(MaintenancePurge, m, 0,
+					fmt.Sprintf("invalid since %s", m.ValidTo.Format("2006-01-02")))
+				s.applyMaintenance(report, action, cfg.DryRun, func() error {
+					if err := s.store.Delete(ctx, m.ID); err != nil {
+						return err
+					}
+					s.retire(ctx, m.ID)
+					return nil
+				})
+			}
+			continue
+		}
+		active = append(active, m)
+	}
+
+	// Decay: archive old memories that scoring would rank near the floor.
+	// Flagged contradictions are left for the review queue.
+	var kept []*domain.Memory
+	for _, m := range active {
+		score := scorer.RetentionScore(m)
+		if cfg.ArchiveThreshold > 0 && !m.Conflicting && now.Sub(m.CreatedAt) >= cfg.MinAge && score < cfg.ArchiveThreshold {
+			reason := fmt.Sprintf("retention score %.2f below %.2f", score, cfg.ArchiveThreshold)
+			action := newMaintenanceAction(MaintenanceArchive, m, score, reason)
+			s.applyMaintenance(report, action, cfg.DryRun, func() error {
+				return s.archive(ctx, m, reason, now)
+			})
+			continue
+		}
+		kept = append(kept, m)
+	}
+
+	// Quotas: keep the highest scoring memories in each scope
+	if cfg.ScopeQuota > 0 {
+		byScope := make(map[string][]*domain.Memory)
+		for _, m := range kept {
+			byScope[m.SessionID] = append(byScope[m.SessionID], m)
+		}
+		kept = kept[:0]
+		for _, scope := range sortedKeys(byScope) {
+			mems := byScope[scope]
+			sort.SliceStable(mems, func(i, j int) bool {
+				return scorer.RetentionScore(mems[i]) > scorer.RetentionScore(mems[j])
+			})
+			if len(mems) <= cfg.ScopeQuota {
+				kept = append(kept, mems...)
+				continue
+			}
+			kept = append(kept, mems[:cfg.ScopeQuota]...)
+			for _, m := range mems[cfg.ScopeQuota:] {
+				score := scorer.RetentionScore(m)
+				reason := fmt.Sprintf("scope %q over quota of %d", scopeLabel(scope), cfg.ScopeQuota)
+				action := newMaintenanceAction(MaintenanceQuota, m, score, reason)
+				s.applyMaintenance(report, action, cfg.DryRun, func() error {
+					return s.archive(ctx, m, reason, now)
+				})
+			}
+		}
+	}
+
+	// Merging: a memory the LLM judges to repeat another one in its scope
+	// is superseded by the better scoring of the two
+	if cfg.Consolidate && s.llm != nil {
+		kept = s.mergeDuplicates(ctx, report, kept, scorer, cfg.DryRun)
+	}
+
+	fileStore, isFileStore := s.fileStore()
+
+	// Consolidation: fold facts into observations. Reflect is LLM-driven
+	// only for file stores; other stores consolidate internally.
+	if cfg.Consolidate && isFileStore && s.llm != nil {
+		minFacts := cfg.ConsolidateMinFacts
+		if minFacts <= 0 {
+			minFacts = 3
+		}
+		facts := make(map[string]int)
+		for _, m := range kept {
+			if m.Type == domain.MemoryTypeFact {
+				facts[m.SessionID]++
+			}
+		}
+		for _, scope := range sortedKeys(facts) {
+			if facts[scope] < minFacts {
+				continue
+			}
+			action := MaintenanceAction{
+				Kind:      MaintenanceConsolidate,
+				SessionID: scope,
+				Reason:    fmt.Sprintf("%d active facts in scope %q", facts[scope], scopeLabel(scope)),
+			}
+			s.applyMaintenance(report, action, cfg.DryRun, func() error {
+				summary, err := fileStore.Reflect(ctx, scope)
+				if err == nil && summary != "" {
+					report.Actions[len(report.Actions)-1].Reason = summary
+				}
+				return err
+			})
+		}
+	}
+
+	if cfg.CompactIndex && isFileStore {
+		action := MaintenanceAction{Kind: MaintenanceCompact, Reason: "rewrite index without archived entries"}
+		s.applyMaintenance(report, action, cfg.DryRun, func() error {
+			removed, err := fileStore.CompactIndex(ctx)
+			if err == nil {
+				report.Actions[len(report.Actions)-1].Reason = fmt.Sprintf("dropped %d index entries", removed)
+			}
+			return err
+		})
+	}
+
+	if !cfg.DryRun && len(report.Actions) > 0 && s.navigator != nil {
+		s.navigator.InvalidateCache()
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// applyMaintenance records action and, unless dryRun, runs apply. The action
+// is appended before apply runs so apply may refine its reason.
+func (s *Service) applyMaintenance(report *MaintenanceReport, action MaintenanceAction, dryRun bool, apply func() error) {
+	report.Actions = append(report.Actions, action)
+	if dryRun {
+		return
+	}
+	if err := apply(); err != nil {
+		report.Actions = report.Actions[:len(report.Actions)-1]
+		target := action.MemoryID
+		if target == "" {
+			target = scopeLabel(action.SessionID)
+		}
+		report.Errors = append(report.Errors, fmt.Sprintf("%s %s: %v", action.Kind, target, err))
+	}
+}
+
+// mergeDuplicates looks for near-duplicates among the current memories and
+// supersedes the weaker copy of each pair. It returns the memories left.
+func (s *Service) mergeDuplicates(ctx context.Context, report *MaintenanceReport, mems []*domain.Memory, scorer *MemoryScorer, dryRun bool) []*domain.Memory {
+	current := make(map[string]*domain.Memory, len(mems))
+	for _, m := range mems {
+		current[m.ID] = m
+	}
+	merged := make(map[string]bool)
+	for _, m := range mems {
+		if merged[m.ID] || m.Conflicting || !checksConflicts(m.Type) {
+			continue
+		}
+		var candidates []*domain.Memory
+		for _, c := range s.findConflictCandidates(ctx, m) {
+			if other := current[c.ID]; other != nil && !merged[c.ID] && !other.Conflicting && other.SessionID == m.SessionID {
+				candidates = append(candidates, other)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		decision := s.detectConflict(ctx, m, candidates)
+		if decision.Outcome != ConflictOutcomeDuplicate {
+			continue
+		}
+		keep, drop := m, current[decision.ExistingID]
+		if scorer.RetentionScore(drop) > scorer.RetentionScore(keep) {
+			keep, drop = drop, keep
+		}
+		merged[drop.ID] = true
+
+		reason := fmt.Sprintf("duplicate of %s", keep.ID)
+		if decision.Reason != "" {
+			reason += ": " + decision.Reason
+		}
+		action := newMaintenanceAction(MaintenanceMerge, drop, scorer.RetentionScore(drop), reason)
+		s.applyMaintenance(report, action, dryRun, func() error {
+			if err := s.mergeDuplicate(ctx, keep, drop, "maintenance"); err != nil {
+				return err
+			}
+			if err := s.supersede(ctx, drop, keep.ID, "maintenance", "duplicate"); err != nil {
+				return err
+			}
+			s.retire(ctx, drop.ID)
+			return nil
+		})
+	}
+
+	var left []*domain.Memory
+	for _, m := range mems {
+		if !merged[m.ID] {
+			left = append(left, m)
+		}
+	}
+	return left
+}
+
+// archive closes a memory's validity window and marks it archived
+func (s *Service) archive(ctx context.Context, m *domain.Memory, reason string, at time.Time) error {
+	m.ValidTo = &at
+	m.UpdatedAt = at
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]interface{})
+	}
+	m.Metadata[store.MetaArchivedAt] = at.Format(time.RFC3339)
+	m.RevisionHistory = append(m.RevisionHistory, domain.MemoryRevision{
+		At:      at,
+		By:      "maintenance",
+		Summary: "archived: " + reason,
+	})
+	if err := s.store.Update(ctx, m); err != nil {
+		return err
+	}
+	s.retire(ctx, m.ID)
+	return nil
+}
+
+// retire drops a memory that is no longer current from the shadow index and
+// the entity graph, so searches stop returning it
+func (s *Service) retire(ctx context.Context, id string) {
+	if s.shadowIndex != nil {
+		_ = s.shadowIndex.Delete(ctx, id)
+	}
+	s.forgetInGraph(ctx, id)
+}
+
+func newMaintenanceAction(kind MaintenanceActionKind, m *domain.Memory, score float64, reason string) MaintenanceAction {
+	return MaintenanceAction{
+		Kind:      kind,
+		MemoryID:  m.ID,
+		SessionID: m.SessionID,
+		Type:      m.Type,
+		Score:     score,
+		Content:   truncateContent(m.Content),
+		Reason:    reason,
+	}
+}
+
+func scopeLabel(sessionID string) string {
+	if sessionID == "" {
+		return "global"
+	}
+	return sessionID
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceTestService(t *testing.T) (*Service, *store.FileMemoryStore) {
+	t.Helper()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	return NewService(fileStore, nil, nil, nil), fileStore
+}
+
+func storeAged(t *testing.T, s *store.FileMemoryStore, id, session string, importance float64, age time.Duration) {
+	t.Helper()
+	require.NoError(t, s.Store(context.Background(), &domain.Memory{
+		ID:         id,
+		SessionID:  session,
+		Type:       domain.MemoryTypeFact,
+		Content:    "memory " + id,
+		Importance: importance,
+		CreatedAt:  time.Now().Add(-age),
+	}))
+}
+
+func TestRetentionScore(t *testing.T) {
+	scorer := NewMemoryScorer(nil)
+	now := time.Now()
+	scorer.SetNowFunc(func() time.Time { return now })
+
+	fresh := &domain.Memory{Importance: 0.5, CreatedAt: now}
+	old := &domain.Memory{Importance: 0.5, CreatedAt: now.AddDate(-1, 0, 0)}
+	recentlyUsed := &domain.Memory{Importance: 0.5, CreatedAt: now.AddDate(-1, 0, 0), LastAccessed: now, AccessCount: 3}
+
+	assert.Greater(t, scorer.RetentionScore(fresh), scorer.RetentionScore(old))
+	assert.Greater(t, scorer.RetentionScore(recentlyUsed), scorer.RetentionScore(fresh))
+	assert.Less(t, scorer.RetentionScore(old), 0.35)
+}
+
+func TestRunMaintenance_DryRunThenArchive(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newMaintenanceTestService(t)
+	year := 365 * 24 * time.Hour
+
+	storeAged(t, fileStore, "old-minor", "", 0.1, year)
+	storeAged(t, fileStore, "old-important", "", 1.0, year)
+	storeAged(t, fileStore, "new-minor", "", 0.1, time.Hour)
+
+	cfg := DefaultMaintenanceConfig()
+	cfg.DryRun = true
+	report, err := svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Scanned)
+	require.Equal(t, 1, report.Count(MaintenanceArchive))
+	assert.Equal(t, 1, report.Count(MaintenanceCompact))
+
+	m, err := svc.Get(ctx, "old-minor")
+	require.NoError(t, err)
+	assert.Nil(t, m.ValidTo, "dry run must not change the store")
+
+	cfg.DryRun = false
+	report, err = svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+
+	m, err = svc.Get(ctx, "old-minor")
+	require.NoError(t, err)
+	require.NotNil(t, m.ValidTo)
+	assert.True(t, store.IsArchived(m))
+	require.NotEmpty(t, m.RevisionHistory)
+	assert.Equal(t, "maintenance", m.RevisionHistory[len(m.RevisionHistory)-1].By)
+
+	idx, err := fileStore.ReadIndex(ctx)
+	require.NoError(t, err)
+	for _, e := range idx.Entries {
+		assert.NotEqual(t, "old-minor", e.ID, "archived memories are dropped from the index")
+	}
+
+	// Archived memories still answer as-of queries for the time they were valid
+	hits, err := svc.SearchAsOf(ctx, "old-minor", time.Now().Add(-24*time.Hour), 5)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+}
+
+func TestRunMaintenance_ScopeQuota(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newMaintenanceTestService(t)
+
+	storeAged(t, fileStore, "a-high", "session-a", 0.9, time.Hour)
+	storeAged(t, fileStore, "a-mid", "session-a", 0.5, time.Hour)
+	storeAged(t, fileStore, "a-low", "session-a", 0.1, time.Hour)
+	storeAged(t, fileStore, "b-only", "session-b", 0.1, time.Hour)
+
+	cfg := DefaultMaintenanceConfig()
+	cfg.ScopeQuota = 2
+	report, err := svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Count(MaintenanceQuota))
+
+	for _, a := range report.Actions {
+		if a.Kind == MaintenanceQuota {
+			assert.Equal(t, "a-low", a.MemoryID)
+		}
+	}
+
+	m, err := svc.Get(ctx, "b-only")
+	require.NoError(t, err)
+	assert.Nil(t, m.ValidTo)
+}
+
+func TestRunMaintenance_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newMaintenanceTestService(t)
+
+	storeAged(t, fileStore, "superseded", "", 0.5, 200*24*time.Hour)
+	storeAged(t, fileStore, "current", "", 0.5, time.Hour)
+	m, err := fileStore.Get(ctx, "superseded")
+	require.NoError(t, err)
+	ended := time.Now().AddDate(0, -3, 0)
+	m.ValidTo = &ended
+	m.SupersededBy = "current"
+	require.NoError(t, fileStore.Update(ctx, m))
+
+	cfg := DefaultMaintenanceConfig()
+	cfg.PurgeAfter = 30 * 24 * time.Hour
+	report, err := svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Count(MaintenancePurge))
+
+	_, err = fileStore.Get(ctx, "superseded")
+	assert.Error(t, err)
+	_, err = fileStore.Get(ctx, "current")
+	assert.NoError(t, err)
+}
+
+func TestRunMaintenance_ArchiveDropsShadowEntry(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newMaintenanceTestService(t)
+	shadow := new(MockMemoryStore)
+	shadow.On("Delete", mock.Anything, "old-minor").Return(nil).Once()
+	svc.SetShadowIndex(shadow)
+
+	storeAged(t, fileStore, "old-minor", "", 0.1, 365*24*time.Hour)
+	storeAged(t, fileStore, "new-minor", "", 0.1, time.Hour)
+
+	report, err := svc.RunMaintenance(ctx, DefaultMaintenanceConfig())
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Count(MaintenanceArchive))
+	shadow.AssertExpectations(t)
+}
+
+func TestRunMaintenance_MergesDuplicates(t *testing.T) {
+	ctx := context.Background()
+	svc, llm := newConflictTestService(t)
+	fileStore, _ := svc.fileStore()
+	now := time.Now()
+	require.NoError(t, fileStore.Store(ctx, &domain.Memory{
+		ID: "tz", Type: domain.MemoryTypeFact, Content: "User works in the Berlin timezone", Importance: 0.9, CreatedAt: now,
+	}))
+	require.NoError(t, fileStore.Store(ctx, &domain.Memory{
+		ID: "tz-dup", Type: domain.MemoryTypeFact, Content: "User works in the Berlin timezone (CET)", Importance: 0.3,
+		EvidenceIDs: []string{"msg-42"}, CreatedAt: now,
+	}))
+	// whichever of the two is looked at first is reported as a duplicate of the other
+	for content, other := range map[string]string{"User works in the Berlin timezone\n": "tz-dup", "User works in the Berlin timezone (CET)\n": "tz"} {
+		raw, _ := json.Marshal(ConflictDecision{Outcome: ConflictOutcomeDuplicate, ExistingID: other, Reason: "same timezone"})
+		llm.On("GenerateStructured", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			return strings.Contains(prompt, "NEW memory (fact):\n"+content)
+		}), mock.Anything, mock.Anything).Return(&domain.StructuredResult{Raw: string(raw), Valid: true}, nil).Maybe()
+	}
+
+	cfg := DefaultMaintenanceConfig()
+	cfg.DryRun = true
+	report, err := svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Count(MaintenanceMerge))
+	dup, err := svc.Get(ctx, "tz-dup")
+	require.NoError(t, err)
+	assert.Nil(t, dup.ValidTo, "dry run must not change the store")
+
+	cfg.DryRun = false
+	report, err = svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	require.Equal(t, 1, report.Count(MaintenanceMerge))
+	for _, a := range report.Actions {
+		if a.Kind == MaintenanceMerge {
+			assert.Equal(t, "tz-dup", a.MemoryID, "the lower scoring copy goes")
+			assert.Equal(t, "duplicate of tz: same timezone", a.Reason)
+		}
+	}
+
+	dup, err = svc.Get(ctx, "tz-dup")
+	require.NoError(t, err)
+	assert.Equal(t, "tz", dup.SupersededBy)
+	require.NotNil(t, dup.ValidTo)
+	kept, err := svc.Get(ctx, "tz")
+	require.NoError(t, err)
+	assert.Nil(t, kept.ValidTo)
+	assert.Equal(t, []string{"msg-42"}, kept.EvidenceIDs)
+	assert.Equal(t, "maintenance", kept.RevisionHistory[len(kept.RevisionHistory)-1].By)
+
+	report, err = svc.RunMaintenance(ctx, cfg)
+	require.NoError(t, err)
+	assert.Zero(t, report.Count(MaintenanceMerge), "merged memories are not looked at again")
+}
@@ -0,0 +1,513 @@
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// ExportFormat identifies memory export files; ExportVersion is bumped on
+// incompatible changes to the record layout
+const (
+	ExportFormat  = "agentgo-memory"
+	ExportVersion = 1
+)
+
+// ExportRecordKind is the kind of a line in an export file
+type ExportRecordKind string
+
+const (
+	ExportRecordHeader      ExportRecordKind = "header"
+	ExportRecordBank        ExportRecordKind = "bank"
+	ExportRecordMentalModel ExportRecordKind = "mental_model"
+	ExportRecordMemory      ExportRecordKind = "memory"
+)
+
+// ExportRecord is one JSONL line of an export. The first line is always a
+// header; exactly one payload field is set on the others.
+type ExportRecord struct {
+	Kind ExportRecordKind `json:"kind"`
+
+	// Header fields
+	Format     string    `json:"format,omitempty"`
+	Version    int       `json:"version,omitempty"`
+	ExportedAt time.Time `json:"exported_at,omitempty"`
+
+	Bank        *ExportedBank       `json:"bank,omitempty"`
+	MentalModel *domain.MentalModel `json:"mental_model,omitempty"`
+	Memory      *domain.Memory      `json:"memory,omitempty"`
+}
+
+// ExportedBank is a bank configuration together with the session it belongs to
+type ExportedBank struct {
+	SessionID string                   `json:"session_id"`
+	Config    *domain.MemoryBankConfig `json:"config"`
+}
+
+// ExportOptions filters what is exported
+type ExportOptions struct {
+	// SessionIDs limits the export to these scopes ("" is the global scope).
+	// Empty exports everything.
+	SessionIDs []string
+	// Types limits memories to these types. Empty exports all types.
+	Types []domain.MemoryType
+	// CurrentOnly skips superseded and archived memories
+	CurrentOnly bool
+}
+
+// ExportStats counts what an export wrote
+type ExportStats struct {
+	Banks        int `json:"banks"`
+	MentalModels int `json:"mental_models"`
+	Memories     int `json:"memories"`
+}
+
+// ImportConflictPolicy decides what happens when an imported memory's ID
+// already exists in the target store
+type ImportConflictPolicy string
+
+const (
+	ImportSkip      ImportConflictPolicy = "skip"      // keep the existing memory
+	ImportOverwrite ImportConflictPolicy = "overwrite" // replace it with the imported one
+	ImportMerge     ImportConflictPolicy = "merge"     // combine evidence, history and counters
+)
+
+// ImportOptions controls how an export is applied to the target store
+type ImportOptions struct {
+	OnConflict ImportConflictPolicy
+	// RemapIDs gives every imported memory a fresh ID and rewrites evidence,
+	// supersession and conflict links to match
+	RemapIDs bool
+	// SessionMap moves memories between scopes, old session ID to new
+	SessionMap map[string]string
+	// SessionIDs imports only these scopes (before mapping). Empty imports all.
+	SessionIDs []string
+	// Reembed computes fresh vectors with the service's embedder
+	Reembed bool
+	// DryRun parses and plans the import without writing
+	DryRun bool
+}
+
+// ImportStats counts what an import did (or would do)
+type ImportStats struct {
+	Banks        int               `json:"banks"`
+	MentalModels int               `json:"mental_models"`
+	Created      int               `json:"created"`
+	Overwritten  int               `json:"overwritten"`
+	Merged       int               `json:"merged"`
+	Skipped      int               `json:"skipped"`
+	Reembedded   int               `json:"reembedded"`
+	IDMap        map[string]string `json:"id_map,omitempty"`
+	Errors       []string          `json:"errors,omitempty"`
+}
+
+// ParseImportConflictPolicy validates a policy name
+func ParseImportConflictPolicy(s string) (ImportConflictPolicy, error) {
+	switch p := ImportConflictPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return ImportSkip, nil
+	case ImportSkip, ImportOverwrite, ImportMerge:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q (want skip, overwrite or merge)", s)
+	}
+}
+
+// bankConfigLister and mentalModelLister are implemented by stores that can
+// enumerate their bank configs and mental models for export
+type bankConfigLister interface {
+	BankConfigs(ctx context.Context) (map[string]*domain.MemoryBankConfig, error)
+}
+
+type mentalModelLister interface {
+	MentalModels(ctx context.Context) ([]*domain.MentalModel, error)
+}
+
+// Export writes the store as versioned JSONL: a header, then bank configs,
+// mental models and memories. Vectors are omitted because they are tied to
+// the embedding model; use ImportOptions.Reembed on the receiving side.
+func (s *Service) Export(ctx context.Context, w io.Writer, opts *ExportOptions) (*ExportStats, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	inScope := sessionFilter(opts.SessionIDs)
+	stats := &ExportStats{}
+	enc := json.NewEncoder(w)
+
+	if err := enc.Encode(&ExportRecord{
+		Kind:       ExportRecordHeader,
+		Format:     ExportFormat,
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	if lister, ok := s.store.(bankConfigLister); ok {
+		banks, err := lister.BankConfigs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list bank configs: %w", err)
+		}
+		for _, sessionID := range sortedKeys(banks) {
+			if !inScope(sessionID) {
+				continue
+			}
+			if err := enc.Encode(&ExportRecord{
+				Kind: ExportRecordBank,
+				Bank: &ExportedBank{SessionID: sessionID, Config: banks[sessionID]},
+			}); err != nil {
+				return nil, err
+			}
+			stats.Banks++
+		}
+	}
+
+	all, _, err := s.store.List(ctx, 100000, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mental models are stored as memories; export them once, as models.
+	// They are global, so a scope filter must include "" to export them.
+	modelIDs := make(map[string]bool)
+	if lister, ok := s.store.(mentalModelLister); ok && inScope("") {
+		models, err := lister.MentalModels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list mental models: %w", err)
+		}
+		for _, model := range models {
+			modelIDs[model.ID] = true
+			if err := enc.Encode(&ExportRecord{Kind: ExportRecordMentalModel, MentalModel: model}); err != nil {
+				return nil, err
+			}
+			stats.MentalModels++
+		}
+	}
+
+	types := make(map[domain.MemoryType]bool, len(opts.Types))
+	for _, t := range opts.Types {
+		types[t] = true
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+	for _, m := range all {
+		if modelIDs[m.ID] || !inScope(m.SessionID) {
+			continue
+		}
+		if len(types) > 0 && !types[m.Type] {
+			continue
+		}
+		if opts.CurrentOnly && (m.ValidTo != nil || m.SupersededBy != "") {
+			continue
+		}
+		exported := *m
+		exported.Vector = nil
+		if err := enc.Encode(&ExportRecord{Kind: ExportRecordMemory, Memory: &exported}); err != nil {
+			return nil, err
+		}
+		stats.Memories++
+	}
+
+	return stats, nil
+}
+
+// Import reads an export produced by Export into the service's store.
+// Memories are written directly, bypassing conflict detection, so that
+// validity intervals and supersession chains arrive unchanged.
+func (s *Service) Import(ctx context.Context, r io.Reader, opts *ImportOptions) (*ImportStats, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	policy, err := ParseImportConflictPolicy(string(opts.OnConflict))
+	if err != nil {
+		return nil, err
+	}
+	ctx = store.WithMemoryChange(ctx, "", "import")
+	if opts.Reembed && s.embedder == nil {
+		return nil, fmt.Errorf("re-embedding requires an embedder")
+	}
+
+	banks, models, memories, err := readExport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	inScope := sessionFilter(opts.SessionIDs)
+	mapSession := func(id string) string {
+		if mapped, ok := opts.SessionMap[id]; ok {
+			return mapped
+		}
+		return id
+	}
+	stats := &ImportStats{}
+
+	for _, b := range banks {
+		if !inScope(b.SessionID) || b.Config == nil {
+			continue
+		}
+		stats.Banks++
+		if opts.DryRun {
+			continue
+		}
+		if err := s.store.ConfigureBank(ctx, mapSession(b.SessionID), b.Config); err != nil {
+			stats.Errors = append(stats.Errors, fmt.Sprintf("bank %s: %v", b.SessionID, err))
+			stats.Banks--
+		}
+	}
+
+	for _, model := range models {
+		if opts.RemapIDs {
+			model.ID = remapID(stats, model.ID)
+		} else if existing, err := s.store.Get(ctx, model.ID); err == nil && existing != nil && policy == ImportSkip {
+			stats.Skipped++
+			continue
+		}
+		stats.MentalModels++
+		if opts.DryRun {
+			continue
+		}
+		if err := s.store.AddMentalModel(ctx, model); err != nil {
+			stats.Errors = append(stats.Errors, fmt.Sprintf("mental model %s: %v", model.ID, err))
+			stats.MentalModels--
+		}
+	}
+
+	var selected []*domain.Memory
+	for _, m := range memories {
+		if inScope(m.SessionID) {
+			selected = append(selected, m)
+		}
+	}
+
+	// Assign every new ID before rewriting links so references to memories
+	// later in the file are remapped too
+	if opts.RemapIDs {
+		for _, m := range selected {
+			m.ID = remapID(stats, m.ID)
+		}
+		for _, m := range selected {
+			rewriteLinks(m, stats.IDMap)
+		}
+	}
+
+	for _, m := range selected {
+		m.SessionID = mapSession(m.SessionID)
+
+		existing, err := s.store.Get(ctx, m.ID)
+		exists := err == nil && existing != nil
+		if exists && policy == ImportSkip {
+			stats.Skipped++
+			continue
+		}
+
+		toStore := m
+		if exists && policy == ImportMerge {
+			toStore = mergeImported(existing, m)
+		}
+
+		if opts.Reembed {
+			if !opts.DryRun {
+				vec, err := s.embedder.Embed(ctx, toStore.Content)
+				if err != nil {
+					stats.Errors = append(stats.Errors, fmt.Sprintf("embed %s: %v", m.ID, err))
+					continue
+				}
+				toStore.Vector = vec
+			}
+			stats.Reembedded++
+		}
+
+		if !opts.DryRun {
+			if err := s.store.Store(ctx, toStore); err != nil {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("memory %s: %v", m.ID, err))
+				continue
+			}
+		}
+
+		switch {
+		case !exists:
+			stats.Created++
+		case policy == ImportMerge:
+			stats.Merged++
+		default:
+			stats.Overwritten++
+		}
+	}
+
+	if !opts.DryRun && s.navigator != nil {
+		s.navigator.InvalidateCache()
+	}
+	return stats, nil
+}
+
+// readExport parses and validates an export stream
+func readExport(r io.Reader) ([]*ExportedBank, []*domain.MentalModel, []*domain.Memory, error) {
+	var (
+		banks    []*ExportedBank
+		models   []*domain.MentalModel
+		memories []*domain.Memory
+		header   bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var rec ExportRecord
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			return nil, nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if !header {
+			if rec.Kind != ExportRecordHeader || rec.Format != ExportFormat {
+				return nil, nil, nil, fmt.Errorf("line %d: not an %s export", line, ExportFormat)
+			}
+			if rec.Version > ExportVersion {
+				return nil, nil, nil, fmt.Errorf("export version %d is newer than supported version %d", rec.Version, ExportVersion)
+			}
+			header = true
+			continue
+		}
+
+		switch rec.Kind {
+		case ExportRecordBank:
+			if rec.Bank != nil {
+				banks = append(banks, rec.Bank)
+			}
+		case ExportRecordMentalModel:
+			if rec.MentalModel != nil {
+				models = append(models, rec.MentalModel)
+			}
+		case ExportRecordMemory:
+			if rec.Memory != nil && rec.Memory.ID != "" {
+				memories = append(memories, rec.Memory)
+			}
+		default:
+			// Unknown kinds from newer minor revisions are ignored
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	if !header {
+		return nil, nil, nil, fmt.Errorf("empty export")
+	}
+	return banks, models, memories, nil
+}
+
+// mergeImported combines an imported memory with the existing one of the
+// same ID. The more recently updated side wins for content and validity;
+// evidence, revisions and usage counters are combined.
+func mergeImported(existing, imported *domain.Memory) *domain.Memory {
+	merged := *existing
+	if imported.UpdatedAt.After(existing.UpdatedAt) {
+		merged = *imported
+	}
+
+	merged.EvidenceIDs = appendUnique(append([]string{}, existing.EvidenceIDs...), imported.EvidenceIDs...)
+
+	seen := make(map[string]bool)
+	var revisions []domain.MemoryRevision
+	for _, rev := range append(append([]domain.MemoryRevision{}, existing.RevisionHistory...), imported.RevisionHistory...) {
+		key := rev.At.UTC().Format(time.RFC3339Nano) + "|" + rev.By + "|" + rev.Summary
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		revisions = append(revisions, rev)
+	}
+	sort.SliceStable(revisions, func(i, j int) bool { return revisions[i].At.Before(revisions[j].At) })
+	merged.RevisionHistory = revisions
+
+	if existing.AccessCount > merged.AccessCount {
+		merged.AccessCount = existing.AccessCount
+	}
+	if existing.LastAccessed.After(merged.LastAccessed) {
+		merged.LastAccessed = existing.LastAccessed
+	}
+	if existing.Importance > merged.Importance {
+		merged.Importance = existing.Importance
+	}
+	if !existing.CreatedAt.IsZero() && existing.CreatedAt.Before(merged.CreatedAt) {
+		merged.CreatedAt = existing.CreatedAt
+	}
+	if len(merged.Vector) == 0 {
+		merged.Vector = existing.Vector
+	}
+
+	if existing.Metadata != nil || imported.Metadata != nil {
+		meta := make(map[string]interface{})
+		for k, v := range existing.Metadata {
+			meta[k] = v
+		}
+		for k, v := range imported.Metadata {
+			meta[k] = v
+		}
+		merged.Metadata = meta
+	}
+	return &merged
+}
+
+// remapID assigns (or returns the already assigned) fresh ID for old
+func remapID(stats *ImportStats, old string) string {
+	if stats.IDMap == nil {
+		stats.IDMap = make(map[string]string)
+	}
+	if id, ok := stats.IDMap[old]; ok {
+		return id
+	}
+	id := uuid.New().String()
+	stats.IDMap[old] = id
+	return id
+}
+
+// rewriteLinks points a memory's references at remapped IDs
+func rewriteLinks(m *domain.Memory, idMap map[string]string) {
+	lookup := func(id string) string {
+		if mapped, ok := idMap[id]; ok {
+			return mapped
+		}
+		return id
+	}
+
+	m.SupersededBy = lookup(m.SupersededBy)
+	for i, id := range m.EvidenceIDs {
+		m.EvidenceIDs[i] = lookup(id)
+	}
+	if m.Metadata != nil {
+		if raw, ok := m.Metadata[metaConflictsWith]; ok {
+			ids := splitIDs(fmt.Sprint(raw))
+			for i, id := range ids {
+				ids[i] = lookup(id)
+			}
+			setConflictsWith(m, ids)
+		}
+	}
+}
+
+// sessionFilter returns a predicate matching the given session IDs, or
+// everything when none are given
+func sessionFilter(sessionIDs []string) func(string) bool {
+	if len(sessionIDs) == 0 {
+		return func(string) bool { return true }
+	}
+	allowed := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		allowed[id] = true
+	}
+	return func(id string) bool { return allowed[id] }
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPortabilityTestService(t *testing.T) *Service {
+	t.Helper()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	return NewService(fileStore, nil, nil, nil)
+}
+
+// seedPortability stores a superseded fact, its replacement, an observation
+// citing both, a session-scoped preference, a mental model and a bank config
+func seedPortability(t *testing.T, svc *Service) {
+	t.Helper()
+	ctx := context.Background()
+	created := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	ended := created.Add(48 * time.Hour)
+
+	for _, m := range []*domain.Memory{
+		{ID: "city-old", Type: domain.MemoryTypeFact, Content: "User lives in Paris", Importance: 0.7,
+			CreatedAt: created, ValidTo: &ended, SupersededBy: "city-new",
+			RevisionHistory: []domain.MemoryRevision{{At: ended, By: "conflict", Summary: "superseded by city-new"}}},
+		{ID: "city-new", Type: domain.MemoryTypeFact, Content: "User lives in Berlin", Importance: 0.8,
+			CreatedAt: ended, ValidFrom: ended},
+		{ID: "obs", Type: domain.MemoryTypeObservation, Content: "User relocates often", Importance: 0.6,
+			CreatedAt: ended, EvidenceIDs: []string{"city-old", "city-new"}, Confidence: 0.7},
+		{ID: "pref", SessionID: "project-x", Type: domain.MemoryTypePreference, Content: "Prefers tabs", Importance: 0.5,
+			CreatedAt: created},
+	} {
+		require.NoError(t, svc.store.Store(ctx, m))
+	}
+	require.NoError(t, svc.AddMentalModel(ctx, &domain.MentalModel{
+		ID: "mm-1", Name: "Release rules", Description: "How we ship", Content: "Always tag releases", Tags: []string{"ops", "release"},
+	}))
+	require.NoError(t, svc.ConfigureBank(ctx, "project-x", &domain.MemoryBankConfig{
+		Mission: "Project X assistant", Directives: []string{"Never push to main"}, Skepticism: 4,
+	}))
+}
+
+func exportToBuffer(t *testing.T, svc *Service, opts *ExportOptions) (*bytes.Buffer, *ExportStats) {
+	t.Helper()
+	var buf bytes.Buffer
+	stats, err := svc.Export(context.Background(), &buf, opts)
+	require.NoError(t, err)
+	return &buf, stats
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newPortabilityTestService(t)
+	seedPortability(t, src)
+
+	buf, stats := exportToBuffer(t, src, nil)
+	assert.Equal(t, &ExportStats{Banks: 1, MentalModels: 1, Memories: 4}, stats)
+
+	var header ExportRecord
+	firstLine := strings.SplitN(buf.String(), "\n", 2)[0]
+	require.NoError(t, json.Unmarshal([]byte(firstLine), &header))
+	assert.Equal(t, ExportRecordHeader, header.Kind)
+	assert.Equal(t, ExportFormat, header.Format)
+	assert.Equal(t, ExportVersion, header.Version)
+
+	dst := newPortabilityTestService(t)
+	imported, err := dst.Import(ctx, buf, nil)
+	require.NoError(t, err)
+	assert.Empty(t, imported.Errors)
+	assert.Equal(t, 4, imported.Created)
+	assert.Equal(t, 1, imported.MentalModels)
+	assert.Equal(t, 1, imported.Banks)
+
+	old, err := dst.Get(ctx, "city-old")
+	require.NoError(t, err)
+	require.NotNil(t, old.ValidTo)
+	assert.Equal(t, "city-new", old.SupersededBy)
+	assert.Len(t, old.RevisionHistory, 1)
+
+	obs, err := dst.Get(ctx, "obs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"city-old", "city-new"}, obs.EvidenceIDs)
+
+	pref, err := dst.Get(ctx, "pref")
+	require.NoError(t, err)
+	assert.Equal(t, "project-x", pref.SessionID)
+
+	models, err := dst.store.(*store.FileMemoryStore).MentalModels(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "Always tag releases", models[0].Content)
+	assert.Equal(t, []string{"ops", "release"}, models[0].Tags)
+
+	banks, err := dst.store.(*store.FileMemoryStore).BankConfigs(ctx)
+	require.NoError(t, err)
+	require.Contains(t, banks, "project-x")
+	assert.Equal(t, []string{"Never push to main"}, banks["project-x"].Directives)
+}
+
+func TestExport_ScopeAndCurrentOnly(t *testing.T) {
+	src := newPortabilityTestService(t)
+	seedPortability(t, src)
+
+	_, stats := exportToBuffer(t, src, &ExportOptions{SessionIDs: []string{"project-x"}})
+	assert.Equal(t, 1, stats.Memories)
+	assert.Equal(t, 1, stats.Banks)
+
+	_, stats = exportToBuffer(t, src, &ExportOptions{CurrentOnly: true})
+	assert.Equal(t, 3, stats.Memories)
+}
+
+func TestImport_ConflictPolicies(t *testing.T) {
+	ctx := context.Background()
+	src := newPortabilityTestService(t)
+	seedPortability(t, src)
+	buf, _ := exportToBuffer(t, src, nil)
+	export := buf.String()
+
+	dst := newPortabilityTestService(t)
+	require.NoError(t, dst.store.Store(ctx, &domain.Memory{
+		ID: "obs", Type: domain.MemoryTypeObservation, Content: "Local wording", Importance: 0.9,
+		AccessCount: 7, EvidenceIDs: []string{"local-fact"}, CreatedAt: time.Now(),
+	}))
+
+	stats, err := dst.Import(ctx, strings.NewReader(export), &ImportOptions{OnConflict: ImportSkip})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Skipped)
+	got, _ := dst.Get(ctx, "obs")
+	assert.Equal(t, "Local wording", got.Content)
+
+	stats, err = dst.Import(ctx, strings.NewReader(export), &ImportOptions{OnConflict: ImportMerge})
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Merged)
+	got, _ = dst.Get(ctx, "obs")
+	assert.ElementsMatch(t, []string{"local-fact", "city-old", "city-new"}, got.EvidenceIDs)
+	assert.Equal(t, 7, got.AccessCount)
+	assert.InDelta(t, 0.9, got.Importance, 1e-9)
+
+	stats, err = dst.Import(ctx, strings.NewReader(export), &ImportOptions{OnConflict: ImportOverwrite})
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Overwritten)
+	got, _ = dst.Get(ctx, "obs")
+	assert.Equal(t, []string{"city-old", "city-new"}, got.EvidenceIDs)
+
+	_, err = dst.Import(ctx, strings.NewReader(export), &ImportOptions{OnConflict: "replace"})
+	assert.Error(t, err)
+}
+
+func TestImport_RemapIDsAndSessions(t *testing.T) {
+	ctx := context.Background()
+	src := newPortabilityTestService(t)
+	seedPortability(t, src)
+	buf, _ := exportToBuffer(t, src, nil)
+
+	dst := newPortabilityTestService(t)
+	stats, err := dst.Import(ctx, buf, &ImportOptions{
+		RemapIDs:   true,
+		SessionMap: map[string]string{"project-x": "project-y"},
+	})
+	require.NoError(t, err)
+	require.Len(t, stats.IDMap, 5)
+
+	old, err := dst.Get(ctx, stats.IDMap["city-old"])
+	require.NoError(t, err)
+	assert.Equal(t, stats.IDMap["city-new"], old.SupersededBy)
+
+	obs, err := dst.Get(ctx, stats.IDMap["obs"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{stats.IDMap["city-old"], stats.IDMap["city-new"]}, obs.EvidenceIDs)
+
+	pref, err := dst.Get(ctx, stats.IDMap["pref"])
+	require.NoError(t, err)
+	assert.Equal(t, "project-y", pref.SessionID)
+
+	_, err = dst.Get(ctx, "city-old")
+	assert.Error(t, err, "original IDs are not reused")
+}
+
+func TestImport_ReembedAndValidation(t *testing.T) {
+	ctx := context.Background()
+	src := newPortabilityTestService(t)
+	seedPortability(t, src)
+	buf, _ := exportToBuffer(t, src, &ExportOptions{SessionIDs: []string{"project-x"}})
+	export := buf.String()
+
+	noEmbedder := newPortabilityTestService(t)
+	_, err := noEmbedder.Import(ctx, strings.NewReader(export), &ImportOptions{Reembed: true})
+	assert.Error(t, err)
+
+	memStore := new(MockMemoryStore)
+	embedder := new(MockEmbedder)
+	svc := NewService(memStore, nil, embedder, nil)
+	embedder.On("Embed", mock.Anything, "Prefers tabs").Return([]float64{0.1, 0.2}, nil).Once()
+	memStore.On("ConfigureBank", mock.Anything, "project-x", mock.Anything).Return(nil)
+	memStore.On("Get", mock.Anything, "pref").Return(nil, store.ErrMemoryNotFound)
+	memStore.On("Store", mock.Anything, mock.MatchedBy(func(m *domain.Memory) bool {
+		return m.ID == "pref" && len(m.Vector) == 2
+	})).Return(nil).Once()
+
+	stats, err := svc.Import(ctx, strings.NewReader(export), &ImportOptions{Reembed: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Reembedded)
+	memStore.AssertExpectations(t)
+	embedder.AssertExpectations(t)
+
+	_, err = svc.Import(ctx, strings.NewReader(`{"kind":"memory","memory":{"id":"x"}}`), nil)
+	assert.Error(t, err, "files without a header are rejected")
+}
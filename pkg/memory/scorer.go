@@ -10,26 +10,31 @@ import (
 // ScoringConfig holds configuration for memory scoring
 type ScoringConfig struct {
 	// Recency settings
-	RecencyWeight float64 // Weight for recency factor (default: 0.3)
-	HalfLifeDays  float64 // Half-life in days for exponential decay (default: 30.0)
-	EnableRecency bool    // Enable recency scoring
+	RecencyWeight float64 `json:"recency_weight"` // Weight for recency factor (default: 0.3)
+	HalfLifeDays  float64 `json:"half_life_days"` // Half-life in days for exponential decay (default: 30.0)
+	EnableRecency bool    `json:"enable_recency"` // Enable recency scoring
 
 	// Importance settings
-	ImportanceWeight float64 // Weight for importance factor (default: 0.3)
-	MinImportance    float64 // Minimum importance factor (default: 0.7)
-	EnableImportance bool    // Enable importance scoring
+	ImportanceWeight float64 `json:"importance_weight"` // Weight for importance factor (default: 0.3)
+	MinImportance    float64 `json:"min_importance"`    // Minimum importance factor (default: 0.7)
+	EnableImportance bool    `json:"enable_importance"` // Enable importance scoring
 
 	// Length normalization settings
-	LengthNormWeight float64 // Weight for length normalization (default: 0.1)
-	AnchorLength     int     // Anchor length for normalization (default: 100)
-	EnableLengthNorm bool    // Enable length normalization
+	LengthNormWeight float64 `json:"length_norm_weight"` // Weight for length normalization (default: 0.1)
+	AnchorLength     int     `json:"anchor_length"`      // Anchor length for normalization (default: 100)
+	EnableLengthNorm bool    `json:"enable_length_norm"` // Enable length normalization
 
 	// Access boost settings
-	AccessBoostWeight float64 // Weight for access boost (default: 0.1)
-	EnableAccessBoost bool    // Enable access boost
+	AccessBoostWeight float64 `json:"access_boost_weight"` // Weight for access boost (default: 0.1)
+	EnableAccessBoost bool    `json:"enable_access_boost"` // Enable access boost
 
 	// Vector score weight
-	VectorScoreWeight float64 // Weight for original vector similarity score (default: 0.2)
+	VectorScoreWeight float64 `json:"vector_score_weight"` // Weight for original vector similarity score (default: 0.2)
+
+	// Episode outcome settings
+	SuccessWeight float64 `json:"success_weight"` // Factor for episodes of successful runs (default: 1.0)
+	FailureWeight float64 `json:"failure_weight"` // Factor for episodes of failed runs (default: 0.6)
+	EnableOutcome bool    `json:"enable_outcome"` // Weight episodes by outcome and user feedback
 }
 
 // DefaultScoringConfig returns default scoring configuration
@@ -47,6 +52,9 @@ func DefaultScoringConfig() *ScoringConfig {
 		AccessBoostWeight: 0.1,
 		EnableAccessBoost: true,
 		VectorScoreWeight: 0.2,
+		SuccessWeight:     1.0,
+		FailureWeight:     0.6,
+		EnableOutcome:     true,
 	}
 }
 
@@ -67,41 +75,95 @@ func NewMemoryScorer(config *ScoringConfig) *MemoryScorer {
 	}
 }
 
+// ScoreBreakdown records the factors MemoryScorer applied to one memory.
+// A factor is 0 when it was disabled or did not apply.
+type ScoreBreakdown struct {
+	Base       float64 `json:"base"`       // score from retrieval, before scoring
+	Recency    float64 `json:"recency"`    // time decay factor
+	Importance float64 `json:"importance"` // importance factor
+	Length     float64 `json:"length"`     // length normalization factor
+	Access     float64 `json:"access"`     // access boost factor
+	Outcome    float64 `json:"outcome"`    // episode outcome multiplier
+	Final      float64 `json:"final"`
+}
+
 // Score calculates the enhanced score for a single memory
 // Formula: score = baseScore * recencyFactor * importanceFactor * lengthFactor * accessFactor
 func (s *MemoryScorer) Score(memory *domain.MemoryWithScore) float64 {
+	return s.Explain(memory).Final
+}
+
+// Explain calculates the enhanced score for a single memory and reports
+// each factor that went into it
+func (s *MemoryScorer) Explain(memory *domain.MemoryWithScore) ScoreBreakdown {
 	if memory == nil {
-		return 0
+		return ScoreBreakdown{}
 	}
 
 	baseScore := memory.Score
 	finalScore := baseScore
+	b := ScoreBreakdown{Base: baseScore}
 
 	// Apply recency factor
 	if s.config.EnableRecency && memory.CreatedAt.IsZero() == false {
-		recencyFactor := s.calculateRecencyFactor(memory.CreatedAt)
-		finalScore = finalScore*(1-s.config.RecencyWeight) + baseScore*recencyFactor*s.config.RecencyWeight
+		b.Recency = s.calculateRecencyFactor(memory.CreatedAt)
+		finalScore = finalScore*(1-s.config.RecencyWeight) + baseScore*b.Recency*s.config.RecencyWeight
 	}
 
 	// Apply importance factor
 	if s.config.EnableImportance {
-		importanceFactor := s.calculateImportanceFactor(memory.Importance)
-		finalScore = finalScore*(1-s.config.ImportanceWeight) + baseScore*importanceFactor*s.config.ImportanceWeight
+		b.Importance = s.calculateImportanceFactor(memory.Importance)
+		finalScore = finalScore*(1-s.config.ImportanceWeight) + baseScore*b.Importance*s.config.ImportanceWeight
 	}
 
 	// Apply length normalization
 	if s.config.EnableLengthNorm && len(memory.Content) > 0 {
-		lengthFactor := s.calculateLengthFactor(len(memory.Content))
-		finalScore = finalScore*(1-s.config.LengthNormWeight) + baseScore*lengthFactor*s.config.LengthNormWeight
+		b.Length = s.calculateLengthFactor(len(memory.Content))
+		finalScore = finalScore*(1-s.config.LengthNormWeight) + baseScore*b.Length*s.config.LengthNormWeight
 	}
 
 	// Apply access boost
 	if s.config.EnableAccessBoost && memory.AccessCount > 0 {
-		accessFactor := s.calculateAccessFactor(memory.AccessCount)
-		finalScore = finalScore*(1-s.config.AccessBoostWeight) + baseScore*accessFactor*s.config.AccessBoostWeight
+		b.Access = s.calculateAccessFactor(memory.AccessCount)
+		finalScore = finalScore*(1-s.config.AccessBoostWeight) + baseScore*b.Access*s.config.AccessBoostWeight
+	}
+
+	// Apply episode outcome
+	b.Outcome = 1.0
+	if memory.Memory != nil {
+		b.Outcome = s.OutcomeFactor(memory.Memory)
+		finalScore *= b.Outcome
+	}
+
+	b.Final = finalScore
+	return b
+}
+
+// Config returns the scorer's configuration
+func (s *MemoryScorer) Config() *ScoringConfig {
+	return s.config
+}
+
+// OutcomeFactor weights an episode by how its run ended: successes by
+// SuccessWeight, failures by FailureWeight, adjusted by user feedback.
+// Memories other than episodes, and all memories when EnableOutcome is
+// off, get 1.
+func (s *MemoryScorer) OutcomeFactor(memory *domain.Memory) float64 {
+	if !s.config.EnableOutcome || memory == nil || memory.Type != domain.MemoryTypeEpisode {
+		return 1.0
 	}
 
-	return finalScore
+	factor := s.config.SuccessWeight
+	if metaString(memory.Metadata, domain.MetaEpisodeOutcome) == string(domain.EpisodeFailure) {
+		factor = s.config.FailureWeight
+	}
+	switch metaString(memory.Metadata, domain.MetaEpisodeFeedback) {
+	case "positive":
+		factor *= 1.2
+	case "negative":
+		factor *= 0.5
+	}
+	return factor
 }
 
 // ScoreAll scores and sorts all memories
@@ -121,6 +183,31 @@ func (s *MemoryScorer) ScoreAll(memories []*domain.MemoryWithScore) []*domain.Me
 	return memories
 }
 
+// RetentionScore returns how worth keeping a memory is, independent of any
+// query: the recency, importance and access factors applied to a base of 1.
+// Recency is measured from the last access when the memory has been used.
+func (s *MemoryScorer) RetentionScore(memory *domain.Memory) float64 {
+	if memory == nil {
+		return 0
+	}
+
+	score := 1.0
+	if s.config.EnableRecency {
+		lastActive := memory.CreatedAt
+		if memory.LastAccessed.After(lastActive) {
+			lastActive = memory.LastAccessed
+		}
+		score *= s.calculateRecencyFactor(lastActive)
+	}
+	if s.config.EnableImportance {
+		score *= s.calculateImportanceFactor(memory.Importance)
+	}
+	if s.config.EnableAccessBoost {
+		score *= s.calculateAccessFactor(memory.AccessCount)
+	}
+	return score
+}
+
 // calculateRecencyFactor calculates time decay factor
 // Uses exponential decay: exp(-ageDays / halfLife)
 func (s *MemoryScorer) calculateRecencyFactor(createdAt time.Time) float64 {
@@ -197,3 +197,67 @@ func TestMemoryScorer_ScoreAll(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryScorer_OutcomeFactor(t *testing.T) {
+	scorer := NewMemoryScorer(nil)
+	episode := func(outcome domain.EpisodeOutcome, feedback string) *domain.Memory {
+		return &domain.Memory{
+			Type: domain.MemoryTypeEpisode,
+			Metadata: map[string]interface{}{
+				domain.MetaEpisodeOutcome:  string(outcome),
+				domain.MetaEpisodeFeedback: feedback,
+			},
+		}
+	}
+
+	success := scorer.OutcomeFactor(episode(domain.EpisodeSuccess, ""))
+	failure := scorer.OutcomeFactor(episode(domain.EpisodeFailure, ""))
+	liked := scorer.OutcomeFactor(episode(domain.EpisodeSuccess, "positive"))
+	disliked := scorer.OutcomeFactor(episode(domain.EpisodeSuccess, "negative"))
+
+	if !(liked > success && success > failure && success > disliked) {
+		t.Errorf("unexpected outcome ordering: liked=%v success=%v failure=%v disliked=%v", liked, success, failure, disliked)
+	}
+	if f := scorer.OutcomeFactor(&domain.Memory{Type: domain.MemoryTypeFact}); f != 1.0 {
+		t.Errorf("OutcomeFactor() for a fact = %v, want 1", f)
+	}
+
+	disabled := NewMemoryScorer(&ScoringConfig{})
+	if f := disabled.OutcomeFactor(episode(domain.EpisodeFailure, "")); f != 1.0 {
+		t.Errorf("OutcomeFactor() with outcome disabled = %v, want 1", f)
+	}
+}
+
+func TestMemoryScorer_Explain(t *testing.T) {
+	scorer := NewMemoryScorer(nil)
+	now := time.Now()
+	scorer.SetNowFunc(func() time.Time { return now })
+
+	m := &domain.MemoryWithScore{
+		Memory: &domain.Memory{
+			Content:     "User prefers dark mode in every editor they use",
+			Importance:  0.6,
+			AccessCount: 3,
+			CreatedAt:   now.AddDate(0, 0, -10),
+		},
+		Score: 0.8,
+	}
+
+	b := scorer.Explain(m)
+	if b.Final != scorer.Score(m) {
+		t.Errorf("Explain final %v != Score %v", b.Final, scorer.Score(m))
+	}
+	if b.Base != 0.8 || b.Outcome != 1.0 {
+		t.Errorf("unexpected base/outcome: %+v", b)
+	}
+	for name, f := range map[string]float64{"recency": b.Recency, "importance": b.Importance, "length": b.Length, "access": b.Access} {
+		if f <= 0 || f > 1 {
+			t.Errorf("%s factor %v out of (0, 1]", name, f)
+		}
+	}
+
+	m.AccessCount = 0
+	if b := scorer.Explain(m); b.Access != 0 {
+		t.Errorf("access factor should be 0 when it does not apply, got %v", b.Access)
+	}
+}
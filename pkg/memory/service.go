@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -39,6 +40,24 @@ type Service struct {
 	navigator        *IndexNavigator
 	reflectThreshold int // auto-reflect after this many new facts
 
+	// Contradiction detection on Add
+	detectConflicts   bool
+	conflictThreshold float64
+
+	// Episodic memory of agent runs
+	episodes *EpisodeConfig
+
+	// Entity graph over stored facts; nil disables graph extraction/queries
+	entityGraph *EntityGraph
+
+	// Review of extracted memories before they are stored
+	suggestionPolicy SuggestionPolicy
+	suggestions      domain.MemorySuggestionStore
+
+	// Scope-aware access control; nil means every caller is trusted
+	accessPolicy domain.MemoryAccessPolicy
+	auditor      domain.MemoryAuditor
+
 	mu sync.RWMutex
 }
 
@@ -59,6 +78,18 @@ type Config struct {
 
 	// Hindsight: auto-reflect after this many new facts (0 = disabled)
 	ReflectThreshold int
+
+	// DetectConflicts compares new facts and preferences with close existing
+	// memories via the LLM to merge duplicates, supersede outdated memories
+	// and flag contradictions for review
+	DetectConflicts bool
+	// ConflictThreshold is the minimum similarity for an existing memory to
+	// be considered (default 0.6)
+	ConflictThreshold float64
+
+	// Episodes records completed agent runs and recalls similar ones as
+	// examples (nil disables)
+	Episodes *EpisodeConfig
 }
 
 // DefaultConfig returns default configuration
@@ -73,6 +104,9 @@ func DefaultConfig() *Config {
 		EnableHybrid:      false,
 		RRFK:              60.0,
 		ReflectThreshold:  5,
+		DetectConflicts:   true,
+		ConflictThreshold: 0.6,
+		Episodes:          DefaultEpisodeConfig(),
 	}
 }
 
@@ -101,6 +135,23 @@ func NewService(
 		enableHybrid:     config.EnableHybrid,
 		rrfK:             config.RRFK,
 		reflectThreshold: config.ReflectThreshold,
+		detectConflicts:  config.DetectConflicts,
+	}
+	svc.conflictThreshold = config.ConflictThreshold
+	if svc.conflictThreshold <= 0 {
+		svc.conflictThreshold = 0.6
+	}
+
+	svc.episodes = &EpisodeConfig{}
+	if config.Episodes != nil {
+		episodes := *config.Episodes
+		svc.episodes = &episodes
+	}
+	if svc.episodes.MaxExamples <= 0 {
+		svc.episodes.MaxExamples = 2
+	}
+	if svc.episodes.MaxCandidates <= 0 {
+		svc.episodes.MaxCandidates = 500
 	}
 
 	if config.NoiseFilterConfig != nil {
@@ -108,7 +159,7 @@ func NewService(
 	}
 
 	// Wire up IndexNavigator for file-based stores when no embedder is available
-	if fileStore, ok := memStore.(*store.FileMemoryStore); ok && llm != nil {
+	if fileStore, ok := store.Unwrap(memStore).(*store.FileMemoryStore); ok && llm != nil {
 		fileStore.WithLLM(llm)
 		svc.navigator = NewIndexNavigator(fileStore, llm)
 	}
@@ -123,9 +174,75 @@ func (s *Service) SetPromptManager(m *prompt.Manager) {
 
 // SetShadowIndex sets the optional vector index for accelerating file-based stores
 func (s *Service) SetShadowIndex(idx domain.MemoryStore) {
+	if s.accessPolicy != nil && idx != nil {
+		idx = store.NewAccessControlledStore(store.Unwrap(idx), s.accessPolicy, s.auditor)
+	}
 	s.shadowIndex = idx
 }
 
+// SetAccessControl enforces policy for callers carrying a
+// domain.MemoryPrincipal on their context. Store reads are filtered, writes
+// outside the principal's scopes fail with store.ErrMemoryAccessDenied, and
+// retrieved context is redacted. auditor may be nil.
+func (s *Service) SetAccessControl(policy domain.MemoryAccessPolicy, auditor domain.MemoryAuditor) {
+	if policy == nil {
+		policy = store.DefaultScopePolicy()
+	}
+	s.accessPolicy = policy
+	s.auditor = auditor
+	s.store = store.NewAccessControlledStore(store.Unwrap(s.store), policy, auditor)
+	if s.shadowIndex != nil {
+		s.shadowIndex = store.NewAccessControlledStore(store.Unwrap(s.shadowIndex), policy, auditor)
+	}
+}
+
+// ConfigureAccess builds a store.ScopePolicy from per-scope-type rules and,
+// if auditPath is set, appends audit events to it as JSONL
+func (s *Service) ConfigureAccess(read, write map[string]string, auditPath string) error {
+	policy, err := store.NewScopePolicy(read, write)
+	if err != nil {
+		return err
+	}
+	var auditor domain.MemoryAuditor
+	if auditPath != "" {
+		log, err := store.NewFileAuditLog(auditPath)
+		if err != nil {
+			return err
+		}
+		auditor = log
+	}
+	s.SetAccessControl(policy, auditor)
+	return nil
+}
+
+// fileStore returns the file store underneath any access control wrapper
+func (s *Service) fileStore() (*store.FileMemoryStore, bool) {
+	fs, ok := store.Unwrap(s.store).(*store.FileMemoryStore)
+	return fs, ok
+}
+
+// canRead reports whether the principal on ctx may read m
+func (s *Service) canRead(ctx context.Context, m *domain.Memory) bool {
+	if s.accessPolicy == nil || m == nil {
+		return true
+	}
+	return s.accessPolicy.Allow(domain.MemoryPrincipalFromContext(ctx), domain.MemoryScopeOf(m), domain.MemoryAccessRead)
+}
+
+func (s *Service) auditRedaction(ctx context.Context, op string, redacted int) {
+	if s.auditor == nil {
+		return
+	}
+	s.auditor.RecordMemoryAccess(ctx, &domain.MemoryAuditEvent{
+		At:        time.Now(),
+		Principal: domain.MemoryPrincipalFromContext(ctx).String(),
+		Operation: op,
+		Mode:      domain.MemoryAccessRead,
+		Allowed:   true,
+		Redacted:  redacted,
+	})
+}
+
 // RetrieveAndInject searches relevant memories and formats them for LLM context.
 // T6a: Routing logic —
 //   - embedder available → vector search (+ optional hybrid BM25)
@@ -141,9 +258,35 @@ func (s *Service) RetrieveAndInject(ctx context.Context, query string, sessionID
 // RetrieveAndInjectWithLogic is the full retrieval pipeline returning the navigator's
 // reasoning string alongside the context and memories.
 func (s *Service) RetrieveAndInjectWithLogic(ctx context.Context, query string, sessionID string) (string, []*domain.MemoryWithScore, string, error) {
+	mems, memoryLogic := s.retrieve(ctx, query, sessionID, nil, nil)
+	if len(mems) == 0 {
+		return "", nil, memoryLogic, nil
+	}
+	return s.formatMemories(mems), mems, memoryLogic, nil
+}
+
+// retrieve runs the retrieval pipeline. opts may override scoring for this
+// query; tr, if non-nil, records why each candidate was kept or dropped and
+// leaves access counts untouched.
+func (s *Service) retrieve(ctx context.Context, query string, sessionID string, opts *RetrieveOptions, tr *retrievalTrace) ([]*domain.MemoryWithScore, string) {
+	scorer := s.scorer
+	maxMemories := s.maxMemories
+	if opts != nil {
+		if opts.Scoring != nil {
+			scorer = NewMemoryScorer(opts.Scoring)
+		}
+		if opts.MaxMemories > 0 {
+			maxMemories = opts.MaxMemories
+		}
+	}
+
 	// 0. Adaptive retrieval - skip if query doesn't need memory
-	if s.classifier != nil && !s.classifier.NeedsMemory(query) {
-		return "", nil, "", nil
+	if s.classifier != nil {
+		needs := s.classifier.NeedsMemory(query)
+		tr.classified(s.classifier.Classify(query), needs)
+		if !needs {
+			return nil, ""
+		}
 	}
 
 	var allMemories []*domain.MemoryWithScore
@@ -153,9 +296,10 @@ func (s *Service) RetrieveAndInjectWithLogic(ctx context.Context, query string,
 	if s.entityMemory != nil && query != "" {
 		entities, err := s.entityMemory.SearchEntities(ctx, query, 3)
 		if err == nil {
+			var entityMemories []*domain.MemoryWithScore
 			for _, ent := range entities {
 				content := fmt.Sprintf("Entity: %s (%s) - %s", ent.Name, ent.Type, ent.Description)
-				allMemories = append(allMemories, &domain.MemoryWithScore{
+				entityMemories = append(entityMemories, &domain.MemoryWithScore{
 					Memory: &domain.Memory{
 						ID:         "ent_" + ent.Name,
 						Type:       domain.MemoryTypeFact,
@@ -165,20 +309,37 @@ func (s *Service) RetrieveAndInjectWithLogic(ctx context.Context, query string,
 					Score: 1.0,
 				})
 			}
+			tr.found(SourceEntity, entityMemories)
+			allMemories = append(allMemories, entityMemories...)
 		}
 	}
 
+	// 1b. Entity graph: facts about entities the query names, plus their
+	// direct relations
+	if s.entityGraph != nil && query != "" {
+		graphMemories := s.graphMemories(ctx, query)
+		tr.found(SourceGraph, graphMemories)
+		allMemories = append(allMemories, graphMemories...)
+	}
+
 	// 2. Vector Search (if embedder available)
 	var vectorResults []*domain.MemoryWithScore
 	if s.embedder != nil {
 		vector, err := s.embedder.Embed(ctx, query)
 		if err == nil {
-			scopes := DefaultScopeChain(sessionID, "", "", "")
-			vectorResults, _ = s.store.SearchByScope(ctx, vector, scopes.ToSlice(), s.maxMemories*2)
+			userID := ""
+			if p := domain.MemoryPrincipalFromContext(ctx); p != nil {
+				userID = p.UserID
+			}
+			scopes := DefaultScopeChain(sessionID, "", "", userID)
+			vectorResults, _ = s.store.SearchByScope(ctx, vector, scopes.ToSlice(), maxMemories*2)
+			tr.found(SourceVector, vectorResults)
 
 			if s.enableHybrid {
-				textMems, _ := s.store.SearchByText(ctx, query, s.maxMemories)
+				textMems, _ := s.store.SearchByText(ctx, query, maxMemories)
+				tr.found(SourceText, textMems)
 				vectorResults = s.rrfFusion(vectorResults, textMems)
+				tr.fused(vectorResults)
 			}
 		}
 	}
@@ -186,13 +347,14 @@ func (s *Service) RetrieveAndInjectWithLogic(ctx context.Context, query string,
 	// 3. Navigator Search (PageIndex-style, for file stores) — captures reasoning
 	var navResults []*domain.MemoryWithScore
 	if s.navigator != nil {
-		navResult, err := s.navigator.NavigateWithReason(ctx, query, s.maxMemories)
+		navResult, err := s.navigator.NavigateWithReason(ctx, query, maxMemories)
 		if err == nil && navResult != nil {
 			memoryLogic = navResult.Reasoning
 			for i, m := range navResult.Memories {
 				score := 1.0 - float64(i)*0.05
 				navResults = append(navResults, &domain.MemoryWithScore{Memory: m, Score: score})
 			}
+			tr.found(SourceNavigator, navResults)
 		}
 	}
 
@@ -200,7 +362,9 @@ func (s *Service) RetrieveAndInjectWithLogic(ctx context.Context, query string,
 	switch {
 	case len(vectorResults) > 0 && len(navResults) > 0:
 		// Both available: RRF fusion
-		allMemories = append(allMemories, s.rrfFusion(vectorResults, navResults)...)
+		fused := s.rrfFusion(vectorResults, navResults)
+		tr.fused(fused)
+		allMemories = append(allMemories, fused...)
 	case len(vectorResults) > 0:
 		allMemories = append(allMemories, vectorResults...)
 	case len(navResults) > 0:
@@ -208,52 +372,148 @@ func (s *Service) RetrieveAndInjectWithLogic(ctx context.Context, query string,
 	default:
 		// Final fallback: keyword search first, then plain list
 		if query != "" {
-			textMems, err := s.store.SearchByText(ctx, query, s.maxMemories)
+			textMems, err := s.store.SearchByText(ctx, query, maxMemories)
 			if err == nil && len(textMems) > 0 {
+				tr.found(SourceText, textMems)
 				allMemories = append(allMemories, textMems...)
 				break
 			}
 		}
-		mems, _, _ := s.store.List(ctx, s.maxMemories, 0)
+		mems, _, _ := s.store.List(ctx, maxMemories, 0)
+		var recent []*domain.MemoryWithScore
 		for _, m := range mems {
-			allMemories = append(allMemories, &domain.MemoryWithScore{Memory: m, Score: 0.5})
+			recent = append(recent, &domain.MemoryWithScore{Memory: m, Score: 0.5})
 		}
+		tr.found(SourceRecent, recent)
+		allMemories = append(allMemories, recent...)
 	}
 
-	// 5. Noise filtering
+	// 5. Noise filtering (archived, superseded and expired memories are kept
+	// only for history and episodes are recalled separately as examples).
+	// The navigator, entity and recent sources read outside the store's
+	// validity filters, and the navigator and entity memory outside its
+	// access control, so both are applied here too.
+	active := allMemories[:0]
+	redacted := 0
+	now := time.Now()
+	for _, m := range allMemories {
+		if m.Memory != nil && store.IsArchived(m.Memory) {
+			tr.excluded(m, "archived")
+			continue
+		}
+		if m.Memory != nil && m.SupersededBy != "" {
+			tr.excluded(m, "superseded")
+			continue
+		}
+		if m.Memory != nil && m.ValidTo != nil && !now.Before(*m.ValidTo) {
+			tr.excluded(m, "expired")
+			continue
+		}
+		if m.Memory != nil && m.Type == domain.MemoryTypeEpisode {
+			tr.excluded(m, "episode")
+			continue
+		}
+		if !s.canRead(ctx, m.Memory) {
+			redacted++
+			tr.redacted(m)
+			continue
+		}
+		active = append(active, m)
+	}
+	allMemories = active
+	if redacted > 0 {
+		// The navigator's reasoning may quote redacted memories
+		memoryLogic = ""
+		s.auditRedaction(ctx, "retrieve", redacted)
+	}
 	if s.noiseFilter != nil {
+		if tr != nil {
+			for i, verdict := range s.noiseFilter.Verdicts(allMemories) {
+				if verdict != "" {
+					tr.excluded(allMemories[i], "noise:"+verdict)
+				}
+			}
+		}
 		allMemories = s.noiseFilter.Filter(allMemories)
 	}
 
 	// 6. Scoring and ranking
-	if s.scorer != nil {
-		allMemories = s.scorer.ScoreAll(allMemories)
+	if scorer != nil {
+		for _, m := range allMemories {
+			b := scorer.Explain(m)
+			tr.scored(m, b)
+			m.Score = b.Final
+		}
+		scorer.sortByScore(allMemories)
 	} else {
 		allMemories = s.mergeAndRank(allMemories)
 	}
 
 	// 7. Limit results
-	if len(allMemories) > s.maxMemories {
-		allMemories = allMemories[:s.maxMemories]
+	if len(allMemories) > maxMemories {
+		allMemories = allMemories[:maxMemories]
 	}
+	tr.selected(allMemories)
 
-	// Update access count
-	for _, m := range allMemories {
-		if m.ID != "" && !strings.HasPrefix(m.ID, "ent_") {
-			_ = s.store.IncrementAccess(ctx, m.ID)
+	// Update access count (explanations are diagnostic reads)
+	if tr == nil {
+		for _, m := range allMemories {
+			if m.ID != "" && !strings.HasPrefix(m.ID, "ent_") {
+				_ = s.store.IncrementAccess(ctx, m.ID)
+			}
 		}
 	}
 
-	if len(allMemories) == 0 {
-		return "", nil, memoryLogic, nil
-	}
-
-	return s.formatMemories(allMemories), allMemories, memoryLogic, nil
+	return allMemories, memoryLogic
 }
 
 // StoreIfWorthwhile decides what to store based on task completion.
 // T6b: After storing new facts, checks if ReflectThreshold is reached and triggers async Reflect.
+// In suggest mode (see SetSuggestionPolicy) candidates are queued for review
+// instead of stored; in off mode nothing is extracted. Memories of a
+// signed-in user go to that user's scope rather than the session, which the
+// user does not own under access control; failed writes are returned.
 func (s *Service) StoreIfWorthwhile(ctx context.Context, req *domain.MemoryStoreRequest) error {
+	switch s.suggestionPolicy.Mode {
+	case SuggestionModeOff:
+		return nil
+	case SuggestionModeSuggest:
+		_, err := s.SuggestMemories(ctx, req)
+		return err
+	}
+
+	items := s.extractMemories(ctx, req)
+	bank := principalBank(ctx, req.SessionID)
+	newFactCount := 0
+	var errs []error
+	for _, item := range items {
+		mem := &domain.Memory{
+			ID:         uuid.New().String(),
+			SessionID:  bank,
+			Type:       item.Type,
+			Content:    item.Content,
+			Importance: item.Importance,
+			SourceType: domain.MemorySourceInferred, // stored by agent inference
+			CreatedAt:  time.Now(),
+		}
+		if err := s.Add(ctx, mem); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if item.Type == domain.MemoryTypeFact {
+			newFactCount++
+		}
+	}
+	if len(items) > len(errs) {
+		s.afterExtraction(bank, newFactCount)
+	}
+
+	return errors.Join(errs...)
+}
+
+// extractMemories asks the LLM which parts of a finished task are worth
+// remembering. Extraction errors are swallowed to keep the chat clean.
+func (s *Service) extractMemories(ctx context.Context, req *domain.MemoryStoreRequest) []domain.MemoryItem {
 	if s.llm == nil {
 		return nil
 	}
@@ -277,6 +537,7 @@ func (s *Service) StoreIfWorthwhile(ctx context.Context, req *domain.MemoryStore
 						"type":       map[string]interface{}{"type": "string", "enum": []string{"fact", "skill", "pattern", "context", "preference"}},
 						"content":    map[string]interface{}{"type": "string"},
 						"importance": map[string]interface{}{"type": "number"},
+						"confidence": map[string]interface{}{"type": "number"},
 					},
 					"required": []string{"type", "content", "importance"},
 				},
@@ -304,24 +565,12 @@ func (s *Service) StoreIfWorthwhile(ctx context.Context, req *domain.MemoryStore
 	if !summary.ShouldStore {
 		return nil
 	}
+	return summary.Memories
+}
 
-	newFactCount := 0
-	for _, item := range summary.Memories {
-		mem := &domain.Memory{
-			ID:         uuid.New().String(),
-			SessionID:  req.SessionID,
-			Type:       item.Type,
-			Content:    item.Content,
-			Importance: item.Importance,
-			SourceType: domain.MemorySourceInferred, // stored by agent inference
-			CreatedAt:  time.Now(),
-		}
-		_ = s.Add(ctx, mem)
-		if item.Type == domain.MemoryTypeFact {
-			newFactCount++
-		}
-	}
-
+// afterExtraction runs the bookkeeping that follows storing extracted
+// memories
+func (s *Service) afterExtraction(sessionID string, newFactCount int) {
 	// Invalidate navigator cache since new memories were stored
 	if s.navigator != nil {
 		s.navigator.InvalidateCache()
@@ -329,22 +578,20 @@ func (s *Service) StoreIfWorthwhile(ctx context.Context, req *domain.MemoryStore
 
 	// T6b: Async Reflect trigger — count facts for this session, fire if threshold reached
 	if newFactCount > 0 && s.reflectThreshold > 0 {
-		go s.maybeReflect(req.SessionID)
+		go s.maybeReflect(sessionID)
 	}
-
-	return nil
 }
 
 // maybeReflect counts active facts for sessionID and triggers Reflect if threshold is met.
 // Only runs for FileMemoryStore (which has LLM-driven Reflect); other stores handle this internally.
 // Runs in a goroutine; errors are silently swallowed.
 func (s *Service) maybeReflect(sessionID string) {
-	fileStore, ok := s.store.(*store.FileMemoryStore)
+	fileStore, ok := s.fileStore()
 	if !ok {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := store.WithMemoryChange(domain.WithSystemMemoryPrincipal(context.Background()), "reflect", "consolidate facts into observations")
 	facts, _, err := fileStore.List(ctx, 1000, 0)
 	if err != nil {
 		return
@@ -382,12 +629,54 @@ func (s *Service) Add(ctx context.Context, memory *domain.Memory) error {
 		memory.Vector = vec
 	}
 
+	// Check against close existing memories before writing
+	decision, existing := s.checkConflict(ctx, memory)
+	if decision != nil {
+		switch decision.Outcome {
+		case ConflictOutcomeDuplicate:
+			if err := s.mergeDuplicate(ctx, existing, memory, "agent"); err != nil {
+				return err
+			}
+			// Nothing new was stored; point the caller at the memory that was
+			memory.ID = existing.ID
+			return nil
+		case ConflictOutcomeUpdate:
+			if memory.ValidFrom.IsZero() {
+				memory.ValidFrom = time.Now()
+			}
+			memory.RevisionHistory = append(memory.RevisionHistory, domain.MemoryRevision{
+				At: time.Now(), By: "agent", Summary: fmt.Sprintf("supersedes %s", existing.ID),
+			})
+		case ConflictOutcomeContradiction:
+			summary := fmt.Sprintf("contradicts %s", existing.ID)
+			if decision.Reason != "" {
+				summary += ": " + decision.Reason
+			}
+			flagConflict(memory, existing.ID, decision.Reason, summary, time.Now())
+		}
+	}
+
 	// 1. Write to Primary Store (The Truth)
 	err := s.store.Store(ctx, memory)
 	if err != nil {
 		return err
 	}
 
+	// Only touch the existing memory once the new one is safely stored
+	if decision != nil {
+		switch decision.Outcome {
+		case ConflictOutcomeUpdate:
+			if err := s.supersede(ctx, existing, memory.ID, "agent", decision.Reason); err != nil {
+				return err
+			}
+		case ConflictOutcomeContradiction:
+			flagConflict(existing, memory.ID, decision.Reason, fmt.Sprintf("contradicted by %s", memory.ID), time.Now())
+			if err := s.store.Update(ctx, existing); err != nil {
+				return err
+			}
+		}
+	}
+
 	// 2. Write to Shadow Index (The Accelerator)
 	if s.shadowIndex != nil {
 		// Ensure it has vector before indexing
@@ -396,6 +685,11 @@ func (s *Service) Add(ctx context.Context, memory *domain.Memory) error {
 		}
 	}
 
+	// 3. Link into the entity graph (best effort)
+	if s.entityGraph != nil && s.llm != nil && graphIndexable(memory) {
+		_ = s.entityGraph.Index(ctx, memory)
+	}
+
 	return nil
 }
 
@@ -469,6 +763,73 @@ func (s *Service) Search(ctx context.Context, query string, topK int) ([]*domain
 	return finalResults, nil
 }
 
+// SearchTemporal returns memories as they were believed at query.AsOf, or all
+// versions valid at some point during [query.From, query.To]. Superseded
+// memories are included when they were valid at the requested time.
+func (s *Service) SearchTemporal(ctx context.Context, query *domain.MemoryTemporalQuery) ([]*domain.MemoryWithScore, error) {
+	temporal, err := s.temporalStore()
+	if err != nil {
+		return nil, err
+	}
+	if query == nil {
+		query = &domain.MemoryTemporalQuery{}
+	}
+	return temporal.SearchTemporal(ctx, query)
+}
+
+// SearchAsOf searches memories as they stood at a point in time
+func (s *Service) SearchAsOf(ctx context.Context, query string, asOf time.Time, topK int) ([]*domain.MemoryWithScore, error) {
+	temporal, err := s.temporalStore()
+	if err != nil {
+		return nil, err
+	}
+	if topK <= 0 {
+		topK = 10
+	}
+	return temporal.SearchTemporal(ctx, &domain.MemoryTemporalQuery{Text: query, AsOf: asOf, TopK: topK})
+}
+
+// RetrieveAsOf is the point-in-time counterpart of RetrieveAndInject: it
+// returns the memories the agent would have had available at asOf, formatted
+// for LLM context. Access counts are left untouched since this is an audit read.
+func (s *Service) RetrieveAsOf(ctx context.Context, query string, sessionID string, asOf time.Time) (string, []*domain.MemoryWithScore, error) {
+	temporal, err := s.temporalStore()
+	if err != nil {
+		return "", nil, err
+	}
+	q := &domain.MemoryTemporalQuery{Text: query, SessionID: sessionID, AsOf: asOf, TopK: s.maxMemories}
+	mems, err := temporal.SearchTemporal(ctx, q)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(mems) == 0 && query != "" {
+		// Nothing matched the text; fall back to everything valid at that time
+		q.Text = ""
+		if mems, err = temporal.SearchTemporal(ctx, q); err != nil {
+			return "", nil, err
+		}
+	}
+	return s.formatMemories(mems), mems, nil
+}
+
+// History returns every version of a memory, oldest first
+func (s *Service) History(ctx context.Context, id string) ([]*domain.Memory, error) {
+	temporal, err := s.temporalStore()
+	if err != nil {
+		return nil, err
+	}
+	return temporal.History(ctx, id)
+}
+
+// temporalStore returns the store when it answers temporal queries
+func (s *Service) temporalStore() (domain.TemporalMemoryStore, error) {
+	temporal, ok := s.store.(domain.TemporalMemoryStore)
+	if !ok {
+		return nil, store.ErrTemporalUnsupported
+	}
+	return temporal, nil
+}
+
 func (s *Service) Get(ctx context.Context, id string) (*domain.Memory, error) {
 	return s.store.Get(ctx, id)
 }
@@ -478,7 +839,46 @@ func (s *Service) List(ctx context.Context, limit, offset int) ([]*domain.Memory
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
-	return s.store.Delete(ctx, id)
+	if err := s.store.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.forgetInGraph(ctx, id)
+	return nil
+}
+
+// Reindex brings the shadow index and entity graph in line with the store for
+// memories changed behind the service's back, e.g. by a git revert of the
+// memory files. Memories no longer in the store are dropped from both.
+func (s *Service) Reindex(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		mem, err := s.store.Get(ctx, id)
+		if errors.Is(err, store.ErrMemoryAccessDenied) {
+			return err
+		}
+		if err != nil {
+			s.retire(ctx, id)
+			continue
+		}
+
+		if s.shadowIndex != nil && s.embedder != nil {
+			if len(mem.Vector) == 0 {
+				if mem.Vector, err = s.embedder.Embed(ctx, mem.Content); err != nil {
+					return fmt.Errorf("failed to embed memory %s: %w", id, err)
+				}
+			}
+			_ = s.shadowIndex.Delete(ctx, id)
+			if err := s.shadowIndex.Store(ctx, mem); err != nil {
+				return fmt.Errorf("failed to index memory %s: %w", id, err)
+			}
+		}
+
+		s.forgetInGraph(ctx, id)
+		if s.entityGraph != nil && s.llm != nil && graphIndexable(mem) &&
+			mem.ValidTo == nil && mem.SupersededBy == "" && !store.IsArchived(mem) {
+			_ = s.entityGraph.Index(ctx, mem)
+		}
+	}
+	return nil
 }
 
 func (s *Service) ConfigureBank(ctx context.Context, sessionID string, config *domain.MemoryBankConfig) error {
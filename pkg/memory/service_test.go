@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockMemoryStore is a mock implementation of domain.MemoryStore
@@ -57,6 +59,22 @@ func (m *MockMemoryStore) SearchByText(ctx context.Context, query string, topK i
 	return args.Get(0).([]*domain.MemoryWithScore), args.Error(1)
 }
 
+func (m *MockMemoryStore) SearchTemporal(ctx context.Context, query *domain.MemoryTemporalQuery) ([]*domain.MemoryWithScore, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MemoryWithScore), args.Error(1)
+}
+
+func (m *MockMemoryStore) History(ctx context.Context, id string) ([]*domain.Memory, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Memory), args.Error(1)
+}
+
 func (m *MockMemoryStore) Get(ctx context.Context, id string) (*domain.Memory, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -280,6 +298,7 @@ func TestService_Add(t *testing.T) {
 		vector := []float64{0.5, 0.6, 0.7}
 
 		embedder.On("Embed", ctx, memory.Content).Return(vector, nil)
+		store.On("Search", ctx, vector, conflictCandidates, mock.Anything).Return([]*domain.MemoryWithScore{}, nil).Maybe()
 		store.On("Store", ctx, mock.MatchedBy(func(m *domain.Memory) bool {
 			return m.Content == memory.Content && len(m.Vector) > 0
 		})).Return(nil)
@@ -326,6 +345,7 @@ func TestService_StoreIfWorthwhile(t *testing.T) {
 
 		// Add() will be called internally, which calls Embed and Store
 		embedder.On("Embed", ctx, "Project status updated to 60%.").Return([]float64{0.1, 0.2, 0.3}, nil)
+		store.On("Search", ctx, []float64{0.1, 0.2, 0.3}, conflictCandidates, mock.Anything).Return([]*domain.MemoryWithScore{}, nil).Maybe()
 		store.On("Store", ctx, mock.MatchedBy(func(m *domain.Memory) bool {
 			return m.Content == "Project status updated to 60%."
 		})).Return(nil)
@@ -375,3 +395,28 @@ func TestService_StoreIfWorthwhile(t *testing.T) {
 		store.AssertNotCalled(t, "Store")
 	})
 }
+
+func TestService_Reindex(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := store.NewFileMemoryStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, fileStore.Store(ctx, &domain.Memory{
+		ID: "kept", Type: domain.MemoryTypeFact, Content: "User prefers tabs", Importance: 0.8, CreatedAt: time.Now(),
+	}))
+
+	embedder := new(MockEmbedder)
+	embedder.On("Embed", ctx, "User prefers tabs").Return([]float64{1, 0}, nil)
+	shadow := new(MockMemoryStore)
+	shadow.On("Delete", ctx, "kept").Return(nil)
+	shadow.On("Delete", ctx, "gone").Return(nil)
+	shadow.On("Store", ctx, mock.MatchedBy(func(m *domain.Memory) bool {
+		return m.ID == "kept" && len(m.Vector) == 2
+	})).Return(nil)
+
+	svc := NewService(fileStore, nil, embedder, nil)
+	svc.SetShadowIndex(shadow)
+
+	require.NoError(t, svc.Reindex(ctx, "kept", "gone"))
+	shadow.AssertExpectations(t)
+	embedder.AssertExpectations(t)
+}
@@ -0,0 +1,271 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// SuggestionMode controls what happens to memories extracted after a task
+type SuggestionMode string
+
+const (
+	SuggestionModeAuto    SuggestionMode = "auto"    // store extracted memories silently (default)
+	SuggestionModeSuggest SuggestionMode = "suggest" // queue them for the user to accept or reject
+	SuggestionModeOff     SuggestionMode = "off"     // extract nothing
+)
+
+// SuggestionPolicy configures suggest mode
+type SuggestionPolicy struct {
+	Mode SuggestionMode
+	// Timeout after which a pending suggestion is decided by OnTimeout
+	// (0 = wait for the user indefinitely)
+	Timeout time.Duration
+	// OnTimeout is "accept" or "reject" (default)
+	OnTimeout string
+	// AutoAcceptConfidence stores suggestions at or above this confidence
+	// straight away (0 disables)
+	AutoAcceptConfidence float64
+}
+
+// SetSuggestionPolicy sets how extracted memories are handled. st holds
+// suggestions awaiting review; it is required for suggest mode.
+func (s *Service) SetSuggestionPolicy(policy SuggestionPolicy, st domain.MemorySuggestionStore) error {
+	switch policy.Mode {
+	case "":
+		policy.Mode = SuggestionModeAuto
+	case SuggestionModeAuto, SuggestionModeSuggest, SuggestionModeOff:
+	default:
+		return fmt.Errorf("unknown memory suggestion mode %q (use auto, suggest or off)", policy.Mode)
+	}
+	switch policy.OnTimeout {
+	case "":
+		policy.OnTimeout = "reject"
+	case "accept", "reject":
+	default:
+		return fmt.Errorf("unknown suggestion timeout action %q (use accept or reject)", policy.OnTimeout)
+	}
+	if policy.Mode == SuggestionModeSuggest && st == nil {
+		return fmt.Errorf("suggest mode requires a suggestion store")
+	}
+	s.suggestionPolicy = policy
+	s.suggestions = st
+	return nil
+}
+
+// SuggestsMemories reports whether extracted memories wait for review
+func (s *Service) SuggestsMemories() bool {
+	return s.suggestionPolicy.Mode == SuggestionModeSuggest && s.suggestions != nil
+}
+
+// SuggestMemories extracts candidate memories from a finished task and
+// queues them for review. Candidates at or above AutoAcceptConfidence are
+// stored immediately and returned already accepted.
+func (s *Service) SuggestMemories(ctx context.Context, req *domain.MemoryStoreRequest) ([]*domain.MemorySuggestion, error) {
+	if s.suggestions == nil {
+		return nil, fmt.Errorf("memory suggestions are not enabled")
+	}
+
+	items := s.extractMemories(ctx, req)
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	scope := ScopeString(domain.MemoryScopeFromBankID(req.SessionID))
+	var out []*domain.MemorySuggestion
+	for _, item := range items {
+		confidence := item.Confidence
+		if confidence <= 0 {
+			// Older prompts and models may omit confidence
+			confidence = item.Importance
+		}
+		sg := &domain.MemorySuggestion{
+			ID:         uuid.New().String(),
+			SessionID:  req.SessionID,
+			Type:       item.Type,
+			Content:    item.Content,
+			Importance: item.Importance,
+			Confidence: confidence,
+			Scope:      scope,
+			Status:     domain.MemorySuggestionPending,
+			CreatedAt:  now,
+		}
+		if s.suggestionPolicy.Timeout > 0 {
+			expires := now.Add(s.suggestionPolicy.Timeout)
+			sg.ExpiresAt = &expires
+		}
+		if err := s.suggestions.SaveSuggestion(ctx, sg); err != nil {
+			return out, err
+		}
+
+		if t := s.suggestionPolicy.AutoAcceptConfidence; t > 0 && confidence >= t {
+			if _, err := s.decideSuggestion(ctx, sg, true, "policy"); err != nil {
+				return out, err
+			}
+		}
+		out = append(out, sg)
+	}
+
+	if s.suggestionPolicy.Timeout > 0 {
+		time.AfterFunc(s.suggestionPolicy.Timeout, func() {
+			_, _ = s.ExpireSuggestions(domain.WithSystemMemoryPrincipal(context.Background()))
+		})
+	}
+	return out, nil
+}
+
+// PendingSuggestions lists suggestions awaiting review, oldest first, after
+// applying the timeout policy. sessionID filters to one session ("" = all).
+// Only suggestions for scopes the principal on ctx may read are listed.
+func (s *Service) PendingSuggestions(ctx context.Context, sessionID string) ([]*domain.MemorySuggestion, error) {
+	if s.suggestions == nil {
+		return nil, nil
+	}
+	// The timeout policy acts for the system, whoever happens to be asking
+	if _, err := s.ExpireSuggestions(domain.WithSystemMemoryPrincipal(ctx)); err != nil {
+		return nil, err
+	}
+	all, err := s.suggestions.ListSuggestions(ctx, domain.MemorySuggestionPending)
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.MemorySuggestion
+	for _, sg := range all {
+		if (sessionID == "" || sg.SessionID == sessionID) && s.suggestionAllowed(ctx, sg, domain.MemoryAccessRead) {
+			out = append(out, sg)
+		}
+	}
+	return out, nil
+}
+
+// AcceptSuggestion stores a pending suggestion as a memory
+func (s *Service) AcceptSuggestion(ctx context.Context, id, by string) (*domain.Memory, error) {
+	sg, err := s.pendingSuggestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.decideSuggestion(ctx, sg, true, by)
+}
+
+// RejectSuggestion discards a pending suggestion
+func (s *Service) RejectSuggestion(ctx context.Context, id, by string) error {
+	sg, err := s.pendingSuggestion(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.decideSuggestion(ctx, sg, false, by)
+	return err
+}
+
+// ExpireSuggestions applies the timeout policy to pending suggestions whose
+// deadline has passed and returns how many were decided
+func (s *Service) ExpireSuggestions(ctx context.Context) (int, error) {
+	if s.suggestions == nil {
+		return 0, nil
+	}
+	pending, err := s.suggestions.ListSuggestions(ctx, domain.MemorySuggestionPending)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	accept := s.suggestionPolicy.OnTimeout == "accept"
+	decided := 0
+	for _, sg := range pending {
+		if sg.ExpiresAt == nil || now.Before(*sg.ExpiresAt) {
+			continue
+		}
+		if _, err := s.decideSuggestion(ctx, sg, accept, "timeout"); err != nil {
+			return decided, err
+		}
+		decided++
+	}
+	return decided, nil
+}
+
+func (s *Service) pendingSuggestion(ctx context.Context, id string) (*domain.MemorySuggestion, error) {
+	if s.suggestions == nil {
+		return nil, fmt.Errorf("memory suggestions are not enabled")
+	}
+	sg, err := s.suggestions.GetSuggestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.suggestionAllowed(ctx, sg, domain.MemoryAccessWrite) {
+		// Like memories, suggestions the caller may not touch do not exist
+		return nil, fmt.Errorf("%w: %s", store.ErrSuggestionNotFound, id)
+	}
+	if sg.Status != domain.MemorySuggestionPending {
+		return nil, fmt.Errorf("suggestion %s was already %s", id, sg.Status)
+	}
+	return sg, nil
+}
+
+// suggestionAllowed applies the access policy to the scope the suggested
+// memory would be stored in
+func (s *Service) suggestionAllowed(ctx context.Context, sg *domain.MemorySuggestion, mode domain.MemoryAccessMode) bool {
+	if s.accessPolicy == nil {
+		return true
+	}
+	return s.accessPolicy.Allow(domain.MemoryPrincipalFromContext(ctx), domain.MemoryScopeFromBankID(sg.SessionID), mode)
+}
+
+// decideSuggestion records the decision and, on accept, stores the memory.
+// The decision is claimed in the store first, so a user and the timeout
+// deciding at the same moment cannot both act on the suggestion.
+func (s *Service) decideSuggestion(ctx context.Context, sg *domain.MemorySuggestion, accept bool, by string) (*domain.Memory, error) {
+	decided := *sg
+	now := time.Now()
+	decided.DecidedAt = &now
+	decided.DecidedBy = by
+	decided.Status = domain.MemorySuggestionRejected
+
+	var mem *domain.Memory
+	if accept {
+		mem = &domain.Memory{
+			ID:         uuid.New().String(),
+			SessionID:  sg.SessionID,
+			Type:       sg.Type,
+			Content:    sg.Content,
+			Importance: sg.Importance,
+			Confidence: sg.Confidence,
+			SourceType: domain.MemorySourceInferred,
+			CreatedAt:  now,
+			Metadata:   map[string]interface{}{"suggestion_id": sg.ID},
+		}
+		decided.Status = domain.MemorySuggestionAccepted
+		decided.MemoryID = mem.ID
+	}
+	if err := s.suggestions.DecideSuggestion(ctx, &decided); err != nil {
+		return nil, err
+	}
+	if !accept {
+		*sg = decided
+		return nil, nil
+	}
+
+	if err := s.Add(store.WithMemoryChange(ctx, by, "accepted suggestion "+sg.ID), mem); err != nil {
+		// Put it back up for review
+		_ = s.suggestions.SaveSuggestion(ctx, sg)
+		return nil, err
+	}
+	newFacts := 0
+	if sg.Type == domain.MemoryTypeFact {
+		newFacts = 1
+	}
+	s.afterExtraction(sg.SessionID, newFacts)
+	*sg = decided
+	if mem.ID != sg.MemoryID {
+		// Merged into an existing memory
+		sg.MemoryID = mem.ID
+		if err := s.suggestions.SaveSuggestion(ctx, sg); err != nil {
+			return mem, err
+		}
+	}
+	return mem, nil
+}
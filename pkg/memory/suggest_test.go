@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const suggestionExtraction = `{"should_store": true, "memories": [
+	{"type": "fact", "content": "The staging database runs Postgres 16", "importance": 0.6, "confidence": 0.95},
+	{"type": "preference", "content": "User likes terse commit messages", "importance": 0.7, "confidence": 0.4}
+]}`
+
+func newSuggestTestService(t *testing.T, policy SuggestionPolicy) (*Service, *store.FileMemoryStore) {
+	t.Helper()
+	dir := t.TempDir()
+	fileStore, err := store.NewFileMemoryStore(filepath.Join(dir, "memories"))
+	require.NoError(t, err)
+	suggestions, err := store.NewFileSuggestionStore(filepath.Join(dir, "suggestions.json"))
+	require.NoError(t, err)
+
+	llm := new(MockGenerator)
+	llm.On("GenerateStructured", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&domain.StructuredResult{Valid: true, Raw: suggestionExtraction}, nil)
+
+	cfg := DefaultConfig()
+	cfg.ClassifierConfig = &ClassifierConfig{Enabled: false}
+	svc := NewService(fileStore, llm, nil, cfg)
+	require.NoError(t, svc.SetSuggestionPolicy(policy, suggestions))
+	return svc, fileStore
+}
+
+func suggestRequest() *domain.MemoryStoreRequest {
+	return &domain.MemoryStoreRequest{
+		SessionID:  "session-1",
+		TaskGoal:   "Set up the staging database",
+		TaskResult: "Provisioned Postgres 16 on staging",
+	}
+}
+
+func TestSuggestMemories_QueuesInsteadOfStoring(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeSuggest})
+	assert.True(t, svc.SuggestsMemories())
+
+	require.NoError(t, svc.StoreIfWorthwhile(ctx, suggestRequest()))
+
+	stored, _, err := fileStore.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, stored, "suggest mode must not store before review")
+
+	pending, err := svc.PendingSuggestions(ctx, "session-1")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	byType := make(map[domain.MemoryType]*domain.MemorySuggestion)
+	for _, sg := range pending {
+		byType[sg.Type] = sg
+	}
+	require.Contains(t, byType, domain.MemoryTypeFact)
+	assert.Equal(t, 0.95, byType[domain.MemoryTypeFact].Confidence)
+	assert.Equal(t, 0.4, byType[domain.MemoryTypePreference].Confidence)
+	assert.NotEmpty(t, byType[domain.MemoryTypeFact].Scope)
+
+	none, err := svc.PendingSuggestions(ctx, "other-session")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestSuggestMemories_AcceptAndReject(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeSuggest})
+
+	suggestions, err := svc.SuggestMemories(ctx, suggestRequest())
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+
+	mem, err := svc.AcceptSuggestion(ctx, suggestions[0].ID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, suggestions[0].Content, mem.Content)
+	assert.Equal(t, suggestions[0].ID, mem.Metadata["suggestion_id"])
+
+	got, err := fileStore.Get(ctx, mem.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "The staging database runs Postgres 16", got.Content)
+
+	require.NoError(t, svc.RejectSuggestion(ctx, suggestions[1].ID, "alice"))
+
+	pending, err := svc.PendingSuggestions(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = svc.AcceptSuggestion(ctx, suggestions[1].ID, "alice")
+	assert.Error(t, err, "a rejected suggestion cannot be accepted later")
+
+	stored, _, err := fileStore.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func TestSuggestMemories_AutoAcceptConfidence(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeSuggest, AutoAcceptConfidence: 0.9})
+
+	suggestions, err := svc.SuggestMemories(ctx, suggestRequest())
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+	assert.Equal(t, domain.MemorySuggestionAccepted, suggestions[0].Status)
+	assert.Equal(t, "policy", suggestions[0].DecidedBy)
+	assert.NotEmpty(t, suggestions[0].MemoryID)
+	assert.Equal(t, domain.MemorySuggestionPending, suggestions[1].Status)
+
+	stored, _, err := fileStore.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func TestExpireSuggestions_AppliesTimeoutPolicy(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeSuggest, Timeout: time.Hour, OnTimeout: "accept"})
+
+	suggestions, err := svc.SuggestMemories(ctx, suggestRequest())
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+
+	n, err := svc.ExpireSuggestions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "nothing has expired yet")
+
+	// Backdate one suggestion past its deadline
+	sg, err := svc.suggestions.GetSuggestion(ctx, suggestions[1].ID)
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	sg.ExpiresAt = &past
+	require.NoError(t, svc.suggestions.SaveSuggestion(ctx, sg))
+
+	pending, err := svc.PendingSuggestions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, suggestions[0].ID, pending[0].ID)
+
+	decided, err := svc.suggestions.GetSuggestion(ctx, suggestions[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MemorySuggestionAccepted, decided.Status)
+	assert.Equal(t, "timeout", decided.DecidedBy)
+
+	stored, _, err := fileStore.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func TestSuggestions_DecidedOnce(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeSuggest})
+
+	suggestions, err := svc.SuggestMemories(ctx, suggestRequest())
+	require.NoError(t, err)
+	sg := suggestions[0]
+
+	// A user accepting while the timeout fires: one decision wins
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	wins := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(accept bool) {
+			defer wg.Done()
+			cp := *sg
+			if _, err := svc.decideSuggestion(ctx, &cp, accept, "racer"); err == nil {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			} else {
+				assert.ErrorIs(t, err, store.ErrSuggestionDecided)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, wins)
+
+	decided, err := svc.suggestions.GetSuggestion(ctx, sg.ID)
+	require.NoError(t, err)
+	stored, _, err := fileStore.List(ctx, 10, 0)
+	require.NoError(t, err)
+	if decided.Status == domain.MemorySuggestionAccepted {
+		require.Len(t, stored, 1)
+		assert.Equal(t, decided.MemoryID, stored[0].ID)
+	} else {
+		assert.Empty(t, stored)
+	}
+}
+
+func TestSuggestions_ScopedToPrincipal(t *testing.T) {
+	system := domain.WithSystemMemoryPrincipal(context.Background())
+	svc, _ := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeSuggest})
+	svc.SetAccessControl(nil, nil)
+
+	req := suggestRequest()
+	req.SessionID = "user:alice"
+	suggestions, err := svc.SuggestMemories(system, req)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+
+	bob := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "bob"})
+	pending, err := svc.PendingSuggestions(bob, "")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	_, err = svc.AcceptSuggestion(bob, suggestions[0].ID, "bob")
+	assert.ErrorIs(t, err, store.ErrSuggestionNotFound)
+	assert.ErrorIs(t, svc.RejectSuggestion(bob, suggestions[1].ID, "bob"), store.ErrSuggestionNotFound)
+
+	alice := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "alice"})
+	pending, err = svc.PendingSuggestions(alice, "")
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+	mem, err := svc.AcceptSuggestion(alice, suggestions[0].ID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", mem.SessionID)
+}
+
+func TestSetSuggestionPolicy_Validates(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil)
+	assert.Error(t, svc.SetSuggestionPolicy(SuggestionPolicy{Mode: "sometimes"}, nil))
+	assert.Error(t, svc.SetSuggestionPolicy(SuggestionPolicy{Mode: SuggestionModeSuggest}, nil))
+	assert.Error(t, svc.SetSuggestionPolicy(SuggestionPolicy{OnTimeout: "ignore"}, nil))
+	assert.NoError(t, svc.SetSuggestionPolicy(SuggestionPolicy{}, nil))
+	assert.False(t, svc.SuggestsMemories())
+}
+
+func TestStoreIfWorthwhile_OffMode(t *testing.T) {
+	ctx := context.Background()
+	svc, fileStore := newSuggestTestService(t, SuggestionPolicy{Mode: SuggestionModeOff})
+
+	require.NoError(t, svc.StoreIfWorthwhile(ctx, suggestRequest()))
+
+	stored, _, err := fileStore.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+	pending, err := svc.PendingSuggestions(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,96 @@
+package pool
+
+import (
+	"context"
+
+	"github.com/liliang-cn/agent-go/pkg/cassette"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// SetCassette routes the client's generation calls through a cassette
+// recorder or replayer. A nil cassette or disabled mode removes it.
+func (c *Client) SetCassette(tape *cassette.Cassette, mode cassette.Mode, match cassette.MatchMode) {
+	switch {
+	case tape == nil:
+		c.tape = nil
+	case mode == cassette.ModeRecord:
+		c.tape = cassette.NewRecorder(rawClient{c}, tape)
+	case mode == cassette.ModeReplay:
+		c.tape = cassette.NewReplayer(tape, match)
+	default:
+		c.tape = nil
+	}
+}
+
+// Generate generates text
+func (c *Client) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	if c.tape != nil {
+		return c.tape.Generate(ctx, prompt, opts)
+	}
+	return c.generate(ctx, prompt, opts)
+}
+
+// Stream 流式生成
+func (c *Client) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	if c.tape != nil {
+		return c.tape.Stream(ctx, prompt, opts, callback)
+	}
+	return c.stream(ctx, prompt, opts, callback)
+}
+
+// GenerateWithTools 使用工具生成
+func (c *Client) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	if c.tape != nil {
+		return c.tape.GenerateWithTools(ctx, messages, tools, opts)
+	}
+	return c.generateWithTools(ctx, messages, tools, opts)
+}
+
+// StreamWithTools 流式工具调用
+func (c *Client) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	if c.tape != nil {
+		return c.tape.StreamWithTools(ctx, messages, tools, opts, callback)
+	}
+	return c.streamWithTools(ctx, messages, tools, opts, callback)
+}
+
+// GenerateStructured 结构化生成
+func (c *Client) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	if c.tape != nil {
+		return c.tape.GenerateStructured(ctx, prompt, schema, opts)
+	}
+	return c.generateStructured(ctx, prompt, schema, opts)
+}
+
+// rawClient exposes the client's network calls to a cassette recorder
+// without going back through the taped methods
+type rawClient struct {
+	c *Client
+}
+
+func (r rawClient) GetModelName() string { return r.c.modelName }
+func (r rawClient) GetBaseURL() string   { return r.c.baseURL }
+
+func (r rawClient) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+	return r.c.generate(ctx, prompt, opts)
+}
+
+func (r rawClient) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+	return r.c.stream(ctx, prompt, opts, callback)
+}
+
+func (r rawClient) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+	return r.c.generateWithTools(ctx, messages, tools, opts)
+}
+
+func (r rawClient) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+	return r.c.streamWithTools(ctx, messages, tools, opts, callback)
+}
+
+func (r rawClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+	return r.c.generateStructured(ctx, prompt, schema, opts)
+}
+
+func (r rawClient) RecognizeIntent(ctx context.Context, request string) (*domain.IntentResult, error) {
+	return r.c.RecognizeIntent(ctx, request)
+}
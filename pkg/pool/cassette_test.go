@@ -0,0 +1,71 @@
+package pool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/cassette"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+func TestPoolCassetteRecordThenReplayOffline(t *testing.T) {
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"recorded answer"}}]}`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "pool.cassette.json")
+	providers := []Provider{{Name: "primary", BaseURL: server.URL, ModelName: "gpt-4o-mini"}}
+	messages := []domain.Message{{Role: "user", Content: "hello"}}
+
+	recorder, err := NewPool(PoolConfig{
+		Enabled:   true,
+		Providers: providers,
+		Cassette:  cassette.Config{Mode: cassette.ModeRecord, Path: path},
+	})
+	if err != nil {
+		t.Fatalf("NewPool(record): %v", err)
+	}
+	client, err := recorder.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, err := client.GenerateWithTools(context.Background(), messages, nil, nil); err != nil {
+		t.Fatalf("GenerateWithTools(record): %v", err)
+	}
+	recorder.Release(client)
+	server.Close()
+
+	if got := requestCount.Load(); got != 1 {
+		t.Fatalf("expected 1 live request while recording, got %d", got)
+	}
+
+	replayer, err := NewPool(PoolConfig{
+		Enabled:   true,
+		Providers: providers,
+		Cassette:  cassette.Config{Mode: cassette.ModeReplay, Path: path},
+	})
+	if err != nil {
+		t.Fatalf("NewPool(replay): %v", err)
+	}
+	client, err = replayer.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	result, err := client.GenerateWithTools(context.Background(), messages, nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateWithTools(replay): %v", err)
+	}
+	if result.Content != "recorded answer" {
+		t.Fatalf("unexpected replayed content: %q", result.Content)
+	}
+	if got := requestCount.Load(); got != 1 {
+		t.Fatalf("replay must not reach the server, got %d requests", got)
+	}
+}
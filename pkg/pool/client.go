@@ -21,6 +21,8 @@ type Client struct {
 	modelName     string
 	http          *http.Client
 	promptManager *prompt.Manager
+	// tape records or replays generation calls when a cassette is configured
+	tape domain.Generator
 }
 
 // NewClient 创建新client
@@ -57,8 +59,8 @@ func (c *Client) GetBaseURL() string {
 	return c.baseURL
 }
 
-// Generate generates text
-func (c *Client) Generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
+// generate generates text
+func (c *Client) generate(ctx context.Context, prompt string, opts *domain.GenerationOptions) (string, error) {
 	if opts == nil {
 		opts = &domain.GenerationOptions{}
 	}
@@ -104,8 +106,8 @@ func (c *Client) Generate(ctx context.Context, prompt string, opts *domain.Gener
 	return result.Choices[0].Message.Content, nil
 }
 
-// Stream 流式生成
-func (c *Client) Stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
+// stream 流式生成
+func (c *Client) stream(ctx context.Context, prompt string, opts *domain.GenerationOptions, callback func(string)) error {
 	if opts == nil {
 		opts = &domain.GenerationOptions{}
 	}
@@ -276,8 +278,8 @@ func shouldRetryPoolWithoutNativeWebSearch(opts *domain.GenerationOptions, err e
 		strings.Contains(msg, "unknown field")
 }
 
-// GenerateWithTools 使用工具生成
-func (c *Client) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
+// generateWithTools 使用工具生成
+func (c *Client) generateWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions) (*domain.GenerationResult, error) {
 	if opts == nil {
 		opts = &domain.GenerationOptions{}
 	}
@@ -365,10 +367,10 @@ func (c *Client) GenerateWithTools(ctx context.Context, messages []domain.Messag
 	return response, nil
 }
 
-// StreamWithTools 流式工具调用
-func (c *Client) StreamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
+// streamWithTools 流式工具调用
+func (c *Client) streamWithTools(ctx context.Context, messages []domain.Message, tools []domain.ToolDefinition, opts *domain.GenerationOptions, callback domain.ToolCallCallback) error {
 	// 简化实现：先获取完整结果再流式回调
-	result, err := c.GenerateWithTools(ctx, messages, tools, opts)
+	result, err := c.generateWithTools(ctx, messages, tools, opts)
 	if err != nil {
 		return err
 	}
@@ -376,8 +378,8 @@ func (c *Client) StreamWithTools(ctx context.Context, messages []domain.Message,
 	return callback(result)
 }
 
-// GenerateStructured 结构化生成
-func (c *Client) GenerateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
+// generateStructured 结构化生成
+func (c *Client) generateStructured(ctx context.Context, prompt string, schema interface{}, opts *domain.GenerationOptions) (*domain.StructuredResult, error) {
 	if opts == nil {
 		opts = &domain.GenerationOptions{}
 	}
@@ -643,7 +645,7 @@ func (c *Client) embedSingle(ctx context.Context, text string) ([]float64, error
 func (c *Client) Health(ctx context.Context) error {
 	// Check if this is an embedding model by trying a simple Generate first
 	// If Generate fails, try embedding
-	_, err := c.Generate(ctx, "hi", &domain.GenerationOptions{MaxTokens: 1})
+	_, err := c.generate(ctx, "hi", &domain.GenerationOptions{MaxTokens: 1})
 	if err != nil {
 		// If Generate fails, this might be an embedding-only model
 		// Try embedding as fallback
@@ -10,6 +10,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/liliang-cn/agent-go/pkg/cassette"
 	"github.com/liliang-cn/agent-go/pkg/domain"
 	"github.com/liliang-cn/agent-go/pkg/prompt"
 )
@@ -46,6 +47,8 @@ type PoolConfig struct {
 	Enabled   bool              `mapstructure:"enabled"`
 	Strategy  SelectionStrategy `mapstructure:"strategy"`
 	Providers []Provider        `mapstructure:"providers"`
+	// Cassette records or replays every client's generation calls
+	Cassette cassette.Config `mapstructure:"cassette"`
 }
 
 // clientWrapper 包装client及其状态
@@ -92,12 +95,24 @@ func NewPool(config PoolConfig) (*Pool, error) {
 		pool.strategy = StrategyRoundRobin
 	}
 
+	// 所有clients共享同一个cassette
+	var tape *cassette.Cassette
+	if config.Cassette.Enabled() {
+		var err error
+		if tape, err = cassette.Open(config.Cassette); err != nil {
+			return nil, fmt.Errorf("failed to open cassette: %w", err)
+		}
+	}
+
 	// 初始化clients
 	for _, p := range config.Providers {
 		client, err := NewClient(p.BaseURL, p.Key, p.ModelName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create client %s: %w", p.Name, err)
 		}
+		if tape != nil {
+			client.SetCassette(tape, config.Cassette.Mode, config.Cassette.Match)
+		}
 
 		pool.clients[p.Name] = &clientWrapper{
 			client:          client,
@@ -8,6 +8,7 @@ const (
 	AgentVerification         = "agent.verification"
 	AgentSystemPrompt         = "agent.system_prompt"
 	AgentRevisePlan           = "agent.revise_plan"
+	AgentReplanSteps          = "agent.replan_steps"
 	MemoryExtraction          = "memory.extraction"
 	MemoryReflection          = "memory.reflection"
 	LLMCompact                = "llm.compact"
@@ -16,6 +17,7 @@ const (
 	RAGGraphExtraction        = "rag.graph_extraction"
 	RAGSystemPrompt           = "rag.system_prompt"
 	AgentDynamicToolSelection = "agent.dynamic_tool_selection"
+	EvalJudge                 = "eval.judge"
 )
 
 func (m *Manager) loadDefaults() {
@@ -124,6 +126,32 @@ Generate a revised plan based on the user's instruction. Return JSON with:
 - steps: array of steps, each with tool, description, arguments
 Keep the same step structure. Only include steps that need to be done.`
 
+	// 6b. Agent Replan Steps (adaptive execution after a step failure)
+	m.defaults[AgentReplanSteps] = `You are repairing an execution plan after one of its steps failed.
+
+Goal: {{.Goal}}
+
+{{.ToolDescriptions}}
+{{if .CompletedSteps}}
+=== Completed Steps ===
+{{range $i, $step := .CompletedSteps}}  {{add $i 1}}. [{{$step.Tool}}] {{$step.Description}}
+     Result: {{$step.Result}}
+{{end}}{{end}}
+=== Failed Step ===
+[{{.FailedStep.Tool}}] {{.FailedStep.Description}}
+Arguments: {{.FailedStep.Arguments}}
+Failure: {{.Failure}}
+{{if .RemainingSteps}}
+=== Remaining Steps (not yet executed) ===
+{{range $i, $step := .RemainingSteps}}  {{add $i 1}}. [{{$step.Tool}}] {{$step.Description}}
+{{end}}{{end}}
+=== Task ===
+Replace the failed step and the remaining steps with a new sequence that still achieves the goal.
+Do not repeat completed steps. Avoid the approach that caused the failure.
+Return JSON with:
+- reasoning: why the new steps will succeed where the failed step did not
+- steps: array of steps, each with description, tool, arguments`
+
 	// 7. Memory Extraction (Hindsight)
 	m.defaults[MemoryExtraction] = `Analyze the completed task and extract any information worth storing in long-term memory.
 
@@ -160,6 +188,21 @@ Return JSON with: should_store (boolean), reasoning (string), and memories array
 
 	// 13. Agent Dynamic Tool Selection
 	m.defaults[AgentDynamicToolSelection] = `You are a helpful assistant with access to tools. Use tools when appropriate to help the user.`
+
+	// 14. Eval Judge (LLM-as-judge rubric grading)
+	m.defaults[EvalJudge] = `You are grading an AI assistant's answer against a rubric.
+
+Question: {{.Input}}
+{{if .Expected}}
+Reference Answer: {{.Expected}}
+{{end}}
+Assistant Answer: {{.Answer}}
+
+Rubric:
+{{.Rubric}}
+
+Score how well the answer satisfies the rubric from 0.0 (not at all) to 1.0 (fully).
+Return JSON with score and a one-sentence reason.`
 }
 
 // add is a helper for templates
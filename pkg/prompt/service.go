@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"text/template"
@@ -61,6 +62,24 @@ func (m *Manager) Get(key string) string {
 	return m.defaults[key]
 }
 
+// Keys returns the keys of all registered prompts, defaults and overrides, sorted
+func (m *Manager) Keys() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.defaults)+len(m.prompts))
+	for k := range m.defaults {
+		keys = append(keys, k)
+	}
+	for k := range m.prompts {
+		if _, ok := m.defaults[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Render renders a prompt template with provided data
 func (m *Manager) Render(key string, data interface{}) (string, error) {
 	content := m.Get(key)
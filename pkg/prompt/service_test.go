@@ -39,3 +39,23 @@ func TestDefaultPromptsExist(t *testing.T) {
 	assert.NotEmpty(t, m.Get(AgentVerification))
 	assert.NotEmpty(t, m.Get(AgentSystemPrompt))
 }
+
+func TestPromptManagerKeys(t *testing.T) {
+	m := NewManager()
+	m.RegisterDefault("zz.default", "a")
+	m.SetPrompt("zz.default", "b")
+	m.SetPrompt("zz.override", "c")
+
+	keys := m.Keys()
+	assert.IsIncreasing(t, keys)
+	assert.Contains(t, keys, "zz.default")
+	assert.Contains(t, keys, "zz.override")
+
+	count := 0
+	for _, k := range keys {
+		if k == "zz.default" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/liliang-cn/agent-go/pkg/ptc"
 	pb "github.com/liliang-cn/agent-go/pkg/ptc/grpc/pb"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 )
 
 // Client is a gRPC client for the PTC service
@@ -31,6 +32,7 @@ func NewClient(address string) (*Client, error) {
 		conn, err = grpc.Dial(
 			socketPath,
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			telemetry.GRPCDialOption(),
 			grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
 				var d net.Dialer
 				return d.DialContext(ctx, "unix", addr)
@@ -41,6 +43,7 @@ func NewClient(address string) (*Client, error) {
 		conn, err = grpc.Dial(
 			address,
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			telemetry.GRPCDialOption(),
 		)
 	}
 
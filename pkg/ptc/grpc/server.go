@@ -15,6 +15,7 @@ import (
 
 	"github.com/liliang-cn/agent-go/pkg/ptc"
 	pb "github.com/liliang-cn/agent-go/pkg/ptc/grpc/pb"
+	"github.com/liliang-cn/agent-go/pkg/telemetry"
 )
 
 // Server implements the PTC gRPC service
@@ -279,7 +280,7 @@ func (s *Server) Start() error {
 	}
 
 	// Create gRPC server with options
-	opts := []grpc.ServerOption{}
+	opts := []grpc.ServerOption{telemetry.GRPCServerOption()}
 	if s.config.MaxRecvMsgSize > 0 {
 		opts = append(opts, grpc.MaxRecvMsgSize(s.config.MaxRecvMsgSize))
 	}
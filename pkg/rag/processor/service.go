@@ -95,6 +95,13 @@ func (s *Service) SetPromptManager(m *prompt.Manager) {
 	}
 }
 
+// GraphStore returns the vector store's graph backend, or nil when the
+// backend has none. Other subsystems (e.g. the memory entity graph) may
+// share it.
+func (s *Service) GraphStore() domain.GraphStore {
+	return s.graphStore
+}
+
 // initializeTools sets up the tool system
 
 func (s *Service) Ingest(ctx context.Context, req domain.IngestRequest) (domain.IngestResponse, error) {
@@ -238,6 +238,77 @@ func (s *SQLiteGraphStore) InitGraphSchema(ctx context.Context) error {
 	return s.graph.InitGraphSchema(ctx)
 }
 
+// GetNode implements domain.GraphReader
+func (s *SQLiteGraphStore) GetNode(ctx context.Context, id string) (*domain.GraphNode, error) {
+	node, err := s.graph.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dNode := toDomainGraphNode(node)
+	return &dNode, nil
+}
+
+// GetEdges implements domain.GraphReader
+func (s *SQLiteGraphStore) GetEdges(ctx context.Context, nodeID string, direction string) ([]domain.GraphEdge, error) {
+	edges, err := s.graph.GetEdges(ctx, nodeID, direction)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.GraphEdge, len(edges))
+	for i, e := range edges {
+		result[i] = domain.GraphEdge{
+			ID:         e.ID,
+			FromNodeID: e.FromNodeID,
+			ToNodeID:   e.ToNodeID,
+			EdgeType:   e.EdgeType,
+			Weight:     e.Weight,
+			Properties: e.Properties,
+		}
+	}
+	return result, nil
+}
+
+// DeleteNode implements domain.GraphDeleter; the node's edges cascade
+func (s *SQLiteGraphStore) DeleteNode(ctx context.Context, id string) error {
+	return s.graph.DeleteNode(ctx, id)
+}
+
+// DeleteEdge implements domain.GraphDeleter
+func (s *SQLiteGraphStore) DeleteEdge(ctx context.Context, id string) error {
+	return s.graph.DeleteEdge(ctx, id)
+}
+
+// ListNodes implements domain.GraphReader
+func (s *SQLiteGraphStore) ListNodes(ctx context.Context, nodeType string) ([]domain.GraphNode, error) {
+	var filter *graph.GraphFilter
+	if nodeType != "" {
+		filter = &graph.GraphFilter{NodeTypes: []string{nodeType}}
+	}
+	nodes, err := s.graph.GetAllNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.GraphNode, len(nodes))
+	for i, n := range nodes {
+		result[i] = toDomainGraphNode(n)
+	}
+	return result, nil
+}
+
+func toDomainGraphNode(n *graph.GraphNode) domain.GraphNode {
+	vector := make([]float64, len(n.Vector))
+	for i, v := range n.Vector {
+		vector[i] = float64(v)
+	}
+	return domain.GraphNode{
+		ID:         n.ID,
+		Content:    n.Content,
+		NodeType:   n.NodeType,
+		Properties: n.Properties,
+		Vector:     vector,
+	}
+}
+
 func (s *SQLiteGraphStore) HybridSearch(ctx context.Context, vector []float64, startNodeID string, topK int) ([]domain.HybridSearchResult, error) {
 	// Convert vector
 	var queryVector []float32
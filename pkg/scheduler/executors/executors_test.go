@@ -23,6 +23,9 @@ func TestCreateAllExecutors(t *testing.T) {
 
 	scriptExec := NewScriptExecutor(cfg)
 	assert.NotNil(t, scriptExec)
+
+	memoryGCExec := NewMemoryGCExecutor(cfg)
+	assert.NotNil(t, memoryGCExec)
 }
 
 // Test executor types
@@ -44,4 +47,8 @@ func TestExecutorTypes(t *testing.T) {
 	// Test Script executor
 	scriptExec := NewScriptExecutor(cfg)
 	assert.Equal(t, "script", string(scriptExec.Type()))
+
+	// Test memory GC executor
+	memoryGCExec := NewMemoryGCExecutor(cfg)
+	assert.Equal(t, "memory_gc", string(memoryGCExec.Type()))
 }
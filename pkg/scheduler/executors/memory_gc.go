@@ -0,0 +1,160 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/memory"
+	"github.com/liliang-cn/agent-go/pkg/scheduler"
+	"github.com/liliang-cn/agent-go/pkg/store"
+)
+
+// MemoryGCExecutor runs memory maintenance passes
+type MemoryGCExecutor struct {
+	config  *config.Config
+	service *memory.Service
+}
+
+// NewMemoryGCExecutor creates a new memory maintenance executor
+func NewMemoryGCExecutor(cfg *config.Config) *MemoryGCExecutor {
+	return &MemoryGCExecutor{
+		config: cfg,
+	}
+}
+
+// SetMemoryService sets the memory service to maintain. Without one the
+// executor opens the configured store directly and skips consolidation,
+// which needs an LLM.
+func (e *MemoryGCExecutor) SetMemoryService(svc *memory.Service) {
+	e.service = svc
+}
+
+// Type returns the task type this executor handles
+func (e *MemoryGCExecutor) Type() scheduler.TaskType {
+	return scheduler.TaskTypeMemoryGC
+}
+
+// Validate checks if the parameters are valid for memory maintenance
+func (e *MemoryGCExecutor) Validate(parameters map[string]string) error {
+	for _, key := range []string{"dry-run", "consolidate", "compact-index"} {
+		if v, exists := parameters[key]; exists {
+			if _, err := strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("%s must be a boolean: %s", key, v)
+			}
+		}
+	}
+
+	if v, exists := parameters["archive-threshold"]; exists {
+		if f, err := strconv.ParseFloat(v, 64); err != nil || f < 0 || f > 1 {
+			return fmt.Errorf("archive-threshold must be between 0 and 1: %s", v)
+		}
+	}
+
+	for _, key := range []string{"min-age-days", "scope-quota", "purge-after-days"} {
+		if v, exists := parameters[key]; exists {
+			if n, err := strconv.Atoi(v); err != nil || n < 0 {
+				return fmt.Errorf("%s must be a non-negative integer: %s", key, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Execute runs a maintenance pass and returns the report as JSON
+func (e *MemoryGCExecutor) Execute(ctx context.Context, parameters map[string]string) (*scheduler.TaskResult, error) {
+	start := time.Now()
+
+	svc := e.service
+	if svc == nil {
+		memStore, closeStore, err := e.openStore()
+		if err != nil {
+			return &scheduler.TaskResult{
+				Success:  false,
+				Error:    err.Error(),
+				Duration: time.Since(start),
+			}, nil
+		}
+		defer closeStore()
+		svc = memory.NewService(memStore, nil, nil, nil)
+	}
+
+	report, err := svc.RunMaintenance(ctx, e.maintenanceConfig(parameters))
+	if err != nil {
+		return &scheduler.TaskResult{
+			Success:  false,
+			Error:    fmt.Sprintf("memory maintenance failed: %v", err),
+			Duration: time.Since(start),
+		}, nil
+	}
+
+	outputJSON, _ := json.MarshalIndent(report, "", "  ")
+	return &scheduler.TaskResult{
+		Success:  len(report.Errors) == 0,
+		Output:   string(outputJSON),
+		Duration: time.Since(start),
+	}, nil
+}
+
+// maintenanceConfig starts from memory.maintenance in the config file and
+// applies per-task overrides
+func (e *MemoryGCExecutor) maintenanceConfig(parameters map[string]string) *memory.MaintenanceConfig {
+	cfg := memory.DefaultMaintenanceConfig()
+	if e.config != nil {
+		m := e.config.Memory.Maintenance
+		cfg.ArchiveThreshold = m.ArchiveThreshold
+		cfg.MinAge = time.Duration(m.MinAgeDays) * 24 * time.Hour
+		cfg.ScopeQuota = m.ScopeQuota
+		cfg.PurgeAfter = time.Duration(m.PurgeAfterDays) * 24 * time.Hour
+		cfg.Consolidate = m.Consolidate
+		cfg.CompactIndex = m.CompactIndex
+	}
+
+	if v, err := strconv.ParseBool(parameters["dry-run"]); err == nil {
+		cfg.DryRun = v
+	}
+	if v, err := strconv.ParseBool(parameters["consolidate"]); err == nil {
+		cfg.Consolidate = v
+	}
+	if v, err := strconv.ParseBool(parameters["compact-index"]); err == nil {
+		cfg.CompactIndex = v
+	}
+	if v, err := strconv.ParseFloat(parameters["archive-threshold"], 64); err == nil {
+		cfg.ArchiveThreshold = v
+	}
+	if v, err := strconv.Atoi(parameters["min-age-days"]); err == nil {
+		cfg.MinAge = time.Duration(v) * 24 * time.Hour
+	}
+	if v, err := strconv.Atoi(parameters["scope-quota"]); err == nil {
+		cfg.ScopeQuota = v
+	}
+	if v, err := strconv.Atoi(parameters["purge-after-days"]); err == nil {
+		cfg.PurgeAfter = time.Duration(v) * 24 * time.Hour
+	}
+	return cfg
+}
+
+// openStore opens the memory store named in the config and returns the
+// function that releases it
+func (e *MemoryGCExecutor) openStore() (domain.MemoryStore, func() error, error) {
+	if e.config == nil || e.config.Memory.MemoryPath == "" {
+		return nil, nil, fmt.Errorf("memory path is not configured")
+	}
+	if e.config.Memory.StoreType == "vector" {
+		memStore, err := store.NewMemoryStore(e.config.Memory.MemoryPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return memStore, memStore.Close, nil
+	}
+	fileStore, err := store.NewFileMemoryStore(e.config.Memory.MemoryPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fileStore, func() error { return nil }, nil
+}
@@ -0,0 +1,92 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/config"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/agent-go/pkg/memory"
+	"github.com/liliang-cn/agent-go/pkg/scheduler"
+	"github.com/liliang-cn/agent-go/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryGCExecutorType(t *testing.T) {
+	executor := NewMemoryGCExecutor(nil)
+	assert.Equal(t, scheduler.TaskTypeMemoryGC, executor.Type())
+}
+
+func TestMemoryGCExecutorValidate(t *testing.T) {
+	executor := NewMemoryGCExecutor(nil)
+
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		wantErr    bool
+	}{
+		{name: "No parameters", parameters: map[string]string{}},
+		{name: "Valid overrides", parameters: map[string]string{"dry-run": "true", "archive-threshold": "0.3", "scope-quota": "100"}},
+		{name: "Invalid dry-run", parameters: map[string]string{"dry-run": "maybe"}, wantErr: true},
+		{name: "Threshold out of range", parameters: map[string]string{"archive-threshold": "2"}, wantErr: true},
+		{name: "Negative purge age", parameters: map[string]string{"purge-after-days": "-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.parameters)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMemoryGCExecutorExecute(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Memory.StoreType = "file"
+	cfg.Memory.MemoryPath = dir
+	cfg.Memory.Maintenance.ArchiveThreshold = 0.35
+	cfg.Memory.Maintenance.MinAgeDays = 30
+
+	fileStore, err := store.NewFileMemoryStore(dir)
+	require.NoError(t, err)
+	old := time.Now().AddDate(-1, 0, 0)
+	require.NoError(t, fileStore.Store(context.Background(), &domain.Memory{
+		ID:         "stale-fact",
+		Type:       domain.MemoryTypeFact,
+		Content:    "The build server used to run on Jenkins",
+		Importance: 0.1,
+		CreatedAt:  old,
+	}))
+
+	executor := NewMemoryGCExecutor(cfg)
+
+	result, err := executor.Execute(context.Background(), map[string]string{"dry-run": "true"})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+
+	var report memory.MaintenanceReport
+	require.NoError(t, json.Unmarshal([]byte(result.Output), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Count(memory.MaintenanceArchive))
+
+	m, err := fileStore.Get(context.Background(), "stale-fact")
+	require.NoError(t, err)
+	assert.Nil(t, m.ValidTo, "dry run must not change the store")
+
+	result, err = executor.Execute(context.Background(), map[string]string{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+
+	m, err = fileStore.Get(context.Background(), "stale-fact")
+	require.NoError(t, err)
+	assert.NotNil(t, m.ValidTo)
+	assert.True(t, store.IsArchived(m))
+}
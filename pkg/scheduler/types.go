@@ -13,6 +13,9 @@ const (
 	TaskTypeIngest TaskType = "ingest"
 	TaskTypeMCP    TaskType = "mcp"
 	TaskTypeScript TaskType = "script"
+	// TaskTypeMemoryGC archives decayed memories, enforces quotas and
+	// compacts the memory store
+	TaskTypeMemoryGC TaskType = "memory_gc"
 )
 
 // TaskStatus represents the status of a task execution
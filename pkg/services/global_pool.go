@@ -60,6 +60,7 @@ func (s *GlobalPoolService) Initialize(ctx context.Context, cfg *config.Config)
 		Enabled:   cfg.LLM.Enabled,
 		Strategy:  cfg.LLM.Strategy,
 		Providers: cfg.LLM.Providers,
+		Cassette:  cfg.LLM.Cassette,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create LLM pool: %w", err)
@@ -0,0 +1,541 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// ErrMemoryAccessDenied is returned when a principal may not write a scope.
+// Denied reads surface as ErrMemoryNotFound so existence is not leaked.
+var ErrMemoryAccessDenied = errors.New("memory access denied")
+
+// ScopeRule says who may access a scope type
+type ScopeRule string
+
+const (
+	ScopeRuleAny   ScopeRule = "any"   // every principal
+	ScopeRuleOwner ScopeRule = "owner" // the principal the scope ID names
+	ScopeRuleAdmin ScopeRule = "admin" // admins only
+	ScopeRuleNone  ScopeRule = "none"  // nobody but admins and the system
+)
+
+// ScopePolicy is a MemoryAccessPolicy with one read and one write rule per
+// scope type. Admins and the system principal may always read and write; a
+// nil principal is anonymous and only passes "any" rules.
+type ScopePolicy struct {
+	Read  map[domain.MemoryScopeType]ScopeRule
+	Write map[domain.MemoryScopeType]ScopeRule
+}
+
+// DefaultScopePolicy lets everyone read global memories and keeps every
+// other scope to its owner. Only admins write global memories.
+func DefaultScopePolicy() *ScopePolicy {
+	owner := map[domain.MemoryScopeType]ScopeRule{
+		domain.MemoryScopeAgent:   ScopeRuleOwner,
+		domain.MemoryScopeProject: ScopeRuleOwner,
+		domain.MemoryScopeUser:    ScopeRuleOwner,
+		domain.MemoryScopeSession: ScopeRuleOwner,
+	}
+	p := &ScopePolicy{
+		Read:  map[domain.MemoryScopeType]ScopeRule{domain.MemoryScopeGlobal: ScopeRuleAny},
+		Write: map[domain.MemoryScopeType]ScopeRule{domain.MemoryScopeGlobal: ScopeRuleAdmin},
+	}
+	for t, r := range owner {
+		p.Read[t] = r
+		p.Write[t] = r
+	}
+	return p
+}
+
+// NewScopePolicy overrides the default policy with rules keyed by scope type
+// name, as they appear in config
+func NewScopePolicy(read, write map[string]string) (*ScopePolicy, error) {
+	p := DefaultScopePolicy()
+	apply := func(dst map[domain.MemoryScopeType]ScopeRule, src map[string]string) error {
+		for scopeType, rule := range src {
+			switch r := ScopeRule(rule); r {
+			case ScopeRuleAny, ScopeRuleOwner, ScopeRuleAdmin, ScopeRuleNone:
+				dst[domain.MemoryScopeType(scopeType)] = r
+			default:
+				return fmt.Errorf("invalid rule %q for scope %q (want any, owner, admin or none)", rule, scopeType)
+			}
+		}
+		return nil
+	}
+	if err := apply(p.Read, read); err != nil {
+		return nil, err
+	}
+	if err := apply(p.Write, write); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Allow implements domain.MemoryAccessPolicy
+func (p *ScopePolicy) Allow(principal *domain.MemoryPrincipal, scope domain.MemoryScope, mode domain.MemoryAccessMode) bool {
+	if principal.Unrestricted() {
+		return true
+	}
+	rules := p.Read
+	if mode == domain.MemoryAccessWrite {
+		rules = p.Write
+	}
+	switch rules[scope.Type] {
+	case ScopeRuleAny:
+		return true
+	case ScopeRuleOwner:
+		return principal.Owns(scope)
+	default:
+		return false
+	}
+}
+
+// FileAuditLog appends memory audit events to a JSONL file
+type FileAuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileAuditLog opens (or creates) the audit log at path
+func NewFileAuditLog(path string) (*FileAuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory audit log: %w", err)
+	}
+	return &FileAuditLog{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// RecordMemoryAccess implements domain.MemoryAuditor
+func (l *FileAuditLog) RecordMemoryAccess(ctx context.Context, event *domain.MemoryAuditEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(event)
+}
+
+// Close closes the underlying file
+func (l *FileAuditLog) Close() error {
+	return l.file.Close()
+}
+
+// accessOverfetch widens searches so filtering still leaves topK results
+const accessOverfetch = 3
+
+// AccessControlledStore enforces a MemoryAccessPolicy on another store and
+// audits every read and write. Callers without a domain.MemoryPrincipal are
+// anonymous. Reads drop out-of-scope memories; writes outside the principal's
+// scopes fail with ErrMemoryAccessDenied.
+type AccessControlledStore struct {
+	inner   domain.MemoryStore
+	policy  domain.MemoryAccessPolicy
+	auditor domain.MemoryAuditor
+}
+
+// NewAccessControlledStore wraps inner. A nil policy uses DefaultScopePolicy;
+// a nil auditor disables auditing.
+func NewAccessControlledStore(inner domain.MemoryStore, policy domain.MemoryAccessPolicy, auditor domain.MemoryAuditor) *AccessControlledStore {
+	if policy == nil {
+		policy = DefaultScopePolicy()
+	}
+	return &AccessControlledStore{inner: inner, policy: policy, auditor: auditor}
+}
+
+// Unwrap returns the store being guarded
+func (s *AccessControlledStore) Unwrap() domain.MemoryStore {
+	return s.inner
+}
+
+// Policy returns the policy being enforced
+func (s *AccessControlledStore) Policy() domain.MemoryAccessPolicy {
+	return s.policy
+}
+
+// Auditor returns the auditor, or nil
+func (s *AccessControlledStore) Auditor() domain.MemoryAuditor {
+	return s.auditor
+}
+
+// Unwrap returns the store underneath any access control wrapper
+func Unwrap(ms domain.MemoryStore) domain.MemoryStore {
+	for {
+		w, ok := ms.(*AccessControlledStore)
+		if !ok {
+			return ms
+		}
+		ms = w.inner
+	}
+}
+
+// unrestricted reports whether the caller skips filtering altogether
+func (s *AccessControlledStore) unrestricted(ctx context.Context) bool {
+	return domain.MemoryPrincipalFromContext(ctx).Unrestricted()
+}
+
+func (s *AccessControlledStore) allowed(ctx context.Context, scope domain.MemoryScope, mode domain.MemoryAccessMode) bool {
+	return s.policy.Allow(domain.MemoryPrincipalFromContext(ctx), scope, mode)
+}
+
+func (s *AccessControlledStore) audit(ctx context.Context, op string, mode domain.MemoryAccessMode, scope string, ids []string, allowed bool, redacted int) {
+	if s.auditor == nil {
+		return
+	}
+	s.auditor.RecordMemoryAccess(ctx, &domain.MemoryAuditEvent{
+		At:        time.Now(),
+		Principal: domain.MemoryPrincipalFromContext(ctx).String(),
+		Operation: op,
+		Mode:      mode,
+		MemoryIDs: ids,
+		Scope:     scope,
+		Allowed:   allowed,
+		Redacted:  redacted,
+	})
+}
+
+func (s *AccessControlledStore) filter(ctx context.Context, op string, mems []*domain.Memory) []*domain.Memory {
+	kept := make([]*domain.Memory, 0, len(mems))
+	ids := make([]string, 0, len(mems))
+	for _, m := range mems {
+		if m != nil && s.allowed(ctx, domain.MemoryScopeOf(m), domain.MemoryAccessRead) {
+			kept = append(kept, m)
+			ids = append(ids, m.ID)
+		}
+	}
+	s.audit(ctx, op, domain.MemoryAccessRead, "", ids, true, len(mems)-len(kept))
+	return kept
+}
+
+func (s *AccessControlledStore) filterScored(ctx context.Context, op string, hits []*domain.MemoryWithScore, topK int) []*domain.MemoryWithScore {
+	kept := make([]*domain.MemoryWithScore, 0, len(hits))
+	ids := make([]string, 0, len(hits))
+	for _, h := range hits {
+		if h == nil || h.Memory == nil || !s.allowed(ctx, domain.MemoryScopeOf(h.Memory), domain.MemoryAccessRead) {
+			continue
+		}
+		if topK > 0 && len(kept) >= topK {
+			break
+		}
+		kept = append(kept, h)
+		ids = append(ids, h.ID)
+	}
+	redacted := 0
+	for _, h := range hits {
+		if h != nil && h.Memory != nil && !s.allowed(ctx, domain.MemoryScopeOf(h.Memory), domain.MemoryAccessRead) {
+			redacted++
+		}
+	}
+	s.audit(ctx, op, domain.MemoryAccessRead, "", ids, true, redacted)
+	return kept
+}
+
+// overfetch widens topK when results will be filtered
+func (s *AccessControlledStore) overfetch(ctx context.Context, topK int) int {
+	if s.unrestricted(ctx) || topK <= 0 {
+		return topK
+	}
+	return topK * accessOverfetch
+}
+
+// checkWrite authorizes writing memory m, and overwriting any existing
+// memory with the same ID
+func (s *AccessControlledStore) checkWrite(ctx context.Context, op string, m *domain.Memory) error {
+	scope := domain.MemoryScopeOf(m)
+	ok := s.allowed(ctx, scope, domain.MemoryAccessWrite)
+	if ok && !s.unrestricted(ctx) && m.ID != "" {
+		if existing, err := s.inner.Get(ctx, m.ID); err == nil && existing != nil {
+			ok = s.allowed(ctx, domain.MemoryScopeOf(existing), domain.MemoryAccessWrite)
+		}
+	}
+	s.audit(ctx, op, domain.MemoryAccessWrite, m.SessionID, []string{m.ID}, ok, 0)
+	if !ok {
+		return fmt.Errorf("%w: %s on %s", ErrMemoryAccessDenied, op, scopeLabelOf(scope))
+	}
+	return nil
+}
+
+func (s *AccessControlledStore) checkScopeWrite(ctx context.Context, op string, bankID string) error {
+	scope := domain.MemoryScopeFromBankID(bankID)
+	ok := s.allowed(ctx, scope, domain.MemoryAccessWrite)
+	s.audit(ctx, op, domain.MemoryAccessWrite, bankID, nil, ok, 0)
+	if !ok {
+		return fmt.Errorf("%w: %s on %s", ErrMemoryAccessDenied, op, scopeLabelOf(scope))
+	}
+	return nil
+}
+
+func scopeLabelOf(scope domain.MemoryScope) string {
+	if scope.ID == "" {
+		return string(scope.Type)
+	}
+	return string(scope.Type) + ":" + scope.ID
+}
+
+func (s *AccessControlledStore) Store(ctx context.Context, memory *domain.Memory) error {
+	if err := s.checkWrite(ctx, "store", memory); err != nil {
+		return err
+	}
+	return s.inner.Store(ctx, memory)
+}
+
+func (s *AccessControlledStore) Search(ctx context.Context, vector []float64, topK int, minScore float64) ([]*domain.MemoryWithScore, error) {
+	hits, err := s.inner.Search(ctx, vector, s.overfetch(ctx, topK), minScore)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterScored(ctx, "search", hits, topK), nil
+}
+
+func (s *AccessControlledStore) SearchBySession(ctx context.Context, sessionID string, vector []float64, topK int) ([]*domain.MemoryWithScore, error) {
+	hits, err := s.inner.SearchBySession(ctx, sessionID, vector, s.overfetch(ctx, topK))
+	if err != nil {
+		return nil, err
+	}
+	return s.filterScored(ctx, "search_by_session", hits, topK), nil
+}
+
+// SearchByScope drops scopes the principal may not read before searching,
+// then filters hits in case the store maps scopes loosely
+func (s *AccessControlledStore) SearchByScope(ctx context.Context, vector []float64, scopes []domain.MemoryScope, topK int) ([]*domain.MemoryWithScore, error) {
+	readable := make([]domain.MemoryScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if s.allowed(ctx, scope, domain.MemoryAccessRead) {
+			readable = append(readable, scope)
+		}
+	}
+	if len(readable) == 0 {
+		s.audit(ctx, "search_by_scope", domain.MemoryAccessRead, "", nil, false, 0)
+		return nil, nil
+	}
+	hits, err := s.inner.SearchByScope(ctx, vector, readable, topK)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterScored(ctx, "search_by_scope", hits, topK), nil
+}
+
+func (s *AccessControlledStore) StoreWithScope(ctx context.Context, memory *domain.Memory, scope domain.MemoryScope) error {
+	ok := s.allowed(ctx, scope, domain.MemoryAccessWrite)
+	s.audit(ctx, "store_with_scope", domain.MemoryAccessWrite, scopeLabelOf(scope), []string{memory.ID}, ok, 0)
+	if !ok {
+		return fmt.Errorf("%w: store on %s", ErrMemoryAccessDenied, scopeLabelOf(scope))
+	}
+	return s.inner.StoreWithScope(ctx, memory, scope)
+}
+
+func (s *AccessControlledStore) SearchByText(ctx context.Context, query string, topK int) ([]*domain.MemoryWithScore, error) {
+	hits, err := s.inner.SearchByText(ctx, query, s.overfetch(ctx, topK))
+	if err != nil {
+		return nil, err
+	}
+	return s.filterScored(ctx, "search_by_text", hits, topK), nil
+}
+
+func (s *AccessControlledStore) SearchTemporal(ctx context.Context, query *domain.MemoryTemporalQuery) ([]*domain.MemoryWithScore, error) {
+	topK := 0
+	if query != nil {
+		widened := *query
+		topK = query.TopK
+		widened.TopK = s.overfetch(ctx, topK)
+		query = &widened
+	}
+	temporal, ok := s.inner.(domain.TemporalMemoryStore)
+	if !ok {
+		return nil, ErrTemporalUnsupported
+	}
+	hits, err := temporal.SearchTemporal(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterScored(ctx, "search_temporal", hits, topK), nil
+}
+
+func (s *AccessControlledStore) History(ctx context.Context, id string) ([]*domain.Memory, error) {
+	temporal, ok := s.inner.(domain.TemporalMemoryStore)
+	if !ok {
+		return nil, ErrTemporalUnsupported
+	}
+	chain, err := temporal.History(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	chain = s.filter(ctx, "history", chain)
+	if len(chain) == 0 {
+		return nil, ErrMemoryNotFound
+	}
+	return chain, nil
+}
+
+func (s *AccessControlledStore) Get(ctx context.Context, id string) (*domain.Memory, error) {
+	m, err := s.inner.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ok := s.allowed(ctx, domain.MemoryScopeOf(m), domain.MemoryAccessRead)
+	s.audit(ctx, "get", domain.MemoryAccessRead, m.SessionID, []string{id}, ok, 0)
+	if !ok {
+		return nil, ErrMemoryNotFound
+	}
+	return m, nil
+}
+
+func (s *AccessControlledStore) Update(ctx context.Context, memory *domain.Memory) error {
+	if err := s.checkWrite(ctx, "update", memory); err != nil {
+		return err
+	}
+	return s.inner.Update(ctx, memory)
+}
+
+// IncrementAccess follows a read, so it needs read rather than write access
+func (s *AccessControlledStore) IncrementAccess(ctx context.Context, id string) error {
+	if !s.unrestricted(ctx) {
+		m, err := s.inner.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !s.allowed(ctx, domain.MemoryScopeOf(m), domain.MemoryAccessRead) {
+			return ErrMemoryNotFound
+		}
+	}
+	return s.inner.IncrementAccess(ctx, id)
+}
+
+func (s *AccessControlledStore) GetByType(ctx context.Context, memoryType domain.MemoryType, limit int) ([]*domain.Memory, error) {
+	mems, err := s.inner.GetByType(ctx, memoryType, s.overfetch(ctx, limit))
+	if err != nil {
+		return nil, err
+	}
+	mems = s.filter(ctx, "get_by_type", mems)
+	if limit > 0 && len(mems) > limit {
+		mems = mems[:limit]
+	}
+	return mems, nil
+}
+
+// List pages over readable memories only, so total reflects what the
+// principal can see
+func (s *AccessControlledStore) List(ctx context.Context, limit, offset int) ([]*domain.Memory, int, error) {
+	if s.unrestricted(ctx) {
+		mems, total, err := s.inner.List(ctx, limit, offset)
+		if err == nil {
+			s.filter(ctx, "list", mems)
+		}
+		return mems, total, err
+	}
+
+	all, _, err := s.inner.List(ctx, 100000, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	readable := make([]*domain.Memory, 0, len(all))
+	for _, m := range all {
+		if s.allowed(ctx, domain.MemoryScopeOf(m), domain.MemoryAccessRead) {
+			readable = append(readable, m)
+		}
+	}
+	total := len(readable)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	page := readable[offset:end]
+
+	ids := make([]string, len(page))
+	for i, m := range page {
+		ids[i] = m.ID
+	}
+	s.audit(ctx, "list", domain.MemoryAccessRead, "", ids, true, len(all)-total)
+	return page, total, nil
+}
+
+func (s *AccessControlledStore) Delete(ctx context.Context, id string) error {
+	m, err := s.inner.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkWrite(ctx, "delete", m); err != nil {
+		if !s.allowed(ctx, domain.MemoryScopeOf(m), domain.MemoryAccessRead) {
+			return ErrMemoryNotFound
+		}
+		return err
+	}
+	return s.inner.Delete(ctx, id)
+}
+
+func (s *AccessControlledStore) DeleteBySession(ctx context.Context, sessionID string) error {
+	if err := s.checkScopeWrite(ctx, "delete_by_session", sessionID); err != nil {
+		return err
+	}
+	return s.inner.DeleteBySession(ctx, sessionID)
+}
+
+func (s *AccessControlledStore) ConfigureBank(ctx context.Context, sessionID string, config *domain.MemoryBankConfig) error {
+	if err := s.checkScopeWrite(ctx, "configure_bank", sessionID); err != nil {
+		return err
+	}
+	return s.inner.ConfigureBank(ctx, sessionID, config)
+}
+
+func (s *AccessControlledStore) Reflect(ctx context.Context, sessionID string) (string, error) {
+	if err := s.checkScopeWrite(ctx, "reflect", sessionID); err != nil {
+		return "", err
+	}
+	return s.inner.Reflect(ctx, sessionID)
+}
+
+func (s *AccessControlledStore) AddMentalModel(ctx context.Context, model *domain.MentalModel) error {
+	if err := s.checkScopeWrite(ctx, "add_mental_model", ""); err != nil {
+		return err
+	}
+	return s.inner.AddMentalModel(ctx, model)
+}
+
+func (s *AccessControlledStore) InitSchema(ctx context.Context) error {
+	return s.inner.InitSchema(ctx)
+}
+
+// MentalModels forwards to the inner store when it can list mental models.
+// Mental models are global.
+func (s *AccessControlledStore) MentalModels(ctx context.Context) ([]*domain.MentalModel, error) {
+	lister, ok := s.inner.(interface {
+		MentalModels(ctx context.Context) ([]*domain.MentalModel, error)
+	})
+	if !ok || !s.allowed(ctx, domain.MemoryScope{Type: domain.MemoryScopeGlobal}, domain.MemoryAccessRead) {
+		return nil, nil
+	}
+	return lister.MentalModels(ctx)
+}
+
+// BankConfigs forwards to the inner store, keeping readable banks only
+func (s *AccessControlledStore) BankConfigs(ctx context.Context) (map[string]*domain.MemoryBankConfig, error) {
+	lister, ok := s.inner.(interface {
+		BankConfigs(ctx context.Context) (map[string]*domain.MemoryBankConfig, error)
+	})
+	if !ok {
+		return nil, nil
+	}
+	banks, err := lister.BankConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for id := range banks {
+		if !s.allowed(ctx, domain.MemoryScopeFromBankID(id), domain.MemoryAccessRead) {
+			delete(banks, id)
+		}
+	}
+	return banks, nil
+}
+
+var _ domain.TemporalMemoryStore = (*AccessControlledStore)(nil)
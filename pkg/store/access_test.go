@@ -0,0 +1,189 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+type recordingAuditor struct {
+	events []*domain.MemoryAuditEvent
+}
+
+func (a *recordingAuditor) RecordMemoryAccess(ctx context.Context, event *domain.MemoryAuditEvent) {
+	a.events = append(a.events, event)
+}
+
+func newAccessTestStore(t *testing.T) (*AccessControlledStore, *recordingAuditor) {
+	t.Helper()
+	ctx := context.Background()
+	inner, err := NewFileMemoryStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new file memory store failed: %v", err)
+	}
+	for _, m := range []*domain.Memory{
+		{ID: "global-1", Type: domain.MemoryTypeFact, Content: "The office opens at nine", SessionID: "global"},
+		{ID: "alice-1", Type: domain.MemoryTypePreference, Content: "Alice prefers dark mode", SessionID: "user:alice"},
+		{ID: "bob-1", Type: domain.MemoryTypePreference, Content: "Bob prefers light mode", SessionID: "user:bob"},
+	} {
+		m.CreatedAt = time.Now()
+		if err := inner.Store(ctx, m); err != nil {
+			t.Fatalf("store %s failed: %v", m.ID, err)
+		}
+	}
+	auditor := &recordingAuditor{}
+	return NewAccessControlledStore(inner, nil, auditor), auditor
+}
+
+func TestAccessControlledStoreFiltersReads(t *testing.T) {
+	s, auditor := newAccessTestStore(t)
+	alice := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "alice"})
+
+	mems, total, err := s.List(alice, 10, 0)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if total != 2 || len(mems) != 2 {
+		t.Fatalf("expected alice to see 2 memories, got %d (total %d)", len(mems), total)
+	}
+	for _, m := range mems {
+		if m.ID == "bob-1" {
+			t.Fatalf("alice should not see bob's memory")
+		}
+	}
+
+	if _, err := s.Get(alice, "bob-1"); !errors.Is(err, ErrMemoryNotFound) {
+		t.Fatalf("expected not found for bob's memory, got %v", err)
+	}
+
+	hits, err := s.SearchByText(alice, "prefers", 10)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != "alice-1" {
+		t.Fatalf("expected only alice-1, got %+v", hits)
+	}
+
+	scopes := []domain.MemoryScope{
+		{Type: domain.MemoryScopeUser, ID: "bob"},
+		{Type: domain.MemoryScopeGlobal},
+	}
+	hits, err = s.SearchByScope(alice, nil, scopes, 10)
+	if err != nil {
+		t.Fatalf("search by scope failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != "global-1" {
+		t.Fatalf("expected only the global memory, got %+v", hits)
+	}
+
+	last := auditor.events[len(auditor.events)-1]
+	if last.Principal != "user:alice" || last.Mode != domain.MemoryAccessRead {
+		t.Fatalf("unexpected audit event %+v", last)
+	}
+}
+
+func TestAccessControlledStoreChecksWrites(t *testing.T) {
+	s, auditor := newAccessTestStore(t)
+	alice := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "alice"})
+
+	if err := s.Store(alice, &domain.Memory{ID: "alice-2", Content: "Alice lives in Paris", SessionID: "user:alice", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("alice should write her own scope: %v", err)
+	}
+	if err := s.Store(alice, &domain.Memory{ID: "g-2", Content: "Shared fact", CreatedAt: time.Now()}); !errors.Is(err, ErrMemoryAccessDenied) {
+		t.Fatalf("expected global write to be denied, got %v", err)
+	}
+	// Re-scoping someone else's memory must not bypass the check
+	if err := s.Update(alice, &domain.Memory{ID: "bob-1", Content: "stolen", SessionID: "user:alice"}); !errors.Is(err, ErrMemoryAccessDenied) {
+		t.Fatalf("expected overwrite of bob's memory to be denied, got %v", err)
+	}
+	if err := s.Delete(alice, "bob-1"); !errors.Is(err, ErrMemoryNotFound) {
+		t.Fatalf("expected delete of unreadable memory to report not found, got %v", err)
+	}
+	if err := s.DeleteBySession(alice, "user:bob"); !errors.Is(err, ErrMemoryAccessDenied) {
+		t.Fatalf("expected delete of bob's bank to be denied, got %v", err)
+	}
+
+	denied := 0
+	for _, e := range auditor.events {
+		if e.Mode == domain.MemoryAccessWrite && !e.Allowed {
+			denied++
+		}
+	}
+	if denied != 4 {
+		t.Fatalf("expected 4 denied writes in the audit log, got %d", denied)
+	}
+
+	// Callers without a principal are anonymous; the system and admins are unrestricted
+	if _, err := s.Get(context.Background(), "bob-1"); !errors.Is(err, ErrMemoryNotFound) {
+		t.Fatalf("anonymous caller should not read bob's memory, got %v", err)
+	}
+	if err := s.Store(context.Background(), &domain.Memory{ID: "anon-1", Content: "x", SessionID: "user:bob"}); !errors.Is(err, ErrMemoryAccessDenied) {
+		t.Fatalf("anonymous write should be denied, got %v", err)
+	}
+	if _, err := s.Get(domain.WithSystemMemoryPrincipal(context.Background()), "bob-1"); err != nil {
+		t.Fatalf("system caller should read bob's memory: %v", err)
+	}
+	admin := domain.WithMemoryPrincipal(context.Background(), &domain.MemoryPrincipal{UserID: "root", Admin: true})
+	if err := s.Delete(admin, "bob-1"); err != nil {
+		t.Fatalf("admin delete failed: %v", err)
+	}
+}
+
+func TestNewScopePolicy(t *testing.T) {
+	p, err := NewScopePolicy(map[string]string{"global": "admin"}, map[string]string{"user": "none"})
+	if err != nil {
+		t.Fatalf("new scope policy failed: %v", err)
+	}
+	alice := &domain.MemoryPrincipal{UserID: "alice"}
+	if p.Allow(alice, domain.MemoryScope{Type: domain.MemoryScopeGlobal}, domain.MemoryAccessRead) {
+		t.Fatalf("global reads should be admin only")
+	}
+	if p.Allow(alice, domain.MemoryScope{Type: domain.MemoryScopeUser, ID: "alice"}, domain.MemoryAccessWrite) {
+		t.Fatalf("user writes should be disabled")
+	}
+	if !p.Allow(alice, domain.MemoryScope{Type: domain.MemoryScopeUser, ID: "alice"}, domain.MemoryAccessRead) {
+		t.Fatalf("owner reads should keep the default rule")
+	}
+
+	if _, err := NewScopePolicy(map[string]string{"user": "everyone"}, nil); err == nil {
+		t.Fatalf("expected invalid rule to be rejected")
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "memory.jsonl")
+	log, err := NewFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("new audit log failed: %v", err)
+	}
+	log.RecordMemoryAccess(context.Background(), &domain.MemoryAuditEvent{Principal: "user:alice", Operation: "get", Mode: domain.MemoryAccessRead, Allowed: true})
+	log.RecordMemoryAccess(context.Background(), &domain.MemoryAuditEvent{Principal: "user:alice", Operation: "store", Mode: domain.MemoryAccessWrite})
+	if err := log.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log failed: %v", err)
+	}
+	defer f.Close()
+	var ops []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event domain.MemoryAuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		ops = append(ops, event.Operation)
+	}
+	if len(ops) != 2 || ops[0] != "get" || ops[1] != "store" {
+		t.Fatalf("unexpected audit operations %v", ops)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/google/uuid"
 	"github.com/liliang-cn/agent-go/pkg/domain"
 	"gopkg.in/yaml.v3"
@@ -23,6 +24,7 @@ type FileMemoryStore struct {
 	mu         sync.RWMutex
 	indexDirty bool // true when index needs rebuild
 	llm        domain.Generator
+	repo       *git.Repository // set by EnableGit
 }
 
 // WithLLM injects an LLM generator used for Reflect() consolidation.
@@ -136,11 +138,15 @@ func (s *FileMemoryStore) Store(ctx context.Context, memory *domain.Memory) erro
 	// Double check content isn't empty
 	content := fmt.Sprintf("---\n%s---\n\n%s", string(frontmatter), memory.Content)
 
+	op := "store"
+	if _, err := os.Stat(path); err == nil {
+		op = "update"
+	}
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return err
 	}
 	s.indexDirty = true
-	return nil
+	return s.recordChange(ctx, op, filepath.Join(category, fileName), memory)
 }
 
 // Search performs a simplified keyword search (since we're embedding-free)
@@ -257,6 +263,25 @@ func (s *FileMemoryStore) SearchByText(ctx context.Context, query string, topK i
 	return results, nil
 }
 
+// SearchTemporal returns memories valid at query.AsOf or during [From, To],
+// including superseded versions
+func (s *FileMemoryStore) SearchTemporal(ctx context.Context, query *domain.MemoryTemporalQuery) ([]*domain.MemoryWithScore, error) {
+	all, _, err := s.List(ctx, 1000, 0)
+	if err != nil {
+		return nil, err
+	}
+	return filterTemporal(all, query), nil
+}
+
+// History returns all versions of a memory linked through SupersededBy
+func (s *FileMemoryStore) History(ctx context.Context, id string) ([]*domain.Memory, error) {
+	all, _, err := s.List(ctx, 1000, 0)
+	if err != nil {
+		return nil, err
+	}
+	return memoryHistory(all, id)
+}
+
 // scopeToBankIDFile converts MemoryScope to bank ID for file store
 func scopeToBankIDFile(scope domain.MemoryScope) string {
 	if scope.Type == domain.MemoryScopeGlobal {
@@ -289,7 +314,9 @@ func (s *FileMemoryStore) IncrementAccess(ctx context.Context, id string) error
 	}
 	m.AccessCount++
 	m.LastAccessed = time.Now()
-	return s.Store(ctx, m)
+	// Access bookkeeping is not worth a commit; it rides along with the
+	// memory's next real change
+	return s.Store(withoutGitCommit(ctx), m)
 }
 
 func (s *FileMemoryStore) GetByType(ctx context.Context, memoryType domain.MemoryType, limit int) ([]*domain.Memory, error) {
@@ -336,7 +363,12 @@ func (s *FileMemoryStore) Delete(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, cat := range []string{"streams", "entities"} {
-		_ = os.Remove(filepath.Join(s.baseDir, cat, id+".md"))
+		rel := filepath.Join(cat, id+".md")
+		if err := os.Remove(filepath.Join(s.baseDir, rel)); err == nil {
+			if err := s.recordChange(ctx, "delete", rel, nil); err != nil {
+				return err
+			}
+		}
 	}
 	s.indexDirty = true
 	return nil
@@ -356,8 +388,24 @@ func (s *FileMemoryStore) InitSchema(ctx context.Context) error {
 	return nil
 }
 
+// ConfigureBank records the bank configuration in banks.json
 func (s *FileMemoryStore) ConfigureBank(ctx context.Context, sessionID string, config *domain.MemoryBankConfig) error {
-	return nil
+	if config == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	banks, err := s.readBankConfigs()
+	if err != nil {
+		return err
+	}
+	banks[sessionID] = config
+	data, err := json.MarshalIndent(banks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.banksFilePath(), data, 0644)
 }
 
 func (s *FileMemoryStore) Reflect(ctx context.Context, sessionID string) (string, error) {
@@ -480,6 +528,10 @@ Output valid JSON only:
 		return "", fmt.Errorf("failed to parse reflection result: %w", err)
 	}
 
+	// Commit everything the reflection writes as one change
+	batch := &gitBatch{}
+	ctx = withGitBatch(ctx, batch)
+
 	created, updated := 0, 0
 	for _, obs := range parsed.Observations {
 		if obs.Content == "" || len(obs.EvidenceIDs) < 2 {
@@ -515,15 +567,20 @@ Output valid JSON only:
 		}
 	}
 
-	return fmt.Sprintf("Reflection complete: %d new observations, %d updated from %d facts.", created, updated, len(newFacts)), nil
+	summary := fmt.Sprintf("Reflection complete: %d new observations, %d updated from %d facts.", created, updated, len(newFacts))
+	if err := s.commitBatch(ctx, "reflect", "reflect: "+summary, batch); err != nil {
+		return summary, fmt.Errorf("memory reflected but git commit failed: %w", err)
+	}
+	return summary, nil
 }
 
 func (s *FileMemoryStore) AddMentalModel(ctx context.Context, model *domain.MentalModel) error {
 	m := &domain.Memory{
 		ID:         model.ID,
 		Type:       domain.MemoryTypePattern,
-		Content:    fmt.Sprintf("Mental Model: %s\n%s", model.Name, model.Content),
+		Content:    mentalModelMemoryContent(model),
 		Importance: 1.0,
+		Metadata:   mentalModelMetadata(model),
 		CreatedAt:  time.Now(),
 	}
 	return s.Store(ctx, m)
@@ -605,6 +662,19 @@ func IsStale(m *domain.Memory) bool {
 	return m.ValidTo != nil || m.SupersededBy != ""
 }
 
+// MetaArchivedAt is the metadata key set when maintenance archives a memory
+// whose decayed score fell below the retention threshold
+const MetaArchivedAt = "archived_at"
+
+// IsArchived returns true if the memory was archived by maintenance.
+func IsArchived(m *domain.Memory) bool {
+	if m.Metadata == nil {
+		return false
+	}
+	v, ok := m.Metadata[MetaArchivedAt]
+	return ok && fmt.Sprint(v) != ""
+}
+
 // indexDir returns the path to the _index/ directory
 func (s *FileMemoryStore) indexDir() string {
 	return filepath.Join(s.baseDir, "_index")
@@ -627,6 +697,35 @@ func (s *FileMemoryStore) RebuildIndex(ctx context.Context) error {
 	return nil
 }
 
+// CompactIndex rewrites the index files without archived entries and removes
+// temp files left behind by interrupted writes. Returns the number of index
+// entries dropped.
+func (s *FileMemoryStore) CompactIndex(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, _ := s.readIndexFiles()
+	if err := s.rebuildIndex(ctx); err != nil {
+		return 0, err
+	}
+	s.indexDirty = false
+
+	leftovers, _ := filepath.Glob(filepath.Join(s.indexDir(), ".index-*.tmp"))
+	for _, f := range leftovers {
+		_ = os.Remove(f)
+	}
+
+	after, err := s.readIndexFiles()
+	if err != nil {
+		return 0, err
+	}
+	removed := len(before.Entries) - len(after.Entries)
+	if removed < 0 {
+		removed = 0
+	}
+	return removed, nil
+}
+
 // ReadIndex returns the merged memory index across all type files.
 // Rebuilds if dirty or missing.
 func (s *FileMemoryStore) ReadIndex(ctx context.Context) (*MemoryIndex, error) {
@@ -686,7 +785,7 @@ func (s *FileMemoryStore) rebuildIndex(ctx context.Context) error {
 		files, _ := filepath.Glob(filepath.Join(s.baseDir, cat, "*.md"))
 		for _, f := range files {
 			m, err := s.readFile(f)
-			if err != nil {
+			if err != nil || IsArchived(m) {
 				continue
 			}
 			groups[m.Type] = append(groups[m.Type], MemoryIndexEntry{
@@ -0,0 +1,468 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// ErrGitNotEnabled is returned by history operations on a store without git
+var ErrGitNotEnabled = errors.New("git versioning is not enabled for this memory store")
+
+// Commit message trailers written by the versioned store
+const (
+	gitTrailerActor     = "Actor"
+	gitTrailerReason    = "Reason"
+	gitTrailerOperation = "Operation"
+	gitTrailerMemoryID  = "Memory-ID"
+)
+
+// gitignore keeps derived and side-car files out of history; only memory
+// files under streams/ and entities/ are versioned
+const memoryGitignore = "_index/\n*.tmp\n*.json\n*.jsonl\n"
+
+// MemoryCommit is one entry of the memory history
+type MemoryCommit struct {
+	Hash      string    `json:"hash"`
+	Subject   string    `json:"subject"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason,omitempty"`
+	Operation string    `json:"operation"`
+	MemoryIDs []string  `json:"memory_ids,omitempty"`
+	When      time.Time `json:"when"`
+}
+
+type memoryChangeKey struct{}
+
+// memoryChange attributes a write. batch collects the files of a multi-step
+// operation (Reflect) into one commit; skip leaves the write uncommitted.
+type memoryChange struct {
+	actor  string
+	reason string
+	batch  *gitBatch
+	skip   bool
+}
+
+type gitBatch struct {
+	changes []gitChange
+}
+
+type gitChange struct {
+	op       string
+	path     string // relative to baseDir
+	memoryID string
+	memType  domain.MemoryType
+	content  string
+}
+
+// WithMemoryChange attributes memory writes made with ctx to actor, for the
+// reason given. Without it the actor is the memory principal on ctx, or
+// "system".
+func WithMemoryChange(ctx context.Context, actor, reason string) context.Context {
+	change := memoryChangeFrom(ctx)
+	change.actor, change.reason = actor, reason
+	return context.WithValue(ctx, memoryChangeKey{}, &change)
+}
+
+func memoryChangeFrom(ctx context.Context) memoryChange {
+	if ctx != nil {
+		if c, ok := ctx.Value(memoryChangeKey{}).(*memoryChange); ok {
+			return *c
+		}
+	}
+	return memoryChange{}
+}
+
+func withGitBatch(ctx context.Context, b *gitBatch) context.Context {
+	change := memoryChangeFrom(ctx)
+	change.batch = b
+	return context.WithValue(ctx, memoryChangeKey{}, &change)
+}
+
+func withoutGitCommit(ctx context.Context) context.Context {
+	change := memoryChangeFrom(ctx)
+	change.skip = true
+	return context.WithValue(ctx, memoryChangeKey{}, &change)
+}
+
+func (c memoryChange) actorFor(ctx context.Context) string {
+	if c.actor != "" {
+		return c.actor
+	}
+	return domain.MemoryPrincipalFromContext(ctx).String()
+}
+
+// EnableGit versions the memory files in a git repository at the store's
+// base directory, creating it if needed. Every Store, Update, Delete and
+// Reflect then commits with a message naming the actor and reason.
+func (s *FileMemoryStore) EnableGit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo != nil {
+		return nil
+	}
+
+	repo, err := git.PlainOpen(s.baseDir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = git.PlainInit(s.baseDir, false)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open memory git repository: %w", err)
+	}
+	s.repo = repo
+
+	// Record memories written before versioning was turned on
+	if _, err := repo.Head(); errors.Is(err, plumbing.ErrReferenceNotFound) {
+		ignorePath := filepath.Join(s.baseDir, ".gitignore")
+		if _, err := os.Stat(ignorePath); os.IsNotExist(err) {
+			if err := os.WriteFile(ignorePath, []byte(memoryGitignore), 0644); err != nil {
+				return err
+			}
+		}
+		changes := []gitChange{{op: "init", path: ".gitignore"}}
+		for _, cat := range []string{"streams", "entities"} {
+			files, _ := filepath.Glob(filepath.Join(s.baseDir, cat, "*.md"))
+			for _, f := range files {
+				changes = append(changes, gitChange{op: "init", path: filepath.Join(cat, filepath.Base(f))})
+			}
+		}
+		ctx := WithMemoryChange(context.Background(), "system", "enable git versioning")
+		if _, err := s.commitLocked(ctx, "init", "import existing memories", changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GitEnabled reports whether writes are committed to git
+func (s *FileMemoryStore) GitEnabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.repo != nil
+}
+
+// recordChange commits a written or removed memory file, or adds it to the
+// batch on ctx. Caller must hold s.mu.
+func (s *FileMemoryStore) recordChange(ctx context.Context, op, path string, m *domain.Memory) error {
+	change := memoryChangeFrom(ctx)
+	if s.repo == nil || change.skip {
+		return nil
+	}
+	gc := gitChange{op: op, path: path}
+	if m != nil {
+		gc.memoryID, gc.memType, gc.content = m.ID, m.Type, m.Content
+	} else {
+		gc.memoryID = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
+	if change.batch != nil {
+		change.batch.changes = append(change.batch.changes, gc)
+		return nil
+	}
+	if _, err := s.commitLocked(ctx, op, "", []gitChange{gc}); err != nil {
+		return fmt.Errorf("memory written but git commit failed: %w", err)
+	}
+	return nil
+}
+
+// commitBatch commits the files a batch collected as one operation
+func (s *FileMemoryStore) commitBatch(ctx context.Context, op, subject string, b *gitBatch) error {
+	if len(b.changes) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.commitLocked(ctx, op, subject, b.changes)
+	return err
+}
+
+// commitLocked stages changes and commits them. It returns a nil commit
+// when nothing actually changed. Caller must hold s.mu.
+func (s *FileMemoryStore) commitLocked(ctx context.Context, op, subject string, changes []gitChange) (*MemoryCommit, error) {
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, c := range changes {
+		rel := filepath.ToSlash(c.path)
+		if _, err := os.Stat(filepath.Join(s.baseDir, c.path)); os.IsNotExist(err) {
+			// Removing a file that was never committed is not an error
+			_, _ = wt.Remove(rel)
+		} else if err := wt.AddWithOptions(&git.AddOptions{Path: rel, SkipStatus: true}); err != nil {
+			return nil, err
+		}
+		if c.memoryID != "" && !seen[c.memoryID] {
+			seen[c.memoryID] = true
+			ids = append(ids, c.memoryID)
+		}
+	}
+
+	if subject == "" {
+		subject = changeSubject(op, changes)
+	}
+	change := memoryChangeFrom(ctx)
+	actor := change.actorFor(ctx)
+	reason := change.reason
+
+	var msg strings.Builder
+	msg.WriteString(subject)
+	msg.WriteString("\n\n")
+	fmt.Fprintf(&msg, "%s: %s\n", gitTrailerActor, actor)
+	if reason != "" {
+		fmt.Fprintf(&msg, "%s: %s\n", gitTrailerReason, oneLine(reason))
+	}
+	fmt.Fprintf(&msg, "%s: %s\n", gitTrailerOperation, op)
+	for _, id := range ids {
+		fmt.Fprintf(&msg, "%s: %s\n", gitTrailerMemoryID, id)
+	}
+
+	now := time.Now()
+	hash, err := wt.Commit(msg.String(), &git.CommitOptions{
+		Author: &object.Signature{Name: actor, Email: "agentgo@localhost", When: now},
+	})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryCommit{Hash: hash.String(), Subject: subject, Actor: actor, Reason: reason, Operation: op, MemoryIDs: ids, When: now}, nil
+}
+
+func changeSubject(op string, changes []gitChange) string {
+	if len(changes) != 1 {
+		return fmt.Sprintf("%s %d memories", op, len(changes))
+	}
+	c := changes[0]
+	id := c.memoryID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	if c.content == "" {
+		return fmt.Sprintf("%s %s", op, id)
+	}
+	return fmt.Sprintf("%s %s %s: %s", op, c.memType, id, truncate(oneLine(c.content), 60))
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Log returns the memory history, newest first. memoryID limits it to
+// commits touching that memory; limit <= 0 returns everything.
+func (s *FileMemoryStore) Log(ctx context.Context, memoryID string, limit int) ([]*MemoryCommit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.repo == nil {
+		return nil, ErrGitNotEnabled
+	}
+
+	opts := &git.LogOptions{}
+	if memoryID != "" {
+		name := memoryID + ".md"
+		opts.PathFilter = func(p string) bool { return filepath.Base(p) == name }
+	}
+	iter, err := s.repo.Log(opts)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*MemoryCommit
+	for limit <= 0 || len(out) < limit {
+		c, err := iter.Next()
+		if err != nil {
+			break
+		}
+		out = append(out, parseMemoryCommit(c))
+	}
+	return out, nil
+}
+
+func parseMemoryCommit(c *object.Commit) *MemoryCommit {
+	mc := &MemoryCommit{Hash: c.Hash.String(), Actor: c.Author.Name, When: c.Author.When}
+	lines := strings.Split(strings.TrimSpace(c.Message), "\n")
+	if len(lines) > 0 {
+		mc.Subject = lines[0]
+	}
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case gitTrailerActor:
+			mc.Actor = value
+		case gitTrailerReason:
+			mc.Reason = value
+		case gitTrailerOperation:
+			mc.Operation = value
+		case gitTrailerMemoryID:
+			mc.MemoryIDs = append(mc.MemoryIDs, value)
+		}
+	}
+	return mc
+}
+
+// Diff returns a unified diff of the memory files. With only from it shows
+// what that commit changed (default HEAD); with both it compares the two.
+func (s *FileMemoryStore) Diff(ctx context.Context, from, to string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.repo == nil {
+		return "", ErrGitNotEnabled
+	}
+	if from == "" {
+		from = "HEAD"
+	}
+
+	fromCommit, err := s.resolveCommit(from)
+	if err != nil {
+		return "", err
+	}
+	var a, b *object.Tree
+	if to == "" {
+		if b, err = fromCommit.Tree(); err != nil {
+			return "", err
+		}
+		if a, err = parentTree(fromCommit); err != nil {
+			return "", err
+		}
+	} else {
+		toCommit, err := s.resolveCommit(to)
+		if err != nil {
+			return "", err
+		}
+		if a, err = fromCommit.Tree(); err != nil {
+			return "", err
+		}
+		if b, err = toCommit.Tree(); err != nil {
+			return "", err
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, a, b, nil)
+	if err != nil {
+		return "", err
+	}
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return patch.String(), nil
+}
+
+// Revert undoes the changes a commit made to the memory files and commits
+// the result, like "git revert". Later edits to the same memories are
+// overwritten with the state before the reverted commit.
+func (s *FileMemoryStore) Revert(ctx context.Context, rev string) (*MemoryCommit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		return nil, ErrGitNotEnabled
+	}
+
+	target, err := s.resolveCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := target.Tree()
+	if err != nil {
+		return nil, err
+	}
+	before, err := parentTree(target)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, before, tree, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []gitChange
+	for _, ch := range changes {
+		if ch.From.Name == "" {
+			// Added by the commit: remove it
+			path := filepath.FromSlash(ch.To.Name)
+			if err := os.Remove(filepath.Join(s.baseDir, path)); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			reverted = append(reverted, revertChange(path))
+			continue
+		}
+		f, err := before.File(ch.From.Name)
+		if err != nil {
+			return nil, err
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.FromSlash(ch.From.Name)
+		full := filepath.Join(s.baseDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			return nil, err
+		}
+		reverted = append(reverted, revertChange(path))
+	}
+	s.indexDirty = true
+
+	original := parseMemoryCommit(target)
+	if memoryChangeFrom(ctx).reason == "" {
+		ctx = WithMemoryChange(ctx, memoryChangeFrom(ctx).actorFor(ctx), "revert "+target.Hash.String())
+	}
+	subject := fmt.Sprintf("revert %s: %s", target.Hash.String()[:8], original.Subject)
+	commit, err := s.commitLocked(ctx, "revert", subject, reverted)
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return nil, fmt.Errorf("commit %s has nothing left to revert", rev)
+	}
+	return commit, nil
+}
+
+func revertChange(path string) gitChange {
+	gc := gitChange{op: "revert", path: path}
+	if strings.HasSuffix(path, ".md") {
+		gc.memoryID = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
+	return gc
+}
+
+func (s *FileMemoryStore) resolveCommit(rev string) (*object.Commit, error) {
+	hash, err := s.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("unknown memory revision %q: %w", rev, err)
+	}
+	return s.repo.CommitObject(*hash)
+}
+
+// parentTree returns the first parent's tree, or nil for the root commit
+func parentTree(c *object.Commit) (*object.Tree, error) {
+	if c.NumParents() == 0 {
+		return nil, nil
+	}
+	parent, err := c.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+	return parent.Tree()
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+func TestFileMemoryStore_GitHistory(t *testing.T) {
+	ctx := domain.WithSystemMemoryPrincipal(context.Background())
+	dir := t.TempDir()
+	s, err := NewFileMemoryStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileMemoryStore: %v", err)
+	}
+
+	// A memory written before versioning is picked up by the first commit
+	pre := &domain.Memory{ID: "pre", Type: domain.MemoryTypeFact, Content: "Existing fact", CreatedAt: time.Now()}
+	if err := s.Store(ctx, pre); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if err := s.EnableGit(); err != nil {
+		t.Fatalf("EnableGit: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		t.Fatalf("expected a git repository: %v", err)
+	}
+
+	m := &domain.Memory{ID: "m1", Type: domain.MemoryTypePreference, Content: "User prefers tabs", CreatedAt: time.Now()}
+	if err := s.Store(WithMemoryChange(ctx, "alice", "told us in chat"), m); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if err := s.IncrementAccess(ctx, "m1"); err != nil {
+		t.Fatalf("IncrementAccess: %v", err)
+	}
+	m.Content = "User prefers spaces"
+	if err := s.Update(WithMemoryChange(ctx, "bob", "corrected"), m); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	log, err := s.Log(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(log) != 3 {
+		t.Fatalf("log has %d commits, want 3 (init, store, update; access is not committed): %+v", len(log), log)
+	}
+	update, store := log[0], log[1]
+	if update.Operation != "update" || update.Actor != "bob" || update.Reason != "corrected" {
+		t.Fatalf("unexpected update commit: %+v", update)
+	}
+	if store.Operation != "store" || store.Actor != "alice" || len(store.MemoryIDs) != 1 || store.MemoryIDs[0] != "m1" {
+		t.Fatalf("unexpected store commit: %+v", store)
+	}
+	if log[2].Operation != "init" {
+		t.Fatalf("first commit = %+v, want init", log[2])
+	}
+
+	filtered, err := s.Log(ctx, "pre", 0)
+	if err != nil {
+		t.Fatalf("Log(pre): %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Operation != "init" {
+		t.Fatalf("Log(pre) = %+v, want only the init commit", filtered)
+	}
+
+	diff, err := s.Diff(ctx, "", "")
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !strings.Contains(diff, "-User prefers tabs") || !strings.Contains(diff, "+User prefers spaces") {
+		t.Fatalf("diff does not show the update:\n%s", diff)
+	}
+
+	// Reverting the update restores the earlier content
+	reverted, err := s.Revert(WithMemoryChange(ctx, "carol", ""), update.Hash)
+	if err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if reverted.Operation != "revert" || reverted.Actor != "carol" {
+		t.Fatalf("unexpected revert commit: %+v", reverted)
+	}
+	got, err := s.Get(ctx, "m1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Content != "User prefers tabs" {
+		t.Fatalf("content after revert = %q", got.Content)
+	}
+
+	// Reverting the store removes the memory again; deletes are committed too
+	if _, err := s.Revert(ctx, store.Hash); err != nil {
+		t.Fatalf("Revert store: %v", err)
+	}
+	if _, err := s.Get(ctx, "m1"); err == nil {
+		t.Fatal("memory should be gone after reverting its creation")
+	}
+	if err := s.Delete(ctx, "pre"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	log, _ = s.Log(ctx, "", 1)
+	if len(log) != 1 || log[0].Operation != "delete" || log[0].Actor != "system" {
+		t.Fatalf("latest commit = %+v, want a delete by system", log)
+	}
+}
+
+func TestFileMemoryStore_GitNotEnabled(t *testing.T) {
+	s, err := NewFileMemoryStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileMemoryStore: %v", err)
+	}
+	if s.GitEnabled() {
+		t.Fatal("git should be off by default")
+	}
+	if _, err := s.Log(context.Background(), "", 0); err != ErrGitNotEnabled {
+		t.Fatalf("Log err = %v, want ErrGitNotEnabled", err)
+	}
+}
@@ -212,3 +212,60 @@ func TestFileMemoryStoreIndexAndHelpers(t *testing.T) {
 		t.Fatal("expected invalid markdown format error")
 	}
 }
+
+func TestFileMemoryStoreTemporalQueries(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileMemoryStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new file memory store failed: %v", err)
+	}
+
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Store(ctx, &domain.Memory{ID: "deploy-v1", Type: domain.MemoryTypeFact, Content: "Deploy target is staging", CreatedAt: jan, ValidFrom: jan}); err != nil {
+		t.Fatalf("store v1 failed: %v", err)
+	}
+	if err := store.Store(ctx, &domain.Memory{ID: "deploy-v2", Type: domain.MemoryTypeFact, Content: "Deploy target is production", CreatedAt: mar, ValidFrom: mar}); err != nil {
+		t.Fatalf("store v2 failed: %v", err)
+	}
+	if err := store.MarkStale(ctx, "deploy-v1", "deploy-v2"); err != nil {
+		t.Fatalf("mark stale failed: %v", err)
+	}
+	// MarkStale stamps ValidTo with the current time; pin it to the handover
+	v1, _ := store.Get(ctx, "deploy-v1")
+	v1.ValidTo = &mar
+	if err := store.Update(ctx, v1); err != nil {
+		t.Fatalf("update v1 failed: %v", err)
+	}
+
+	feb := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	hits, err := store.SearchTemporal(ctx, &domain.MemoryTemporalQuery{Text: "deploy target", AsOf: feb})
+	if err != nil || len(hits) != 1 || hits[0].ID != "deploy-v1" {
+		t.Fatalf("unexpected as-of result: %v %+v", err, hits)
+	}
+
+	hits, _ = store.SearchTemporal(ctx, &domain.MemoryTemporalQuery{AsOf: mar.Add(time.Hour)})
+	if len(hits) != 1 || hits[0].ID != "deploy-v2" {
+		t.Fatalf("expected only the newer version after handover, got %+v", hits)
+	}
+
+	hits, _ = store.SearchTemporal(ctx, &domain.MemoryTemporalQuery{From: feb, To: mar.Add(time.Hour)})
+	if len(hits) != 2 || hits[0].ID != "deploy-v2" {
+		t.Fatalf("expected both versions in range, newest first, got %+v", hits)
+	}
+
+	hits, _ = store.SearchTemporal(ctx, &domain.MemoryTemporalQuery{AsOf: jan.Add(-time.Hour)})
+	if len(hits) != 0 {
+		t.Fatalf("expected nothing before the first memory, got %+v", hits)
+	}
+
+	for _, id := range []string{"deploy-v1", "deploy-v2"} {
+		history, err := store.History(ctx, id)
+		if err != nil || len(history) != 2 || history[0].ID != "deploy-v1" || history[1].ID != "deploy-v2" {
+			t.Fatalf("unexpected history from %s: %v %+v", id, err, history)
+		}
+	}
+	if _, err := store.History(ctx, "missing"); err == nil {
+		t.Fatal("expected error for unknown memory")
+	}
+}
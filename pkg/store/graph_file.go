@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// FileGraphStore is a small JSON-file graph implementing domain.GraphStore,
+// domain.GraphReader and domain.GraphDeleter. It backs the memory entity graph for file-based
+// memory when no shared RAG graph is configured.
+type FileGraphStore struct {
+	mu    sync.RWMutex
+	path  string
+	nodes map[string]domain.GraphNode
+	edges map[string]domain.GraphEdge
+}
+
+type fileGraph struct {
+	Nodes []domain.GraphNode `json:"nodes"`
+	Edges []domain.GraphEdge `json:"edges"`
+}
+
+// NewFileGraphStore opens (or creates) the graph file at path
+func NewFileGraphStore(path string) (*FileGraphStore, error) {
+	g := &FileGraphStore{
+		path:  path,
+		nodes: make(map[string]domain.GraphNode),
+		edges: make(map[string]domain.GraphEdge),
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fg fileGraph
+	if err := json.Unmarshal(data, &fg); err != nil {
+		return nil, fmt.Errorf("failed to parse graph file %s: %w", path, err)
+	}
+	for _, n := range fg.Nodes {
+		g.nodes[n.ID] = n
+	}
+	for _, e := range fg.Edges {
+		g.edges[e.ID] = e
+	}
+	return g, nil
+}
+
+// UpsertNode implements domain.GraphStore
+func (g *FileGraphStore) UpsertNode(ctx context.Context, node domain.GraphNode) error {
+	if node.ID == "" {
+		return fmt.Errorf("graph node requires an ID")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nodes[node.ID] = node
+	return g.save()
+}
+
+// UpsertEdge implements domain.GraphStore
+func (g *FileGraphStore) UpsertEdge(ctx context.Context, edge domain.GraphEdge) error {
+	if edge.ID == "" {
+		return fmt.Errorf("graph edge requires an ID")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.edges[edge.ID] = edge
+	return g.save()
+}
+
+// DeleteNode implements domain.GraphDeleter
+func (g *FileGraphStore) DeleteNode(ctx context.Context, id string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.nodes[id]; !ok {
+		return fmt.Errorf("node not found: %s", id)
+	}
+	delete(g.nodes, id)
+	for edgeID, e := range g.edges {
+		if e.FromNodeID == id || e.ToNodeID == id {
+			delete(g.edges, edgeID)
+		}
+	}
+	return g.save()
+}
+
+// DeleteEdge implements domain.GraphDeleter
+func (g *FileGraphStore) DeleteEdge(ctx context.Context, id string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.edges[id]; !ok {
+		return fmt.Errorf("edge not found: %s", id)
+	}
+	delete(g.edges, id)
+	return g.save()
+}
+
+// HybridSearch ranks nodes by vector similarity; with startNodeID only the
+// node's direct neighbours are considered
+func (g *FileGraphStore) HybridSearch(ctx context.Context, vector []float64, startNodeID string, topK int) ([]domain.HybridSearchResult, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	candidates := make(map[string]bool)
+	if startNodeID != "" {
+		for _, e := range g.edges {
+			if e.FromNodeID == startNodeID {
+				candidates[e.ToNodeID] = true
+			} else if e.ToNodeID == startNodeID {
+				candidates[e.FromNodeID] = true
+			}
+		}
+	} else {
+		for id := range g.nodes {
+			candidates[id] = true
+		}
+	}
+
+	var results []domain.HybridSearchResult
+	for id := range candidates {
+		n, ok := g.nodes[id]
+		if !ok {
+			continue
+		}
+		score := vectorSimilarity(vector, n.Vector)
+		graphScore := 0.0
+		if startNodeID != "" {
+			graphScore = 1.0
+		}
+		node := n
+		results = append(results, domain.HybridSearchResult{
+			Node:        &node,
+			Score:       score + graphScore,
+			VectorScore: score,
+			GraphScore:  graphScore,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// InitGraphSchema implements domain.GraphStore
+func (g *FileGraphStore) InitGraphSchema(ctx context.Context) error {
+	return os.MkdirAll(filepath.Dir(g.path), 0755)
+}
+
+// GetNode implements domain.GraphReader
+func (g *FileGraphStore) GetNode(ctx context.Context, id string) (*domain.GraphNode, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	n, ok := g.nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("node not found: %s", id)
+	}
+	return &n, nil
+}
+
+// GetEdges implements domain.GraphReader
+func (g *FileGraphStore) GetEdges(ctx context.Context, nodeID string, direction string) ([]domain.GraphEdge, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var edges []domain.GraphEdge
+	for _, e := range g.edges {
+		out := e.FromNodeID == nodeID
+		in := e.ToNodeID == nodeID
+		switch direction {
+		case "out":
+			if out {
+				edges = append(edges, e)
+			}
+		case "in":
+			if in {
+				edges = append(edges, e)
+			}
+		case "both", "":
+			if out || in {
+				edges = append(edges, e)
+			}
+		default:
+			return nil, fmt.Errorf("invalid direction: %s (use 'in', 'out', or 'both')", direction)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	return edges, nil
+}
+
+// ListNodes implements domain.GraphReader
+func (g *FileGraphStore) ListNodes(ctx context.Context, nodeType string) ([]domain.GraphNode, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var nodes []domain.GraphNode
+	for _, n := range g.nodes {
+		if nodeType == "" || n.NodeType == nodeType {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// save writes the graph atomically. Caller must hold g.mu.
+func (g *FileGraphStore) save() error {
+	fg := fileGraph{
+		Nodes: make([]domain.GraphNode, 0, len(g.nodes)),
+		Edges: make([]domain.GraphEdge, 0, len(g.edges)),
+	}
+	for _, n := range g.nodes {
+		fg.Nodes = append(fg.Nodes, n)
+	}
+	for _, e := range g.edges {
+		fg.Edges = append(fg.Edges, e)
+	}
+	sort.Slice(fg.Nodes, func(i, j int) bool { return fg.Nodes[i].ID < fg.Nodes[j].ID })
+	sort.Slice(fg.Edges, func(i, j int) bool { return fg.Edges[i].ID < fg.Edges[j].ID })
+
+	data, err := json.MarshalIndent(fg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), 0755); err != nil {
+		return err
+	}
+	tmp := g.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.path)
+}
+
+func vectorSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+var (
+	_ domain.GraphStore  = (*FileGraphStore)(nil)
+	_ domain.GraphReader = (*FileGraphStore)(nil)
+)
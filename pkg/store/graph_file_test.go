@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+func TestFileGraphStore_PersistsAndTraverses(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "graph", "graph.json")
+
+	g, err := NewFileGraphStore(path)
+	if err != nil {
+		t.Fatalf("NewFileGraphStore: %v", err)
+	}
+	if err := g.InitGraphSchema(ctx); err != nil {
+		t.Fatalf("InitGraphSchema: %v", err)
+	}
+	for _, n := range []domain.GraphNode{
+		{ID: "a", NodeType: "person", Properties: map[string]interface{}{"aliases": []string{"al"}}},
+		{ID: "b", NodeType: "project"},
+		{ID: "c", NodeType: "project"},
+	} {
+		if err := g.UpsertNode(ctx, n); err != nil {
+			t.Fatalf("UpsertNode: %v", err)
+		}
+	}
+	if err := g.UpsertEdge(ctx, domain.GraphEdge{ID: "a-b", FromNodeID: "a", ToNodeID: "b", EdgeType: "owns"}); err != nil {
+		t.Fatalf("UpsertEdge: %v", err)
+	}
+	if err := g.UpsertEdge(ctx, domain.GraphEdge{ID: "c-a", FromNodeID: "c", ToNodeID: "a", EdgeType: "led_by"}); err != nil {
+		t.Fatalf("UpsertEdge: %v", err)
+	}
+
+	// Reopen to check everything was written to disk
+	g, err = NewFileGraphStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	node, err := g.GetNode(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if aliases, _ := node.Properties["aliases"].([]interface{}); len(aliases) != 1 || aliases[0] != "al" {
+		t.Errorf("aliases = %#v, want [al]", node.Properties["aliases"])
+	}
+	if _, err := g.GetNode(ctx, "missing"); err == nil {
+		t.Error("GetNode(missing) should fail")
+	}
+
+	for dir, want := range map[string]int{"out": 1, "in": 1, "both": 2} {
+		edges, err := g.GetEdges(ctx, "a", dir)
+		if err != nil {
+			t.Fatalf("GetEdges(%s): %v", dir, err)
+		}
+		if len(edges) != want {
+			t.Errorf("GetEdges(%s) = %d edges, want %d", dir, len(edges), want)
+		}
+	}
+	if _, err := g.GetEdges(ctx, "a", "sideways"); err == nil {
+		t.Error("GetEdges with an invalid direction should fail")
+	}
+
+	projects, err := g.ListNodes(ctx, "project")
+	if err != nil {
+		t.Fatalf("ListNodes: %v", err)
+	}
+	if len(projects) != 2 || projects[0].ID != "b" || projects[1].ID != "c" {
+		t.Errorf("ListNodes(project) = %v, want [b c]", projects)
+	}
+
+	neighbours, err := g.HybridSearch(ctx, nil, "a", 10)
+	if err != nil {
+		t.Fatalf("HybridSearch: %v", err)
+	}
+	if len(neighbours) != 2 {
+		t.Errorf("HybridSearch from a = %d results, want 2", len(neighbours))
+	}
+}
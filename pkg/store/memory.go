@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -58,13 +59,14 @@ func NewMemoryStore(dbPath string) (*MemoryStore, error) {
 func (s *MemoryStore) Store(ctx context.Context, memory *domain.Memory) error {
 	bankID := memory.SessionID
 	if bankID == "" {
-		bankID = "default"
+		bankID = defaultBankID
 	}
 
 	if memory.Metadata == nil {
 		memory.Metadata = make(map[string]interface{})
 	}
 	memory.Metadata["memory_type"] = string(memory.Type)
+	setHindsightMetadata(memory)
 
 	hMem := &hindsight.Memory{
 		ID:         memory.ID,
@@ -202,6 +204,7 @@ func (s *MemoryStore) StoreWithScope(ctx context.Context, memory *domain.Memory,
 		memory.Metadata = make(map[string]interface{})
 	}
 	memory.Metadata["memory_type"] = string(memory.Type)
+	setHindsightMetadata(memory)
 
 	hMem := &hindsight.Memory{
 		ID:         memory.ID,
@@ -259,30 +262,67 @@ func (s *MemoryStore) SearchByText(ctx context.Context, query string, topK int)
 		// Calculate simple relevance score based on term frequency
 		score := calculateTextScore(query, content)
 
-		results = append(results, &domain.MemoryWithScore{
-			Memory: &domain.Memory{
-				ID:        id,
-				Content:   content,
-				Metadata:  metadata,
-				SessionID: bankID,
-				Type:      domain.MemoryType(memType),
-				CreatedAt: createdAt,
-			},
-			Score: score,
-		})
+		mem := &domain.Memory{
+			ID:        id,
+			Content:   content,
+			Metadata:  metadata,
+			SessionID: bankID,
+			Type:      domain.MemoryType(memType),
+			CreatedAt: createdAt,
+		}
+		applyHindsightMetadata(mem)
+		results = append(results, &domain.MemoryWithScore{Memory: mem, Score: score})
 	}
 
 	return results, nil
 }
 
+// SearchTemporal returns memories valid at query.AsOf or during [From, To],
+// including superseded versions
+func (s *MemoryStore) SearchTemporal(ctx context.Context, query *domain.MemoryTemporalQuery) ([]*domain.MemoryWithScore, error) {
+	all, _, err := s.List(ctx, 10000, 0)
+	if err != nil {
+		return nil, err
+	}
+	return filterTemporal(all, query), nil
+}
+
+// History returns all versions of a memory linked through SupersededBy
+func (s *MemoryStore) History(ctx context.Context, id string) ([]*domain.Memory, error) {
+	all, _, err := s.List(ctx, 10000, 0)
+	if err != nil {
+		return nil, err
+	}
+	return memoryHistory(all, id)
+}
+
+// MarkStale marks a memory as superseded by a newer one, closing its
+// validity window and recording a revision
+func (s *MemoryStore) MarkStale(ctx context.Context, id string, supersededByID string) error {
+	m, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	m.ValidTo = &now
+	m.SupersededBy = supersededByID
+	m.UpdatedAt = now
+	m.RevisionHistory = append(m.RevisionHistory, domain.MemoryRevision{
+		At:      now,
+		By:      "reflect",
+		Summary: fmt.Sprintf("superseded by %s", supersededByID),
+	})
+	return s.Update(ctx, m)
+}
+
 // scopeToBankID converts MemoryScope to bank ID
 func scopeToBankID(scope domain.MemoryScope) string {
 	if scope.Type == domain.MemoryScopeGlobal {
-		return "default"
+		return defaultBankID
 	}
 	if scope.Type == domain.MemoryScopeSession {
 		if scope.ID == "" {
-			return "default"
+			return defaultBankID
 		}
 		return scope.ID
 	}
@@ -411,14 +451,16 @@ func (s *MemoryStore) List(ctx context.Context, limit, offset int) ([]*domain.Me
 				memType = mt
 			}
 
-			allMems = append(allMems, &domain.Memory{
+			mem := &domain.Memory{
 				ID:        id,
 				Content:   content,
 				Metadata:  metadata,
 				SessionID: bankID,
 				Type:      domain.MemoryType(memType),
 				CreatedAt: createdAt,
-			})
+			}
+			applyHindsightMetadata(mem)
+			allMems = append(allMems, mem)
 		}
 	}
 
@@ -437,13 +479,15 @@ func (s *MemoryStore) InitSchema(ctx context.Context) error {
 	return nil
 }
 
+// ConfigureBank creates the bank, replacing the configuration of an
+// existing one
 func (s *MemoryStore) ConfigureBank(ctx context.Context, bankID string, config *domain.MemoryBankConfig) error {
-	bank := hindsight.NewBank(bankID, bankID)
-	bank.Description = config.Mission
-	bank.Disposition.Skepticism = config.Skepticism
-	bank.Disposition.Literalism = config.Literalism
-	bank.Disposition.Empathy = config.Empathy
-	return s.sys.CreateBank(ctx, bank)
+	if _, exists := s.sys.GetBank(bankID); exists {
+		if err := s.sys.DeleteBank(bankID); err != nil {
+			return err
+		}
+	}
+	return s.sys.CreateBank(ctx, newHindsightBank(bankID, config))
 }
 
 func (s *MemoryStore) Reflect(ctx context.Context, bankID string) (string, error) {
@@ -459,20 +503,19 @@ func (s *MemoryStore) Reflect(ctx context.Context, bankID string) (string, error
 	return resp.Context, nil
 }
 
+// AddMentalModel stores the model as a global observation. It goes through
+// Store so the memory carries the same metadata as any other.
 func (s *MemoryStore) AddMentalModel(ctx context.Context, model *domain.MentalModel) error {
-	hMem := &hindsight.Memory{
+	return s.Store(ctx, &domain.Memory{
 		ID:         model.ID,
-		BankID:     "global",
-		Type:       hindsight.ObservationMemory,
-		Content:    fmt.Sprintf("Mental Model: %s\n%s", model.Name, model.Content),
+		SessionID:  "global",
+		Type:       domain.MemoryTypeObservation,
+		Content:    mentalModelMemoryContent(model),
+		Importance: 1.0,
 		Confidence: 1.0,
-		Metadata: map[string]any{
-			"name":        model.Name,
-			"description": model.Description,
-			"tags":        model.Tags,
-		},
-	}
-	return s.sys.Retain(ctx, hMem)
+		Metadata:   mentalModelMetadata(model),
+		CreatedAt:  time.Now(),
+	})
 }
 
 func (s *MemoryStore) Close() error {
@@ -542,7 +585,7 @@ func toDomainMemory(im *Memory) *domain.Memory {
 	if im == nil {
 		return nil
 	}
-	return &domain.Memory{
+	mem := &domain.Memory{
 		ID:           im.ID,
 		SessionID:    im.SessionID,
 		Type:         domain.MemoryType(im.Type),
@@ -555,4 +598,122 @@ func toDomainMemory(im *Memory) *domain.Memory {
 		CreatedAt:    im.CreatedAt,
 		UpdatedAt:    im.UpdatedAt,
 	}
+	applyHindsightMetadata(mem)
+	return mem
+}
+
+// Hindsight has no columns for the temporal and evidence fields, so they
+// travel in metadata as strings and are restored onto domain.Memory when read
+// back.
+const (
+	metaCreatedAt       = "created_at"
+	metaImportance      = "importance"
+	metaAccessCount     = "access_count"
+	metaLastAccessed    = "last_accessed"
+	metaUpdatedAt       = "updated_at"
+	metaSourceType      = "source_type"
+	metaValidFrom       = "valid_from"
+	metaValidTo         = "valid_to"
+	metaSupersededBy    = "superseded_by"
+	metaRevisionHistory = "revision_history"
+	metaEvidenceIDs     = "evidence_ids"
+	metaConfidence      = "confidence_score"
+	metaConflicting     = "conflicting"
+)
+
+// defaultBankID holds global memories, which have no session
+const defaultBankID = "default"
+
+func setHindsightMetadata(memory *domain.Memory) {
+	memory.Metadata[metaImportance] = strconv.FormatFloat(memory.Importance, 'f', -1, 64)
+	if memory.AccessCount > 0 {
+		memory.Metadata[metaAccessCount] = strconv.Itoa(memory.AccessCount)
+	}
+	if !memory.LastAccessed.IsZero() {
+		memory.Metadata[metaLastAccessed] = memory.LastAccessed.Format(time.RFC3339Nano)
+	}
+	if !memory.UpdatedAt.IsZero() {
+		memory.Metadata[metaUpdatedAt] = memory.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	if memory.SourceType != "" {
+		memory.Metadata[metaSourceType] = string(memory.SourceType)
+	}
+	if !memory.ValidFrom.IsZero() {
+		memory.Metadata[metaValidFrom] = memory.ValidFrom.Format(time.RFC3339Nano)
+	}
+	if memory.ValidTo != nil {
+		memory.Metadata[metaValidTo] = memory.ValidTo.Format(time.RFC3339Nano)
+	}
+	if memory.SupersededBy != "" {
+		memory.Metadata[metaSupersededBy] = memory.SupersededBy
+	}
+	if len(memory.RevisionHistory) > 0 {
+		if data, err := json.Marshal(memory.RevisionHistory); err == nil {
+			memory.Metadata[metaRevisionHistory] = string(data)
+		}
+	}
+	if len(memory.EvidenceIDs) > 0 {
+		memory.Metadata[metaEvidenceIDs] = strings.Join(memory.EvidenceIDs, ",")
+	}
+	if memory.Confidence > 0 {
+		memory.Metadata[metaConfidence] = strconv.FormatFloat(memory.Confidence, 'f', -1, 64)
+	}
+	if memory.Conflicting {
+		memory.Metadata[metaConflicting] = "true"
+	}
+}
+
+func applyHindsightMetadata(mem *domain.Memory) {
+	if mem.Metadata == nil {
+		return
+	}
+	get := func(key string) string {
+		v, ok := mem.Metadata[key]
+		if !ok || v == nil {
+			return ""
+		}
+		delete(mem.Metadata, key)
+		return fmt.Sprint(v)
+	}
+
+	// The embeddings.created_at column is reset on every upsert; the
+	// original creation time is kept in metadata as unix seconds
+	if secs, err := strconv.ParseInt(fmt.Sprint(mem.Metadata[metaCreatedAt]), 10, 64); err == nil && secs > 0 {
+		mem.CreatedAt = time.Unix(secs, 0)
+	}
+	if mem.SessionID == defaultBankID {
+		mem.SessionID = ""
+	}
+	if v, err := strconv.ParseFloat(get(metaImportance), 64); err == nil {
+		mem.Importance = v
+	}
+	if n, err := strconv.Atoi(get(metaAccessCount)); err == nil {
+		mem.AccessCount = n
+	}
+	if t, err := time.Parse(time.RFC3339Nano, get(metaLastAccessed)); err == nil {
+		mem.LastAccessed = t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, get(metaUpdatedAt)); err == nil {
+		mem.UpdatedAt = t
+	}
+	if st := get(metaSourceType); st != "" {
+		mem.SourceType = domain.MemorySourceType(st)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, get(metaValidFrom)); err == nil {
+		mem.ValidFrom = t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, get(metaValidTo)); err == nil {
+		mem.ValidTo = &t
+	}
+	mem.SupersededBy = get(metaSupersededBy)
+	if raw := get(metaRevisionHistory); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &mem.RevisionHistory)
+	}
+	if raw := get(metaEvidenceIDs); raw != "" {
+		mem.EvidenceIDs = strings.Split(raw, ",")
+	}
+	if c, err := strconv.ParseFloat(get(metaConfidence), 64); err == nil {
+		mem.Confidence = c
+	}
+	mem.Conflicting = get(metaConflicting) == "true"
 }
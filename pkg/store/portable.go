@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+	"github.com/liliang-cn/cortexdb/v2/pkg/graph"
+	"github.com/liliang-cn/cortexdb/v2/pkg/hindsight"
+)
+
+// Mental models are stored as ordinary memories; these metadata keys mark
+// them so they can be listed and exported as mental models again
+const (
+	MetaMentalModel            = "mental_model"
+	MetaMentalModelDescription = "mental_model_description"
+	MetaMentalModelTags        = "mental_model_tags"
+)
+
+// mentalModelPrefix is prepended to a mental model's content when stored
+const mentalModelPrefix = "Mental Model: "
+
+func mentalModelMemoryContent(model *domain.MentalModel) string {
+	return fmt.Sprintf("%s%s\n%s", mentalModelPrefix, model.Name, model.Content)
+}
+
+func mentalModelMetadata(model *domain.MentalModel) map[string]interface{} {
+	meta := map[string]interface{}{
+		MetaMentalModel:            model.Name,
+		MetaMentalModelDescription: model.Description,
+	}
+	if len(model.Tags) > 0 {
+		meta[MetaMentalModelTags] = strings.Join(model.Tags, ",")
+	}
+	return meta
+}
+
+// MentalModelFromMemory reconstructs the mental model a memory was stored
+// from. Returns false for ordinary memories.
+func MentalModelFromMemory(m *domain.Memory) (*domain.MentalModel, bool) {
+	if m == nil || m.Metadata == nil {
+		return nil, false
+	}
+	name, ok := m.Metadata[MetaMentalModel]
+	if !ok {
+		return nil, false
+	}
+	model := &domain.MentalModel{
+		ID:      m.ID,
+		Name:    fmt.Sprint(name),
+		Content: strings.TrimPrefix(m.Content, mentalModelPrefix+fmt.Sprint(name)+"\n"),
+	}
+	if d, ok := m.Metadata[MetaMentalModelDescription]; ok && d != nil {
+		model.Description = fmt.Sprint(d)
+	}
+	if t, ok := m.Metadata[MetaMentalModelTags]; ok && t != nil && fmt.Sprint(t) != "" {
+		model.Tags = strings.Split(fmt.Sprint(t), ",")
+	}
+	return model, true
+}
+
+func mentalModelsFrom(all []*domain.Memory) []*domain.MentalModel {
+	var models []*domain.MentalModel
+	for _, m := range all {
+		if model, ok := MentalModelFromMemory(m); ok {
+			models = append(models, model)
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// MentalModels returns every mental model added to the store
+func (s *FileMemoryStore) MentalModels(ctx context.Context) ([]*domain.MentalModel, error) {
+	all, _, err := s.List(ctx, 100000, 0)
+	if err != nil {
+		return nil, err
+	}
+	return mentalModelsFrom(all), nil
+}
+
+// banksFilePath is where the file store keeps bank configurations
+func (s *FileMemoryStore) banksFilePath() string {
+	return filepath.Join(s.baseDir, "banks.json")
+}
+
+// BankConfigs returns the configuration of every configured bank, keyed by
+// session ID
+func (s *FileMemoryStore) BankConfigs(ctx context.Context) (map[string]*domain.MemoryBankConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.readBankConfigs()
+}
+
+// readBankConfigs loads banks.json. Caller must hold s.mu.
+func (s *FileMemoryStore) readBankConfigs() (map[string]*domain.MemoryBankConfig, error) {
+	banks := make(map[string]*domain.MemoryBankConfig)
+	data, err := os.ReadFile(s.banksFilePath())
+	if os.IsNotExist(err) {
+		return banks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &banks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.banksFilePath(), err)
+	}
+	return banks, nil
+}
+
+// MentalModels returns every mental model added to the store
+func (s *MemoryStore) MentalModels(ctx context.Context) ([]*domain.MentalModel, error) {
+	all, _, err := s.List(ctx, 100000, 0)
+	if err != nil {
+		return nil, err
+	}
+	return mentalModelsFrom(all), nil
+}
+
+// BankConfigs returns the configuration of every persisted bank, keyed by
+// bank ID. Hindsight keeps only the disposition on reload, so the full
+// config is read back from the bank's graph node.
+func (s *MemoryStore) BankConfigs(ctx context.Context) (map[string]*domain.MemoryBankConfig, error) {
+	nodes, err := s.sys.Graph().GetAllNodes(ctx, &graph.GraphFilter{NodeTypes: []string{"bank"}})
+	if err != nil {
+		return nil, err
+	}
+
+	banks := make(map[string]*domain.MemoryBankConfig, len(nodes))
+	for _, node := range nodes {
+		cfg := &domain.MemoryBankConfig{
+			Mission:    propString(node.Properties, "description"),
+			Skepticism: propInt(node.Properties, "skepticism"),
+			Literalism: propInt(node.Properties, "literalism"),
+			Empathy:    propInt(node.Properties, "empathy"),
+		}
+		if bg := propString(node.Properties, "background"); bg != "" {
+			_ = json.Unmarshal([]byte(bg), &cfg.Directives)
+		}
+		banks[node.ID] = cfg
+	}
+	return banks, nil
+}
+
+// newHindsightBank converts a bank config. Directives have no hindsight
+// field and travel as JSON in the bank background.
+func newHindsightBank(bankID string, config *domain.MemoryBankConfig) *hindsight.Bank {
+	bank := hindsight.NewBank(bankID, bankID)
+	bank.Description = config.Mission
+	bank.Disposition.Skepticism = config.Skepticism
+	bank.Disposition.Literalism = config.Literalism
+	bank.Disposition.Empathy = config.Empathy
+	if len(config.Directives) > 0 {
+		if data, err := json.Marshal(config.Directives); err == nil {
+			bank.Background = string(data)
+		}
+	}
+	return bank
+}
+
+func propString(props map[string]interface{}, key string) string {
+	v, ok := props[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func propInt(props map[string]interface{}, key string) int {
+	n, _ := strconv.ParseFloat(propString(props, key), 64)
+	return int(n)
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// ErrSuggestionNotFound is returned for unknown memory suggestion IDs
+var ErrSuggestionNotFound = errors.New("memory suggestion not found")
+
+// ErrSuggestionDecided is returned when deciding a suggestion that was
+// already accepted or rejected
+var ErrSuggestionDecided = errors.New("memory suggestion already decided")
+
+// FileSuggestionStore keeps memory suggestions in a JSON file so they can
+// be reviewed from another process (CLI, UI) than the agent that made them
+type FileSuggestionStore struct {
+	mu          sync.Mutex
+	path        string
+	suggestions map[string]*domain.MemorySuggestion
+}
+
+// NewFileSuggestionStore opens (or creates) the suggestion file at path
+func NewFileSuggestionStore(path string) (*FileSuggestionStore, error) {
+	s := &FileSuggestionStore{path: path, suggestions: make(map[string]*domain.MemorySuggestion)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load rereads the file so decisions made elsewhere are seen. Caller must
+// hold s.mu (or be the constructor).
+func (s *FileSuggestionStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var list []*domain.MemorySuggestion
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse suggestions file %s: %w", s.path, err)
+	}
+	s.suggestions = make(map[string]*domain.MemorySuggestion, len(list))
+	for _, sg := range list {
+		s.suggestions[sg.ID] = sg
+	}
+	return nil
+}
+
+// SaveSuggestion implements domain.MemorySuggestionStore
+func (s *FileSuggestionStore) SaveSuggestion(ctx context.Context, sg *domain.MemorySuggestion) error {
+	if sg.ID == "" {
+		return fmt.Errorf("memory suggestion requires an ID")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	cp := *sg
+	s.suggestions[sg.ID] = &cp
+	return s.save()
+}
+
+// GetSuggestion implements domain.MemorySuggestionStore
+func (s *FileSuggestionStore) GetSuggestion(ctx context.Context, id string) (*domain.MemorySuggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	sg, ok := s.suggestions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSuggestionNotFound, id)
+	}
+	cp := *sg
+	return &cp, nil
+}
+
+// ListSuggestions implements domain.MemorySuggestionStore
+func (s *FileSuggestionStore) ListSuggestions(ctx context.Context, status domain.MemorySuggestionStatus) ([]*domain.MemorySuggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	var out []*domain.MemorySuggestion
+	for _, sg := range s.suggestions {
+		if status == "" || sg.Status == status {
+			cp := *sg
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// DecideSuggestion implements domain.MemorySuggestionStore
+func (s *FileSuggestionStore) DecideSuggestion(ctx context.Context, sg *domain.MemorySuggestion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	current, ok := s.suggestions[sg.ID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSuggestionNotFound, sg.ID)
+	}
+	if current.Status != domain.MemorySuggestionPending {
+		return fmt.Errorf("%w: %s was %s", ErrSuggestionDecided, sg.ID, current.Status)
+	}
+	cp := *sg
+	s.suggestions[sg.ID] = &cp
+	return s.save()
+}
+
+// DeleteSuggestion implements domain.MemorySuggestionStore
+func (s *FileSuggestionStore) DeleteSuggestion(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.suggestions[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSuggestionNotFound, id)
+	}
+	delete(s.suggestions, id)
+	return s.save()
+}
+
+// save writes the suggestions atomically. Caller must hold s.mu.
+func (s *FileSuggestionStore) save() error {
+	list := make([]*domain.MemorySuggestion, 0, len(s.suggestions))
+	for _, sg := range s.suggestions {
+		list = append(list, sg)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+var _ domain.MemorySuggestionStore = (*FileSuggestionStore)(nil)
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+func TestFileSuggestionStore_SharedBetweenInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "suggestions.json")
+
+	agentSide, err := NewFileSuggestionStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSuggestionStore: %v", err)
+	}
+	now := time.Now()
+	for i, id := range []string{"b", "a"} {
+		if err := agentSide.SaveSuggestion(ctx, &domain.MemorySuggestion{
+			ID:        id,
+			Type:      domain.MemoryTypeFact,
+			Content:   "fact " + id,
+			Status:    domain.MemorySuggestionPending,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("SaveSuggestion: %v", err)
+		}
+	}
+
+	// A second process (CLI, UI) sees the agent's suggestions and its decisions
+	// are visible to the agent
+	reviewer, err := NewFileSuggestionStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	pending, err := reviewer.ListSuggestions(ctx, domain.MemorySuggestionPending)
+	if err != nil {
+		t.Fatalf("ListSuggestions: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != "b" {
+		t.Fatalf("pending = %+v, want b then a (oldest first)", pending)
+	}
+
+	pending[0].Status = domain.MemorySuggestionRejected
+	if err := reviewer.SaveSuggestion(ctx, pending[0]); err != nil {
+		t.Fatalf("SaveSuggestion: %v", err)
+	}
+	got, err := agentSide.GetSuggestion(ctx, "b")
+	if err != nil {
+		t.Fatalf("GetSuggestion: %v", err)
+	}
+	if got.Status != domain.MemorySuggestionRejected {
+		t.Fatalf("status = %s, want rejected", got.Status)
+	}
+
+	all, _ := agentSide.ListSuggestions(ctx, "")
+	if len(all) != 2 {
+		t.Fatalf("all = %d, want 2", len(all))
+	}
+
+	if err := agentSide.DeleteSuggestion(ctx, "a"); err != nil {
+		t.Fatalf("DeleteSuggestion: %v", err)
+	}
+	if _, err := reviewer.GetSuggestion(ctx, "a"); !errors.Is(err, ErrSuggestionNotFound) {
+		t.Fatalf("GetSuggestion after delete: err = %v, want ErrSuggestionNotFound", err)
+	}
+}
+
+func TestFileSuggestionStore_DecideOnce(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewFileSuggestionStore(filepath.Join(t.TempDir(), "suggestions.json"))
+	if err != nil {
+		t.Fatalf("NewFileSuggestionStore: %v", err)
+	}
+	sg := &domain.MemorySuggestion{ID: "s", Content: "fact", Status: domain.MemorySuggestionPending}
+	if err := st.SaveSuggestion(ctx, sg); err != nil {
+		t.Fatalf("SaveSuggestion: %v", err)
+	}
+
+	accepted, rejected := *sg, *sg
+	accepted.Status = domain.MemorySuggestionAccepted
+	rejected.Status = domain.MemorySuggestionRejected
+	if err := st.DecideSuggestion(ctx, &accepted); err != nil {
+		t.Fatalf("DecideSuggestion: %v", err)
+	}
+	if err := st.DecideSuggestion(ctx, &rejected); !errors.Is(err, ErrSuggestionDecided) {
+		t.Fatalf("second DecideSuggestion: err = %v, want ErrSuggestionDecided", err)
+	}
+	got, _ := st.GetSuggestion(ctx, "s")
+	if got.Status != domain.MemorySuggestionAccepted {
+		t.Fatalf("status = %s, want accepted", got.Status)
+	}
+	if err := st.DecideSuggestion(ctx, &domain.MemorySuggestion{ID: "missing"}); !errors.Is(err, ErrSuggestionNotFound) {
+		t.Fatalf("DecideSuggestion(missing): err = %v, want ErrSuggestionNotFound", err)
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/liliang-cn/agent-go/pkg/domain"
+)
+
+// ErrTemporalUnsupported is returned for temporal queries against a store
+// that does not implement domain.TemporalMemoryStore
+var ErrTemporalUnsupported = errors.New("memory store does not support temporal queries")
+
+// filterTemporal applies a temporal query to a set of memories. Text matches
+// are ranked by relevance, everything else by most recent validity.
+func filterTemporal(all []*domain.Memory, query *domain.MemoryTemporalQuery) []*domain.MemoryWithScore {
+	text := strings.ToLower(strings.TrimSpace(query.Text))
+
+	var results []*domain.MemoryWithScore
+	for _, m := range all {
+		if !query.Matches(m) {
+			continue
+		}
+		score := 1.0
+		if text != "" {
+			score = calculateTextScore(text, m.Content)
+			if score == 0 {
+				continue
+			}
+		}
+		results = append(results, &domain.MemoryWithScore{Memory: m, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].EffectiveFrom().After(results[j].EffectiveFrom())
+	})
+
+	if query.TopK > 0 && len(results) > query.TopK {
+		results = results[:query.TopK]
+	}
+	return results
+}
+
+// memoryHistory collects every memory connected to id through SupersededBy
+// links, in either direction, ordered by when each version became valid
+func memoryHistory(all []*domain.Memory, id string) ([]*domain.Memory, error) {
+	byID := make(map[string]*domain.Memory, len(all))
+	predecessors := make(map[string][]string)
+	for _, m := range all {
+		byID[m.ID] = m
+		if m.SupersededBy != "" {
+			predecessors[m.SupersededBy] = append(predecessors[m.SupersededBy], m.ID)
+		}
+	}
+	if _, ok := byID[id]; !ok {
+		return nil, ErrMemoryNotFound
+	}
+
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	var chain []*domain.Memory
+	for len(queue) > 0 {
+		current := byID[queue[0]]
+		queue = queue[1:]
+		chain = append(chain, current)
+
+		next := append([]string{current.SupersededBy}, predecessors[current.ID]...)
+		for _, nid := range next {
+			if nid == "" || seen[nid] || byID[nid] == nil {
+				continue
+			}
+			seen[nid] = true
+			queue = append(queue, nid)
+		}
+	}
+
+	sort.SliceStable(chain, func(i, j int) bool {
+		return chain[i].EffectiveFrom().Before(chain[j].EffectiveFrom())
+	})
+	return chain, nil
+}
+
+var (
+	_ domain.TemporalMemoryStore = (*MemoryStore)(nil)
+	_ domain.TemporalMemoryStore = (*FileMemoryStore)(nil)
+)
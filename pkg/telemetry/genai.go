@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// GenAI semantic-convention attribute keys
+const (
+	AttrGenAISystem         = attribute.Key("gen_ai.system")
+	AttrGenAIOperationName  = attribute.Key("gen_ai.operation.name")
+	AttrGenAIRequestModel   = attribute.Key("gen_ai.request.model")
+	AttrGenAIResponseModel  = attribute.Key("gen_ai.response.model")
+	AttrGenAIInputTokens    = attribute.Key("gen_ai.usage.input_tokens")
+	AttrGenAIOutputTokens   = attribute.Key("gen_ai.usage.output_tokens")
+	AttrGenAITokenType      = attribute.Key("gen_ai.token.type")
+	AttrGenAIToolName       = attribute.Key("gen_ai.tool.name")
+	AttrGenAIToolCallID     = attribute.Key("gen_ai.tool.call.id")
+	AttrGenAIAgentID        = attribute.Key("gen_ai.agent.id")
+	AttrGenAIAgentName      = attribute.Key("gen_ai.agent.name")
+	AttrGenAIConversationID = attribute.Key("gen_ai.conversation.id")
+	AttrGenAIDataSourceID   = attribute.Key("gen_ai.data_source.id")
+	AttrErrorType           = attribute.Key("error.type")
+)
+
+// GenAI operation names
+const (
+	OperationChat        = "chat"
+	OperationInvokeAgent = "invoke_agent"
+	OperationExecuteTool = "execute_tool"
+	OperationRetrieval   = "retrieval"
+)
+
+// Token types for the token usage counter
+const (
+	TokenTypeInput  = "input"
+	TokenTypeOutput = "output"
+)
+
+// Metrics holds the instruments recorded alongside spans
+type Metrics struct {
+	// LLMDuration is gen_ai.client.operation.duration in seconds
+	LLMDuration metric.Float64Histogram
+	// TokenUsage counts input and output tokens by gen_ai.token.type
+	TokenUsage metric.Int64Counter
+	// ToolDuration is the tool execution latency in seconds
+	ToolDuration metric.Float64Histogram
+	// AgentDuration is the agent and sub-agent run latency in seconds
+	AgentDuration metric.Float64Histogram
+	// RetrievalDuration is the RAG retrieval latency in seconds
+	RetrievalDuration metric.Float64Histogram
+}
+
+func newMetrics(meter metric.Meter) (*Metrics, error) {
+	var m Metrics
+	var err error
+	if m.LLMDuration, err = meter.Float64Histogram("gen_ai.client.operation.duration",
+		metric.WithUnit("s"), metric.WithDescription("GenAI operation duration")); err != nil {
+		return nil, err
+	}
+	if m.TokenUsage, err = meter.Int64Counter("gen_ai.client.token.usage",
+		metric.WithUnit("{token}"), metric.WithDescription("Number of input and output tokens used")); err != nil {
+		return nil, err
+	}
+	if m.ToolDuration, err = meter.Float64Histogram("agentgo.tool.duration",
+		metric.WithUnit("s"), metric.WithDescription("Tool execution duration")); err != nil {
+		return nil, err
+	}
+	if m.AgentDuration, err = meter.Float64Histogram("agentgo.agent.duration",
+		metric.WithUnit("s"), metric.WithDescription("Agent run duration")); err != nil {
+		return nil, err
+	}
+	if m.RetrievalDuration, err = meter.Float64Histogram("agentgo.rag.retrieval.duration",
+		metric.WithUnit("s"), metric.WithDescription("RAG retrieval duration")); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// RecordTokens adds input and output token counts for model
+func (m *Metrics) RecordTokens(ctx context.Context, model string, input, output int) {
+	if m == nil {
+		return
+	}
+	if input > 0 {
+		m.TokenUsage.Add(ctx, int64(input), metric.WithAttributes(
+			AttrGenAIOperationName.String(OperationChat),
+			AttrGenAIRequestModel.String(model),
+			AttrGenAITokenType.String(TokenTypeInput),
+		))
+	}
+	if output > 0 {
+		m.TokenUsage.Add(ctx, int64(output), metric.WithAttributes(
+			AttrGenAIOperationName.String(OperationChat),
+			AttrGenAIRequestModel.String(model),
+			AttrGenAITokenType.String(TokenTypeOutput),
+		))
+	}
+}
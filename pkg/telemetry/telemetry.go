@@ -0,0 +1,288 @@
+// Package telemetry exports agent traces and metrics over OTLP and
+// propagates trace context into outbound HTTP and gRPC calls.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// ScopeName is the instrumentation scope for spans and metrics emitted by agent-go
+const ScopeName = "github.com/liliang-cn/agent-go"
+
+// Protocol selects the OTLP transport
+type Protocol string
+
+const (
+	ProtocolGRPC Protocol = "grpc"
+	ProtocolHTTP Protocol = "http"
+)
+
+// Config configures OTLP export
+type Config struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// Protocol is grpc (default, port 4317) or http (port 4318)
+	Protocol Protocol `mapstructure:"protocol" json:"protocol"`
+	// Endpoint is host:port or a URL; empty uses the OTEL_EXPORTER_OTLP_* env vars
+	Endpoint string `mapstructure:"endpoint" json:"endpoint"`
+	// Insecure disables TLS; implied by an http:// endpoint URL
+	Insecure    bool              `mapstructure:"insecure" json:"insecure"`
+	Headers     map[string]string `mapstructure:"headers" json:"headers,omitempty"`
+	ServiceName string            `mapstructure:"service_name" json:"service_name"`
+	// SampleRatio is the fraction of traces sampled; 0 samples everything
+	SampleRatio float64 `mapstructure:"sample_ratio" json:"sample_ratio"`
+	// MetricsInterval is how often metrics are pushed (default 30s)
+	MetricsInterval time.Duration `mapstructure:"metrics_interval" json:"metrics_interval"`
+	DisableMetrics  bool          `mapstructure:"disable_metrics" json:"disable_metrics"`
+}
+
+// Validate checks the protocol and endpoint
+func (c Config) Validate() error {
+	switch c.Protocol {
+	case "", ProtocolGRPC, ProtocolHTTP:
+	default:
+		return fmt.Errorf("invalid telemetry protocol %q (use grpc or http)", c.Protocol)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("telemetry sample_ratio must be between 0 and 1")
+	}
+	_, _, err := c.endpoint()
+	return err
+}
+
+// endpoint splits Endpoint into host:port and whether TLS is disabled
+func (c Config) endpoint() (string, bool, error) {
+	if c.Endpoint == "" {
+		return "", c.Insecure, nil
+	}
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		// Plain host:port
+		return c.Endpoint, c.Insecure, nil
+	}
+	switch u.Scheme {
+	case "http":
+		return u.Host, true, nil
+	case "https":
+		return u.Host, c.Insecure, nil
+	}
+	return "", false, fmt.Errorf("invalid telemetry endpoint %q", c.Endpoint)
+}
+
+// Provider owns the OTel tracer and meter providers for one process
+type Provider struct {
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	tracer         trace.Tracer
+	metrics        *Metrics
+}
+
+// Setup creates OTLP exporters from cfg and installs the resulting providers
+// and a W3C trace-context propagator as the OTel globals
+func Setup(ctx context.Context, cfg Config) (*Provider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	spanExporter, err := newSpanExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	var reader sdkmetric.Reader
+	if !cfg.DisableMetrics {
+		metricExporter, err := newMetricExporter(ctx, cfg)
+		if err != nil {
+			_ = spanExporter.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		}
+		interval := cfg.MetricsInterval
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+		reader = sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(interval))
+	}
+
+	p, err := NewProvider(cfg, spanExporter, reader)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(p.tracerProvider)
+	if p.meterProvider != nil {
+		otel.SetMeterProvider(p.meterProvider)
+	}
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return p, nil
+}
+
+// NewProvider builds a provider around an existing span exporter and optional
+// metric reader without touching the OTel globals. Tests use it with
+// in-memory exporters.
+func NewProvider(cfg Config, exporter sdktrace.SpanExporter, reader sdkmetric.Reader) (*Provider, error) {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "agentgo"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build telemetry resource: %w", err)
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	}
+
+	p := &Provider{
+		tracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sampler),
+		),
+	}
+	p.tracer = p.tracerProvider.Tracer(ScopeName)
+
+	if reader != nil {
+		p.meterProvider = sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(reader),
+			sdkmetric.WithResource(res),
+		)
+		p.metrics, err = newMetrics(p.meterProvider.Meter(ScopeName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Tracer returns the tracer spans should be started from
+func (p *Provider) Tracer() trace.Tracer {
+	return p.tracer
+}
+
+// Metrics returns the metric instruments, or nil when metrics are disabled
+func (p *Provider) Metrics() *Metrics {
+	return p.metrics
+}
+
+// ForceFlush exports all pending spans and metrics
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	err := p.tracerProvider.ForceFlush(ctx)
+	if p.meterProvider != nil {
+		err = errors.Join(err, p.meterProvider.ForceFlush(ctx))
+	}
+	return err
+}
+
+// Shutdown flushes and stops the exporters
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.tracerProvider.Shutdown(ctx)
+	if p.meterProvider != nil {
+		err = errors.Join(err, p.meterProvider.Shutdown(ctx))
+	}
+	return err
+}
+
+func newSpanExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	endpoint, insecure, err := cfg.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Protocol == ProtocolHTTP {
+		var opts []otlptracehttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	var opts []otlptracegrpc.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+func newMetricExporter(ctx context.Context, cfg Config) (sdkmetric.Exporter, error) {
+	endpoint, insecure, err := cfg.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Protocol == ProtocolHTTP {
+		var opts []otlpmetrichttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+
+	var opts []otlpmetricgrpc.Option
+	if endpoint != "" {
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
+	}
+	return otlpmetricgrpc.New(ctx, opts...)
+}
+
+// HTTPTransport wraps base so outbound requests carry the W3C traceparent of
+// the request context and are recorded as client spans
+func HTTPTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// GRPCServerOption extracts incoming trace context and records server spans
+func GRPCServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// GRPCDialOption injects trace context into outgoing calls and records client spans
+func GRPCDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
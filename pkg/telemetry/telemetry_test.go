@@ -0,0 +1,172 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is an in-process OTLP collector stub that keeps received spans
+type collector struct {
+	collectortrace.UnimplementedTraceServiceServer
+
+	mu      sync.Mutex
+	spans   []*tracepb.Span
+	metrics int
+}
+
+func (c *collector) Export(_ context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.addSpans(req)
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) addSpans(req *collectortrace.ExportTraceServiceRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+func (c *collector) spanNames() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.spans))
+	for _, s := range c.spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// metricsServer adapts the collector to the metrics service, whose Export
+// method signature clashes with the trace service
+type metricsServer struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	c *collector
+}
+
+func (m metricsServer) Export(_ context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	m.c.mu.Lock()
+	m.c.metrics += len(req.ResourceMetrics)
+	m.c.mu.Unlock()
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func startGRPCCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	c := &collector{}
+	srv := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(srv, c)
+	collectormetrics.RegisterMetricsServiceServer(srv, metricsServer{c: c})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return c, lis.Addr().String()
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Protocol: ProtocolHTTP, Endpoint: "http://localhost:4318"}.Validate())
+	assert.Error(t, Config{Protocol: "udp"}.Validate())
+	assert.Error(t, Config{SampleRatio: 2}.Validate())
+	assert.Error(t, Config{Endpoint: "ftp://collector:21"}.Validate())
+
+	endpoint, insecure, err := Config{Endpoint: "http://otel:4318"}.endpoint()
+	require.NoError(t, err)
+	assert.Equal(t, "otel:4318", endpoint)
+	assert.True(t, insecure)
+}
+
+func TestSetupExportsOverGRPC(t *testing.T) {
+	c, addr := startGRPCCollector(t)
+	ctx := context.Background()
+
+	p, err := Setup(ctx, Config{Enabled: true, Endpoint: addr, Insecure: true, ServiceName: "telemetry-test"})
+	require.NoError(t, err)
+
+	ctx, span := p.Tracer().Start(ctx, "invoke_agent tester")
+	p.Metrics().RecordTokens(ctx, "test-model", 12, 3)
+	span.End()
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Contains(t, c.spanNames(), "invoke_agent tester")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Positive(t, c.metrics)
+}
+
+func TestSetupExportsOverHTTP(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req collectortrace.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.addSpans(&req)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	p, err := Setup(ctx, Config{Enabled: true, Protocol: ProtocolHTTP, Endpoint: srv.URL, DisableMetrics: true})
+	require.NoError(t, err)
+	assert.Nil(t, p.Metrics())
+
+	_, span := p.Tracer().Start(ctx, "chat test-model")
+	span.End()
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Contains(t, c.spanNames(), "chat test-model")
+}
+
+func TestHTTPTransportInjectsTraceparent(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer srv.Close()
+
+	c, addr := startGRPCCollector(t)
+	ctx := context.Background()
+	p, err := Setup(ctx, Config{Enabled: true, Endpoint: addr, Insecure: true, DisableMetrics: true})
+	require.NoError(t, err)
+
+	ctx, span := p.Tracer().Start(ctx, "execute_tool fetch")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: HTTPTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	span.End()
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+	assert.Contains(t, c.spanNames(), "execute_tool fetch")
+}
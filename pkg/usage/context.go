@@ -0,0 +1,16 @@
+package usage
+
+import "context"
+
+type traceIDKey struct{}
+
+// WithTraceID returns a context whose usage records are linked to traceID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID set by WithTraceID, if any
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
@@ -13,6 +13,8 @@ const (
 	CallTypeLLM CallType = "llm"
 	CallTypeMCP CallType = "mcp"
 	CallTypeRAG CallType = "rag"
+	// CallTypeSampling is an LLM call made on behalf of an MCP server
+	CallTypeSampling CallType = "sampling"
 )
 
 // Conversation represents a conversation session
@@ -40,6 +42,7 @@ type UsageRecord struct {
 	ID               string    `json:"id" db:"id"`
 	ConversationID   string    `json:"conversation_id" db:"conversation_id"`
 	MessageID        string    `json:"message_id" db:"message_id"`
+	TraceID          string    `json:"trace_id,omitempty" db:"trace_id"`
 	CallType         CallType  `json:"call_type" db:"call_type"`
 	Provider         string    `json:"provider" db:"provider"`
 	Model            string    `json:"model" db:"model"`
@@ -82,6 +85,7 @@ type ConversationWithMessages struct {
 // UsageFilter represents filters for querying usage records
 type UsageFilter struct {
 	ConversationID string    `json:"conversation_id,omitempty"`
+	TraceID        string    `json:"trace_id,omitempty"`
 	CallType       CallType  `json:"call_type,omitempty"`
 	Provider       string    `json:"provider,omitempty"`
 	Model          string    `json:"model,omitempty"`
@@ -163,6 +163,7 @@ func (s *Service) TrackLLMCall(ctx context.Context, provider, model string, inpu
 	record.Success = true
 
 	// Save to database
+	record.TraceID = TraceIDFromContext(ctx)
 	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
 		return nil, fmt.Errorf("failed to create usage record: %w", err)
 	}
@@ -190,6 +191,40 @@ func (s *Service) TrackLLMCallWithTokens(ctx context.Context, provider, model st
 	record.Cost = CalculateCost(model, inputTokens, outputTokens)
 	record.Success = true
 
+	record.TraceID = TraceIDFromContext(ctx)
+	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to create usage record: %w", err)
+	}
+
+	return record, nil
+}
+
+// TrackSamplingCall tracks an LLM call made for an MCP server's sampling
+// request. The record's provider is "mcp:<server>" so usage can be grouped
+// by the server that asked for it.
+func (s *Service) TrackSamplingCall(ctx context.Context, server, model string, input, output string, startTime time.Time) (*UsageRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := NewUsageRecord("", "", CallTypeSampling)
+
+	if s.currentConversation != nil {
+		record.ConversationID = s.currentConversation.ID
+	}
+
+	record.Provider = "mcp:" + server
+	record.Model = model
+	record.Latency = time.Since(startTime).Milliseconds()
+	record.InputTokens = s.tokenCounter.EstimateTokens(input, model)
+	record.OutputTokens = s.tokenCounter.EstimateTokens(output, model)
+	record.TotalTokens = record.InputTokens + record.OutputTokens
+	record.Cost = CalculateCost(model, record.InputTokens, record.OutputTokens)
+	record.Success = true
+
+	metadataJSON, _ := json.Marshal(map[string]interface{}{"server": server})
+	record.RequestMetadata = string(metadataJSON)
+
+	record.TraceID = TraceIDFromContext(ctx)
 	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
 		return nil, fmt.Errorf("failed to create usage record: %w", err)
 	}
@@ -219,6 +254,7 @@ func (s *Service) TrackMCPCall(ctx context.Context, toolName string, params inte
 		record.RequestMetadata = string(paramsJSON)
 	}
 
+	record.TraceID = TraceIDFromContext(ctx)
 	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
 		return nil, fmt.Errorf("failed to create usage record: %w", err)
 	}
@@ -255,6 +291,7 @@ func (s *Service) TrackRAGCall(ctx context.Context, operation string, query stri
 	metadataJSON, _ := json.Marshal(metadata)
 	record.RequestMetadata = string(metadataJSON)
 
+	record.TraceID = TraceIDFromContext(ctx)
 	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
 		return nil, fmt.Errorf("failed to create usage record: %w", err)
 	}
@@ -279,6 +316,7 @@ func (s *Service) TrackError(ctx context.Context, callType CallType, provider, m
 	record.Success = false
 	record.ErrorMessage = errorMsg
 
+	record.TraceID = TraceIDFromContext(ctx)
 	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
 		return nil, fmt.Errorf("failed to create usage record: %w", err)
 	}
@@ -356,6 +394,11 @@ func (s *Service) DeleteConversation(ctx context.Context, conversationID string)
 	return s.repo.DeleteConversation(ctx, conversationID)
 }
 
+// ListUsageRecords lists individual usage records matching filter
+func (s *Service) ListUsageRecords(ctx context.Context, filter *UsageFilter) ([]*UsageRecord, error) {
+	return s.repo.ListUsageRecords(ctx, filter)
+}
+
 // ExportConversation exports a conversation to JSON
 func (s *Service) ExportConversation(ctx context.Context, conversationID string) ([]byte, error) {
 	history, err := s.GetConversationHistory(ctx, conversationID)
@@ -96,6 +96,26 @@ func TestService_TrackLLMCall(t *testing.T) {
 	assert.True(t, record.Success)
 }
 
+func TestService_LinksUsageToTrace(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	_, err := service.TrackLLMCallWithTokens(WithTraceID(ctx, "trace-1"), "openai", "gpt-4", 10, 5, startTime)
+	require.NoError(t, err)
+	_, err = service.TrackLLMCallWithTokens(WithTraceID(ctx, "trace-2"), "openai", "gpt-4", 10, 5, startTime)
+	require.NoError(t, err)
+	_, err = service.TrackMCPCall(ctx, "search", nil, startTime)
+	require.NoError(t, err)
+
+	records, err := service.ListUsageRecords(ctx, &UsageFilter{TraceID: "trace-1"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "trace-1", records[0].TraceID)
+}
+
 func TestService_TrackMCPCall(t *testing.T) {
 	service, cleanup := setupTestService(t)
 	defer cleanup()
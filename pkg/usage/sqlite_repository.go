@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -86,6 +87,16 @@ func (r *SQLiteRepository) Initialize(ctx context.Context) error {
 		}
 	}
 
+	// Migration: link usage records to the trace they were written under
+	if _, err := r.db.ExecContext(ctx, `ALTER TABLE usage_records ADD COLUMN trace_id TEXT DEFAULT ''`); err != nil {
+		if !strings.Contains(err.Error(), "duplicate column name") {
+			return fmt.Errorf("failed to execute migration query: %w", err)
+		}
+	}
+	if _, err := r.db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_usage_records_trace_id ON usage_records(trace_id)`); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
 	// Initialize RAG tables
 	if err := r.InitializeRAGTables(ctx); err != nil {
 		return fmt.Errorf("failed to initialize RAG tables: %w", err)
@@ -256,15 +267,16 @@ func (r *SQLiteRepository) DeleteMessage(ctx context.Context, id string) error {
 // CreateUsageRecord creates a new usage record
 func (r *SQLiteRepository) CreateUsageRecord(ctx context.Context, record *UsageRecord) error {
 	query := `INSERT INTO usage_records (
-		id, conversation_id, message_id, call_type, provider, model,
+		id, conversation_id, message_id, trace_id, call_type, provider, model,
 		input_tokens, output_tokens, total_tokens, cost, latency,
 		success, error_message, request_metadata, response_metadata, created_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		record.ID,
 		record.ConversationID,
 		record.MessageID,
+		record.TraceID,
 		record.CallType,
 		record.Provider,
 		record.Model,
@@ -284,7 +296,7 @@ func (r *SQLiteRepository) CreateUsageRecord(ctx context.Context, record *UsageR
 
 // GetUsageRecord retrieves a usage record by ID
 func (r *SQLiteRepository) GetUsageRecord(ctx context.Context, id string) (*UsageRecord, error) {
-	query := `SELECT id, conversation_id, message_id, call_type, provider, model,
+	query := `SELECT id, conversation_id, message_id, trace_id, call_type, provider, model,
 			  input_tokens, output_tokens, total_tokens, cost, latency,
 			  success, error_message, request_metadata, response_metadata, created_at
 			  FROM usage_records WHERE id = ?`
@@ -294,6 +306,7 @@ func (r *SQLiteRepository) GetUsageRecord(ctx context.Context, id string) (*Usag
 		&record.ID,
 		&record.ConversationID,
 		&record.MessageID,
+		&record.TraceID,
 		&record.CallType,
 		&record.Provider,
 		&record.Model,
@@ -316,7 +329,7 @@ func (r *SQLiteRepository) GetUsageRecord(ctx context.Context, id string) (*Usag
 
 // ListUsageRecords lists usage records based on filter
 func (r *SQLiteRepository) ListUsageRecords(ctx context.Context, filter *UsageFilter) ([]*UsageRecord, error) {
-	query := `SELECT id, conversation_id, message_id, call_type, provider, model,
+	query := `SELECT id, conversation_id, message_id, trace_id, call_type, provider, model,
 			  input_tokens, output_tokens, total_tokens, cost, latency,
 			  success, error_message, request_metadata, response_metadata, created_at
 			  FROM usage_records WHERE 1=1`
@@ -327,6 +340,10 @@ func (r *SQLiteRepository) ListUsageRecords(ctx context.Context, filter *UsageFi
 		query += " AND conversation_id = ?"
 		args = append(args, filter.ConversationID)
 	}
+	if filter.TraceID != "" {
+		query += " AND trace_id = ?"
+		args = append(args, filter.TraceID)
+	}
 	if filter.CallType != "" {
 		query += " AND call_type = ?"
 		args = append(args, filter.CallType)
@@ -372,6 +389,7 @@ func (r *SQLiteRepository) ListUsageRecords(ctx context.Context, filter *UsageFi
 			&record.ID,
 			&record.ConversationID,
 			&record.MessageID,
+			&record.TraceID,
 			&record.CallType,
 			&record.Provider,
 			&record.Model,
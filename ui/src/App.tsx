@@ -8,6 +8,7 @@ import { Status } from './pages/Status'
 import { Skills } from './pages/Skills'
 import { MCP } from './pages/MCP'
 import { Memory } from './pages/Memory'
+import { Traces } from './pages/Traces'
 import { Agent } from './pages/Agent'
 import { Settings } from './pages/Settings'
 import { Setup } from './pages/Setup'
@@ -39,6 +40,9 @@ function Nav() {
       <NavLink to="/memory" className={linkClass} data-testid="nav-memory">
         {t('memoryNav')}
       </NavLink>
+      <NavLink to="/traces" className={linkClass} data-testid="nav-traces">
+        {t('traces')}
+      </NavLink>
       <NavLink to="/status" className={linkClass} data-testid="nav-status">
         {t('status')}
       </NavLink>
@@ -106,6 +110,7 @@ function App() {
           <Route path="/skills" element={<Skills />} />
           <Route path="/mcp" element={<MCP />} />
           <Route path="/memory" element={<Memory />} />
+          <Route path="/traces" element={<Traces />} />
           <Route path="/status" element={<Status />} />
           <Route path="/query" element={<QueryTest />} />
           <Route path="/documents" element={<Documents />} />
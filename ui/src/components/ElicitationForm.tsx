@@ -0,0 +1,131 @@
+import { useState } from 'react'
+import { useTranslation } from 'react-i18next'
+import { useRespondToElicitation } from '../hooks/useApi'
+import type { ElicitationAnswer, ElicitationField, ElicitationRequest } from '../lib/api'
+
+type Values = Record<string, string | boolean>
+
+function initialValues(fields: ElicitationField[]): Values {
+  const values: Values = {}
+  for (const field of fields) {
+    if (field.type === 'boolean') {
+      values[field.name] = field.default === true
+    } else {
+      values[field.name] = field.default == null ? '' : String(field.default)
+    }
+  }
+  return values
+}
+
+// toContent converts the form values into the types the schema asks for,
+// leaving out optional fields left empty
+function toContent(fields: ElicitationField[], values: Values): Record<string, unknown> {
+  const content: Record<string, unknown> = {}
+  for (const field of fields) {
+    const value = values[field.name]
+    if (field.type === 'boolean') {
+      content[field.name] = value === true
+      continue
+    }
+    const text = String(value ?? '').trim()
+    if (text === '') continue
+    content[field.name] = field.type === 'number' || field.type === 'integer' ? Number(text) : text
+  }
+  return content
+}
+
+export function ElicitationForm({
+  request,
+  sessionId,
+  onDone,
+}: {
+  request: ElicitationRequest
+  sessionId: string
+  onDone: (id: string) => void
+}) {
+  const { t } = useTranslation()
+  const fields = request.fields ?? []
+  const [values, setValues] = useState<Values>(() => initialValues(fields))
+  const respond = useRespondToElicitation()
+
+  const answer = (action: ElicitationAnswer['action'], content?: Record<string, unknown>) => {
+    respond.mutate(
+      { id: request.id, session_id: sessionId, action, content },
+      // A request the server gave up on is gone either way
+      { onSettled: () => onDone(request.id) },
+    )
+  }
+
+  const submit = (e: React.FormEvent) => {
+    e.preventDefault()
+    answer('accept', toContent(fields, values))
+  }
+
+  return (
+    <form
+      onSubmit={submit}
+      className="rounded-[20px] border border-amber-200 bg-amber-50 p-4 text-left text-sm text-slate-800"
+      data-testid="chat-elicitation"
+    >
+      <div className="font-semibold text-slate-900">{t('elicitationTitle', { server: request.server })}</div>
+      <p className="mt-1 whitespace-pre-wrap text-slate-700">{request.message}</p>
+      {request.url && (
+        <a href={request.url} target="_blank" rel="noreferrer" className="mt-2 inline-block text-blue-700 underline">
+          {t('elicitationOpenLink')}
+        </a>
+      )}
+
+      {fields.length > 0 && (
+        <div className="mt-3 space-y-3">
+          {fields.map((field) => {
+            const label = field.title || field.name
+            const value = values[field.name]
+            const set = (v: string | boolean) => setValues((prev) => ({ ...prev, [field.name]: v }))
+            return (
+              <label key={field.name} className="block">
+                <span className="mb-1 block font-medium text-slate-700">
+                  {label}
+                  {field.required && <span className="ml-1 text-rose-600">*</span>}
+                </span>
+                {field.type === 'boolean' ? (
+                  <input type="checkbox" checked={value === true} onChange={(e) => set(e.target.checked)} />
+                ) : field.enum && field.enum.length > 0 ? (
+                  <select value={String(value)} onChange={(e) => set(e.target.value)} required={field.required} className="dashboard-input">
+                    {!field.required && <option value="" />}
+                    {field.enum.map((option) => (
+                      <option key={option} value={option}>
+                        {option}
+                      </option>
+                    ))}
+                  </select>
+                ) : (
+                  <input
+                    type={field.type === 'number' || field.type === 'integer' ? 'number' : field.format === 'email' ? 'email' : 'text'}
+                    step={field.type === 'integer' ? 1 : 'any'}
+                    value={String(value)}
+                    onChange={(e) => set(e.target.value)}
+                    required={field.required}
+                    className="dashboard-input"
+                  />
+                )}
+                {field.description && <span className="mt-1 block text-xs text-slate-500">{field.description}</span>}
+              </label>
+            )
+          })}
+        </div>
+      )}
+
+      <div className="mt-4 flex flex-wrap gap-2">
+        <button type="submit" disabled={respond.isPending} className="dashboard-button px-4 py-2 text-sm" data-testid="chat-elicitation-submit">
+          {t('elicitationSubmit')}
+        </button>
+        <button type="button" disabled={respond.isPending} onClick={() => answer('decline')} className="dashboard-secondary-button px-4 py-2 text-sm">
+          {t('elicitationDecline')}
+        </button>
+        <button type="button" disabled={respond.isPending} onClick={() => answer('cancel')} className="dashboard-secondary-button px-4 py-2 text-sm">
+          {t('elicitationCancel')}
+        </button>
+      </div>
+    </form>
+  )
+}
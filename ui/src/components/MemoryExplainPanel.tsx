@@ -0,0 +1,128 @@
+import { useState } from 'react'
+import { useTranslation } from 'react-i18next'
+import { useExplainMemorySearch } from '../hooks/useApi'
+import type { MemoryExplanation, ScoringConfig } from '../lib/api'
+
+// Scoring weights that can be tuned per query
+const tunableWeights: { key: string; label: string; step: number }[] = [
+  { key: 'recency_weight', label: 'Recency', step: 0.05 },
+  { key: 'half_life_days', label: 'Half-life (days)', step: 1 },
+  { key: 'importance_weight', label: 'Importance', step: 0.05 },
+  { key: 'length_norm_weight', label: 'Length', step: 0.05 },
+  { key: 'access_boost_weight', label: 'Access', step: 0.05 },
+]
+
+export function MemoryExplainPanel() {
+  const { t } = useTranslation()
+  const [query, setQuery] = useState('')
+  const [overrides, setOverrides] = useState<ScoringConfig>({})
+  const explainMutation = useExplainMemorySearch()
+  const explanation = explainMutation.data
+  const scoring = { ...(explanation?.scoring ?? {}), ...overrides }
+
+  const runExplain = (e?: React.FormEvent) => {
+    e?.preventDefault()
+    if (!query.trim()) return
+    explainMutation.mutate({ query, scoring: Object.keys(overrides).length > 0 ? overrides : undefined })
+  }
+
+  return (
+    <div className="glass-panel rounded-[28px] p-6" data-testid="memory-explain">
+      <h3 className="text-lg font-medium text-slate-900">{t('explainRetrieval')}</h3>
+      <p className="mb-4 text-sm text-slate-500">{t('explainRetrievalHelp')}</p>
+      <form onSubmit={runExplain} className="flex gap-2">
+        <input
+          type="text"
+          value={query}
+          onChange={(e) => setQuery(e.target.value)}
+          placeholder={t('queryPlaceholder')}
+          className="dashboard-input"
+          data-testid="memory-explain-query"
+        />
+        <button
+          type="submit"
+          disabled={explainMutation.isPending || !query.trim()}
+          className="dashboard-button px-4 py-2 text-sm disabled:opacity-50"
+          data-testid="memory-explain-submit"
+        >
+          {t('explainButton')}
+        </button>
+      </form>
+
+      {explanation && (
+        <div className="mt-4 space-y-4">
+          <div className="grid grid-cols-2 gap-3 md:grid-cols-5">
+            {tunableWeights.map(({ key, label, step }) => (
+              <label key={key} className="text-xs text-slate-500">
+                {label}
+                <input
+                  type="number"
+                  step={step}
+                  min={0}
+                  value={Number(scoring[key] ?? 0)}
+                  onChange={(e) => setOverrides({ ...overrides, [key]: parseFloat(e.target.value) || 0 })}
+                  onBlur={() => runExplain()}
+                  className="dashboard-input mt-1"
+                  data-testid={`memory-explain-weight-${key}`}
+                />
+              </label>
+            ))}
+          </div>
+
+          <p className="text-xs text-slate-500">
+            {t('classifierDecision')}: {explanation.query_type ?? '-'}
+            {explanation.redacted ? ` · ${explanation.redacted} redacted` : ''}
+          </p>
+          {!explanation.needs_memory && <p className="text-sm text-slate-500">{t('retrievalSkipped')}</p>}
+          {explanation.logic && <p className="text-xs italic text-slate-500">{explanation.logic}</p>}
+
+          <div className="space-y-2">
+            {explanation.memories.map((m) => (
+              <ExplanationRow key={`${m.memory_id}-${m.content}`} memory={m} />
+            ))}
+          </div>
+        </div>
+      )}
+    </div>
+  )
+}
+
+function ExplanationRow({ memory }: { memory: MemoryExplanation }) {
+  const { t } = useTranslation()
+  const retrieval = [
+    memory.vector_rank ? `vector ${memory.vector_score?.toFixed(3)} (#${memory.vector_rank})` : '',
+    memory.text_rank ? `bm25 ${memory.text_score?.toFixed(3)} (#${memory.text_rank})` : '',
+    memory.navigator_rank ? `navigator #${memory.navigator_rank}` : '',
+    memory.rrf_rank ? `rrf ${memory.rrf_score?.toFixed(4)} (#${memory.rrf_rank})` : '',
+  ].filter(Boolean)
+
+  return (
+    <div
+      className={`rounded-2xl border p-3 text-sm ${memory.selected ? 'border-sky-200 bg-white' : 'border-slate-100 bg-slate-50 opacity-70'}`}
+      data-testid={`memory-explain-row-${memory.memory_id}`}
+    >
+      <div className="flex items-center justify-between text-xs text-slate-500">
+        <span>
+          {memory.selected ? `#${memory.rank}` : `${t('droppedLabel')}: ${memory.excluded}`} · {memory.type}
+        </span>
+        {memory.scoring && (
+          <span>
+            {t('finalScore')}: {memory.scoring.final.toFixed(3)}
+          </span>
+        )}
+      </div>
+      <p className="mt-1 line-clamp-2 text-slate-700">{memory.content}</p>
+      <p className="mt-1 text-xs text-slate-500">
+        {t('sourcesLabel')}: {memory.sources.join(', ')} · {t('scopeLabel')}: {memory.scope} ({memory.scope_weight.toFixed(2)})
+        {retrieval.length > 0 && ` · ${retrieval.join(' · ')}`}
+      </p>
+      {memory.scoring && (
+        <p className="mt-1 text-xs text-slate-400">
+          base {memory.scoring.base.toFixed(3)} · recency {memory.scoring.recency.toFixed(2)} · importance{' '}
+          {memory.scoring.importance.toFixed(2)} · length {memory.scoring.length.toFixed(2)} · access{' '}
+          {memory.scoring.access.toFixed(2)} · outcome {memory.scoring.outcome.toFixed(2)}
+        </p>
+      )}
+    </div>
+  )
+}
@@ -1,5 +1,5 @@
 import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query'
-import { api, QueryRequest, ChatRequest, CreateSkillRequest, AddMCPServerRequest, CallToolRequest, AddMemoryRequest, UpdateConfigRequest, CreateAgentRequest, CreateSquadRequest, ApplySetupRequest } from '../lib/api'
+import { api, QueryRequest, ChatRequest, CreateSkillRequest, AddMCPServerRequest, CallToolRequest, AddMemoryRequest, ExplainMemoryRequest, UpdateConfigRequest, CreateAgentRequest, CreateSquadRequest, ApplySetupRequest } from '../lib/api'
 
 // RAG Hooks
 export function useQueryRAG() {
@@ -128,6 +128,23 @@ export function useOpsLogs(limit = 20) {
   })
 }
 
+// Trace Hooks
+export function useTraces(limit = 50) {
+  return useQuery({
+    queryKey: ['traces', limit],
+    queryFn: () => api.getTraces(limit),
+    select: (data) => data.traces,
+  })
+}
+
+export function useTrace(id: string) {
+  return useQuery({
+    queryKey: ['traces', 'detail', id],
+    queryFn: () => api.getTrace(id),
+    enabled: !!id,
+  })
+}
+
 // Skills Hooks
 export function useSkills() {
   return useQuery({
@@ -240,6 +257,53 @@ export function useDeleteMemory() {
   })
 }
 
+export function useExplainMemorySearch() {
+  return useMutation({
+    mutationFn: (data: ExplainMemoryRequest) => api.explainMemorySearch(data),
+  })
+}
+
+export function useMemoryConflicts() {
+  return useQuery({
+    queryKey: ['memories', 'conflicts'],
+    queryFn: api.getMemoryConflicts,
+  })
+}
+
+export function useResolveMemoryConflict() {
+  const queryClient = useQueryClient()
+  return useMutation({
+    mutationFn: (winnerId: string) => api.resolveMemoryConflict(winnerId),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['memories'] })
+    },
+  })
+}
+
+export function useMemorySuggestions() {
+  return useQuery({
+    queryKey: ['memories', 'suggestions'],
+    queryFn: api.getMemorySuggestions,
+  })
+}
+
+export function useDecideMemorySuggestion() {
+  const queryClient = useQueryClient()
+  return useMutation({
+    mutationFn: ({ id, accept }: { id: string; accept: boolean }) =>
+      accept ? api.acceptMemorySuggestion(id) : api.rejectMemorySuggestion(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['memories'] })
+    },
+  })
+}
+
+export function useRespondToElicitation() {
+  return useMutation({
+    mutationFn: api.respondToElicitation,
+  })
+}
+
 export function useConfig() {
   return useQuery({
     queryKey: ['config'],
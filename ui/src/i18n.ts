@@ -11,6 +11,15 @@ const resources = {
       mcp: 'MCP',
       memoryNav: 'Memory',
       status: 'Status',
+      traces: 'Traces',
+      noTraces: 'No traces recorded yet. Run the agent with tracing enabled.',
+      errorLoadingTraces: 'Failed to load traces',
+      selectTrace: 'Select a trace to see its waterfall.',
+      traceSpans: 'Spans',
+      traceTokens: 'Tokens',
+      traceHistory: 'History',
+      traceUsage: 'Usage',
+      traceErrors: 'Errors',
       query: 'Query',
       documents: 'Documents',
       settings: 'Settings',
@@ -140,6 +149,23 @@ const resources = {
       confirmDeleteMemory: 'Are you sure you want to delete this memory?',
       memoryDetails: 'Memory Details',
       errorLoadingMemories: 'Error loading memories',
+      memoryConflicts: 'Conflicts to review',
+      memoryConflictsHelp: 'These memories contradict each other. Keep the one that is correct; the others will be superseded.',
+      keepThisMemory: 'Keep this',
+      memorySuggestions: 'Suggested memories',
+      memorySuggestionsHelp: 'The agent proposes remembering these. Accept to store them, or reject to discard.',
+      acceptSuggestion: 'Remember',
+      rejectSuggestion: 'Discard',
+      explainRetrieval: 'Explain retrieval',
+      explainRetrievalHelp: 'Run a query through the agent\'s memory retrieval and see why each memory was picked or dropped. Weight changes apply to this query only.',
+      explainButton: 'Explain',
+      classifierDecision: 'Classifier',
+      retrievalSkipped: 'Retrieval skipped for this query',
+      droppedLabel: 'dropped',
+      sourcesLabel: 'Sources',
+      scopeLabel: 'Scope',
+      finalScore: 'Final score',
+      confidenceLabel: 'Confidence',
       testQuery: 'Test Query',
       queryPlaceholder: 'Enter your query...',
       search: 'Search',
@@ -190,6 +216,11 @@ const resources = {
       chatQueueDepth: 'Queued tasks: {{count}}',
       chatLastAssistantMessage: 'Last Assistant Message',
       chatNoStructuredData: 'No structured assistant message yet.',
+      elicitationTitle: '{{server}} needs your input',
+      elicitationOpenLink: 'Open link',
+      elicitationSubmit: 'Submit',
+      elicitationDecline: 'Decline',
+      elicitationCancel: 'Cancel',
       chatPromptAgent: 'Send a task to the selected agent...',
       chatPromptMultiAgent: 'Use @Captain @MemberName and send one shared task...',
       chatMultiAgentHint: 'Mention one or more members, for example @Captain @Writer summarize the findings and propose changes.',
@@ -394,6 +425,15 @@ const resources = {
       mcp: 'MCP',
       memoryNav: '记忆',
       status: '状态',
+      traces: '追踪',
+      noTraces: '暂无追踪记录。请在启用追踪后运行智能体。',
+      errorLoadingTraces: '加载追踪失败',
+      selectTrace: '选择一条追踪以查看瀑布图。',
+      traceSpans: 'Span',
+      traceTokens: 'Token',
+      traceHistory: '历史记录',
+      traceUsage: '用量',
+      traceErrors: '错误',
       query: '查询',
       documents: '文档',
       settings: '设置',
@@ -523,6 +563,23 @@ const resources = {
       confirmDeleteMemory: '确定要删除这条记忆吗？',
       memoryDetails: '记忆详情',
       errorLoadingMemories: '加载记忆出错',
+      memoryConflicts: '待审核的冲突',
+      memoryConflictsHelp: '这些记忆相互矛盾。保留正确的一条，其余将被取代。',
+      keepThisMemory: '保留此条',
+      memorySuggestions: '建议的记忆',
+      memorySuggestionsHelp: '智能体建议记住以下内容。接受即保存，拒绝则丢弃。',
+      acceptSuggestion: '记住',
+      rejectSuggestion: '丢弃',
+      explainRetrieval: '检索解释',
+      explainRetrievalHelp: '用智能体的记忆检索流程运行查询，查看每条记忆被选中或丢弃的原因。权重调整仅对本次查询生效。',
+      explainButton: '解释',
+      classifierDecision: '分类器',
+      retrievalSkipped: '此查询跳过了记忆检索',
+      droppedLabel: '已丢弃',
+      sourcesLabel: '来源',
+      scopeLabel: '作用域',
+      finalScore: '最终得分',
+      confidenceLabel: '置信度',
       testQuery: '测试查询',
       queryPlaceholder: '输入查询内容...',
       search: '搜索',
@@ -573,6 +630,11 @@ const resources = {
       chatQueueDepth: '排队任务：{{count}}',
       chatLastAssistantMessage: '最近一条助手消息',
       chatNoStructuredData: '还没有结构化的助手消息。',
+      elicitationTitle: '{{server}} 需要你的输入',
+      elicitationOpenLink: '打开链接',
+      elicitationSubmit: '提交',
+      elicitationDecline: '拒绝',
+      elicitationCancel: '取消',
       chatPromptAgent: '给选中的智能体发送任务...',
       chatPromptMultiAgent: '用 @Captain @成员名 这类 mention 发送共享任务...',
       chatMultiAgentHint: '请先提及一个或多个成员，例如 @Captain @Writer 总结问题并给出修改建议。',
@@ -285,6 +285,112 @@ export interface Memory {
   updated_at?: string
 }
 
+export interface MemoryRevision {
+  at: string
+  by?: string
+  summary?: string
+}
+
+export interface ConflictMemory extends Memory {
+  confidence?: number
+  conflicting?: boolean
+  revision_history?: MemoryRevision[]
+}
+
+export interface MemoryConflict {
+  memories: ConflictMemory[]
+  reason?: string
+  detected_at: string
+}
+
+export interface MemorySuggestion {
+  id: string
+  session_id?: string
+  type: string
+  content: string
+  importance: number
+  confidence: number
+  scope: string
+  status: 'pending' | 'accepted' | 'rejected'
+  created_at: string
+  expires_at?: string
+}
+
+export interface ElicitationField {
+  name: string
+  title?: string
+  description?: string
+  type: 'string' | 'number' | 'integer' | 'boolean'
+  format?: string
+  enum?: string[]
+  default?: unknown
+  required?: boolean
+}
+
+export interface ElicitationRequest {
+  id: string
+  server: string
+  message: string
+  url?: string
+  fields?: ElicitationField[]
+}
+
+export interface ElicitationAnswer {
+  id: string
+  session_id: string
+  action: 'accept' | 'decline' | 'cancel'
+  content?: Record<string, unknown>
+}
+
+export interface ScoreBreakdown {
+  base: number
+  recency: number
+  importance: number
+  length: number
+  access: number
+  outcome: number
+  final: number
+}
+
+export interface MemoryExplanation {
+  memory_id: string
+  type: string
+  content: string
+  sources: string[]
+  vector_score?: number
+  vector_rank?: number
+  text_score?: number
+  text_rank?: number
+  navigator_rank?: number
+  rrf_score?: number
+  rrf_rank?: number
+  scope: string
+  scope_weight: number
+  scoring?: ScoreBreakdown
+  excluded?: string
+  selected: boolean
+  rank?: number
+}
+
+export type ScoringConfig = Record<string, number | boolean>
+
+export interface RetrievalExplanation {
+  query: string
+  query_type?: string
+  needs_memory: boolean
+  scoring?: ScoringConfig
+  logic?: string
+  redacted?: number
+  memories: MemoryExplanation[]
+}
+
+export interface ExplainMemoryRequest {
+  query: string
+  session_id?: string
+  limit?: number
+  scoring?: ScoringConfig
+}
+
 export interface AddMemoryRequest {
   content: string
   type: string
@@ -296,6 +402,72 @@ export interface UpdateMemoryRequest {
   content: string
 }
 
+// Trace types
+export type SpanKind = 'agent' | 'llm' | 'tool' | 'retrieval' | 'handoff' | 'guardrail' | 'internal'
+
+export interface TraceSpan {
+  id: string
+  trace_id: string
+  parent_id?: string
+  name: string
+  kind: SpanKind
+  status: 'ok' | 'error' | 'canceled'
+  start_time: string
+  end_time?: string
+  duration?: number // nanoseconds
+  attributes?: Record<string, string>
+  agent_id?: string
+  session_id?: string
+}
+
+export interface TraceRecord {
+  id: string
+  name: string
+  session_id?: string
+  agent_id?: string
+  status: 'ok' | 'error'
+  start_time: string
+  end_time: string
+  duration: number // nanoseconds
+  span_count: number
+  llm_calls: number
+  tool_calls: number
+  input_tokens: number
+  output_tokens: number
+  errors: number
+}
+
+export interface TraceHistoryRecord {
+  id: string
+  role: string
+  content: string
+  tool_name?: string
+  round: number
+  created_at: string
+  success: boolean
+  error?: string
+}
+
+export interface TraceUsageRecord {
+  id: string
+  call_type: string
+  provider: string
+  model: string
+  input_tokens: number
+  output_tokens: number
+  cost: number
+  latency: number
+  success: boolean
+  created_at: string
+}
+
+export interface TraceDetail {
+  trace: TraceRecord
+  spans: TraceSpan[]
+  history?: TraceHistoryRecord[]
+  usage?: TraceUsageRecord[]
+}
+
 // Stream callback types
 export type StreamCallback = (chunk: string) => void
 export type StreamErrorCallback = (error: Error) => void
@@ -521,6 +693,40 @@ export const api = {
   searchMemories: (query: string) =>
     fetchAPI<Memory[]>(`/memories/search?q=${encodeURIComponent(query)}`),
 
+  explainMemorySearch: (data: ExplainMemoryRequest) =>
+    fetchAPI<RetrievalExplanation>('/memories/explain', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
+  getMemoryConflicts: () => fetchAPI<MemoryConflict[]>('/memories/conflicts'),
+
+  resolveMemoryConflict: (winnerId: string) =>
+    fetchAPI<{ success: boolean }>('/memories/conflicts/resolve', {
+      method: 'POST',
+      body: JSON.stringify({ winner_id: winnerId }),
+    }),
+
+  getMemorySuggestions: () => fetchAPI<MemorySuggestion[]>('/memories/suggestions'),
+
+  acceptMemorySuggestion: (id: string) =>
+    fetchAPI<{ success: boolean; memory: Memory }>('/memories/suggestions/accept', {
+      method: 'POST',
+      body: JSON.stringify({ id }),
+    }),
+
+  rejectMemorySuggestion: (id: string) =>
+    fetchAPI<{ success: boolean }>('/memories/suggestions/reject', {
+      method: 'POST',
+      body: JSON.stringify({ id }),
+    }),
+
+  respondToElicitation: (answer: ElicitationAnswer) =>
+    fetchAPI<{ status: string }>('/mcp/elicitations/respond', {
+      method: 'POST',
+      body: JSON.stringify(answer),
+    }),
+
   // Agents API
   getSquads: () => fetchAPI<SquadsResponse>('/squads'),
 
@@ -549,6 +755,12 @@ export const api = {
   getOpsLogs: (limit = 20) =>
     fetchAPI<OpsLogsResponse>(`/ops/logs?limit=${limit}`),
 
+  // Traces API
+  getTraces: (limit = 50) =>
+    fetchAPI<{ traces: TraceRecord[] }>(`/traces?limit=${limit}`),
+
+  getTrace: (id: string) => fetchAPI<TraceDetail>(`/traces/${id}`),
+
   getSetup: () => fetchAPI<SetupState>('/setup'),
 
   applySetup: (data: ApplySetupRequest) =>
@@ -3,6 +3,8 @@ import { useTranslation } from 'react-i18next'
 import { useChat } from '@ai-sdk/react'
 import { DefaultChatTransport } from 'ai'
 import { useSquads } from '../hooks/useApi'
+import { ElicitationForm } from '../components/ElicitationForm'
+import type { ElicitationRequest } from '../lib/api'
 
 type ChatMode = 'rag' | 'agent'
 
@@ -72,6 +74,8 @@ export function Chat() {
   const [chatMode, setChatMode] = useState<ChatMode>('rag')
   const [debugEnabled, setDebugEnabled] = useState(false)
   const [selectedAgent, setSelectedAgent] = useState('')
+  // MCP servers' requests for input raised by this chat's agent turns
+  const [elicitations, setElicitations] = useState<Array<{ sessionId: string; request: ElicitationRequest }>>([])
   const messagesEndRef = useRef<HTMLDivElement>(null)
   const { data: squads = [] } = useSquads()
   const leadAgents = useMemo(
@@ -107,6 +111,11 @@ export function Chat() {
         }
       },
     }),
+    onData: (part) => {
+      if (part.type !== 'data-elicitation') return
+      const data = part.data as { session_id: string; elicitation: ElicitationRequest }
+      setElicitations((prev) => [...prev, { sessionId: data.session_id, request: data.elicitation }])
+    },
   })
 
   useEffect(() => {
@@ -168,6 +177,7 @@ export function Chat() {
           <button
             onClick={() => {
               setMessages([])
+              setElicitations([])
               setInput('')
             }}
             className="dashboard-secondary-button px-3 py-2 text-sm"
@@ -340,6 +350,15 @@ export function Chat() {
               )
             })}
 
+            {elicitations.map(({ sessionId, request }) => (
+              <ElicitationForm
+                key={request.id}
+                request={request}
+                sessionId={sessionId}
+                onDone={(id) => setElicitations((prev) => prev.filter((item) => item.request.id !== id))}
+              />
+            ))}
+
             {error && <div className="rounded-[20px] border border-rose-200 bg-rose-50 p-3 text-sm text-rose-700">{t('error')}: {error.message}</div>}
             <div ref={messagesEndRef} />
           </div>
@@ -1,7 +1,8 @@
 import { useState } from 'react'
 import { useTranslation } from 'react-i18next'
-import { useMemories, useAddMemory, useDeleteMemory } from '../hooks/useApi'
+import { useMemories, useAddMemory, useDeleteMemory, useMemoryConflicts, useResolveMemoryConflict, useMemorySuggestions, useDecideMemorySuggestion } from '../hooks/useApi'
 import type { Memory, AddMemoryRequest } from '../lib/api'
+import { MemoryExplainPanel } from '../components/MemoryExplainPanel'
 
 export function Memory() {
   const { t } = useTranslation()
@@ -12,6 +13,10 @@ export function Memory() {
   const { data: memories, isLoading, error, refetch } = useMemories()
   const addMutation = useAddMemory()
   const deleteMutation = useDeleteMemory()
+  const { data: conflicts } = useMemoryConflicts()
+  const resolveMutation = useResolveMemoryConflict()
+  const { data: suggestions } = useMemorySuggestions()
+  const decideMutation = useDecideMemorySuggestion()
   
   // Filter memories based on search
   const filteredMemories = searchQuery 
@@ -106,6 +111,82 @@ export function Memory() {
         />
       </div>
 
+      {/* Retrieval Explanation */}
+      <MemoryExplainPanel />
+
+      {/* Conflict Review Queue */}
+      {conflicts && conflicts.length > 0 && (
+        <div className="glass-panel rounded-[28px] border border-amber-200 p-6" data-testid="memory-conflicts">
+          <h3 className="text-lg font-medium text-slate-900">{t('memoryConflicts')} ({conflicts.length})</h3>
+          <p className="mb-4 text-sm text-slate-500">{t('memoryConflictsHelp')}</p>
+          <div className="space-y-4">
+            {conflicts.map((conflict) => (
+              <div key={conflict.memories.map((m) => m.id).join('|')} className="rounded-2xl bg-amber-50/60 p-4">
+                {conflict.reason && <p className="mb-2 text-xs text-amber-700">{conflict.reason}</p>}
+                <div className="grid gap-3 md:grid-cols-2">
+                  {conflict.memories.map((memory) => (
+                    <div key={memory.id} className="rounded-xl border border-amber-100 bg-white p-3">
+                      <p className="text-sm text-slate-700">{memory.content}</p>
+                      <div className="mt-2 flex items-center justify-between text-xs text-slate-500">
+                        <span>
+                          {memory.type} · {t('confidenceLabel')}: {(memory.confidence ?? 0).toFixed(2)}
+                        </span>
+                        <button
+                          onClick={() => resolveMutation.mutate(memory.id)}
+                          disabled={resolveMutation.isPending}
+                          className="dashboard-button px-3 py-1 text-xs disabled:opacity-50"
+                          data-testid={`memory-conflict-keep-${memory.id}`}
+                        >
+                          {t('keepThisMemory')}
+                        </button>
+                      </div>
+                    </div>
+                  ))}
+                </div>
+              </div>
+            ))}
+          </div>
+        </div>
+      )}
+
+      {/* Suggested Memories */}
+      {suggestions && suggestions.length > 0 && (
+        <div className="glass-panel rounded-[28px] border border-sky-200 p-6" data-testid="memory-suggestions">
+          <h3 className="text-lg font-medium text-slate-900">{t('memorySuggestions')} ({suggestions.length})</h3>
+          <p className="mb-4 text-sm text-slate-500">{t('memorySuggestionsHelp')}</p>
+          <div className="space-y-3">
+            {suggestions.map((suggestion) => (
+              <div key={suggestion.id} className="rounded-xl border border-sky-100 bg-white p-3">
+                <p className="text-sm text-slate-700">{suggestion.content}</p>
+                <div className="mt-2 flex items-center justify-between text-xs text-slate-500">
+                  <span>
+                    {suggestion.type} · {t('scopeLabel')}: {suggestion.scope} · {t('confidenceLabel')}: {suggestion.confidence.toFixed(2)}
+                  </span>
+                  <div className="flex gap-2">
+                    <button
+                      onClick={() => decideMutation.mutate({ id: suggestion.id, accept: true })}
+                      disabled={decideMutation.isPending}
+                      className="dashboard-button px-3 py-1 text-xs disabled:opacity-50"
+                      data-testid={`memory-suggestion-accept-${suggestion.id}`}
+                    >
+                      {t('acceptSuggestion')}
+                    </button>
+                    <button
+                      onClick={() => decideMutation.mutate({ id: suggestion.id, accept: false })}
+                      disabled={decideMutation.isPending}
+                      className="dashboard-secondary-button px-3 py-1 text-xs disabled:opacity-50"
+                      data-testid={`memory-suggestion-reject-${suggestion.id}`}
+                    >
+                      {t('rejectSuggestion')}
+                    </button>
+                  </div>
+                </div>
+              </div>
+            ))}
+          </div>
+        </div>
+      )}
+
       {/* Add Memory Form */}
       {showAddForm && (
         <div className="fixed inset-0 z-50 flex items-center justify-center bg-sky-950/10 backdrop-blur-sm">
@@ -0,0 +1,226 @@
+import { useMemo, useState } from 'react'
+import { useTranslation } from 'react-i18next'
+import { useTraces, useTrace } from '../hooks/useApi'
+import type { TraceDetail, TraceSpan } from '../lib/api'
+
+const NS_PER_MS = 1_000_000
+
+function formatDuration(ns: number) {
+  const ms = ns / NS_PER_MS
+  if (ms >= 1000) return `${(ms / 1000).toFixed(2)} s`
+  return `${ms.toFixed(ms < 10 ? 1 : 0)} ms`
+}
+
+function kindColor(kind: TraceSpan['kind']) {
+  switch (kind) {
+    case 'agent': return 'bg-sky-500'
+    case 'llm': return 'bg-indigo-500'
+    case 'tool': return 'bg-emerald-500'
+    case 'retrieval': return 'bg-amber-500'
+    case 'handoff': return 'bg-pink-500'
+    default: return 'bg-slate-400'
+  }
+}
+
+interface SpanRow {
+  span: TraceSpan
+  depth: number
+}
+
+// orderSpans flattens the span tree depth-first, children sorted by start time
+function orderSpans(spans: TraceSpan[]): SpanRow[] {
+  const ids = new Set(spans.map((s) => s.id))
+  const children = new Map<string, TraceSpan[]>()
+  const roots: TraceSpan[] = []
+  for (const span of spans) {
+    if (!span.parent_id || !ids.has(span.parent_id)) {
+      roots.push(span)
+    } else {
+      children.set(span.parent_id, [...(children.get(span.parent_id) ?? []), span])
+    }
+  }
+  const rows: SpanRow[] = []
+  const walk = (list: TraceSpan[], depth: number) => {
+    list
+      .slice()
+      .sort((a, b) => Date.parse(a.start_time) - Date.parse(b.start_time))
+      .forEach((span) => {
+        rows.push({ span, depth })
+        walk(children.get(span.id) ?? [], depth + 1)
+      })
+  }
+  walk(roots, 0)
+  return rows
+}
+
+function Waterfall({ detail }: { detail: TraceDetail }) {
+  const { t } = useTranslation()
+  const start = Date.parse(detail.trace.start_time)
+  const total = Math.max(detail.trace.duration / NS_PER_MS, 1)
+  const rows = useMemo(() => orderSpans(detail.spans), [detail.spans])
+
+  return (
+    <div className="space-y-6" data-testid="trace-waterfall">
+      <div className="grid grid-cols-2 gap-3 sm:grid-cols-4">
+        <div className="rounded-2xl bg-white/70 p-3">
+          <p className="text-xs text-slate-500">{t('traceSpans')}</p>
+          <p className="text-lg font-semibold text-slate-900">{detail.trace.span_count}</p>
+        </div>
+        <div className="rounded-2xl bg-white/70 p-3">
+          <p className="text-xs text-slate-500">LLM / Tools</p>
+          <p className="text-lg font-semibold text-slate-900">{detail.trace.llm_calls} / {detail.trace.tool_calls}</p>
+        </div>
+        <div className="rounded-2xl bg-white/70 p-3">
+          <p className="text-xs text-slate-500">{t('traceTokens')}</p>
+          <p className="text-lg font-semibold text-slate-900">
+            {detail.trace.input_tokens} → {detail.trace.output_tokens}
+          </p>
+        </div>
+        <div className="rounded-2xl bg-white/70 p-3">
+          <p className="text-xs text-slate-500">{t('traceErrors')}</p>
+          <p className={`text-lg font-semibold ${detail.trace.errors > 0 ? 'text-rose-600' : 'text-slate-900'}`}>
+            {detail.trace.errors}
+          </p>
+        </div>
+      </div>
+
+      <div className="space-y-1">
+        {rows.map(({ span, depth }) => {
+          const offset = ((Date.parse(span.start_time) - start) / total) * 100
+          const width = Math.max(((span.duration ?? 0) / NS_PER_MS / total) * 100, 0.5)
+          const inTokens = span.attributes?.['gen_ai.usage.input_tokens']
+          const outTokens = span.attributes?.['gen_ai.usage.output_tokens']
+          return (
+            <div key={span.id} className="grid grid-cols-[minmax(0,2fr)_minmax(0,3fr)_80px] items-center gap-3 text-sm" data-testid="trace-span">
+              <div className="truncate" style={{ paddingLeft: depth * 16 }} title={span.name}>
+                <span className={span.status === 'error' ? 'text-rose-600' : 'text-slate-800'}>{span.name}</span>
+                {inTokens && (
+                  <span className="ml-2 text-xs text-slate-500">{inTokens}→{outTokens} tok</span>
+                )}
+                {span.status === 'error' && span.attributes?.error && (
+                  <p className="truncate text-xs text-rose-500">{span.attributes.error}</p>
+                )}
+              </div>
+              <div className="relative h-4 rounded bg-slate-100">
+                <div
+                  className={`absolute h-4 rounded ${span.status === 'error' ? 'bg-rose-500' : kindColor(span.kind)}`}
+                  style={{ left: `${Math.min(offset, 99.5)}%`, width: `${Math.min(width, 100 - offset)}%` }}
+                />
+              </div>
+              <div className="text-right text-xs text-slate-500">{formatDuration(span.duration ?? 0)}</div>
+            </div>
+          )
+        })}
+      </div>
+
+      {detail.history && detail.history.length > 0 && (
+        <div>
+          <h4 className="mb-2 text-sm font-semibold text-slate-700">{t('traceHistory')}</h4>
+          <div className="space-y-1 text-sm" data-testid="trace-history">
+            {detail.history.map((h) => (
+              <div key={h.id} className="flex gap-3">
+                <span className="w-24 shrink-0 text-xs text-slate-500">{new Date(h.created_at).toLocaleTimeString()}</span>
+                <span className="w-20 shrink-0 text-xs font-medium text-slate-600">{h.role}</span>
+                <span className="truncate text-slate-800">{h.tool_name ? `${h.tool_name} ` : ''}{h.content}</span>
+              </div>
+            ))}
+          </div>
+        </div>
+      )}
+
+      {detail.usage && detail.usage.length > 0 && (
+        <div>
+          <h4 className="mb-2 text-sm font-semibold text-slate-700">{t('traceUsage')}</h4>
+          <div className="space-y-1 text-sm" data-testid="trace-usage">
+            {detail.usage.map((u) => (
+              <div key={u.id} className="flex gap-3">
+                <span className="w-24 shrink-0 text-xs text-slate-500">{new Date(u.created_at).toLocaleTimeString()}</span>
+                <span className="w-12 shrink-0 text-xs font-medium text-slate-600">{u.call_type}</span>
+                <span className="truncate text-slate-800">{u.model}</span>
+                <span className="ml-auto text-xs text-slate-500">{u.input_tokens} → {u.output_tokens} · ${u.cost.toFixed(4)}</span>
+              </div>
+            ))}
+          </div>
+        </div>
+      )}
+    </div>
+  )
+}
+
+export function Traces() {
+  const { t } = useTranslation()
+  const [selectedId, setSelectedId] = useState('')
+  const { data: traces, isLoading, error, refetch } = useTraces()
+  const { data: detail, isLoading: detailLoading } = useTrace(selectedId)
+
+  if (isLoading) {
+    return (
+      <div className="flex items-center justify-center h-64">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-blue-600"></div>
+      </div>
+    )
+  }
+
+  if (error) {
+    return (
+      <div className="rounded-[24px] border border-rose-200 bg-rose-50 p-4">
+        <p className="text-rose-700">{t('errorLoadingTraces')}: {error.message}</p>
+        <button onClick={() => refetch()} className="mt-2 px-4 py-2 bg-red-600 text-white rounded-lg">
+          {t('retry')}
+        </button>
+      </div>
+    )
+  }
+
+  return (
+    <div className="space-y-6" data-testid="page-traces">
+      <div className="flex items-center justify-between">
+        <h2 className="text-xl font-semibold text-slate-900">{t('traces')}</h2>
+        <button
+          onClick={() => refetch()}
+          className="dashboard-secondary-button px-4 py-2 text-sm"
+          data-testid="traces-refresh"
+        >
+          {t('refresh')}
+        </button>
+      </div>
+
+      <div className="grid gap-6 lg:grid-cols-[360px_minmax(0,1fr)]">
+        <div className="glass-panel rounded-[28px] p-4">
+          {!traces || traces.length === 0 ? (
+            <p className="text-sm text-slate-500">{t('noTraces')}</p>
+          ) : (
+            <ul className="space-y-2" data-testid="trace-list">
+              {traces.map((trace) => (
+                <li key={trace.id}>
+                  <button
+                    onClick={() => setSelectedId(trace.id)}
+                    className={`w-full rounded-2xl p-3 text-left transition ${
+                      selectedId === trace.id ? 'bg-sky-100' : 'hover:bg-white/70'
+                    }`}
+                  >
+                    <div className="flex items-center justify-between gap-2">
+                      <span className={`truncate text-sm font-medium ${trace.status === 'error' ? 'text-rose-600' : 'text-slate-900'}`}>
+                        {trace.name}
+                      </span>
+                      <span className="shrink-0 text-xs text-slate-500">{formatDuration(trace.duration)}</span>
+                    </div>
+                    <p className="mt-1 text-xs text-slate-500">
+                      {new Date(trace.start_time).toLocaleString()} · {trace.span_count} spans · {trace.input_tokens + trace.output_tokens} tok
+                    </p>
+                  </button>
+                </li>
+              ))}
+            </ul>
+          )}
+        </div>
+
+        <div className="glass-panel rounded-[28px] p-6">
+          {!selectedId && <p className="text-sm text-slate-500">{t('selectTrace')}</p>}
+          {selectedId && detailLoading && <p className="text-sm text-slate-500">{t('loading')}</p>}
+          {detail && <Waterfall detail={detail} />}
+        </div>
+      </div>
+    </div>
+  )
+}